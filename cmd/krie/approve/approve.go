@@ -0,0 +1,81 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approve
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Gui774ume/krie/pkg/krie"
+)
+
+// CLIOptions are the command line options of the approve command
+type CLIOptions struct {
+	Config   string
+	Approver string
+	Key      string
+	TTL      time.Duration
+}
+
+func approveCmd(cmd *cobra.Command, args []string) error {
+	if len(options.Approver) == 0 || len(options.Key) == 0 {
+		return fmt.Errorf("--approver and --key are required")
+	}
+
+	key, err := krie.ReadPrivateKey(options.Key)
+	if err != nil {
+		return err
+	}
+
+	config, err := krie.LoadOptions(options.Config)
+	if err != nil {
+		return err
+	}
+
+	hash, err := config.Events.Hash()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "approving policies %s (version: %s), enforced rules: %v\n", hash, config.PolicyVersion, config.Events.EnforcedRules())
+	fmt.Println(krie.SignApproval(options.Approver, key, hash, time.Now().Add(options.TTL)))
+	return nil
+}
+
+func keyGenCmd(cmd *cobra.Command, args []string) error {
+	if len(options.Key) == 0 {
+		return fmt.Errorf("--key is required")
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("couldn't generate key: %w", err)
+	}
+	if err = os.WriteFile(options.Key, []byte(base64.StdEncoding.EncodeToString(privateKey.Seed())+"\n"), 0600); err != nil {
+		return fmt.Errorf("couldn't write private key: %w", err)
+	}
+
+	// the public key goes in the approvers list of the approval section
+	fmt.Println(base64.StdEncoding.EncodeToString(publicKey))
+	return nil
+}
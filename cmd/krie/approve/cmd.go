@@ -0,0 +1,50 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approve
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Approve represents the command used to sign the approval of a policy
+var Approve = &cobra.Command{
+	Use:   "approve",
+	Short: "Sign an approval token for the policies of a KRIE config file",
+	RunE:  approveCmd,
+}
+
+// KeyGen represents the command used to generate the key pair of an approver
+var KeyGen = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate the ed25519 key pair of an approver",
+	RunE:  keyGenCmd,
+}
+
+var options CLIOptions
+
+func init() {
+	Approve.Flags().StringVar(&options.Config, "config", "./cmd/krie/run/config/default_config.yaml", "KRIe config file")
+	Approve.Flags().StringVar(&options.Approver, "approver", "", "name of the approver, as configured in the approval section")
+	Approve.Flags().StringVar(&options.Key, "key", "", "private key file of the approver")
+	Approve.Flags().DurationVar(&options.TTL, "ttl", time.Hour, "validity period of the approval token")
+
+	KeyGen.Flags().StringVar(&options.Key, "key", "", "output private key file")
+
+	Approve.AddCommand(KeyGen)
+}
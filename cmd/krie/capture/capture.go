@@ -0,0 +1,72 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capture
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Gui774ume/krie/pkg/krie"
+)
+
+// CLIOptions are the command line options of the capture command
+type CLIOptions struct {
+	Config   string
+	Duration time.Duration
+	Events   string
+	PID      uint32
+	Output   string
+}
+
+func captureCmd(cmd *cobra.Command, args []string) error {
+	config, err := krie.LoadOptions(options.Config)
+	if err != nil {
+		return err
+	}
+	logrus.SetLevel(logrus.Level(config.LogLevel))
+
+	eventTypes, err := krie.ParseCaptureEventTypes(options.Events)
+	if err != nil {
+		return err
+	}
+	output := options.Output
+	if len(output) == 0 {
+		output = fmt.Sprintf("krie-capture-%s.json", time.Now().UTC().Format("20060102T150405Z"))
+	}
+
+	// an interrupt ends the capture early
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	result, err := krie.Capture(ctx, config, &krie.CaptureRequest{
+		Duration:   options.Duration,
+		EventTypes: eventTypes,
+		PID:        options.PID,
+		Output:     output,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d events captured from %s to %s in %s\n", result.Events, result.Start.Format(time.RFC3339), result.End.Format(time.RFC3339), result.Output)
+	return nil
+}
@@ -0,0 +1,40 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capture
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Capture represents the command used to capture the events of a set of event types for a time window
+var Capture = &cobra.Command{
+	Use:   "capture",
+	Short: "Attach the probes of the requested event types for a time window, and write their events to a dedicated file",
+	RunE:  captureCmd,
+}
+
+var options CLIOptions
+
+func init() {
+	Capture.Flags().StringVar(&options.Config, "config", "./cmd/krie/run/config/default_config.yaml", "KRIe config file")
+	Capture.Flags().DurationVar(&options.Duration, "duration", 10*time.Minute, "duration of the capture")
+	Capture.Flags().StringVar(&options.Events, "events", "", "comma separated list of the captured event types, for example ptrace,bpf")
+	Capture.Flags().Uint32Var(&options.PID, "pid", 0, "only capture the events of this process")
+	Capture.Flags().StringVar(&options.Output, "output", "", "file of the captured events (defaults to ./krie-capture-[timestamp].json)")
+}
@@ -0,0 +1,41 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collect
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Collect represents the command used to export a forensic bundle
+var Collect = &cobra.Command{
+	Use:   "collect",
+	Short: "Package the recent events, the inventory, the probe report, the kernel information, the policies and the agent logs into a forensic bundle",
+	RunE:  collectCmd,
+}
+
+var options CLIOptions
+
+func init() {
+	Collect.Flags().StringVar(&options.Config, "config", "./cmd/krie/run/config/default_config.yaml", "KRIe config file")
+	Collect.Flags().StringVar(&options.Collect.Output, "output", "", "output bundle (default \"krie-<hostname>-<timestamp>.tar.gz\")")
+	Collect.Flags().IntVar(&options.Collect.Events, "events", 10000, "number of events collected from the end of the output file")
+	Collect.Flags().DurationVar(&options.Collect.Since, "since", 24*time.Hour, "age of the oldest burst bundle collected")
+	Collect.Flags().StringSliceVar(&options.Collect.LogFiles, "log-file", nil, "agent log file to collect (can be repeated)")
+	Collect.Flags().StringVar(&options.Collect.Key, "key", "", "private key used to sign the bundle, as generated by \"krie approve keygen\"")
+}
@@ -0,0 +1,56 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collect
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Gui774ume/krie/pkg/krie"
+)
+
+// CLIOptions are the command line options of the collect command
+type CLIOptions struct {
+	Config  string
+	Collect krie.CollectOptions
+}
+
+func collectCmd(cmd *cobra.Command, args []string) error {
+	config, err := krie.LoadOptions(options.Config)
+	if err != nil {
+		return err
+	}
+
+	if len(options.Collect.Output) == 0 {
+		hostname, _ := os.Hostname()
+		options.Collect.Output = fmt.Sprintf("krie-%s-%s.tar.gz", hostname, time.Now().UTC().Format("20060102T150405Z"))
+	}
+
+	digest, err := krie.Collect(options.Config, config, options.Collect)
+	if err != nil {
+		return fmt.Errorf("couldn't collect forensic bundle: %w", err)
+	}
+
+	fmt.Printf("%s %s\n", digest, options.Collect.Output)
+	if len(options.Collect.Key) > 0 {
+		fmt.Printf("signature: %s.sig\n", options.Collect.Output)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package companion
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Companion represents the command used to run an unprivileged companion of a privileged KRIE process
+var Companion = &cobra.Command{
+	Use:   "companion",
+	Short: "Run an unprivileged companion which reads the events streamed by a privileged KRIE process, and hosts the API and the output file",
+	RunE:  companionCmd,
+}
+
+var options CLIOptions
+
+func init() {
+	Companion.Flags().StringVar(&options.Config, "config", "./cmd/krie/run/config/default_config.yaml", "KRIe config file")
+	Companion.Flags().StringVar(&options.Socket, "socket", "", "unix socket of the privileged process (defaults to companion.socket)")
+	Companion.Flags().StringVar(&options.Listen, "listen", "", "listen address of the API (defaults to api.listen)")
+	Companion.Flags().StringVar(&options.Output, "output", "", "output file of the streamed events (disabled by default)")
+}
@@ -0,0 +1,60 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package companion
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Gui774ume/krie/pkg/krie"
+)
+
+// CLIOptions are the command line options of the companion command
+type CLIOptions struct {
+	Config string
+	Socket string
+	Listen string
+	Output string
+}
+
+func companionCmd(cmd *cobra.Command, args []string) error {
+	config, err := krie.LoadOptions(options.Config)
+	if err != nil {
+		return err
+	}
+	if len(options.Socket) > 0 {
+		config.Companion.Socket = options.Socket
+	}
+	if len(options.Listen) > 0 {
+		config.API.Listen = options.Listen
+	}
+	// the output file of the config belongs to the privileged process
+	config.Output = options.Output
+
+	companion, err := krie.NewCompanion(config)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	return companion.Run(ctx)
+}
@@ -19,14 +19,36 @@ package main
 import (
 	"github.com/sirupsen/logrus"
 
+	"github.com/Gui774ume/krie/cmd/krie/approve"
+	"github.com/Gui774ume/krie/cmd/krie/capture"
+	"github.com/Gui774ume/krie/cmd/krie/collect"
+	"github.com/Gui774ume/krie/cmd/krie/companion"
+	"github.com/Gui774ume/krie/cmd/krie/noise"
+	"github.com/Gui774ume/krie/cmd/krie/query"
 	"github.com/Gui774ume/krie/cmd/krie/run"
+	"github.com/Gui774ume/krie/cmd/krie/selftest"
+	"github.com/Gui774ume/krie/cmd/krie/sigma"
+	"github.com/Gui774ume/krie/cmd/krie/verifykernel"
+	krietesting "github.com/Gui774ume/krie/pkg/testing"
 )
 
 func main() {
+	// the selftest triggers run in a child process
+	krietesting.HandleTrigger()
+
 	logrus.SetFormatter(&logrus.TextFormatter{
 		FullTimestamp:          true,
 		TimestampFormat:        "2006-01-02T15:04:05Z",
 		DisableLevelTruncation: true,
 	})
+	run.KRIE.AddCommand(approve.Approve)
+	run.KRIE.AddCommand(capture.Capture)
+	run.KRIE.AddCommand(collect.Collect)
+	run.KRIE.AddCommand(companion.Companion)
+	run.KRIE.AddCommand(noise.Noise)
+	run.KRIE.AddCommand(query.Query)
+	run.KRIE.AddCommand(selftest.SelfTest)
+	run.KRIE.AddCommand(sigma.Sigma)
+	run.KRIE.AddCommand(verifykernel.VerifyKernel)
 	_ = run.KRIE.Execute()
 }
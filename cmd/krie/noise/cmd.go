@@ -0,0 +1,36 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noise
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Noise represents the command used to rank the noisiest rules and processes, and to suggest filters
+var Noise = &cobra.Command{
+	Use:   "noise",
+	Short: "Rank the rules and processes that generate the most events, and suggest filters with their projected volume reduction",
+	RunE:  noiseCmd,
+}
+
+var options CLIOptions
+
+func init() {
+	Noise.Flags().StringVar(&options.Config, "config", "./cmd/krie/run/config/default_config.yaml", "KRIe config file")
+	Noise.Flags().StringVar(&options.Input, "input", "", "JSON lines file of events (defaults to the storage of the config)")
+	Noise.Flags().BoolVar(&options.JSON, "json", false, "print the report in JSON")
+}
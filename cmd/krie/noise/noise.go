@@ -0,0 +1,90 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noise
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Gui774ume/krie/pkg/krie"
+)
+
+// CLIOptions are the command line options of the noise command
+type CLIOptions struct {
+	Config string
+	Input  string
+	JSON   bool
+}
+
+func noiseCmd(cmd *cobra.Command, args []string) error {
+	config, err := krie.LoadOptions(options.Config)
+	if err != nil {
+		return err
+	}
+
+	var storage krie.Storage
+	if len(options.Input) > 0 {
+		storage = krie.OpenFileStorage(options.Input)
+	} else if storage, err = krie.OpenStorage(config); err != nil {
+		return fmt.Errorf("%w, use --input", err)
+	}
+
+	report, err := krie.NoiseReportFromStorage(storage, config.Noise, config.Events)
+	if err != nil {
+		return fmt.Errorf("couldn't build noise report: %w", err)
+	}
+
+	if options.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	fmt.Printf("%d events from %s to %s\n\n", report.Total, report.Since.Format("2006-01-02T15:04:05Z07:00"), report.Until.Format("2006-01-02T15:04:05Z07:00"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "RULE\tCOMM\tCOUNT\tSHARE\tPER HOUR")
+	for i, entry := range report.Combinations {
+		if i == 20 {
+			break
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%.1f%%\t%.1f\n", entry.RuleID, entry.Comm, entry.Count, entry.Share, entry.PerHour)
+	}
+	_ = w.Flush()
+
+	if report.Annotations != nil {
+		fmt.Printf("\nannotations: %d acknowledged, %d false positive(s)\n", report.Annotations.Acknowledged, report.Annotations.FalsePositives)
+		for ruleID, count := range report.Annotations.FalsePositiveRules {
+			fmt.Printf("  - %s: %d false positive(s)\n", ruleID, count)
+		}
+		if len(report.Annotations.Tickets) > 0 {
+			fmt.Printf("  tickets: %s\n", strings.Join(report.Annotations.Tickets, ", "))
+		}
+	}
+
+	if len(report.Suggestions) > 0 {
+		fmt.Printf("\nsuggestions:\n")
+		for _, suggestion := range report.Suggestions {
+			fmt.Printf("  - %s (-%.1f%%)\n", suggestion.Description, suggestion.ProjectedReduction)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Query represents the command used to search the events of the local store
+var Query = &cobra.Command{
+	Use:   "query",
+	Short: "Print the events of the local store matching the provided time range, event types and rule, as JSON lines",
+	RunE:  queryCmd,
+}
+
+var options CLIOptions
+
+func init() {
+	Query.Flags().StringVar(&options.Config, "config", "./cmd/krie/run/config/default_config.yaml", "KRIe config file")
+	Query.Flags().StringVar(&options.Input, "input", "", "JSON lines file of events (defaults to the storage of the config)")
+	Query.Flags().StringVar(&options.Since, "since", "", "only the events after this time (RFC 3339 time, or a duration such as 1h)")
+	Query.Flags().StringVar(&options.Until, "until", "", "only the events before this time (RFC 3339 time, or a duration such as 1h)")
+	Query.Flags().StringArrayVar(&options.Types, "type", nil, "only the events of this type, can be repeated")
+	Query.Flags().StringVar(&options.Rule, "rule", "", "only the events of this rule and of its sub rules")
+	Query.Flags().IntVar(&options.Limit, "limit", 0, "maximum number of events (0 for no limit)")
+}
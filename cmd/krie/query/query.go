@@ -0,0 +1,93 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Gui774ume/krie/pkg/krie"
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// CLIOptions are the command line options of the query command
+type CLIOptions struct {
+	Config string
+	Input  string
+	Since  string
+	Until  string
+	Types  []string
+	Rule   string
+	Limit  int
+}
+
+// parseTime parses an RFC 3339 time, or a duration relative to now
+func parseTime(input string, now time.Time) (time.Time, error) {
+	if len(input) == 0 {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(input); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, input)
+}
+
+func queryCmd(cmd *cobra.Command, args []string) error {
+	config, err := krie.LoadOptions(options.Config)
+	if err != nil {
+		return err
+	}
+
+	query := krie.StorageQuery{
+		RuleID: options.Rule,
+		Limit:  options.Limit,
+	}
+	now := time.Now()
+	if query.Since, err = parseTime(options.Since, now); err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	if query.Until, err = parseTime(options.Until, now); err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+	for _, input := range options.Types {
+		eventType := events.ParseEventType(input)
+		if eventType == events.UnknownEventType {
+			return fmt.Errorf("unknown event type: %s", input)
+		}
+		query.Types = append(query.Types, eventType)
+	}
+
+	var storage krie.Storage
+	if len(options.Input) > 0 {
+		storage = krie.OpenFileStorage(options.Input)
+	} else if storage, err = krie.OpenStorage(config); err != nil {
+		return fmt.Errorf("%w, use --input", err)
+	}
+
+	var writeErr error
+	err = storage.Query(query, func(event *krie.StoredEvent) bool {
+		_, writeErr = os.Stdout.Write(event.Data)
+		return writeErr == nil
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't query the events: %w", err)
+	}
+	return writeErr
+}
@@ -21,6 +21,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"syscall"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -40,9 +41,10 @@ func parseConfig() error {
 	if err = decoder.Decode(&options.KRIEOptions); err != nil {
 		return fmt.Errorf("couldn't decode config file %s: %w", options.Config, err)
 	}
+	options.KRIEOptions.ConfigFile = options.Config
 
 	// create output directory
-	if len(options.KRIEOptions.Output) > 0 {
+	if len(options.KRIEOptions.Output) > 0 && options.KRIEOptions.Output != krie.StdoutOutput {
 		_ = os.MkdirAll(filepath.Dir(options.KRIEOptions.Output), 0644)
 	}
 
@@ -73,16 +75,46 @@ func krieCmd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("couldn't start: %w", err)
 	}
 
-	wait()
+	wait(trace)
 
 	_ = trace.Stop()
 	return nil
 }
 
-// wait stops the main goroutine until an interrupt or kill signal is sent
-func wait() {
+// reloadPolicies reloads the events section and the policy version of the config file. The approval tokens are read
+// from the tokens file of the running configuration.
+func reloadPolicies(trace *krie.KRIE) error {
+	reloaded, err := krie.LoadOptions(options.Config)
+	if err != nil {
+		return err
+	}
+
+	var tokens []string
+	if len(options.KRIEOptions.Approval.TokensFile) > 0 {
+		if tokens, err = krie.ReadApprovalTokens(options.KRIEOptions.Approval.TokensFile); err != nil {
+			return err
+		}
+	}
+	return trace.UpdatePolicies(reloaded.PolicyVersion, reloaded.Events, tokens)
+}
+
+// wait stops the main goroutine until an interrupt or kill signal is sent, or until a new instance takes over, the
+// policies are reloaded on SIGHUP
+func wait(trace *krie.KRIE) {
 	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, os.Interrupt, os.Kill)
-	<-sig
-	fmt.Println()
+	signal.Notify(sig, os.Interrupt, os.Kill, syscall.SIGHUP)
+	for {
+		select {
+		case s := <-sig:
+			if s != syscall.SIGHUP {
+				fmt.Println()
+				return
+			}
+			if err := reloadPolicies(trace); err != nil {
+				logrus.Errorf("couldn't reload policies: %v", err)
+			}
+		case <-trace.UpgradeRequested():
+			return
+		}
+	}
 }
@@ -0,0 +1,39 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package selftest
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// SelfTest represents the command used to validate KRIE against the running kernel
+var SelfTest = &cobra.Command{
+	Use:   "selftest",
+	Short: "Trigger each event type against the running kernel and check the produced events",
+	RunE:  selfTestCmd,
+}
+
+var options CLIOptions
+
+func init() {
+	SelfTest.Flags().StringVar(&options.Config, "config", "./cmd/krie/run/config/default_config.yaml", "KRIe config file")
+	SelfTest.Flags().StringVar(&options.Harness.ModulePath, "module", "", "compiled test module (see pkg/testing/module), the module events are skipped if empty")
+	SelfTest.Flags().DurationVar(&options.Harness.Timeout, "timeout", 5*time.Second, "maximum time to wait for the event of a trigger")
+	SelfTest.Flags().StringSliceVar(&options.Triggers, "trigger", nil, "trigger to run (can be repeated), all the triggers are run if empty")
+}
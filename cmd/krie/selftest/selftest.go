@@ -0,0 +1,70 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package selftest
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Gui774ume/krie/pkg/krie"
+	krietesting "github.com/Gui774ume/krie/pkg/testing"
+)
+
+// CLIOptions are the command line options of the selftest command
+type CLIOptions struct {
+	Config   string
+	Triggers []string
+	Harness  krietesting.HarnessOptions
+}
+
+func selfTestCmd(cmd *cobra.Command, args []string) error {
+	config, err := krie.LoadOptions(options.Config)
+	if err != nil {
+		return err
+	}
+	logrus.SetLevel(logrus.Level(config.LogLevel))
+
+	triggers, err := krietesting.SelectTriggers(options.Triggers)
+	if err != nil {
+		return err
+	}
+
+	harness, err := krietesting.NewHarness(config, options.Harness)
+	if err != nil {
+		return fmt.Errorf("couldn't create test harness: %w", err)
+	}
+	if err = harness.Start(); err != nil {
+		_ = harness.Stop()
+		return fmt.Errorf("couldn't start test harness: %w", err)
+	}
+	results := harness.RunAll(triggers)
+	_ = harness.Stop()
+
+	var failed int
+	for _, result := range results {
+		fmt.Printf("%-4s %-15s %8s %s%s\n", result.Status, result.Trigger, result.Duration.Round(1e6), result.EventID, result.Error)
+		if result.Status == krietesting.StatusFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d/%d trigger(s) failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sigma
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/Gui774ume/krie/pkg/krie"
+)
+
+// Sigma represents the command used to export the rules of KRIE as Sigma rules
+var Sigma = &cobra.Command{
+	Use:   "sigma",
+	Short: "Export the activated rules of a configuration as Sigma rules over the JSON fields of the events",
+	RunE:  sigmaCmd,
+}
+
+var options CLIOptions
+
+func init() {
+	Sigma.Flags().StringVar(&options.Config, "config", "./cmd/krie/run/config/default_config.yaml", "KRIe config file")
+	Sigma.Flags().StringVar(&options.Output, "output", "", "directory of the Sigma rules, one file per rule (defaults to the standard output)")
+	Sigma.Flags().StringVar(&options.Product, "product", krie.DefaultSigmaLogSource.Product, "product of the log source of the rules")
+	Sigma.Flags().StringVar(&options.Service, "service", krie.DefaultSigmaLogSource.Service, "service of the log source of the rules")
+}
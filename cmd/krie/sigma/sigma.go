@@ -0,0 +1,57 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sigma
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Gui774ume/krie/pkg/krie"
+)
+
+// CLIOptions are the command line options of the sigma command
+type CLIOptions struct {
+	Config  string
+	Output  string
+	Product string
+	Service string
+}
+
+func sigmaCmd(cmd *cobra.Command, args []string) error {
+	config, err := krie.LoadOptions(options.Config)
+	if err != nil {
+		return err
+	}
+	if err = config.Events.IsValid(); err != nil {
+		return fmt.Errorf("invalid events section: %w", err)
+	}
+
+	rules := krie.SigmaRules(config.Events, krie.SigmaLogSource{
+		Product: options.Product,
+		Service: options.Service,
+	})
+	if len(options.Output) == 0 {
+		return krie.WriteSigmaRules(os.Stdout, rules)
+	}
+	if err = krie.WriteSigmaRuleFiles(options.Output, rules); err != nil {
+		return fmt.Errorf("couldn't write Sigma rules: %w", err)
+	}
+	fmt.Printf("%d Sigma rules written to %s\n", len(rules), options.Output)
+	return nil
+}
@@ -0,0 +1,40 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifykernel
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// VerifyKernel represents the command used to produce the compatibility report of KRIE on the running kernel
+var VerifyKernel = &cobra.Command{
+	Use:   "verify-kernel",
+	Short: "Load KRIE on the running kernel, trigger each event type and write a JSON compatibility report",
+	RunE:  verifyKernelCmd,
+}
+
+var options CLIOptions
+
+func init() {
+	VerifyKernel.Flags().StringVar(&options.Config, "config", "./cmd/krie/run/config/default_config.yaml", "KRIe config file")
+	VerifyKernel.Flags().StringVar(&options.Harness.ModulePath, "module", "", "compiled test module (see pkg/testing/module), the module events are skipped if empty")
+	VerifyKernel.Flags().DurationVar(&options.Harness.Timeout, "timeout", 5*time.Second, "maximum time to wait for the event of a trigger")
+	VerifyKernel.Flags().StringSliceVar(&options.Triggers, "trigger", nil, "trigger to run (can be repeated), all the triggers are run if empty")
+	VerifyKernel.Flags().StringVar(&options.Output, "output", "", "report file (defaults to the standard output)")
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifykernel
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Gui774ume/krie/pkg/krie"
+	krietesting "github.com/Gui774ume/krie/pkg/testing"
+)
+
+// CLIOptions are the command line options of the verify-kernel command
+type CLIOptions struct {
+	Config   string
+	Triggers []string
+	Output   string
+	Harness  krietesting.HarnessOptions
+}
+
+func verifyKernelCmd(cmd *cobra.Command, args []string) error {
+	config, err := krie.LoadOptions(options.Config)
+	if err != nil {
+		return err
+	}
+	logrus.SetLevel(logrus.Level(config.LogLevel))
+
+	triggers, err := krietesting.SelectTriggers(options.Triggers)
+	if err != nil {
+		return err
+	}
+
+	report := krietesting.VerifyKernel(config, options.Harness, triggers)
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't marshal report: %w", err)
+	}
+	data = append(data, '\n')
+	if len(options.Output) > 0 {
+		if err = os.WriteFile(options.Output, data, 0644); err != nil {
+			return fmt.Errorf("couldn't write report: %w", err)
+		}
+	} else if _, err = os.Stdout.Write(data); err != nil {
+		return err
+	}
+
+	if !report.Compatible {
+		return fmt.Errorf("kernel %s isn't compatible: %d/%d trigger(s) failed%s", report.Kernel.Release, report.Failed, len(report.Triggers), reportError(report))
+	}
+	return nil
+}
+
+func reportError(report *krietesting.KernelReport) string {
+	if len(report.Error) == 0 {
+		return ""
+	}
+	return ", " + report.Error
+}
@@ -11,9 +11,12 @@
 #define CGROUP_MAX_LENGTH 128
 #define TASK_COMM_LEN 16
 #define MODULE_NAME_LEN 56
+#define MODULE_FILE_NAME_LEN 64
 #define BPF_OBJ_NAME_LEN 16
+#define BPF_VERIFIER_LOG_LEN 512
 #define BPF_TAG_SIZE 8
 #define SYMBOL_NAME_LENGTH 64
+#define ARGS_MAX_LENGTH 256
 
 #define PREPARE_KERNEL_CRED_HOOK 0
 #define COMMIT_CREDS_HOOK        1
@@ -64,4 +67,38 @@ __attribute__((always_inline)) u64 get_kernel_parameter_count() {
     return kernel_parameter_count;
 };
 
+__attribute__((always_inline)) u64 get_control_register_cr4_mask() {
+    u64 control_register_cr4_mask;
+    LOAD_CONSTANT("control_register_cr4_mask", control_register_cr4_mask);
+    return control_register_cr4_mask;
+};
+
+__attribute__((always_inline)) u64 get_krie_capture_args() {
+    u64 krie_capture_args;
+    LOAD_CONSTANT("krie_capture_args", krie_capture_args);
+    return krie_capture_args;
+};
+
+// get_krie_self_events returns 1 if the events of KRIE and of the processes it starts are reported
+__attribute__((always_inline)) u64 get_krie_self_events() {
+    u64 krie_self_events;
+    LOAD_CONSTANT("krie_self_events", krie_self_events);
+    return krie_self_events;
+};
+
+// get_syscall_correlation_timeout returns the number of nanoseconds after which a cached syscall entry without exit is
+// an orphan, 0 disables the expiration
+__attribute__((always_inline)) u64 get_syscall_correlation_timeout() {
+    u64 syscall_correlation_timeout;
+    LOAD_CONSTANT("syscall_correlation_timeout", syscall_correlation_timeout);
+    return syscall_correlation_timeout;
+};
+
+// get_krie_probe_origin returns the identifier of the program, the constant is edited per program at load time
+__attribute__((always_inline)) u32 get_krie_probe_origin() {
+    u64 krie_probe_origin;
+    LOAD_CONSTANT("krie_probe_origin", krie_probe_origin);
+    return krie_probe_origin;
+};
+
 #endif
@@ -26,6 +26,51 @@ enum event_type
     EVENT_KERNEL_PARAMETER,
     EVENT_PERIODIC_KERNEL_PARAMETER,
     EVENT_REGISTER_CHECK,
+
+    // KRIE user space events
+    EVENT_RESPONSE,
+    EVENT_POLICY_CHANGE,
+    EVENT_RAW,
+    EVENT_CLOCK_SKEW,
+    EVENT_SILENCE,
+    EVENT_TAIL_CALL_TAMPER,
+    EVENT_INTEGRITY_SWITCH,
+    EVENT_SYSCTL_DRIFT,
+    EVENT_KEXEC,
+    EVENT_COVERAGE_CHANGE,
+    EVENT_CONTROL_REGISTER,
+    EVENT_PROCESS_EXIT,
+    EVENT_FTRACE_HOOK,
+    EVENT_CRED_CHANGE,
+    EVENT_LSM_STATE_CHANGE,
+    EVENT_ENRICHMENT,
+    EVENT_IO_URING,
+    EVENT_BPF_PROGRAM_UNLOAD,
+    EVENT_PERF_EVENT_OPEN,
+    EVENT_RAW_MEMORY_ACCESS,
+    EVENT_MODULE_TAINT_CHANGE,
+    EVENT_MSR_WRITE,
+    EVENT_SYMBOL_RESOLUTION,
+    EVENT_USERMODE_HELPER,
+    EVENT_PROCESS_VM,
+    EVENT_MOUNT,
+    EVENT_PRCTL,
+    EVENT_PROFILE_SNAPSHOT,
+    EVENT_CAPSET,
+    EVENT_BPF_MAP_TAMPER,
+    EVENT_TRACEFS_PROBE,
+    EVENT_NETFILTER_HOOK,
+    EVENT_KERNEL_LOG_TAMPER,
+    EVENT_UPGRADE,
+    EVENT_KEYRING,
+    EVENT_NAMESPACE,
+    EVENT_SYSCALL_ORPHANS,
+    EVENT_USERFAULTFD,
+    EVENT_BINFMT_MISC,
+    EVENT_MODULE_SIGNATURE,
+    EVENT_REBOOT,
+    EVENT_HIDDEN_PROCESS,
+    EVENT_ANNOTATION,
     EVENT_MAX, // has to be the last one
 };
 
@@ -35,7 +80,7 @@ struct kernel_event_t {
     u32 cpu;
     u32 type;
     u32 action;
-    u32 padding;
+    u32 origin;
 };
 
 struct perf_map_stats_t {
@@ -48,17 +93,61 @@ struct {
 	__uint(type, BPF_MAP_TYPE_PERF_EVENT_ARRAY);
 } events SEC(".maps");
 
+// events_shard_[1-3] are used to route specific event types to dedicated ring buffers, shard 0 is the "events" map
+struct {
+	__uint(type, BPF_MAP_TYPE_PERF_EVENT_ARRAY);
+} events_shard_1 SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_PERF_EVENT_ARRAY);
+} events_shard_2 SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_PERF_EVENT_ARRAY);
+} events_shard_3 SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_ARRAY);
+	__type(key, u32);
+	__type(value, u32);
+	__uint(max_entries, EVENT_MAX);
+} event_shards SEC(".maps");
+
+__attribute__((always_inline)) u32 get_event_shard(u32 event_type) {
+    u32 *shard = bpf_map_lookup_elem(&event_shards, &event_type);
+    if (shard == NULL) {
+        return 0;
+    }
+    return *shard;
+}
+
+__attribute__((always_inline)) int output_event(void *ctx, u32 event_type, u32 cpu, void *data, u64 size) {
+    // record the program sending the event, all the events start with a kernel_event_t
+    ((struct kernel_event_t *)data)->origin = get_krie_probe_origin();
+
+    switch (get_event_shard(event_type)) {
+        case 1:
+            return bpf_perf_event_output(ctx, &events_shard_1, cpu, data, size);
+        case 2:
+            return bpf_perf_event_output(ctx, &events_shard_2, cpu, data, size);
+        case 3:
+            return bpf_perf_event_output(ctx, &events_shard_3, cpu, data, size);
+        default:
+            return bpf_perf_event_output(ctx, &events, cpu, data, size);
+    }
+}
+
 #define send_event_with_size_ptr_perf(ctx, event_type, kernel_event, kernel_event_size)                                \
     kernel_event->event.type = event_type;                                                                             \
     kernel_event->event.cpu = bpf_get_smp_processor_id();                                                              \
     kernel_event->event.timestamp = bpf_ktime_get_ns();                                                                \
-    perf_ret = bpf_perf_event_output(ctx, &events, kernel_event->event.cpu, kernel_event, kernel_event_size);          \
+    perf_ret = output_event(ctx, event_type, kernel_event->event.cpu, kernel_event, kernel_event_size);                \
 
 #define send_event_with_size_perf(ctx, event_type, kernel_event, kernel_event_size)                                    \
     kernel_event.event.type = event_type;                                                                              \
     kernel_event.event.cpu = bpf_get_smp_processor_id();                                                               \
     kernel_event.event.timestamp = bpf_ktime_get_ns();                                                                 \
-    perf_ret = bpf_perf_event_output(ctx, &events, kernel_event.event.cpu, &kernel_event, kernel_event_size);          \
+    perf_ret = output_event(ctx, event_type, kernel_event.event.cpu, &kernel_event, kernel_event_size);                \
 
 #define send_event(ctx, event_type, kernel_event)                                                                      \
     u64 size = sizeof(kernel_event);                                                                                   \
@@ -8,18 +8,39 @@
 #ifndef _FILTER_KRIE_RUNTIME_H_
 #define _FILTER_KRIE_RUNTIME_H_
 
+// is_krie_runtime returns 1 if the current process is KRIE, or a process started by KRIE (the commands of the
+// responders, the helpers)
+int __attribute__((always_inline)) is_krie_runtime() {
+    u32 krie_pid = (u32)get_krie_pid();
+    if ((bpf_get_current_pid_tgid() >> 32) == krie_pid) {
+        return 1;
+    }
+    struct task_struct *task = (struct task_struct *)bpf_get_current_task();
+    return BPF_CORE_READ(task, real_parent, tgid) == krie_pid;
+};
+
+// filter_krie_runtime_with_pid returns 1 if the provided process is never blocked nor killed
 int __attribute__((always_inline)) filter_krie_runtime_with_pid(u32 pid) {
     // filter pid
     if (pid == (u32)get_krie_pid() || pid == 1 || pid == 0) {
         return 1;
     }
+    // filter the processes started by KRIE
+    if (pid == (bpf_get_current_pid_tgid() >> 32)) {
+        return is_krie_runtime();
+    }
     return 0;
 };
 
+// filter_krie_runtime returns 1 if the events of the current process are dropped: the events of KRIE and of the
+// processes it starts are self-generated, they are only reported when krie_self_events is set
 int __attribute__((always_inline)) filter_krie_runtime() {
     // filter pid
     u32 pid = bpf_get_current_pid_tgid() >> 32;
-    return filter_krie_runtime_with_pid(pid);
+    if (pid == 1 || pid == 0) {
+        return 1;
+    }
+    return !get_krie_self_events() && is_krie_runtime();
 };
 
 #endif
\ No newline at end of file
@@ -12,11 +12,36 @@
 #include "bpf.h"
 #include "setsockopt.h"
 #include "ptrace.h"
+#include "kexec.h"
 #include "kprobe.h"
 #include "sysctl.h"
 #include "raw_syscalls.h"
 #include "krie_ticker.h"
 #include "credentials.h"
+#include "integrity_switch.h"
+#include "control_register.h"
+#include "ftrace_hook.h"
+#include "lsm_state.h"
+#include "io_uring.h"
+#include "perf_event.h"
+#include "raw_memory.h"
+#include "msr.h"
+#include "symbol_resolution.h"
+#include "usermode_helper.h"
+#include "process_vm.h"
+#include "mount.h"
+#include "prctl.h"
+#include "capset.h"
+#include "tracefs.h"
+#include "netfilter.h"
+#include "kernel_log.h"
+#include "keyring.h"
+#include "namespace.h"
+#include "userfaultfd.h"
+#include "binfmt_misc.h"
+#include "module_signature.h"
+#include "reboot.h"
+#include "hidden_process.h"
 #include "lsm.h"
 
 #endif
\ No newline at end of file
@@ -0,0 +1,62 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _BINFMT_MISC_H_
+#define _BINFMT_MISC_H_
+
+#define BINFMT_MISC_DEFINITION_LEN 256
+
+struct binfmt_misc_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u32 size;
+    u32 padding;
+    char definition[BINFMT_MISC_DEFINITION_LEN];
+};
+
+memory_factory(binfmt_misc_event)
+
+// bm_register_write parses the writes to /proc/sys/fs/binfmt_misc/register: the registered interpreter is executed by
+// the kernel for every matching binary, whoever runs it
+SEC("kprobe/bm_register_write")
+int BPF_KPROBE(kprobe_bm_register_write, struct file *file, const char *buffer, size_t count) {
+    struct binfmt_misc_event_t *event = new_binfmt_misc_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    event->event.type = EVENT_BINFMT_MISC;
+    event->size = count;
+
+    // the definition is truncated to BINFMT_MISC_DEFINITION_LEN - 1, size reports the length of the write
+    u32 len = count;
+    if (len >= BINFMT_MISC_DEFINITION_LEN) {
+        len = BINFMT_MISC_DEFINITION_LEN - 1;
+    }
+    len &= (BINFMT_MISC_DEFINITION_LEN - 1);
+    bpf_probe_read_user(&event->definition[0], len, buffer);
+    event->definition[len & (BINFMT_MISC_DEFINITION_LEN - 1)] = 0;
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+
+    return krie_kprobe_enforce_policy(ctx, &event->process, event->event.action);
+};
+
+#endif
@@ -37,10 +37,29 @@ struct bpf_event_t {
     struct bpf_prog_t prog;
     int cmd;
     u32 padding;
+    char verifier_log[BPF_VERIFIER_LOG_LEN];
 };
 
 memory_factory(bpf_event)
 
+struct bpf_map_owner_t {
+    u32 pid;
+    u32 padding;
+    char comm[TASK_COMM_LEN];
+};
+
+struct bpf_map_tamper_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    struct bpf_map_t map;
+    struct bpf_map_owner_t owner;
+    int cmd;
+    u32 krie_map;
+};
+
+memory_factory(bpf_map_tamper_event)
+
 struct {
     __uint(type, BPF_MAP_TYPE_LRU_HASH);
     __type(key, u32);
@@ -69,34 +88,35 @@ struct {
     __uint(max_entries, 4096);
 } tgid_fd_prog_id SEC(".maps");
 
-SYSCALL_KPROBE3(bpf, int, cmd, union bpf_attr_def*, uattr, unsigned int, size) {
-    struct syscall_cache_t syscall = {
-        .type = EVENT_BPF,
-        .bpf = {
-            .cmd = cmd,
-        }
-    };
-    bpf_probe_read(&syscall.bpf.attr, sizeof(syscall.bpf.attr), &uattr);
-
-    cache_syscall(&syscall);
+// processes which created the maps, the maps of KRIE are pushed from user space
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, u32);
+    __type(value, struct bpf_map_owner_t);
+    __uint(max_entries, 4096);
+} bpf_map_owners SEC(".maps");
 
-    // create process context for KRIE detection
-    struct bpf_event_t *event = new_bpf_event();
-    if (event == NULL) {
-        // should never happen
-        return 0;
+__attribute__((always_inline)) int is_map_write(int cmd) {
+    switch (cmd) {
+    case BPF_MAP_UPDATE_ELEM:
+    case BPF_MAP_DELETE_ELEM:
+    case BPF_MAP_UPDATE_BATCH:
+    case BPF_MAP_DELETE_BATCH:
+        return 1;
     }
-    fill_process_context(&event->process);
-
-    // we're about to allow this call to go through, double check with KRIE
-    u32 action = krie_run_event_check(ctx, &event->process, &syscall.type);
+    return 0;
+}
 
-    // pop cache if need be
-    if (action > KRIE_ACTION_LOG) {
-        pop_syscall(EVENT_BPF);
+// get_foreign_map_owner returns the owner of a map created by another process, or NULL
+__attribute__((always_inline)) struct bpf_map_owner_t *get_foreign_map_owner(u32 map_id) {
+    if (map_id == 0) {
+        return NULL;
     }
-
-    return krie_syscall_kprobe_enforce_policy(ctx, &event->process, action);
+    struct bpf_map_owner_t *owner = bpf_map_lookup_elem(&bpf_map_owners, &map_id);
+    if (owner == NULL || owner->pid == bpf_get_current_pid_tgid() >> 32) {
+        return NULL;
+    }
+    return owner;
 }
 
 __attribute__((always_inline)) void save_obj_fd(struct syscall_cache_t *syscall) {
@@ -224,6 +244,48 @@ __attribute__((always_inline)) void populate_map_id_and_prog_id(struct syscall_c
     }
 }
 
+SYSCALL_KPROBE3(bpf, int, cmd, union bpf_attr_def*, uattr, unsigned int, size) {
+    struct syscall_cache_t syscall = {
+        .type = EVENT_BPF,
+        .bpf = {
+            .cmd = cmd,
+        }
+    };
+    bpf_probe_read(&syscall.bpf.attr, sizeof(syscall.bpf.attr), &uattr);
+
+    cache_syscall(&syscall);
+
+    // create process context for KRIE detection
+    struct bpf_event_t *event = new_bpf_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    fill_process_context(&event->process);
+
+    // we're about to allow this call to go through, double check with KRIE
+    u32 action = krie_run_event_check(ctx, &event->process, &syscall.type);
+
+    // a write to a map created by another process is also checked against the policy of the tamper events
+    if (is_map_write(cmd)) {
+        populate_map_id_and_prog_id(&syscall);
+        if (get_foreign_map_owner(syscall.bpf.map_id) != NULL) {
+            u64 tamper_type = EVENT_BPF_MAP_TAMPER;
+            u32 tamper_action = krie_run_event_check(ctx, &event->process, &tamper_type);
+            if (tamper_action > action) {
+                action = tamper_action;
+            }
+        }
+    }
+
+    // pop cache if need be
+    if (action > KRIE_ACTION_LOG) {
+        pop_syscall(EVENT_BPF);
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, &event->process, action);
+}
+
 __attribute__((always_inline)) void fill_from_syscall_args(struct syscall_cache_t *syscall, struct bpf_event_t *event) {
     switch (event->cmd) {
     case BPF_MAP_CREATE:
@@ -238,6 +300,20 @@ __attribute__((always_inline)) void fill_from_syscall_args(struct syscall_cache_
     }
 }
 
+__attribute__((always_inline)) void fill_verifier_log(struct syscall_cache_t *syscall, struct bpf_event_t *event) {
+    u32 log_level = 0;
+    u64 log_buf = 0;
+    bpf_probe_read(&log_level, sizeof(log_level), &syscall->bpf.attr->log_level);
+    bpf_probe_read(&log_buf, sizeof(log_buf), &syscall->bpf.attr->log_buf);
+    if (log_level == 0 || log_buf == 0) {
+        // the caller didn't ask for a verifier log
+        return;
+    }
+
+    // the verifier wrote its log to the buffer of the caller before the syscall returned
+    bpf_probe_read_user_str(&event->verifier_log, sizeof(event->verifier_log), (void *)log_buf);
+}
+
 __attribute__((always_inline)) struct process_context_t *send_bpf_event(void *ctx, struct syscall_cache_t *syscall, u32 *action) {
     struct bpf_event_t *event = new_bpf_event();
     if (event == NULL) {
@@ -275,6 +351,11 @@ __attribute__((always_inline)) struct process_context_t *send_bpf_event(void *ct
         fill_from_syscall_args(syscall, event);
     }
 
+    if (event->cmd == BPF_PROG_LOAD && syscall->bpf.retval < 0) {
+        // the program was rejected, capture the verifier log to explain why
+        fill_verifier_log(syscall, event);
+    }
+
     // filter krie runtime
     if (filter_krie_runtime()) {
         return 0;
@@ -290,6 +371,47 @@ __attribute__((always_inline)) struct process_context_t *send_bpf_event(void *ct
     return &event->process;
 }
 
+__attribute__((always_inline)) u32 send_bpf_map_tamper_event(void *ctx, struct syscall_cache_t *syscall) {
+    struct bpf_map_owner_t *owner = get_foreign_map_owner(syscall->bpf.map_id);
+    if (owner == NULL) {
+        return KRIE_ACTION_NOP;
+    }
+
+    struct bpf_map_tamper_event_t *event = new_bpf_map_tamper_event();
+    if (event == NULL) {
+        // should never happen
+        return KRIE_ACTION_NOP;
+    }
+    event->event.type = EVENT_BPF_MAP_TAMPER;
+    event->event.retval = syscall->bpf.retval;
+    event->cmd = syscall->bpf.cmd;
+    event->owner = *owner;
+    event->krie_map = owner->pid == (u32)get_krie_pid();
+
+    u32 id = syscall->bpf.map_id;
+    struct bpf_map_t *map = bpf_map_lookup_elem(&bpf_maps, &id);
+    if (map != NULL) {
+        event->map = *map;
+    } else {
+        event->map.id = id;
+    }
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return KRIE_ACTION_NOP;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+    return event->event.action;
+}
+
 __attribute__((always_inline)) struct process_context_t *sys_bpf_ret(void *ctx, int retval, u32 *action) {
     struct syscall_cache_t *syscall = pop_syscall(EVENT_BPF);
     if (!syscall) {
@@ -306,8 +428,18 @@ __attribute__((always_inline)) struct process_context_t *sys_bpf_ret(void *ctx,
     // populate map_id or prog_id if applicable
     populate_map_id_and_prog_id(syscall);
 
+    // report the writes to the maps created by another process
+    u32 tamper_action = KRIE_ACTION_NOP;
+    if (is_map_write(syscall->bpf.cmd)) {
+        tamper_action = send_bpf_map_tamper_event(ctx, syscall);
+    }
+
     // send monitoring event
-    return send_bpf_event(ctx, syscall, action);
+    struct process_context_t *process_ctx = send_bpf_event(ctx, syscall, action);
+    if (tamper_action > *action) {
+        *action = tamper_action;
+    }
+    return process_ctx;
 }
 
 SYSCALL_KRETPROBE(bpf) {
@@ -353,6 +485,15 @@ int BPF_KPROBE(kprobe_security_bpf_map, struct bpf_map *map) {
     // save map metadata
     bpf_map_update_elem(&bpf_maps, &m.id, &m, BPF_ANY);
 
+    // the process creating the map owns it
+    if (syscall->bpf.cmd == BPF_MAP_CREATE) {
+        struct bpf_map_owner_t owner = {
+            .pid = bpf_get_current_pid_tgid() >> 32,
+        };
+        bpf_get_current_comm(&owner.comm, sizeof(owner.comm));
+        bpf_map_update_elem(&bpf_map_owners, &m.id, &owner, BPF_ANY);
+    }
+
     // update context
     syscall->bpf.map_id = m.id;
     return 0;
@@ -0,0 +1,152 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _CAPSET_H_
+#define _CAPSET_H_
+
+#define CAPSET_LINUX_CAPABILITY_VERSION_1 0x19980330
+
+#define CAPSET_CAP_SYS_MODULE 16
+#define CAPSET_CAP_SYS_RAWIO 17
+#define CAPSET_CAP_PERFMON 38
+#define CAPSET_CAP_BPF 39
+
+struct capset_user_header_t {
+    u32 version;
+    int pid;
+};
+
+struct capset_user_data_t {
+    u32 effective;
+    u32 permitted;
+    u32 inheritable;
+};
+
+struct capset_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u64 effective_before;
+    u64 permitted_before;
+    u64 inheritable_before;
+    u64 effective_after;
+    u64 permitted_after;
+    u64 inheritable_after;
+};
+
+memory_factory(capset_event)
+
+SYSCALL_KPROBE2(capset, void *, header, void *, data) {
+    if (data == NULL) {
+        return 0;
+    }
+
+    struct syscall_cache_t syscall = {
+        .type = EVENT_CAPSET,
+    };
+
+    // the 64 bits capability sets are split in two 32 bits structures since _LINUX_CAPABILITY_VERSION_2
+    struct capset_user_header_t hdr = {};
+    bpf_probe_read_user(&hdr, sizeof(hdr), header);
+    struct capset_user_data_t caps[2] = {};
+    bpf_probe_read_user(&caps[0], sizeof(caps[0]), data);
+    if (hdr.version != CAPSET_LINUX_CAPABILITY_VERSION_1) {
+        bpf_probe_read_user(&caps[1], sizeof(caps[1]), (struct capset_user_data_t *)data + 1);
+    }
+    syscall.capset.effective_after = caps[0].effective | ((u64)caps[1].effective << 32);
+    syscall.capset.permitted_after = caps[0].permitted | ((u64)caps[1].permitted << 32);
+    syscall.capset.inheritable_after = caps[0].inheritable | ((u64)caps[1].inheritable << 32);
+
+    // create process context for KRIE detection
+    struct capset_event_t *event = new_capset_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    fill_process_context(&event->process);
+    __builtin_memcpy(&syscall.capset.effective_before, &event->process.credentials.cap_effective, sizeof(u64));
+    __builtin_memcpy(&syscall.capset.permitted_before, &event->process.credentials.cap_permitted, sizeof(u64));
+    __builtin_memcpy(&syscall.capset.inheritable_before, &event->process.credentials.cap_inheritable, sizeof(u64));
+
+    // only the calls granting a sensitive capability are monitored, capset is mostly used to drop capabilities
+    u64 sensitive = (1ULL << CAPSET_CAP_SYS_MODULE) | (1ULL << CAPSET_CAP_SYS_RAWIO) | (1ULL << CAPSET_CAP_PERFMON) | (1ULL << CAPSET_CAP_BPF);
+    u64 gained = (syscall.capset.effective_after & ~syscall.capset.effective_before) |
+        (syscall.capset.permitted_after & ~syscall.capset.permitted_before);
+    if ((gained & sensitive) == 0) {
+        return 0;
+    }
+    cache_syscall(&syscall);
+
+    // we're about to allow this call to go through, double check with KRIE
+    u32 action = krie_run_event_check(ctx, &event->process, &syscall.type);
+
+    // pop cache if need be
+    if (action > KRIE_ACTION_LOG) {
+        pop_syscall(EVENT_CAPSET);
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, &event->process, action);
+}
+
+__attribute__((always_inline)) struct process_context_t *sys_capset_ret(void *ctx, int retval, u32 *action) {
+    struct syscall_cache_t *syscall = pop_syscall(EVENT_CAPSET);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct capset_event_t *event = new_capset_event();
+    if (event == NULL) {
+        // ignore, should not happen
+        return 0;
+    }
+    event->event.type = EVENT_CAPSET;
+    event->event.retval = retval;
+    event->effective_before = syscall->capset.effective_before;
+    event->permitted_before = syscall->capset.permitted_before;
+    event->inheritable_before = syscall->capset.inheritable_before;
+    event->effective_after = syscall->capset.effective_after;
+    event->permitted_after = syscall->capset.permitted_after;
+    event->inheritable_after = syscall->capset.inheritable_after;
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+    *action = event->event.action;
+
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+    return &event->process;
+}
+
+SYSCALL_KRETPROBE(capset) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_capset_ret(ctx, (int)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SEC("tracepoint/handle_sys_capset_exit")
+int tracepoint_handle_sys_capset_exit(struct tracepoint_raw_syscalls_sys_exit_t *args) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_capset_ret(args, args->ret, &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_tp_enforce_policy(args, process_ctx, action);
+}
+
+#endif
@@ -0,0 +1,97 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _CONTROL_REGISTER_H_
+#define _CONTROL_REGISTER_H_
+
+#define CONTROL_REGISTER_CR0 0
+#define CONTROL_REGISTER_CR4 4
+
+#define X86_CR0_WP   (1UL << 16)
+#define X86_CR4_SMEP (1UL << 20)
+#define X86_CR4_SMAP (1UL << 21)
+
+struct control_register_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u64 old_value;
+    u64 new_value;
+    u32 reg;
+    u32 old_value_known;
+};
+
+memory_factory(control_register_event)
+
+struct control_register_shadow_t {
+    u64 value;
+    u32 known;
+    u32 padding;
+};
+
+// last value written to each control register, per CPU
+struct {
+	__uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+	__type(key, u32);
+	__type(value, struct control_register_shadow_t);
+	__uint(max_entries, 5);
+} control_register_shadows SEC(".maps");
+
+__attribute__((always_inline)) int control_register_check(struct pt_regs *ctx, u32 reg, u64 value, u64 sensitive_bits) {
+    struct control_register_shadow_t *shadow = bpf_map_lookup_elem(&control_register_shadows, &reg);
+    if (shadow == NULL) {
+        // should never happen
+        return 0;
+    }
+    u64 old_value = shadow->value;
+    u32 old_value_known = shadow->known;
+    shadow->value = value;
+    shadow->known = 1;
+
+    // only the writes that flip a sensitive bit are reported, the first write seen on a CPU is reported if it clears one
+    if (old_value_known) {
+        if ((old_value & sensitive_bits) == (value & sensitive_bits)) {
+            return 0;
+        }
+    } else if ((value & sensitive_bits) == sensitive_bits) {
+        return 0;
+    }
+
+    struct control_register_event_t *event = new_control_register_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    event->event.type = EVENT_CONTROL_REGISTER;
+    event->old_value = old_value;
+    event->new_value = value;
+    event->reg = reg;
+    event->old_value_known = old_value_known;
+    fill_process_context(&event->process);
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+
+    return krie_kprobe_enforce_policy(ctx, &event->process, event->event.action);
+};
+
+SEC("kprobe/native_write_cr0")
+int BPF_KPROBE(kprobe_native_write_cr0, unsigned long val) {
+    return control_register_check(ctx, CONTROL_REGISTER_CR0, val, X86_CR0_WP);
+};
+
+SEC("kprobe/native_write_cr4")
+int BPF_KPROBE(kprobe_native_write_cr4, unsigned long val) {
+    // only the protections supported by the CPU are checked
+    return control_register_check(ctx, CONTROL_REGISTER_CR4, val, get_control_register_cr4_mask());
+};
+
+#endif
@@ -21,8 +21,120 @@ int BPF_KPROBE(kprobe_prepare_kernel_cred) {
     return krie_perf_enforce_policy(ctx, process_ctx, action);
 };
 
+#define CRED_CHANGE_ROOT       (1 << 0)
+#define CRED_CHANGE_CAPABILITY (1 << 1)
+
+#define CAP_SETUID     7
+#define CAP_SYS_MODULE 16
+#define CAP_SYS_ADMIN  21
+
+struct cred_change_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u32 uid;
+    u32 gid;
+    u32 euid;
+    u32 egid;
+    u32 suid;
+    u32 fsuid;
+    u64 cap_permitted;
+    u64 cap_effective;
+    u32 reason;
+    u32 padding;
+};
+
+memory_factory(cred_change_event)
+
+// threads committing the credentials of an exec, the credentials of a setuid exec are expected to change
+struct {
+	__uint(type, BPF_MAP_TYPE_LRU_HASH);
+	__type(key, u64);
+	__type(value, u8);
+	__uint(max_entries, 1024);
+} exec_cred_commits SEC(".maps");
+
+SEC("kprobe/security_bprm_committing_creds")
+int kprobe_security_bprm_committing_creds(struct pt_regs *ctx) {
+    u64 id = bpf_get_current_pid_tgid();
+    u8 committing = 1;
+    bpf_map_update_elem(&exec_cred_commits, &id, &committing, BPF_ANY);
+    return 0;
+};
+
+SEC("kprobe/security_bprm_committed_creds")
+int kprobe_security_bprm_committed_creds(struct pt_regs *ctx) {
+    u64 id = bpf_get_current_pid_tgid();
+    bpf_map_delete_elem(&exec_cred_commits, &id);
+    return 0;
+};
+
+__attribute__((always_inline)) u32 cred_change_check(struct pt_regs *ctx, struct process_context_t *old, struct cred *new) {
+    u64 id = bpf_get_current_pid_tgid();
+    if (bpf_map_lookup_elem(&exec_cred_commits, &id) != NULL) {
+        // setuid exec
+        return KRIE_ACTION_NOP;
+    }
+
+    // the capabilities of a new user namespace are only effective in this namespace
+    u32 user_ns = 0;
+    BPF_CORE_READ_INTO(&user_ns, new, user_ns, ns.inum);
+    if (user_ns != old->namespaces.user_namespace) {
+        return KRIE_ACTION_NOP;
+    }
+
+    struct cred_change_event_t *event = new_cred_change_event();
+    if (event == NULL) {
+        // should never happen
+        return KRIE_ACTION_NOP;
+    }
+    BPF_CORE_READ_INTO(&event->uid, new, uid);
+    BPF_CORE_READ_INTO(&event->gid, new, gid);
+    BPF_CORE_READ_INTO(&event->euid, new, euid);
+    BPF_CORE_READ_INTO(&event->egid, new, egid);
+    BPF_CORE_READ_INTO(&event->suid, new, suid);
+    BPF_CORE_READ_INTO(&event->fsuid, new, fsuid);
+    BPF_CORE_READ_INTO(&event->cap_permitted, new, cap_permitted);
+    BPF_CORE_READ_INTO(&event->cap_effective, new, cap_effective);
+
+    u64 old_permitted = 0;
+    __builtin_memcpy(&old_permitted, &old->credentials.cap_permitted, sizeof(old_permitted));
+    u32 old_uid = 0, old_euid = 0, old_suid = 0;
+    __builtin_memcpy(&old_uid, &old->credentials.uid, sizeof(old_uid));
+    __builtin_memcpy(&old_euid, &old->credentials.euid, sizeof(old_euid));
+    __builtin_memcpy(&old_suid, &old->credentials.suid, sizeof(old_suid));
+
+    // setuid(0) and friends can only switch to root when one of the current uids is root, or with CAP_SETUID
+    event->reason = 0;
+    if ((event->uid == 0 || event->euid == 0 || event->suid == 0 || event->fsuid == 0) &&
+        old_uid != 0 && old_euid != 0 && old_suid != 0 && (old_permitted & (1ULL << CAP_SETUID)) == 0) {
+        event->reason |= CRED_CHANGE_ROOT;
+    }
+
+    // capset can only raise the effective capabilities within the permitted ones
+    u64 sensitive = (1ULL << CAP_SYS_MODULE) | (1ULL << CAP_SYS_ADMIN);
+    if (((event->cap_permitted | event->cap_effective) & sensitive & ~old_permitted) != 0) {
+        event->reason |= CRED_CHANGE_CAPABILITY;
+    }
+
+    if (event->reason == 0) {
+        return KRIE_ACTION_NOP;
+    }
+
+    event->event.type = EVENT_CRED_CHANGE;
+    copy_process_ctx(&event->process, old);
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+    return event->event.action;
+};
+
 SEC("kprobe/commit_creds")
-int BPF_KPROBE(kprobe_commit_creds) {
+int BPF_KPROBE(kprobe_commit_creds, struct cred *new) {
     struct process_context_t *process_ctx = new_process_context();
     if (process_ctx == NULL) {
         // should never happen, ignore
@@ -31,6 +143,10 @@ int BPF_KPROBE(kprobe_commit_creds) {
     fill_process_context(process_ctx);
 
     u32 action = run_task_check(ctx, process_ctx, COMMIT_CREDS_HOOK);
+    u32 cred_action = cred_change_check(ctx, process_ctx, new);
+    if (cred_action > action) {
+        action = cred_action;
+    }
     return krie_perf_enforce_policy(ctx, process_ctx, action);
 };
 
@@ -0,0 +1,83 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _FTRACE_HOOK_H_
+#define _FTRACE_HOOK_H_
+
+#define REGISTER_FTRACE_FUNCTION 1
+#define UNREGISTER_FTRACE_FUNCTION 2
+#define FTRACE_SET_FILTER_IP 3
+
+struct ftrace_hook_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u64 ops;
+    u64 func;
+    u64 ip;
+    u32 cmd;
+    u32 remove;
+};
+
+memory_factory(ftrace_hook_event)
+
+__attribute__((always_inline)) int ftrace_hook_check(struct pt_regs *ctx, struct ftrace_ops *ops, u32 cmd, u64 ip, u32 remove) {
+    u64 func = 0;
+    BPF_CORE_READ_INTO(&func, ops, func);
+
+    // the callbacks of the core kernel (function tracer, kprobes, BPF trampolines, livepatch, ...) are expected, a
+    // callback outside of the kernel text belongs to a module, which is what an ftrace based rootkit looks like
+    u64 _stext = (u64)get_kallsyms_addr(KALLSYMS_STEXT);
+    u64 _etext = (u64)get_kallsyms_addr(KALLSYMS_ETEXT);
+    if (func > _stext && func < _etext) {
+        return 0;
+    }
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    struct ftrace_hook_event_t *event = new_ftrace_hook_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    event->event.type = EVENT_FTRACE_HOOK;
+    event->ops = (u64)ops;
+    event->func = func;
+    event->ip = ip;
+    event->cmd = cmd;
+    event->remove = remove;
+    fill_process_context(&event->process);
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+
+    return krie_kprobe_enforce_policy(ctx, &event->process, event->event.action);
+};
+
+SEC("kprobe/register_ftrace_function")
+int BPF_KPROBE(kprobe_register_ftrace_function, struct ftrace_ops *ops) {
+    return ftrace_hook_check(ctx, ops, REGISTER_FTRACE_FUNCTION, 0, 0);
+};
+
+SEC("kprobe/unregister_ftrace_function")
+int BPF_KPROBE(kprobe_unregister_ftrace_function, struct ftrace_ops *ops) {
+    return ftrace_hook_check(ctx, ops, UNREGISTER_FTRACE_FUNCTION, 0, 0);
+};
+
+SEC("kprobe/ftrace_set_filter_ip")
+int BPF_KPROBE(kprobe_ftrace_set_filter_ip, struct ftrace_ops *ops, unsigned long ip, int remove) {
+    return ftrace_hook_check(ctx, ops, FTRACE_SET_FILTER_IP, ip, remove);
+};
+
+#endif
@@ -0,0 +1,42 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _HIDDEN_PROCESS_H_
+#define _HIDDEN_PROCESS_H_
+
+struct hidden_process_task_t {
+    u64 start_time;
+    u32 pid;
+    u32 ppid;
+    char comm[TASK_COMM_LEN];
+};
+
+// iter_task lists the thread group leaders known to the kernel, user space compares them with the entries of /proc to
+// find the processes hidden from procfs (a rootkit filtering the getdents of /proc doesn't hide them from the kernel)
+SEC("iter/task")
+int iter_task(struct bpf_iter__task *ctx) {
+    struct seq_file *seq = ctx->meta->seq;
+    struct task_struct *task = ctx->task;
+    if (task == NULL) {
+        return 0;
+    }
+
+    struct hidden_process_task_t entry = {};
+    entry.pid = BPF_CORE_READ(task, tgid);
+    if (BPF_CORE_READ(task, pid) != entry.pid) {
+        // /proc only lists the thread group leaders
+        return 0;
+    }
+    entry.ppid = BPF_CORE_READ(task, real_parent, tgid);
+    entry.start_time = BPF_CORE_READ(task, start_time);
+    BPF_CORE_READ_INTO(&entry.comm, task, comm);
+
+    bpf_seq_write(seq, &entry, sizeof(entry));
+    return 0;
+}
+
+#endif
@@ -0,0 +1,107 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _INTEGRITY_SWITCH_H_
+#define _INTEGRITY_SWITCH_H_
+
+#define SECURITYFS_MAGIC 0x73636673
+#define SYSFS_MAGIC      0x62656572
+
+#define MAY_WRITE  0x00000002
+#define MAY_APPEND 0x00000008
+
+#define INTEGRITY_SWITCH_PATH_DEPTH 4
+#define INTEGRITY_SWITCH_NAME_LEN   64
+
+struct integrity_switch_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u32 fs_magic;
+    u32 mask;
+    char names[INTEGRITY_SWITCH_PATH_DEPTH][INTEGRITY_SWITCH_NAME_LEN];
+};
+
+memory_factory(integrity_switch_event)
+
+__attribute__((always_inline)) int is_sig_enforce(char *name) {
+    char sig_enforce[] = "sig_enforce";
+
+#pragma unroll
+    for (int i = 0; i < sizeof(sig_enforce); i++) {
+        if (name[i] != sig_enforce[i]) {
+            return 0;
+        }
+    }
+    return 1;
+};
+
+__attribute__((always_inline)) int integrity_switch_check(void *ctx, struct file *file, int mask, u32 program_type) {
+    if ((mask & (MAY_WRITE | MAY_APPEND)) == 0) {
+        return 0;
+    }
+
+    // securityfs holds the IMA / EVM policies and the lockdown switch, sysfs holds module.sig_enforce
+    unsigned long magic = BPF_CORE_READ(file, f_inode, i_sb, s_magic);
+    if (magic != SECURITYFS_MAGIC && magic != SYSFS_MAGIC) {
+        return 0;
+    }
+
+    struct integrity_switch_event_t *event = new_integrity_switch_event();
+    if (event == NULL) {
+        // ignore, should never happen
+        return 0;
+    }
+
+    // collect the last components of the path, from the leaf up
+    struct dentry *dentry = BPF_CORE_READ(file, f_path.dentry);
+#pragma unroll
+    for (int i = 0; i < INTEGRITY_SWITCH_PATH_DEPTH; i++) {
+        bpf_probe_read_str(&event->names[i][0], INTEGRITY_SWITCH_NAME_LEN, BPF_CORE_READ(dentry, d_name.name));
+        dentry = BPF_CORE_READ(dentry, d_parent);
+    }
+
+    // the only sysfs entry we care about is module.sig_enforce
+    if (magic == SYSFS_MAGIC && !is_sig_enforce(&event->names[0][0])) {
+        return 0;
+    }
+
+    event->event.type = EVENT_INTEGRITY_SWITCH;
+    event->fs_magic = (u32)magic;
+    event->mask = mask;
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+
+    return enforce_policy(ctx, &event->process, event->event.action, program_type, SYMBOL_HOOK);
+};
+
+SEC("lsm/file_permission")
+int BPF_PROG(lsm_integrity_switch_file_permission, struct file *file, int mask, int ret) {
+    if (ret != 0) {
+        return ret;
+    }
+    return integrity_switch_check(ctx, file, mask, LSM_PROG);
+};
+
+// fallback used when BPF LSM isn't available: the write is reported but can't be blocked
+SEC("kprobe/security_file_permission")
+int BPF_KPROBE(kprobe_security_file_permission, struct file *file, int mask) {
+    return integrity_switch_check(ctx, file, mask, KPROBE_PROG);
+};
+
+#endif
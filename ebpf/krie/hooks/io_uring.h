@@ -0,0 +1,189 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _IO_URING_H_
+#define _IO_URING_H_
+
+#define IO_URING_SETUP 1
+#define IO_URING_REGISTER 2
+
+#define IORING_REGISTER_BUFFERS 0
+#define IO_URING_MAX_BUFFERS 4
+
+struct io_uring_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u64 buffers_addr[IO_URING_MAX_BUFFERS];
+    u64 buffers_len[IO_URING_MAX_BUFFERS];
+    u32 cmd;
+    u32 entries;
+    u32 flags;
+    u32 sq_thread_cpu;
+    u32 sq_thread_idle;
+    u32 features;
+    u32 fd;
+    u32 opcode;
+    u32 nr_args;
+    u32 padding;
+};
+
+memory_factory(io_uring_event)
+
+// io_uring_params_t mirrors the head of struct io_uring_params
+struct io_uring_params_t {
+    u32 sq_entries;
+    u32 cq_entries;
+    u32 flags;
+    u32 sq_thread_cpu;
+    u32 sq_thread_idle;
+    u32 features;
+};
+
+struct iovec_t {
+    u64 base;
+    u64 len;
+};
+
+int __attribute__((always_inline)) trace_io_uring(void *ctx, struct syscall_cache_t *syscall) {
+    cache_syscall(syscall);
+
+    // create process context for KRIE detection
+    struct io_uring_event_t *event = new_io_uring_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    fill_process_context(&event->process);
+
+    // we're about to allow this call to go through, double check with KRIE
+    u32 action = krie_run_event_check(ctx, &event->process, &syscall->type);
+
+    // pop cache if need be
+    if (action > KRIE_ACTION_LOG) {
+        pop_syscall(EVENT_IO_URING);
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, &event->process, action);
+}
+
+SYSCALL_KPROBE2(io_uring_setup, u32, entries, struct io_uring_params *, params) {
+    struct syscall_cache_t syscall = {
+        .type = EVENT_IO_URING,
+        .io_uring = {
+            .cmd = IO_URING_SETUP,
+            .entries = entries,
+            .params = params,
+        }
+    };
+    return trace_io_uring(ctx, &syscall);
+}
+
+SYSCALL_KPROBE4(io_uring_register, unsigned int, fd, unsigned int, opcode, void *, arg, unsigned int, nr_args) {
+    struct syscall_cache_t syscall = {
+        .type = EVENT_IO_URING,
+        .io_uring = {
+            .cmd = IO_URING_REGISTER,
+            .fd = fd,
+            .opcode = opcode,
+            .arg = arg,
+            .nr_args = nr_args,
+        }
+    };
+    return trace_io_uring(ctx, &syscall);
+}
+
+__attribute__((always_inline)) struct process_context_t *sys_io_uring_ret(void *ctx, int retval, u32 *action) {
+    struct syscall_cache_t *syscall = pop_syscall(EVENT_IO_URING);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct io_uring_event_t *event = new_io_uring_event();
+    if (event == NULL) {
+        // ignore, should not happen
+        return 0;
+    }
+    event->event.type = EVENT_IO_URING;
+    event->event.retval = retval;
+    event->cmd = syscall->io_uring.cmd;
+    event->entries = syscall->io_uring.entries;
+    event->fd = syscall->io_uring.fd;
+    event->opcode = syscall->io_uring.opcode;
+    event->nr_args = syscall->io_uring.nr_args;
+
+    if (event->cmd == IO_URING_SETUP && syscall->io_uring.params != NULL) {
+        // the features are set by the kernel on success
+        struct io_uring_params_t params = {};
+        bpf_probe_read(&params, sizeof(params), syscall->io_uring.params);
+        event->flags = params.flags;
+        event->sq_thread_cpu = params.sq_thread_cpu;
+        event->sq_thread_idle = params.sq_thread_idle;
+        event->features = params.features;
+    }
+
+    if (event->cmd == IO_URING_REGISTER && event->opcode == IORING_REGISTER_BUFFERS && syscall->io_uring.arg != NULL) {
+        struct iovec_t iov = {};
+#pragma unroll
+        for (int i = 0; i < IO_URING_MAX_BUFFERS; i++) {
+            if (i >= event->nr_args) {
+                break;
+            }
+            bpf_probe_read(&iov, sizeof(iov), (struct iovec_t *)syscall->io_uring.arg + i);
+            event->buffers_addr[i] = iov.base;
+            event->buffers_len[i] = iov.len;
+        }
+    }
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+    *action = event->event.action;
+
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+    return &event->process;
+}
+
+SYSCALL_KRETPROBE(io_uring_setup) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_io_uring_ret(ctx, (int)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SYSCALL_KRETPROBE(io_uring_register) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_io_uring_ret(ctx, (int)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SEC("tracepoint/handle_sys_io_uring_exit")
+int tracepoint_handle_sys_io_uring_exit(struct tracepoint_raw_syscalls_sys_exit_t *args) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_io_uring_ret(args, (int)args->ret, &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_tp_enforce_policy(args, process_ctx, action);
+}
+
+#endif
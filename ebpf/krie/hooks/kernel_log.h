@@ -0,0 +1,154 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _KERNEL_LOG_H_
+#define _KERNEL_LOG_H_
+
+#define KERNEL_LOG_SYSLOG 1
+#define KERNEL_LOG_KMSG 2
+
+#define SYSLOG_ACTION_READ_CLEAR 4
+#define SYSLOG_ACTION_CLEAR 5
+#define SYSLOG_ACTION_CONSOLE_OFF 6
+
+#define KERNEL_LOG_O_TRUNC 00001000
+
+struct kernel_log_tamper_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u32 source;
+    u32 syslog_action;
+    u32 flags;
+    u32 padding;
+};
+
+memory_factory(kernel_log_tamper_event)
+
+SYSCALL_KPROBE1(syslog, int, type) {
+    // only the actions erasing the ring buffer or silencing the console are monitored
+    if (type != SYSLOG_ACTION_READ_CLEAR && type != SYSLOG_ACTION_CLEAR && type != SYSLOG_ACTION_CONSOLE_OFF) {
+        return 0;
+    }
+
+    struct syscall_cache_t syscall = {
+        .type = EVENT_KERNEL_LOG_TAMPER,
+        .syslog = {
+            .action = type,
+        },
+    };
+    cache_syscall(&syscall);
+
+    // create process context for KRIE detection
+    struct kernel_log_tamper_event_t *event = new_kernel_log_tamper_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    fill_process_context(&event->process);
+
+    // we're about to allow this call to go through, double check with KRIE
+    u32 action = krie_run_event_check(ctx, &event->process, &syscall.type);
+
+    // pop cache if need be
+    if (action > KRIE_ACTION_LOG) {
+        pop_syscall(EVENT_KERNEL_LOG_TAMPER);
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, &event->process, action);
+}
+
+__attribute__((always_inline)) struct process_context_t *sys_syslog_ret(void *ctx, int retval, u32 *action) {
+    struct syscall_cache_t *syscall = pop_syscall(EVENT_KERNEL_LOG_TAMPER);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct kernel_log_tamper_event_t *event = new_kernel_log_tamper_event();
+    if (event == NULL) {
+        // ignore, should not happen
+        return 0;
+    }
+    event->event.type = EVENT_KERNEL_LOG_TAMPER;
+    event->event.retval = retval;
+    event->source = KERNEL_LOG_SYSLOG;
+    event->syslog_action = syscall->syslog.action;
+    event->flags = 0;
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+    *action = event->event.action;
+
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+    return &event->process;
+}
+
+SYSCALL_KRETPROBE(syslog) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_syslog_ret(ctx, (int)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SEC("tracepoint/handle_sys_syslog_exit")
+int tracepoint_handle_sys_syslog_exit(struct tracepoint_raw_syscalls_sys_exit_t *args) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_syslog_ret(args, args->ret, &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_tp_enforce_policy(args, process_ctx, action);
+}
+
+// /dev/kmsg can't be truncated, but an open with O_TRUNC is how a shell redirection (> /dev/kmsg) wipes a log file:
+// it shows an attempt to erase the kernel log, or to forge its entries
+SEC("kprobe/devkmsg_open")
+int BPF_KPROBE(kprobe_devkmsg_open, struct inode *inode, struct file *file) {
+    u32 flags = BPF_CORE_READ(file, f_flags);
+    if ((flags & KERNEL_LOG_O_TRUNC) == 0) {
+        return 0;
+    }
+
+    struct kernel_log_tamper_event_t *event = new_kernel_log_tamper_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    event->event.type = EVENT_KERNEL_LOG_TAMPER;
+    event->source = KERNEL_LOG_KMSG;
+    event->syslog_action = 0;
+    event->flags = flags;
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+
+    return krie_kprobe_enforce_policy(ctx, &event->process, event->event.action);
+};
+
+#endif
@@ -13,20 +13,45 @@ struct init_module_event_t {
     struct process_context_t process;
 
     u32 loaded_from_memory;
-    u32 padding;
+    int fd;
     char name[MODULE_NAME_LEN];
+    u64 ino;
+    u32 dev;
+    u32 nlink;
+    char file_name[MODULE_FILE_NAME_LEN];
 };
 
 memory_factory(init_module_event)
 
-int __attribute__((always_inline)) trace_init_module(void *ctx, u32 loaded_from_memory) {
+__attribute__((always_inline)) struct file *get_current_file(int fd) {
+    struct task_struct *task = (struct task_struct *)bpf_get_current_task();
+    struct file **fds = BPF_CORE_READ(task, files, fdt, fd);
+    struct file *file = NULL;
+    bpf_probe_read(&file, sizeof(file), &fds[fd]);
+    return file;
+}
+
+int __attribute__((always_inline)) trace_init_module(void *ctx, u32 loaded_from_memory, int fd) {
     struct syscall_cache_t syscall = {
         .type = EVENT_INIT_MODULE,
         .init_module = {
             .loaded_from_memory = loaded_from_memory,
+            .fd = fd,
         },
     };
 
+    // finit_module: resolve the file backing the module, so that user space can find it on disk
+    if (fd >= 0) {
+        struct file *file = get_current_file(fd);
+        if (file != NULL) {
+            syscall.init_module.ino = BPF_CORE_READ(file, f_inode, i_ino);
+            syscall.init_module.dev = BPF_CORE_READ(file, f_inode, i_sb, s_dev);
+            // a memfd or a deleted file has no link left
+            syscall.init_module.nlink = BPF_CORE_READ(file, f_inode, i_nlink);
+            bpf_probe_read_str(&syscall.init_module.file_name[0], sizeof(syscall.init_module.file_name), BPF_CORE_READ(file, f_path.dentry, d_name.name));
+        }
+    }
+
     cache_syscall(&syscall);
 
     // create process context for KRIE detection
@@ -49,11 +74,11 @@ int __attribute__((always_inline)) trace_init_module(void *ctx, u32 loaded_from_
 };
 
 SYSCALL_KPROBE0(init_module) {
-    return trace_init_module(ctx, 1);
+    return trace_init_module(ctx, 1, -1);
 };
 
-SYSCALL_KPROBE0(finit_module) {
-    return trace_init_module(ctx, 0);
+SYSCALL_KPROBE1(finit_module, int, fd) {
+    return trace_init_module(ctx, 0, fd);
 };
 
 int __attribute__((always_inline)) trace_module(void *ctx, struct module *mod) {
@@ -102,6 +127,11 @@ __attribute__((always_inline)) struct process_context_t *trace_init_module_ret(v
     event->event.retval = retval;
     bpf_probe_read(&event->loaded_from_memory, sizeof(event->loaded_from_memory), &syscall->init_module.loaded_from_memory);
     bpf_probe_read_str(&event->name[0], sizeof(event->name), &syscall->init_module.name[0]);
+    event->fd = syscall->init_module.fd;
+    event->ino = syscall->init_module.ino;
+    event->dev = syscall->init_module.dev;
+    event->nlink = syscall->init_module.nlink;
+    bpf_probe_read_str(&event->file_name[0], sizeof(event->file_name), &syscall->init_module.file_name[0]);
 
     fill_process_context(&event->process);
 
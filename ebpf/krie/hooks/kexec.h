@@ -0,0 +1,151 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _KEXEC_H_
+#define _KEXEC_H_
+
+#define KEXEC_CMDLINE_LEN 256
+
+struct kexec_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u64 entry;
+    u64 nr_segments;
+    u64 flags;
+    s32 kernel_fd;
+    s32 initrd_fd;
+    u32 file_load;
+    u32 cmdline_len;
+    char cmdline[KEXEC_CMDLINE_LEN];
+};
+
+memory_factory(kexec_event)
+
+int __attribute__((always_inline)) trace_kexec(void *ctx, struct syscall_cache_t *syscall) {
+    cache_syscall(syscall);
+
+    // create process context for KRIE detection
+    struct kexec_event_t *event = new_kexec_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    fill_process_context(&event->process);
+
+    // we're about to allow this call to go through, double check with KRIE
+    u32 action = krie_run_event_check(ctx, &event->process, &syscall->type);
+
+    // pop cache if need be
+    if (action > KRIE_ACTION_LOG) {
+        pop_syscall(EVENT_KEXEC);
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, &event->process, action);
+}
+
+SYSCALL_KPROBE4(kexec_load, unsigned long, entry, unsigned long, nr_segments, void *, segments, unsigned long, flags) {
+    struct syscall_cache_t syscall = {
+        .type = EVENT_KEXEC,
+        .kexec = {
+            .entry = entry,
+            .nr_segments = nr_segments,
+            .flags = flags,
+            .kernel_fd = -1,
+            .initrd_fd = -1,
+        }
+    };
+    return trace_kexec(ctx, &syscall);
+}
+
+SYSCALL_KPROBE5(kexec_file_load, int, kernel_fd, int, initrd_fd, unsigned long, cmdline_len, const char *, cmdline, unsigned long, flags) {
+    struct syscall_cache_t syscall = {
+        .type = EVENT_KEXEC,
+        .kexec = {
+            .flags = flags,
+            .kernel_fd = kernel_fd,
+            .initrd_fd = initrd_fd,
+            .file_load = 1,
+            .cmdline_len = cmdline_len,
+            .cmdline = cmdline,
+        }
+    };
+    return trace_kexec(ctx, &syscall);
+}
+
+__attribute__((always_inline)) struct process_context_t *sys_kexec_ret(void *ctx, int retval, u32 *action) {
+    struct syscall_cache_t *syscall = pop_syscall(EVENT_KEXEC);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct kexec_event_t *event = new_kexec_event();
+    if (event == NULL) {
+        // ignore, should not happen
+        return 0;
+    }
+    event->event.type = EVENT_KEXEC;
+    event->event.retval = retval;
+    event->entry = syscall->kexec.entry;
+    event->nr_segments = syscall->kexec.nr_segments;
+    event->flags = syscall->kexec.flags;
+    event->kernel_fd = syscall->kexec.kernel_fd;
+    event->initrd_fd = syscall->kexec.initrd_fd;
+    event->file_load = syscall->kexec.file_load;
+    event->cmdline_len = syscall->kexec.cmdline_len;
+    if (syscall->kexec.cmdline != NULL) {
+        bpf_probe_read_str(&event->cmdline[0], sizeof(event->cmdline), syscall->kexec.cmdline);
+    }
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+    *action = event->event.action;
+
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+    return &event->process;
+}
+
+SYSCALL_KRETPROBE(kexec_load) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_kexec_ret(ctx, (int)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SYSCALL_KRETPROBE(kexec_file_load) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_kexec_ret(ctx, (int)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SEC("tracepoint/handle_sys_kexec_exit")
+int tracepoint_handle_sys_kexec_exit(struct tracepoint_raw_syscalls_sys_exit_t *args) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_kexec_ret(args, (int)args->ret, &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_tp_enforce_policy(args, process_ctx, action);
+}
+
+#endif
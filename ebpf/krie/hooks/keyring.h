@@ -0,0 +1,213 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _KEYRING_H_
+#define _KEYRING_H_
+
+#define KEYRING_ADD_KEY 1
+#define KEYRING_REQUEST_KEY 2
+#define KEYRING_KEYCTL 3
+
+#define KEYRING_TYPE_LEN 32
+#define KEYRING_DESCRIPTION_LEN 128
+
+#define KEYCTL_GET_KEYRING_ID 0
+#define KEYCTL_JOIN_SESSION_KEYRING 1
+#define KEYCTL_UPDATE 2
+#define KEYCTL_DESCRIBE 6
+#define KEYCTL_SEARCH 10
+#define KEYCTL_READ 11
+#define KEYCTL_INSTANTIATE 12
+#define KEYCTL_GET_SECURITY 17
+#define KEYCTL_PKEY_QUERY 24
+#define KEYCTL_CAPABILITIES 31
+
+struct keyring_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u64 payload_len;
+    u32 syscall;
+    u32 keyctl_cmd;
+    s32 key;
+    u32 padding;
+    char type[KEYRING_TYPE_LEN];
+    char description[KEYRING_DESCRIPTION_LEN];
+};
+
+memory_factory(keyring_event)
+
+int __attribute__((always_inline)) trace_keyring(void *ctx, struct syscall_cache_t *syscall) {
+    cache_syscall(syscall);
+
+    // create process context for KRIE detection
+    struct keyring_event_t *event = new_keyring_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    fill_process_context(&event->process);
+
+    // we're about to allow this call to go through, double check with KRIE
+    u32 action = krie_run_event_check(ctx, &event->process, &syscall->type);
+
+    // pop cache if need be
+    if (action > KRIE_ACTION_LOG) {
+        pop_syscall(EVENT_KEYRING);
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, &event->process, action);
+}
+
+SYSCALL_KPROBE5(add_key, const char *, type, const char *, description, const void *, payload, size_t, plen, s32, ringid) {
+    struct syscall_cache_t syscall = {
+        .type = EVENT_KEYRING,
+        .keyring = {
+            .syscall = KEYRING_ADD_KEY,
+            .key_type = (void *)type,
+            .description = (void *)description,
+            .payload_len = plen,
+            .key = ringid,
+        }
+    };
+    return trace_keyring(ctx, &syscall);
+}
+
+SYSCALL_KPROBE4(request_key, const char *, type, const char *, description, const char *, callout_info, s32, destringid) {
+    struct syscall_cache_t syscall = {
+        .type = EVENT_KEYRING,
+        .keyring = {
+            .syscall = KEYRING_REQUEST_KEY,
+            .key_type = (void *)type,
+            .description = (void *)description,
+            .key = destringid,
+        }
+    };
+    return trace_keyring(ctx, &syscall);
+}
+
+SYSCALL_KPROBE4(keyctl, int, option, unsigned long, arg2, unsigned long, arg3, unsigned long, arg4) {
+    // the queries don't change the keys, they aren't monitored
+    switch (option) {
+        case KEYCTL_GET_KEYRING_ID:
+        case KEYCTL_DESCRIBE:
+        case KEYCTL_READ:
+        case KEYCTL_GET_SECURITY:
+        case KEYCTL_PKEY_QUERY:
+        case KEYCTL_CAPABILITIES:
+            return 0;
+    }
+
+    struct syscall_cache_t syscall = {
+        .type = EVENT_KEYRING,
+        .keyring = {
+            .syscall = KEYRING_KEYCTL,
+            .keyctl_cmd = option,
+        }
+    };
+    switch (option) {
+        case KEYCTL_JOIN_SESSION_KEYRING:
+            // arg2 is the name of the session keyring
+            syscall.keyring.description = (void *)arg2;
+            break;
+        case KEYCTL_SEARCH:
+            syscall.keyring.key = (s32)arg2;
+            syscall.keyring.key_type = (void *)arg3;
+            syscall.keyring.description = (void *)arg4;
+            break;
+        case KEYCTL_UPDATE:
+        case KEYCTL_INSTANTIATE:
+            syscall.keyring.key = (s32)arg2;
+            syscall.keyring.payload_len = arg4;
+            break;
+        default:
+            // the other commands take a key or a keyring as first argument
+            syscall.keyring.key = (s32)arg2;
+            break;
+    }
+    return trace_keyring(ctx, &syscall);
+}
+
+__attribute__((always_inline)) struct process_context_t *sys_keyring_ret(void *ctx, long retval, u32 *action) {
+    struct syscall_cache_t *syscall = pop_syscall(EVENT_KEYRING);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct keyring_event_t *event = new_keyring_event();
+    if (event == NULL) {
+        // ignore, should not happen
+        return 0;
+    }
+    event->event.type = EVENT_KEYRING;
+    event->event.retval = retval;
+    event->syscall = syscall->keyring.syscall;
+    event->keyctl_cmd = syscall->keyring.keyctl_cmd;
+    event->key = syscall->keyring.key;
+    event->payload_len = syscall->keyring.payload_len;
+    // the strings are zeroed when the pointers are NULL
+    bpf_probe_read_user_str(&event->type, sizeof(event->type), syscall->keyring.key_type);
+    bpf_probe_read_user_str(&event->description, sizeof(event->description), syscall->keyring.description);
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+    *action = event->event.action;
+
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+    return &event->process;
+}
+
+SYSCALL_KRETPROBE(add_key) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_keyring_ret(ctx, (long)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SYSCALL_KRETPROBE(request_key) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_keyring_ret(ctx, (long)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SYSCALL_KRETPROBE(keyctl) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_keyring_ret(ctx, (long)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SEC("tracepoint/handle_sys_keyring_exit")
+int tracepoint_handle_sys_keyring_exit(struct tracepoint_raw_syscalls_sys_exit_t *args) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_keyring_ret(args, args->ret, &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_tp_enforce_policy(args, process_ctx, action);
+}
+
+#endif
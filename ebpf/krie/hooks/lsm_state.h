@@ -0,0 +1,91 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _LSM_STATE_H_
+#define _LSM_STATE_H_
+
+#define LSM_STATE_SELINUX_PERMISSIVE 1
+#define LSM_STATE_SELINUX_DISABLE 2
+#define LSM_STATE_APPARMOR_PROFILE_REMOVAL 4
+
+#define LSM_STATE_PROFILE_LEN 128
+
+struct lsm_state_change_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u32 change;
+    s32 value;
+    char profile[LSM_STATE_PROFILE_LEN];
+};
+
+memory_factory(lsm_state_change_event)
+
+__attribute__((always_inline)) int lsm_state_change_send(struct pt_regs *ctx, struct lsm_state_change_event_t *event, u32 change, s32 value) {
+    event->event.type = EVENT_LSM_STATE_CHANGE;
+    event->change = change;
+    event->value = value;
+    fill_process_context(&event->process);
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+
+    return krie_kprobe_enforce_policy(ctx, &event->process, event->event.action);
+};
+
+// parse_selinuxfs_value parses the value written to a selinuxfs boolean file ("0" or "1", followed by a new line)
+__attribute__((always_inline)) s32 parse_selinuxfs_value(const char *user_buf) {
+    char buf[2] = {};
+    bpf_probe_read(&buf, sizeof(buf), user_buf);
+    if (buf[0] < '0' || buf[0] > '9') {
+        return -1;
+    }
+    return buf[0] - '0';
+};
+
+__attribute__((always_inline)) int selinuxfs_write_check(struct pt_regs *ctx, const char *user_buf, u32 change) {
+    s32 value = parse_selinuxfs_value(user_buf);
+
+    // switching to enforcing mode is expected, only the writes that weaken SELinux are reported
+    if ((change == LSM_STATE_SELINUX_PERMISSIVE && value != 0) || (change == LSM_STATE_SELINUX_DISABLE && value == 0)) {
+        return 0;
+    }
+
+    struct lsm_state_change_event_t *event = new_lsm_state_change_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    return lsm_state_change_send(ctx, event, change, value);
+};
+
+SEC("kprobe/sel_write_enforce")
+int BPF_KPROBE(kprobe_sel_write_enforce, struct file *file, const char *buf) {
+    return selinuxfs_write_check(ctx, buf, LSM_STATE_SELINUX_PERMISSIVE);
+};
+
+SEC("kprobe/sel_write_disable")
+int BPF_KPROBE(kprobe_sel_write_disable, struct file *file, const char *buf) {
+    return selinuxfs_write_check(ctx, buf, LSM_STATE_SELINUX_DISABLE);
+};
+
+SEC("kprobe/aa_remove_profiles")
+int BPF_KPROBE(kprobe_aa_remove_profiles, void *policy_ns, void *subj, char *fqname) {
+    struct lsm_state_change_event_t *event = new_lsm_state_change_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    bpf_probe_read_str(&event->profile[0], sizeof(event->profile), fqname);
+    return lsm_state_change_send(ctx, event, LSM_STATE_APPARMOR_PROFILE_REMOVAL, 0);
+};
+
+#endif
@@ -0,0 +1,51 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _MODULE_SIGNATURE_H_
+#define _MODULE_SIGNATURE_H_
+
+// do_init_module runs once the module is verified and laid out: a module without a valid signature only gets here when
+// the enforcement is disabled, or bypassed
+SEC("kprobe/do_init_module")
+int BPF_KPROBE(kprobe_module_signature_do_init_module, struct module *mod) {
+    // the modules aren't verified without CONFIG_MODULE_SIG
+    if (!bpf_core_field_exists(mod->sig_ok)) {
+        return 0;
+    }
+    if (BPF_CORE_READ(mod, sig_ok)) {
+        return 0;
+    }
+
+    struct module_signature_event_t *event = new_module_signature_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    event->event.type = EVENT_MODULE_SIGNATURE;
+    event->kind = MODULE_SIGNATURE_LOAD;
+    event->sig_enforce = read_sig_enforce();
+    event->previous_sig_enforce = event->sig_enforce;
+    BPF_CORE_READ_INTO(&event->name, mod, name);
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+
+    return krie_kprobe_enforce_policy(ctx, &event->process, event->event.action);
+};
+
+#endif
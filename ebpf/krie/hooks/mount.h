@@ -0,0 +1,163 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _MOUNT_H_
+#define _MOUNT_H_
+
+#define MOUNT_FSTYPE_LEN 16
+#define MOUNT_PATH_LEN 128
+
+#define MOUNT_FS_DEBUGFS 1
+#define MOUNT_FS_TRACEFS 2
+#define MOUNT_FS_SECURITYFS 3
+#define MOUNT_FS_BPF 4
+#define MOUNT_FS_PROC 5
+
+struct mount_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u64 flags;
+    u32 fs;
+    u32 padding;
+    char source[MOUNT_PATH_LEN];
+    char target[MOUNT_PATH_LEN];
+};
+
+memory_factory(mount_event)
+
+__attribute__((always_inline)) int fstype_equals(const char *fstype, const char *name, int len) {
+    // a single exit, the loop wouldn't be unrolled otherwise
+    int equals = 1;
+#pragma unroll
+    for (int i = 0; i < MOUNT_FSTYPE_LEN; i++) {
+        if (i < len && fstype[i] != name[i]) {
+            equals = 0;
+        }
+    }
+    return equals;
+}
+
+// parse_sensitive_fs returns the sensitive filesystem of a file system type, or 0 if the file system isn't monitored
+__attribute__((always_inline)) u32 parse_sensitive_fs(const char *fstype) {
+    if (fstype_equals(fstype, "debugfs", sizeof("debugfs"))) {
+        return MOUNT_FS_DEBUGFS;
+    }
+    if (fstype_equals(fstype, "tracefs", sizeof("tracefs"))) {
+        return MOUNT_FS_TRACEFS;
+    }
+    if (fstype_equals(fstype, "securityfs", sizeof("securityfs"))) {
+        return MOUNT_FS_SECURITYFS;
+    }
+    if (fstype_equals(fstype, "bpf", sizeof("bpf"))) {
+        return MOUNT_FS_BPF;
+    }
+    if (fstype_equals(fstype, "proc", sizeof("proc"))) {
+        return MOUNT_FS_PROC;
+    }
+    return 0;
+}
+
+SYSCALL_KPROBE5(mount, const char *, source, const char *, target, const char *, fstype, unsigned long, flags, void *, data) {
+    if (fstype == NULL) {
+        // bind mounts, remounts and propagation changes don't have a file system type
+        return 0;
+    }
+
+    char type[MOUNT_FSTYPE_LEN] = {};
+    bpf_probe_read_user_str(&type, sizeof(type), fstype);
+    u32 fs = parse_sensitive_fs(type);
+    if (fs == 0) {
+        return 0;
+    }
+
+    struct syscall_cache_t syscall = {
+        .type = EVENT_MOUNT,
+        .mount = {
+            .source = (void *)source,
+            .target = (void *)target,
+            .flags = flags,
+            .fs = fs,
+        }
+    };
+    cache_syscall(&syscall);
+
+    // create process context for KRIE detection
+    struct mount_event_t *event = new_mount_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    fill_process_context(&event->process);
+
+    // we're about to allow this call to go through, double check with KRIE
+    u32 action = krie_run_event_check(ctx, &event->process, &syscall.type);
+
+    // pop cache if need be
+    if (action > KRIE_ACTION_LOG) {
+        pop_syscall(EVENT_MOUNT);
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, &event->process, action);
+}
+
+__attribute__((always_inline)) struct process_context_t *sys_mount_ret(void *ctx, int retval, u32 *action) {
+    struct syscall_cache_t *syscall = pop_syscall(EVENT_MOUNT);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct mount_event_t *event = new_mount_event();
+    if (event == NULL) {
+        // ignore, should not happen
+        return 0;
+    }
+    event->event.type = EVENT_MOUNT;
+    event->event.retval = retval;
+    event->flags = syscall->mount.flags;
+    event->fs = syscall->mount.fs;
+    bpf_probe_read_user_str(&event->source, sizeof(event->source), syscall->mount.source);
+    bpf_probe_read_user_str(&event->target, sizeof(event->target), syscall->mount.target);
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+    *action = event->event.action;
+
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+    return &event->process;
+}
+
+SYSCALL_KRETPROBE(mount) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_mount_ret(ctx, (int)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SEC("tracepoint/handle_sys_mount_exit")
+int tracepoint_handle_sys_mount_exit(struct tracepoint_raw_syscalls_sys_exit_t *args) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_mount_ret(args, args->ret, &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_tp_enforce_policy(args, process_ctx, action);
+}
+
+#endif
@@ -0,0 +1,123 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _MSR_H_
+#define _MSR_H_
+
+#define MSR_WRITE_SOURCE_TRACEPOINT 1
+#define MSR_WRITE_SOURCE_CHARDEV 2
+
+#define MSR_MINOR_MASK ((1U << 20) - 1)
+
+#define MSR_IA32_SYSENTER_CS  0x00000174
+#define MSR_IA32_SYSENTER_ESP 0x00000175
+#define MSR_IA32_SYSENTER_EIP 0x00000176
+#define MSR_EFER              0xc0000080
+#define MSR_STAR              0xc0000081
+#define MSR_LSTAR             0xc0000082
+#define MSR_CSTAR             0xc0000083
+#define MSR_SYSCALL_MASK      0xc0000084
+
+struct tracepoint_msr_write_msr_t
+{
+    unsigned short common_type;
+    unsigned char common_flags;
+    unsigned char common_preempt_count;
+    int common_pid;
+
+    unsigned msr;
+    u64 val;
+    int failed;
+};
+
+struct msr_write_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u64 value;
+    u32 msr;
+    u32 source;
+    u32 cpu;
+    u32 padding;
+};
+
+memory_factory(msr_write_event)
+
+__attribute__((always_inline)) struct msr_write_event_t *msr_write_fill(void *ctx, u32 msr, u64 value, u32 source, u32 cpu) {
+    struct msr_write_event_t *event = new_msr_write_event();
+    if (event == NULL) {
+        // should never happen
+        return NULL;
+    }
+    event->event.type = EVENT_MSR_WRITE;
+    event->msr = msr;
+    event->value = value;
+    event->source = source;
+    event->cpu = cpu;
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return NULL;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+    return event;
+};
+
+__attribute__((always_inline)) int is_sensitive_msr(u32 msr) {
+    switch (msr) {
+        case MSR_IA32_SYSENTER_CS:
+        case MSR_IA32_SYSENTER_ESP:
+        case MSR_IA32_SYSENTER_EIP:
+        case MSR_EFER:
+        case MSR_STAR:
+        case MSR_LSTAR:
+        case MSR_CSTAR:
+        case MSR_SYSCALL_MASK:
+            return 1;
+    }
+    return 0;
+};
+
+// the write_msr tracepoint sees every write done by the kernel (wrmsr), including the context switches: only the MSRs
+// that control the syscall entry points are reported. The write already happened, the policy can only kill the caller.
+SEC("tracepoint/msr/write_msr")
+int tracepoint_msr_write_msr(struct tracepoint_msr_write_msr_t *args) {
+    if (!is_sensitive_msr(args->msr)) {
+        return 0;
+    }
+
+    struct msr_write_event_t *event = msr_write_fill(args, args->msr, args->val, MSR_WRITE_SOURCE_TRACEPOINT, bpf_get_smp_processor_id());
+    if (event == NULL) {
+        return 0;
+    }
+    return krie_tp_enforce_policy(args, &event->process, event->event.action);
+};
+
+// msr_write is the write handler of /dev/cpu/*/msr, the offset of the write is the MSR index
+SEC("kprobe/msr_write")
+int BPF_KPROBE(kprobe_msr_write, struct file *file, const char *buf, size_t count, loff_t *ppos) {
+    loff_t reg = 0;
+    bpf_probe_read(&reg, sizeof(reg), ppos);
+    u64 value = 0;
+    bpf_probe_read(&value, sizeof(value), (void *)buf);
+    dev_t rdev = BPF_CORE_READ(file, f_inode, i_rdev);
+
+    struct msr_write_event_t *event = msr_write_fill(ctx, (u32)reg, value, MSR_WRITE_SOURCE_CHARDEV, rdev & MSR_MINOR_MASK);
+    if (event == NULL) {
+        return 0;
+    }
+    return krie_kprobe_enforce_policy(ctx, &event->process, event->event.action);
+};
+
+#endif
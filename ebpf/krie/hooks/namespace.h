@@ -0,0 +1,146 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _NAMESPACE_H_
+#define _NAMESPACE_H_
+
+#define NAMESPACE_SETNS 1
+#define NAMESPACE_UNSHARE 2
+
+// CLONE_NEWTIME, CLONE_NEWNS, CLONE_NEWCGROUP, CLONE_NEWUTS, CLONE_NEWIPC, CLONE_NEWUSER, CLONE_NEWPID and CLONE_NEWNET
+#define NAMESPACE_CLONE_FLAGS 0x7e020080
+
+struct namespace_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    struct namespace_context_t previous;
+    u64 flags;
+    u32 syscall;
+    s32 fd;
+};
+
+memory_factory(namespace_event)
+
+int __attribute__((always_inline)) trace_namespace(void *ctx, struct syscall_cache_t *syscall) {
+    // the namespaces before the call, the process context of the event holds the namespaces after the call
+    fill_namespace_context(&syscall->namespace.previous, (struct task_struct *)bpf_get_current_task());
+    cache_syscall(syscall);
+
+    // create process context for KRIE detection
+    struct namespace_event_t *event = new_namespace_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    fill_process_context(&event->process);
+
+    // we're about to allow this call to go through, double check with KRIE
+    u32 action = krie_run_event_check(ctx, &event->process, &syscall->type);
+
+    // pop cache if need be
+    if (action > KRIE_ACTION_LOG) {
+        pop_syscall(EVENT_NAMESPACE);
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, &event->process, action);
+}
+
+SYSCALL_KPROBE2(setns, int, fd, int, nstype) {
+    struct syscall_cache_t syscall = {
+        .type = EVENT_NAMESPACE,
+        .namespace = {
+            .syscall = NAMESPACE_SETNS,
+            .fd = fd,
+            .flags = (u32)nstype,
+        }
+    };
+    return trace_namespace(ctx, &syscall);
+}
+
+SYSCALL_KPROBE1(unshare, unsigned long, flags) {
+    // unshare also detaches the file descriptor table and the file system information, which aren't namespaces
+    if ((flags & NAMESPACE_CLONE_FLAGS) == 0) {
+        return 0;
+    }
+
+    struct syscall_cache_t syscall = {
+        .type = EVENT_NAMESPACE,
+        .namespace = {
+            .syscall = NAMESPACE_UNSHARE,
+            .flags = flags,
+        }
+    };
+    return trace_namespace(ctx, &syscall);
+}
+
+__attribute__((always_inline)) struct process_context_t *sys_namespace_ret(void *ctx, int retval, u32 *action) {
+    struct syscall_cache_t *syscall = pop_syscall(EVENT_NAMESPACE);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct namespace_event_t *event = new_namespace_event();
+    if (event == NULL) {
+        // ignore, should not happen
+        return 0;
+    }
+    event->event.type = EVENT_NAMESPACE;
+    event->event.retval = retval;
+    event->syscall = syscall->namespace.syscall;
+    event->fd = syscall->namespace.fd;
+    event->flags = syscall->namespace.flags;
+    __builtin_memcpy(&event->previous, &syscall->namespace.previous, sizeof(struct namespace_context_t));
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+    *action = event->event.action;
+
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+    return &event->process;
+}
+
+SYSCALL_KRETPROBE(setns) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_namespace_ret(ctx, (int)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SYSCALL_KRETPROBE(unshare) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_namespace_ret(ctx, (int)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SEC("tracepoint/handle_sys_namespace_exit")
+int tracepoint_handle_sys_namespace_exit(struct tracepoint_raw_syscalls_sys_exit_t *args) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_namespace_ret(args, args->ret, &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_tp_enforce_policy(args, process_ctx, action);
+}
+
+#endif
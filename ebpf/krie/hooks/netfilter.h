@@ -0,0 +1,58 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _NETFILTER_H_
+#define _NETFILTER_H_
+
+struct netfilter_hook_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u64 ops;
+    u64 hook;
+    u32 net_namespace;
+    u32 pf;
+    u32 hooknum;
+    s32 priority;
+};
+
+memory_factory(netfilter_hook_event)
+
+// nf_register_net_hook registers every netfilter hook, nf_register_net_hooks included: a module hooking the network
+// stack to hide its traffic or to wait for a magic packet has to go through it
+SEC("kprobe/nf_register_net_hook")
+int BPF_KPROBE(kprobe_nf_register_net_hook, struct net *net, const struct nf_hook_ops *reg) {
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    struct netfilter_hook_event_t *event = new_netfilter_hook_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    event->event.type = EVENT_NETFILTER_HOOK;
+    event->ops = (u64)reg;
+    event->hook = (u64)BPF_CORE_READ(reg, hook);
+    event->net_namespace = BPF_CORE_READ(net, ns.inum);
+    event->pf = BPF_CORE_READ(reg, pf);
+    event->hooknum = BPF_CORE_READ(reg, hooknum);
+    event->priority = BPF_CORE_READ(reg, priority);
+    fill_process_context(&event->process);
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+
+    return krie_kprobe_enforce_policy(ctx, &event->process, event->event.action);
+};
+
+#endif
@@ -0,0 +1,149 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _PERF_EVENT_H_
+#define _PERF_EVENT_H_
+
+#define PERF_EVENT_PROBE_LEN 128
+#define PERF_TYPE_MAX 6
+
+struct perf_event_open_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u64 config;
+    u64 config1;
+    u64 config2;
+    u64 sample_type;
+    u64 attr_flags;
+    u64 flags;
+    u32 type;
+    s32 pid;
+    s32 cpu;
+    s32 group_fd;
+    char probe[PERF_EVENT_PROBE_LEN];
+};
+
+memory_factory(perf_event_open_event)
+
+// perf_event_attr_t mirrors the head of struct perf_event_attr
+struct perf_event_attr_t {
+    u32 type;
+    u32 size;
+    u64 config;
+    u64 sample_period;
+    u64 sample_type;
+    u64 read_format;
+    u64 flags;
+    u32 wakeup_events;
+    u32 bp_type;
+    u64 config1;
+    u64 config2;
+};
+
+SYSCALL_KPROBE5(perf_event_open, struct perf_event_attr *, attr, pid_t, pid, int, cpu, int, group_fd, unsigned long, flags) {
+    struct syscall_cache_t syscall = {
+        .type = EVENT_PERF_EVENT_OPEN,
+        .perf_event_open = {
+            .attr = attr,
+            .pid = pid,
+            .cpu = cpu,
+            .group_fd = group_fd,
+            .flags = flags,
+        }
+    };
+    cache_syscall(&syscall);
+
+    // create process context for KRIE detection
+    struct perf_event_open_event_t *event = new_perf_event_open_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    fill_process_context(&event->process);
+
+    // we're about to allow this call to go through, double check with KRIE
+    u32 action = krie_run_event_check(ctx, &event->process, &syscall.type);
+
+    // pop cache if need be
+    if (action > KRIE_ACTION_LOG) {
+        pop_syscall(EVENT_PERF_EVENT_OPEN);
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, &event->process, action);
+}
+
+__attribute__((always_inline)) struct process_context_t *sys_perf_event_open_ret(void *ctx, int retval, u32 *action) {
+    struct syscall_cache_t *syscall = pop_syscall(EVENT_PERF_EVENT_OPEN);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct perf_event_open_event_t *event = new_perf_event_open_event();
+    if (event == NULL) {
+        // ignore, should not happen
+        return 0;
+    }
+    event->event.type = EVENT_PERF_EVENT_OPEN;
+    event->event.retval = retval;
+    event->pid = syscall->perf_event_open.pid;
+    event->cpu = syscall->perf_event_open.cpu;
+    event->group_fd = syscall->perf_event_open.group_fd;
+    event->flags = syscall->perf_event_open.flags;
+
+    struct perf_event_attr_t attr = {};
+    bpf_probe_read(&attr, sizeof(attr), syscall->perf_event_open.attr);
+    event->type = attr.type;
+    event->config = attr.config;
+    event->config1 = attr.config1;
+    event->config2 = attr.config2;
+    event->sample_type = attr.sample_type;
+    event->attr_flags = attr.flags;
+
+    // the kprobe and uprobe PMUs are dynamic, config1 points to the probed function or to the probed binary
+    if (attr.type >= PERF_TYPE_MAX && attr.config1 != 0) {
+        bpf_probe_read_str(&event->probe, sizeof(event->probe), (void *)attr.config1);
+    }
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+    *action = event->event.action;
+
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+    return &event->process;
+}
+
+SYSCALL_KRETPROBE(perf_event_open) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_perf_event_open_ret(ctx, (int)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SEC("tracepoint/handle_sys_perf_event_open_exit")
+int tracepoint_handle_sys_perf_event_open_exit(struct tracepoint_raw_syscalls_sys_exit_t *args) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_perf_event_open_ret(args, (int)args->ret, &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_tp_enforce_policy(args, process_ctx, action);
+}
+
+#endif
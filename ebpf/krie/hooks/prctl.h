@@ -0,0 +1,164 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _PRCTL_H_
+#define _PRCTL_H_
+
+#define PRCTL_OPTION_SET_DUMPABLE 4
+#define PRCTL_OPTION_SET_SECCOMP 22
+#define PRCTL_OPTION_SET_PTRACER 0x59616d61
+
+#define SECCOMP_OP_SET_MODE_STRICT 0
+#define SECCOMP_OP_SET_MODE_FILTER 1
+
+#define PRCTL_SET_SECCOMP 1
+#define PRCTL_SET_DUMPABLE 2
+#define PRCTL_SET_PTRACER 3
+#define PRCTL_SECCOMP 4
+
+struct prctl_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u64 value;
+    u64 flags;
+    u32 operation;
+    u32 padding;
+};
+
+memory_factory(prctl_event)
+
+int __attribute__((always_inline)) trace_prctl(void *ctx, struct syscall_cache_t *syscall) {
+    cache_syscall(syscall);
+
+    // create process context for KRIE detection
+    struct prctl_event_t *event = new_prctl_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    fill_process_context(&event->process);
+
+    // we're about to allow this call to go through, double check with KRIE
+    u32 action = krie_run_event_check(ctx, &event->process, &syscall->type);
+
+    // pop cache if need be
+    if (action > KRIE_ACTION_LOG) {
+        pop_syscall(EVENT_PRCTL);
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, &event->process, action);
+}
+
+SYSCALL_KPROBE2(prctl, int, option, unsigned long, arg2) {
+    struct syscall_cache_t syscall = {
+        .type = EVENT_PRCTL,
+        .prctl = {
+            .value = arg2,
+        }
+    };
+
+    // only the options which change the sandbox of the process are monitored
+    switch (option) {
+    case PRCTL_OPTION_SET_SECCOMP:
+        syscall.prctl.operation = PRCTL_SET_SECCOMP;
+        break;
+    case PRCTL_OPTION_SET_DUMPABLE:
+        syscall.prctl.operation = PRCTL_SET_DUMPABLE;
+        break;
+    case PRCTL_OPTION_SET_PTRACER:
+        syscall.prctl.operation = PRCTL_SET_PTRACER;
+        break;
+    default:
+        return 0;
+    }
+    return trace_prctl(ctx, &syscall);
+}
+
+SYSCALL_KPROBE2(seccomp, unsigned int, op, unsigned int, flags) {
+    if (op != SECCOMP_OP_SET_MODE_STRICT && op != SECCOMP_OP_SET_MODE_FILTER) {
+        // SECCOMP_GET_ACTION_AVAIL and SECCOMP_GET_NOTIF_SIZES don't change the sandbox
+        return 0;
+    }
+
+    struct syscall_cache_t syscall = {
+        .type = EVENT_PRCTL,
+        .prctl = {
+            .operation = PRCTL_SECCOMP,
+            // the seccomp modes are the operations + 1 (SECCOMP_MODE_STRICT is 1, SECCOMP_MODE_FILTER is 2)
+            .value = op + 1,
+            .flags = flags,
+        }
+    };
+    return trace_prctl(ctx, &syscall);
+}
+
+__attribute__((always_inline)) struct process_context_t *sys_prctl_ret(void *ctx, int retval, u32 *action) {
+    struct syscall_cache_t *syscall = pop_syscall(EVENT_PRCTL);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct prctl_event_t *event = new_prctl_event();
+    if (event == NULL) {
+        // ignore, should not happen
+        return 0;
+    }
+    event->event.type = EVENT_PRCTL;
+    event->event.retval = retval;
+    event->operation = syscall->prctl.operation;
+    event->value = syscall->prctl.value;
+    event->flags = syscall->prctl.flags;
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+    *action = event->event.action;
+
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+    return &event->process;
+}
+
+SYSCALL_KRETPROBE(prctl) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_prctl_ret(ctx, (int)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SYSCALL_KRETPROBE(seccomp) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_prctl_ret(ctx, (int)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SEC("tracepoint/handle_sys_prctl_exit")
+int tracepoint_handle_sys_prctl_exit(struct tracepoint_raw_syscalls_sys_exit_t *args) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_prctl_ret(args, args->ret, &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_tp_enforce_policy(args, process_ctx, action);
+}
+
+#endif
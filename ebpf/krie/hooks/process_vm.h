@@ -0,0 +1,170 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _PROCESS_VM_H_
+#define _PROCESS_VM_H_
+
+#define PROCESS_VM_READ 1
+#define PROCESS_VM_WRITE 2
+
+#define PROCESS_VM_MAX_IOV 8
+
+struct process_vm_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u64 remote_addr;
+    u64 requested;
+    u64 transferred;
+    u64 local_iovcnt;
+    u64 remote_iovcnt;
+    u64 flags;
+    u32 operation;
+    u32 pid;
+};
+
+memory_factory(process_vm_event)
+
+int __attribute__((always_inline)) trace_process_vm(void *ctx, struct syscall_cache_t *syscall) {
+    cache_syscall(syscall);
+
+    // create process context for KRIE detection
+    struct process_vm_event_t *event = new_process_vm_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    fill_process_context(&event->process);
+
+    // we're about to allow this call to go through, double check with KRIE
+    u32 action = krie_run_event_check(ctx, &event->process, &syscall->type);
+
+    // pop cache if need be
+    if (action > KRIE_ACTION_LOG) {
+        pop_syscall(EVENT_PROCESS_VM);
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, &event->process, action);
+}
+
+SYSCALL_KPROBE6(process_vm_readv, pid_t, pid, const struct iovec *, local_iov, unsigned long, liovcnt, const struct iovec *, remote_iov, unsigned long, riovcnt, unsigned long, flags) {
+    struct syscall_cache_t syscall = {
+        .type = EVENT_PROCESS_VM,
+        .process_vm = {
+            .operation = PROCESS_VM_READ,
+            .pid = pid,
+            .remote_iov = (void *)remote_iov,
+            .local_iovcnt = liovcnt,
+            .remote_iovcnt = riovcnt,
+            .flags = flags,
+        }
+    };
+    return trace_process_vm(ctx, &syscall);
+}
+
+SYSCALL_KPROBE6(process_vm_writev, pid_t, pid, const struct iovec *, local_iov, unsigned long, liovcnt, const struct iovec *, remote_iov, unsigned long, riovcnt, unsigned long, flags) {
+    struct syscall_cache_t syscall = {
+        .type = EVENT_PROCESS_VM,
+        .process_vm = {
+            .operation = PROCESS_VM_WRITE,
+            .pid = pid,
+            .remote_iov = (void *)remote_iov,
+            .local_iovcnt = liovcnt,
+            .remote_iovcnt = riovcnt,
+            .flags = flags,
+        }
+    };
+    return trace_process_vm(ctx, &syscall);
+}
+
+__attribute__((always_inline)) struct process_context_t *sys_process_vm_ret(void *ctx, long retval, u32 *action) {
+    struct syscall_cache_t *syscall = pop_syscall(EVENT_PROCESS_VM);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct process_vm_event_t *event = new_process_vm_event();
+    if (event == NULL) {
+        // ignore, should not happen
+        return 0;
+    }
+    event->event.type = EVENT_PROCESS_VM;
+    event->event.retval = retval;
+    // on success, the return value is the number of bytes transferred
+    event->transferred = retval > 0 ? retval : 0;
+    event->operation = syscall->process_vm.operation;
+    event->pid = syscall->process_vm.pid;
+    event->local_iovcnt = syscall->process_vm.local_iovcnt;
+    event->remote_iovcnt = syscall->process_vm.remote_iovcnt;
+    event->flags = syscall->process_vm.flags;
+    event->remote_addr = 0;
+    event->requested = 0;
+
+    // the requested byte count is the total length of the first remote buffers
+    if (syscall->process_vm.remote_iov != NULL) {
+        struct iovec_t iov = {};
+#pragma unroll
+        for (int i = 0; i < PROCESS_VM_MAX_IOV; i++) {
+            if (i >= event->remote_iovcnt) {
+                break;
+            }
+            bpf_probe_read(&iov, sizeof(iov), (struct iovec_t *)syscall->process_vm.remote_iov + i);
+            if (i == 0) {
+                event->remote_addr = iov.base;
+            }
+            event->requested += iov.len;
+        }
+    }
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+    *action = event->event.action;
+
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+    return &event->process;
+}
+
+SYSCALL_KRETPROBE(process_vm_readv) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_process_vm_ret(ctx, (long)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SYSCALL_KRETPROBE(process_vm_writev) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_process_vm_ret(ctx, (long)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SEC("tracepoint/handle_sys_process_vm_exit")
+int tracepoint_handle_sys_process_vm_exit(struct tracepoint_raw_syscalls_sys_exit_t *args) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_process_vm_ret(args, args->ret, &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_tp_enforce_policy(args, process_ctx, action);
+}
+
+#endif
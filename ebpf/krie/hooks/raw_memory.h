@@ -0,0 +1,129 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _RAW_MEMORY_H_
+#define _RAW_MEMORY_H_
+
+#define RAW_MEMORY_OPEN 1
+#define RAW_MEMORY_READ 2
+#define RAW_MEMORY_WRITE 3
+#define RAW_MEMORY_MMAP 4
+
+#define RAW_MEMORY_MINOR_MASK ((1U << 20) - 1)
+#define RAW_MEMORY_FMODE_WRITE 0x2
+#define RAW_MEMORY_VM_WRITE 0x2
+#define RAW_MEMORY_PAGE_SHIFT 12
+
+struct raw_memory_access_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u64 offset;
+    u64 size;
+    u32 device;
+    u32 operation;
+    u32 writable;
+    u32 padding;
+};
+
+memory_factory(raw_memory_access_event)
+
+static __attribute__((always_inline)) int raw_memory_access_send(struct pt_regs *ctx, dev_t rdev, u32 operation, u64 offset, u64 length, u32 writable) {
+    struct raw_memory_access_event_t *event = new_raw_memory_access_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    event->event.type = EVENT_RAW_MEMORY_ACCESS;
+    event->device = rdev & RAW_MEMORY_MINOR_MASK;
+    event->operation = operation;
+    event->offset = offset;
+    event->size = length;
+    event->writable = writable;
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+
+    return krie_kprobe_enforce_policy(ctx, &event->process, event->event.action);
+};
+
+__attribute__((always_inline)) int raw_memory_rw(struct pt_regs *ctx, struct file *file, size_t count, loff_t *ppos, u32 operation) {
+    loff_t offset = 0;
+    bpf_probe_read(&offset, sizeof(offset), ppos);
+    dev_t rdev = BPF_CORE_READ(file, f_inode, i_rdev);
+    return raw_memory_access_send(ctx, rdev, operation, offset, count, operation == RAW_MEMORY_WRITE);
+};
+
+__attribute__((always_inline)) int raw_memory_mmap(struct pt_regs *ctx, struct file *file, struct vm_area_struct *vma) {
+    u64 start = BPF_CORE_READ(vma, vm_start);
+    u64 end = BPF_CORE_READ(vma, vm_end);
+    u64 pgoff = BPF_CORE_READ(vma, vm_pgoff);
+    u64 vm_flags = BPF_CORE_READ(vma, vm_flags);
+    dev_t rdev = BPF_CORE_READ(file, f_inode, i_rdev);
+    return raw_memory_access_send(ctx, rdev, RAW_MEMORY_MMAP, pgoff << RAW_MEMORY_PAGE_SHIFT, end - start, (vm_flags & RAW_MEMORY_VM_WRITE) > 0);
+};
+
+// open_mem is an alias of open_port, which opens /dev/mem, /dev/kmem and /dev/port
+SEC("kprobe/open_port")
+int BPF_KPROBE(kprobe_open_port, struct inode *inode, struct file *file) {
+    dev_t rdev = BPF_CORE_READ(inode, i_rdev);
+    fmode_t mode = BPF_CORE_READ(file, f_mode);
+    return raw_memory_access_send(ctx, rdev, RAW_MEMORY_OPEN, 0, 0, (mode & RAW_MEMORY_FMODE_WRITE) > 0);
+};
+
+SEC("kprobe/read_mem")
+int BPF_KPROBE(kprobe_read_mem, struct file *file, char *buf, size_t count, loff_t *ppos) {
+    return raw_memory_rw(ctx, file, count, ppos, RAW_MEMORY_READ);
+};
+
+SEC("kprobe/write_mem")
+int BPF_KPROBE(kprobe_write_mem, struct file *file, const char *buf, size_t count, loff_t *ppos) {
+    return raw_memory_rw(ctx, file, count, ppos, RAW_MEMORY_WRITE);
+};
+
+SEC("kprobe/mmap_mem")
+int BPF_KPROBE(kprobe_mmap_mem, struct file *file, struct vm_area_struct *vma) {
+    return raw_memory_mmap(ctx, file, vma);
+};
+
+// /dev/kmem was removed in 5.13
+SEC("kprobe/read_kmem")
+int BPF_KPROBE(kprobe_read_kmem, struct file *file, char *buf, size_t count, loff_t *ppos) {
+    return raw_memory_rw(ctx, file, count, ppos, RAW_MEMORY_READ);
+};
+
+SEC("kprobe/write_kmem")
+int BPF_KPROBE(kprobe_write_kmem, struct file *file, const char *buf, size_t count, loff_t *ppos) {
+    return raw_memory_rw(ctx, file, count, ppos, RAW_MEMORY_WRITE);
+};
+
+SEC("kprobe/mmap_kmem")
+int BPF_KPROBE(kprobe_mmap_kmem, struct file *file, struct vm_area_struct *vma) {
+    return raw_memory_mmap(ctx, file, vma);
+};
+
+SEC("kprobe/read_port")
+int BPF_KPROBE(kprobe_read_port, struct file *file, char *buf, size_t count, loff_t *ppos) {
+    return raw_memory_rw(ctx, file, count, ppos, RAW_MEMORY_READ);
+};
+
+SEC("kprobe/write_port")
+int BPF_KPROBE(kprobe_write_port, struct file *file, const char *buf, size_t count, loff_t *ppos) {
+    return raw_memory_rw(ctx, file, count, ppos, RAW_MEMORY_WRITE);
+};
+
+#endif
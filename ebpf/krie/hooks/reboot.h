@@ -0,0 +1,53 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _REBOOT_H_
+#define _REBOOT_H_
+
+struct reboot_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u32 magic1;
+    u32 magic2;
+    u32 cmd;
+    u32 padding;
+};
+
+memory_factory(reboot_event)
+
+// a successful reboot doesn't return: the event is sent from the entry of the syscall, so that it leaves the machine
+// before the kernel goes down (or kexecs into another kernel)
+SYSCALL_KPROBE4(reboot, int, magic1, int, magic2, unsigned int, cmd, void *, arg) {
+    struct reboot_event_t *event = new_reboot_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    event->event.type = EVENT_REBOOT;
+    event->magic1 = magic1;
+    event->magic2 = magic2;
+    event->cmd = cmd;
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+
+    return krie_syscall_kprobe_enforce_policy(ctx, &event->process, event->event.action);
+}
+
+#endif
@@ -0,0 +1,71 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _SYMBOL_RESOLUTION_H_
+#define _SYMBOL_RESOLUTION_H_
+
+#define SYMBOL_RESOLUTION_LOOKUP_NAME 1
+#define SYMBOL_RESOLUTION_PROC_KALLSYMS 2
+
+struct symbol_resolution_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u64 caller;
+    u32 source;
+    u32 padding;
+    char symbol[SYMBOL_NAME_LENGTH];
+};
+
+memory_factory(symbol_resolution_event)
+
+__attribute__((always_inline)) int symbol_resolution_send(struct pt_regs *ctx, u32 source, const char *name, u64 caller) {
+    struct symbol_resolution_event_t *event = new_symbol_resolution_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    event->event.type = EVENT_SYMBOL_RESOLUTION;
+    event->source = source;
+    event->caller = caller;
+    if (name != NULL) {
+        bpf_probe_read_str(&event->symbol[0], sizeof(event->symbol), (void *)name);
+    }
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+
+    return krie_kprobe_enforce_policy(ctx, &event->process, event->event.action);
+};
+
+// kallsyms_lookup_name isn't exported since 5.7, the modules that call it found it through a kprobe, and the bpf
+// programs call it through bpf_kallsyms_lookup_name
+SEC("kprobe/kallsyms_lookup_name")
+int BPF_KPROBE(kprobe_kallsyms_lookup_name, const char *name) {
+    // the return address is at the top of the stack on function entry, it identifies the caller of the lookup
+    u64 caller = 0;
+    bpf_probe_read(&caller, sizeof(caller), (void *)PT_REGS_SP(ctx));
+    return symbol_resolution_send(ctx, SYMBOL_RESOLUTION_LOOKUP_NAME, name, caller);
+};
+
+// kallsyms_open is the open handler of /proc/kallsyms
+SEC("kprobe/kallsyms_open")
+int BPF_KPROBE(kprobe_kallsyms_open, struct inode *inode, struct file *file) {
+    return symbol_resolution_send(ctx, SYMBOL_RESOLUTION_PROC_KALLSYMS, NULL, 0);
+};
+
+#endif
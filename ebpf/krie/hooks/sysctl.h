@@ -26,6 +26,8 @@ struct sysctl_parameter_value_t {
     u16 block_write_access;
     u16 block_read_access;
     char value[MAX_SYSCTL_OBJ_LEN];
+    u32 capture_read_access;
+    u32 padding;
 };
 
 struct {
@@ -117,6 +119,12 @@ int cgroup_sysctl(struct bpf_sysctl *ctx) {
         }
     }
 
+    // writes are always captured, reads are only captured for the parameters that opted in (or when they are blocked)
+    if (!event->write_access && !decision->capture_read_access && !decision->block_read_access) {
+        u32 action = krie_run_event_check(ctx, &event->process, &event->event.type);
+        return krie_cgroup_sysctl_enforce_policy(ctx, &event->process, action);
+    }
+
     // copy the value of the control parameter
     int new_value_index = bpf_sysctl_get_current_value(ctx, &event->name_value[value_index & (MAX_SYSCTL_BUF_LEN - 1 - 3*MAX_SYSCTL_OBJ_LEN)], MAX_SYSCTL_OBJ_LEN);
     if ((int)new_value_index == -E2BIG) {
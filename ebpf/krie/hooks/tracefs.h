@@ -0,0 +1,112 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _TRACEFS_H_
+#define _TRACEFS_H_
+
+#define TRACEFS_OTHER 0
+#define TRACEFS_KPROBE_EVENTS 1
+#define TRACEFS_UPROBE_EVENTS 2
+#define TRACEFS_DYNAMIC_EVENTS 3
+
+#define TRACEFS_PROBE_DEFINITION_LEN 256
+#define TRACEFS_FILE_NAME_LEN 16
+
+struct tracefs_probe_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u32 file;
+    u32 size;
+    char definition[TRACEFS_PROBE_DEFINITION_LEN];
+};
+
+memory_factory(tracefs_probe_event)
+
+__attribute__((always_inline)) u32 get_tracefs_file(char *name) {
+    char kprobe_events[] = "kprobe_events";
+    char uprobe_events[] = "uprobe_events";
+    char dynamic_events[] = "dynamic_events";
+    int is_kprobe_events = 1, is_uprobe_events = 1, is_dynamic_events = 1;
+
+#pragma unroll
+    for (int i = 0; i < sizeof(kprobe_events); i++) {
+        if (name[i] != kprobe_events[i]) {
+            is_kprobe_events = 0;
+        }
+    }
+#pragma unroll
+    for (int i = 0; i < sizeof(uprobe_events); i++) {
+        if (name[i] != uprobe_events[i]) {
+            is_uprobe_events = 0;
+        }
+    }
+#pragma unroll
+    for (int i = 0; i < sizeof(dynamic_events); i++) {
+        if (name[i] != dynamic_events[i]) {
+            is_dynamic_events = 0;
+        }
+    }
+    if (is_kprobe_events) {
+        return TRACEFS_KPROBE_EVENTS;
+    }
+    if (is_uprobe_events) {
+        return TRACEFS_UPROBE_EVENTS;
+    }
+    if (is_dynamic_events) {
+        return TRACEFS_DYNAMIC_EVENTS;
+    }
+    return TRACEFS_OTHER;
+};
+
+// trace_parse_run_command parses the writes to kprobe_events, uprobe_events and dynamic_events: the probes created
+// through tracefs never go through register_kprobe from a module, they are only visible here
+SEC("kprobe/trace_parse_run_command")
+int BPF_KPROBE(kprobe_trace_parse_run_command, struct file *file, const char *buffer, size_t count) {
+    char name[TRACEFS_FILE_NAME_LEN] = {};
+    bpf_probe_read_str(&name[0], sizeof(name), (void *)BPF_CORE_READ(file, f_path.dentry, d_name.name));
+    u32 tracefs_file = get_tracefs_file(&name[0]);
+    if (tracefs_file == TRACEFS_OTHER) {
+        return 0;
+    }
+
+    struct tracefs_probe_event_t *event = new_tracefs_probe_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    event->event.type = EVENT_TRACEFS_PROBE;
+    event->file = tracefs_file;
+    event->size = count;
+
+    // the definition is truncated to TRACEFS_PROBE_DEFINITION_LEN - 1, size reports the length of the write
+    u32 len = count;
+    if (len >= TRACEFS_PROBE_DEFINITION_LEN) {
+        len = TRACEFS_PROBE_DEFINITION_LEN - 1;
+    }
+    len &= (TRACEFS_PROBE_DEFINITION_LEN - 1);
+    bpf_probe_read_user(&event->definition[0], len, buffer);
+    event->definition[len & (TRACEFS_PROBE_DEFINITION_LEN - 1)] = 0;
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+
+    return krie_kprobe_enforce_policy(ctx, &event->process, event->event.action);
+};
+
+#endif
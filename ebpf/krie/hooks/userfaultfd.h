@@ -0,0 +1,151 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _USERFAULTFD_H_
+#define _USERFAULTFD_H_
+
+#define USERFAULTFD_CREATE 1
+#define USERFAULTFD_API 2
+
+#define UFFDIO_API 0xc018aa3f
+
+struct userfaultfd_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u64 api;
+    u64 features;
+    u32 operation;
+    u32 flags;
+};
+
+memory_factory(userfaultfd_event)
+
+SYSCALL_KPROBE1(userfaultfd, int, flags) {
+    struct syscall_cache_t syscall = {
+        .type = EVENT_USERFAULTFD,
+        .userfaultfd = {
+            .flags = flags,
+        },
+    };
+    cache_syscall(&syscall);
+
+    // create process context for KRIE detection
+    struct userfaultfd_event_t *event = new_userfaultfd_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    fill_process_context(&event->process);
+
+    // we're about to allow this call to go through, double check with KRIE
+    u32 action = krie_run_event_check(ctx, &event->process, &syscall.type);
+
+    // pop cache if need be
+    if (action > KRIE_ACTION_LOG) {
+        pop_syscall(EVENT_USERFAULTFD);
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, &event->process, action);
+}
+
+__attribute__((always_inline)) struct process_context_t *sys_userfaultfd_ret(void *ctx, int retval, u32 *action) {
+    struct syscall_cache_t *syscall = pop_syscall(EVENT_USERFAULTFD);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct userfaultfd_event_t *event = new_userfaultfd_event();
+    if (event == NULL) {
+        // ignore, should not happen
+        return 0;
+    }
+    event->event.type = EVENT_USERFAULTFD;
+    event->event.retval = retval;
+    event->operation = USERFAULTFD_CREATE;
+    event->flags = syscall->userfaultfd.flags;
+    event->api = 0;
+    event->features = 0;
+
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+    *action = event->event.action;
+
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+    return &event->process;
+}
+
+SYSCALL_KRETPROBE(userfaultfd) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_userfaultfd_ret(ctx, (int)PT_REGS_RC(ctx), &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_syscall_kprobe_enforce_policy(ctx, process_ctx, action);
+}
+
+SEC("tracepoint/handle_sys_userfaultfd_exit")
+int tracepoint_handle_sys_userfaultfd_exit(struct tracepoint_raw_syscalls_sys_exit_t *args) {
+    u32 action = KRIE_ACTION_NOP;
+    struct process_context_t *process_ctx = sys_userfaultfd_ret(args, args->ret, &action);
+    if (process_ctx == NULL) {
+        return 0;
+    }
+
+    return krie_tp_enforce_policy(args, process_ctx, action);
+}
+
+// the UFFDIO_API handshake enables the features of a userfaultfd, it is required before registering a memory range.
+// The file descriptors opened through /dev/userfaultfd skip the userfaultfd syscall, but not the handshake.
+SEC("kprobe/userfaultfd_ioctl")
+int BPF_KPROBE(kprobe_userfaultfd_ioctl, struct file *file, unsigned int cmd, unsigned long arg) {
+    if (cmd != UFFDIO_API) {
+        return 0;
+    }
+
+    struct userfaultfd_event_t *event = new_userfaultfd_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    event->event.type = EVENT_USERFAULTFD;
+    event->operation = USERFAULTFD_API;
+
+    // struct uffdio_api starts with the api and the requested features
+    bpf_probe_read_user(&event->api, sizeof(event->api), (void *)arg);
+    bpf_probe_read_user(&event->features, sizeof(event->features), (void *)arg + sizeof(u64));
+
+    // the flags of the userfaultfd tell if the kernel faults are handled (no UFFD_USER_MODE_ONLY)
+    struct userfaultfd_ctx *uffd_ctx = (struct userfaultfd_ctx *)BPF_CORE_READ(file, private_data);
+    event->flags = BPF_CORE_READ(uffd_ctx, flags);
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+
+    return krie_kprobe_enforce_policy(ctx, &event->process, event->event.action);
+};
+
+#endif
@@ -0,0 +1,174 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _USERMODE_HELPER_H_
+#define _USERMODE_HELPER_H_
+
+#define USERMODE_HELPER_WRITE 1
+#define USERMODE_HELPER_EXEC 2
+
+#define USERMODE_HELPER_OTHER 0
+#define USERMODE_HELPER_MODPROBE 1
+#define USERMODE_HELPER_CORE_PATTERN 2
+
+#define USERMODE_HELPER_PATH_LEN 256
+#define USERMODE_HELPER_PROCNAME_LEN 16
+
+struct usermode_helper_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u32 operation;
+    u32 helper;
+    char old_path[USERMODE_HELPER_PATH_LEN];
+    char new_path[USERMODE_HELPER_PATH_LEN];
+};
+
+memory_factory(usermode_helper_event)
+
+struct usermode_helper_write_t {
+    u32 helper;
+    u32 padding;
+    void *data;
+    char old_path[USERMODE_HELPER_PATH_LEN];
+};
+
+// the writes to the usermode helper sysctls in progress, the old value is read before the handler overwrites it
+struct {
+	__uint(type, BPF_MAP_TYPE_LRU_HASH);
+	__type(key, u64);
+	__type(value, struct usermode_helper_write_t);
+	__uint(max_entries, 128);
+} usermode_helper_writes SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+	__type(key, u32);
+	__type(value, struct usermode_helper_write_t);
+	__uint(max_entries, 1);
+} usermode_helper_write_gen SEC(".maps");
+
+__attribute__((always_inline)) u32 get_usermode_helper(char *procname) {
+    char modprobe[] = "modprobe";
+    char core_pattern[] = "core_pattern";
+    int is_modprobe = 1, is_core_pattern = 1;
+
+#pragma unroll
+    for (int i = 0; i < sizeof(modprobe); i++) {
+        if (procname[i] != modprobe[i]) {
+            is_modprobe = 0;
+        }
+    }
+#pragma unroll
+    for (int i = 0; i < sizeof(core_pattern); i++) {
+        if (procname[i] != core_pattern[i]) {
+            is_core_pattern = 0;
+        }
+    }
+    if (is_modprobe) {
+        return USERMODE_HELPER_MODPROBE;
+    }
+    if (is_core_pattern) {
+        return USERMODE_HELPER_CORE_PATTERN;
+    }
+    return USERMODE_HELPER_OTHER;
+};
+
+__attribute__((always_inline)) int usermode_helper_send(void *ctx, struct usermode_helper_event_t *event) {
+    event->event.type = EVENT_USERMODE_HELPER;
+    fill_process_context(&event->process);
+
+    // filter krie runtime
+    if (filter_krie_runtime()) {
+        return 0;
+    }
+
+    // run KRIE detections
+    event->event.action = krie_run_event_check(ctx, &event->process, &event->event.type);
+
+    // send event
+    int perf_ret;
+    send_event_ptr(ctx, event->event.type, event);
+
+    return krie_kprobe_enforce_policy(ctx, &event->process, event->event.action);
+};
+
+// proc_dostring handles kernel.modprobe, and kernel.core_pattern through proc_dostring_coredump
+SEC("kprobe/proc_dostring")
+int BPF_KPROBE(kprobe_proc_dostring, struct ctl_table *table, int write) {
+    if (!write) {
+        return 0;
+    }
+
+    char procname[USERMODE_HELPER_PROCNAME_LEN] = {};
+    bpf_probe_read_str(&procname[0], sizeof(procname), (void *)BPF_CORE_READ(table, procname));
+    u32 helper = get_usermode_helper(&procname[0]);
+    if (helper == USERMODE_HELPER_OTHER) {
+        return 0;
+    }
+
+    u32 key = 0;
+    struct usermode_helper_write_t *in_progress = bpf_map_lookup_elem(&usermode_helper_write_gen, &key);
+    if (in_progress == NULL) {
+        // should never happen
+        return 0;
+    }
+    in_progress->helper = helper;
+    in_progress->data = BPF_CORE_READ(table, data);
+    bpf_probe_read_str(&in_progress->old_path[0], sizeof(in_progress->old_path), in_progress->data);
+
+    u64 id = bpf_get_current_pid_tgid();
+    bpf_map_update_elem(&usermode_helper_writes, &id, in_progress, BPF_ANY);
+    return 0;
+};
+
+SEC("kretprobe/proc_dostring")
+int BPF_KRETPROBE(kretprobe_proc_dostring, int retval) {
+    u64 id = bpf_get_current_pid_tgid();
+    struct usermode_helper_write_t *in_progress = bpf_map_lookup_elem(&usermode_helper_writes, &id);
+    if (in_progress == NULL) {
+        return 0;
+    }
+
+    struct usermode_helper_event_t *event = new_usermode_helper_event();
+    if (event == NULL) {
+        // should never happen
+        bpf_map_delete_elem(&usermode_helper_writes, &id);
+        return 0;
+    }
+    event->event.retval = retval;
+    event->operation = USERMODE_HELPER_WRITE;
+    event->helper = in_progress->helper;
+    bpf_probe_read_str(&event->old_path[0], sizeof(event->old_path), &in_progress->old_path[0]);
+    bpf_probe_read_str(&event->new_path[0], sizeof(event->new_path), in_progress->data);
+    bpf_map_delete_elem(&usermode_helper_writes, &id);
+
+    return usermode_helper_send(ctx, event);
+};
+
+// call_usermodehelper_exec runs every usermode helper: the helpers requested by request_module use modprobe_path
+// directly, an exploit that overwrote modprobe_path in memory shows up here without any sysctl write
+SEC("kprobe/call_usermodehelper_exec")
+int BPF_KPROBE(kprobe_call_usermodehelper_exec, struct subprocess_info *sub_info) {
+    struct usermode_helper_event_t *event = new_usermode_helper_event();
+    if (event == NULL) {
+        // should never happen
+        return 0;
+    }
+    const char *path = BPF_CORE_READ(sub_info, path);
+    event->operation = USERMODE_HELPER_EXEC;
+    event->helper = USERMODE_HELPER_OTHER;
+    if ((u64)path == (u64)get_kallsyms_addr(KALLSYMS_MODPROBE_PATH)) {
+        event->helper = USERMODE_HELPER_MODPROBE;
+    }
+    event->old_path[0] = 0;
+    bpf_probe_read_str(&event->new_path[0], sizeof(event->new_path), (void *)path);
+
+    return usermode_helper_send(ctx, event);
+};
+
+#endif
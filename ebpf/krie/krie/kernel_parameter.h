@@ -72,7 +72,7 @@ __attribute__((always_inline)) u32 run_kernel_parameter_check(void *ctx, struct
             triggered = 1;
             event->addr = param->addr;
             event->expected_value = param->expected_value;
-            perf_ret = bpf_perf_event_output(ctx, &events, event->event.cpu, event, size);
+            perf_ret = output_event(ctx, event->event.type, event->event.cpu, event, size);
             if (perf_ret == 0) {
                 param->last_sent = now;
             }
@@ -13,12 +13,14 @@
 #define KALLSYMS_IA32_SYS_CALL_TABLE 2
 #define KALLSYMS_STEXT               3
 #define KALLSYMS_ETEXT               4
+#define KALLSYMS_MODPROBE_PATH       5
+#define KALLSYMS_SIG_ENFORCE         6
 
 struct {
 	__uint(type, BPF_MAP_TYPE_HASH);
 	__type(key, u32);
 	__type(value, u64);
-	__uint(max_entries, 5);
+	__uint(max_entries, 7);
 } kallsyms SEC(".maps");
 
 static __attribute__((always_inline)) u64 *get_kallsyms_addr(u32 entry) {
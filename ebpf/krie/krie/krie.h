@@ -22,6 +22,7 @@
 #include "kill_switch.h"
 #include "event_check.h"
 #include "kernel_parameter.h"
+#include "sig_enforce.h"
 #include "task.h"
 
 //static __attribute__((always_inline)) u8 is_set(u64 input, u64 flag) {
@@ -57,6 +58,12 @@ static __attribute__((always_inline)) u32 krie_run_event_check(void *ctx, struct
     };
     u32 check_action = KRIE_ACTION_NOP;
 
+    // the self-generated events are excluded from the kernel policies: KRIE never blocks nor kills itself, and doesn't
+    // report the checks of its own calls
+    if (is_krie_runtime()) {
+        return KRIE_ACTION_NOP;
+    }
+
     check_action = run_event_check(ctx, process_ctx, data);
     if (policy.action < check_action) {
         policy.action = check_action;
@@ -178,6 +185,10 @@ static __attribute__((always_inline)) u32 krie_run_kernel_parameter_detection(vo
     if (policy.action < check_action) {
         policy.action = check_action;
     }
+    check_action = run_sig_enforce_check(ctx, process_ctx);
+    if (policy.action < check_action) {
+        policy.action = check_action;
+    }
 
     // set process kill switch
     if (policy.action == KRIE_ACTION_KILL) {
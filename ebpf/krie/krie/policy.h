@@ -29,6 +29,23 @@ __attribute__((always_inline)) struct policy_t *get_policy(u32 event_type) {
     return bpf_map_lookup_elem(&policies, &event_type);
 };
 
+// burst_pids contains the processes in burst capture mode, the value is the monotonic deadline of the capture
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__type(key, u32);
+	__type(value, u64);
+	__uint(max_entries, 1024);
+} burst_pids SEC(".maps");
+
+__attribute__((always_inline)) int is_in_burst_capture() {
+    u32 tgid = bpf_get_current_pid_tgid() >> 32;
+    u64 *deadline = bpf_map_lookup_elem(&burst_pids, &tgid);
+    if (deadline == NULL) {
+        return 0;
+    }
+    return *deadline > bpf_ktime_get_ns();
+};
+
 // program types
 #define KPROBE_PROG        1
 #define TRACEPOINT_PROG    2
@@ -122,7 +139,11 @@ __attribute__((always_inline)) int krie_lsm_enforce_policy(void *ctx, struct pro
         return KRIE_ACTION_NOP;                                                                                        \
     }                                                                                                                  \
     if (policy->action == KRIE_ACTION_NOP) {                                                                           \
-        return KRIE_ACTION_NOP;                                                                                        \
+        if (!is_in_burst_capture()) {                                                                                  \
+            return KRIE_ACTION_NOP;                                                                                    \
+        }                                                                                                              \
+        new_policy.action = KRIE_ACTION_LOG;                                                                           \
+        policy = &new_policy;                                                                                          \
     }                                                                                                                  \
 
 #define fetch_policy_or_log(check)                                                                                     \
@@ -136,7 +157,11 @@ __attribute__((always_inline)) int krie_lsm_enforce_policy(void *ctx, struct pro
         return KRIE_ACTION_NOP;                                                                                        \
     }                                                                                                                  \
     if (policy->action == KRIE_ACTION_NOP) {                                                                           \
-        return KRIE_ACTION_NOP;                                                                                        \
+        if (!is_in_burst_capture()) {                                                                                  \
+            return KRIE_ACTION_NOP;                                                                                    \
+        }                                                                                                              \
+        new_policy.action = KRIE_ACTION_LOG;                                                                           \
+        policy = &new_policy;                                                                                          \
     }                                                                                                                  \
 
 #endif
\ No newline at end of file
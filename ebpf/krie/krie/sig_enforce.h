@@ -0,0 +1,102 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+/* Copyright (c) 2020
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of version 2 of the GNU General Public
+ * License as published by the Free Software Foundation.
+ */
+#ifndef _SIG_ENFORCE_H_
+#define _SIG_ENFORCE_H_
+
+#define MODULE_SIGNATURE_LOAD 1
+#define MODULE_SIGNATURE_FLIP 2
+
+#define SIG_ENFORCE_DISABLED 0
+#define SIG_ENFORCE_ENABLED  1
+#define SIG_ENFORCE_UNKNOWN  2
+
+struct module_signature_event_t {
+    struct kernel_event_t event;
+    struct process_context_t process;
+
+    u32 kind;
+    u32 sig_enforce;
+    u32 previous_sig_enforce;
+    u32 padding;
+    char name[MODULE_NAME_LEN];
+};
+
+memory_factory(module_signature_event)
+
+struct sig_enforce_state_t {
+    u32 value;
+    u32 initialized;
+};
+
+// the last value of module.sig_enforce seen by the checks
+struct {
+	__uint(type, BPF_MAP_TYPE_ARRAY);
+	__type(key, u32);
+	__type(value, struct sig_enforce_state_t);
+	__uint(max_entries, 1);
+} sig_enforce_state SEC(".maps");
+
+// read_sig_enforce returns the value of module.sig_enforce, it is unknown without CONFIG_MODULE_SIG or when the symbol
+// isn't in kallsyms
+__attribute__((always_inline)) u32 read_sig_enforce() {
+    void *addr = get_kallsyms_addr(KALLSYMS_SIG_ENFORCE);
+    if (addr == NULL) {
+        return SIG_ENFORCE_UNKNOWN;
+    }
+    u8 value = 0;
+    if (bpf_probe_read_kernel(&value, sizeof(value), addr) < 0) {
+        return SIG_ENFORCE_UNKNOWN;
+    }
+    return value ? SIG_ENFORCE_ENABLED : SIG_ENFORCE_DISABLED;
+};
+
+// run_sig_enforce_check reports the runtime changes of module.sig_enforce: the parameter can only be enabled through
+// sysfs, disabling it requires a write to kernel memory
+__attribute__((always_inline)) u32 run_sig_enforce_check(void *ctx, struct process_context_t *process_ctx) {
+    u32 value = read_sig_enforce();
+    if (value == SIG_ENFORCE_UNKNOWN) {
+        return KRIE_ACTION_NOP;
+    }
+    u32 key = 0;
+    struct sig_enforce_state_t *state = bpf_map_lookup_elem(&sig_enforce_state, &key);
+    if (state == NULL) {
+        // should never happen, ignore
+        return KRIE_ACTION_NOP;
+    }
+    if (!state->initialized) {
+        state->value = value;
+        state->initialized = 1;
+        return KRIE_ACTION_NOP;
+    }
+    u32 previous = state->value;
+    if (previous == value) {
+        return KRIE_ACTION_NOP;
+    }
+    // the checks of the other CPUs may report the same change
+    state->value = value;
+
+    struct module_signature_event_t *event = new_module_signature_event();
+    if (event == NULL) {
+        // should never happen, ignore
+        return KRIE_ACTION_NOP;
+    }
+    event->event.type = EVENT_MODULE_SIGNATURE;
+    copy_process_ctx(&event->process, process_ctx);
+    fetch_policy_or_block(event->event.type)
+    event->event.action = policy->action;
+    event->event.cpu = bpf_get_smp_processor_id();
+    event->event.timestamp = bpf_ktime_get_ns();
+    event->kind = MODULE_SIGNATURE_FLIP;
+    event->sig_enforce = value;
+    event->previous_sig_enforce = previous;
+
+    output_event(ctx, event->event.type, event->event.cpu, event, sizeof(*event));
+    return policy->action;
+};
+
+#endif
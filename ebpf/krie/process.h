@@ -50,10 +50,26 @@ struct process_context_t {
     struct cgroup_context_t cgroups[CGROUP_SUBSYS_COUNT + 1];
     u32 pid;
     u32 tid;
+    u32 args_len;
+    u32 args_truncated;
+    char args[ARGS_MAX_LENGTH];
 };
 
 memory_factory(process_context)
 
+__attribute__((always_inline)) void fill_namespace_context(struct namespace_context_t *ns, struct task_struct *task) {
+    BPF_CORE_READ_INTO(&ns->cgroup_namespace, task, nsproxy, cgroup_ns, ns.inum);
+    BPF_CORE_READ_INTO(&ns->ipc_namespace, task, nsproxy, ipc_ns, ns.inum);
+    BPF_CORE_READ_INTO(&ns->net_namespace, task, nsproxy, net_ns, ns.inum);
+    BPF_CORE_READ_INTO(&ns->mnt_namespace, task, nsproxy, mnt_ns, ns.inum);
+    BPF_CORE_READ_INTO(&ns->pid_namespace, task, nsproxy, pid_ns_for_children, ns.inum);
+    if (bpf_core_field_exists(task->nsproxy->time_ns->ns.inum)) {
+        BPF_CORE_READ_INTO(&ns->time_namespace, task, nsproxy, time_ns, ns.inum);
+    }
+    BPF_CORE_READ_INTO(&ns->user_namespace, task, cred, user_ns, ns.inum);
+    BPF_CORE_READ_INTO(&ns->uts_namespace, task, nsproxy, uts_ns, ns.inum);
+}
+
 __attribute__((always_inline)) int fill_process_context(struct process_context_t *ctx) {
     // fetch current task
     struct task_struct* task = (struct task_struct*)bpf_get_current_task();
@@ -93,30 +109,47 @@ __attribute__((always_inline)) int fill_process_context(struct process_context_t
     BPF_CORE_READ_INTO(&ctx->credentials.cap_ambient, task, cred, cap_ambient);
 
     // fetch process namespaces
-    BPF_CORE_READ_INTO(&ctx->namespaces.cgroup_namespace, task, nsproxy, cgroup_ns, ns.inum);
-    BPF_CORE_READ_INTO(&ctx->namespaces.ipc_namespace, task, nsproxy, ipc_ns, ns.inum);
-    BPF_CORE_READ_INTO(&ctx->namespaces.net_namespace, task, nsproxy, net_ns, ns.inum);
-    BPF_CORE_READ_INTO(&ctx->namespaces.mnt_namespace, task, nsproxy, mnt_ns, ns.inum);
-    BPF_CORE_READ_INTO(&ctx->namespaces.pid_namespace, task, nsproxy, pid_ns_for_children, ns.inum);
-    if (bpf_core_field_exists(task->nsproxy->time_ns->ns.inum)) {
-        BPF_CORE_READ_INTO(&ctx->namespaces.time_namespace, task, nsproxy, time_ns, ns.inum);
+    fill_namespace_context(&ctx->namespaces, task);
+
+    // fetch process arguments (kernel threads have no mm, and therefore no arguments), unless the privacy mode is on
+    u64 arg_start = 0, arg_end = 0;
+    if (get_krie_capture_args() == 0) {
+        ctx->args_len = 0;
+        ctx->args_truncated = 0;
+        ctx->args[0] = 0;
+        return 0;
+    }
+    BPF_CORE_READ_INTO(&arg_start, task, mm, arg_start);
+    BPF_CORE_READ_INTO(&arg_end, task, mm, arg_end);
+    u64 args_len = arg_end > arg_start ? arg_end - arg_start : 0;
+    ctx->args_truncated = 0;
+    if (args_len >= ARGS_MAX_LENGTH) {
+        args_len = ARGS_MAX_LENGTH - 1;
+        ctx->args_truncated = 1;
     }
-    BPF_CORE_READ_INTO(&ctx->namespaces.user_namespace, task, cred, user_ns, ns.inum);
-    BPF_CORE_READ_INTO(&ctx->namespaces.uts_namespace, task, nsproxy, uts_ns, ns.inum);
+    ctx->args_len = 0;
+    if (args_len > 0 && bpf_probe_read_user(ctx->args, args_len & (ARGS_MAX_LENGTH - 1), (void *)arg_start) == 0) {
+        ctx->args_len = args_len;
+    }
+    ctx->args[args_len & (ARGS_MAX_LENGTH - 1)] = 0;
     return 0;
 }
 
 __attribute__((always_inline)) void copy_process_ctx(struct process_context_t *dst, struct process_context_t *src) {
     dst->pid = src->pid;
     dst->tid = src->pid;
-    __builtin_memmove(dst->comm, src->comm, TASK_COMM_LEN);
-    __builtin_memmove(&dst->namespaces, &src->namespaces, sizeof(struct namespace_context_t));
-    __builtin_memmove(&dst->credentials, &src->credentials, sizeof(struct credentials_context_t));
+    __builtin_memcpy(dst->comm, src->comm, TASK_COMM_LEN);
+    __builtin_memcpy(&dst->namespaces, &src->namespaces, sizeof(struct namespace_context_t));
+    __builtin_memcpy(&dst->credentials, &src->credentials, sizeof(struct credentials_context_t));
 
     #pragma unroll
     for (u32 i = 0; i <= CGROUP_SUBSYS_COUNT; i++) {
-        __builtin_memmove(&dst->cgroups[i], &src->cgroups[i], sizeof(struct cgroup_context_t));
+        __builtin_memcpy(&dst->cgroups[i], &src->cgroups[i], sizeof(struct cgroup_context_t));
     }
+
+    dst->args_len = src->args_len;
+    dst->args_truncated = src->args_truncated;
+    __builtin_memcpy(dst->args, src->args, ARGS_MAX_LENGTH);
     return;
 };
 
@@ -21,11 +21,17 @@ struct tracepoint_raw_syscalls_sys_exit_t
 
 struct syscall_cache_t {
     u64 type;
+    u64 timestamp;
 
     union {
         struct {
             char name[MODULE_NAME_LEN];
             u32 loaded_from_memory;
+            int fd;
+            u32 dev;
+            u64 ino;
+            u32 nlink;
+            char file_name[MODULE_FILE_NAME_LEN];
         } init_module;
 
         struct {
@@ -55,6 +61,90 @@ struct syscall_cache_t {
             u64 addr;
         } ptrace;
 
+        struct {
+            u64 entry;
+            u64 nr_segments;
+            u64 flags;
+            int kernel_fd;
+            int initrd_fd;
+            u32 file_load;
+            u32 cmdline_len;
+            const char *cmdline;
+        } kexec;
+
+        struct {
+            struct io_uring_params *params;
+            void *arg;
+            u32 cmd;
+            u32 entries;
+            u32 fd;
+            u32 opcode;
+            u32 nr_args;
+        } io_uring;
+
+        struct {
+            struct perf_event_attr *attr;
+            u64 flags;
+            s32 pid;
+            s32 cpu;
+            s32 group_fd;
+        } perf_event_open;
+
+        struct {
+            void *remote_iov;
+            u64 local_iovcnt;
+            u64 remote_iovcnt;
+            u64 flags;
+            u32 operation;
+            u32 pid;
+        } process_vm;
+
+        struct {
+            void *source;
+            void *target;
+            u64 flags;
+            u32 fs;
+        } mount;
+
+        struct {
+            u64 value;
+            u64 flags;
+            u32 operation;
+        } prctl;
+
+        struct {
+            u64 effective_before;
+            u64 permitted_before;
+            u64 inheritable_before;
+            u64 effective_after;
+            u64 permitted_after;
+            u64 inheritable_after;
+        } capset;
+
+        struct {
+            u32 action;
+        } syslog;
+
+        struct {
+            void *key_type;
+            void *description;
+            u64 payload_len;
+            u32 syscall;
+            u32 keyctl_cmd;
+            s32 key;
+        } keyring;
+
+        struct {
+            struct namespace_context_t previous;
+            u64 flags;
+            u32 syscall;
+            s32 fd;
+        } namespace;
+
+        struct {
+            u32 flags;
+        } userfaultfd;
+
         struct {
             struct kprobe *p;
             u32 kprobe_type;
@@ -70,8 +160,73 @@ struct {
 	__uint(max_entries, 1024);
 } syscalls SEC(".maps");
 
+#define SYSCALL_ORPHAN_OVERWRITTEN 1
+#define SYSCALL_ORPHAN_EXPIRED 2
+#define SYSCALL_ORPHAN_UNMATCHED_EXIT 3
+
+struct syscall_orphan_sample_t {
+    u64 timestamp;
+    u64 age;
+    u64 pid_tgid;
+    u32 kind;
+    u32 padding;
+};
+
+struct syscall_orphans_t {
+    u64 overwritten;
+    u64 expired;
+    u64 unmatched_exits;
+    struct syscall_orphan_sample_t last;
+};
+
+// syscall_orphans counts, per event type, the entries which lost their exit and the exits which lost their entry
+struct {
+	__uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+	__type(key, u32);
+	__type(value, struct syscall_orphans_t);
+	__uint(max_entries, EVENT_MAX);
+} syscall_orphans SEC(".maps");
+
+void __attribute__((always_inline)) record_syscall_orphan(u64 type, u32 kind, u64 age) {
+    u32 key = type;
+    struct syscall_orphans_t *orphans = bpf_map_lookup_elem(&syscall_orphans, &key);
+    if (orphans == NULL) {
+        return;
+    }
+
+    switch (kind) {
+        case SYSCALL_ORPHAN_OVERWRITTEN:
+            orphans->overwritten++;
+            break;
+        case SYSCALL_ORPHAN_EXPIRED:
+            orphans->expired++;
+            break;
+        case SYSCALL_ORPHAN_UNMATCHED_EXIT:
+            orphans->unmatched_exits++;
+            break;
+    }
+    orphans->last.timestamp = bpf_ktime_get_ns();
+    orphans->last.age = age;
+    orphans->last.pid_tgid = bpf_get_current_pid_tgid();
+    orphans->last.kind = kind;
+}
+
+__attribute__((always_inline)) int is_syscall_expired(struct syscall_cache_t *syscall, u64 now) {
+    u64 timeout = get_syscall_correlation_timeout();
+    return timeout > 0 && now - syscall->timestamp > timeout;
+}
+
 void __attribute__((always_inline)) cache_syscall(struct syscall_cache_t *syscall) {
     u64 key = bpf_get_current_pid_tgid();
+    u64 now = bpf_ktime_get_ns();
+
+    // a thread is in one syscall at a time: the previous entry of the thread lost its exit
+    struct syscall_cache_t *previous = (struct syscall_cache_t *)bpf_map_lookup_elem(&syscalls, &key);
+    if (previous != NULL) {
+        record_syscall_orphan(previous->type, SYSCALL_ORPHAN_OVERWRITTEN, now - previous->timestamp);
+    }
+
+    syscall->timestamp = now;
     bpf_map_update_elem(&syscalls, &key, syscall, BPF_ANY);
 }
 
@@ -81,6 +236,10 @@ struct syscall_cache_t *__attribute__((always_inline)) peek_syscall(u64 type) {
     if (!syscall) {
         return NULL;
     }
+    if (is_syscall_expired(syscall, bpf_ktime_get_ns())) {
+        // the entry is reported when it is popped or overwritten
+        return NULL;
+    }
     if (!type || syscall->type == type) {
         return syscall;
     }
@@ -93,10 +252,21 @@ struct syscall_cache_t *__attribute__((always_inline)) pop_syscall(u64 type) {
     if (!syscall) {
         return NULL;
     }
+
+    u64 now = bpf_ktime_get_ns();
+    if (is_syscall_expired(syscall, now)) {
+        // the entry lost its exit, don't correlate it with the exit of a later call
+        record_syscall_orphan(syscall->type, SYSCALL_ORPHAN_EXPIRED, now - syscall->timestamp);
+        bpf_map_delete_elem(&syscalls, &key);
+        return NULL;
+    }
     if (!type || syscall->type == type) {
         bpf_map_delete_elem(&syscalls, &key);
         return syscall;
     }
+
+    // the entry of this exit was overwritten by the entry of another event type
+    record_syscall_orphan(type, SYSCALL_ORPHAN_UNMATCHED_EXIT, now - syscall->timestamp);
     return NULL;
 }
 
@@ -0,0 +1,64 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// manifest computes the checksums of the eBPF programs variants listed in ebpf/variants.json, and writes the manifest
+// embedded in the assets of KRIE.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/assets"
+)
+
+func main() {
+	input := flag.String("input", "ebpf/variants.json", "list of the variants of the eBPF programs")
+	bin := flag.String("bin", "ebpf/bin", "directory of the compiled eBPF programs")
+	output := flag.String("output", "ebpf/bin/variants.json", "path of the generated manifest")
+	flag.Parse()
+
+	data, err := os.ReadFile(*input)
+	if err != nil {
+		logrus.Fatalf("couldn't read %s: %v", *input, err)
+	}
+	var variants []assets.Variant
+	if err = json.Unmarshal(data, &variants); err != nil {
+		logrus.Fatalf("couldn't parse %s: %v", *input, err)
+	}
+
+	for i := range variants {
+		object, err := os.ReadFile(filepath.Join(*bin, variants[i].Asset))
+		if err != nil {
+			logrus.Fatalf("couldn't read variant %s: %v", variants[i].Name, err)
+		}
+		sum := sha256.Sum256(object)
+		variants[i].SHA256 = hex.EncodeToString(sum[:])
+	}
+
+	if data, err = json.MarshalIndent(variants, "", "  "); err != nil {
+		logrus.Fatalf("couldn't generate the manifest: %v", err)
+	}
+	if err = os.WriteFile(*output, append(data, '\n'), 0644); err != nil {
+		logrus.Fatalf("couldn't write %s: %v", *output, err)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// VariantsManifest is the name of the asset listing the embedded variants of the eBPF programs
+const VariantsManifest = "/variants.json"
+
+// Variant is an embedded build of the eBPF programs, for the kernels of a version range
+type Variant struct {
+	// Name identifies the variant in the logs and metrics
+	Name string `json:"name"`
+	// Asset is the name of the embedded object file
+	Asset string `json:"asset"`
+	// MinKernel is the first kernel version supported by the variant (inclusive), empty for no lower bound
+	MinKernel string `json:"min_kernel,omitempty"`
+	// MaxKernel is the first kernel version not supported by the variant (exclusive), empty for no upper bound
+	MaxKernel string `json:"max_kernel,omitempty"`
+	// SyscallWrapper is set when the variant expects the syscall wrapper introduced in kernel 4.17
+	SyscallWrapper bool `json:"syscall_wrapper"`
+	// SHA256 is the checksum of the object file, computed at build time
+	SHA256 string `json:"sha256"`
+}
+
+// Verify returns an error if the checksum of the provided object file doesn't match the checksum of the variant
+func (v *Variant) Verify(data []byte) error {
+	sum := sha256.Sum256(data)
+	if checksum := hex.EncodeToString(sum[:]); checksum != v.SHA256 {
+		return fmt.Errorf("checksum mismatch for variant %s (%s): expected %s, got %s", v.Name, v.Asset, v.SHA256, checksum)
+	}
+	return nil
+}
+
+// Variants returns the embedded variants of the eBPF programs, as listed in the manifest generated at build time
+func Variants() ([]Variant, error) {
+	data, err := Asset(VariantsManifest)
+	if err != nil {
+		return nil, err
+	}
+	var variants []Variant
+	if err = json.Unmarshal(data, &variants); err != nil {
+		return nil, fmt.Errorf("couldn't parse %s: %w", VariantsManifest, err)
+	}
+	return variants, nil
+}
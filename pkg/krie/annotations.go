@@ -0,0 +1,295 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	// AnnotationAck is the status of an event acknowledged by an analyst
+	AnnotationAck = "ack"
+	// AnnotationFalsePositive is the status of an event flagged as a false positive by an analyst
+	AnnotationFalsePositive = "false_positive"
+)
+
+// Annotation is the triage note of an analyst on an event
+type Annotation struct {
+	EventID string `json:"event_id"`
+	Status  string `json:"status"`
+	// RuleID is the rule of the event, it lets the reports group the annotations per rule
+	RuleID    string    `json:"rule_id,omitempty"`
+	TicketID  string    `json:"ticket_id,omitempty"`
+	Author    string    `json:"author,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsValid returns an error if the annotation is invalid
+func (a *Annotation) IsValid() error {
+	if len(a.EventID) == 0 {
+		return fmt.Errorf("missing event_id")
+	}
+	switch a.Status {
+	case AnnotationAck, AnnotationFalsePositive:
+	default:
+		return fmt.Errorf("invalid status %q, expected %q or %q", a.Status, AnnotationAck, AnnotationFalsePositive)
+	}
+	return nil
+}
+
+// newAnnotationEvent returns the annotation event recording the provided annotation
+func newAnnotationEvent(annotation *Annotation) *events.Event {
+	event := events.NewEvent()
+	event.Kernel.Type = events.AnnotationEventType
+	event.AnnotationEvent = events.AnnotationEvent{
+		EventID:  annotation.EventID,
+		Status:   annotation.Status,
+		RuleID:   annotation.RuleID,
+		TicketID: annotation.TicketID,
+		Author:   annotation.Author,
+		Comment:  annotation.Comment,
+	}
+	return event
+}
+
+// storedAnnotation is the subset of the JSON representation of an annotation event parsed by parseAnnotation
+type storedAnnotation struct {
+	Annotation *events.AnnotationEvent `json:"annotation"`
+}
+
+// parseAnnotation returns the annotation recorded by a stored annotation event
+func parseAnnotation(event *StoredEvent) (*Annotation, error) {
+	var stored storedAnnotation
+	if err := json.Unmarshal(event.Data, &stored); err != nil {
+		return nil, err
+	}
+	if stored.Annotation == nil {
+		return nil, fmt.Errorf("missing annotation section")
+	}
+	return &Annotation{
+		EventID:   stored.Annotation.EventID,
+		Status:    stored.Annotation.Status,
+		RuleID:    stored.Annotation.RuleID,
+		TicketID:  stored.Annotation.TicketID,
+		Author:    stored.Annotation.Author,
+		Comment:   stored.Annotation.Comment,
+		CreatedAt: event.Time,
+	}, nil
+}
+
+// serializedAnnotation returns the annotation recorded by a serialized annotation event, or nil if the event isn't an
+// annotation event
+func serializedAnnotation(serializer *events.EventSerializer) *Annotation {
+	if serializer.AnnotationEventSerializer == nil || serializer.AnnotationEventSerializer.AnnotationEvent == nil {
+		return nil
+	}
+	annotation := serializer.AnnotationEventSerializer.AnnotationEvent
+	out := &Annotation{
+		EventID:  annotation.EventID,
+		Status:   annotation.Status,
+		RuleID:   annotation.RuleID,
+		TicketID: annotation.TicketID,
+		Author:   annotation.Author,
+		Comment:  annotation.Comment,
+	}
+	if serializer.KernelEventSerializer != nil && serializer.KernelEventSerializer.KernelEvent != nil {
+		out.CreatedAt = serializer.KernelEventSerializer.KernelEvent.Time
+	}
+	return out
+}
+
+// AnnotationSummary summarizes the annotations of a report
+type AnnotationSummary struct {
+	Acknowledged   int `json:"acknowledged"`
+	FalsePositives int `json:"false_positives"`
+	// FalsePositiveRules is the number of false positives per rule
+	FalsePositiveRules map[string]int `json:"false_positive_rules,omitempty"`
+	Tickets            []string       `json:"tickets,omitempty"`
+}
+
+// summarizeAnnotations builds the summary of the provided annotations, ruleIDs resolves the rule of the annotations
+// which don't have one
+func summarizeAnnotations(annotations map[string]*Annotation, ruleIDs map[string]string) *AnnotationSummary {
+	if len(annotations) == 0 {
+		return nil
+	}
+	summary := &AnnotationSummary{}
+	tickets := make(map[string]bool)
+	for _, annotation := range annotations {
+		switch annotation.Status {
+		case AnnotationAck:
+			summary.Acknowledged++
+		case AnnotationFalsePositive:
+			summary.FalsePositives++
+			ruleID := annotation.RuleID
+			if len(ruleID) == 0 {
+				ruleID = ruleIDs[annotation.EventID]
+			}
+			if len(ruleID) > 0 {
+				if summary.FalsePositiveRules == nil {
+					summary.FalsePositiveRules = make(map[string]int)
+				}
+				summary.FalsePositiveRules[ruleID]++
+			}
+		}
+		if len(annotation.TicketID) > 0 && !tickets[annotation.TicketID] {
+			tickets[annotation.TicketID] = true
+			summary.Tickets = append(summary.Tickets, annotation.TicketID)
+		}
+	}
+	sort.Strings(summary.Tickets)
+	return summary
+}
+
+// annotationStore indexes the annotations of the events, by event ID. The annotations are recorded as annotation
+// events: they reach the sinks and the local store like the other events, and the index is rebuilt from the local store
+// on startup. The annotations expire with the retention of the annotation event type, the last annotation of an event
+// replaces the previous ones.
+type annotationStore struct {
+	lock        sync.RWMutex
+	record      func(annotation *Annotation) error
+	maxAge      time.Duration
+	annotations map[string]*Annotation
+}
+
+// newAnnotationStore loads the annotations of the provided storage (if any), record is called with each new
+// annotation to write its annotation event
+func newAnnotationStore(options *Options, storage Storage, record func(annotation *Annotation) error) (*annotationStore, error) {
+	as := &annotationStore{
+		record:      record,
+		annotations: make(map[string]*Annotation),
+	}
+	if options.Storage != nil && len(options.Storage.Type) > 0 {
+		as.maxAge = options.Storage.Retention.policy().MaxAge(events.AnnotationEventType)
+	}
+	if storage == nil {
+		// the annotations are only kept in memory
+		return as, nil
+	}
+
+	now := time.Now()
+	err := storage.Query(StorageQuery{Types: []events.EventType{events.AnnotationEventType}}, func(event *StoredEvent) bool {
+		annotation, err := parseAnnotation(event)
+		if err != nil {
+			logrus.Warnf("ignoring invalid annotation event %s: %v", event.ID, err)
+			return true
+		}
+		if !as.expired(annotation, now) {
+			as.annotations[annotation.EventID] = annotation
+		}
+		return true
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("couldn't load annotations: %w", err)
+	}
+	return as, nil
+}
+
+// openAnnotationStorage opens the local store of the annotation events of the provided options, or returns nil if the
+// events are neither in a storage nor in an output file
+func openAnnotationStorage(options *Options) (Storage, error) {
+	if (options.Storage == nil || len(options.Storage.Type) == 0) && (len(options.Output) == 0 || options.Output == StdoutOutput) {
+		return nil, nil
+	}
+	return OpenStorage(options)
+}
+
+// expired returns true if the annotation is past the retention of the annotation events
+func (as *annotationStore) expired(annotation *Annotation, now time.Time) bool {
+	return as.maxAge > 0 && annotation.CreatedAt.Before(now.Add(-as.maxAge))
+}
+
+// expire drops the expired annotations from the index, the caller must hold the lock
+func (as *annotationStore) expire(now time.Time) {
+	if as.maxAge == 0 {
+		return
+	}
+	for eventID, annotation := range as.annotations {
+		if as.expired(annotation, now) {
+			delete(as.annotations, eventID)
+		}
+	}
+}
+
+// add records an annotation, and writes its annotation event
+func (as *annotationStore) add(annotation Annotation) error {
+	if err := annotation.IsValid(); err != nil {
+		return err
+	}
+	if annotation.CreatedAt.IsZero() {
+		annotation.CreatedAt = time.Now()
+	}
+	if as.record != nil {
+		if err := as.record(&annotation); err != nil {
+			return fmt.Errorf("couldn't record annotation: %w", err)
+		}
+	}
+	as.index(&annotation)
+	return nil
+}
+
+// index adds an annotation to the index, without writing its annotation event
+func (as *annotationStore) index(annotation *Annotation) {
+	as.lock.Lock()
+	defer as.lock.Unlock()
+	as.expire(time.Now())
+	as.annotations[annotation.EventID] = annotation
+}
+
+// get returns the annotation of an event, or nil
+func (as *annotationStore) get(eventID string) *Annotation {
+	as.lock.RLock()
+	defer as.lock.RUnlock()
+	annotation := as.annotations[eventID]
+	if annotation == nil || as.expired(annotation, time.Now()) {
+		return nil
+	}
+	return annotation
+}
+
+// summary returns the summary of the annotations
+func (as *annotationStore) summary() *AnnotationSummary {
+	as.lock.Lock()
+	defer as.lock.Unlock()
+	as.expire(time.Now())
+	return summarizeAnnotations(as.annotations, nil)
+}
+
+// recordAnnotation emits the annotation event of an annotation
+func (e *KRIE) recordAnnotation(annotation *Annotation) error {
+	return e.emitEvent(newAnnotationEvent(annotation))
+}
+
+// AnnotateEvent records the triage note of an analyst on an event
+func (e *KRIE) AnnotateEvent(annotation Annotation) error {
+	return e.annotations.add(annotation)
+}
+
+// EventAnnotation returns the annotation of an event, or nil if the event wasn't annotated
+func (e *KRIE) EventAnnotation(eventID string) *Annotation {
+	return e.annotations.get(eventID)
+}
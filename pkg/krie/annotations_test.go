@@ -0,0 +1,149 @@
+//go:build !krie_minimal
+
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// writeTestEvents writes the provided events to a JSON lines file, as the output file of KRIE
+func writeTestEvents(t *testing.T, path string, evts ...*events.Event) {
+	var data []byte
+	for _, event := range evts {
+		line, err := event.MarshalJSON()
+		assert.NoError(t, err)
+		data = append(append(data, line...), '\n')
+	}
+	assert.NoError(t, os.WriteFile(path, data, 0600))
+}
+
+func newTestAnnotationEvent(annotation *Annotation, at time.Time) *events.Event {
+	event := newAnnotationEvent(annotation)
+	event.ID = events.NewEventID()
+	event.Kernel.Time = at
+	return event
+}
+
+func newTestRuleEvent(id string, ruleID string) *events.Event {
+	event := events.NewEvent()
+	event.ID = id
+	event.Kernel.Type = events.BPFEventType
+	event.Kernel.Time = time.Now()
+	event.Policy.RuleID = ruleID
+	return event
+}
+
+func TestAnnotationStoreLoadsStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+	now := time.Now()
+	writeTestEvents(t, path,
+		newTestRuleEvent("1", "bpf"),
+		newTestAnnotationEvent(&Annotation{EventID: "1", Status: AnnotationAck}, now.Add(-time.Hour)),
+		newTestAnnotationEvent(&Annotation{EventID: "1", Status: AnnotationFalsePositive, TicketID: "SEC-1"}, now.Add(-time.Minute)),
+		newTestAnnotationEvent(&Annotation{EventID: "2", Status: AnnotationAck}, now.Add(-48*time.Hour)),
+	)
+
+	options := NewOptions()
+	options.Output = path
+	storage, err := openAnnotationStorage(options)
+	assert.NoError(t, err)
+	store, err := newAnnotationStore(options, storage, nil)
+	assert.NoError(t, err)
+	if annotation := store.get("1"); assert.NotNil(t, annotation) {
+		assert.Equal(t, AnnotationFalsePositive, annotation.Status)
+		assert.Equal(t, "SEC-1", annotation.TicketID)
+	}
+	assert.NotNil(t, store.get("2"))
+
+	// the annotations expire with the retention of the annotation events
+	options.Storage.Type = "file"
+	options.Storage.Retention.EventTypes = map[string]int64{"annotation": 24}
+	store, err = newAnnotationStore(options, storage, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, store.get("1"))
+	assert.Nil(t, store.get("2"))
+}
+
+func TestAnnotationStoreRecords(t *testing.T) {
+	var recorded []*Annotation
+	store, err := newAnnotationStore(NewOptions(), nil, func(annotation *Annotation) error {
+		recorded = append(recorded, annotation)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Error(t, store.add(Annotation{EventID: "1", Status: "nope"}))
+	assert.NoError(t, store.add(Annotation{EventID: "1", Status: AnnotationAck}))
+	assert.Len(t, recorded, 1)
+	assert.Equal(t, AnnotationAck, store.get("1").Status)
+}
+
+func TestNoiseReportAnnotations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+	writeTestEvents(t, path,
+		newTestRuleEvent("1", "bpf"),
+		newTestRuleEvent("2", "bpf/fileless"),
+		newTestAnnotationEvent(&Annotation{EventID: "1", Status: AnnotationFalsePositive}, time.Now()),
+		newTestAnnotationEvent(&Annotation{EventID: "2", Status: AnnotationFalsePositive, RuleID: "bpf/fileless", TicketID: "SEC-2"}, time.Now()),
+	)
+
+	report, err := NoiseReportFromFile(path, NewNoiseOptions(), events.NewEventsOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), report.Total)
+	for _, entry := range report.Rules {
+		assert.NotEqual(t, "annotation", entry.RuleID)
+	}
+	if assert.NotNil(t, report.Annotations) {
+		assert.Equal(t, 2, report.Annotations.FalsePositives)
+		assert.Equal(t, map[string]int{"bpf": 1, "bpf/fileless": 1}, report.Annotations.FalsePositiveRules)
+		assert.Equal(t, []string{"SEC-2"}, report.Annotations.Tickets)
+	}
+}
+
+// unavailableStorage is a storage whose queries fail
+type unavailableStorage struct{}
+
+func (us *unavailableStorage) Start() error             { return nil }
+func (us *unavailableStorage) Send(_ []SinkEvent) error { return nil }
+func (us *unavailableStorage) Flush() error             { return nil }
+func (us *unavailableStorage) Stop() error              { return nil }
+func (us *unavailableStorage) Query(_ StorageQuery, _ func(event *StoredEvent) bool) error {
+	return errors.New("storage unavailable")
+}
+
+func TestNewKRIEAnnotationsUnavailable(t *testing.T) {
+	RegisterStorage("test_unavailable", func(_ func(out interface{}) error) (Storage, error) {
+		return &unavailableStorage{}, nil
+	})
+	options := NewOptions()
+	options.Storage.Type = "test_unavailable"
+
+	e, err := NewKRIE(options)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "couldn't load annotations: storage unavailable")
+	}
+	assert.Nil(t, e)
+}
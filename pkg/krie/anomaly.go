@@ -0,0 +1,141 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// AnomalyOptions configures the anomaly detector: it models the frequencies of the (process, event type, key fields)
+// combinations observed on the host, and adds an anomaly score to the events, independently of the rules
+type AnomalyOptions struct {
+	Enabled bool `yaml:"enabled"`
+	// Threshold is the score (in bits) above which an event is flagged as rare: 10 flags the combinations seen less
+	// than once every 1024 events
+	Threshold float64 `yaml:"threshold"`
+	// MinObservations is the number of events observed before the first event is flagged as rare, the events are
+	// scored but never flagged while the detector learns the host
+	MinObservations uint64 `yaml:"min_observations"`
+	// MaxKeys is the maximum number of tracked combinations, the new combinations are scored but not tracked once the
+	// limit is reached
+	MaxKeys int `yaml:"max_keys"`
+}
+
+// IsValid returns an error if the anomaly options are invalid
+func (o *AnomalyOptions) IsValid() error {
+	if !o.Enabled {
+		return nil
+	}
+	if o.Threshold <= 0 {
+		return fmt.Errorf("invalid threshold: %v", o.Threshold)
+	}
+	if o.MaxKeys <= 0 {
+		return fmt.Errorf("invalid max_keys: %d", o.MaxKeys)
+	}
+	return nil
+}
+
+// NewAnomalyOptions returns a default set of anomaly options
+func NewAnomalyOptions() *AnomalyOptions {
+	return &AnomalyOptions{
+		Threshold:       10,
+		MinObservations: 10000,
+		MaxKeys:         65536,
+	}
+}
+
+// anomalyMiddleware is the name of the middleware of the anomaly detector
+const anomalyMiddleware = "anomaly"
+
+// anomalyDetector scores the events with the surprisal of their combination: the rarer the combination on the host,
+// the higher the score
+type anomalyDetector struct {
+	options *AnomalyOptions
+
+	lock   sync.Mutex
+	counts map[string]uint64
+	total  uint64
+	// cLogC is the sum of c*log2(c) over the counts, it gives the entropy of the observed combinations
+	cLogC float64
+}
+
+func newAnomalyDetector(options *AnomalyOptions) *anomalyDetector {
+	return &anomalyDetector{
+		options: options,
+		counts:  make(map[string]uint64),
+	}
+}
+
+// middleware returns the middleware of the detector, it runs after the rules stage
+func (ad *anomalyDetector) middleware() Middleware {
+	return Middleware{
+		Name:  anomalyMiddleware,
+		After: RulesStage,
+		Handler: func(event *events.Event, next EventProcessor) error {
+			if event.HasProcessContext() {
+				event.Anomaly = ad.observe(event.AnomalyKey())
+			}
+			return next(event)
+		},
+	}
+}
+
+// observe scores a combination, then records it
+func (ad *anomalyDetector) observe(key string) *events.Anomaly {
+	ad.lock.Lock()
+	defer ad.lock.Unlock()
+
+	count, tracked := ad.counts[key]
+	anomaly := &events.Anomaly{
+		Key:          key,
+		Occurrences:  count,
+		Observations: ad.total,
+		Entropy:      ad.entropy(),
+	}
+	// Laplace smoothing: the unseen combinations get the probability of a combination seen once
+	p := float64(count+1) / float64(ad.total+uint64(len(ad.counts))+1)
+	anomaly.Score = -math.Log2(p)
+	anomaly.Rare = ad.total >= ad.options.MinObservations && anomaly.Score >= ad.options.Threshold
+
+	if !tracked && len(ad.counts) >= ad.options.MaxKeys {
+		return anomaly
+	}
+	ad.counts[key] = count + 1
+	ad.total++
+	ad.cLogC += cLogC(count+1) - cLogC(count)
+	return anomaly
+}
+
+// entropy returns the entropy of the observed combinations, in bits
+func (ad *anomalyDetector) entropy() float64 {
+	if ad.total == 0 {
+		return 0
+	}
+	n := float64(ad.total)
+	return math.Log2(n) - ad.cLogC/n
+}
+
+func cLogC(c uint64) float64 {
+	if c == 0 {
+		return 0
+	}
+	return float64(c) * math.Log2(float64(c))
+}
@@ -0,0 +1,121 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// APIOptions configures the REST API of KRIE
+type APIOptions struct {
+	// Listen is the address of the REST API, leave empty to disable the API
+	Listen string `yaml:"listen"`
+	// TokenFile is a file containing the bearer token expected by the API, leave empty to disable authentication
+	TokenFile string `yaml:"token_file"`
+	// ScanTimeout is the maximum duration of an on demand scan, in seconds
+	ScanTimeout int64 `yaml:"scan_timeout"`
+	// Tenants are the tenants of the ingestion endpoint, indexed by tenant name
+	Tenants map[string]*TenantOptions `yaml:"tenants"`
+}
+
+// TenantOptions configures a tenant of the ingestion endpoint of the API
+type TenantOptions struct {
+	// KeyFile is a file containing the API key of the agents of the tenant
+	KeyFile string `yaml:"key_file"`
+	// Sinks are the sinks receiving the events of the tenant, they must be declared in the sinks section
+	Sinks []string `yaml:"sinks"`
+	// EventsPerMinute is the maximum number of events accepted from the agents of the tenant per minute, 0 means no
+	// quota
+	EventsPerMinute int64 `yaml:"events_per_minute"`
+}
+
+// IsValid returns an error if the tenant options are invalid
+func (o *TenantOptions) IsValid() error {
+	if len(o.KeyFile) == 0 {
+		return fmt.Errorf("empty key_file")
+	}
+	if len(o.Sinks) == 0 {
+		return fmt.Errorf("no sink")
+	}
+	if o.EventsPerMinute < 0 {
+		return fmt.Errorf("invalid events_per_minute: %d", o.EventsPerMinute)
+	}
+	return nil
+}
+
+// tenantOfSink returns the tenant of the provided sink, if any
+func (o *APIOptions) tenantOfSink(name string) (string, bool) {
+	for tenant, options := range o.Tenants {
+		for _, sink := range options.Sinks {
+			if sink == name {
+				return tenant, true
+			}
+		}
+	}
+	return "", false
+}
+
+// IsValid returns an error if the API options are invalid
+func (o *APIOptions) IsValid() error {
+	if len(o.Listen) == 0 {
+		if len(o.Tenants) > 0 {
+			return fmt.Errorf("tenants require a listen address")
+		}
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(o.Listen); err != nil {
+		return fmt.Errorf("invalid listen address: %w", err)
+	}
+	if o.ScanTimeout <= 0 {
+		return fmt.Errorf("invalid scan_timeout: %d", o.ScanTimeout)
+	}
+	for name, tenant := range o.Tenants {
+		if tenant == nil {
+			return fmt.Errorf("tenant %s: empty tenant", name)
+		}
+		if err := tenant.IsValid(); err != nil {
+			return fmt.Errorf("tenant %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewAPIOptions returns a default set of API options
+func NewAPIOptions() *APIOptions {
+	return &APIOptions{
+		ScanTimeout: 60,
+	}
+}
+
+// readAPIToken reads the bearer token of the API
+func readAPIToken(path string) (string, error) {
+	if len(path) == 0 {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("couldn't read API token: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if len(token) == 0 {
+		return "", fmt.Errorf("empty API token in %s", path)
+	}
+	return token, nil
+}
@@ -0,0 +1,352 @@
+//go:build !krie_minimal
+
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// apiServer exposes the REST API of KRIE
+type apiServer struct {
+	krie    *KRIE
+	options *APIOptions
+	token   string
+	tenants []*tenant
+	server  *http.Server
+}
+
+func newAPIServer(krie *KRIE, options *APIOptions) (*apiServer, error) {
+	token, err := readAPIToken(options.TokenFile)
+	if err != nil {
+		return nil, err
+	}
+	tenants, err := newTenants(options.Tenants)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tenants {
+		if len(token) > 0 && t.key == token {
+			return nil, fmt.Errorf("tenant %s: the key of a tenant can't be the API token", t.name)
+		}
+	}
+	as := &apiServer{
+		krie:    krie,
+		options: options,
+		token:   token,
+		tenants: tenants,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/scanners", as.authenticate(as.handleScanners))
+	mux.HandleFunc("/v1/scan", as.authenticate(as.handleScan))
+	mux.HandleFunc("/v1/processes/", as.authenticate(as.handleProcess))
+	mux.HandleFunc("/v1/rules", as.authenticate(as.handleRules))
+	mux.HandleFunc("/v1/rules/mode", as.authenticate(as.handleRuleMode))
+	mux.HandleFunc("/v1/noise", as.authenticate(as.handleNoise))
+	mux.HandleFunc("/v1/annotations", as.authenticate(as.handleAnnotations))
+	mux.HandleFunc("/v1/annotations/", as.authenticate(as.handleAnnotation))
+	mux.HandleFunc("/v1/schema", as.authenticate(as.handleSchema))
+	mux.HandleFunc("/v1/captures", as.authenticate(as.handleCaptures))
+	// the ingestion endpoint is authenticated by the keys of the tenants, not by the API token
+	mux.HandleFunc("/v1/ingest", as.handleIngest)
+	as.server = &http.Server{
+		Addr:              options.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return as, nil
+}
+
+// authenticate checks the bearer token of the requests
+func (as *apiServer) authenticate(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if len(as.token) > 0 {
+			expected := "Bearer " + as.token
+			if subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), []byte(expected)) != 1 {
+				writeAPIError(w, http.StatusUnauthorized, fmt.Errorf("invalid token"))
+				return
+			}
+		}
+		handler(w, req)
+	}
+}
+
+// handleScanners lists the registered scanners
+func (as *apiServer) handleScanners(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, map[string]interface{}{
+		"scanners": as.krie.Scanners(),
+	})
+}
+
+// handleScan runs the requested scanners (all of them if the scanner parameter isn't set), and returns their findings
+func (as *apiServer) handleScan(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		return
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), time.Duration(as.options.ScanTimeout)*time.Second)
+	defer cancel()
+
+	results, err := as.krie.Scan(ctx, req.URL.Query()["scanner"]...)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	clean := true
+	for _, result := range results {
+		clean = clean && result.Clean
+	}
+	writeAPIResponse(w, http.StatusOK, map[string]interface{}{
+		"clean":   clean,
+		"results": results,
+	})
+}
+
+// handleProcess returns the process with the requested PID at the time of the "at" parameter (RFC 3339, defaults to
+// now), along with its ancestors
+func (as *apiServer) handleProcess(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		return
+	}
+	pid, err := strconv.ParseUint(strings.TrimPrefix(req.URL.Path, "/v1/processes/"), 10, 32)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid pid: %w", err))
+		return
+	}
+	var at time.Time
+	if value := req.URL.Query().Get("at"); len(value) > 0 {
+		if at, err = time.Parse(time.RFC3339, value); err != nil {
+			writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid at parameter: %w", err))
+			return
+		}
+	}
+
+	process, ancestors, err := as.krie.LookupProcess(uint32(pid), at)
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	if process == nil {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("process %d not found", pid))
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, map[string]interface{}{
+		"process":   process,
+		"ancestors": ancestors,
+	})
+}
+
+// handleRules returns the mode of each rule configured to block, kill or alter the monitored operations
+func (as *apiServer) handleRules(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, as.krie.RuleModes())
+}
+
+// handleSchema returns the personal data processed for each event type, and whether the privacy mode is enabled
+func (as *apiServer) handleSchema(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, map[string]interface{}{
+		"privacy_mode": as.krie.options.Privacy.Enabled,
+		"events":       events.Schema(),
+	})
+}
+
+// captureRequest is the body of a capture request
+type captureRequest struct {
+	// Duration is a Go duration, for example "10m"
+	Duration string   `json:"duration"`
+	Events   []string `json:"events"`
+	PID      uint32   `json:"pid"`
+}
+
+// handleCaptures lists the active captures, or starts a time-window capture of the requested event types
+func (as *apiServer) handleCaptures(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		writeAPIResponse(w, http.StatusOK, map[string]interface{}{
+			"captures": as.krie.Captures(),
+		})
+	case http.MethodPost:
+		var body captureRequest
+		if err := json.NewDecoder(io.LimitReader(req.Body, 1<<20)).Decode(&body); err != nil {
+			writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+			return
+		}
+		duration, err := time.ParseDuration(body.Duration)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid duration: %w", err))
+			return
+		}
+		eventTypes, err := ParseCaptureEventTypes(strings.Join(body.Events, ","))
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err)
+			return
+		}
+		result, err := as.krie.StartCapture(&CaptureRequest{
+			Duration:   duration,
+			EventTypes: eventTypes,
+			PID:        body.PID,
+		})
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeAPIResponse(w, http.StatusOK, result)
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+	}
+}
+
+// ruleModeRequest is the body of a rule mode change
+type ruleModeRequest struct {
+	Rule   string          `json:"rule"`
+	Mode   events.RuleMode `json:"mode"`
+	Tokens []string        `json:"tokens"`
+}
+
+// handleRuleMode switches a rule between the monitor and enforce modes
+func (as *apiServer) handleRuleMode(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPut {
+		writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		return
+	}
+	var body ruleModeRequest
+	if err := json.NewDecoder(io.LimitReader(req.Body, 1<<20)).Decode(&body); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+	if err := as.krie.SetRuleMode(body.Rule, body.Mode, body.Tokens); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, as.krie.RuleModes())
+}
+
+// handleNoise returns the noise report of the events dispatched since KRIE started
+func (as *apiServer) handleNoise(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		return
+	}
+	report, err := as.krie.NoiseReport()
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, err)
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, report)
+}
+
+// handleAnnotations records the triage note of an analyst on an event (ack, false positive, ticket ID)
+func (as *apiServer) handleAnnotations(w http.ResponseWriter, req *http.Request) {
+	serveAnnotations(w, req, as.krie.annotations)
+}
+
+// handleAnnotation returns the annotation of the requested event
+func (as *apiServer) handleAnnotation(w http.ResponseWriter, req *http.Request) {
+	serveAnnotation(w, req, as.krie.annotations)
+}
+
+// serveAnnotations records an annotation in the provided store, the handler is shared with the companions
+func serveAnnotations(w http.ResponseWriter, req *http.Request, store *annotationStore) {
+	if req.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		return
+	}
+	var annotation Annotation
+	if err := json.NewDecoder(io.LimitReader(req.Body, 1<<20)).Decode(&annotation); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+	annotation.CreatedAt = time.Now()
+	if err := store.add(annotation); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, annotation)
+}
+
+// serveAnnotation returns an annotation of the provided store, the handler is shared with the companions
+func serveAnnotation(w http.ResponseWriter, req *http.Request, store *annotationStore) {
+	if req.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		return
+	}
+	eventID := strings.TrimPrefix(req.URL.Path, "/v1/annotations/")
+	annotation := store.get(eventID)
+	if annotation == nil {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("event %s isn't annotated", eventID))
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, annotation)
+}
+
+func writeAPIResponse(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logrus.Debugf("couldn't write API response: %v", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeAPIResponse(w, status, map[string]string{"error": err.Error()})
+}
+
+func (as *apiServer) start() error {
+	listener, err := net.Listen("tcp", as.server.Addr)
+	if err != nil {
+		return fmt.Errorf("couldn't start the API: %w", err)
+	}
+	go func() {
+		if err := as.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Errorf("API stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (as *apiServer) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = as.server.Shutdown(ctx)
+}
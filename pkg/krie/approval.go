@@ -0,0 +1,239 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// approvalTokenPrefix is prepended to the signed content of an approval token, so that an approval signature can't be
+// confused with another signature made with the same key
+const approvalTokenPrefix = "krie-approval/v1"
+
+// ApprovalScope defines which policy changes require an approval
+type ApprovalScope string
+
+const (
+	// ApprovalScopeEnforcement requires an approval for the changes that switch a rule from detection to enforcement
+	ApprovalScopeEnforcement ApprovalScope = "enforcement"
+	// ApprovalScopeAll requires an approval for every policy change
+	ApprovalScopeAll ApprovalScope = "all"
+)
+
+// UnmarshalYAML parses an approval scope
+func (as *ApprovalScope) UnmarshalYAML(value *yaml.Node) error {
+	var scope string
+	if err := value.Decode(&scope); err != nil {
+		return fmt.Errorf("failed to parse approval scope: %w", err)
+	}
+	switch ApprovalScope(scope) {
+	case ApprovalScopeEnforcement, ApprovalScopeAll:
+		*as = ApprovalScope(scope)
+	case "":
+		*as = ApprovalScopeEnforcement
+	default:
+		return fmt.Errorf("unknown approval scope: %s", scope)
+	}
+	return nil
+}
+
+// ApprovalOptions configures the approval of the policy changes. An approval token is an ed25519 signature of the hash
+// of the approved policies, made by one of the configured approvers.
+type ApprovalOptions struct {
+	Required     bool          `yaml:"required"`
+	Scope        ApprovalScope `yaml:"scope"`
+	MinApprovals int           `yaml:"min_approvals"`
+	TokensFile   string        `yaml:"tokens_file"`
+
+	// Approvers maps the name of each approver to its base64 encoded ed25519 public key
+	Approvers map[string]string `yaml:"approvers"`
+}
+
+// IsValid returns an error if the approval options are invalid
+func (o *ApprovalOptions) IsValid() error {
+	if !o.Required {
+		return nil
+	}
+	if o.MinApprovals <= 0 || o.MinApprovals > len(o.Approvers) {
+		return fmt.Errorf("invalid min_approvals (%d) for %d approver(s)", o.MinApprovals, len(o.Approvers))
+	}
+	for name, key := range o.Approvers {
+		if len(name) == 0 || strings.Contains(name, ":") {
+			return fmt.Errorf("invalid approver name: %q", name)
+		}
+		if _, err := parseApproverKey(key); err != nil {
+			return fmt.Errorf("invalid public key for approver %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewApprovalOptions returns a default set of approval options
+func NewApprovalOptions() *ApprovalOptions {
+	return &ApprovalOptions{
+		Scope:        ApprovalScopeEnforcement,
+		MinApprovals: 1,
+	}
+}
+
+func parseApproverKey(key string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid key size: %d", len(raw))
+	}
+	return raw, nil
+}
+
+func approvalMessage(policyHash string, expires int64) []byte {
+	return []byte(fmt.Sprintf("%s\n%s\n%d", approvalTokenPrefix, policyHash, expires))
+}
+
+// SignApproval returns an approval token of the provided policy hash. The token is formatted as
+// <approver>:<expiration timestamp>:<base64 signature>.
+func SignApproval(approver string, key ed25519.PrivateKey, policyHash string, expires time.Time) string {
+	signature := ed25519.Sign(key, approvalMessage(policyHash, expires.Unix()))
+	return fmt.Sprintf("%s:%d:%s", approver, expires.Unix(), base64.StdEncoding.EncodeToString(signature))
+}
+
+// ReadPrivateKey reads a base64 encoded ed25519 private key seed, as generated by "krie approve keygen"
+func ReadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read private key: %w", err)
+	}
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid private key %s", path)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// ReadApprovalTokens reads the approval tokens of a file, one token per line. Empty lines and lines starting with '#'
+// are ignored.
+func ReadApprovalTokens(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open approval tokens file: %w", err)
+	}
+	defer f.Close()
+
+	var tokens []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tokens = append(tokens, line)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't read approval tokens file: %w", err)
+	}
+	return tokens, nil
+}
+
+// approvalVerifier checks the approval tokens of the policy changes
+type approvalVerifier struct {
+	options *ApprovalOptions
+	keys    map[string]ed25519.PublicKey
+}
+
+func newApprovalVerifier(options *ApprovalOptions) (*approvalVerifier, error) {
+	av := &approvalVerifier{
+		options: options,
+		keys:    make(map[string]ed25519.PublicKey),
+	}
+	for name, key := range options.Approvers {
+		publicKey, err := parseApproverKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key for approver %s: %w", name, err)
+		}
+		av.keys[name] = publicKey
+	}
+	return av, nil
+}
+
+// isRequired returns true if a change with the provided escalations requires an approval
+func (av *approvalVerifier) isRequired(escalations []string) bool {
+	if !av.options.Required {
+		return false
+	}
+	return av.options.Scope == ApprovalScopeAll || len(escalations) > 0
+}
+
+// verify returns the sorted list of the approvers of the provided policy hash. An error is returned if less than
+// min_approvals distinct approvers signed the hash.
+func (av *approvalVerifier) verify(policyHash string, tokens []string) ([]string, error) {
+	return av.verifyAt(policyHash, tokens, time.Now().Unix())
+}
+
+// verifyExpired is verify, with the expired approval tokens accepted
+func (av *approvalVerifier) verifyExpired(policyHash string, tokens []string) ([]string, error) {
+	return av.verifyAt(policyHash, tokens, math.MinInt64)
+}
+
+// verifyAt is verify, with the approval tokens that expire before now rejected
+func (av *approvalVerifier) verifyAt(policyHash string, tokens []string, now int64) ([]string, error) {
+	approved := make(map[string]bool)
+
+	for _, token := range tokens {
+		parts := strings.SplitN(token, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		key, ok := av.keys[parts[0]]
+		if !ok {
+			continue
+		}
+		expires, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || expires < now {
+			continue
+		}
+		signature, err := base64.StdEncoding.DecodeString(parts[2])
+		if err != nil {
+			continue
+		}
+		if ed25519.Verify(key, approvalMessage(policyHash, expires), signature) {
+			approved[parts[0]] = true
+		}
+	}
+
+	approvers := make([]string, 0, len(approved))
+	for name := range approved {
+		approvers = append(approvers, name)
+	}
+	sort.Strings(approvers)
+
+	if len(approvers) < av.options.MinApprovals {
+		return approvers, fmt.Errorf("%d valid approval(s) for %s, %d required", len(approvers), policyHash, av.options.MinApprovals)
+	}
+	return approvers, nil
+}
@@ -0,0 +1,82 @@
+//go:build !krie_minimal
+
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+func newTestApprovalOptions(t *testing.T, approvedHash func(options *Options) string, expires time.Time) *Options {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	options := NewOptions()
+	options.Events.KexecEvent = events.BlockAction
+	options.Approval.Required = true
+	options.Approval.Approvers = map[string]string{"alice": base64.StdEncoding.EncodeToString(public)}
+	options.Approval.TokensFile = filepath.Join(t.TempDir(), "tokens")
+	token := SignApproval("alice", private, approvedHash(options), expires)
+	assert.NoError(t, os.WriteFile(options.Approval.TokensFile, []byte(token+"\n"), 0600))
+	return options
+}
+
+func TestNewKRIEExpiredApproval(t *testing.T) {
+	policiesHash := func(options *Options) string {
+		hash, err := options.Events.Hash()
+		assert.NoError(t, err)
+		return hash
+	}
+
+	// a valid approval enforces the policies
+	e, err := NewKRIE(newTestApprovalOptions(t, policiesHash, time.Now().Add(time.Hour)))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"alice"}, e.policyApprovers)
+		assert.Empty(t, e.monitoredRules)
+		assert.Equal(t, []string{"kexec"}, e.options.Events.EnforcedRules())
+	}
+
+	// an expired approval starts the enforced rules in monitor mode
+	e, err = NewKRIE(newTestApprovalOptions(t, policiesHash, time.Now().Add(-time.Hour)))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"kexec"}, e.monitoredRules)
+		assert.True(t, e.options.Events.IsMonitored("kexec"))
+		assert.Empty(t, e.options.Events.EnforcedRules())
+		hash, _ := e.options.Events.Hash()
+		assert.Equal(t, hash, e.policyHash)
+	}
+
+	// the approval of other policies is still rejected
+	otherHash := func(_ *Options) string {
+		return "other"
+	}
+	_, err = NewKRIE(newTestApprovalOptions(t, otherHash, time.Now().Add(-time.Hour)))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "policies not approved")
+	}
+}
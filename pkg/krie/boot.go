@@ -0,0 +1,226 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BootOptions configures the early start of KRIE, from the initramfs or an early systemd target. The module loads and
+// the sysctl changes performed during the boot are a classic persistence window: the probes are loaded as early as
+// possible, and the sinks that can't be initialized yet (output file on a filesystem that isn't mounted, unreachable
+// collector, ...) are retried until they are available. In the meantime, their events are kept in the boot backlog.
+type BootOptions struct {
+	// EarlyStart defers the initialization of the sinks that aren't available yet, instead of failing the startup
+	EarlyStart bool `yaml:"early_start"`
+	// RetryInterval is the interval in seconds between two initialization attempts of a deferred sink
+	RetryInterval int64 `yaml:"retry_interval"`
+	// MemoryBacklog is the maximum number of events kept in memory for each deferred sink, when the backlog directory
+	// isn't set. The newest events are dropped when the backlog is full, so that the boot events are kept.
+	MemoryBacklog int `yaml:"memory_backlog"`
+	// Backlog is the on-disk backlog of the deferred sinks (one spool per sink in the backlog directory). A backlog on a
+	// filesystem preserved across the switch to the real root (such as /run) is replayed by the next KRIE instance.
+	Backlog *SpoolOptions `yaml:"backlog"`
+}
+
+// IsValid returns an error if the boot options are invalid
+func (o *BootOptions) IsValid() error {
+	if !o.EarlyStart {
+		return nil
+	}
+	if o.RetryInterval <= 0 {
+		return fmt.Errorf("invalid retry_interval: %d", o.RetryInterval)
+	}
+	if o.MemoryBacklog <= 0 {
+		return fmt.Errorf("invalid memory_backlog: %d", o.MemoryBacklog)
+	}
+	if err := o.Backlog.IsValid(); err != nil {
+		return fmt.Errorf("invalid backlog section: %w", err)
+	}
+	return nil
+}
+
+// NewBootOptions returns a default set of boot options
+func NewBootOptions() *BootOptions {
+	return &BootOptions{
+		RetryInterval: 2,
+		MemoryBacklog: 10000,
+		Backlog:       NewSpoolOptions(),
+	}
+}
+
+// deferredSink wraps a sink which may not be available at startup: the events are kept in the boot backlog until the
+// sink can be initialized, and replayed in order before the new events.
+type deferredSink struct {
+	name    string
+	open    func() (eventSink, error)
+	options *BootOptions
+
+	lock    sync.Mutex
+	sink    eventSink
+	memory  []*queuedEvent
+	spool   *spool
+	dropped uint64
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newDeferredSink(name string, open func() (eventSink, error), options *BootOptions) (*deferredSink, error) {
+	ds := &deferredSink{
+		name:    name,
+		open:    open,
+		options: options,
+		stop:    make(chan struct{}),
+	}
+	if len(options.Backlog.Directory) > 0 {
+		backlog := *options.Backlog
+		backlog.Directory = filepath.Join(backlog.Directory, name)
+		var err error
+		if ds.spool, err = newSpool(&backlog, nil); err != nil {
+			return nil, fmt.Errorf("couldn't create boot backlog of sink %s: %w", name, err)
+		}
+	}
+	return ds, nil
+}
+
+func (ds *deferredSink) start() error {
+	err := ds.tryOpen()
+	if err == nil {
+		return nil
+	}
+	logrus.Warnf("sink %s isn't available yet, its events are kept in the boot backlog: %v", ds.name, err)
+	ds.wg.Add(1)
+	go ds.retryLoop()
+	return nil
+}
+
+func (ds *deferredSink) retryLoop() {
+	defer ds.wg.Done()
+	ticker := time.NewTicker(time.Duration(ds.options.RetryInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ds.stop:
+			return
+		case <-ticker.C:
+			if err := ds.tryOpen(); err != nil {
+				logrus.Debugf("sink %s still isn't available: %v", ds.name, err)
+				continue
+			}
+			return
+		}
+	}
+}
+
+// tryOpen initializes the sink, and replays the boot backlog
+func (ds *deferredSink) tryOpen() error {
+	sink, err := ds.open()
+	if err != nil {
+		return err
+	}
+
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+	replayed := 0
+	if ds.spool != nil {
+		// the backlog may contain the events of the previous instance (for example the initramfs one)
+		if err = ds.spool.replay(func(id string, data []byte) error {
+			replayed++
+			return sink.write(&queuedEvent{id: id, data: data})
+		}); err != nil {
+			_ = sink.close()
+			return fmt.Errorf("couldn't replay boot backlog: %w", err)
+		}
+	}
+	for _, evt := range ds.memory {
+		if err = sink.write(evt); err != nil {
+			logrus.Errorf("couldn't write backlogged %s event to sink %s: %v", evt.eventType, ds.name, err)
+		}
+		replayed++
+	}
+	ds.memory = nil
+	if ds.dropped > 0 {
+		logrus.Warnf("sink %s dropped %d boot event(s): memory backlog full (memory_backlog: %d)", ds.name, ds.dropped, ds.options.MemoryBacklog)
+	}
+	ds.sink = sink
+	logrus.Infof("sink %s is available, %d backlogged event(s) replayed", ds.name, replayed)
+	return nil
+}
+
+func (ds *deferredSink) write(evt *queuedEvent) error {
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+	if ds.sink != nil {
+		return ds.sink.write(evt)
+	}
+	if ds.spool != nil {
+		return ds.spool.append(evt.id, evt.data)
+	}
+	if len(ds.memory) >= ds.options.MemoryBacklog {
+		ds.dropped++
+		return nil
+	}
+	ds.memory = append(ds.memory, evt)
+	return nil
+}
+
+func (ds *deferredSink) close() error {
+	close(ds.stop)
+	ds.wg.Wait()
+
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+	if ds.spool != nil {
+		_ = ds.spool.close()
+	}
+	if ds.sink == nil {
+		if len(ds.memory) > 0 {
+			logrus.Warnf("sink %s never became available, %d boot event(s) lost", ds.name, len(ds.memory))
+		}
+		return nil
+	}
+	return ds.sink.close()
+}
+
+// openSink creates a sink, the creation is deferred until the sink is available when the early start is enabled
+func (e *KRIE) openSink(name string, open func() (eventSink, error)) (eventSink, error) {
+	if !e.options.Boot.EarlyStart {
+		return open()
+	}
+	return newDeferredSink(name, open, e.options.Boot)
+}
+
+// deferStart defers the start of a sink until it is available when the early start is enabled
+func (e *KRIE) deferStart(name string, sink eventSink) (eventSink, error) {
+	starter, ok := sink.(sinkStarter)
+	if !ok || !e.options.Boot.EarlyStart {
+		return sink, nil
+	}
+	return newDeferredSink(name, func() (eventSink, error) {
+		if err := starter.start(); err != nil {
+			return nil, err
+		}
+		return sink, nil
+	}, e.options.Boot)
+}
@@ -0,0 +1,248 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cilium/ebpf"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// BPFLifecycleOptions configures the BPF program lifecycle tracker: the bpf events of a program (load, attach, pin)
+// share a lifecycle ID, and a bpf_program_unload event is emitted when the program is unloaded
+type BPFLifecycleOptions struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is the number of seconds between two checks of the loaded programs
+	Interval int64 `yaml:"interval"`
+	// MaxPrograms is the maximum number of tracked programs, the events of the other programs aren't linked
+	MaxPrograms int `yaml:"max_programs"`
+}
+
+// IsValid returns an error if the BPF lifecycle options are invalid
+func (o *BPFLifecycleOptions) IsValid() error {
+	if !o.Enabled {
+		return nil
+	}
+	if o.Interval <= 0 {
+		return fmt.Errorf("invalid interval: %d", o.Interval)
+	}
+	if o.MaxPrograms <= 0 {
+		return fmt.Errorf("invalid max_programs: %d", o.MaxPrograms)
+	}
+	return nil
+}
+
+// NewBPFLifecycleOptions returns a default set of BPF lifecycle options
+func NewBPFLifecycleOptions() *BPFLifecycleOptions {
+	return &BPFLifecycleOptions{
+		Interval:    10,
+		MaxPrograms: 4096,
+	}
+}
+
+// trackedBPFProgram is a program seen in a bpf event
+type trackedBPFProgram struct {
+	lifecycle events.BPFProgramLifecycle
+	program   events.BPFProgram
+	// process is the process which loaded the program, or the first process seen with the program if it was loaded
+	// before KRIE
+	process events.ProcessContext
+}
+
+// bpfLifecycleTracker links the bpf events of the same program, and emits a bpf_program_unload event when a tracked
+// program is unloaded
+type bpfLifecycleTracker struct {
+	krie    *KRIE
+	options *BPFLifecycleOptions
+	bootID  string
+
+	lock     sync.Mutex
+	programs map[uint32]*trackedBPFProgram
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newBPFLifecycleTracker(krie *KRIE, options *BPFLifecycleOptions) *bpfLifecycleTracker {
+	return &bpfLifecycleTracker{
+		krie:     krie,
+		options:  options,
+		bootID:   readBootID(),
+		programs: make(map[uint32]*trackedBPFProgram),
+		stop:     make(chan struct{}),
+	}
+}
+
+// readBootID returns the boot ID of the host. The program IDs are unique until the next boot, when the boot ID can't be
+// read the start time of KRIE is used instead.
+func readBootID() string {
+	data, err := os.ReadFile("/proc/sys/kernel/random/boot_id")
+	if err != nil || len(strings.TrimSpace(string(data))) == 0 {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// link sets the lifecycle of the program of a bpf event
+func (bt *bpfLifecycleTracker) link(event *events.Event) {
+	program := &event.BPFEvent.Program
+	if program.ID == 0 {
+		return
+	}
+	stage := events.BPFLifecycleStage(event.BPFEvent.Cmd)
+
+	bt.lock.Lock()
+	defer bt.lock.Unlock()
+
+	tracked, ok := bt.programs[program.ID]
+	if !ok {
+		if len(bt.programs) >= bt.options.MaxPrograms {
+			return
+		}
+		tracked = &trackedBPFProgram{
+			lifecycle: events.BPFProgramLifecycle{
+				ID:        fmt.Sprintf("%s-%d", bt.bootID, program.ID),
+				FirstSeen: event.Kernel.Time,
+			},
+			program: events.BPFProgram{ID: program.ID},
+			process: event.Process,
+		}
+		bt.programs[program.ID] = tracked
+	}
+
+	// the metadata of the program are only known from its load, or from the first access to it
+	if len(program.Tag) > 0 && len(tracked.program.Tag) == 0 {
+		tracked.program = *program
+		tracked.lifecycle.Identity = bpfProgramIdentity(program)
+	}
+	if stage == events.BPFLifecycleLoad {
+		tracked.process = event.Process
+		tracked.lifecycle.LoadEventID = event.ID
+	}
+
+	lifecycle := tracked.advance(stage, event.ID)
+	event.BPFEvent.Lifecycle = &lifecycle
+}
+
+// advance records a new event in the lifecycle of the program, and returns the lifecycle of the event
+func (tp *trackedBPFProgram) advance(stage string, eventID string) events.BPFProgramLifecycle {
+	tp.lifecycle.Sequence++
+	tp.lifecycle.Stage = stage
+	if len(stage) > 0 && !tp.hasStage(stage) {
+		tp.lifecycle.Stages = append(tp.lifecycle.Stages, stage)
+	}
+
+	lifecycle := tp.lifecycle
+	lifecycle.Stages = append([]string(nil), tp.lifecycle.Stages...)
+	tp.lifecycle.PreviousEventID = eventID
+	return lifecycle
+}
+
+func (tp *trackedBPFProgram) hasStage(stage string) bool {
+	for _, s := range tp.lifecycle.Stages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// bpfProgramIdentity returns an identity of the program which doesn't change when the program is reloaded
+func bpfProgramIdentity(program *events.BPFProgram) string {
+	if len(program.Name) == 0 {
+		return program.Tag
+	}
+	return fmt.Sprintf("%s:%s", program.Name, program.Tag)
+}
+
+func (bt *bpfLifecycleTracker) start() {
+	bt.wg.Add(1)
+	go func() {
+		defer bt.wg.Done()
+		ticker := time.NewTicker(time.Duration(bt.options.Interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-bt.stop:
+				return
+			case <-ticker.C:
+				bt.check()
+			}
+		}
+	}()
+}
+
+func (bt *bpfLifecycleTracker) close() {
+	close(bt.stop)
+	bt.wg.Wait()
+}
+
+// loadedBPFPrograms returns the IDs of the programs loaded in the kernel
+func loadedBPFPrograms() (map[uint32]bool, error) {
+	loaded := make(map[uint32]bool)
+	var id ebpf.ProgramID
+	for {
+		next, err := ebpf.ProgramGetNextID(id)
+		if errors.Is(err, os.ErrNotExist) {
+			return loaded, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		loaded[uint32(next)] = true
+		id = next
+	}
+}
+
+// check emits a bpf_program_unload event for the tracked programs which were unloaded
+func (bt *bpfLifecycleTracker) check() {
+	loaded, err := loadedBPFPrograms()
+	if err != nil {
+		logrus.Debugf("couldn't list the loaded BPF programs: %v", err)
+		return
+	}
+
+	var unloaded []*trackedBPFProgram
+	bt.lock.Lock()
+	for id, tracked := range bt.programs {
+		if loaded[id] {
+			continue
+		}
+		unloaded = append(unloaded, tracked)
+		delete(bt.programs, id)
+	}
+	bt.lock.Unlock()
+
+	for _, tracked := range unloaded {
+		event := events.NewEvent()
+		event.Kernel.Type = events.BPFProgramUnloadEventType
+		event.Process = tracked.process
+		event.BPFProgramUnloadEvent.Program = tracked.program
+		event.BPFProgramUnloadEvent.Lifecycle = tracked.advance(events.BPFLifecycleUnload, "")
+		if err := bt.krie.emitEvent(event); err != nil {
+			logrus.Errorf("couldn't emit bpf_program_unload event: %v", err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+//go:build krie_minimal
+
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// The minimal build profile (krie_minimal build tag) strips the network sinks (webhook, stix), the metrics endpoint, the API and
+// the container enrichment. Events are written to the output file (or to the standard output) and to the registered sinks.
+
+// checkBuildProfile returns an error if the options require a subsystem stripped from the build
+func checkBuildProfile(o *Options) error {
+	if len(o.Webhook.URL) > 0 {
+		return fmt.Errorf("the webhook sink isn't available in the minimal build")
+	}
+	if len(o.Metrics.Listen) > 0 {
+		return fmt.Errorf("the metrics endpoint isn't available in the minimal build")
+	}
+	if len(o.API.Listen) > 0 {
+		return fmt.Errorf("the API isn't available in the minimal build")
+	}
+	if o.CRI.Enabled {
+		return fmt.Errorf("the container enrichment isn't available in the minimal build")
+	}
+	return nil
+}
+
+type criResolver struct{}
+
+func newCRIResolver(_ *CRIOptions) *criResolver {
+	return &criResolver{}
+}
+
+func (r *criResolver) resolve(_ string) *events.ContainerContext {
+	return nil
+}
+
+type webhookSink struct{}
+
+func newWebhookSink(_ *WebhookOptions) (*webhookSink, error) {
+	return &webhookSink{}, nil
+}
+
+func (ws *webhookSink) write(_ *queuedEvent) error {
+	return fmt.Errorf("the webhook sink isn't available in the minimal build")
+}
+
+func (ws *webhookSink) close() error {
+	return nil
+}
+
+type metricsServer struct{}
+
+func newMetricsServer(_ string, _ *metricsRegistry) *metricsServer {
+	return &metricsServer{}
+}
+
+func (ms *metricsServer) start() error {
+	return fmt.Errorf("the metrics endpoint isn't available in the minimal build")
+}
+
+func (ms *metricsServer) stop() {}
+
+type apiServer struct{}
+
+func newAPIServer(_ *KRIE, _ *APIOptions) (*apiServer, error) {
+	return nil, fmt.Errorf("the API isn't available in the minimal build")
+}
+
+func (as *apiServer) start() error {
+	return fmt.Errorf("the API isn't available in the minimal build")
+}
+
+func (as *apiServer) stop() {}
+
+// Companion is unavailable in the minimal build
+type Companion struct{}
+
+// NewCompanion returns an error in the minimal build
+func NewCompanion(_ *Options) (*Companion, error) {
+	return nil, fmt.Errorf("the companion isn't available in the minimal build")
+}
+
+// Run returns an error in the minimal build
+func (c *Companion) Run(_ context.Context) error {
+	return fmt.Errorf("the companion isn't available in the minimal build")
+}
@@ -0,0 +1,296 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// BurstOptions configures the burst capture mode: when an event matches one of the burst rules, every event of the
+// offending process (or of its container) is recorded into a dedicated bundle file for the duration of the burst. The
+// event types with a "nop" action are temporarily enabled for the offending processes, as long as their probes were
+// loaded at startup (see EventTypes).
+type BurstOptions struct {
+	Rules     []string `yaml:"rules"`
+	Duration  int64    `yaml:"duration"`
+	Directory string   `yaml:"directory"`
+	MaxActive int      `yaml:"max_active"`
+
+	// EventTypes are the event types loaded at startup so that they can be enabled during a burst
+	EventTypes events.EventTypeList `yaml:"event_types"`
+}
+
+// IsValid returns an error if the burst options are invalid
+func (o *BurstOptions) IsValid() error {
+	if len(o.Rules) == 0 {
+		return nil
+	}
+	if len(o.Directory) == 0 {
+		return fmt.Errorf("empty directory")
+	}
+	if o.Duration <= 0 {
+		return fmt.Errorf("invalid duration: %d", o.Duration)
+	}
+	if o.MaxActive <= 0 {
+		return fmt.Errorf("invalid max_active: %d", o.MaxActive)
+	}
+	return nil
+}
+
+func (o *BurstOptions) isEnabled() bool {
+	return len(o.Rules) > 0
+}
+
+// NewBurstOptions returns a default set of burst options
+func NewBurstOptions() *BurstOptions {
+	return &BurstOptions{
+		Duration:  30,
+		Directory: "/var/log/krie/bursts",
+		MaxActive: 8,
+	}
+}
+
+// burstProcessSnapshot is a snapshot of a process taken when a burst starts
+type burstProcessSnapshot struct {
+	PID         uint32   `json:"pid"`
+	Exe         string   `json:"exe,omitempty"`
+	Cmdline     []string `json:"cmdline,omitempty"`
+	KernelStack []string `json:"kernel_stack,omitempty"`
+}
+
+// burstHeader is the first record of a burst bundle
+type burstHeader struct {
+	TriggerEventID string                 `json:"trigger_event_id"`
+	TriggerRuleID  string                 `json:"trigger_rule_id"`
+	Start          time.Time              `json:"start"`
+	End            time.Time              `json:"end"`
+	ContainerID    string                 `json:"container_id,omitempty"`
+	Processes      []burstProcessSnapshot `json:"processes"`
+}
+
+type burst struct {
+	pid         uint32
+	containerID string
+	pids        []uint32
+	deadline    time.Time
+	file        *os.File
+}
+
+func (b *burst) matches(pid uint32, containerID string) bool {
+	if len(b.containerID) > 0 {
+		return b.containerID == containerID
+	}
+	return b.pid == pid
+}
+
+// burstRecorder records the bursts triggered by the burst rules
+type burstRecorder struct {
+	sync.Mutex
+	krie    *KRIE
+	options *BurstOptions
+	active  []*burst
+}
+
+func newBurstRecorder(krie *KRIE, options *BurstOptions) *burstRecorder {
+	return &burstRecorder{
+		krie:    krie,
+		options: options,
+	}
+}
+
+func (br *burstRecorder) isEnabled() bool {
+	return br.options.isEnabled()
+}
+
+// record writes the event to the active bursts of its process, and starts a new burst if the event matches a burst
+// rule. It returns true if the event was written to a burst bundle.
+func (br *burstRecorder) record(event *events.Event, data []byte) bool {
+	if !event.HasProcessContext() {
+		return false
+	}
+	containerID := event.Process.ContainerID()
+
+	br.Lock()
+	defer br.Unlock()
+
+	now := time.Now()
+	br.expire(now)
+
+	var captured bool
+	for _, b := range br.active {
+		if b.matches(event.Process.PID, containerID) {
+			if _, err := b.file.Write(data); err != nil {
+				logrus.Warnf("couldn't write to burst bundle %s: %v", b.file.Name(), err)
+			}
+			captured = true
+		}
+	}
+	if captured || !matchRules(br.options.Rules, event.Policy.RuleID) {
+		return captured
+	}
+
+	if len(br.active) >= br.options.MaxActive {
+		logrus.Warnf("couldn't start burst capture for %s: %d bursts already active", event.ID, len(br.active))
+		return false
+	}
+	b, err := br.start(event, containerID, data, now)
+	if err != nil {
+		logrus.Errorf("couldn't start burst capture for %s: %v", event.ID, err)
+		return false
+	}
+	br.active = append(br.active, b)
+	logrus.Infof("burst capture started for %s (pid: %d, container: %s), recording to %s", event.ID, event.Process.PID, containerID, b.file.Name())
+	return true
+}
+
+func (br *burstRecorder) start(event *events.Event, containerID string, data []byte, now time.Time) (*burst, error) {
+	if err := os.MkdirAll(br.options.Directory, 0700); err != nil {
+		return nil, fmt.Errorf("couldn't create burst directory: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(br.options.Directory, fmt.Sprintf("burst-%s.json", event.ID)), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create burst bundle: %w", err)
+	}
+
+	b := &burst{
+		pid:         event.Process.PID,
+		containerID: containerID,
+		pids:        []uint32{event.Process.PID},
+		deadline:    now.Add(time.Duration(br.options.Duration) * time.Second),
+		file:        f,
+	}
+	if len(containerID) > 0 {
+		b.pids = containerPIDs(containerID)
+	}
+
+	// enable the additional event types for the offending processes
+	if br.krie.burstPidsMap != nil {
+		deadline := uint64(br.krie.timeResolver.ComputeMonotonicTimestamp(b.deadline))
+		for _, pid := range b.pids {
+			if err = br.krie.burstPidsMap.Put(pid, deadline); err != nil {
+				logrus.Warnf("couldn't enable burst capture for pid %d: %v", pid, err)
+			}
+		}
+	}
+
+	header := burstHeader{
+		TriggerEventID: event.ID,
+		TriggerRuleID:  event.Policy.RuleID,
+		Start:          now,
+		End:            b.deadline,
+		ContainerID:    containerID,
+	}
+	for _, pid := range b.pids {
+		header.Processes = append(header.Processes, snapshotProcess(pid))
+	}
+	headerData, err := json.Marshal(header)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("couldn't marshal burst header: %w", err)
+	}
+	if _, err = f.Write(append(headerData, '\n')); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("couldn't write burst header: %w", err)
+	}
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("couldn't write burst trigger: %w", err)
+	}
+	return b, nil
+}
+
+// expire closes the bursts which reached their deadline
+func (br *burstRecorder) expire(now time.Time) {
+	active := br.active[:0]
+	for _, b := range br.active {
+		if now.Before(b.deadline) {
+			active = append(active, b)
+			continue
+		}
+		br.stop(b)
+	}
+	br.active = active
+}
+
+func (br *burstRecorder) stop(b *burst) {
+	if br.krie.burstPidsMap != nil {
+		for _, pid := range b.pids {
+			_ = br.krie.burstPidsMap.Delete(pid)
+		}
+	}
+	logrus.Infof("burst capture recorded to %s", b.file.Name())
+	_ = b.file.Close()
+}
+
+func (br *burstRecorder) close() {
+	br.Lock()
+	defer br.Unlock()
+	for _, b := range br.active {
+		br.stop(b)
+	}
+	br.active = nil
+}
+
+// containerPIDs returns the processes of the provided container
+func containerPIDs(containerID string) []uint32 {
+	var pids []uint32
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		pid, err := strconv.ParseUint(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		cgroup, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "cgroup"))
+		if err != nil {
+			continue
+		}
+		if strings.Contains(string(cgroup), containerID) {
+			pids = append(pids, uint32(pid))
+		}
+	}
+	return pids
+}
+
+// snapshotProcess captures the executable, the full command line and the kernel stack of a process
+func snapshotProcess(pid uint32) burstProcessSnapshot {
+	snapshot := burstProcessSnapshot{
+		PID: pid,
+	}
+	procDir := filepath.Join("/proc", strconv.FormatUint(uint64(pid), 10))
+	snapshot.Exe, _ = os.Readlink(filepath.Join(procDir, "exe"))
+	if cmdline, err := os.ReadFile(filepath.Join(procDir, "cmdline")); err == nil {
+		snapshot.Cmdline = strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+	}
+	if stack, err := os.ReadFile(filepath.Join(procDir, "stack")); err == nil {
+		snapshot.KernelStack = strings.Split(strings.TrimSpace(string(stack)), "\n")
+	}
+	return snapshot
+}
@@ -0,0 +1,406 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// CaptureOptions configures the time-window captures requested through the API: the events of the requested event
+// types (and process) are recorded into a dedicated file for the duration of the capture
+type CaptureOptions struct {
+	// Directory is the directory of the capture files
+	Directory string `yaml:"directory"`
+	// MaxActive is the maximum count of concurrent captures
+	MaxActive int `yaml:"max_active"`
+	// MaxDuration is the maximum duration of a capture, in seconds
+	MaxDuration int64 `yaml:"max_duration"`
+}
+
+// IsValid returns an error if the capture options are invalid
+func (o *CaptureOptions) IsValid() error {
+	if len(o.Directory) == 0 {
+		return fmt.Errorf("empty directory")
+	}
+	if o.MaxActive <= 0 {
+		return fmt.Errorf("invalid max_active: %d", o.MaxActive)
+	}
+	if o.MaxDuration <= 0 {
+		return fmt.Errorf("invalid max_duration: %d", o.MaxDuration)
+	}
+	return nil
+}
+
+// NewCaptureOptions returns a default set of capture options
+func NewCaptureOptions() *CaptureOptions {
+	return &CaptureOptions{
+		Directory:   "/var/log/krie/captures",
+		MaxActive:   4,
+		MaxDuration: 3600,
+	}
+}
+
+// CaptureRequest is a time-window capture of the events of a set of event types, optionally restricted to a process
+type CaptureRequest struct {
+	// Duration is the duration of the capture
+	Duration time.Duration
+	// EventTypes are the captured event types
+	EventTypes events.EventTypeList
+	// PID restricts the capture to the events of a process (thread group), 0 captures every process
+	PID uint32
+	// Output is the file of the captured events, one JSON event per line
+	Output string
+}
+
+// IsValid returns an error if the capture request is invalid
+func (r *CaptureRequest) IsValid() error {
+	if r.Duration <= 0 {
+		return fmt.Errorf("invalid duration: %s", r.Duration)
+	}
+	if len(r.EventTypes) == 0 {
+		return fmt.Errorf("no event type to capture")
+	}
+	return nil
+}
+
+// eventTypeNames returns the names of the captured event types
+func (r *CaptureRequest) eventTypeNames() []string {
+	out := make([]string, 0, len(r.EventTypes))
+	for _, eventType := range r.EventTypes {
+		out = append(out, eventType.String())
+	}
+	return out
+}
+
+// matches returns true if the event is captured by the request
+func (r *CaptureRequest) matches(event *events.Event) bool {
+	if !r.EventTypes.Contains(event.Kernel.Type) {
+		return false
+	}
+	return r.PID == 0 || (event.HasProcessContext() && event.Process.PID == r.PID)
+}
+
+// ParseCaptureEventTypes parses a comma separated list of event types
+func ParseCaptureEventTypes(input string) (events.EventTypeList, error) {
+	var out events.EventTypeList
+	for _, name := range strings.Split(input, ",") {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+		eventType := events.ParseEventType(name)
+		if eventType == events.UnknownEventType {
+			return nil, fmt.Errorf("unknown event type: %s", name)
+		}
+		out.Insert(eventType)
+	}
+	return out, nil
+}
+
+// CaptureResult is the summary of a capture
+type CaptureResult struct {
+	Output     string    `json:"output"`
+	EventTypes []string  `json:"event_types"`
+	PID        uint32    `json:"pid,omitempty"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Events     uint64    `json:"events"`
+}
+
+// oneShotCaptureOptions returns the options of a one-shot capture: only the probes of the requested event types are
+// attached, nothing is blocked nor killed, and the events are only written to the output of the capture. The
+// enrichments, the redaction and the privacy mode of the provided options apply.
+func oneShotCaptureOptions(options *Options, request *CaptureRequest, captured *uint64) *Options {
+	out := *options
+	out.Events = options.Events.WithEventTypes(request.EventTypes)
+	out.Output = request.Output
+	out.OutputFormats = nil
+	out.Sinks = nil
+	out.Responders = nil
+	// the state of the agent (checkpoints, spools) isn't shared with the capture
+	out.StateDirectory = ""
+	out.RecordFixtures = ""
+	out.Webhook = NewWebhookOptions()
+	out.Burst = NewBurstOptions()
+	out.API = NewAPIOptions()
+	out.Status = NewStatusOptions()
+	out.Companion = NewCompanionOptions()
+	out.Boot = NewBootOptions()
+	out.Profiling = NewProfilingOptions()
+	metrics := *options.Metrics
+	metrics.Listen = ""
+	metrics.TextfileDir = ""
+	out.Metrics = &metrics
+
+	out.Middlewares = append(append([]Middleware{}, options.Middlewares...),
+		Middleware{
+			Name:  "capture_filter",
+			After: DecodeStage,
+			Handler: func(event *events.Event, next EventProcessor) error {
+				if !request.matches(event) {
+					return nil
+				}
+				return next(event)
+			},
+		},
+		Middleware{
+			Name:  "capture_counter",
+			After: RedactStage,
+			Handler: func(event *events.Event, next EventProcessor) error {
+				if request.EventTypes.Contains(event.Kernel.Type) {
+					atomic.AddUint64(captured, 1)
+				}
+				return next(event)
+			},
+		},
+	)
+	return &out
+}
+
+// Capture runs a one-shot capture: a new instance of KRIE attaches the probes of the requested event types, writes
+// their events to the output of the request, and detaches when the duration elapses or when the context is cancelled.
+// It can't run next to another instance of KRIE.
+func Capture(ctx context.Context, options *Options, request *CaptureRequest) (*CaptureResult, error) {
+	if err := request.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid capture: %w", err)
+	}
+	if len(request.Output) == 0 || request.Output == StdoutOutput {
+		return nil, fmt.Errorf("invalid capture: the output must be a file")
+	}
+	if err := os.MkdirAll(filepath.Dir(request.Output), 0700); err != nil {
+		return nil, fmt.Errorf("couldn't create capture directory: %w", err)
+	}
+
+	var captured uint64
+	trace, err := NewKRIE(oneShotCaptureOptions(options, request, &captured))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create a new instance of KRIE: %w", err)
+	}
+
+	result := &CaptureResult{
+		Output:     request.Output,
+		EventTypes: request.eventTypeNames(),
+		PID:        request.PID,
+		Start:      time.Now(),
+	}
+	if err = trace.Start(); err != nil {
+		_ = trace.Stop()
+		return nil, fmt.Errorf("couldn't start: %w", err)
+	}
+	logrus.Infof("capturing [%s] for %s to %s", request.EventTypes, request.Duration, request.Output)
+
+	timer := time.NewTimer(request.Duration)
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		timer.Stop()
+	}
+
+	err = trace.Stop()
+	result.End = time.Now()
+	result.Events = atomic.LoadUint64(&captured)
+	return result, err
+}
+
+// captureSession is a time-window capture requested through the API
+type captureSession struct {
+	id       string
+	request  *CaptureRequest
+	start    time.Time
+	deadline time.Time
+	events   uint64
+	file     *os.File
+}
+
+// result returns the summary of the session
+func (cs *captureSession) result() *CaptureResult {
+	return &CaptureResult{
+		Output:     cs.request.Output,
+		EventTypes: cs.request.eventTypeNames(),
+		PID:        cs.request.PID,
+		Start:      cs.start,
+		End:        cs.deadline,
+		Events:     cs.events,
+	}
+}
+
+// captureRecorder records the time-window captures of a running instance of KRIE. The probes of the running instance
+// are already attached: a capture records the events of the activated event types, and the event types enabled
+// during a burst (see BurstOptions.EventTypes) when the capture is restricted to a process.
+type captureRecorder struct {
+	sync.Mutex
+	krie    *KRIE
+	options *CaptureOptions
+	active  []*captureSession
+	count   int32
+}
+
+func newCaptureRecorder(krie *KRIE, options *CaptureOptions) *captureRecorder {
+	return &captureRecorder{
+		krie:    krie,
+		options: options,
+	}
+}
+
+// isActive returns true if at least one capture is active
+func (cr *captureRecorder) isActive() bool {
+	return atomic.LoadInt32(&cr.count) > 0
+}
+
+// start starts a capture, the output of the request is ignored: the capture is written to the capture directory
+func (cr *captureRecorder) start(request *CaptureRequest) (*CaptureResult, error) {
+	if err := request.IsValid(); err != nil {
+		return nil, err
+	}
+	if request.Duration > time.Duration(cr.options.MaxDuration)*time.Second {
+		return nil, fmt.Errorf("duration %s exceeds max_duration (%ds)", request.Duration, cr.options.MaxDuration)
+	}
+	probed := cr.krie.probedEventTypes()
+	policies, _, _ := cr.krie.currentPolicies()
+	activated := policies.ActivatedEventTypes()
+	for _, eventType := range request.EventTypes {
+		if !probed.Contains(eventType) {
+			return nil, fmt.Errorf("the probes of %s aren't loaded, use krie capture", eventType)
+		}
+		if !activated.Contains(eventType) && request.PID == 0 {
+			return nil, fmt.Errorf("%s is only enabled during bursts, the capture must be restricted to a pid", eventType)
+		}
+	}
+
+	cr.Lock()
+	defer cr.Unlock()
+
+	now := time.Now()
+	cr.expire(now)
+	if len(cr.active) >= cr.options.MaxActive {
+		return nil, fmt.Errorf("%d captures already active", len(cr.active))
+	}
+
+	if err := os.MkdirAll(cr.options.Directory, 0700); err != nil {
+		return nil, fmt.Errorf("couldn't create capture directory: %w", err)
+	}
+	session := &captureSession{
+		id:       events.NewEventID(),
+		start:    now,
+		deadline: now.Add(request.Duration),
+	}
+	captured := *request
+	captured.Output = filepath.Join(cr.options.Directory, fmt.Sprintf("capture-%s.json", session.id))
+	session.request = &captured
+
+	var err error
+	if session.file, err = os.OpenFile(captured.Output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600); err != nil {
+		return nil, fmt.Errorf("couldn't create capture file: %w", err)
+	}
+
+	// enable the burst only event types for the captured process
+	if captured.PID > 0 && cr.krie.burstPidsMap != nil {
+		deadline := uint64(cr.krie.timeResolver.ComputeMonotonicTimestamp(session.deadline))
+		if err = cr.krie.burstPidsMap.Put(captured.PID, deadline); err != nil {
+			logrus.Warnf("couldn't enable the burst only event types for pid %d: %v", captured.PID, err)
+		}
+	}
+
+	cr.active = append(cr.active, session)
+	atomic.StoreInt32(&cr.count, int32(len(cr.active)))
+	logrus.Infof("capturing [%s] for %s to %s", captured.EventTypes, captured.Duration, captured.Output)
+	return session.result(), nil
+}
+
+// record writes the event to the active captures it matches
+func (cr *captureRecorder) record(event *events.Event, data []byte) {
+	cr.Lock()
+	defer cr.Unlock()
+
+	cr.expire(time.Now())
+	for _, session := range cr.active {
+		if !session.request.matches(event) {
+			continue
+		}
+		if _, err := session.file.Write(data); err != nil {
+			logrus.Warnf("couldn't write to capture %s: %v", session.file.Name(), err)
+			continue
+		}
+		session.events++
+	}
+}
+
+// list returns the summary of the active captures
+func (cr *captureRecorder) list() []*CaptureResult {
+	cr.Lock()
+	defer cr.Unlock()
+
+	cr.expire(time.Now())
+	out := make([]*CaptureResult, 0, len(cr.active))
+	for _, session := range cr.active {
+		out = append(out, session.result())
+	}
+	return out
+}
+
+// expire closes the captures which reached their deadline
+func (cr *captureRecorder) expire(now time.Time) {
+	active := cr.active[:0]
+	for _, session := range cr.active {
+		if now.Before(session.deadline) {
+			active = append(active, session)
+			continue
+		}
+		cr.stop(session)
+	}
+	cr.active = active
+	atomic.StoreInt32(&cr.count, int32(len(cr.active)))
+}
+
+func (cr *captureRecorder) stop(session *captureSession) {
+	if session.request.PID > 0 && cr.krie.burstPidsMap != nil {
+		_ = cr.krie.burstPidsMap.Delete(session.request.PID)
+	}
+	logrus.Infof("capture recorded to %s (%d events)", session.file.Name(), session.events)
+	_ = session.file.Close()
+}
+
+func (cr *captureRecorder) close() {
+	cr.Lock()
+	defer cr.Unlock()
+	for _, session := range cr.active {
+		cr.stop(session)
+	}
+	cr.active = nil
+	atomic.StoreInt32(&cr.count, 0)
+}
+
+// StartCapture starts a time-window capture on the running instance, see captureRecorder
+func (e *KRIE) StartCapture(request *CaptureRequest) (*CaptureResult, error) {
+	return e.captures.start(request)
+}
+
+// Captures returns the summary of the active captures
+func (e *KRIE) Captures() []*CaptureResult {
+	return e.captures.list()
+}
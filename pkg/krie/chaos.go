@@ -0,0 +1,147 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	chaosMetricName = "krie_chaos_injected_faults_total"
+	chaosMetricHelp = "Faults injected by the chaos mode"
+
+	chaosFaultDecodeError = "decode_error"
+	chaosFaultSinkFailure = "sink_failure"
+	chaosFaultPerfDrop    = "perf_drop"
+)
+
+// errChaos is the error injected by the chaos mode
+var errChaos = errors.New("fault injected by the chaos mode")
+
+// ChaosOptions configures the chaos mode, a developer mode which randomly injects faults to exercise the drop
+// accounting, the metrics and the recovery paths of KRIE. Rates are probabilities between 0 and 1.
+type ChaosOptions struct {
+	Enabled         bool    `yaml:"enabled"`
+	Seed            int64   `yaml:"seed"`
+	DecodeErrorRate float64 `yaml:"decode_error_rate"`
+	SinkFailureRate float64 `yaml:"sink_failure_rate"`
+	PerfDropRate    float64 `yaml:"perf_drop_rate"`
+}
+
+// IsValid returns an error if the chaos options are invalid
+func (o *ChaosOptions) IsValid() error {
+	for name, rate := range map[string]float64{
+		"decode_error_rate": o.DecodeErrorRate,
+		"sink_failure_rate": o.SinkFailureRate,
+		"perf_drop_rate":    o.PerfDropRate,
+	} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("invalid %s: %f", name, rate)
+		}
+	}
+	return nil
+}
+
+// NewChaosOptions returns a default set of chaos options
+func NewChaosOptions() *ChaosOptions {
+	return &ChaosOptions{}
+}
+
+// chaos injects faults according to the chaos options
+type chaos struct {
+	options *ChaosOptions
+	metrics *metricsRegistry
+
+	lock sync.Mutex
+	rand *rand.Rand
+}
+
+func newChaos(options *ChaosOptions, metrics *metricsRegistry) *chaos {
+	seed := options.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	logrus.Warnf("chaos mode enabled (seed %d): decode_error_rate %g, sink_failure_rate %g, perf_drop_rate %g", seed, options.DecodeErrorRate, options.SinkFailureRate, options.PerfDropRate)
+	return &chaos{
+		options: options,
+		metrics: metrics,
+		rand:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+// inject returns true if the fault should be injected
+func (c *chaos) inject(fault string, rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	c.lock.Lock()
+	injected := c.rand.Float64() < rate
+	c.lock.Unlock()
+
+	if injected {
+		c.metrics.add(chaosMetricName, chaosMetricHelp, 1, "fault", fault)
+	}
+	return injected
+}
+
+func (c *chaos) decodeError() bool {
+	return c != nil && c.inject(chaosFaultDecodeError, c.options.DecodeErrorRate)
+}
+
+func (c *chaos) perfDrop() bool {
+	return c != nil && c.inject(chaosFaultPerfDrop, c.options.PerfDropRate)
+}
+
+func (c *chaos) sinkFailure() bool {
+	return c != nil && c.inject(chaosFaultSinkFailure, c.options.SinkFailureRate)
+}
+
+// chaosEventSink fails the writes of a sink at random
+type chaosEventSink struct {
+	chaos *chaos
+	sink  eventSink
+}
+
+func (cs *chaosEventSink) write(evt *queuedEvent) error {
+	if cs.chaos.sinkFailure() {
+		return errChaos
+	}
+	return cs.sink.write(evt)
+}
+
+func (cs *chaosEventSink) close() error {
+	return cs.sink.close()
+}
+
+// chaosSink fails the batches of a registered sink at random
+type chaosSink struct {
+	Sink
+	chaos *chaos
+}
+
+func (cs *chaosSink) Send(batch []SinkEvent) error {
+	if cs.chaos.sinkFailure() {
+		return errChaos
+	}
+	return cs.Sink.Send(batch)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	checkpointsDirectory = "checkpoints"
+	// checkpointInterval is the number of acknowledged events between two checkpoints
+	checkpointInterval = 64
+)
+
+// checkpoint is the delivery position of a sink in its spool, persisted in the state directory so that a restart
+// resumes the delivery from the last acknowledged event
+type checkpoint struct {
+	path    string
+	pending int
+
+	Segment string `json:"segment"`
+	Offset  int64  `json:"offset"`
+	EventID string `json:"event_id"`
+}
+
+// loadCheckpoint loads the checkpoint of the provided sink, an empty checkpoint is returned if none was saved yet
+func loadCheckpoint(stateDirectory string, sink string) (*checkpoint, error) {
+	dir := filepath.Join(stateDirectory, checkpointsDirectory)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("couldn't create checkpoints directory: %w", err)
+	}
+
+	cp := &checkpoint{
+		path: filepath.Join(dir, sink+".json"),
+	}
+	data, err := os.ReadFile(cp.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cp, nil
+		}
+		return nil, fmt.Errorf("couldn't read %s checkpoint: %w", sink, err)
+	}
+	if err = json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("couldn't parse %s checkpoint: %w", sink, err)
+	}
+	return cp, nil
+}
+
+// acknowledge moves the checkpoint after the provided event, the checkpoint is saved every checkpointInterval events
+func (cp *checkpoint) acknowledge(segment string, offset int64, id string) error {
+	cp.Segment = segment
+	cp.Offset = offset
+	cp.EventID = id
+	cp.pending++
+	if cp.pending < checkpointInterval {
+		return nil
+	}
+	return cp.save()
+}
+
+// save atomically writes the checkpoint to disk
+func (cp *checkpoint) save() error {
+	cp.pending = 0
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal checkpoint: %w", err)
+	}
+	tmp := cp.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("couldn't write checkpoint: %w", err)
+	}
+	if err = os.Rename(tmp, cp.path); err != nil {
+		return fmt.Errorf("couldn't write checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	TimestampAnomalyStale  = "stale"
+	TimestampAnomalyFuture = "future"
+
+	timestampAnomaliesMetricName = "krie_timestamp_anomalies_total"
+	timestampAnomaliesMetricHelp = "Events with an implausible kernel timestamp"
+)
+
+// ClockOptions configures the sanity checks of the kernel timestamps of the events. An event received long after its
+// kernel timestamp points to a stalled perf buffer, a timestamp in the future points to clock tampering or to a replay.
+type ClockOptions struct {
+	// MaxAge is the maximum delay, in seconds, between the kernel timestamp of an event and its reception, 0 disables
+	// the check
+	MaxAge int64 `yaml:"max_age"`
+	// MaxFuture is the maximum tolerated drift, in milliseconds, of a kernel timestamp in the future, 0 disables the
+	// check
+	MaxFuture int64 `yaml:"max_future"`
+	// ReportInterval is the minimum number of seconds between two clock_skew events of the same anomaly
+	ReportInterval int64 `yaml:"report_interval"`
+}
+
+// IsValid returns an error if the clock options are invalid
+func (o *ClockOptions) IsValid() error {
+	if o.MaxAge < 0 {
+		return fmt.Errorf("invalid max_age: %d", o.MaxAge)
+	}
+	if o.MaxFuture < 0 {
+		return fmt.Errorf("invalid max_future: %d", o.MaxFuture)
+	}
+	if o.ReportInterval < 0 {
+		return fmt.Errorf("invalid report_interval: %d", o.ReportInterval)
+	}
+	return nil
+}
+
+// NewClockOptions returns a default set of clock options
+func NewClockOptions() *ClockOptions {
+	return &ClockOptions{
+		MaxAge:         60,
+		MaxFuture:      1000,
+		ReportInterval: 10,
+	}
+}
+
+// clockChecker flags the events with an implausible kernel timestamp, and reports them with clock_skew events
+type clockChecker struct {
+	options *ClockOptions
+	krie    *KRIE
+
+	lock       sync.Mutex
+	lastReport map[string]time.Time
+	suppressed map[string]uint64
+}
+
+func newClockChecker(krie *KRIE, options *ClockOptions) *clockChecker {
+	return &clockChecker{
+		options:    options,
+		krie:       krie,
+		lastReport: make(map[string]time.Time),
+		suppressed: make(map[string]uint64),
+	}
+}
+
+// check annotates the event if its kernel timestamp is implausible
+func (c *clockChecker) check(event *events.Event, received time.Time) {
+	if event.Kernel.Time.IsZero() {
+		return
+	}
+	skew := received.Sub(event.Kernel.Time)
+	switch {
+	case c.options.MaxAge > 0 && skew > time.Duration(c.options.MaxAge)*time.Second:
+		event.TimestampAnomaly = TimestampAnomalyStale
+	case c.options.MaxFuture > 0 && -skew > time.Duration(c.options.MaxFuture)*time.Millisecond:
+		event.TimestampAnomaly = TimestampAnomalyFuture
+	default:
+		return
+	}
+	c.krie.metrics.add(timestampAnomaliesMetricName, timestampAnomaliesMetricHelp, 1, "anomaly", event.TimestampAnomaly)
+
+	// rate limit the diagnostic events: a stalled buffer flags every queued event
+	c.lock.Lock()
+	if received.Sub(c.lastReport[event.TimestampAnomaly]) < time.Duration(c.options.ReportInterval)*time.Second {
+		c.suppressed[event.TimestampAnomaly]++
+		c.lock.Unlock()
+		return
+	}
+	c.lastReport[event.TimestampAnomaly] = received
+	suppressed := c.suppressed[event.TimestampAnomaly]
+	c.suppressed[event.TimestampAnomaly] = 0
+	c.lock.Unlock()
+
+	diagnostic := events.NewEvent()
+	diagnostic.Kernel.Type = events.ClockSkewEventType
+	diagnostic.ClockSkewEvent = events.ClockSkewEvent{
+		Anomaly:          event.TimestampAnomaly,
+		TriggerEventID:   event.ID,
+		TriggerEventType: event.Kernel.Type.String(),
+		KernelTime:       event.Kernel.Time,
+		ReceivedTime:     received,
+		Skew:             skew.Milliseconds(),
+		Suppressed:       suppressed,
+	}
+	if err := c.krie.emitEvent(diagnostic); err != nil {
+		logrus.Errorf("couldn't emit clock_skew event: %v", err)
+	}
+}
@@ -0,0 +1,461 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	// bundleSignaturePrefix is prepended to the signed digest of a bundle
+	bundleSignaturePrefix = "krie-bundle/v1"
+	// maxCollectedLogSize is the maximum number of bytes collected from the end of each log file
+	maxCollectedLogSize = 16 * 1024 * 1024
+)
+
+// collectedKernelFiles are the kernel information files added to a forensic bundle
+var collectedKernelFiles = map[string]string{
+	"kernel/version":    "/proc/version",
+	"kernel/cmdline":    "/proc/cmdline",
+	"kernel/os-release": "/etc/os-release",
+}
+
+// collectedInventoryFiles are the inventory files added to a forensic bundle
+var collectedInventoryFiles = map[string]string{
+	"inventory/modules":                   "/proc/modules",
+	"inventory/tainted":                   "/proc/sys/kernel/tainted",
+	"inventory/lockdown":                  "/sys/kernel/security/lockdown",
+	"inventory/modules_disabled":          "/proc/sys/kernel/modules_disabled",
+	"inventory/kptr_restrict":             "/proc/sys/kernel/kptr_restrict",
+	"inventory/unprivileged_bpf_disabled": "/proc/sys/kernel/unprivileged_bpf_disabled",
+	"inventory/kprobe_events":             "/sys/kernel/debug/tracing/kprobe_events",
+}
+
+// CollectOptions configures a forensic bundle
+type CollectOptions struct {
+	// Output is the path of the bundle, a .tar.gz archive
+	Output string
+	// Events is the maximum number of events collected from the end of the output file
+	Events int
+	// Since is the age of the oldest burst bundle collected
+	Since time.Duration
+	// LogFiles are the agent log files to collect
+	LogFiles []string
+	// Key is the ed25519 private key used to sign the bundle, leave empty to skip the signature
+	Key string
+}
+
+// bundleManifest describes the content of a forensic bundle
+type bundleManifest struct {
+	Created       time.Time         `json:"created"`
+	Hostname      string            `json:"hostname"`
+	PolicyVersion string            `json:"policy_version,omitempty"`
+	PolicyHash    string            `json:"policy_hash"`
+	Files         map[string]string `json:"files"`
+	Errors        []string          `json:"errors,omitempty"`
+}
+
+// probeReport lists the probes loaded by KRIE for each event type
+type probeReport struct {
+	ActivatedEventTypes []string            `json:"activated_event_types"`
+	BurstEventTypes     []string            `json:"burst_event_types,omitempty"`
+	Probes              map[string][]string `json:"probes"`
+}
+
+type bundleWriter struct {
+	tar      *tar.Writer
+	manifest *bundleManifest
+}
+
+func (bw *bundleWriter) add(name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: bw.manifest.Created,
+	}
+	if err := bw.tar.WriteHeader(header); err != nil {
+		return fmt.Errorf("couldn't add %s to bundle: %w", name, err)
+	}
+	if _, err := bw.tar.Write(data); err != nil {
+		return fmt.Errorf("couldn't add %s to bundle: %w", name, err)
+	}
+	h := sha256.Sum256(data)
+	bw.manifest.Files[name] = hex.EncodeToString(h[:])
+	return nil
+}
+
+// addFile adds a file to the bundle, missing files are recorded in the manifest
+func (bw *bundleWriter) addFile(name string, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		bw.manifest.Errors = append(bw.manifest.Errors, fmt.Sprintf("%s: %v", name, err))
+		return nil
+	}
+	return bw.add(name, data)
+}
+
+func (bw *bundleWriter) addJSON(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't marshal %s: %w", name, err)
+	}
+	return bw.add(name, data)
+}
+
+// Collect packages the recent events, the inventory, the probe report, the kernel information, the policies and the
+// agent logs into a compressed forensic bundle. When a key is provided, the bundle is signed and the signature is
+// written next to the bundle with a ".sig" extension. Collect returns the SHA-256 digest of the bundle.
+func Collect(configPath string, options *Options, collect CollectOptions) (string, error) {
+	policyHash, err := options.Events.Hash()
+	if err != nil {
+		return "", err
+	}
+
+	var key ed25519.PrivateKey
+	if len(collect.Key) > 0 {
+		if key, err = ReadPrivateKey(collect.Key); err != nil {
+			return "", err
+		}
+	}
+
+	// the hostname of the manifest follows the privacy mode
+	p, err := newPrivacy(options.Privacy)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.OpenFile(collect.Output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", fmt.Errorf("couldn't create bundle: %w", err)
+	}
+	defer f.Close()
+
+	digest := sha256.New()
+	gz := gzip.NewWriter(io.MultiWriter(f, digest))
+	bw := &bundleWriter{
+		tar: tar.NewWriter(gz),
+		manifest: &bundleManifest{
+			Created:       time.Now(),
+			PolicyVersion: options.PolicyVersion,
+			PolicyHash:    policyHash,
+			Files:         make(map[string]string),
+		},
+	}
+	bw.manifest.Hostname = p.hostname()
+
+	for _, step := range []func() error{
+		func() error { return collectConfig(bw, configPath) },
+		func() error { return collectEvents(bw, options, collect) },
+		func() error { return collectKernelInfo(bw) },
+		func() error { return collectFiles(bw, collectedInventoryFiles) },
+		func() error { return bw.addJSON("probes/report.json", newProbeReport(options)) },
+		func() error { return collectLogs(bw, collect.LogFiles) },
+	} {
+		if err = step(); err != nil {
+			return "", err
+		}
+	}
+
+	if err = bw.addJSON("manifest.json", bw.manifest); err != nil {
+		return "", err
+	}
+	if err = bw.tar.Close(); err != nil {
+		return "", fmt.Errorf("couldn't write bundle: %w", err)
+	}
+	if err = gz.Close(); err != nil {
+		return "", fmt.Errorf("couldn't write bundle: %w", err)
+	}
+
+	sum := hex.EncodeToString(digest.Sum(nil))
+	if key != nil {
+		signature := ed25519.Sign(key, []byte(fmt.Sprintf("%s\n%s", bundleSignaturePrefix, sum)))
+		if err = os.WriteFile(collect.Output+".sig", []byte(base64.StdEncoding.EncodeToString(signature)+"\n"), 0600); err != nil {
+			return "", fmt.Errorf("couldn't write bundle signature: %w", err)
+		}
+	}
+	return "sha256:" + sum, nil
+}
+
+// collectEvents adds the last events of the output file and the recent burst bundles
+func collectEvents(bw *bundleWriter, options *Options, collect CollectOptions) error {
+	if len(options.Output) > 0 && options.Output != StdoutOutput {
+		data, err := tailLines(options.Output, collect.Events)
+		if err != nil {
+			bw.manifest.Errors = append(bw.manifest.Errors, fmt.Sprintf("events/output.json: %v", err))
+		} else if err = bw.add("events/output.json", data); err != nil {
+			return err
+		}
+	}
+
+	if !options.Burst.isEnabled() {
+		return nil
+	}
+	entries, err := os.ReadDir(options.Burst.Directory)
+	if err != nil {
+		bw.manifest.Errors = append(bw.manifest.Errors, fmt.Sprintf("events/bursts: %v", err))
+		return nil
+	}
+	oldest := bw.manifest.Created.Add(-collect.Since)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || info.ModTime().Before(oldest) {
+			continue
+		}
+		if err = bw.addFile("events/bursts/"+entry.Name(), filepath.Join(options.Burst.Directory, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func collectKernelInfo(bw *bundleWriter) error {
+	var uname unix.Utsname
+	if err := unix.Uname(&uname); err == nil {
+		info := fmt.Sprintf("%s %s %s %s\n", unix.ByteSliceToString(uname.Sysname[:]), unix.ByteSliceToString(uname.Release[:]),
+			unix.ByteSliceToString(uname.Version[:]), unix.ByteSliceToString(uname.Machine[:]))
+		if err = bw.add("kernel/uname", []byte(info)); err != nil {
+			return err
+		}
+	}
+	return collectFiles(bw, collectedKernelFiles)
+}
+
+func collectFiles(bw *bundleWriter, files map[string]string) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := bw.addFile(name, files[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func collectLogs(bw *bundleWriter, logFiles []string) error {
+	for _, path := range logFiles {
+		name := "logs/" + filepath.Base(path)
+		f, err := os.Open(path)
+		if err != nil {
+			bw.manifest.Errors = append(bw.manifest.Errors, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if info, err := f.Stat(); err == nil && info.Size() > maxCollectedLogSize {
+			_, _ = f.Seek(-maxCollectedLogSize, io.SeekEnd)
+		}
+		data, err := io.ReadAll(f)
+		_ = f.Close()
+		if err != nil {
+			bw.manifest.Errors = append(bw.manifest.Errors, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if err = bw.add(name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newProbeReport(options *Options) *probeReport {
+	report := &probeReport{
+		Probes: make(map[string][]string),
+	}
+	eventTypes := append(events.EventTypeList{}, options.Events.ActivatedEventTypes()...)
+	for _, eventType := range options.Events.ActivatedEventTypes() {
+		report.ActivatedEventTypes = append(report.ActivatedEventTypes, eventType.String())
+	}
+	if options.Burst.isEnabled() {
+		for _, eventType := range options.Burst.EventTypes {
+			report.BurstEventTypes = append(report.BurstEventTypes, eventType.String())
+			eventTypes.Insert(eventType)
+		}
+	}
+	for _, eventType := range eventTypes {
+		for _, probe := range events.AllProbes(events.EventTypeList{eventType}) {
+			report.Probes[eventType.String()] = append(report.Probes[eventType.String()], probe.EBPFFuncName)
+		}
+	}
+	sort.Strings(report.ActivatedEventTypes)
+	return report
+}
+
+// tailLines returns the last n lines of a file
+func tailLines(path string, n int) ([]byte, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := make([]string, 0, n)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(lines) == n {
+			lines = lines[1:]
+		}
+		lines = append(lines, scanner.Text())
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// redactedConfigValue replaces the secrets of the configuration added to a forensic bundle
+const redactedConfigValue = "redacted"
+
+// sensitiveConfigKeys are the substrings of the configuration keys holding secrets. The values of the headers are
+// redacted as well, since they usually carry credentials (Authorization, API keys, ...).
+var sensitiveConfigKeys = []string{"password", "secret", "token", "salt", "hash_key", "api_key", "apikey", "private_key", "credential", "authorization", "headers"}
+
+// isSensitiveConfigKey returns true if the value of a configuration key is a secret. The keys pointing to a file
+// (token_file, hash_key_file, ...) only hold a path, and are kept.
+func isSensitiveConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	if strings.HasSuffix(key, "_file") || strings.HasSuffix(key, "_files") {
+		return false
+	}
+	if key == "key" {
+		return true
+	}
+	for _, sensitive := range sensitiveConfigKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// isURLConfigKey returns true if the value of a configuration key is a URL, which can embed credentials
+func isURLConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	return strings.HasSuffix(key, "url") || key == "proxy" || key == "endpoint"
+}
+
+// redactURL removes the credentials of a URL: its user information and the sensitive query parameters
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || len(u.Host) == 0 {
+		return value
+	}
+	if u.User != nil {
+		u.User = url.User(redactedConfigValue)
+	}
+	if query := u.Query(); len(query) > 0 {
+		for key := range query {
+			if isSensitiveConfigKey(key) {
+				query.Set(key, redactedConfigValue)
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// redactConfigNode redacts the secrets of a configuration node, secret is true if the node is the value of a
+// sensitive key
+func redactConfigNode(node *yaml.Node, secret bool) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			redactConfigNode(value, secret || isSensitiveConfigKey(key.Value))
+			if value.Kind == yaml.ScalarNode && isURLConfigKey(key.Value) {
+				value.Value = redactURL(value.Value)
+			}
+		}
+	case yaml.ScalarNode:
+		if secret && len(node.Value) > 0 {
+			node.Value = redactedConfigValue
+			node.Tag = "!!str"
+			node.Style = 0
+		}
+	default:
+		for _, child := range node.Content {
+			redactConfigNode(child, secret)
+		}
+	}
+}
+
+// sanitizeConfig returns a copy of a configuration file without its secrets: the hash key of the privacy mode, the
+// passwords, the tokens, the headers of the sinks and the intel feeds, and the credentials embedded in the URLs
+func sanitizeConfig(data []byte) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("couldn't parse configuration: %w", err)
+	}
+	redactConfigNode(&root, false)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return nil, fmt.Errorf("couldn't encode configuration: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("couldn't encode configuration: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// collectConfig adds a sanitized copy of the configuration to the bundle, the configuration is left out if it can't
+// be sanitized
+func collectConfig(bw *bundleWriter, configPath string) error {
+	data, err := os.ReadFile(configPath)
+	if err == nil {
+		data, err = sanitizeConfig(data)
+	}
+	if err != nil {
+		bw.manifest.Errors = append(bw.manifest.Errors, fmt.Sprintf("policy/config.yaml: %v", err))
+		return nil
+	}
+	return bw.add("policy/config.yaml", data)
+}
@@ -0,0 +1,229 @@
+//go:build !krie_minimal
+
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// companionRetryInterval is the delay between two connection attempts to the stream of the privileged process
+const companionRetryInterval = 5 * time.Second
+
+// Companion is an unprivileged KRIE process: it doesn't load any eBPF program, it reads the events streamed by the
+// privileged process (see CompanionOptions) and hosts the read-only API (noise report and annotations) and the output
+// file.
+type Companion struct {
+	options *Options
+	output  *fileSink
+
+	noise       *noiseTracker
+	annotations *annotationStore
+	server      *http.Server
+	token       string
+}
+
+// NewCompanion returns a companion for the provided options. The annotations are loaded from the storage of the
+// options (or from the output file of the companion), and follow the annotation events of the stream. The annotations
+// recorded by the companion are written as annotation events to its output file: they can't reach the privileged
+// process, the stream is one way.
+func NewCompanion(options *Options) (*Companion, error) {
+	if len(options.Companion.Socket) == 0 {
+		return nil, fmt.Errorf("the companion stream is disabled, set companion.socket")
+	}
+	c := &Companion{
+		options: options,
+		noise:   newNoiseTracker(options.Noise),
+	}
+
+	var err error
+	if len(options.Output) > 0 {
+		if c.output, err = newFileSink(options.Output); err != nil {
+			return nil, err
+		}
+	}
+	// the storage of the privileged process may not be readable by the user of the companion
+	storage, err := openAnnotationStorage(options)
+	if err != nil {
+		logrus.Warnf("couldn't open the annotations storage: %v", err)
+	}
+	if c.annotations, err = newAnnotationStore(options, storage, c.recordAnnotation); err != nil {
+		logrus.Warnf("%v, starting without the previous annotations", err)
+		if c.annotations, err = newAnnotationStore(options, nil, c.recordAnnotation); err != nil {
+			return nil, err
+		}
+	}
+	if len(options.API.Listen) > 0 {
+		if c.token, err = readAPIToken(options.API.TokenFile); err != nil {
+			return nil, err
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/v1/noise", c.authenticate(c.handleNoise))
+		mux.HandleFunc("/v1/annotations", c.authenticate(func(w http.ResponseWriter, req *http.Request) {
+			serveAnnotations(w, req, c.annotations)
+		}))
+		mux.HandleFunc("/v1/annotations/", c.authenticate(func(w http.ResponseWriter, req *http.Request) {
+			serveAnnotation(w, req, c.annotations)
+		}))
+		c.server = &http.Server{
+			Addr:              options.API.Listen,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+	}
+	return c, nil
+}
+
+// authenticate checks the bearer token of the requests, with the token of the API section
+func (c *Companion) authenticate(handler http.HandlerFunc) http.HandlerFunc {
+	return (&apiServer{token: c.token}).authenticate(handler)
+}
+
+// handleNoise returns the noise report of the events received since the companion started
+func (c *Companion) handleNoise(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		return
+	}
+	c.noise.lock.Lock()
+	report := c.noise.counter.report(c.options.Noise, c.options.Events)
+	c.noise.lock.Unlock()
+	report.Annotations = c.annotations.summary()
+	writeAPIResponse(w, http.StatusOK, report)
+}
+
+// Run reads the stream of the privileged process until the context is cancelled, the companion reconnects when the
+// privileged process restarts
+func (c *Companion) Run(ctx context.Context) error {
+	if c.server != nil {
+		listener, err := net.Listen("tcp", c.server.Addr)
+		if err != nil {
+			return fmt.Errorf("couldn't start the API: %w", err)
+		}
+		go func() {
+			if err := c.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logrus.Errorf("API stopped: %v", err)
+			}
+		}()
+		logrus.Infof("API listening on %s", c.server.Addr)
+	}
+	defer c.close()
+
+	for {
+		if err := c.consume(ctx); err != nil {
+			logrus.Warnf("companion stream unavailable: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(companionRetryInterval):
+		}
+	}
+}
+
+// consume reads the events of the stream until the privileged process disconnects, or the context is cancelled
+func (c *Companion) consume(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", c.options.Companion.Socket)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("connected to %s", c.options.Companion.Socket)
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		c.handleEvent(scanner.Bytes())
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("stream closed by the privileged process")
+}
+
+// handleEvent counts a streamed event and writes it to the output file, the annotation events are indexed
+func (c *Companion) handleEvent(data []byte) {
+	var serializer events.EventSerializer
+	if err := events.UnmarshalEventSerializer(data, &serializer); err != nil {
+		logrus.Debugf("couldn't decode streamed event: %v", err)
+		return
+	}
+	if annotation := serializedAnnotation(&serializer); annotation != nil {
+		c.annotations.index(annotation)
+	} else {
+		ruleID, comm, at := serializedNoiseKey(&serializer)
+		c.noise.lock.Lock()
+		c.noise.counter.add(ruleID, comm, at)
+		c.noise.lock.Unlock()
+	}
+
+	if err := c.write(data); err != nil {
+		logrus.Errorf("couldn't write event: %v", err)
+	}
+}
+
+// write appends an event to the output file, if any
+func (c *Companion) write(data []byte) error {
+	if c.output == nil {
+		return nil
+	}
+	line := make([]byte, 0, len(data)+1)
+	line = append(append(line, data...), '\n')
+	return c.output.write(&queuedEvent{data: line})
+}
+
+// recordAnnotation writes the annotation event of an annotation recorded by the companion to its output file
+func (c *Companion) recordAnnotation(annotation *Annotation) error {
+	event := newAnnotationEvent(annotation)
+	event.ID = events.NewEventID()
+	event.Kernel.Time = annotation.CreatedAt
+	data, err := event.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return c.write(data)
+}
+
+func (c *Companion) close() {
+	if c.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = c.server.Shutdown(ctx)
+	}
+	if c.output != nil {
+		_ = c.output.close()
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CompanionSinkName is the name of the sink streaming the events to the companions
+const CompanionSinkName = "companion"
+
+// CompanionOptions configures the stream of events consumed by the unprivileged companions (see "krie companion"): the
+// companions host the API and report features, so that the privileged process only loads the eBPF programs and reads
+// the kernel events
+type CompanionOptions struct {
+	// Socket is the unix socket streaming the events, leave empty to disable the stream
+	Socket string `yaml:"socket"`
+	// Group is the group allowed to connect to the socket, leave empty to restrict it to root
+	Group string `yaml:"group"`
+	// ClientBuffer is the number of events buffered for each companion, the events are dropped for the slow companions
+	ClientBuffer int `yaml:"client_buffer"`
+}
+
+// IsValid returns an error if the companion options are invalid
+func (o *CompanionOptions) IsValid() error {
+	if len(o.Socket) == 0 {
+		return nil
+	}
+	if o.ClientBuffer <= 0 {
+		return fmt.Errorf("invalid client_buffer: %d", o.ClientBuffer)
+	}
+	if len(o.Group) > 0 {
+		if _, err := user.LookupGroup(o.Group); err != nil {
+			return fmt.Errorf("invalid group: %w", err)
+		}
+	}
+	return nil
+}
+
+// NewCompanionOptions returns a default set of companion options
+func NewCompanionOptions() *CompanionOptions {
+	return &CompanionOptions{
+		ClientBuffer: 1000,
+	}
+}
+
+// companionClient is a companion connected to the stream
+type companionClient struct {
+	conn    net.Conn
+	events  chan []byte
+	dropped uint64
+}
+
+// companionStream is the sink streaming the events, as JSON lines, to the companions connected to its socket
+type companionStream struct {
+	options  *CompanionOptions
+	listener net.Listener
+
+	lock    sync.Mutex
+	clients map[*companionClient]bool
+	wg      sync.WaitGroup
+}
+
+func newCompanionStream(options *CompanionOptions) *companionStream {
+	return &companionStream{
+		options: options,
+		clients: make(map[*companionClient]bool),
+	}
+}
+
+func (cs *companionStream) start() error {
+	// remove the socket of a previous run
+	_ = os.Remove(cs.options.Socket)
+	listener, err := net.Listen("unix", cs.options.Socket)
+	if err != nil {
+		return fmt.Errorf("couldn't start the companion stream: %w", err)
+	}
+	mode := os.FileMode(0600)
+	if len(cs.options.Group) > 0 {
+		group, err := user.LookupGroup(cs.options.Group)
+		if err != nil {
+			_ = listener.Close()
+			return fmt.Errorf("couldn't start the companion stream: %w", err)
+		}
+		gid, _ := strconv.Atoi(group.Gid)
+		if err = os.Chown(cs.options.Socket, 0, gid); err != nil {
+			_ = listener.Close()
+			return fmt.Errorf("couldn't start the companion stream: %w", err)
+		}
+		mode = 0660
+	}
+	if err = os.Chmod(cs.options.Socket, mode); err != nil {
+		_ = listener.Close()
+		return fmt.Errorf("couldn't start the companion stream: %w", err)
+	}
+	cs.listener = listener
+
+	cs.wg.Add(1)
+	go cs.accept()
+	return nil
+}
+
+func (cs *companionStream) accept() {
+	defer cs.wg.Done()
+	for {
+		conn, err := cs.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				logrus.Errorf("companion stream stopped: %v", err)
+			}
+			return
+		}
+		client := &companionClient{
+			conn:   conn,
+			events: make(chan []byte, cs.options.ClientBuffer),
+		}
+		cs.lock.Lock()
+		cs.clients[client] = true
+		cs.lock.Unlock()
+		logrus.Infof("companion connected to %s", cs.options.Socket)
+
+		cs.wg.Add(1)
+		go cs.serve(client)
+	}
+}
+
+// serve writes the events to a companion until it disconnects
+func (cs *companionStream) serve(client *companionClient) {
+	defer cs.wg.Done()
+	for data := range client.events {
+		if _, err := client.conn.Write(data); err != nil {
+			logrus.Infof("companion disconnected: %v", err)
+			break
+		}
+	}
+	cs.lock.Lock()
+	delete(cs.clients, client)
+	cs.lock.Unlock()
+	_ = client.conn.Close()
+	// drain the events queued in the meantime
+	for range client.events {
+	}
+}
+
+func (cs *companionStream) write(evt *queuedEvent) error {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	for client := range cs.clients {
+		select {
+		case client.events <- evt.data:
+		default:
+			client.dropped++
+			if client.dropped == 1 || client.dropped%1000 == 0 {
+				logrus.Warnf("companion too slow, %d event(s) dropped", client.dropped)
+			}
+		}
+	}
+	return nil
+}
+
+func (cs *companionStream) close() error {
+	if cs.listener == nil {
+		return nil
+	}
+	err := cs.listener.Close()
+	cs.lock.Lock()
+	for client := range cs.clients {
+		close(client.events)
+		delete(cs.clients, client)
+	}
+	cs.lock.Unlock()
+	cs.wg.Wait()
+	_ = os.Remove(cs.options.Socket)
+	return err
+}
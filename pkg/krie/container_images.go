@@ -0,0 +1,61 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// checkContainerImage applies the container image policy to an init_module event: the module loads of the containers
+// which don't run an allowed image digest are flagged, and unloaded in block mode
+func (e *KRIE) checkContainerImage(event *events.Event, policies *events.Options) {
+	imagePolicy := policies.ContainerImages
+	if imagePolicy.Action == events.NopAction || !event.HasProcessContext() {
+		return
+	}
+	id := event.Process.ContainerID()
+	if len(id) == 0 {
+		// the module loads of the host processes aren't affected
+		return
+	}
+
+	// the verdict can't wait for a deferred container enrichment, the resolver caches the lookup
+	if event.Process.Container == nil && e.criResolver != nil {
+		event.Process.Container = e.criResolver.resolve(id)
+	}
+
+	// a container whose image digest can't be resolved is denied
+	var digest string
+	if event.Process.Container != nil {
+		digest = event.Process.Container.ImageDigest
+	}
+	if len(digest) > 0 && imagePolicy.IsAllowed(digest) {
+		event.InitModule.ImagePolicy = events.ImagePolicyAllowed
+		return
+	}
+
+	event.InitModule.ImagePolicy = events.ImagePolicyDenied
+	logrus.Warnf("module %s was loaded from container %s which doesn't run an allowed image (digest %q)", event.InitModule.Name, id, digest)
+	if imagePolicy.Action < events.BlockAction || policies.IsMonitored(fmt.Sprintf("%s/%s", events.InitModuleEventType, events.UnallowedImageRule)) {
+		return
+	}
+	unloadModule(event)
+}
@@ -0,0 +1,109 @@
+//go:build !krie_minimal
+
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+var (
+	allowedDigest = "sha256:" + strings.Repeat("a", 64)
+	deniedDigest  = "sha256:" + strings.Repeat("b", 64)
+)
+
+func newTestImagePolicyKRIE(containers map[string]string) *KRIE {
+	resolver := newCRIResolver(NewCRIOptions())
+	for id, digest := range containers {
+		resolver.cache[id] = &criCacheEntry{
+			container: &events.ContainerContext{ID: id, ImageDigest: digest},
+			expiresAt: time.Now().Add(time.Hour),
+		}
+	}
+	return &KRIE{criResolver: resolver}
+}
+
+func newTestModuleEvent(containerID string) *events.Event {
+	event := events.NewEvent()
+	event.Kernel.Type = events.InitModuleEventType
+	event.InitModule.Name = "test_module"
+	event.Process.Cgroups[0].Name = "/kubepods/" + containerID
+	return event
+}
+
+func TestCheckContainerImage(t *testing.T) {
+	allowed, denied, unknown := strings.Repeat("1", 64), strings.Repeat("2", 64), strings.Repeat("3", 64)
+	e := newTestImagePolicyKRIE(map[string]string{allowed: allowedDigest, denied: deniedDigest, unknown: ""})
+
+	policies := events.NewEventsOptions()
+	policies.ContainerImages.Action = events.LogAction
+	policies.ContainerImages.Digests = []string{allowedDigest}
+	assert.NoError(t, policies.ContainerImages.IsValid())
+
+	for id, expected := range map[string]string{allowed: events.ImagePolicyAllowed, denied: events.ImagePolicyDenied, unknown: events.ImagePolicyDenied, "": ""} {
+		event := newTestModuleEvent(id)
+		e.checkContainerImage(event, policies)
+		assert.Equal(t, expected, event.InitModule.ImagePolicy, id)
+		assert.False(t, event.InitModule.Unloaded)
+	}
+
+	event := newTestModuleEvent(denied)
+	e.checkContainerImage(event, policies)
+	assert.Equal(t, "init_module/unallowed_image", policies.RuleID(event))
+	assert.Equal(t, events.HighConfidence, policies.Confidence(event))
+}
+
+func TestContainerImageOptions(t *testing.T) {
+	options := &events.ContainerImageOptions{Action: events.BlockAction}
+	assert.Error(t, options.IsValid())
+	options.Digests = []string{"sha256:abc"}
+	assert.Error(t, options.IsValid())
+	options.Digests = []string{strings.ToUpper(allowedDigest[7:])}
+	assert.Error(t, options.IsValid())
+	options.Digests = []string{allowedDigest}
+	assert.NoError(t, options.IsValid())
+	assert.True(t, options.IsAllowed(strings.ToUpper(allowedDigest)))
+	assert.False(t, options.IsAllowed(deniedDigest))
+}
+
+func TestCheckCRICtl(t *testing.T) {
+	assert.Error(t, checkCRICtl("crictl"))
+
+	path := filepath.Join(t.TempDir(), "crictl")
+	assert.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), 0755))
+	assert.NoError(t, os.Chmod(path, 0777))
+	err := checkCRICtl(path)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "writable")
+	}
+	assert.NoError(t, os.Chmod(path, 0755))
+	if os.Getuid() == 0 {
+		assert.NoError(t, checkCRICtl(path))
+	} else {
+		assert.Error(t, checkCRICtl(path))
+	}
+	assert.Error(t, checkCRICtl(filepath.Dir(path)))
+}
@@ -0,0 +1,313 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cilium/ebpf"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	syscallOrphansMetricName = "krie_syscall_orphans_total"
+	syscallOrphansMetricHelp = "Syscall entries and exits which couldn't be correlated, by event type and kind"
+)
+
+// CorrelationOptions configures the correlation of the entries and the exits of the monitored syscalls. The events
+// assembled from an entry and an exit are lost when one of them is missed (nested entries, a syscall cache full under
+// heavy load, an exit missed by a kretprobe): the orphans are counted and reported instead of being dropped silently.
+type CorrelationOptions struct {
+	// Timeout is the number of seconds after which an entry without exit is an orphan, 0 disables the expiration
+	Timeout int64 `yaml:"timeout"`
+	// Interval is the number of seconds between two reports of the orphans, 0 disables the reports
+	Interval int64 `yaml:"interval"`
+	// Samples is the maximum number of orphans sampled per event type in a report
+	Samples int `yaml:"samples"`
+}
+
+// IsValid returns an error if the correlation options are invalid
+func (o *CorrelationOptions) IsValid() error {
+	if o.Timeout < 0 {
+		return fmt.Errorf("invalid timeout: %d", o.Timeout)
+	}
+	if o.Interval < 0 {
+		return fmt.Errorf("invalid interval: %d", o.Interval)
+	}
+	if o.Samples < 0 {
+		return fmt.Errorf("invalid samples: %d", o.Samples)
+	}
+	return nil
+}
+
+// NewCorrelationOptions returns a default set of correlation options
+func NewCorrelationOptions() *CorrelationOptions {
+	return &CorrelationOptions{
+		Timeout:  10,
+		Interval: 60,
+		Samples:  5,
+	}
+}
+
+// timeoutConstant returns the value of the syscall_correlation_timeout constant of the eBPF programs
+func (o *CorrelationOptions) timeoutConstant() uint64 {
+	return uint64(o.Timeout * time.Second.Nanoseconds())
+}
+
+// syscallOrphanSample is the last orphan recorded by a CPU, see struct syscall_orphan_sample_t
+type syscallOrphanSample struct {
+	Timestamp uint64
+	Age       uint64
+	PIDTGID   uint64
+	Kind      uint32
+	Padding   uint32
+}
+
+// syscallOrphans are the orphan counters of an event type on a CPU, see struct syscall_orphans_t
+type syscallOrphans struct {
+	Overwritten    uint64
+	Expired        uint64
+	UnmatchedExits uint64
+	Last           syscallOrphanSample
+}
+
+// syscallOrphanKinds are the kinds of orphans, indexed by their SYSCALL_ORPHAN_* value
+var syscallOrphanKinds = map[uint32]string{
+	1: events.SyscallOrphanOverwritten,
+	2: events.SyscallOrphanExpired,
+	3: events.SyscallOrphanUnmatchedExit,
+}
+
+// syscallCacheHeaderLen is the length of the type and the timestamp at the start of a struct syscall_cache_t
+const syscallCacheHeaderLen = 16
+
+// correlationMonitor periodically reports the orphans counted by the eBPF programs, and sweeps the entries older than
+// the timeout from the syscall cache: the entries which lost their exit are only dropped by the kernel when the thread
+// enters a monitored syscall again, or by the eviction of the cache.
+type correlationMonitor struct {
+	options *CorrelationOptions
+	krie    *KRIE
+	cache   *ebpf.Map
+	orphans *ebpf.Map
+
+	// totals are the kernel counters at the previous report, lastSamples the time of the newest reported sample
+	totals      [events.MaxEventType]syscallOrphans
+	lastSamples [events.MaxEventType]uint64
+	lastReport  time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newCorrelationMonitor returns a correlation monitor, the manager must be started
+func newCorrelationMonitor(krie *KRIE, options *CorrelationOptions) *correlationMonitor {
+	cm := &correlationMonitor{
+		options:    options,
+		krie:       krie,
+		lastReport: time.Now(),
+		stop:       make(chan struct{}),
+	}
+	var err error
+	var found bool
+	if cm.cache, found, err = krie.manager.GetMap("syscalls"); err != nil || !found {
+		logrus.Debugf("couldn't find the syscall cache, the stale entries won't be swept: %v", err)
+		cm.cache = nil
+	}
+	if cm.orphans, found, err = krie.manager.GetMap("syscall_orphans"); err != nil || !found {
+		logrus.Debugf("couldn't find the syscall orphans counters: %v", err)
+		cm.orphans = nil
+	}
+	return cm
+}
+
+func (cm *correlationMonitor) start() {
+	cm.wg.Add(1)
+	go func() {
+		defer cm.wg.Done()
+		ticker := time.NewTicker(time.Duration(cm.options.Interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-cm.stop:
+				return
+			case <-ticker.C:
+				cm.report()
+			}
+		}
+	}()
+}
+
+func (cm *correlationMonitor) close() {
+	close(cm.stop)
+	cm.wg.Wait()
+}
+
+// monotonicNow returns the current time of the clock of bpf_ktime_get_ns
+func monotonicNow() uint64 {
+	var now unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &now); err != nil {
+		return 0
+	}
+	return uint64(now.Nano())
+}
+
+// report emits a syscall_orphans event per event type with orphans since the previous report
+func (cm *correlationMonitor) report() {
+	reports := make(map[events.EventType]*events.SyscallOrphansEvent)
+	reportOf := func(eventType events.EventType) *events.SyscallOrphansEvent {
+		report, ok := reports[eventType]
+		if !ok {
+			report = &events.SyscallOrphansEvent{
+				EventType: eventType.String(),
+				Since:     cm.lastReport,
+			}
+			reports[eventType] = report
+		}
+		return report
+	}
+	sample := func(report *events.SyscallOrphansEvent, kind string, pidTgid uint64, age uint64, at uint64) {
+		report.Samples = append(report.Samples, events.SyscallOrphanSample{
+			Kind: kind,
+			PID:  uint32(pidTgid >> 32),
+			TID:  uint32(pidTgid),
+			Age:  float64(age) / float64(time.Millisecond),
+			Time: cm.krie.timeResolver.ResolveMonotonicTimestamp(at),
+		})
+	}
+
+	cm.sweep(func(eventType events.EventType, pidTgid uint64, age uint64, at uint64) {
+		report := reportOf(eventType)
+		report.Expired++
+		sample(report, events.SyscallOrphanExpired, pidTgid, age, at)
+	})
+
+	if cm.orphans != nil {
+		for eventType := events.EventType(1); eventType < events.MaxEventType; eventType++ {
+			var perCPU []syscallOrphans
+			if err := cm.orphans.Lookup(uint32(eventType), &perCPU); err != nil {
+				continue
+			}
+			var total syscallOrphans
+			for _, cpu := range perCPU {
+				total.Overwritten += cpu.Overwritten
+				total.Expired += cpu.Expired
+				total.UnmatchedExits += cpu.UnmatchedExits
+			}
+			previous := cm.totals[eventType]
+			cm.totals[eventType] = total
+			if total.Overwritten == previous.Overwritten && total.Expired == previous.Expired && total.UnmatchedExits == previous.UnmatchedExits {
+				continue
+			}
+			report := reportOf(eventType)
+			report.Overwritten += total.Overwritten - previous.Overwritten
+			report.Expired += total.Expired - previous.Expired
+			report.UnmatchedExits += total.UnmatchedExits - previous.UnmatchedExits
+
+			// each CPU keeps its last orphan
+			newest := cm.lastSamples[eventType]
+			for _, cpu := range perCPU {
+				if cpu.Last.Timestamp <= cm.lastSamples[eventType] {
+					continue
+				}
+				sample(report, syscallOrphanKinds[cpu.Last.Kind], cpu.Last.PIDTGID, cpu.Last.Age, cpu.Last.Timestamp)
+				if cpu.Last.Timestamp > newest {
+					newest = cpu.Last.Timestamp
+				}
+			}
+			cm.lastSamples[eventType] = newest
+		}
+	}
+	cm.lastReport = time.Now()
+
+	eventTypes := make([]events.EventType, 0, len(reports))
+	for eventType := range reports {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Slice(eventTypes, func(i, j int) bool {
+		return eventTypes[i] < eventTypes[j]
+	})
+	for _, eventType := range eventTypes {
+		report := reports[eventType]
+		sort.Slice(report.Samples, func(i, j int) bool {
+			return report.Samples[i].Time.After(report.Samples[j].Time)
+		})
+		if len(report.Samples) > cm.options.Samples {
+			report.Samples = report.Samples[:cm.options.Samples]
+		}
+		for kind, count := range map[string]uint64{
+			events.SyscallOrphanOverwritten:   report.Overwritten,
+			events.SyscallOrphanExpired:       report.Expired,
+			events.SyscallOrphanUnmatchedExit: report.UnmatchedExits,
+		} {
+			if count > 0 {
+				cm.krie.metrics.add(syscallOrphansMetricName, syscallOrphansMetricHelp, count, "event_type", report.EventType, "kind", kind)
+			}
+		}
+
+		logrus.Debugf("%s: %d overwritten, %d expired, %d unmatched exits since %s", report.EventType, report.Overwritten, report.Expired, report.UnmatchedExits, report.Since.Format(time.RFC3339))
+		event := events.NewEvent()
+		event.Kernel.Type = events.SyscallOrphansEventType
+		event.SyscallOrphansEvent = *report
+		if err := cm.krie.emitEvent(event); err != nil {
+			logrus.Errorf("couldn't emit syscall_orphans event: %v", err)
+		}
+	}
+}
+
+// sweep deletes the entries of the syscall cache older than the timeout
+func (cm *correlationMonitor) sweep(expired func(eventType events.EventType, pidTgid uint64, age uint64, at uint64)) {
+	if cm.cache == nil || cm.options.Timeout == 0 {
+		return
+	}
+	now := monotonicNow()
+	timeout := cm.options.timeoutConstant()
+	if now == 0 {
+		return
+	}
+
+	stale := make(map[uint64]uint64)
+	var pidTgid uint64
+	value := make([]byte, cm.cache.ValueSize())
+	iterator := cm.cache.Iterate()
+	for iterator.Next(&pidTgid, &value) {
+		if len(value) < syscallCacheHeaderLen {
+			continue
+		}
+		timestamp := events.ByteOrder.Uint64(value[8:16])
+		if timestamp == 0 || now < timestamp || now-timestamp <= timeout {
+			continue
+		}
+		stale[pidTgid] = timestamp
+		expired(events.EventType(events.ByteOrder.Uint64(value[0:8])), pidTgid, now-timestamp, now)
+	}
+	if err := iterator.Err(); err != nil {
+		logrus.Debugf("couldn't iterate over the syscall cache: %v", err)
+	}
+	for key, timestamp := range stale {
+		// the thread may have replaced the entry in the meantime
+		if err := cm.cache.Lookup(key, &value); err != nil || events.ByteOrder.Uint64(value[8:16]) != timestamp {
+			continue
+		}
+		_ = cm.cache.Delete(key)
+	}
+}
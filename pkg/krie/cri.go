@@ -0,0 +1,86 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// CRIOptions configures the container enrichment of the events
+type CRIOptions struct {
+	Enabled         bool   `yaml:"enabled"`
+	RuntimeEndpoint string `yaml:"runtime_endpoint"`
+	// CRICtl is the absolute path to the crictl binary. crictl runs with the privileges of KRIE: the binary isn't looked
+	// up in $PATH, and has to be owned by root and not writable by the other users.
+	CRICtl   string `yaml:"crictl"`
+	Timeout  int64  `yaml:"timeout"`
+	CacheTTL int64  `yaml:"cache_ttl"`
+}
+
+// IsValid returns an error if the CRI options are invalid
+func (o *CRIOptions) IsValid() error {
+	if !o.Enabled {
+		return nil
+	}
+	if len(o.RuntimeEndpoint) == 0 {
+		return fmt.Errorf("empty runtime_endpoint")
+	}
+	if err := checkCRICtl(o.CRICtl); err != nil {
+		return fmt.Errorf("invalid crictl: %w", err)
+	}
+	if o.Timeout <= 0 {
+		return fmt.Errorf("invalid timeout: %d", o.Timeout)
+	}
+	if o.CacheTTL <= 0 {
+		return fmt.Errorf("invalid cache_ttl: %d", o.CacheTTL)
+	}
+	return nil
+}
+
+// checkCRICtl returns an error if the provided crictl binary could be replaced by an unprivileged user
+func checkCRICtl(path string) error {
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("%q isn't an absolute path", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s isn't a regular file", path)
+	}
+	if info.Mode().Perm()&0022 != 0 {
+		return fmt.Errorf("%s is writable by the group or the other users", path)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && stat.Uid != 0 {
+		return fmt.Errorf("%s isn't owned by root", path)
+	}
+	return nil
+}
+
+// NewCRIOptions returns a default set of CRI options
+func NewCRIOptions() *CRIOptions {
+	return &CRIOptions{
+		RuntimeEndpoint: "unix:///run/containerd/containerd.sock",
+		CRICtl:          "/usr/local/bin/crictl",
+		Timeout:         2,
+		CacheTTL:        300,
+	}
+}
@@ -0,0 +1,171 @@
+//go:build !krie_minimal
+
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// crictlInspect is used to parse the output of "crictl inspect"
+type crictlInspect struct {
+	Status struct {
+		Image struct {
+			Image string `json:"image"`
+		} `json:"image"`
+		ImageRef string `json:"imageRef"`
+	} `json:"status"`
+	Info struct {
+		SandboxID string `json:"sandboxID"`
+	} `json:"info"`
+}
+
+// crictlInspectPod is used to parse the output of "crictl inspectp"
+type crictlInspectPod struct {
+	Status struct {
+		// RuntimeHandler is the handler of the RuntimeClass of the pod, empty for the default runtime
+		RuntimeHandler string `json:"runtimeHandler"`
+	} `json:"status"`
+}
+
+type criCacheEntry struct {
+	container *events.ContainerContext
+	expiresAt time.Time
+}
+
+// criLookup is a lookup in progress, shared by the concurrent cache misses of a container ID
+type criLookup struct {
+	done      chan struct{}
+	container *events.ContainerContext
+}
+
+// criResolver resolves container IDs to their image and runtime, using the CRI socket of the container runtime
+// (containerd, CRI-O) through crictl.
+type criResolver struct {
+	sync.Mutex
+	options  *CRIOptions
+	cache    map[string]*criCacheEntry
+	inflight map[string]*criLookup
+}
+
+func newCRIResolver(options *CRIOptions) *criResolver {
+	return &criResolver{
+		options:  options,
+		cache:    make(map[string]*criCacheEntry),
+		inflight: make(map[string]*criLookup),
+	}
+}
+
+// resolve returns the container context of the provided container ID. Failed lookups are cached too, so that a
+// container unknown to the CRI (for example a plain docker container) isn't queried for every event. The concurrent
+// cache misses of a container ID wait for the same lookup.
+func (r *criResolver) resolve(id string) *events.ContainerContext {
+	now := time.Now()
+
+	r.Lock()
+	if entry, ok := r.cache[id]; ok && now.Before(entry.expiresAt) {
+		r.Unlock()
+		return entry.container
+	}
+	if lookup, ok := r.inflight[id]; ok {
+		r.Unlock()
+		<-lookup.done
+		return lookup.container
+	}
+	lookup := &criLookup{done: make(chan struct{})}
+	r.inflight[id] = lookup
+	r.Unlock()
+
+	container, err := r.inspect(id)
+	if err != nil {
+		logrus.Debugf("couldn't resolve container %s: %v", id, err)
+		container = &events.ContainerContext{ID: id}
+	}
+	lookup.container = container
+	close(lookup.done)
+
+	r.Lock()
+	defer r.Unlock()
+	delete(r.inflight, id)
+	// evict expired entries
+	for key, value := range r.cache {
+		if now.After(value.expiresAt) {
+			delete(r.cache, key)
+		}
+	}
+	r.cache[id] = &criCacheEntry{
+		container: container,
+		expiresAt: now.Add(time.Duration(r.options.CacheTTL) * time.Second),
+	}
+	return container
+}
+
+// crictl runs crictl with the provided command and ID, and parses its JSON output
+func (r *criResolver) crictl(command string, id string, out interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.options.Timeout)*time.Second)
+	defer cancel()
+
+	// crictl doesn't inherit the environment of KRIE: its configuration is the command line
+	cmd := exec.CommandContext(ctx, r.options.CRICtl, "--runtime-endpoint", r.options.RuntimeEndpoint, command, "-o", "json", id)
+	cmd.Env = []string{}
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("crictl %s failed: %w", command, err)
+	}
+	if err = json.Unmarshal(output, out); err != nil {
+		return fmt.Errorf("couldn't parse crictl %s output: %w", command, err)
+	}
+	return nil
+}
+
+func (r *criResolver) inspect(id string) (*events.ContainerContext, error) {
+	var inspect crictlInspect
+	if err := r.crictl("inspect", id, &inspect); err != nil {
+		return nil, err
+	}
+
+	container := &events.ContainerContext{
+		ID:    id,
+		Image: inspect.Status.Image.Image,
+	}
+	// the runtime class is a property of the pod sandbox of the container
+	if len(inspect.Info.SandboxID) > 0 {
+		var pod crictlInspectPod
+		if err := r.crictl("inspectp", inspect.Info.SandboxID, &pod); err != nil {
+			logrus.Debugf("couldn't resolve the pod sandbox of container %s: %v", id, err)
+		} else {
+			container.RuntimeClass = pod.Status.RuntimeHandler
+		}
+	}
+	if i := strings.LastIndex(inspect.Status.ImageRef, "@"); i >= 0 {
+		container.ImageDigest = inspect.Status.ImageRef[i+1:]
+	} else if strings.HasPrefix(inspect.Status.ImageRef, "sha256:") {
+		container.ImageDigest = inspect.Status.ImageRef
+	}
+	return container, nil
+}
@@ -0,0 +1,74 @@
+//go:build !krie_minimal
+
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// fakeCRICtl is a crictl that logs its calls and answers slowly, so that the lookups of the test overlap
+const fakeCRICtl = `#!/bin/sh
+echo "$3 $6" >> %s
+/bin/sleep 0.2
+case "$3" in
+inspect) echo '{"status":{"image":{"image":"nginx:1.25"},"imageRef":"docker.io/library/nginx@%s"},"info":{"sandboxID":"pod","runtimeType":"io.containerd.runc.v2"}}' ;;
+inspectp) echo '{"status":{"runtimeHandler":"kata"}}' ;;
+esac
+`
+
+func TestCRIResolverSharedLookup(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	options := NewCRIOptions()
+	options.CRICtl = filepath.Join(dir, "crictl")
+	assert.NoError(t, os.WriteFile(options.CRICtl, []byte(fmt.Sprintf(fakeCRICtl, calls, allowedDigest)), 0700))
+	resolver := newCRIResolver(options)
+
+	id := strings.Repeat("1", 64)
+	var wg sync.WaitGroup
+	containers := make([]*events.ContainerContext, 8)
+	for i := range containers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			containers[i] = resolver.resolve(id)
+		}(i)
+	}
+	wg.Wait()
+
+	expected := &events.ContainerContext{ID: id, Image: "nginx:1.25", ImageDigest: allowedDigest, RuntimeClass: "kata"}
+	for _, container := range containers {
+		assert.Equal(t, expected, container)
+	}
+
+	// one lookup of the container and of its pod sandbox, whatever the number of concurrent misses
+	data, err := os.ReadFile(calls)
+	assert.NoError(t, err)
+	assert.Equal(t, "inspect "+id+"\ninspectp pod\n", string(data))
+	assert.Empty(t, resolver.inflight)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	decodeErrorsMetricName = "krie_decode_errors_total"
+	decodeErrorsMetricHelp = "Events sent by the kernel that couldn't be decoded"
+)
+
+// DecodingMode defines what KRIE does with the events that can't be decoded
+type DecodingMode string
+
+const (
+	// StrictDecoding drops the events that can't be decoded, with an error
+	StrictDecoding DecodingMode = "strict"
+	// LenientDecoding emits the events that can't be decoded as raw events, so that a version skew between the eBPF
+	// programs and user space doesn't lose data
+	LenientDecoding DecodingMode = "lenient"
+)
+
+// UnmarshalYAML parses a decoding mode
+func (dm *DecodingMode) UnmarshalYAML(value *yaml.Node) error {
+	var mode string
+	if err := value.Decode(&mode); err != nil {
+		return fmt.Errorf("failed to parse decoding mode: %w", err)
+	}
+	switch DecodingMode(mode) {
+	case StrictDecoding, LenientDecoding:
+		*dm = DecodingMode(mode)
+	case "":
+		*dm = StrictDecoding
+	default:
+		return fmt.Errorf("unknown decoding mode: %s", mode)
+	}
+	return nil
+}
+
+// toRawEvent turns an event that couldn't be decoded into a raw event
+func toRawEvent(event *events.Event, data []byte, decodingErr error) {
+	typeID := uint32(event.Kernel.Type)
+	if len(data) >= 24 {
+		typeID = events.ByteOrder.Uint32(data[20:24])
+	}
+	if event.Kernel.Time.IsZero() {
+		event.Kernel.Time = time.Now()
+	}
+	event.Kernel.Type = events.RawEventType
+	event.RawEvent = events.RawEvent{
+		TypeID:  typeID,
+		Type:    events.EventType(typeID).String(),
+		Payload: hex.EncodeToString(data),
+		Size:    len(data),
+		Error:   decodingErr.Error(),
+	}
+}
@@ -0,0 +1,348 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// Enrichment is an expensive enrichment of the events, which can be deferred out of the hot path
+type Enrichment string
+
+const (
+	// ContainerEnrichment resolves the container of the process through the CRI (see the cri section)
+	ContainerEnrichment Enrichment = "container"
+	// SymbolsEnrichment resolves the kernel symbols of the hooked_syscall, hooked_syscall_table, kernel_parameter and
+	// ftrace_hook events
+	SymbolsEnrichment Enrichment = "symbols"
+	// ModuleInfoEnrichment hashes and inspects the file of the loaded modules
+	ModuleInfoEnrichment Enrichment = "module_info"
+)
+
+// EnrichmentMode is how the result of the deferred enrichments is delivered
+type EnrichmentMode string
+
+const (
+	// DelayedEnrichment holds the events until their deferred enrichments complete, and sends the complete events
+	DelayedEnrichment EnrichmentMode = "delay"
+	// FollowUpEnrichment sends the events right away, then the result of their deferred enrichments in an enrichment
+	// event keyed by event ID
+	FollowUpEnrichment EnrichmentMode = "follow_up"
+)
+
+// EnrichmentOptions configures the deferred enrichments
+type EnrichmentOptions struct {
+	// Deferred lists the enrichments computed asynchronously
+	Deferred []Enrichment   `yaml:"deferred"`
+	Mode     EnrichmentMode `yaml:"mode"`
+	// Workers is the number of goroutines computing the deferred enrichments
+	Workers int `yaml:"workers"`
+	// QueueSize is the maximum number of events waiting for their deferred enrichments. When the queue is full, the
+	// enrichments of a delayed event are computed in the hot path, and the follow-up records are dropped.
+	QueueSize int `yaml:"queue_size"`
+
+	deferred map[Enrichment]bool
+}
+
+// IsValid returns an error if the enrichment options are invalid
+func (o *EnrichmentOptions) IsValid() error {
+	o.deferred = make(map[Enrichment]bool)
+	for _, enrichment := range o.Deferred {
+		switch enrichment {
+		case ContainerEnrichment, SymbolsEnrichment, ModuleInfoEnrichment:
+			o.deferred[enrichment] = true
+		default:
+			return fmt.Errorf("unknown enrichment: %s", enrichment)
+		}
+	}
+	if len(o.Deferred) == 0 {
+		return nil
+	}
+	if o.Mode != DelayedEnrichment && o.Mode != FollowUpEnrichment {
+		return fmt.Errorf("invalid mode: %s", o.Mode)
+	}
+	if o.Workers <= 0 {
+		return fmt.Errorf("invalid workers: %d", o.Workers)
+	}
+	if o.QueueSize <= 0 {
+		return fmt.Errorf("invalid queue_size: %d", o.QueueSize)
+	}
+	return nil
+}
+
+// NewEnrichmentOptions returns a default set of enrichment options
+func NewEnrichmentOptions() *EnrichmentOptions {
+	return &EnrichmentOptions{
+		Mode:      DelayedEnrichment,
+		Workers:   2,
+		QueueSize: 1024,
+	}
+}
+
+// deferredEvent is an event waiting for its deferred enrichments
+type deferredEvent struct {
+	event       *events.Event
+	enrichments []Enrichment
+	burstOnly   bool
+}
+
+// enricher computes the deferred enrichments
+type enricher struct {
+	krie    *KRIE
+	options *EnrichmentOptions
+
+	queue   chan *deferredEvent
+	dropped uint64
+	wg      sync.WaitGroup
+}
+
+func newEnricher(krie *KRIE, options *EnrichmentOptions) *enricher {
+	return &enricher{
+		krie:    krie,
+		options: options,
+		queue:   make(chan *deferredEvent, options.QueueSize),
+	}
+}
+
+func (en *enricher) start() {
+	for i := 0; i < en.options.Workers; i++ {
+		en.wg.Add(1)
+		go func() {
+			defer en.wg.Done()
+			for deferred := range en.queue {
+				en.complete(deferred)
+			}
+		}()
+	}
+}
+
+// close completes the queued events and stops the workers
+func (en *enricher) close() {
+	close(en.queue)
+	en.wg.Wait()
+	if dropped := atomic.LoadUint64(&en.dropped); dropped > 0 {
+		logrus.Warnf("%d enrichment record(s) dropped: queue full (queue_size: %d)", dropped, en.options.QueueSize)
+	}
+}
+
+// defers returns true if the provided enrichment is deferred, en can be nil when no enrichment is deferred
+func (en *enricher) defers(enrichment Enrichment, policies *events.Options) bool {
+	if en == nil || !en.options.deferred[enrichment] {
+		return false
+	}
+	// the verdict of the module signer policy sets the confidence of the event, it can't follow the event
+	if enrichment == ModuleInfoEnrichment && en.options.Mode == FollowUpEnrichment && policies.ModuleSigners.Action != events.NopAction {
+		return false
+	}
+	return true
+}
+
+// submit queues an event for its deferred enrichments. In delay mode, the event is dispatched by the enricher and
+// submit returns true. In follow up mode, submit returns false: the caller dispatches the event, and the enrichments
+// are computed on a copy of the event.
+func (en *enricher) submit(event *events.Event, enrichments []Enrichment, burstOnly bool) bool {
+	// the events are reused by the perf readers
+	copied := *event
+	deferred := &deferredEvent{
+		event:       &copied,
+		enrichments: enrichments,
+		burstOnly:   burstOnly,
+	}
+	select {
+	case en.queue <- deferred:
+		return en.options.Mode == DelayedEnrichment
+	default:
+	}
+	if en.options.Mode == FollowUpEnrichment {
+		atomic.AddUint64(&en.dropped, 1)
+		return false
+	}
+	// keep the event: complete it in the hot path
+	policies, _, _ := en.krie.currentPolicies()
+	for _, enrichment := range enrichments {
+		en.krie.runEnrichment(event, enrichment, policies)
+	}
+	return false
+}
+
+// complete computes the deferred enrichments of an event, and sends either the complete event or its follow-up record
+func (en *enricher) complete(deferred *deferredEvent) {
+	policies, _, _ := en.krie.currentPolicies()
+	event := deferred.event
+	for _, enrichment := range deferred.enrichments {
+		en.krie.runEnrichment(event, enrichment, policies)
+	}
+
+	if en.options.Mode == DelayedEnrichment {
+		// the enrichments may change the rule and the confidence of the event (module signer policy)
+		event.Policy.RuleID = policies.RuleID(event)
+		event.Policy.Mode = policies.RuleMode(event.Policy.RuleID)
+		event.Policy.Confidence = policies.Confidence(event)
+		if err := en.krie.finishEvent(event, deferred.burstOnly); err != nil {
+			logrus.Errorf("couldn't send %s event: %v", event.Kernel.Type, err)
+		}
+		return
+	}
+
+	record := events.NewEvent()
+	record.Kernel.Type = events.EnrichmentEventType
+	record.EnrichmentEvent = events.EnrichmentEvent{
+		EventID:   event.ID,
+		EventType: event.Kernel.Type.String(),
+		Fields:    make(map[string]string),
+	}
+	for _, enrichment := range deferred.enrichments {
+		record.EnrichmentEvent.Enrichments = append(record.EnrichmentEvent.Enrichments, string(enrichment))
+		enrichedFields(event, enrichment, record.EnrichmentEvent.Fields)
+	}
+	if err := en.krie.emitEvent(record); err != nil {
+		logrus.Errorf("couldn't send enrichment event: %v", err)
+	}
+}
+
+// enrich runs an enrichment on the event, or adds it to the deferred enrichments
+func (e *KRIE) enrich(event *events.Event, enrichment Enrichment, policies *events.Options, deferred []Enrichment) []Enrichment {
+	if e.enricher.defers(enrichment, policies) {
+		return append(deferred, enrichment)
+	}
+	e.runEnrichment(event, enrichment, policies)
+	return deferred
+}
+
+// runEnrichment runs an enrichment on the event
+func (e *KRIE) runEnrichment(event *events.Event, enrichment Enrichment, policies *events.Options) {
+	switch enrichment {
+	case ContainerEnrichment:
+		event.Process.Container = e.criResolver.resolve(event.Process.ContainerID())
+	case ModuleInfoEnrichment:
+		e.inspectModule(event, policies)
+	case SymbolsEnrichment:
+		e.resolveEventSymbols(event)
+	}
+}
+
+// resolveEventSymbols resolves the kernel symbols of an event
+func (e *KRIE) resolveEventSymbols(event *events.Event) {
+	switch event.Kernel.Type {
+	case events.FTraceHookEventType:
+		// fetch the owner of the callback and the traced function
+		if err := e.resolveFuncSymbol(&event.FTraceHookEvent.Callback); err != nil {
+			logrus.Error(err)
+		}
+		if event.FTraceHookEvent.Target.Address != 0 {
+			if err := e.resolveFuncSymbol(&event.FTraceHookEvent.Target); err != nil {
+				logrus.Error(err)
+			}
+		}
+	case events.NetfilterHookEventType:
+		// fetch the owner of the hook
+		if err := e.resolveFuncSymbol(&event.NetfilterHookEvent.Hook); err != nil {
+			logrus.Error(err)
+		}
+	case events.HookedSyscallEventType, events.HookedSyscallTableEventType:
+		// fetch symbol owners
+		if err := e.resolveFuncSymbol(&event.HookedSyscallEvent.InitialHandler); err != nil {
+			logrus.Error(err)
+		}
+		if err := e.resolveFuncSymbol(&event.HookedSyscallEvent.NewHandler); err != nil {
+			logrus.Error(err)
+		}
+	case events.KernelParameterEventType, events.PeriodicKernelParameterEventType:
+		// fetch symbol
+		if err := e.resolveObjectSymbol(&event.KernelParameterEvent.Parameter); err != nil {
+			logrus.Error(err)
+		}
+	case events.MSRWriteEventType:
+		// fetch the owner of the new syscall entry point
+		if event.MSRWriteEvent.Target != nil {
+			if err := e.resolveFuncSymbol(event.MSRWriteEvent.Target); err != nil {
+				logrus.Error(err)
+			}
+		}
+	case events.SymbolResolutionEventType:
+		// fetch the owner of the caller of kallsyms_lookup_name
+		if event.SymbolResolutionEvent.Caller != nil {
+			if err := e.resolveFuncSymbol(event.SymbolResolutionEvent.Caller); err != nil {
+				logrus.Error(err)
+			}
+		}
+	}
+}
+
+// enrichedFields adds the fields set by an enrichment to the fields of an enrichment event
+func enrichedFields(event *events.Event, enrichment Enrichment, fields map[string]string) {
+	addSymbol := func(prefix string, symbol events.KernelSymbol) {
+		fields[prefix+".symbol"] = symbol.Symbol
+		fields[prefix+".module"] = symbol.Module
+	}
+
+	switch enrichment {
+	case ContainerEnrichment:
+		if container := event.Process.Container; container != nil {
+			fields["process.container.id"] = container.ID
+			fields["process.container.image"] = container.Image
+			fields["process.container.image_digest"] = container.ImageDigest
+			fields["process.container.runtime_class"] = container.RuntimeClass
+		}
+	case SymbolsEnrichment:
+		switch event.Kernel.Type {
+		case events.FTraceHookEventType:
+			addSymbol("ftrace_hook.callback", event.FTraceHookEvent.Callback)
+			if event.FTraceHookEvent.Target.Address != 0 {
+				addSymbol("ftrace_hook.target", event.FTraceHookEvent.Target)
+			}
+		case events.NetfilterHookEventType:
+			addSymbol("netfilter_hook.hook", event.NetfilterHookEvent.Hook)
+		case events.HookedSyscallEventType, events.HookedSyscallTableEventType:
+			addSymbol("hooked_syscall.initial_handler", event.HookedSyscallEvent.InitialHandler)
+			addSymbol("hooked_syscall.new_handler", event.HookedSyscallEvent.NewHandler)
+		case events.KernelParameterEventType, events.PeriodicKernelParameterEventType:
+			addSymbol("kernel_parameter.parameter", event.KernelParameterEvent.Parameter)
+		case events.MSRWriteEventType:
+			if event.MSRWriteEvent.Target != nil {
+				addSymbol("msr_write.target", *event.MSRWriteEvent.Target)
+			}
+		case events.SymbolResolutionEventType:
+			if event.SymbolResolutionEvent.Caller != nil {
+				addSymbol("symbol_resolution.caller", *event.SymbolResolutionEvent.Caller)
+			}
+		}
+	case ModuleInfoEnrichment:
+		im := &event.InitModule
+		fields["init_module.path"] = im.Path
+		fields["init_module.sha256"] = im.SHA256
+		if info := im.ModInfo; info != nil {
+			fields["init_module.modinfo.path"] = info.Path
+			fields["init_module.modinfo.version"] = info.Version
+			fields["init_module.modinfo.license"] = info.License
+			fields["init_module.modinfo.vermagic"] = info.Vermagic
+			fields["init_module.modinfo.signer"] = info.Signer
+			fields["init_module.modinfo.error"] = info.Error
+		}
+	}
+	for key, value := range fields {
+		if len(value) == 0 {
+			delete(fields, key)
+		}
+	}
+}
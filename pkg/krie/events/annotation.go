@@ -0,0 +1,44 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+// AnnotationEvent is the event generated when an analyst annotates an event, the last annotation of an event replaces
+// the previous ones
+type AnnotationEvent struct {
+	EventID string `json:"event_id"`
+	Status  string `json:"status"`
+	// RuleID is the rule of the annotated event
+	RuleID   string `json:"rule_id,omitempty"`
+	TicketID string `json:"ticket_id,omitempty"`
+	Author   string `json:"author,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// AnnotationEventSerializer is used to serialize AnnotationEvent
+// easyjson:json
+type AnnotationEventSerializer struct {
+	*AnnotationEvent
+}
+
+// NewAnnotationEventSerializer returns a new instance of AnnotationEventSerializer
+func NewAnnotationEventSerializer(e *AnnotationEvent) *AnnotationEventSerializer {
+	return &AnnotationEventSerializer{
+		AnnotationEvent: e,
+	}
+}
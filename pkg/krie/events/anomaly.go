@@ -0,0 +1,107 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Anomaly is the rarity of an event on the host, computed by the anomaly detector from the frequencies of the
+// (process, event type, key fields) combinations it observed
+type Anomaly struct {
+	// Key is the combination of the event, the process and the key fields of the event type
+	Key string `json:"key"`
+	// Score is the surprisal of the combination, in bits: a combination seen once every 2^n events scores n
+	Score float64 `json:"score"`
+	// Entropy is the entropy of the observed combinations, in bits: the average score of an event on the host
+	Entropy float64 `json:"entropy"`
+	// Occurrences is the number of times the combination was observed, before this event
+	Occurrences uint64 `json:"occurrences"`
+	// Observations is the number of events observed by the detector, before this event
+	Observations uint64 `json:"observations"`
+	// Rare is set when the score is above the threshold of the detector
+	Rare bool `json:"rare,omitempty"`
+}
+
+// AnomalyKey returns the key of the event for the anomaly detector: the executable (or the comm) of the process, the
+// event type and the fields that characterize the events of this type
+func (e *Event) AnomalyKey() string {
+	process := e.Process.Exe
+	if len(process) == 0 {
+		process = e.Process.Comm
+	}
+
+	var fields []string
+	switch e.Kernel.Type {
+	case InitModuleEventType:
+		fields = []string{e.InitModule.Name}
+	case DeleteModuleEventType:
+		fields = []string{e.DeleteModule.Name}
+	case BPFEventType:
+		fields = []string{e.BPFEvent.Cmd.String(), e.BPFEvent.Program.Type.String()}
+	case PTraceEventType:
+		fields = []string{e.PTraceEvent.Request.String()}
+	case KProbeEventType:
+		fields = []string{e.KProbeEvent.Command.String(), e.KProbeEvent.Symbol}
+	case SysCtlEventType:
+		fields = []string{e.SysCtlEvent.Action.String(), e.SysCtlEvent.Name}
+	case IOUringEventType:
+		fields = []string{e.IOUringEvent.Syscall, e.IOUringEvent.Opcode}
+	case PerfEventOpenEventType:
+		fields = []string{e.PerfEventOpenEvent.Type}
+	case RawMemoryAccessEventType:
+		fields = []string{e.RawMemoryAccessEvent.Device, e.RawMemoryAccessEvent.Operation.String()}
+	case MSRWriteEventType:
+		fields = []string{fmt.Sprintf("0x%x", e.MSRWriteEvent.MSR)}
+	case ProcessVMEventType:
+		fields = []string{e.ProcessVMEvent.Operation.String()}
+	case MountEventType:
+		fields = []string{e.MountEvent.FSType.String()}
+	case PrctlEventType:
+		fields = []string{e.PrctlEvent.Operation.String()}
+	case CapsetEventType:
+		fields = e.CapsetEvent.Gained.Names()
+	case BPFMapTamperEventType:
+		fields = []string{e.BPFMapTamperEvent.Cmd.String(), e.BPFMapTamperEvent.Owner.Comm}
+	case KernelLogTamperEventType:
+		fields = []string{e.KernelLogTamperEvent.Operation.String()}
+	case KeyringEventType:
+		fields = []string{e.KeyringEvent.Syscall.String(), e.KeyringEvent.Type}
+		if e.KeyringEvent.Syscall == KeyringKeyctl {
+			fields = append(fields, e.KeyringEvent.Command.String())
+		}
+	case NamespaceEventType:
+		fields = append([]string{e.NamespaceEvent.Syscall.String()}, e.NamespaceEvent.Changed...)
+	case UserfaultfdEventType:
+		fields = append([]string{e.UserfaultfdEvent.Operation.String()}, e.UserfaultfdEvent.Flags...)
+	case ModuleSignatureEventType:
+		fields = []string{e.ModuleSignatureEvent.Kind.String(), e.ModuleSignatureEvent.Module, e.ModuleSignatureEvent.SigEnforce.String()}
+	case RebootEventType:
+		fields = []string{e.RebootEvent.Cmd.String()}
+	case BinfmtMiscEventType:
+		fields = []string{e.BinfmtMiscEvent.Name, e.BinfmtMiscEvent.Interpreter}
+	case NetfilterHookEventType:
+		fields = []string{e.NetfilterHookEvent.Family.String(), e.NetfilterHookEvent.HookName, e.NetfilterHookEvent.Hook.Module}
+	case TracefsProbeEventType:
+		fields = []string{e.TracefsProbeEvent.File}
+		for _, cmd := range e.TracefsProbeEvent.Commands {
+			fields = append(fields, cmd.Target)
+		}
+	}
+	return strings.Join(append([]string{process, e.Kernel.Type.String()}, fields...), "|")
+}
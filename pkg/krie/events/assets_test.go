@@ -0,0 +1,158 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"bytes"
+	"testing"
+
+	manager "github.com/DataDog/ebpf-manager"
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/btf"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Gui774ume/krie/pkg/assets"
+)
+
+// assetSpec is the collection spec of an embedded variant of the eBPF programs
+type assetSpec struct {
+	variant assets.Variant
+	spec    *ebpf.CollectionSpec
+}
+
+// loadAssetSpecs returns the collection spec of each embedded variant of the eBPF programs
+func loadAssetSpecs(t *testing.T) []assetSpec {
+	variants, err := assets.Variants()
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	var specs []assetSpec
+	for _, variant := range variants {
+		data, err := assets.Asset(variant.Asset)
+		if !assert.NoError(t, err, "variant %s", variant.Name) {
+			continue
+		}
+		spec, err := ebpf.LoadCollectionSpecFromReader(bytes.NewReader(data))
+		if assert.NoError(t, err, "variant %s", variant.Name) {
+			specs = append(specs, assetSpec{variant: variant, spec: spec})
+		}
+	}
+	return specs
+}
+
+// withSyscallPrefix expands the syscall probes for the syscall wrapper of the provided variant, on x86_64
+func withSyscallPrefix(variant assets.Variant, fn func()) {
+	arch, prefix, ia32Prefix := RuntimeArch, syscallPrefix, ia32SyscallPrefix
+	defer func() {
+		RuntimeArch, syscallPrefix, ia32SyscallPrefix = arch, prefix, ia32Prefix
+	}()
+	RuntimeArch = "x64"
+	if variant.SyscallWrapper {
+		syscallPrefix, ia32SyscallPrefix = "__x64_sys_", "__ia32_"
+	} else {
+		syscallPrefix, ia32SyscallPrefix = "sys_", "compat_"
+	}
+	fn()
+}
+
+// selectedProbes adds the programs of a probes selector to the provided set
+func selectedProbes(selector manager.ProbesSelector, out map[manager.ProbeIdentificationPair]bool) {
+	var selectors []manager.ProbesSelector
+	switch s := selector.(type) {
+	case *manager.ProbeSelector:
+		out[manager.ProbeIdentificationPair{EBPFSection: s.EBPFSection, EBPFFuncName: s.EBPFFuncName}] = true
+	case *manager.OneOf:
+		selectors = s.Selectors
+	case *manager.AllOf:
+		selectors = s.Selectors
+	case *manager.BestEffort:
+		selectors = s.Selectors
+	}
+	for _, s := range selectors {
+		selectedProbes(s, out)
+	}
+}
+
+// TestAssetsProvideProbes checks that the embedded object of each variant provides the programs and the maps
+// referenced by the probes, the selectors and the tail call routes of every event type: a stale asset only fails when
+// the probes are loaded
+func TestAssetsProvideProbes(t *testing.T) {
+	var all EventTypeList
+	for eventType := UnknownEventType + 1; eventType < MaxEventType; eventType++ {
+		all.Insert(eventType)
+	}
+
+	for _, asset := range loadAssetSpecs(t) {
+		programs := map[manager.ProbeIdentificationPair]bool{TaskIteratorProbe: true}
+		maps := map[string]bool{"events": true}
+		withSyscallPrefix(asset.variant, func() {
+			for _, probe := range AllProbes(all) {
+				programs[manager.ProbeIdentificationPair{EBPFSection: probe.EBPFSection, EBPFFuncName: probe.EBPFFuncName}] = true
+			}
+			for _, selector := range AllProbesSelectors(all) {
+				selectedProbes(selector, programs)
+			}
+			for _, route := range AllTailCallRoutes(all) {
+				programs[manager.ProbeIdentificationPair{EBPFSection: route.EBPFSection, EBPFFuncName: route.EBPFFuncName}] = true
+				maps[route.ProgArrayName] = true
+			}
+		})
+
+		for pair := range programs {
+			program, ok := asset.spec.Programs[pair.EBPFFuncName]
+			if assert.True(t, ok, "variant %s: missing program %s (%s)", asset.variant.Name, pair.EBPFFuncName, pair.EBPFSection) {
+				assert.Equal(t, pair.EBPFSection, program.SectionName, "variant %s: section of %s", asset.variant.Name, pair.EBPFFuncName)
+			}
+		}
+		for name := range maps {
+			assert.Contains(t, asset.spec.Maps, name, "variant %s: missing map", asset.variant.Name)
+		}
+	}
+}
+
+// TestAssetsProbeOrigin checks that the programs of each variant load the identifier written by ProbeOrigins, and that
+// the kernel_event_t struct of the variant carries it at the offset decoded by KernelEvent.UnmarshalBinary
+func TestAssetsProbeOrigin(t *testing.T) {
+	for _, asset := range loadAssetSpecs(t) {
+		var loaded bool
+		for _, program := range asset.spec.Programs {
+			if _, ok := program.Instructions.ReferenceOffsets()[ProbeOriginConstant]; ok {
+				loaded = true
+				break
+			}
+		}
+		assert.True(t, loaded, "variant %s: no program loads %s", asset.variant.Name, ProbeOriginConstant)
+
+		if !assert.NotNil(t, asset.spec.Types, "variant %s: missing BTF", asset.variant.Name) {
+			continue
+		}
+		var kernelEvent *btf.Struct
+		if !assert.NoError(t, asset.spec.Types.TypeByName("kernel_event_t", &kernelEvent), "variant %s", asset.variant.Name) {
+			continue
+		}
+		var origin *btf.Member
+		for i := range kernelEvent.Members {
+			if kernelEvent.Members[i].Name == "origin" {
+				origin = &kernelEvent.Members[i]
+			}
+		}
+		if assert.NotNil(t, origin, "variant %s: kernel_event_t has no origin field", asset.variant.Name) {
+			assert.Equal(t, btf.Bits(28*8), origin.Offset, "variant %s: offset of kernel_event_t.origin", asset.variant.Name)
+			assert.Equal(t, uint32(32), kernelEvent.Size, "variant %s: size of kernel_event_t", asset.variant.Name)
+		}
+	}
+}
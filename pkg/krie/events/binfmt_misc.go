@@ -0,0 +1,205 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	// binfmtMiscDefinitionLen is the maximum length of the definition captured by a binfmt_misc event
+	binfmtMiscDefinitionLen = 256
+	// binfmtMiscEventLen is the length of the binary representation of a binfmt_misc event
+	binfmtMiscEventLen = 8 + binfmtMiscDefinitionLen
+)
+
+func binfmtMiscProbes() []manager.ProbeIdentificationPair {
+	return []manager.ProbeIdentificationPair{
+		{UID: KRIEUID, EBPFSection: "kprobe/bm_register_write", EBPFFuncName: "kprobe_bm_register_write"},
+	}
+}
+
+func addBinfmtMiscProbes(all *[]*manager.Probe) {
+	for _, id := range binfmtMiscProbes() {
+		*all = append(*all, &manager.Probe{ProbeIdentificationPair: id})
+	}
+}
+
+func addBinfmtMiscSelectors(all *[]manager.ProbesSelector) {
+	// binfmt_misc is usually a module (CONFIG_BINFMT_MISC=m), it may not be loaded yet
+	var selectors []manager.ProbesSelector
+	for _, id := range binfmtMiscProbes() {
+		selectors = append(selectors, &manager.ProbeSelector{ProbeIdentificationPair: id})
+	}
+	*all = append(*all, &manager.BestEffort{Selectors: selectors})
+}
+
+// BinfmtMiscType is the kind of match of a binfmt_misc entry
+type BinfmtMiscType string
+
+const (
+	// BinfmtMiscMagic matches the binaries starting with a magic, at an offset
+	BinfmtMiscMagic BinfmtMiscType = "magic"
+	// BinfmtMiscExtension matches the binaries by file name extension
+	BinfmtMiscExtension BinfmtMiscType = "extension"
+)
+
+// binfmtMiscFlags are the names of the flags of a binfmt_misc entry
+var binfmtMiscFlags = map[rune]string{
+	'P': "preserve_argv0",
+	'O': "open_binary",
+	'C': "credentials",
+	'F': "fix_binary",
+}
+
+// binfmtMiscTemporaryDirectories are the world-writable directories, an interpreter installed there doesn't come from
+// a package
+var binfmtMiscTemporaryDirectories = []string{"/tmp/", "/var/tmp/", "/dev/shm/"}
+
+// BinfmtMiscEvent represents a write to /proc/sys/fs/binfmt_misc/register. The kernel runs the registered interpreter
+// for every matching binary, whoever executes it: an entry is a persistence mechanism that survives the deletion of
+// its files when it is registered with the fix_binary flag.
+type BinfmtMiscEvent struct {
+	// Definition is the text written to the register file, :name:type:offset:magic:mask:interpreter:flags
+	Definition string `json:"definition"`
+	// Size is the length of the write
+	Size uint32 `json:"size"`
+	// Truncated is set when the write is longer than the captured definition
+	Truncated bool `json:"truncated,omitempty"`
+
+	// Name is the name of the entry, the file of the entry in the binfmt_misc file system
+	Name string `json:"name,omitempty"`
+	// Type is the kind of match of the entry
+	Type BinfmtMiscType `json:"type,omitempty"`
+	// Offset is the offset of the magic in the binaries
+	Offset uint64 `json:"offset,omitempty"`
+	// Magic is the hex encoded magic matched by the entry, with its escape sequences decoded
+	Magic string `json:"magic,omitempty"`
+	// Mask is the hex encoded mask applied to the binaries before they are compared to the magic
+	Mask string `json:"mask,omitempty"`
+	// Extension is the file name extension matched by the entry
+	Extension string `json:"extension,omitempty"`
+	// Interpreter is the path of the interpreter of the matching binaries
+	Interpreter string `json:"interpreter,omitempty"`
+	// Flags are the flags of the entry
+	Flags []string `json:"flags,omitempty"`
+	// Invalid is set when the definition couldn't be parsed, the kernel rejects it as well
+	Invalid bool `json:"invalid,omitempty"`
+}
+
+// TemporaryInterpreter returns true when the interpreter is installed in a world-writable directory
+func (e *BinfmtMiscEvent) TemporaryInterpreter() bool {
+	for _, dir := range binfmtMiscTemporaryDirectories {
+		if strings.HasPrefix(filepath.Clean(e.Interpreter), dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// unescapeBinfmtMisc decodes the \xHH escape sequences of a magic or of a mask, the other characters are kept as is
+func unescapeBinfmtMisc(value string) []byte {
+	var out []byte
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+3 < len(value) && (value[i+1] == 'x' || value[i+1] == 'X') {
+			if b, err := strconv.ParseUint(value[i+2:i+4], 16, 8); err == nil {
+				out = append(out, byte(b))
+				i += 3
+				continue
+			}
+		}
+		out = append(out, value[i])
+	}
+	return out
+}
+
+// parse parses the definition: the first character is the delimiter of the fields, usually ':'
+func (e *BinfmtMiscEvent) parse() {
+	definition := strings.TrimSuffix(e.Definition, "\n")
+	if len(definition) < 2 {
+		e.Invalid = true
+		return
+	}
+	fields := strings.Split(definition[1:], definition[:1])
+	if len(fields) < 6 {
+		e.Invalid = true
+		return
+	}
+	e.Name = fields[0]
+	switch fields[1] {
+	case "M":
+		e.Type = BinfmtMiscMagic
+		if len(fields[2]) > 0 {
+			offset, err := strconv.ParseUint(fields[2], 10, 64)
+			if err != nil {
+				e.Invalid = true
+			}
+			e.Offset = offset
+		}
+		e.Magic = hex.EncodeToString(unescapeBinfmtMisc(fields[3]))
+		e.Mask = hex.EncodeToString(unescapeBinfmtMisc(fields[4]))
+	case "E":
+		e.Type = BinfmtMiscExtension
+		e.Extension = fields[3]
+	default:
+		e.Invalid = true
+	}
+	e.Interpreter = fields[5]
+	if len(fields) > 6 {
+		for _, flag := range fields[6] {
+			if name, ok := binfmtMiscFlags[flag]; ok {
+				e.Flags = append(e.Flags, name)
+			} else {
+				e.Flags = append(e.Flags, fmt.Sprintf("flag_%c", flag))
+			}
+		}
+	}
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *BinfmtMiscEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < binfmtMiscEventLen {
+		return 0, fmt.Errorf("while parsing BinfmtMiscEvent, got len %d, needed %d: %w", len(data), binfmtMiscEventLen, ErrNotEnoughData)
+	}
+	e.Size = ByteOrder.Uint32(data[0:4])
+	e.Definition = string(bytes.Trim(data[8:binfmtMiscEventLen], "\x00"))
+	e.Truncated = e.Size >= binfmtMiscDefinitionLen
+	e.parse()
+	return binfmtMiscEventLen, nil
+}
+
+// BinfmtMiscEventSerializer is used to serialize BinfmtMiscEvent
+// easyjson:json
+type BinfmtMiscEventSerializer struct {
+	*BinfmtMiscEvent
+}
+
+// NewBinfmtMiscEventSerializer returns a new instance of BinfmtMiscEventSerializer
+func NewBinfmtMiscEventSerializer(e *BinfmtMiscEvent) *BinfmtMiscEventSerializer {
+	return &BinfmtMiscEventSerializer{
+		BinfmtMiscEvent: e,
+	}
+}
@@ -14,12 +14,13 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers $GOFILE
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
 
 package events
 
 import (
 	"fmt"
+	"time"
 
 	manager "github.com/DataDog/ebpf-manager"
 )
@@ -29,6 +30,8 @@ const (
 	BPFObjectNameLen = 16
 	// BPFTagLen is the length of a bpf program tag
 	BPFTagLen = 8
+	// BPFVerifierLogLen is the maximum length of the verifier log captured for a rejected program
+	BPFVerifierLogLen = 512
 )
 
 func addBPFProbes(all *[]*manager.Probe) {
@@ -97,6 +100,38 @@ type BPFEvent struct {
 	Map     BPFMap
 	Program BPFProgram
 	Cmd     BPFCmd
+
+	// Lifecycle links the event to the other events of the lifecycle of its program, it is resolved in user space
+	Lifecycle *BPFProgramLifecycle
+	// Fileless is set when the program is loaded by an executable run from a memfd or a deleted file, it is resolved
+	// in user space
+	Fileless bool
+	// FilelessOrigin is the memfd or the deleted file the loader of the program comes from
+	FilelessOrigin string
+	// VerifierLog is the head of the verifier log of a rejected program, it is captured from the log buffer of the
+	// caller and is empty if the caller didn't ask for a log
+	VerifierLog string
+	// VerifierLogTruncated is set when the verifier log is longer than BPFVerifierLogLen
+	VerifierLogTruncated bool
+}
+
+// DangerousBPFHelpers are the helpers which let a program alter the memory of user space or the control flow of the
+// kernel, instead of only observing them. They make a BPF program offensive: rootkits use them to rewrite the buffers
+// of the syscalls and to fake the return values of the kernel functions.
+var DangerousBPFHelpers = map[BPFHelperFunc]bool{
+	BpfProbeWriteUser: true,
+	BpfOverrideReturn: true,
+}
+
+// DangerousHelpers returns the dangerous helpers called by the program of the event, see DangerousBPFHelpers
+func (e *BPFEvent) DangerousHelpers() BPFHelperFuncList {
+	var out BPFHelperFuncList
+	for _, helper := range e.Program.Helpers {
+		if DangerousBPFHelpers[helper] {
+			out = append(out, helper)
+		}
+	}
+	return out
 }
 
 // UnmarshallBinary unmarshalls a binary representation of itself
@@ -113,11 +148,20 @@ func (e *BPFEvent) UnmarshallBinary(data []byte) (int, error) {
 	}
 	cursor += read
 
-	if len(data) < cursor+4 {
-		return 0, fmt.Errorf("while parsing BPFEvent, got len %d, needed %d: %w", len(data), cursor+4, ErrNotEnoughData)
+	if len(data) < cursor+8+BPFVerifierLogLen {
+		return 0, fmt.Errorf("while parsing BPFEvent, got len %d, needed %d: %w", len(data), cursor+8+BPFVerifierLogLen, ErrNotEnoughData)
 	}
 	e.Cmd = BPFCmd(ByteOrder.Uint32(data[cursor : cursor+4]))
-	return cursor + 4, nil
+	// padding
+	cursor += 8
+
+	e.VerifierLog, err = UnmarshalString(data[cursor:cursor+BPFVerifierLogLen], BPFVerifierLogLen)
+	if err != nil {
+		return 0, err
+	}
+	// the log is NULL terminated, a log filling the buffer was cut
+	e.VerifierLogTruncated = len(e.VerifierLog) == BPFVerifierLogLen-1
+	return cursor + BPFVerifierLogLen, nil
 }
 
 // BPFMap represents a BPF map
@@ -211,22 +255,96 @@ func parseHelpers(helpers []uint64) BPFHelperFuncList {
 	return rep
 }
 
+const (
+	// BPFLifecycleLoad is the lifecycle stage of a BPF_PROG_LOAD command
+	BPFLifecycleLoad = "load"
+	// BPFLifecycleAttach is the lifecycle stage of a BPF_PROG_ATTACH, BPF_RAW_TRACEPOINT_OPEN or BPF_LINK_CREATE command
+	BPFLifecycleAttach = "attach"
+	// BPFLifecycleDetach is the lifecycle stage of a BPF_PROG_DETACH command
+	BPFLifecycleDetach = "detach"
+	// BPFLifecyclePin is the lifecycle stage of a BPF_OBJ_PIN command
+	BPFLifecyclePin = "pin"
+	// BPFLifecycleUnload is the lifecycle stage of a bpf_program_unload event
+	BPFLifecycleUnload = "unload"
+)
+
+// BPFLifecycleStage returns the lifecycle stage of a bpf command, or an empty string if the command doesn't change the
+// lifecycle of a program
+func BPFLifecycleStage(cmd BPFCmd) string {
+	switch cmd {
+	case BpfProgLoadCmd:
+		return BPFLifecycleLoad
+	case BpfProgAttachCmd, BpfRawTracepointOpenCmd, BpfLinkCreateCmd:
+		return BPFLifecycleAttach
+	case BpfProgDetachCmd:
+		return BPFLifecycleDetach
+	case BpfObjPinCmd:
+		return BPFLifecyclePin
+	default:
+		return ""
+	}
+}
+
+// BPFProgramLifecycle links the events of the lifecycle of a BPF program: its load, its attachments, its pins and its
+// unload share the same lifecycle ID
+type BPFProgramLifecycle struct {
+	// ID identifies the program until it is unloaded, it is derived from the boot ID and the ID of the program so that
+	// it doesn't change when KRIE restarts
+	ID string `json:"id"`
+	// Identity identifies the program across reloads, it is derived from the name and the tag of the program
+	Identity string `json:"identity,omitempty"`
+	// Stage is the lifecycle stage of the event
+	Stage string `json:"stage,omitempty"`
+	// Stages are the lifecycle stages observed since the program was first seen
+	Stages []string `json:"stages,omitempty"`
+	// Sequence is the rank of the event in the lifecycle of the program
+	Sequence uint64 `json:"sequence"`
+	// FirstSeen is the time of the first event of the program
+	FirstSeen time.Time `json:"first_seen"`
+	// LoadEventID is the ID of the event which loaded the program, it is empty if the program was loaded before KRIE
+	LoadEventID string `json:"load_event_id,omitempty"`
+	// PreviousEventID is the ID of the previous event of the program
+	PreviousEventID string `json:"previous_event_id,omitempty"`
+}
+
 // BPFEventSerializer is used to serialize BPFEvent
 // easyjson:json
 type BPFEventSerializer struct {
-	Map     *BPFMap     `json:"map,omitempty"`
-	Program *BPFProgram `json:"program,omitempty"`
-	Cmd     BPFCmd      `json:"cmd"`
+	Map            *BPFMap              `json:"map,omitempty"`
+	Program        *BPFProgram          `json:"program,omitempty"`
+	Cmd            BPFCmd               `json:"cmd"`
+	Lifecycle      *BPFProgramLifecycle `json:"lifecycle,omitempty"`
+	Fileless       bool                 `json:"fileless,omitempty"`
+	FilelessOrigin string               `json:"fileless_origin,omitempty"`
+	// DangerousHelpers are the helpers of the program which alter the kernel or user space, see DangerousBPFHelpers
+	DangerousHelpers BPFHelperFuncList `json:"dangerous_helpers,omitempty"`
+	VerifierLog      *BPFVerifierLog   `json:"verifier_log,omitempty"`
+}
+
+// BPFVerifierLog is the verifier log of a rejected program
+type BPFVerifierLog struct {
+	Log       string `json:"log"`
+	Truncated bool   `json:"truncated,omitempty"`
 }
 
 // NewBPFEventSerializer returns a new instance of BPFEventSerializer
 func NewBPFEventSerializer(e *BPFEvent) *BPFEventSerializer {
 	serializer := &BPFEventSerializer{
-		Cmd: e.Cmd,
+		Cmd:            e.Cmd,
+		Lifecycle:      e.Lifecycle,
+		Fileless:       e.Fileless,
+		FilelessOrigin: e.FilelessOrigin,
 	}
 
+	if len(e.VerifierLog) > 0 {
+		serializer.VerifierLog = &BPFVerifierLog{
+			Log:       e.VerifierLog,
+			Truncated: e.VerifierLogTruncated,
+		}
+	}
 	if e.Program.ID > 0 {
 		serializer.Program = &e.Program
+		serializer.DangerousHelpers = e.DangerousHelpers()
 	}
 	if e.Map.ID > 0 {
 		serializer.Map = &e.Map
@@ -234,6 +352,27 @@ func NewBPFEventSerializer(e *BPFEvent) *BPFEventSerializer {
 	return serializer
 }
 
+// BPFProgramUnloadEvent is the event generated when a program tracked by the BPF lifecycle tracker is unloaded
+type BPFProgramUnloadEvent struct {
+	Program   BPFProgram
+	Lifecycle BPFProgramLifecycle
+}
+
+// BPFProgramUnloadEventSerializer is used to serialize BPFProgramUnloadEvent
+// easyjson:json
+type BPFProgramUnloadEventSerializer struct {
+	Program   *BPFProgram          `json:"program"`
+	Lifecycle *BPFProgramLifecycle `json:"lifecycle"`
+}
+
+// NewBPFProgramUnloadEventSerializer returns a new instance of BPFProgramUnloadEventSerializer
+func NewBPFProgramUnloadEventSerializer(e *BPFProgramUnloadEvent) *BPFProgramUnloadEventSerializer {
+	return &BPFProgramUnloadEventSerializer{
+		Program:   &e.Program,
+		Lifecycle: &e.Lifecycle,
+	}
+}
+
 // BPFFilterEvent represents a BPF event
 type BPFFilterEvent struct {
 	Cmd      BPFFilterCmd  `json:"cmd,omitempty"`
@@ -1,3 +1,5 @@
+//go:build !krie_minimal
+
 // Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
 
 package events
@@ -160,6 +162,53 @@ func easyjsonF27e5b1aDecodeGithubComGui774umeKriePkgKrieEvents1(in *jlexer.Lexer
 			}
 		case "cmd":
 			out.Cmd = BPFCmd(in.Uint64())
+		case "lifecycle":
+			if in.IsNull() {
+				in.Skip()
+				out.Lifecycle = nil
+			} else {
+				if out.Lifecycle == nil {
+					out.Lifecycle = new(BPFProgramLifecycle)
+				}
+				easyjsonF27e5b1aDecodeGithubComGui774umeKriePkgKrieEvents4(in, out.Lifecycle)
+			}
+		case "fileless":
+			out.Fileless = bool(in.Bool())
+		case "fileless_origin":
+			out.FilelessOrigin = string(in.String())
+		case "dangerous_helpers":
+			if in.IsNull() {
+				in.Skip()
+				out.DangerousHelpers = nil
+			} else {
+				in.Delim('[')
+				if out.DangerousHelpers == nil {
+					if !in.IsDelim(']') {
+						out.DangerousHelpers = make(BPFHelperFuncList, 0, 16)
+					} else {
+						out.DangerousHelpers = BPFHelperFuncList{}
+					}
+				} else {
+					out.DangerousHelpers = (out.DangerousHelpers)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 BPFHelperFunc
+					v4 = BPFHelperFunc(in.Uint32())
+					out.DangerousHelpers = append(out.DangerousHelpers, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "verifier_log":
+			if in.IsNull() {
+				in.Skip()
+				out.VerifierLog = nil
+			} else {
+				if out.VerifierLog == nil {
+					out.VerifierLog = new(BPFVerifierLog)
+				}
+				easyjsonF27e5b1aDecodeGithubComGui774umeKriePkgKrieEvents6(in, out.VerifierLog)
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -200,6 +249,31 @@ func easyjsonF27e5b1aEncodeGithubComGui774umeKriePkgKrieEvents1(out *jwriter.Wri
 		}
 		out.Raw((in.Cmd).MarshalJSON())
 	}
+	if in.Lifecycle != nil {
+		const prefix string = ",\"lifecycle\":"
+		out.RawString(prefix)
+		easyjsonF27e5b1aEncodeGithubComGui774umeKriePkgKrieEvents4(out, *in.Lifecycle)
+	}
+	if in.Fileless {
+		const prefix string = ",\"fileless\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Fileless))
+	}
+	if in.FilelessOrigin != "" {
+		const prefix string = ",\"fileless_origin\":"
+		out.RawString(prefix)
+		out.String(string(in.FilelessOrigin))
+	}
+	if len(in.DangerousHelpers) != 0 {
+		const prefix string = ",\"dangerous_helpers\":"
+		out.RawString(prefix)
+		out.Raw((in.DangerousHelpers).MarshalJSON())
+	}
+	if in.VerifierLog != nil {
+		const prefix string = ",\"verifier_log\":"
+		out.RawString(prefix)
+		easyjsonF27e5b1aEncodeGithubComGui774umeKriePkgKrieEvents6(out, *in.VerifierLog)
+	}
 	out.RawByte('}')
 }
 
@@ -366,3 +440,258 @@ func easyjsonF27e5b1aEncodeGithubComGui774umeKriePkgKrieEvents2(out *jwriter.Wri
 	}
 	out.RawByte('}')
 }
+func easyjsonF27e5b1aDecodeGithubComGui774umeKriePkgKrieEvents6(in *jlexer.Lexer, out *BPFVerifierLog) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "log":
+			out.Log = string(in.String())
+		case "truncated":
+			out.Truncated = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonF27e5b1aEncodeGithubComGui774umeKriePkgKrieEvents6(out *jwriter.Writer, in BPFVerifierLog) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"log\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Log))
+	}
+	if in.Truncated {
+		const prefix string = ",\"truncated\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Truncated))
+	}
+	out.RawByte('}')
+}
+func easyjsonF27e5b1aDecodeGithubComGui774umeKriePkgKrieEvents4(in *jlexer.Lexer, out *BPFProgramLifecycle) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = string(in.String())
+		case "identity":
+			out.Identity = string(in.String())
+		case "stage":
+			out.Stage = string(in.String())
+		case "stages":
+			if in.IsNull() {
+				in.Skip()
+				out.Stages = nil
+			} else {
+				in.Delim('[')
+				if out.Stages == nil {
+					if !in.IsDelim(']') {
+						out.Stages = make([]string, 0, 4)
+					} else {
+						out.Stages = []string{}
+					}
+				} else {
+					out.Stages = (out.Stages)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.Stages = append(out.Stages, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "sequence":
+			out.Sequence = uint64(in.Uint64())
+		case "first_seen":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.FirstSeen).UnmarshalJSON(data))
+			}
+		case "load_event_id":
+			out.LoadEventID = string(in.String())
+		case "previous_event_id":
+			out.PreviousEventID = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonF27e5b1aEncodeGithubComGui774umeKriePkgKrieEvents4(out *jwriter.Writer, in BPFProgramLifecycle) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ID))
+	}
+	if in.Identity != "" {
+		const prefix string = ",\"identity\":"
+		out.RawString(prefix)
+		out.String(string(in.Identity))
+	}
+	if in.Stage != "" {
+		const prefix string = ",\"stage\":"
+		out.RawString(prefix)
+		out.String(string(in.Stage))
+	}
+	if len(in.Stages) != 0 {
+		const prefix string = ",\"stages\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.Stages {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"sequence\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Sequence))
+	}
+	{
+		const prefix string = ",\"first_seen\":"
+		out.RawString(prefix)
+		out.Raw((in.FirstSeen).MarshalJSON())
+	}
+	if in.LoadEventID != "" {
+		const prefix string = ",\"load_event_id\":"
+		out.RawString(prefix)
+		out.String(string(in.LoadEventID))
+	}
+	if in.PreviousEventID != "" {
+		const prefix string = ",\"previous_event_id\":"
+		out.RawString(prefix)
+		out.String(string(in.PreviousEventID))
+	}
+	out.RawByte('}')
+}
+func easyjsonF27e5b1aDecodeGithubComGui774umeKriePkgKrieEvents5(in *jlexer.Lexer, out *BPFProgramUnloadEventSerializer) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "program":
+			if in.IsNull() {
+				in.Skip()
+				out.Program = nil
+			} else {
+				if out.Program == nil {
+					out.Program = new(BPFProgram)
+				}
+				easyjsonF27e5b1aDecodeGithubComGui774umeKriePkgKrieEvents3(in, out.Program)
+			}
+		case "lifecycle":
+			if in.IsNull() {
+				in.Skip()
+				out.Lifecycle = nil
+			} else {
+				if out.Lifecycle == nil {
+					out.Lifecycle = new(BPFProgramLifecycle)
+				}
+				easyjsonF27e5b1aDecodeGithubComGui774umeKriePkgKrieEvents4(in, out.Lifecycle)
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonF27e5b1aEncodeGithubComGui774umeKriePkgKrieEvents5(out *jwriter.Writer, in BPFProgramUnloadEventSerializer) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"program\":"
+		out.RawString(prefix[1:])
+		if in.Program == nil {
+			out.RawString("null")
+		} else {
+			easyjsonF27e5b1aEncodeGithubComGui774umeKriePkgKrieEvents3(out, *in.Program)
+		}
+	}
+	{
+		const prefix string = ",\"lifecycle\":"
+		out.RawString(prefix)
+		if in.Lifecycle == nil {
+			out.RawString("null")
+		} else {
+			easyjsonF27e5b1aEncodeGithubComGui774umeKriePkgKrieEvents4(out, *in.Lifecycle)
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v BPFProgramUnloadEventSerializer) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonF27e5b1aEncodeGithubComGui774umeKriePkgKrieEvents5(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *BPFProgramUnloadEventSerializer) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonF27e5b1aDecodeGithubComGui774umeKriePkgKrieEvents5(l, v)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+)
+
+// BPFMapOwner is the process which created a BPF map
+type BPFMapOwner struct {
+	PID  uint32 `json:"pid"`
+	Comm string `json:"comm,omitempty"`
+}
+
+// UnmarshalBinary unmarshalls a binary representation of itself
+func (o *BPFMapOwner) UnmarshalBinary(data []byte) (int, error) {
+	if len(data) < 8+TaskCommLength {
+		return 0, fmt.Errorf("while parsing BPFMapOwner, got len %d, needed %d: %w", len(data), 8+TaskCommLength, ErrNotEnoughData)
+	}
+	o.PID = ByteOrder.Uint32(data[0:4])
+	// padding
+
+	var err error
+	o.Comm, err = UnmarshalString(data[8:8+TaskCommLength], TaskCommLength)
+	if err != nil {
+		return 0, err
+	}
+	return 8 + TaskCommLength, nil
+}
+
+// BPFMapTamperEvent represents a write (BPF_MAP_UPDATE_ELEM, BPF_MAP_DELETE_ELEM and their batch variants) to a BPF
+// map created by another process. The eBPF security tools are neutralized by rewriting their maps, the maps created
+// before KRIE started aren't tracked, except for the maps of KRIE.
+type BPFMapTamperEvent struct {
+	Map   BPFMap      `json:"map"`
+	Owner BPFMapOwner `json:"owner"`
+	Cmd   BPFCmd      `json:"cmd"`
+	// KRIEMap is set when the map belongs to KRIE
+	KRIEMap bool `json:"krie_map"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *BPFMapTamperEvent) UnmarshallBinary(data []byte) (int, error) {
+	var cursor int
+	read, err := e.Map.UnmarshalBinary(data[cursor:])
+	if err != nil {
+		return 0, err
+	}
+	cursor += read
+	read, err = e.Owner.UnmarshalBinary(data[cursor:])
+	if err != nil {
+		return 0, err
+	}
+	cursor += read
+
+	if len(data) < cursor+8 {
+		return 0, fmt.Errorf("while parsing BPFMapTamperEvent, got len %d, needed %d: %w", len(data), cursor+8, ErrNotEnoughData)
+	}
+	e.Cmd = BPFCmd(ByteOrder.Uint32(data[cursor : cursor+4]))
+	e.KRIEMap = ByteOrder.Uint32(data[cursor+4:cursor+8]) == 1
+	return cursor + 8, nil
+}
+
+// BPFMapTamperEventSerializer is used to serialize BPFMapTamperEvent
+// easyjson:json
+type BPFMapTamperEventSerializer struct {
+	*BPFMapTamperEvent
+}
+
+// NewBPFMapTamperEventSerializer returns a new instance of BPFMapTamperEventSerializer
+func NewBPFMapTamperEventSerializer(e *BPFMapTamperEvent) *BPFMapTamperEventSerializer {
+	return &BPFMapTamperEventSerializer{
+		BPFMapTamperEvent: e,
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	// capsetEventLen is the length of the binary representation of a capset event
+	capsetEventLen = 48
+
+	capSysRawio = 17
+	capPerfmon  = 38
+	capBPF      = 39
+
+	// capsetSensitiveCapabilities are the capabilities reported by the capset events
+	capsetSensitiveCapabilities CapabilityMask = 1<<capSysModule | 1<<capSysRawio | 1<<capPerfmon | 1<<capBPF
+)
+
+// capabilityNames are the names of the capabilities, indexed by their bit
+var capabilityNames = []string{
+	"CAP_CHOWN",
+	"CAP_DAC_OVERRIDE",
+	"CAP_DAC_READ_SEARCH",
+	"CAP_FOWNER",
+	"CAP_FSETID",
+	"CAP_KILL",
+	"CAP_SETGID",
+	"CAP_SETUID",
+	"CAP_SETPCAP",
+	"CAP_LINUX_IMMUTABLE",
+	"CAP_NET_BIND_SERVICE",
+	"CAP_NET_BROADCAST",
+	"CAP_NET_ADMIN",
+	"CAP_NET_RAW",
+	"CAP_IPC_LOCK",
+	"CAP_IPC_OWNER",
+	"CAP_SYS_MODULE",
+	"CAP_SYS_RAWIO",
+	"CAP_SYS_CHROOT",
+	"CAP_SYS_PTRACE",
+	"CAP_SYS_PACCT",
+	"CAP_SYS_ADMIN",
+	"CAP_SYS_BOOT",
+	"CAP_SYS_NICE",
+	"CAP_SYS_RESOURCE",
+	"CAP_SYS_TIME",
+	"CAP_SYS_TTY_CONFIG",
+	"CAP_MKNOD",
+	"CAP_LEASE",
+	"CAP_AUDIT_WRITE",
+	"CAP_AUDIT_CONTROL",
+	"CAP_SETFCAP",
+	"CAP_MAC_OVERRIDE",
+	"CAP_MAC_ADMIN",
+	"CAP_SYSLOG",
+	"CAP_WAKE_ALARM",
+	"CAP_BLOCK_SUSPEND",
+	"CAP_AUDIT_READ",
+	"CAP_PERFMON",
+	"CAP_BPF",
+	"CAP_CHECKPOINT_RESTORE",
+}
+
+// CapabilityMask is a set of capabilities
+type CapabilityMask uint64
+
+// Names returns the names of the capabilities of the set
+func (m CapabilityMask) Names() []string {
+	names := []string{}
+	for mask := uint64(m); mask != 0; mask &= mask - 1 {
+		bit := bits.TrailingZeros64(mask)
+		if bit < len(capabilityNames) {
+			names = append(names, capabilityNames[bit])
+		} else {
+			names = append(names, fmt.Sprintf("Capability(%d)", bit))
+		}
+	}
+	return names
+}
+
+// MarshalJSON returns the JSON encoding of the capability names of the set
+func (m CapabilityMask) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Names())
+}
+
+// UnmarshalJSON parses the capability names of a set
+func (m *CapabilityMask) UnmarshalJSON(data []byte) error {
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	*m = 0
+	for _, name := range names {
+		bit := -1
+		for i, capability := range capabilityNames {
+			if capability == name {
+				bit = i
+				break
+			}
+		}
+		if bit < 0 {
+			if _, err := fmt.Sscanf(name, "Capability(%d)", &bit); err != nil || bit < 0 || bit > 63 {
+				return fmt.Errorf("unknown capability %s", name)
+			}
+		}
+		*m |= 1 << bit
+	}
+	return nil
+}
+
+// CapabilitySets are the capability sets of a process
+type CapabilitySets struct {
+	Effective   CapabilityMask `json:"effective"`
+	Permitted   CapabilityMask `json:"permitted"`
+	Inheritable CapabilityMask `json:"inheritable"`
+}
+
+func addCapsetProbes(all *[]*manager.Probe) {
+	*all = append(*all, ExpandSyscallProbes(&manager.Probe{
+		ProbeIdentificationPair: manager.ProbeIdentificationPair{
+			UID: KRIEUID,
+		},
+		SyscallFuncName: "capset",
+	}, EntryAndExit)...)
+}
+
+func addCapsetRoutes(all *[]manager.TailCallRoute) {
+	*all = append(*all, []manager.TailCallRoute{
+		{
+			ProgArrayName: "sys_exit_progs",
+			Key:           uint32(CapsetEventType),
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				EBPFSection:  "tracepoint/handle_sys_capset_exit",
+				EBPFFuncName: "tracepoint_handle_sys_capset_exit",
+			},
+		},
+	}...)
+}
+
+func addCapsetSelectors(all *[]manager.ProbesSelector) {
+	*all = append(*all,
+		&manager.OneOf{Selectors: ExpandSyscallProbesSelector(
+			manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: "capset"}, EntryAndExit),
+		},
+	)
+}
+
+// CapsetEvent represents a capset call granting CAP_SYS_MODULE, CAP_SYS_RAWIO, CAP_PERFMON or CAP_BPF to the caller.
+// The sets after the call are the sets requested by the caller, they are applied when the call succeeds.
+type CapsetEvent struct {
+	Before CapabilitySets `json:"before"`
+	After  CapabilitySets `json:"after"`
+	// Gained lists the sensitive capabilities added to the effective or the permitted set
+	Gained CapabilityMask `json:"gained"`
+	// Escalation is set when the permitted set gains a sensitive capability: capset can only drop permitted
+	// capabilities, the call is either rejected or the kernel was tampered with
+	Escalation bool `json:"escalation"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *CapsetEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < capsetEventLen {
+		return 0, fmt.Errorf("while parsing CapsetEvent, got len %d, needed %d: %w", len(data), capsetEventLen, ErrNotEnoughData)
+	}
+	e.Before.Effective = CapabilityMask(ByteOrder.Uint64(data[0:8]))
+	e.Before.Permitted = CapabilityMask(ByteOrder.Uint64(data[8:16]))
+	e.Before.Inheritable = CapabilityMask(ByteOrder.Uint64(data[16:24]))
+	e.After.Effective = CapabilityMask(ByteOrder.Uint64(data[24:32]))
+	e.After.Permitted = CapabilityMask(ByteOrder.Uint64(data[32:40]))
+	e.After.Inheritable = CapabilityMask(ByteOrder.Uint64(data[40:48]))
+
+	permitted := e.After.Permitted &^ e.Before.Permitted & capsetSensitiveCapabilities
+	e.Gained = e.After.Effective&^e.Before.Effective&capsetSensitiveCapabilities | permitted
+	e.Escalation = permitted != 0
+	return capsetEventLen, nil
+}
+
+// CapsetEventSerializer is used to serialize CapsetEvent
+// easyjson:json
+type CapsetEventSerializer struct {
+	*CapsetEvent
+}
+
+// NewCapsetEventSerializer returns a new instance of CapsetEventSerializer
+func NewCapsetEventSerializer(e *CapsetEvent) *CapsetEventSerializer {
+	return &CapsetEventSerializer{
+		CapsetEvent: e,
+	}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import "time"
+
+// ClockSkewEvent is the diagnostic event generated when the kernel timestamp of an event is implausibly old or in the
+// future, compared to the time the event was received in user space
+type ClockSkewEvent struct {
+	// Anomaly is either "stale" or "future"
+	Anomaly          string    `json:"anomaly"`
+	TriggerEventID   string    `json:"trigger_event_id"`
+	TriggerEventType string    `json:"trigger_event_type"`
+	KernelTime       time.Time `json:"kernel_time"`
+	ReceivedTime     time.Time `json:"received_time"`
+	// Skew is the delay between the kernel timestamp and the reception of the event, negative for future timestamps
+	Skew int64 `json:"skew_ms"`
+
+	// Suppressed is the number of anomalies of the same kind not reported since the previous event
+	Suppressed uint64 `json:"suppressed,omitempty"`
+}
+
+// ClockSkewEventSerializer is used to serialize ClockSkewEvent
+// easyjson:json
+type ClockSkewEventSerializer struct {
+	*ClockSkewEvent
+}
+
+// NewClockSkewEventSerializer returns a new instance of ClockSkewEventSerializer
+func NewClockSkewEventSerializer(e *ClockSkewEvent) *ClockSkewEventSerializer {
+	return &ClockSkewEventSerializer{
+		ClockSkewEvent: e,
+	}
+}
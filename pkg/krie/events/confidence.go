@@ -0,0 +1,270 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Confidence is the confidence of KRIE in the detection reported by an event, it is independent of the action taken
+// by KRIE
+type Confidence string
+
+const (
+	// LowConfidence is the confidence of the anomalies: a deviation from the usual behavior, worth a hunt
+	LowConfidence Confidence = "low"
+	// MediumConfidence is the confidence of the sensitive kernel operations, legitimate or not
+	MediumConfidence Confidence = "medium"
+	// HighConfidence is the confidence of the signature matches: a known tampering technique, worth a page
+	HighConfidence Confidence = "high"
+)
+
+// Level returns the rank of the confidence, 0 for the events that don't report a detection
+func (c Confidence) Level() int {
+	switch c {
+	case LowConfidence:
+		return 1
+	case MediumConfidence:
+		return 2
+	case HighConfidence:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// IsValid returns an error if the confidence is unknown
+func (c Confidence) IsValid() error {
+	if c.Level() == 0 {
+		return fmt.Errorf("invalid confidence: %q", c)
+	}
+	return nil
+}
+
+// AtLeast returns true if the confidence is higher or equal to the provided confidence
+func (c Confidence) AtLeast(min Confidence) bool {
+	return c.Level() >= min.Level()
+}
+
+// defaultConfidence returns the confidence of an event when no rule overrides it, or an empty confidence for the
+// events that don't report a detection (responses, policy changes, ...)
+func defaultConfidence(e *Event) Confidence {
+	// a value found in a threat intel feed is a known indicator of compromise
+	if len(e.Intel) > 0 {
+		return HighConfidence
+	}
+	// a process iterating on a failing call is probing the kernel
+	if e.Failure != nil && e.Failure.Repeated {
+		return HighConfidence
+	}
+	switch e.Kernel.Type {
+	case HookedSyscallTableEventType, HookedSyscallEventType, RegisterCheckEventType, KernelParameterEventType,
+		PeriodicKernelParameterEventType, TailCallTamperEventType, IntegritySwitchEventType, SysCtlDriftEventType,
+		ControlRegisterEventType, CredChangeEventType, HiddenProcessEventType:
+		// the kernel state doesn't match the expected state
+		return HighConfidence
+	case InitModuleEventType:
+		if e.InitModule.SignerPolicy == SignerPolicyDenied || e.InitModule.ImagePolicy == ImagePolicyDenied || e.InitModule.Fileless {
+			return HighConfidence
+		}
+		return MediumConfidence
+	case BPFEventType:
+		// loading a bpf program from a memfd or a deleted executable is a strong malicious indicator
+		if e.BPFEvent.Fileless {
+			return HighConfidence
+		}
+		// the programs writing to user space memory or overriding return values are rarely observational
+		if len(e.BPFEvent.DangerousHelpers()) > 0 {
+			return HighConfidence
+		}
+		return MediumConfidence
+	case LSMStateChangeEventType:
+		// the administrators may switch SELinux to permissive mode, but not without going through selinuxfs
+		if e.LSMStateChangeEvent.Change == SELinuxStateTampering {
+			return HighConfidence
+		}
+		return MediumConfidence
+	case RawMemoryAccessEventType:
+		// reading physical memory has legitimate uses (dmidecode), writing to it doesn't
+		if e.RawMemoryAccessEvent.Writable {
+			return HighConfidence
+		}
+		return MediumConfidence
+	case MSRWriteEventType:
+		// only the rootkits redirect the syscall entry points at runtime
+		if e.MSRWriteEvent.SyscallEntry {
+			return HighConfidence
+		}
+		return MediumConfidence
+	case SymbolResolutionEventType:
+		// the kernel resolves symbols for the kprobes and the bpf programs, a module has no legitimate reason to
+		if caller := e.SymbolResolutionEvent.Caller; caller != nil && len(caller.Module) > 0 && caller.Module != "system" && caller.Module != "unknown" {
+			return HighConfidence
+		}
+		return MediumConfidence
+	case UsermodeHelperEventType:
+		switch {
+		case e.UsermodeHelperEvent.Hijacked:
+			// modprobe_path was overwritten in memory, the signature of a kernel exploit
+			return HighConfidence
+		case e.UsermodeHelperEvent.Operation == UsermodeHelperWrite:
+			return MediumConfidence
+		default:
+			// the kernel runs modprobe and the core dump helpers routinely
+			return LowConfidence
+		}
+	case MountEventType:
+		switch {
+		case e.MountEvent.FSType == MountProcFS:
+			// the container runtimes mount procfs in every container
+			return LowConfidence
+		case e.MountEvent.NewNamespace:
+			// a container has no legitimate reason to mount the kernel interfaces
+			return HighConfidence
+		default:
+			return MediumConfidence
+		}
+	case PrctlEventType:
+		// a compromised process weakens its sandbox to let another process inject code into it
+		if e.PrctlEvent.Weakening {
+			return MediumConfidence
+		}
+		return LowConfidence
+	case CapsetEventType:
+		// capset can't add permitted capabilities, a process trying to is probing the kernel or exploiting it
+		if e.CapsetEvent.Escalation {
+			return HighConfidence
+		}
+		return MediumConfidence
+	case BPFMapTamperEventType:
+		// no process has a legitimate reason to rewrite the maps of KRIE
+		if e.BPFMapTamperEvent.KRIEMap {
+			return HighConfidence
+		}
+		return MediumConfidence
+	case KernelLogTamperEventType:
+		switch {
+		case len(e.KernelLogTamperEvent.AfterModuleLoad) > 0:
+			// the kernel log is erased to hide the warnings of the module which was just loaded
+			return HighConfidence
+		case e.KernelLogTamperEvent.Operation == KernelLogKmsgTruncate:
+			// a shell redirection to /dev/kmsg opens it with O_TRUNC, the scripts logging to the kernel log do it
+			return LowConfidence
+		default:
+			return MediumConfidence
+		}
+	case NetfilterHookEventType:
+		switch module := e.NetfilterHookEvent.Hook.Module; {
+		case module == "unknown":
+			// the hook isn't in kallsyms, the module which registered it is hiding
+			return HighConfidence
+		case module == "system" || isNetfilterModule(module):
+			// the firewalls, conntrack and the container runtimes register hooks routinely
+			return LowConfidence
+		default:
+			return MediumConfidence
+		}
+	case TracefsProbeEventType:
+		// removing probes can't hide anything, creating them is how a rootkit hooks the kernel without a module
+		if e.TracefsProbeEvent.RemovalOnly() {
+			return LowConfidence
+		}
+		return MediumConfidence
+	case KeyringEventType:
+		switch {
+		case e.KeyringEvent.Syscall == KeyringAddKey && e.KeyringEvent.Type == AsymmetricKeyType:
+			// a public key added to a trusted keyring gets the modules it signed loaded
+			return HighConfidence
+		case e.KeyringEvent.Syscall == KeyringKeyctl && e.KeyringEvent.Command.ChangesAccess():
+			return MediumConfidence
+		default:
+			// the session managers, the ssh daemons and the network file systems use the keyrings routinely
+			return LowConfidence
+		}
+	case NamespaceEventType:
+		switch {
+		case e.NamespaceEvent.HostEscape:
+			// a process of a container entered the namespaces of the host
+			return HighConfidence
+		case len(e.NamespaceEvent.EnteredHost) > 0:
+			return MediumConfidence
+		default:
+			// the container runtimes and the sandboxes create and enter namespaces routinely
+			return LowConfidence
+		}
+	case UserfaultfdEventType:
+		if e.UserfaultfdEvent.KernelFaults {
+			// the faults of the kernel pause a syscall in the middle of a copy from user space
+			return MediumConfidence
+		}
+		// the live migrations and the garbage collectors handle their own faults
+		return LowConfidence
+	case ModuleSignatureEventType:
+		switch {
+		case e.ModuleSignatureEvent.Bypass:
+			return HighConfidence
+		case e.ModuleSignatureEvent.Kind == ModuleSignatureLoad:
+			// the out-of-tree modules of the hosts without enforcement (DKMS drivers) are unsigned
+			return MediumConfidence
+		default:
+			return LowConfidence
+		}
+	case RebootEventType:
+		switch {
+		case e.RebootEvent.InvalidMagic || e.RebootEvent.Cmd.IsCtrlAltDel():
+			// the call is rejected by the kernel, or it doesn't reboot the machine
+			return LowConfidence
+		case e.RebootEvent.Cmd == RebootCmdKexec:
+			// the machine is about to run a kernel which was never booted by the firmware
+			return HighConfidence
+		default:
+			return MediumConfidence
+		}
+	case BinfmtMiscEventType:
+		if e.BinfmtMiscEvent.TemporaryInterpreter() {
+			// the emulators registered by the container tools are installed by packages
+			return HighConfidence
+		}
+		return MediumConfidence
+	case DeleteModuleEventType, BPFFilterEventType, PTraceEventType, KProbeEventType, SysCtlEventType,
+		KexecEventType, FTraceHookEventType, IOUringEventType, PerfEventOpenEventType, ProcessVMEventType:
+		return MediumConfidence
+	case ClockSkewEventType, SilenceEventType, ProfileSnapshotEventType, UpgradeEventType,
+		SyscallOrphansEventType:
+		return LowConfidence
+	default:
+		return ""
+	}
+}
+
+// Confidence returns the confidence of the provided event. The confidence configured for a rule applies to the rule
+// and to its sub rules (for example "sysctl" applies to "sysctl/kernel/yama/ptrace_scope"), the most specific rule
+// wins. The events of the rules without configured confidence get the default confidence of their event type.
+func (o *Options) Confidence(e *Event) Confidence {
+	confidence, matched := defaultConfidence(e), -1
+	ruleID := e.Policy.RuleID
+	for rule, c := range o.Confidences {
+		if rule != ruleID && !strings.HasPrefix(ruleID, rule+"/") {
+			continue
+		}
+		if len(rule) > matched {
+			confidence, matched = c, len(rule)
+		}
+	}
+	return confidence
+}
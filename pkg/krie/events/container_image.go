@@ -0,0 +1,76 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	ImagePolicyAllowed = "allowed"
+	ImagePolicyDenied  = "denied"
+
+	// UnallowedImageRule is the rule of the init_module events of the containers which don't run an allowed image
+	UnallowedImageRule = "unallowed_image"
+)
+
+// ContainerImageOptions configures the container image policy: the processes of a container can only load a module if
+// the container runs one of the allowed image digests. The image digests are resolved through the CRI (see the cri
+// section), the module loads of the host processes aren't affected.
+type ContainerImageOptions struct {
+	// Action is the action taken for the module loads of the containers which don't run an allowed image: "nop"
+	// disables the policy, "log" alerts, "block" unloads the module
+	Action Action `yaml:"action"`
+	// Digests are the allowed image digests (sha256:<hex>)
+	Digests []string `yaml:"digests"`
+
+	digests map[string]bool `yaml:"-"`
+}
+
+// NewContainerImageOptions returns a default set of container image options
+func NewContainerImageOptions() *ContainerImageOptions {
+	return &ContainerImageOptions{}
+}
+
+// IsValid returns an error if the container image options are invalid, and indexes the allowed digests
+func (o *ContainerImageOptions) IsValid() error {
+	if o.Action == NopAction {
+		return nil
+	}
+	if o.Action > BlockAction {
+		return fmt.Errorf("container_images action cannot be set to \"%s\"", o.Action)
+	}
+	if len(o.Digests) == 0 {
+		return fmt.Errorf("no allowed digest")
+	}
+	o.digests = make(map[string]bool, len(o.Digests))
+	for _, digest := range o.Digests {
+		sum, ok := strings.CutPrefix(digest, "sha256:")
+		if decoded, err := hex.DecodeString(sum); !ok || err != nil || len(decoded) != 32 {
+			return fmt.Errorf("invalid digest %q, expected sha256:<64 hex characters>", digest)
+		}
+		o.digests[strings.ToLower(digest)] = true
+	}
+	return nil
+}
+
+// IsAllowed returns true if the provided image digest is allowed, the digests are indexed by IsValid
+func (o *ContainerImageOptions) IsAllowed(digest string) bool {
+	return o.digests[strings.ToLower(digest)]
+}
@@ -0,0 +1,163 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	controlRegisterCR0 = 0
+	controlRegisterCR4 = 4
+
+	x86CR0WP   = uint64(1) << 16
+	x86CR4SMEP = uint64(1) << 20
+	x86CR4SMAP = uint64(1) << 21
+)
+
+// controlRegisterBits lists the protection bits monitored on each control register
+var controlRegisterBits = map[uint32][]struct {
+	name string
+	mask uint64
+}{
+	controlRegisterCR0: {{name: "wp", mask: x86CR0WP}},
+	controlRegisterCR4: {{name: "smep", mask: x86CR4SMEP}, {name: "smap", mask: x86CR4SMAP}},
+}
+
+var (
+	controlRegisterCR4MaskOnce sync.Once
+	controlRegisterCR4Mask     uint64
+)
+
+// GetControlRegisterCR4Mask returns the mask of the CR4 protection bits supported by the CPU, so that a CPU without
+// SMAP (for example) doesn't report every write to CR4
+func GetControlRegisterCR4Mask() uint64 {
+	controlRegisterCR4MaskOnce.Do(func() {
+		controlRegisterCR4Mask = readControlRegisterCR4Mask()
+	})
+	return controlRegisterCR4Mask
+}
+
+func readControlRegisterCR4Mask() uint64 {
+	f, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return x86CR4SMEP | x86CR4SMAP
+	}
+	defer f.Close()
+
+	var mask uint64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found || strings.TrimSpace(key) != "flags" {
+			continue
+		}
+		for _, flag := range strings.Fields(value) {
+			switch flag {
+			case "smep":
+				mask |= x86CR4SMEP
+			case "smap":
+				mask |= x86CR4SMAP
+			}
+		}
+		break
+	}
+	return mask
+}
+
+func controlRegisterProbes() []manager.ProbeIdentificationPair {
+	return []manager.ProbeIdentificationPair{
+		{UID: KRIEUID, EBPFSection: "kprobe/native_write_cr0", EBPFFuncName: "kprobe_native_write_cr0"},
+		{UID: KRIEUID, EBPFSection: "kprobe/native_write_cr4", EBPFFuncName: "kprobe_native_write_cr4"},
+	}
+}
+
+func addControlRegisterProbes(all *[]*manager.Probe) {
+	for _, id := range controlRegisterProbes() {
+		*all = append(*all, &manager.Probe{ProbeIdentificationPair: id})
+	}
+}
+
+func addControlRegisterSelectors(all *[]manager.ProbesSelector) {
+	// native_write_cr0 and native_write_cr4 only exist on x86
+	var selectors []manager.ProbesSelector
+	for _, id := range controlRegisterProbes() {
+		selectors = append(selectors, &manager.ProbeSelector{ProbeIdentificationPair: id})
+	}
+	*all = append(*all, &manager.BestEffort{Selectors: selectors})
+}
+
+// ControlRegisterEvent represents a write to a control register that changes one of its protection bits (CR0.WP,
+// CR4.SMEP or CR4.SMAP)
+type ControlRegisterEvent struct {
+	Register      string   `json:"register"`
+	OldValue      uint64   `json:"old_value"`
+	NewValue      uint64   `json:"new_value"`
+	OldValueKnown bool     `json:"old_value_known"`
+	Cleared       []string `json:"cleared,omitempty"`
+	Set           []string `json:"set,omitempty"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *ControlRegisterEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < 24 {
+		return 0, fmt.Errorf("while parsing ControlRegisterEvent, got len %d, needed %d: %w", len(data), 24, ErrNotEnoughData)
+	}
+	e.OldValue = ByteOrder.Uint64(data[0:8])
+	e.NewValue = ByteOrder.Uint64(data[8:16])
+	reg := ByteOrder.Uint32(data[16:20])
+	e.Register = fmt.Sprintf("cr%d", reg)
+	e.OldValueKnown = ByteOrder.Uint32(data[20:24]) == 1
+
+	// when the previous value is unknown, every protection bit missing from the new value is considered cleared
+	e.Cleared, e.Set = nil, nil
+	for _, bit := range controlRegisterBits[reg] {
+		if reg == controlRegisterCR4 && GetControlRegisterCR4Mask()&bit.mask == 0 {
+			// not supported by the CPU
+			continue
+		}
+		oldSet := !e.OldValueKnown || e.OldValue&bit.mask != 0
+		newSet := e.NewValue&bit.mask != 0
+		if oldSet && !newSet {
+			e.Cleared = append(e.Cleared, bit.name)
+		} else if !oldSet && newSet {
+			e.Set = append(e.Set, bit.name)
+		}
+	}
+	return 24, nil
+}
+
+// ControlRegisterEventSerializer is used to serialize ControlRegisterEvent
+// easyjson:json
+type ControlRegisterEventSerializer struct {
+	*ControlRegisterEvent
+}
+
+// NewControlRegisterEventSerializer returns a new instance of ControlRegisterEventSerializer
+func NewControlRegisterEventSerializer(e *ControlRegisterEvent) *ControlRegisterEventSerializer {
+	return &ControlRegisterEventSerializer{
+		ControlRegisterEvent: e,
+	}
+}
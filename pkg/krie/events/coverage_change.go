@@ -0,0 +1,53 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+const (
+	// CoverageAttached is the status of a coverage_change event when pending probes were attached
+	CoverageAttached = "attached"
+	// CoverageDetached is the status of a coverage_change event when the symbol of attached probes disappeared
+	CoverageDetached = "detached"
+	// CoverageError is the status of a coverage_change event when pending probes couldn't be attached
+	CoverageError = "error"
+)
+
+// CoverageChangeEvent is the event generated when the symbol of probes that couldn't be attached at startup becomes
+// available (for example when the module providing it is loaded), or when it disappears
+type CoverageChangeEvent struct {
+	Symbol string   `json:"symbol"`
+	Module string   `json:"module,omitempty"`
+	Status string   `json:"status"`
+	Probes []string `json:"probes"`
+	Error  string   `json:"error,omitempty"`
+	// Pending is the number of probes still waiting for their symbol
+	Pending int `json:"pending"`
+}
+
+// CoverageChangeEventSerializer is used to serialize CoverageChangeEvent
+// easyjson:json
+type CoverageChangeEventSerializer struct {
+	*CoverageChangeEvent
+}
+
+// NewCoverageChangeEventSerializer returns a new instance of CoverageChangeEventSerializer
+func NewCoverageChangeEventSerializer(e *CoverageChangeEvent) *CoverageChangeEventSerializer {
+	return &CoverageChangeEventSerializer{
+		CoverageChangeEvent: e,
+	}
+}
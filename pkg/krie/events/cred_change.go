@@ -0,0 +1,123 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	credChangeRoot       = 1 << 0
+	credChangeCapability = 1 << 1
+
+	capSysModule = 16
+	capSysAdmin  = 21
+)
+
+func credChangeProbes() []manager.ProbeIdentificationPair {
+	return []manager.ProbeIdentificationPair{
+		{UID: KRIEUID, EBPFSection: "kprobe/commit_creds", EBPFFuncName: "kprobe_commit_creds"},
+		{UID: KRIEUID, EBPFSection: "kprobe/security_bprm_committing_creds", EBPFFuncName: "kprobe_security_bprm_committing_creds"},
+		{UID: KRIEUID, EBPFSection: "kprobe/security_bprm_committed_creds", EBPFFuncName: "kprobe_security_bprm_committed_creds"},
+	}
+}
+
+func addCredChangeProbes(all *[]*manager.Probe) {
+	for _, id := range credChangeProbes() {
+		*all = append(*all, &manager.Probe{ProbeIdentificationPair: id})
+	}
+}
+
+func addCredChangeSelectors(all *[]manager.ProbesSelector) {
+	// without the exec markers, every setuid exec would be reported
+	var selectors []manager.ProbesSelector
+	for _, id := range credChangeProbes() {
+		selectors = append(selectors, &manager.ProbeSelector{ProbeIdentificationPair: id})
+	}
+	*all = append(*all, &manager.AllOf{Selectors: selectors})
+}
+
+// CredChangeEvent represents a change of credentials which escalates to root, or to CAP_SYS_ADMIN or CAP_SYS_MODULE,
+// outside of a setuid exec and without the privileges to do so. The process context holds the previous credentials.
+type CredChangeEvent struct {
+	UID          uint32 `json:"uid"`
+	GID          uint32 `json:"gid"`
+	EUID         uint32 `json:"euid"`
+	EGID         uint32 `json:"egid"`
+	SUID         uint32 `json:"suid"`
+	FSUID        uint32 `json:"fsuid"`
+	CapPermitted uint64 `json:"cap_permitted"`
+	CapEffective uint64 `json:"cap_effective"`
+	// Reasons lists why the change is an escalation: "root" and / or "capability"
+	Reasons []string `json:"reasons"`
+	// GainedCapabilities lists the sensitive capabilities gained by the change
+	GainedCapabilities []string `json:"gained_capabilities,omitempty"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *CredChangeEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < 48 {
+		return 0, fmt.Errorf("while parsing CredChangeEvent, got len %d, needed %d: %w", len(data), 48, ErrNotEnoughData)
+	}
+	e.UID = ByteOrder.Uint32(data[0:4])
+	e.GID = ByteOrder.Uint32(data[4:8])
+	e.EUID = ByteOrder.Uint32(data[8:12])
+	e.EGID = ByteOrder.Uint32(data[12:16])
+	e.SUID = ByteOrder.Uint32(data[16:20])
+	e.FSUID = ByteOrder.Uint32(data[20:24])
+	e.CapPermitted = ByteOrder.Uint64(data[24:32])
+	e.CapEffective = ByteOrder.Uint64(data[32:40])
+	reason := ByteOrder.Uint32(data[40:44])
+
+	e.Reasons = nil
+	if reason&credChangeRoot != 0 {
+		e.Reasons = append(e.Reasons, "root")
+	}
+	if reason&credChangeCapability != 0 {
+		e.Reasons = append(e.Reasons, "capability")
+	}
+	return 48, nil
+}
+
+// resolveGainedCapabilities lists the sensitive capabilities of the new credentials missing from the previous ones
+func (e *CredChangeEvent) resolveGainedCapabilities(previous *CredentialsContext) {
+	e.GainedCapabilities = nil
+	gained := (e.CapPermitted | e.CapEffective) &^ previous.CapPermitted
+	if gained&(1<<capSysAdmin) != 0 {
+		e.GainedCapabilities = append(e.GainedCapabilities, "CAP_SYS_ADMIN")
+	}
+	if gained&(1<<capSysModule) != 0 {
+		e.GainedCapabilities = append(e.GainedCapabilities, "CAP_SYS_MODULE")
+	}
+}
+
+// CredChangeEventSerializer is used to serialize CredChangeEvent
+// easyjson:json
+type CredChangeEventSerializer struct {
+	*CredChangeEvent
+}
+
+// NewCredChangeEventSerializer returns a new instance of CredChangeEventSerializer
+func NewCredChangeEventSerializer(e *CredChangeEvent) *CredChangeEventSerializer {
+	return &CredChangeEventSerializer{
+		CredChangeEvent: e,
+	}
+}
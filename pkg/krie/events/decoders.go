@@ -0,0 +1,400 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"fmt"
+)
+
+// eventDecoder describes how the events of an event type are decoded and serialized
+type eventDecoder struct {
+	// decode unmarshalls the payload of the event, it is nil for the events generated in user space
+	decode func(e *Event, data []byte) (int, error)
+	// serialize attaches the serializer of the payload to the serializer of the event
+	serialize func(e *Event, s *EventSerializer)
+	// noProcessContext is set for the events that aren't triggered by a process
+	noProcessContext bool
+}
+
+var eventDecoders [MaxEventType]*eventDecoder
+
+// registerEventDecoder registers the decoder of one or more event types
+func registerEventDecoder(decoder *eventDecoder, eventTypes ...EventType) {
+	for _, eventType := range eventTypes {
+		if eventDecoders[eventType] != nil {
+			panic(fmt.Sprintf("event decoder of %s registered twice", eventType))
+		}
+		eventDecoders[eventType] = decoder
+	}
+}
+
+// getEventDecoder returns the decoder of the provided event type, or nil if the event type is unknown
+func getEventDecoder(eventType EventType) *eventDecoder {
+	if eventType >= MaxEventType {
+		return nil
+	}
+	return eventDecoders[eventType]
+}
+
+func init() {
+	// audit events
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.InitModule.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.InitModuleEventSerializer = NewInitModuleSerializer(&e.InitModule)
+		},
+	}, InitModuleEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.DeleteModule.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.DeleteModuleEventSerializer = NewDeleteModuleSerializer(&e.DeleteModule)
+		},
+	}, DeleteModuleEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.BPFEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.BPFEventSerializer = NewBPFEventSerializer(&e.BPFEvent)
+		},
+	}, BPFEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.BPFFilterEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.BPFFilterEventSerializer = NewBPFFilterEventSerializer(&e.BPFFilterEvent)
+		},
+	}, BPFFilterEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.PTraceEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.PtraceEventSerializer = NewPtraceEventSerializer(&e.PTraceEvent)
+		},
+	}, PTraceEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.KProbeEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.KProbeEventSerializer = NewKProbeEventSerializer(&e.KProbeEvent)
+		},
+	}, KProbeEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.SysCtlEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.SysCtlEventEventSerializer = NewSysCtlEventSerializer(&e.SysCtlEvent)
+		},
+	}, SysCtlEventType)
+
+	// krie events
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.HookedSyscallEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.HookedSyscallEventSerializer = NewHookedSyscallEventSerializer(&e.HookedSyscallEvent)
+		},
+	}, HookedSyscallEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.HookedSyscallEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.HookedSyscallEventSerializer = NewHookedSyscallEventSerializer(&e.HookedSyscallEvent)
+		},
+		noProcessContext: true,
+	}, HookedSyscallTableEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.EventCheckEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.EventCheckEventSerializer = NewEventCheckEventSerializer(&e.EventCheckEvent)
+		},
+	}, EventCheckEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.KernelParameterEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.KernelParameterEventSerializer = NewKernelParameterEventSerializer(&e.KernelParameterEvent)
+		},
+	}, KernelParameterEventType, PeriodicKernelParameterEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.RegisterCheckEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.RegisterCheckEventSerializer = NewRegisterCheckEventSerializer(&e.RegisterCheckEvent)
+		},
+	}, RegisterCheckEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.IntegritySwitchEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.IntegritySwitchEventSerializer = NewIntegritySwitchEventSerializer(&e.IntegritySwitchEvent)
+		},
+	}, IntegritySwitchEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.KexecEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.KexecEventSerializer = NewKexecEventSerializer(&e.KexecEvent)
+		},
+	}, KexecEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.ControlRegisterEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.ControlRegisterEventSerializer = NewControlRegisterEventSerializer(&e.ControlRegisterEvent)
+		},
+	}, ControlRegisterEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.FTraceHookEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.FTraceHookEventSerializer = NewFTraceHookEventSerializer(&e.FTraceHookEvent)
+		},
+	}, FTraceHookEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) {
+			read, err := e.CredChangeEvent.UnmarshallBinary(data)
+			if err == nil {
+				e.CredChangeEvent.resolveGainedCapabilities(&e.Process.Credentials)
+			}
+			return read, err
+		},
+		serialize: func(e *Event, s *EventSerializer) {
+			s.CredChangeEventSerializer = NewCredChangeEventSerializer(&e.CredChangeEvent)
+		},
+	}, CredChangeEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.LSMStateChangeEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.LSMStateChangeEventSerializer = NewLSMStateChangeEventSerializer(&e.LSMStateChangeEvent)
+		},
+	}, LSMStateChangeEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.IOUringEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.IOUringEventSerializer = NewIOUringEventSerializer(&e.IOUringEvent)
+		},
+	}, IOUringEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.PerfEventOpenEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.PerfEventOpenEventSerializer = NewPerfEventOpenEventSerializer(&e.PerfEventOpenEvent)
+		},
+	}, PerfEventOpenEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.RawMemoryAccessEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.RawMemoryAccessEventSerializer = NewRawMemoryAccessEventSerializer(&e.RawMemoryAccessEvent)
+		},
+	}, RawMemoryAccessEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.MSRWriteEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.MSRWriteEventSerializer = NewMSRWriteEventSerializer(&e.MSRWriteEvent)
+		},
+	}, MSRWriteEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.SymbolResolutionEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.SymbolResolutionEventSerializer = NewSymbolResolutionEventSerializer(&e.SymbolResolutionEvent)
+		},
+	}, SymbolResolutionEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.UsermodeHelperEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.UsermodeHelperEventSerializer = NewUsermodeHelperEventSerializer(&e.UsermodeHelperEvent)
+		},
+	}, UsermodeHelperEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.ProcessVMEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.ProcessVMEventSerializer = NewProcessVMEventSerializer(&e.ProcessVMEvent)
+		},
+	}, ProcessVMEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.MountEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.MountEventSerializer = NewMountEventSerializer(&e.MountEvent)
+		},
+	}, MountEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.PrctlEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.PrctlEventSerializer = NewPrctlEventSerializer(&e.PrctlEvent)
+		},
+	}, PrctlEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.CapsetEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.CapsetEventSerializer = NewCapsetEventSerializer(&e.CapsetEvent)
+		},
+	}, CapsetEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.BPFMapTamperEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.BPFMapTamperEventSerializer = NewBPFMapTamperEventSerializer(&e.BPFMapTamperEvent)
+		},
+	}, BPFMapTamperEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.TracefsProbeEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.TracefsProbeEventSerializer = NewTracefsProbeEventSerializer(&e.TracefsProbeEvent)
+		},
+	}, TracefsProbeEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.NetfilterHookEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.NetfilterHookEventSerializer = NewNetfilterHookEventSerializer(&e.NetfilterHookEvent)
+		},
+	}, NetfilterHookEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.KernelLogTamperEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.KernelLogTamperEventSerializer = NewKernelLogTamperEventSerializer(&e.KernelLogTamperEvent)
+		},
+	}, KernelLogTamperEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.KeyringEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.KeyringEventSerializer = NewKeyringEventSerializer(&e.KeyringEvent)
+		},
+	}, KeyringEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) {
+			read, err := e.NamespaceEvent.UnmarshallBinary(data)
+			if err == nil {
+				e.NamespaceEvent.resolveChanges(&e.Process.NamespaceContext)
+			}
+			return read, err
+		},
+		serialize: func(e *Event, s *EventSerializer) {
+			s.NamespaceEventSerializer = NewNamespaceEventSerializer(&e.NamespaceEvent)
+		},
+	}, NamespaceEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.UserfaultfdEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.UserfaultfdEventSerializer = NewUserfaultfdEventSerializer(&e.UserfaultfdEvent)
+		},
+	}, UserfaultfdEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.BinfmtMiscEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.BinfmtMiscEventSerializer = NewBinfmtMiscEventSerializer(&e.BinfmtMiscEvent)
+		},
+	}, BinfmtMiscEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.ModuleSignatureEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.ModuleSignatureEventSerializer = NewModuleSignatureEventSerializer(&e.ModuleSignatureEvent)
+		},
+	}, ModuleSignatureEventType)
+	registerEventDecoder(&eventDecoder{
+		decode: func(e *Event, data []byte) (int, error) { return e.RebootEvent.UnmarshallBinary(data) },
+		serialize: func(e *Event, s *EventSerializer) {
+			s.RebootEventSerializer = NewRebootEventSerializer(&e.RebootEvent)
+		},
+	}, RebootEventType)
+
+	// user space events
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.ResponseEventSerializer = NewResponseEventSerializer(&e.ResponseEvent)
+		},
+		noProcessContext: true,
+	}, ResponseEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.PolicyChangeEventSerializer = NewPolicyChangeEventSerializer(&e.PolicyChangeEvent)
+		},
+		noProcessContext: true,
+	}, PolicyChangeEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.RawEventSerializer = NewRawEventSerializer(&e.RawEvent)
+		},
+		noProcessContext: true,
+	}, RawEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.ClockSkewEventSerializer = NewClockSkewEventSerializer(&e.ClockSkewEvent)
+		},
+		noProcessContext: true,
+	}, ClockSkewEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.SilenceEventSerializer = NewSilenceEventSerializer(&e.SilenceEvent)
+		},
+		noProcessContext: true,
+	}, SilenceEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.TailCallTamperEventSerializer = NewTailCallTamperEventSerializer(&e.TailCallTamperEvent)
+		},
+		noProcessContext: true,
+	}, TailCallTamperEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.SysCtlDriftEventSerializer = NewSysCtlDriftEventSerializer(&e.SysCtlDriftEvent)
+		},
+		noProcessContext: true,
+	}, SysCtlDriftEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.CoverageChangeEventSerializer = NewCoverageChangeEventSerializer(&e.CoverageChangeEvent)
+		},
+		noProcessContext: true,
+	}, CoverageChangeEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.ProcessExitEventSerializer = NewProcessExitEventSerializer(&e.ProcessExitEvent)
+		},
+	}, ProcessExitEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.EnrichmentEventSerializer = NewEnrichmentEventSerializer(&e.EnrichmentEvent)
+		},
+		noProcessContext: true,
+	}, EnrichmentEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.BPFProgramUnloadEventSerializer = NewBPFProgramUnloadEventSerializer(&e.BPFProgramUnloadEvent)
+		},
+	}, BPFProgramUnloadEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.ModuleTaintChangeEventSerializer = NewModuleTaintChangeEventSerializer(&e.ModuleTaintChangeEvent)
+		},
+		noProcessContext: true,
+	}, ModuleTaintChangeEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.ProfileSnapshotEventSerializer = NewProfileSnapshotEventSerializer(&e.ProfileSnapshotEvent)
+		},
+		noProcessContext: true,
+	}, ProfileSnapshotEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.UpgradeEventSerializer = NewUpgradeEventSerializer(&e.UpgradeEvent)
+		},
+		noProcessContext: true,
+	}, UpgradeEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.SyscallOrphansEventSerializer = NewSyscallOrphansEventSerializer(&e.SyscallOrphansEvent)
+		},
+		noProcessContext: true,
+	}, SyscallOrphansEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.HiddenProcessEventSerializer = NewHiddenProcessEventSerializer(&e.HiddenProcessEvent)
+		},
+		noProcessContext: true,
+	}, HiddenProcessEventType)
+	registerEventDecoder(&eventDecoder{
+		serialize: func(e *Event, s *EventSerializer) {
+			s.AnnotationEventSerializer = NewAnnotationEventSerializer(&e.AnnotationEvent)
+		},
+		noProcessContext: true,
+	}, AnnotationEventType)
+}
@@ -0,0 +1,34 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventDecoders(t *testing.T) {
+	for eventType := UnknownEventType + 1; eventType < MaxEventType; eventType++ {
+		decoder := getEventDecoder(eventType)
+		if assert.NotNil(t, decoder, "missing decoder for %s", eventType) {
+			assert.NotNil(t, decoder.serialize, "missing serializer for %s", eventType)
+		}
+	}
+	assert.Nil(t, getEventDecoder(UnknownEventType))
+	assert.Nil(t, getEventDecoder(MaxEventType))
+}
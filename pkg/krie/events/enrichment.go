@@ -0,0 +1,43 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+package events
+
+// EnrichmentEvent is the follow-up record of an event whose expensive enrichments (container lookup, symbolization,
+// module inspection) were deferred: it is sent once the enrichments complete, and is keyed by the ID of the event
+type EnrichmentEvent struct {
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	// Enrichments lists the enrichments that completed
+	Enrichments []string `json:"enrichments"`
+	// Fields are the enriched fields, indexed by their path in the JSON representation of the event (for example
+	// "process.container.image" or "ftrace_hook.callback.symbol")
+	Fields map[string]string `json:"fields"`
+}
+
+// EnrichmentEventSerializer is used to serialize EnrichmentEvent
+// easyjson:json
+type EnrichmentEventSerializer struct {
+	*EnrichmentEvent
+}
+
+// NewEnrichmentEventSerializer returns a new instance of EnrichmentEventSerializer
+func NewEnrichmentEventSerializer(e *EnrichmentEvent) *EnrichmentEventSerializer {
+	return &EnrichmentEventSerializer{
+		EnrichmentEvent: e,
+	}
+}
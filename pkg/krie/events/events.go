@@ -14,16 +14,16 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers $GOFILE
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
 
 package events
 
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	manager "github.com/DataDog/ebpf-manager"
-	"github.com/mailru/easyjson/jwriter"
 	"golang.org/x/sys/unix"
 	"gopkg.in/yaml.v3"
 )
@@ -46,27 +46,139 @@ type Options struct {
 	HookedSyscallEvent      Action                  `yaml:"hooked_syscall"`
 	KernelParameterEvent    *KernelParameterOptions `yaml:"kernel_parameter"`
 	RegisterCheckEvent      Action                  `yaml:"register_check"`
+	IntegritySwitchEvent    Action                  `yaml:"integrity_switch"`
+	KexecEvent              Action                  `yaml:"kexec"`
+	ControlRegisterEvent    Action                  `yaml:"control_register"`
+	FTraceHookEvent         Action                  `yaml:"ftrace_hook"`
+	CredChangeEvent         Action                  `yaml:"cred_change"`
+	LSMStateChangeEvent     Action                  `yaml:"lsm_state_change"`
+	IOUringEvent            Action                  `yaml:"io_uring"`
+	PerfEventOpenEvent      Action                  `yaml:"perf_event_open"`
+	RawMemoryAccessEvent    Action                  `yaml:"raw_memory_access"`
+	MSRWriteEvent           Action                  `yaml:"msr_write"`
+	SymbolResolutionEvent   Action                  `yaml:"symbol_resolution"`
+	UsermodeHelperEvent     Action                  `yaml:"usermode_helper"`
+	ProcessVMEvent          Action                  `yaml:"process_vm"`
+	MountEvent              Action                  `yaml:"mount"`
+	PrctlEvent              Action                  `yaml:"prctl"`
+	CapsetEvent             Action                  `yaml:"capset"`
+	BPFMapTamperEvent       Action                  `yaml:"bpf_map_tamper"`
+	TracefsProbeEvent       Action                  `yaml:"tracefs_probe"`
+	NetfilterHookEvent      Action                  `yaml:"netfilter_hook"`
+	KernelLogTamperEvent    Action                  `yaml:"kernel_log_tamper"`
+	KeyringEvent            Action                  `yaml:"keyring"`
+	NamespaceEvent          Action                  `yaml:"namespace"`
+	UserfaultfdEvent        Action                  `yaml:"userfaultfd"`
+	BinfmtMiscEvent         Action                  `yaml:"binfmt_misc"`
+	ModuleSignatureEvent    *ModuleSignatureOptions `yaml:"module_signature"`
+	RebootEvent             Action                  `yaml:"reboot"`
+	ModuleSigners           *ModuleSignerOptions    `yaml:"module_signers"`
+	ContainerImages         *ContainerImageOptions  `yaml:"container_images"`
+	Modes                   map[string]RuleMode     `yaml:"modes"`
+	Confidences             map[string]Confidence   `yaml:"confidence"`
 
 	eventsAction    map[EventType]Action `yaml:"-"`
 	activatedEvents EventTypeList        `yaml:"-"`
+	enforceable     map[string]bool      `yaml:"-"`
+}
+
+// configuredEventsActions returns the configured action of each event type, regardless of the rule modes
+func (o *Options) configuredEventsActions() map[EventType]Action {
+	out := make(map[EventType]Action)
+	for eventType, action := range o.eventsActionFields() {
+		out[eventType] = *action
+	}
+	return out
+}
+
+// eventsActionFields returns the field holding the configured action of each event type
+func (o *Options) eventsActionFields() map[EventType]*Action {
+	return map[EventType]*Action{
+		InitModuleEventType:              &o.InitModuleEvent,
+		DeleteModuleEventType:            &o.DeleteModuleEvent,
+		BPFEventType:                     &o.BPFEvent,
+		BPFFilterEventType:               &o.BPFFilterEvent,
+		PTraceEventType:                  &o.PTraceEvent,
+		KProbeEventType:                  &o.KProbeEvent,
+		SysCtlEventType:                  &o.SysCtlEvent.Action,
+		HookedSyscallTableEventType:      &o.HookedSyscallTableEvent,
+		HookedSyscallEventType:           &o.HookedSyscallEvent,
+		KernelParameterEventType:         &o.KernelParameterEvent.Action,
+		PeriodicKernelParameterEventType: &o.KernelParameterEvent.PeriodicAction,
+		RegisterCheckEventType:           &o.RegisterCheckEvent,
+		IntegritySwitchEventType:         &o.IntegritySwitchEvent,
+		KexecEventType:                   &o.KexecEvent,
+		ControlRegisterEventType:         &o.ControlRegisterEvent,
+		FTraceHookEventType:              &o.FTraceHookEvent,
+		CredChangeEventType:              &o.CredChangeEvent,
+		LSMStateChangeEventType:          &o.LSMStateChangeEvent,
+		IOUringEventType:                 &o.IOUringEvent,
+		PerfEventOpenEventType:           &o.PerfEventOpenEvent,
+		RawMemoryAccessEventType:         &o.RawMemoryAccessEvent,
+		MSRWriteEventType:                &o.MSRWriteEvent,
+		SymbolResolutionEventType:        &o.SymbolResolutionEvent,
+		UsermodeHelperEventType:          &o.UsermodeHelperEvent,
+		ProcessVMEventType:               &o.ProcessVMEvent,
+		MountEventType:                   &o.MountEvent,
+		PrctlEventType:                   &o.PrctlEvent,
+		CapsetEventType:                  &o.CapsetEvent,
+		BPFMapTamperEventType:            &o.BPFMapTamperEvent,
+		TracefsProbeEventType:            &o.TracefsProbeEvent,
+		NetfilterHookEventType:           &o.NetfilterHookEvent,
+		KernelLogTamperEventType:         &o.KernelLogTamperEvent,
+		KeyringEventType:                 &o.KeyringEvent,
+		NamespaceEventType:               &o.NamespaceEvent,
+		UserfaultfdEventType:             &o.UserfaultfdEvent,
+		BinfmtMiscEventType:              &o.BinfmtMiscEvent,
+		ModuleSignatureEventType:         &o.ModuleSignatureEvent.Action,
+		RebootEventType:                  &o.RebootEvent,
+	}
+}
+
+// WithEventTypes returns a copy of the options restricted to the provided event types: the other event types are
+// disabled, and the provided event types are switched to monitor mode, so that they log without blocking nor killing.
+// The provided event types disabled by the options only log.
+func (o *Options) WithEventTypes(eventTypes EventTypeList) *Options {
+	out := *o
+	sysctl := *o.SysCtlEvent
+	out.SysCtlEvent = &sysctl
+	kernelParameter := *o.KernelParameterEvent
+	out.KernelParameterEvent = &kernelParameter
+	signers := *o.ModuleSigners
+	out.ModuleSigners = &signers
+	images := *o.ContainerImages
+	out.ContainerImages = &images
+	moduleSignature := *o.ModuleSignatureEvent
+	out.ModuleSignatureEvent = &moduleSignature
+
+	out.Modes = make(map[string]RuleMode)
+	for eventType, action := range out.eventsActionFields() {
+		if !eventTypes.Contains(eventType) {
+			*action = NopAction
+			continue
+		}
+		if *action < LogAction {
+			*action = LogAction
+		}
+		out.Modes[eventType.String()] = MonitorMode
+	}
+	if !eventTypes.Contains(InitModuleEventType) {
+		out.ModuleSigners.Action = NopAction
+		out.ContainerImages.Action = NopAction
+	}
+	out.eventsAction = make(map[EventType]Action)
+	out.activatedEvents = nil
+	out.enforceable = nil
+	return &out
 }
 
 func (o *Options) ParseEventsActions() map[EventType]Action {
 	if len(o.eventsAction) == 0 {
-		for eventType, action := range map[EventType]Action{
-			InitModuleEventType:              o.InitModuleEvent,
-			DeleteModuleEventType:            o.DeleteModuleEvent,
-			BPFEventType:                     o.BPFEvent,
-			BPFFilterEventType:               o.BPFFilterEvent,
-			PTraceEventType:                  o.PTraceEvent,
-			KProbeEventType:                  o.KProbeEvent,
-			SysCtlEventType:                  o.SysCtlEvent.Action,
-			HookedSyscallTableEventType:      o.HookedSyscallTableEvent,
-			HookedSyscallEventType:           o.HookedSyscallEvent,
-			KernelParameterEventType:         o.KernelParameterEvent.Action,
-			PeriodicKernelParameterEventType: o.KernelParameterEvent.PeriodicAction,
-			RegisterCheckEventType:           o.RegisterCheckEvent,
-		} {
+		for eventType, action := range o.configuredEventsActions() {
+			// the rules in monitor mode only log
+			if action > LogAction && o.IsMonitored(eventType.String()) {
+				action = LogAction
+			}
 			o.eventsAction[eventType] = action
 		}
 	}
@@ -85,9 +197,28 @@ func (o *Options) ActivatedEventTypes() EventTypeList {
 }
 
 func (o *Options) IsValid() error {
+	if err := o.SysCtlEvent.IsValid(); err != nil {
+		return fmt.Errorf("invalid sysctl section: %w", err)
+	}
 	if err := o.KernelParameterEvent.IsValid(); err != nil {
 		return fmt.Errorf("invalid kernel_parameter section: %w", err)
 	}
+	if err := o.ModuleSigners.IsValid(); err != nil {
+		return fmt.Errorf("invalid module_signers section: %w", err)
+	}
+	if err := o.ContainerImages.IsValid(); err != nil {
+		return fmt.Errorf("invalid container_images section: %w", err)
+	}
+	for rule, mode := range o.Modes {
+		if mode != EnforceMode && mode != MonitorMode {
+			return fmt.Errorf("invalid mode for rule %s: %q", rule, mode)
+		}
+	}
+	for rule, confidence := range o.Confidences {
+		if err := confidence.IsValid(); err != nil {
+			return fmt.Errorf("invalid confidence for rule %s: %w", rule, err)
+		}
+	}
 
 	if o.HookedSyscallTableEvent == BlockAction || o.HookedSyscallTableEvent == KillAction {
 		return fmt.Errorf("hooked_syscall_table cannot be set to \"block\" or \"kill\"")
@@ -101,6 +232,9 @@ func NewEventsOptions() *Options {
 		eventsAction:         make(map[EventType]Action),
 		SysCtlEvent:          NewSysCtlOptions(),
 		KernelParameterEvent: NewKernelParameterOptions(),
+		ModuleSigners:        NewModuleSignerOptions(),
+		ContainerImages:      NewContainerImageOptions(),
+		ModuleSignatureEvent: NewModuleSignatureOptions(),
 	}
 }
 
@@ -136,6 +270,94 @@ const (
 	PeriodicKernelParameterEventType
 	// RegisterCheckEventType is the event type of a register_check event
 	RegisterCheckEventType
+	// ResponseEventType is the event type of a response event
+	ResponseEventType
+	// PolicyChangeEventType is the event type of a policy_change event
+	PolicyChangeEventType
+	// RawEventType is the event type of a raw event
+	RawEventType
+	// ClockSkewEventType is the event type of the diagnostic events of the implausible kernel timestamps
+	ClockSkewEventType
+	// SilenceEventType is the event type of the diagnostic events of the silent event types
+	SilenceEventType
+	// TailCallTamperEventType is the event type of a tail_call_tamper event
+	TailCallTamperEventType
+	// IntegritySwitchEventType is the event type of a write to an integrity enforcement switch
+	IntegritySwitchEventType
+	// SysCtlDriftEventType is the event type of a sysctl_drift event
+	SysCtlDriftEventType
+	// KexecEventType is the event type of a kexec_load or kexec_file_load event
+	KexecEventType
+	// CoverageChangeEventType is the event type of a change of the probes coverage
+	CoverageChangeEventType
+	// ControlRegisterEventType is the event type of a change of the protection bits of a control register (CR0.WP, CR4.SMEP, CR4.SMAP)
+	ControlRegisterEventType
+	// ProcessExitEventType is the event type of the exit summary of a process which triggered events
+	ProcessExitEventType
+	// FTraceHookEventType is the event type of the registration of an ftrace callback located outside of the kernel text
+	FTraceHookEventType
+	// CredChangeEventType is the event type of a credentials change escalating to root or to sensitive capabilities outside of an exec
+	CredChangeEventType
+	// LSMStateChangeEventType is the event type of a change weakening the enforcement of an LSM (SELinux permissive or disabled, AppArmor profile removed)
+	LSMStateChangeEventType
+	// EnrichmentEventType is the event type of the result of the deferred enrichments of an event, sent after the event
+	EnrichmentEventType
+	// IOUringEventType is the event type of an io_uring instance setup or an io_uring_register call
+	IOUringEventType
+	// BPFProgramUnloadEventType is the event type of the unload of a BPF program tracked by the BPF lifecycle tracker
+	BPFProgramUnloadEventType
+	// PerfEventOpenEventType is the event type of a perf_event_open call
+	PerfEventOpenEventType
+	// RawMemoryAccessEventType is the event type of an access to /dev/mem, /dev/kmem or /dev/port
+	RawMemoryAccessEventType
+	// ModuleTaintChangeEventType is the event type of a change of the taint flags of a module tracked by the module lifecycle tracker
+	ModuleTaintChangeEventType
+	// MSRWriteEventType is the event type of a write to a model specific register
+	MSRWriteEventType
+	// SymbolResolutionEventType is the event type of a resolution of a kernel symbol through kallsyms_lookup_name or /proc/kallsyms
+	SymbolResolutionEventType
+	// UsermodeHelperEventType is the event type of a change or an execution of a usermode helper (modprobe, core_pattern)
+	UsermodeHelperEventType
+	// ProcessVMEventType is the event type of a process_vm_readv or process_vm_writev call
+	ProcessVMEventType
+	// MountEventType is the event type of a mount of a sensitive file system (debugfs, tracefs, securityfs, bpffs, procfs)
+	MountEventType
+	// PrctlEventType is the event type of a change of the sandbox of a process (seccomp, dumpable, ptracer)
+	PrctlEventType
+	// ProfileSnapshotEventType is the event type of the diagnostic events of the profiles written on resource thresholds
+	ProfileSnapshotEventType
+	// CapsetEventType is the event type of a capset call granting CAP_SYS_MODULE, CAP_SYS_RAWIO, CAP_PERFMON or CAP_BPF
+	CapsetEventType
+	// BPFMapTamperEventType is the event type of a write to a BPF map created by another process
+	BPFMapTamperEventType
+	// TracefsProbeEventType is the event type of a write to kprobe_events, uprobe_events or dynamic_events in tracefs
+	TracefsProbeEventType
+	// NetfilterHookEventType is the event type of a registration of a netfilter hook
+	NetfilterHookEventType
+	// KernelLogTamperEventType is the event type of a clear of the kernel log or of a console silencing
+	KernelLogTamperEventType
+	// UpgradeEventType is the event type of a takeover of a running KRIE instance by a new instance
+	UpgradeEventType
+	// KeyringEventType is the event type of an add_key, request_key or keyctl call
+	KeyringEventType
+	// NamespaceEventType is the event type of a setns or an unshare call
+	NamespaceEventType
+	// SyscallOrphansEventType is the event type of the diagnostic events of the syscall entries and exits which
+	// couldn't be correlated
+	SyscallOrphansEventType
+	// UserfaultfdEventType is the event type of a userfaultfd call or of a UFFDIO_API handshake
+	UserfaultfdEventType
+	// BinfmtMiscEventType is the event type of a binfmt_misc registration
+	BinfmtMiscEventType
+	// ModuleSignatureEventType is the event type of the load of a module without a valid signature, or of a change of
+	// module.sig_enforce
+	ModuleSignatureEventType
+	// RebootEventType is the event type of a reboot call
+	RebootEventType
+	// HiddenProcessEventType is the event type of a process known to the kernel but hidden from /proc
+	HiddenProcessEventType
+	// AnnotationEventType is the event type of the triage note of an analyst on an event
+	AnnotationEventType
 	// MaxEventType is used internally to get the maximum number of events.
 	MaxEventType
 )
@@ -168,6 +390,92 @@ func (t EventType) String() string {
 		return "periodic_kernel_parameter"
 	case RegisterCheckEventType:
 		return "register_check"
+	case ResponseEventType:
+		return "response"
+	case PolicyChangeEventType:
+		return "policy_change"
+	case RawEventType:
+		return "raw"
+	case ClockSkewEventType:
+		return "clock_skew"
+	case SilenceEventType:
+		return "silence"
+	case TailCallTamperEventType:
+		return "tail_call_tamper"
+	case IntegritySwitchEventType:
+		return "integrity_switch"
+	case SysCtlDriftEventType:
+		return "sysctl_drift"
+	case KexecEventType:
+		return "kexec"
+	case CoverageChangeEventType:
+		return "coverage_change"
+	case ControlRegisterEventType:
+		return "control_register"
+	case ProcessExitEventType:
+		return "process_exit"
+	case FTraceHookEventType:
+		return "ftrace_hook"
+	case CredChangeEventType:
+		return "cred_change"
+	case LSMStateChangeEventType:
+		return "lsm_state_change"
+	case EnrichmentEventType:
+		return "enrichment"
+	case IOUringEventType:
+		return "io_uring"
+	case BPFProgramUnloadEventType:
+		return "bpf_program_unload"
+	case PerfEventOpenEventType:
+		return "perf_event_open"
+	case RawMemoryAccessEventType:
+		return "raw_memory_access"
+	case ModuleTaintChangeEventType:
+		return "module_taint_change"
+	case MSRWriteEventType:
+		return "msr_write"
+	case SymbolResolutionEventType:
+		return "symbol_resolution"
+	case UsermodeHelperEventType:
+		return "usermode_helper"
+	case ProcessVMEventType:
+		return "process_vm"
+	case MountEventType:
+		return "mount"
+	case PrctlEventType:
+		return "prctl"
+	case ProfileSnapshotEventType:
+		return "profile_snapshot"
+	case CapsetEventType:
+		return "capset"
+	case BPFMapTamperEventType:
+		return "bpf_map_tamper"
+	case TracefsProbeEventType:
+		return "tracefs_probe"
+	case NetfilterHookEventType:
+		return "netfilter_hook"
+	case KernelLogTamperEventType:
+		return "kernel_log_tamper"
+	case UpgradeEventType:
+		return "upgrade"
+	case KeyringEventType:
+		return "keyring"
+	case NamespaceEventType:
+		return "namespace"
+	case SyscallOrphansEventType:
+		return "syscall_orphans"
+	case UserfaultfdEventType:
+		return "userfaultfd"
+	case BinfmtMiscEventType:
+		return "binfmt_misc"
+	case ModuleSignatureEventType:
+		return "module_signature"
+	case RebootEventType:
+		return "reboot"
+	case HiddenProcessEventType:
+		return "hidden_process"
+	case AnnotationEventType:
+		return "annotation"
 	default:
 		return fmt.Sprintf("EventType(%d)", t)
 	}
@@ -273,6 +581,11 @@ func AllExcludedFunctions() []string {
 			"perf_event_syscall_table_ticker",
 		}...)
 	}
+	if !IsBPFTaskIteratorAvailable() {
+		excluded = append(excluded, []string{
+			TaskIteratorProbe.EBPFFuncName,
+		}...)
+	}
 
 	return excluded
 }
@@ -308,12 +621,87 @@ func AllProbesSelectors(events EventTypeList) []manager.ProbesSelector {
 	if events.Contains(PTraceEventType) {
 		addPTraceSelectors(&all)
 	}
+	if events.Contains(KexecEventType) {
+		addKexecSelectors(&all)
+	}
 	if events.Contains(KProbeEventType) {
 		addKProbeSelectors(&all)
 	}
 	if events.Contains(SysCtlEventType) {
 		addSysCtlSelectors(&all)
 	}
+	if events.Contains(IntegritySwitchEventType) {
+		addIntegritySwitchSelectors(&all)
+	}
+	if events.Contains(ControlRegisterEventType) {
+		addControlRegisterSelectors(&all)
+	}
+	if events.Contains(FTraceHookEventType) {
+		addFTraceHookSelectors(&all)
+	}
+	if events.Contains(CredChangeEventType) {
+		addCredChangeSelectors(&all)
+	}
+	if events.Contains(LSMStateChangeEventType) {
+		addLSMStateChangeSelectors(&all)
+	}
+	if events.Contains(IOUringEventType) {
+		addIOUringSelectors(&all)
+	}
+	if events.Contains(PerfEventOpenEventType) {
+		addPerfEventOpenSelectors(&all)
+	}
+	if events.Contains(RawMemoryAccessEventType) {
+		addRawMemoryAccessSelectors(&all)
+	}
+	if events.Contains(MSRWriteEventType) {
+		addMSRWriteSelectors(&all)
+	}
+	if events.Contains(SymbolResolutionEventType) {
+		addSymbolResolutionSelectors(&all)
+	}
+	if events.Contains(UsermodeHelperEventType) {
+		addUsermodeHelperSelectors(&all)
+	}
+	if events.Contains(ProcessVMEventType) {
+		addProcessVMSelectors(&all)
+	}
+	if events.Contains(MountEventType) {
+		addMountSelectors(&all)
+	}
+	if events.Contains(PrctlEventType) {
+		addPrctlSelectors(&all)
+	}
+	if events.Contains(CapsetEventType) {
+		addCapsetSelectors(&all)
+	}
+	if events.Contains(TracefsProbeEventType) {
+		addTracefsProbeSelectors(&all)
+	}
+	if events.Contains(NetfilterHookEventType) {
+		addNetfilterHookSelectors(&all)
+	}
+	if events.Contains(KernelLogTamperEventType) {
+		addKernelLogTamperSelectors(&all)
+	}
+	if events.Contains(KeyringEventType) {
+		addKeyringSelectors(&all)
+	}
+	if events.Contains(NamespaceEventType) {
+		addNamespaceSelectors(&all)
+	}
+	if events.Contains(UserfaultfdEventType) {
+		addUserfaultfdSelectors(&all)
+	}
+	if events.Contains(BinfmtMiscEventType) {
+		addBinfmtMiscSelectors(&all)
+	}
+	if events.Contains(ModuleSignatureEventType) {
+		addModuleSignatureSelectors(&all)
+	}
+	if events.Contains(RebootEventType) {
+		addRebootSelectors(&all)
+	}
 	return all
 }
 
@@ -379,12 +767,87 @@ func AllProbes(events EventTypeList) []*manager.Probe {
 	if events.Contains(PTraceEventType) {
 		addPTraceProbes(&all)
 	}
+	if events.Contains(KexecEventType) {
+		addKexecProbes(&all)
+	}
 	if events.Contains(KProbeEventType) {
 		addKProbeProbes(&all)
 	}
 	if events.Contains(SysCtlEventType) {
 		addSysCtlProbes(&all)
 	}
+	if events.Contains(IntegritySwitchEventType) {
+		addIntegritySwitchProbes(&all)
+	}
+	if events.Contains(ControlRegisterEventType) {
+		addControlRegisterProbes(&all)
+	}
+	if events.Contains(FTraceHookEventType) {
+		addFTraceHookProbes(&all)
+	}
+	if events.Contains(CredChangeEventType) {
+		addCredChangeProbes(&all)
+	}
+	if events.Contains(LSMStateChangeEventType) {
+		addLSMStateChangeProbes(&all)
+	}
+	if events.Contains(IOUringEventType) {
+		addIOUringProbes(&all)
+	}
+	if events.Contains(PerfEventOpenEventType) {
+		addPerfEventOpenProbes(&all)
+	}
+	if events.Contains(RawMemoryAccessEventType) {
+		addRawMemoryAccessProbes(&all)
+	}
+	if events.Contains(MSRWriteEventType) {
+		addMSRWriteProbes(&all)
+	}
+	if events.Contains(SymbolResolutionEventType) {
+		addSymbolResolutionProbes(&all)
+	}
+	if events.Contains(UsermodeHelperEventType) {
+		addUsermodeHelperProbes(&all)
+	}
+	if events.Contains(ProcessVMEventType) {
+		addProcessVMProbes(&all)
+	}
+	if events.Contains(MountEventType) {
+		addMountProbes(&all)
+	}
+	if events.Contains(PrctlEventType) {
+		addPrctlProbes(&all)
+	}
+	if events.Contains(CapsetEventType) {
+		addCapsetProbes(&all)
+	}
+	if events.Contains(TracefsProbeEventType) {
+		addTracefsProbeProbes(&all)
+	}
+	if events.Contains(NetfilterHookEventType) {
+		addNetfilterHookProbes(&all)
+	}
+	if events.Contains(KernelLogTamperEventType) {
+		addKernelLogTamperProbes(&all)
+	}
+	if events.Contains(KeyringEventType) {
+		addKeyringProbes(&all)
+	}
+	if events.Contains(NamespaceEventType) {
+		addNamespaceProbes(&all)
+	}
+	if events.Contains(UserfaultfdEventType) {
+		addUserfaultfdProbes(&all)
+	}
+	if events.Contains(BinfmtMiscEventType) {
+		addBinfmtMiscProbes(&all)
+	}
+	if events.Contains(ModuleSignatureEventType) {
+		addModuleSignatureProbes(&all)
+	}
+	if events.Contains(RebootEventType) {
+		addRebootProbes(&all)
+	}
 
 	return all
 }
@@ -420,6 +883,39 @@ func AllTailCallRoutes(events EventTypeList) []manager.TailCallRoute {
 	if events.Contains(PTraceEventType) {
 		addPTraceRoutes(&all)
 	}
+	if events.Contains(KexecEventType) {
+		addKexecRoutes(&all)
+	}
+	if events.Contains(IOUringEventType) {
+		addIOUringRoutes(&all)
+	}
+	if events.Contains(PerfEventOpenEventType) {
+		addPerfEventOpenRoutes(&all)
+	}
+	if events.Contains(ProcessVMEventType) {
+		addProcessVMRoutes(&all)
+	}
+	if events.Contains(MountEventType) {
+		addMountRoutes(&all)
+	}
+	if events.Contains(PrctlEventType) {
+		addPrctlRoutes(&all)
+	}
+	if events.Contains(CapsetEventType) {
+		addCapsetRoutes(&all)
+	}
+	if events.Contains(KernelLogTamperEventType) {
+		addKernelLogTamperRoutes(&all)
+	}
+	if events.Contains(KeyringEventType) {
+		addKeyringRoutes(&all)
+	}
+	if events.Contains(NamespaceEventType) {
+		addNamespaceRoutes(&all)
+	}
+	if events.Contains(UserfaultfdEventType) {
+		addUserfaultfdRoutes(&all)
+	}
 	if events.Contains(KProbeEventType) {
 		addKProbeRoutes(&all)
 	}
@@ -431,6 +927,7 @@ func AllTailCallRoutes(events EventTypeList) []manager.TailCallRoute {
 
 // Event is used to parse the events sent from kernel space
 type Event struct {
+	ID      string
 	Kernel  KernelEvent
 	Process ProcessContext
 
@@ -444,10 +941,75 @@ type Event struct {
 	SysCtlEvent    SysCtlEvent
 
 	// krie events
-	HookedSyscallEvent   HookedSyscallEvent
-	EventCheckEvent      EventCheckEvent
-	KernelParameterEvent KernelParameterEvent
-	RegisterCheckEvent   RegisterCheckEvent
+	HookedSyscallEvent     HookedSyscallEvent
+	EventCheckEvent        EventCheckEvent
+	KernelParameterEvent   KernelParameterEvent
+	RegisterCheckEvent     RegisterCheckEvent
+	ResponseEvent          ResponseEvent
+	PolicyChangeEvent      PolicyChangeEvent
+	RawEvent               RawEvent
+	ClockSkewEvent         ClockSkewEvent
+	SilenceEvent           SilenceEvent
+	TailCallTamperEvent    TailCallTamperEvent
+	IntegritySwitchEvent   IntegritySwitchEvent
+	SysCtlDriftEvent       SysCtlDriftEvent
+	KexecEvent             KexecEvent
+	CoverageChangeEvent    CoverageChangeEvent
+	ControlRegisterEvent   ControlRegisterEvent
+	ProcessExitEvent       ProcessExitEvent
+	FTraceHookEvent        FTraceHookEvent
+	CredChangeEvent        CredChangeEvent
+	LSMStateChangeEvent    LSMStateChangeEvent
+	EnrichmentEvent        EnrichmentEvent
+	IOUringEvent           IOUringEvent
+	BPFProgramUnloadEvent  BPFProgramUnloadEvent
+	PerfEventOpenEvent     PerfEventOpenEvent
+	RawMemoryAccessEvent   RawMemoryAccessEvent
+	ModuleTaintChangeEvent ModuleTaintChangeEvent
+	MSRWriteEvent          MSRWriteEvent
+	SymbolResolutionEvent  SymbolResolutionEvent
+	UsermodeHelperEvent    UsermodeHelperEvent
+	ProcessVMEvent         ProcessVMEvent
+	MountEvent             MountEvent
+	PrctlEvent             PrctlEvent
+	ProfileSnapshotEvent   ProfileSnapshotEvent
+	CapsetEvent            CapsetEvent
+	BPFMapTamperEvent      BPFMapTamperEvent
+	TracefsProbeEvent      TracefsProbeEvent
+	NetfilterHookEvent     NetfilterHookEvent
+	KernelLogTamperEvent   KernelLogTamperEvent
+	UpgradeEvent           UpgradeEvent
+	KeyringEvent           KeyringEvent
+	NamespaceEvent         NamespaceEvent
+	SyscallOrphansEvent    SyscallOrphansEvent
+	UserfaultfdEvent       UserfaultfdEvent
+	BinfmtMiscEvent        BinfmtMiscEvent
+	ModuleSignatureEvent   ModuleSignatureEvent
+	RebootEvent            RebootEvent
+	HiddenProcessEvent     HiddenProcessEvent
+	AnnotationEvent        AnnotationEvent
+
+	// ModuleLifecycle links the event to the other events of the kernel module it is attributed to
+	ModuleLifecycle *ModuleLifecycle
+	// Anomaly is the rarity of the event on the host, it is only set when the anomaly detector is enabled
+	Anomaly *Anomaly
+	// Intel are the values of the event found in the threat intel feeds
+	Intel []IntelMatch
+	// Failure is the error returned by the monitored syscall, it is only set when the syscall failed
+	Failure *Failure
+	// Redacted lists the fields that were redacted
+	Redacted []string
+	// Labels are the static labels of the host
+	Labels map[string]string
+	// Policy is the policy that produced the event
+	Policy PolicyContext
+	// Latency is the delay between the emission of the event by the kernel and its serialization, it is only set when
+	// the latency is embedded in the events
+	Latency time.Duration
+	// TimestampAnomaly is set when the kernel timestamp of the event is implausible ("stale" or "future")
+	TimestampAnomaly string
+	// SelfGenerated is set when the event was caused by KRIE or by a process started by KRIE
+	SelfGenerated bool
 }
 
 // NewEvent returns a new Event instance
@@ -455,13 +1017,34 @@ func NewEvent() *Event {
 	return &Event{}
 }
 
-func (e *Event) MarshalJSON() ([]byte, error) {
-	s := NewEventSerializer(e)
-	w := &jwriter.Writer{
-		Flags: jwriter.NilSliceAsEmpty | jwriter.NilMapAsEmpty,
+// UnmarshalBinary unmarshalls the kernel event, the process context and the payload of an event sent by the kernel
+func (e *Event) UnmarshalBinary(data []byte, resolver *TimeResolver) (int, error) {
+	cursor, err := e.Kernel.UnmarshalBinary(data, resolver)
+	if err != nil {
+		return 0, err
+	}
+
+	read, err := e.Process.UnmarshalBinary(data[cursor:])
+	if err != nil {
+		return 0, err
+	}
+	cursor += read
+
+	decoder := getEventDecoder(e.Kernel.Type)
+	if decoder == nil || decoder.decode == nil {
+		return 0, fmt.Errorf("unknown event type: %s", e.Kernel.Type)
 	}
-	s.MarshalEasyJSON(w)
-	return w.BuildBytes()
+	read, err = decoder.decode(e, data[cursor:])
+	if err != nil {
+		return 0, err
+	}
+	return cursor + read, nil
+}
+
+// HasProcessContext returns true if the event was triggered by a process
+func (e *Event) HasProcessContext() bool {
+	decoder := getEventDecoder(e.Kernel.Type)
+	return decoder == nil || !decoder.noProcessContext
 }
 
 func (e Event) String() string {
@@ -475,6 +1058,7 @@ func (e Event) String() string {
 // EventSerializer is used to serialize Event
 // easyjson:json
 type EventSerializer struct {
+	ID                        string `json:"id"`
 	*KernelEventSerializer    `json:"event,omitempty"`
 	*ProcessContextSerializer `json:"process,omitempty"`
 
@@ -488,44 +1072,91 @@ type EventSerializer struct {
 	*SysCtlEventEventSerializer  `json:"sysctl,omitempty"`
 
 	// krie events
-	*HookedSyscallEventSerializer   `json:"hooked_syscall,omitempty"`
-	*EventCheckEventSerializer      `json:"event_check,omitempty"`
-	*KernelParameterEventSerializer `json:"kernel_parameter,omitempty"`
-	*RegisterCheckEventSerializer   `json:"register_check,omitempty"`
+	*HookedSyscallEventSerializer     `json:"hooked_syscall,omitempty"`
+	*EventCheckEventSerializer        `json:"event_check,omitempty"`
+	*KernelParameterEventSerializer   `json:"kernel_parameter,omitempty"`
+	*RegisterCheckEventSerializer     `json:"register_check,omitempty"`
+	*ResponseEventSerializer          `json:"response,omitempty"`
+	*PolicyChangeEventSerializer      `json:"policy_change,omitempty"`
+	*RawEventSerializer               `json:"raw,omitempty"`
+	*ClockSkewEventSerializer         `json:"clock_skew,omitempty"`
+	*SilenceEventSerializer           `json:"silence,omitempty"`
+	*TailCallTamperEventSerializer    `json:"tail_call_tamper,omitempty"`
+	*IntegritySwitchEventSerializer   `json:"integrity_switch,omitempty"`
+	*SysCtlDriftEventSerializer       `json:"sysctl_drift,omitempty"`
+	*KexecEventSerializer             `json:"kexec,omitempty"`
+	*CoverageChangeEventSerializer    `json:"coverage_change,omitempty"`
+	*ControlRegisterEventSerializer   `json:"control_register,omitempty"`
+	*ProcessExitEventSerializer       `json:"process_exit,omitempty"`
+	*FTraceHookEventSerializer        `json:"ftrace_hook,omitempty"`
+	*CredChangeEventSerializer        `json:"cred_change,omitempty"`
+	*LSMStateChangeEventSerializer    `json:"lsm_state_change,omitempty"`
+	*EnrichmentEventSerializer        `json:"enrichment,omitempty"`
+	*IOUringEventSerializer           `json:"io_uring,omitempty"`
+	*BPFProgramUnloadEventSerializer  `json:"bpf_program_unload,omitempty"`
+	*PerfEventOpenEventSerializer     `json:"perf_event_open,omitempty"`
+	*RawMemoryAccessEventSerializer   `json:"raw_memory_access,omitempty"`
+	*ModuleTaintChangeEventSerializer `json:"module_taint_change,omitempty"`
+	*MSRWriteEventSerializer          `json:"msr_write,omitempty"`
+	*SymbolResolutionEventSerializer  `json:"symbol_resolution,omitempty"`
+	*UsermodeHelperEventSerializer    `json:"usermode_helper,omitempty"`
+	*ProcessVMEventSerializer         `json:"process_vm,omitempty"`
+	*MountEventSerializer             `json:"mount,omitempty"`
+	*PrctlEventSerializer             `json:"prctl,omitempty"`
+	*ProfileSnapshotEventSerializer   `json:"profile_snapshot,omitempty"`
+	*CapsetEventSerializer            `json:"capset,omitempty"`
+	*BPFMapTamperEventSerializer      `json:"bpf_map_tamper,omitempty"`
+	*TracefsProbeEventSerializer      `json:"tracefs_probe,omitempty"`
+	*NetfilterHookEventSerializer     `json:"netfilter_hook,omitempty"`
+	*KernelLogTamperEventSerializer   `json:"kernel_log_tamper,omitempty"`
+	*UpgradeEventSerializer           `json:"upgrade,omitempty"`
+	*KeyringEventSerializer           `json:"keyring,omitempty"`
+	*NamespaceEventSerializer         `json:"namespace,omitempty"`
+	*SyscallOrphansEventSerializer    `json:"syscall_orphans,omitempty"`
+	*UserfaultfdEventSerializer       `json:"userfaultfd,omitempty"`
+	*BinfmtMiscEventSerializer        `json:"binfmt_misc,omitempty"`
+	*ModuleSignatureEventSerializer   `json:"module_signature,omitempty"`
+	*RebootEventSerializer            `json:"reboot,omitempty"`
+	*HiddenProcessEventSerializer     `json:"hidden_process,omitempty"`
+	*AnnotationEventSerializer        `json:"annotation,omitempty"`
+
+	Redacted []string          `json:"redacted,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Policy   *PolicyContext    `json:"policy,omitempty"`
+	Latency  int64             `json:"latency_us,omitempty"`
+
+	TimestampAnomaly string           `json:"timestamp_anomaly,omitempty"`
+	SelfGenerated    bool             `json:"self_generated,omitempty"`
+	ModuleLifecycle  *ModuleLifecycle `json:"module_lifecycle,omitempty"`
+	Anomaly          *Anomaly         `json:"anomaly,omitempty"`
+	Intel            []IntelMatch     `json:"intel,omitempty"`
+	Failure          *Failure         `json:"failure,omitempty"`
 }
 
 // NewEventSerializer returns a new EventSerializer instance for the provided Event
 func NewEventSerializer(event *Event) *EventSerializer {
 	serializer := &EventSerializer{
+		ID:                    event.ID,
 		KernelEventSerializer: NewKernelEventSerializer(&event.Kernel),
+		Redacted:              event.Redacted,
+		Labels:                event.Labels,
+		Latency:               event.Latency.Microseconds(),
+		TimestampAnomaly:      event.TimestampAnomaly,
+		SelfGenerated:         event.SelfGenerated,
+		ModuleLifecycle:       event.ModuleLifecycle,
+		Anomaly:               event.Anomaly,
+		Intel:                 event.Intel,
+		Failure:               event.Failure,
+	}
+	if len(event.Policy.RuleID) > 0 {
+		serializer.Policy = &event.Policy
 	}
-	if event.Kernel.Type != HookedSyscallTableEventType {
+	if event.HasProcessContext() {
 		serializer.ProcessContextSerializer = NewProcessContextSerializer(&event.Process)
 	}
 
-	switch event.Kernel.Type {
-	case InitModuleEventType:
-		serializer.InitModuleEventSerializer = NewInitModuleSerializer(&event.InitModule)
-	case DeleteModuleEventType:
-		serializer.DeleteModuleEventSerializer = NewDeleteModuleSerializer(&event.DeleteModule)
-	case BPFEventType:
-		serializer.BPFEventSerializer = NewBPFEventSerializer(&event.BPFEvent)
-	case BPFFilterEventType:
-		serializer.BPFFilterEventSerializer = NewBPFFilterEventSerializer(&event.BPFFilterEvent)
-	case PTraceEventType:
-		serializer.PtraceEventSerializer = NewPtraceEventSerializer(&event.PTraceEvent)
-	case KProbeEventType:
-		serializer.KProbeEventSerializer = NewKProbeEventSerializer(&event.KProbeEvent)
-	case SysCtlEventType:
-		serializer.SysCtlEventEventSerializer = NewSysCtlEventSerializer(&event.SysCtlEvent)
-	case EventCheckEventType:
-		serializer.EventCheckEventSerializer = NewEventCheckEventSerializer(&event.EventCheckEvent)
-	case HookedSyscallTableEventType, HookedSyscallEventType:
-		serializer.HookedSyscallEventSerializer = NewHookedSyscallEventSerializer(&event.HookedSyscallEvent)
-	case KernelParameterEventType, PeriodicKernelParameterEventType:
-		serializer.KernelParameterEventSerializer = NewKernelParameterEventSerializer(&event.KernelParameterEvent)
-	case RegisterCheckEventType:
-		serializer.RegisterCheckEventSerializer = NewRegisterCheckEventSerializer(&event.RegisterCheckEvent)
+	if decoder := getEventDecoder(event.Kernel.Type); decoder != nil {
+		decoder.serialize(event, serializer)
 	}
 	return serializer
 }
@@ -1,3 +1,5 @@
+//go:build !krie_minimal
+
 // Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
 
 package events
@@ -39,6 +41,49 @@ func easyjson692db02bDecodeGithubComGui774umeKriePkgKrieEvents(in *jlexer.Lexer,
 	out.EventCheckEventSerializer = new(EventCheckEventSerializer)
 	out.KernelParameterEventSerializer = new(KernelParameterEventSerializer)
 	out.RegisterCheckEventSerializer = new(RegisterCheckEventSerializer)
+	out.ResponseEventSerializer = new(ResponseEventSerializer)
+	out.PolicyChangeEventSerializer = new(PolicyChangeEventSerializer)
+	out.RawEventSerializer = new(RawEventSerializer)
+	out.ClockSkewEventSerializer = new(ClockSkewEventSerializer)
+	out.SilenceEventSerializer = new(SilenceEventSerializer)
+	out.TailCallTamperEventSerializer = new(TailCallTamperEventSerializer)
+	out.IntegritySwitchEventSerializer = new(IntegritySwitchEventSerializer)
+	out.SysCtlDriftEventSerializer = new(SysCtlDriftEventSerializer)
+	out.KexecEventSerializer = new(KexecEventSerializer)
+	out.CoverageChangeEventSerializer = new(CoverageChangeEventSerializer)
+	out.ControlRegisterEventSerializer = new(ControlRegisterEventSerializer)
+	out.ProcessExitEventSerializer = new(ProcessExitEventSerializer)
+	out.FTraceHookEventSerializer = new(FTraceHookEventSerializer)
+	out.CredChangeEventSerializer = new(CredChangeEventSerializer)
+	out.LSMStateChangeEventSerializer = new(LSMStateChangeEventSerializer)
+	out.EnrichmentEventSerializer = new(EnrichmentEventSerializer)
+	out.IOUringEventSerializer = new(IOUringEventSerializer)
+	out.BPFProgramUnloadEventSerializer = new(BPFProgramUnloadEventSerializer)
+	out.PerfEventOpenEventSerializer = new(PerfEventOpenEventSerializer)
+	out.RawMemoryAccessEventSerializer = new(RawMemoryAccessEventSerializer)
+	out.ModuleTaintChangeEventSerializer = new(ModuleTaintChangeEventSerializer)
+	out.MSRWriteEventSerializer = new(MSRWriteEventSerializer)
+	out.SymbolResolutionEventSerializer = new(SymbolResolutionEventSerializer)
+	out.UsermodeHelperEventSerializer = new(UsermodeHelperEventSerializer)
+	out.ProcessVMEventSerializer = new(ProcessVMEventSerializer)
+	out.MountEventSerializer = new(MountEventSerializer)
+	out.PrctlEventSerializer = new(PrctlEventSerializer)
+	out.ProfileSnapshotEventSerializer = new(ProfileSnapshotEventSerializer)
+	out.CapsetEventSerializer = new(CapsetEventSerializer)
+	out.BPFMapTamperEventSerializer = new(BPFMapTamperEventSerializer)
+	out.TracefsProbeEventSerializer = new(TracefsProbeEventSerializer)
+	out.NetfilterHookEventSerializer = new(NetfilterHookEventSerializer)
+	out.KernelLogTamperEventSerializer = new(KernelLogTamperEventSerializer)
+	out.UpgradeEventSerializer = new(UpgradeEventSerializer)
+	out.KeyringEventSerializer = new(KeyringEventSerializer)
+	out.NamespaceEventSerializer = new(NamespaceEventSerializer)
+	out.SyscallOrphansEventSerializer = new(SyscallOrphansEventSerializer)
+	out.UserfaultfdEventSerializer = new(UserfaultfdEventSerializer)
+	out.BinfmtMiscEventSerializer = new(BinfmtMiscEventSerializer)
+	out.ModuleSignatureEventSerializer = new(ModuleSignatureEventSerializer)
+	out.RebootEventSerializer = new(RebootEventSerializer)
+	out.HiddenProcessEventSerializer = new(HiddenProcessEventSerializer)
+	out.AnnotationEventSerializer = new(AnnotationEventSerializer)
 	in.Delim('{')
 	for !in.IsDelim('}') {
 		key := in.UnsafeFieldName(false)
@@ -49,6 +94,8 @@ func easyjson692db02bDecodeGithubComGui774umeKriePkgKrieEvents(in *jlexer.Lexer,
 			continue
 		}
 		switch key {
+		case "id":
+			out.ID = string(in.String())
 		case "event":
 			if in.IsNull() {
 				in.Skip()
@@ -179,6 +226,548 @@ func easyjson692db02bDecodeGithubComGui774umeKriePkgKrieEvents(in *jlexer.Lexer,
 				}
 				(*out.RegisterCheckEventSerializer).UnmarshalEasyJSON(in)
 			}
+		case "response":
+			if in.IsNull() {
+				in.Skip()
+				out.ResponseEventSerializer = nil
+			} else {
+				if out.ResponseEventSerializer == nil {
+					out.ResponseEventSerializer = new(ResponseEventSerializer)
+				}
+				(*out.ResponseEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "policy_change":
+			if in.IsNull() {
+				in.Skip()
+				out.PolicyChangeEventSerializer = nil
+			} else {
+				if out.PolicyChangeEventSerializer == nil {
+					out.PolicyChangeEventSerializer = new(PolicyChangeEventSerializer)
+				}
+				(*out.PolicyChangeEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "raw":
+			if in.IsNull() {
+				in.Skip()
+				out.RawEventSerializer = nil
+			} else {
+				if out.RawEventSerializer == nil {
+					out.RawEventSerializer = new(RawEventSerializer)
+				}
+				(*out.RawEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "clock_skew":
+			if in.IsNull() {
+				in.Skip()
+				out.ClockSkewEventSerializer = nil
+			} else {
+				if out.ClockSkewEventSerializer == nil {
+					out.ClockSkewEventSerializer = new(ClockSkewEventSerializer)
+				}
+				(*out.ClockSkewEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "silence":
+			if in.IsNull() {
+				in.Skip()
+				out.SilenceEventSerializer = nil
+			} else {
+				if out.SilenceEventSerializer == nil {
+					out.SilenceEventSerializer = new(SilenceEventSerializer)
+				}
+				(*out.SilenceEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "tail_call_tamper":
+			if in.IsNull() {
+				in.Skip()
+				out.TailCallTamperEventSerializer = nil
+			} else {
+				if out.TailCallTamperEventSerializer == nil {
+					out.TailCallTamperEventSerializer = new(TailCallTamperEventSerializer)
+				}
+				(*out.TailCallTamperEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "integrity_switch":
+			if in.IsNull() {
+				in.Skip()
+				out.IntegritySwitchEventSerializer = nil
+			} else {
+				if out.IntegritySwitchEventSerializer == nil {
+					out.IntegritySwitchEventSerializer = new(IntegritySwitchEventSerializer)
+				}
+				(*out.IntegritySwitchEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "sysctl_drift":
+			if in.IsNull() {
+				in.Skip()
+				out.SysCtlDriftEventSerializer = nil
+			} else {
+				if out.SysCtlDriftEventSerializer == nil {
+					out.SysCtlDriftEventSerializer = new(SysCtlDriftEventSerializer)
+				}
+				(*out.SysCtlDriftEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "kexec":
+			if in.IsNull() {
+				in.Skip()
+				out.KexecEventSerializer = nil
+			} else {
+				if out.KexecEventSerializer == nil {
+					out.KexecEventSerializer = new(KexecEventSerializer)
+				}
+				(*out.KexecEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "coverage_change":
+			if in.IsNull() {
+				in.Skip()
+				out.CoverageChangeEventSerializer = nil
+			} else {
+				if out.CoverageChangeEventSerializer == nil {
+					out.CoverageChangeEventSerializer = new(CoverageChangeEventSerializer)
+				}
+				(*out.CoverageChangeEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "control_register":
+			if in.IsNull() {
+				in.Skip()
+				out.ControlRegisterEventSerializer = nil
+			} else {
+				if out.ControlRegisterEventSerializer == nil {
+					out.ControlRegisterEventSerializer = new(ControlRegisterEventSerializer)
+				}
+				(*out.ControlRegisterEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "process_exit":
+			if in.IsNull() {
+				in.Skip()
+				out.ProcessExitEventSerializer = nil
+			} else {
+				if out.ProcessExitEventSerializer == nil {
+					out.ProcessExitEventSerializer = new(ProcessExitEventSerializer)
+				}
+				(*out.ProcessExitEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "ftrace_hook":
+			if in.IsNull() {
+				in.Skip()
+				out.FTraceHookEventSerializer = nil
+			} else {
+				if out.FTraceHookEventSerializer == nil {
+					out.FTraceHookEventSerializer = new(FTraceHookEventSerializer)
+				}
+				(*out.FTraceHookEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "cred_change":
+			if in.IsNull() {
+				in.Skip()
+				out.CredChangeEventSerializer = nil
+			} else {
+				if out.CredChangeEventSerializer == nil {
+					out.CredChangeEventSerializer = new(CredChangeEventSerializer)
+				}
+				(*out.CredChangeEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "lsm_state_change":
+			if in.IsNull() {
+				in.Skip()
+				out.LSMStateChangeEventSerializer = nil
+			} else {
+				if out.LSMStateChangeEventSerializer == nil {
+					out.LSMStateChangeEventSerializer = new(LSMStateChangeEventSerializer)
+				}
+				(*out.LSMStateChangeEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "enrichment":
+			if in.IsNull() {
+				in.Skip()
+				out.EnrichmentEventSerializer = nil
+			} else {
+				if out.EnrichmentEventSerializer == nil {
+					out.EnrichmentEventSerializer = new(EnrichmentEventSerializer)
+				}
+				(*out.EnrichmentEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "io_uring":
+			if in.IsNull() {
+				in.Skip()
+				out.IOUringEventSerializer = nil
+			} else {
+				if out.IOUringEventSerializer == nil {
+					out.IOUringEventSerializer = new(IOUringEventSerializer)
+				}
+				(*out.IOUringEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "bpf_program_unload":
+			if in.IsNull() {
+				in.Skip()
+				out.BPFProgramUnloadEventSerializer = nil
+			} else {
+				if out.BPFProgramUnloadEventSerializer == nil {
+					out.BPFProgramUnloadEventSerializer = new(BPFProgramUnloadEventSerializer)
+				}
+				(*out.BPFProgramUnloadEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "perf_event_open":
+			if in.IsNull() {
+				in.Skip()
+				out.PerfEventOpenEventSerializer = nil
+			} else {
+				if out.PerfEventOpenEventSerializer == nil {
+					out.PerfEventOpenEventSerializer = new(PerfEventOpenEventSerializer)
+				}
+				(*out.PerfEventOpenEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "raw_memory_access":
+			if in.IsNull() {
+				in.Skip()
+				out.RawMemoryAccessEventSerializer = nil
+			} else {
+				if out.RawMemoryAccessEventSerializer == nil {
+					out.RawMemoryAccessEventSerializer = new(RawMemoryAccessEventSerializer)
+				}
+				(*out.RawMemoryAccessEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "module_taint_change":
+			if in.IsNull() {
+				in.Skip()
+				out.ModuleTaintChangeEventSerializer = nil
+			} else {
+				if out.ModuleTaintChangeEventSerializer == nil {
+					out.ModuleTaintChangeEventSerializer = new(ModuleTaintChangeEventSerializer)
+				}
+				(*out.ModuleTaintChangeEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "msr_write":
+			if in.IsNull() {
+				in.Skip()
+				out.MSRWriteEventSerializer = nil
+			} else {
+				if out.MSRWriteEventSerializer == nil {
+					out.MSRWriteEventSerializer = new(MSRWriteEventSerializer)
+				}
+				(*out.MSRWriteEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "symbol_resolution":
+			if in.IsNull() {
+				in.Skip()
+				out.SymbolResolutionEventSerializer = nil
+			} else {
+				if out.SymbolResolutionEventSerializer == nil {
+					out.SymbolResolutionEventSerializer = new(SymbolResolutionEventSerializer)
+				}
+				(*out.SymbolResolutionEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "usermode_helper":
+			if in.IsNull() {
+				in.Skip()
+				out.UsermodeHelperEventSerializer = nil
+			} else {
+				if out.UsermodeHelperEventSerializer == nil {
+					out.UsermodeHelperEventSerializer = new(UsermodeHelperEventSerializer)
+				}
+				(*out.UsermodeHelperEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "process_vm":
+			if in.IsNull() {
+				in.Skip()
+				out.ProcessVMEventSerializer = nil
+			} else {
+				if out.ProcessVMEventSerializer == nil {
+					out.ProcessVMEventSerializer = new(ProcessVMEventSerializer)
+				}
+				(*out.ProcessVMEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "mount":
+			if in.IsNull() {
+				in.Skip()
+				out.MountEventSerializer = nil
+			} else {
+				if out.MountEventSerializer == nil {
+					out.MountEventSerializer = new(MountEventSerializer)
+				}
+				(*out.MountEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "prctl":
+			if in.IsNull() {
+				in.Skip()
+				out.PrctlEventSerializer = nil
+			} else {
+				if out.PrctlEventSerializer == nil {
+					out.PrctlEventSerializer = new(PrctlEventSerializer)
+				}
+				(*out.PrctlEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "profile_snapshot":
+			if in.IsNull() {
+				in.Skip()
+				out.ProfileSnapshotEventSerializer = nil
+			} else {
+				if out.ProfileSnapshotEventSerializer == nil {
+					out.ProfileSnapshotEventSerializer = new(ProfileSnapshotEventSerializer)
+				}
+				(*out.ProfileSnapshotEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "capset":
+			if in.IsNull() {
+				in.Skip()
+				out.CapsetEventSerializer = nil
+			} else {
+				if out.CapsetEventSerializer == nil {
+					out.CapsetEventSerializer = new(CapsetEventSerializer)
+				}
+				(*out.CapsetEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "bpf_map_tamper":
+			if in.IsNull() {
+				in.Skip()
+				out.BPFMapTamperEventSerializer = nil
+			} else {
+				if out.BPFMapTamperEventSerializer == nil {
+					out.BPFMapTamperEventSerializer = new(BPFMapTamperEventSerializer)
+				}
+				(*out.BPFMapTamperEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "tracefs_probe":
+			if in.IsNull() {
+				in.Skip()
+				out.TracefsProbeEventSerializer = nil
+			} else {
+				if out.TracefsProbeEventSerializer == nil {
+					out.TracefsProbeEventSerializer = new(TracefsProbeEventSerializer)
+				}
+				(*out.TracefsProbeEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "netfilter_hook":
+			if in.IsNull() {
+				in.Skip()
+				out.NetfilterHookEventSerializer = nil
+			} else {
+				if out.NetfilterHookEventSerializer == nil {
+					out.NetfilterHookEventSerializer = new(NetfilterHookEventSerializer)
+				}
+				(*out.NetfilterHookEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "kernel_log_tamper":
+			if in.IsNull() {
+				in.Skip()
+				out.KernelLogTamperEventSerializer = nil
+			} else {
+				if out.KernelLogTamperEventSerializer == nil {
+					out.KernelLogTamperEventSerializer = new(KernelLogTamperEventSerializer)
+				}
+				(*out.KernelLogTamperEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "upgrade":
+			if in.IsNull() {
+				in.Skip()
+				out.UpgradeEventSerializer = nil
+			} else {
+				if out.UpgradeEventSerializer == nil {
+					out.UpgradeEventSerializer = new(UpgradeEventSerializer)
+				}
+				(*out.UpgradeEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "keyring":
+			if in.IsNull() {
+				in.Skip()
+				out.KeyringEventSerializer = nil
+			} else {
+				if out.KeyringEventSerializer == nil {
+					out.KeyringEventSerializer = new(KeyringEventSerializer)
+				}
+				(*out.KeyringEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "namespace":
+			if in.IsNull() {
+				in.Skip()
+				out.NamespaceEventSerializer = nil
+			} else {
+				if out.NamespaceEventSerializer == nil {
+					out.NamespaceEventSerializer = new(NamespaceEventSerializer)
+				}
+				(*out.NamespaceEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "syscall_orphans":
+			if in.IsNull() {
+				in.Skip()
+				out.SyscallOrphansEventSerializer = nil
+			} else {
+				if out.SyscallOrphansEventSerializer == nil {
+					out.SyscallOrphansEventSerializer = new(SyscallOrphansEventSerializer)
+				}
+				(*out.SyscallOrphansEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "userfaultfd":
+			if in.IsNull() {
+				in.Skip()
+				out.UserfaultfdEventSerializer = nil
+			} else {
+				if out.UserfaultfdEventSerializer == nil {
+					out.UserfaultfdEventSerializer = new(UserfaultfdEventSerializer)
+				}
+				(*out.UserfaultfdEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "binfmt_misc":
+			if in.IsNull() {
+				in.Skip()
+				out.BinfmtMiscEventSerializer = nil
+			} else {
+				if out.BinfmtMiscEventSerializer == nil {
+					out.BinfmtMiscEventSerializer = new(BinfmtMiscEventSerializer)
+				}
+				(*out.BinfmtMiscEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "module_signature":
+			if in.IsNull() {
+				in.Skip()
+				out.ModuleSignatureEventSerializer = nil
+			} else {
+				if out.ModuleSignatureEventSerializer == nil {
+					out.ModuleSignatureEventSerializer = new(ModuleSignatureEventSerializer)
+				}
+				(*out.ModuleSignatureEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "reboot":
+			if in.IsNull() {
+				in.Skip()
+				out.RebootEventSerializer = nil
+			} else {
+				if out.RebootEventSerializer == nil {
+					out.RebootEventSerializer = new(RebootEventSerializer)
+				}
+				(*out.RebootEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "hidden_process":
+			if in.IsNull() {
+				in.Skip()
+				out.HiddenProcessEventSerializer = nil
+			} else {
+				if out.HiddenProcessEventSerializer == nil {
+					out.HiddenProcessEventSerializer = new(HiddenProcessEventSerializer)
+				}
+				(*out.HiddenProcessEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "annotation":
+			if in.IsNull() {
+				in.Skip()
+				out.AnnotationEventSerializer = nil
+			} else {
+				if out.AnnotationEventSerializer == nil {
+					out.AnnotationEventSerializer = new(AnnotationEventSerializer)
+				}
+				(*out.AnnotationEventSerializer).UnmarshalEasyJSON(in)
+			}
+		case "redacted":
+			if in.IsNull() {
+				in.Skip()
+				out.Redacted = nil
+			} else {
+				in.Delim('[')
+				if out.Redacted == nil {
+					if !in.IsDelim(']') {
+						out.Redacted = make([]string, 0, 4)
+					} else {
+						out.Redacted = []string{}
+					}
+				} else {
+					out.Redacted = (out.Redacted)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.Redacted = append(out.Redacted, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(map[string]string)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v4 string
+					v4 = string(in.String())
+					(out.Labels)[key] = v4
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "policy":
+			if in.IsNull() {
+				in.Skip()
+				out.Policy = nil
+			} else {
+				if out.Policy == nil {
+					out.Policy = new(PolicyContext)
+				}
+				easyjson692db02bDecodeGithubComGui774umeKriePkgKrieEvents1(in, out.Policy)
+			}
+		case "latency_us":
+			out.Latency = int64(in.Int64())
+		case "timestamp_anomaly":
+			out.TimestampAnomaly = string(in.String())
+		case "self_generated":
+			out.SelfGenerated = bool(in.Bool())
+		case "module_lifecycle":
+			if in.IsNull() {
+				in.Skip()
+				out.ModuleLifecycle = nil
+			} else {
+				if out.ModuleLifecycle == nil {
+					out.ModuleLifecycle = new(ModuleLifecycle)
+				}
+				easyjson692db02bDecodeGithubComGui774umeKriePkgKrieEvents2(in, out.ModuleLifecycle)
+			}
+		case "anomaly":
+			if in.IsNull() {
+				in.Skip()
+				out.Anomaly = nil
+			} else {
+				if out.Anomaly == nil {
+					out.Anomaly = new(Anomaly)
+				}
+				easyjson692db02bDecodeGithubComGui774umeKriePkgKrieEvents3(in, out.Anomaly)
+			}
+		case "intel":
+			if in.IsNull() {
+				in.Skip()
+				out.Intel = nil
+			} else {
+				in.Delim('[')
+				if out.Intel == nil {
+					if !in.IsDelim(']') {
+						out.Intel = make([]IntelMatch, 0, 1)
+					} else {
+						out.Intel = []IntelMatch{}
+					}
+				} else {
+					out.Intel = (out.Intel)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v5 IntelMatch
+					easyjson692db02bDecodeGithubComGui774umeKriePkgKrieEvents4(in, &v5)
+					out.Intel = append(out.Intel, v5)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "failure":
+			if in.IsNull() {
+				in.Skip()
+				out.Failure = nil
+			} else {
+				if out.Failure == nil {
+					out.Failure = new(Failure)
+				}
+				easyjson692db02bDecodeGithubComGui774umeKriePkgKrieEvents5(in, out.Failure)
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -193,132 +782,373 @@ func easyjson692db02bEncodeGithubComGui774umeKriePkgKrieEvents(out *jwriter.Writ
 	out.RawByte('{')
 	first := true
 	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ID))
+	}
 	if in.KernelEventSerializer != nil {
 		const prefix string = ",\"event\":"
-		first = false
-		out.RawString(prefix[1:])
+		out.RawString(prefix)
 		(*in.KernelEventSerializer).MarshalEasyJSON(out)
 	}
 	if in.ProcessContextSerializer != nil {
 		const prefix string = ",\"process\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		(*in.ProcessContextSerializer).MarshalEasyJSON(out)
 	}
 	if in.InitModuleEventSerializer != nil {
 		const prefix string = ",\"init_module\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		(*in.InitModuleEventSerializer).MarshalEasyJSON(out)
 	}
 	if in.DeleteModuleEventSerializer != nil {
 		const prefix string = ",\"delete_module\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		(*in.DeleteModuleEventSerializer).MarshalEasyJSON(out)
 	}
 	if in.BPFEventSerializer != nil {
 		const prefix string = ",\"bpf\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		(*in.BPFEventSerializer).MarshalEasyJSON(out)
 	}
 	if in.BPFFilterEventSerializer != nil {
 		const prefix string = ",\"bpf_filter\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		(*in.BPFFilterEventSerializer).MarshalEasyJSON(out)
 	}
 	if in.PtraceEventSerializer != nil {
 		const prefix string = ",\"ptrace\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		(*in.PtraceEventSerializer).MarshalEasyJSON(out)
 	}
 	if in.KProbeEventSerializer != nil {
 		const prefix string = ",\"kprobe\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		(*in.KProbeEventSerializer).MarshalEasyJSON(out)
 	}
 	if in.SysCtlEventEventSerializer != nil {
 		const prefix string = ",\"sysctl\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		(*in.SysCtlEventEventSerializer).MarshalEasyJSON(out)
 	}
 	if in.HookedSyscallEventSerializer != nil {
 		const prefix string = ",\"hooked_syscall\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		(*in.HookedSyscallEventSerializer).MarshalEasyJSON(out)
 	}
 	if in.EventCheckEventSerializer != nil {
 		const prefix string = ",\"event_check\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		(*in.EventCheckEventSerializer).MarshalEasyJSON(out)
 	}
 	if in.KernelParameterEventSerializer != nil {
 		const prefix string = ",\"kernel_parameter\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		(*in.KernelParameterEventSerializer).MarshalEasyJSON(out)
 	}
 	if in.RegisterCheckEventSerializer != nil {
 		const prefix string = ",\"register_check\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		(*in.RegisterCheckEventSerializer).MarshalEasyJSON(out)
 	}
+	if in.ResponseEventSerializer != nil {
+		const prefix string = ",\"response\":"
+		out.RawString(prefix)
+		(*in.ResponseEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.PolicyChangeEventSerializer != nil {
+		const prefix string = ",\"policy_change\":"
+		out.RawString(prefix)
+		(*in.PolicyChangeEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.RawEventSerializer != nil {
+		const prefix string = ",\"raw\":"
+		out.RawString(prefix)
+		(*in.RawEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.ClockSkewEventSerializer != nil {
+		const prefix string = ",\"clock_skew\":"
+		out.RawString(prefix)
+		(*in.ClockSkewEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.SilenceEventSerializer != nil {
+		const prefix string = ",\"silence\":"
+		out.RawString(prefix)
+		(*in.SilenceEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.TailCallTamperEventSerializer != nil {
+		const prefix string = ",\"tail_call_tamper\":"
+		out.RawString(prefix)
+		(*in.TailCallTamperEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.IntegritySwitchEventSerializer != nil {
+		const prefix string = ",\"integrity_switch\":"
+		out.RawString(prefix)
+		(*in.IntegritySwitchEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.SysCtlDriftEventSerializer != nil {
+		const prefix string = ",\"sysctl_drift\":"
+		out.RawString(prefix)
+		(*in.SysCtlDriftEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.KexecEventSerializer != nil {
+		const prefix string = ",\"kexec\":"
+		out.RawString(prefix)
+		(*in.KexecEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.CoverageChangeEventSerializer != nil {
+		const prefix string = ",\"coverage_change\":"
+		out.RawString(prefix)
+		(*in.CoverageChangeEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.ControlRegisterEventSerializer != nil {
+		const prefix string = ",\"control_register\":"
+		out.RawString(prefix)
+		(*in.ControlRegisterEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.ProcessExitEventSerializer != nil {
+		const prefix string = ",\"process_exit\":"
+		out.RawString(prefix)
+		(*in.ProcessExitEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.FTraceHookEventSerializer != nil {
+		const prefix string = ",\"ftrace_hook\":"
+		out.RawString(prefix)
+		(*in.FTraceHookEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.CredChangeEventSerializer != nil {
+		const prefix string = ",\"cred_change\":"
+		out.RawString(prefix)
+		(*in.CredChangeEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.LSMStateChangeEventSerializer != nil {
+		const prefix string = ",\"lsm_state_change\":"
+		out.RawString(prefix)
+		(*in.LSMStateChangeEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.EnrichmentEventSerializer != nil {
+		const prefix string = ",\"enrichment\":"
+		out.RawString(prefix)
+		(*in.EnrichmentEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.IOUringEventSerializer != nil {
+		const prefix string = ",\"io_uring\":"
+		out.RawString(prefix)
+		(*in.IOUringEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.BPFProgramUnloadEventSerializer != nil {
+		const prefix string = ",\"bpf_program_unload\":"
+		out.RawString(prefix)
+		(*in.BPFProgramUnloadEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.PerfEventOpenEventSerializer != nil {
+		const prefix string = ",\"perf_event_open\":"
+		out.RawString(prefix)
+		(*in.PerfEventOpenEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.RawMemoryAccessEventSerializer != nil {
+		const prefix string = ",\"raw_memory_access\":"
+		out.RawString(prefix)
+		(*in.RawMemoryAccessEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.ModuleTaintChangeEventSerializer != nil {
+		const prefix string = ",\"module_taint_change\":"
+		out.RawString(prefix)
+		(*in.ModuleTaintChangeEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.MSRWriteEventSerializer != nil {
+		const prefix string = ",\"msr_write\":"
+		out.RawString(prefix)
+		(*in.MSRWriteEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.SymbolResolutionEventSerializer != nil {
+		const prefix string = ",\"symbol_resolution\":"
+		out.RawString(prefix)
+		(*in.SymbolResolutionEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.UsermodeHelperEventSerializer != nil {
+		const prefix string = ",\"usermode_helper\":"
+		out.RawString(prefix)
+		(*in.UsermodeHelperEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.ProcessVMEventSerializer != nil {
+		const prefix string = ",\"process_vm\":"
+		out.RawString(prefix)
+		(*in.ProcessVMEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.MountEventSerializer != nil {
+		const prefix string = ",\"mount\":"
+		out.RawString(prefix)
+		(*in.MountEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.PrctlEventSerializer != nil {
+		const prefix string = ",\"prctl\":"
+		out.RawString(prefix)
+		(*in.PrctlEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.ProfileSnapshotEventSerializer != nil {
+		const prefix string = ",\"profile_snapshot\":"
+		out.RawString(prefix)
+		(*in.ProfileSnapshotEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.CapsetEventSerializer != nil {
+		const prefix string = ",\"capset\":"
+		out.RawString(prefix)
+		(*in.CapsetEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.BPFMapTamperEventSerializer != nil {
+		const prefix string = ",\"bpf_map_tamper\":"
+		out.RawString(prefix)
+		(*in.BPFMapTamperEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.TracefsProbeEventSerializer != nil {
+		const prefix string = ",\"tracefs_probe\":"
+		out.RawString(prefix)
+		(*in.TracefsProbeEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.NetfilterHookEventSerializer != nil {
+		const prefix string = ",\"netfilter_hook\":"
+		out.RawString(prefix)
+		(*in.NetfilterHookEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.KernelLogTamperEventSerializer != nil {
+		const prefix string = ",\"kernel_log_tamper\":"
+		out.RawString(prefix)
+		(*in.KernelLogTamperEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.UpgradeEventSerializer != nil {
+		const prefix string = ",\"upgrade\":"
+		out.RawString(prefix)
+		(*in.UpgradeEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.KeyringEventSerializer != nil {
+		const prefix string = ",\"keyring\":"
+		out.RawString(prefix)
+		(*in.KeyringEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.NamespaceEventSerializer != nil {
+		const prefix string = ",\"namespace\":"
+		out.RawString(prefix)
+		(*in.NamespaceEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.SyscallOrphansEventSerializer != nil {
+		const prefix string = ",\"syscall_orphans\":"
+		out.RawString(prefix)
+		(*in.SyscallOrphansEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.UserfaultfdEventSerializer != nil {
+		const prefix string = ",\"userfaultfd\":"
+		out.RawString(prefix)
+		(*in.UserfaultfdEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.BinfmtMiscEventSerializer != nil {
+		const prefix string = ",\"binfmt_misc\":"
+		out.RawString(prefix)
+		(*in.BinfmtMiscEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.ModuleSignatureEventSerializer != nil {
+		const prefix string = ",\"module_signature\":"
+		out.RawString(prefix)
+		(*in.ModuleSignatureEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.RebootEventSerializer != nil {
+		const prefix string = ",\"reboot\":"
+		out.RawString(prefix)
+		(*in.RebootEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.HiddenProcessEventSerializer != nil {
+		const prefix string = ",\"hidden_process\":"
+		out.RawString(prefix)
+		(*in.HiddenProcessEventSerializer).MarshalEasyJSON(out)
+	}
+	if in.AnnotationEventSerializer != nil {
+		const prefix string = ",\"annotation\":"
+		out.RawString(prefix)
+		(*in.AnnotationEventSerializer).MarshalEasyJSON(out)
+	}
+	if len(in.Redacted) != 0 {
+		const prefix string = ",\"redacted\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.Redacted {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v5First := true
+			for v5Name, v5Value := range in.Labels {
+				if v5First {
+					v5First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v5Name))
+				out.RawByte(':')
+				out.String(string(v5Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if in.Policy != nil {
+		const prefix string = ",\"policy\":"
+		out.RawString(prefix)
+		easyjson692db02bEncodeGithubComGui774umeKriePkgKrieEvents1(out, *in.Policy)
+	}
+	if in.Latency != 0 {
+		const prefix string = ",\"latency_us\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Latency))
+	}
+	if in.TimestampAnomaly != "" {
+		const prefix string = ",\"timestamp_anomaly\":"
+		out.RawString(prefix)
+		out.String(string(in.TimestampAnomaly))
+	}
+	if in.SelfGenerated {
+		const prefix string = ",\"self_generated\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.SelfGenerated))
+	}
+	if in.ModuleLifecycle != nil {
+		const prefix string = ",\"module_lifecycle\":"
+		out.RawString(prefix)
+		easyjson692db02bEncodeGithubComGui774umeKriePkgKrieEvents2(out, *in.ModuleLifecycle)
+	}
+	if in.Anomaly != nil {
+		const prefix string = ",\"anomaly\":"
+		out.RawString(prefix)
+		easyjson692db02bEncodeGithubComGui774umeKriePkgKrieEvents3(out, *in.Anomaly)
+	}
+	if len(in.Intel) != 0 {
+		const prefix string = ",\"intel\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v6, v7 := range in.Intel {
+				if v6 > 0 {
+					out.RawByte(',')
+				}
+				easyjson692db02bEncodeGithubComGui774umeKriePkgKrieEvents4(out, v7)
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.Failure != nil {
+		const prefix string = ",\"failure\":"
+		out.RawString(prefix)
+		easyjson692db02bEncodeGithubComGui774umeKriePkgKrieEvents5(out, *in.Failure)
+	}
 	out.RawByte('}')
 }
 
@@ -331,3 +1161,412 @@ func (v EventSerializer) MarshalEasyJSON(w *jwriter.Writer) {
 func (v *EventSerializer) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjson692db02bDecodeGithubComGui774umeKriePkgKrieEvents(l, v)
 }
+func easyjson692db02bDecodeGithubComGui774umeKriePkgKrieEvents1(in *jlexer.Lexer, out *PolicyContext) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "version":
+			out.Version = string(in.String())
+		case "hash":
+			out.Hash = string(in.String())
+		case "rule_id":
+			out.RuleID = string(in.String())
+		case "mode":
+			out.Mode = RuleMode(in.String())
+		case "confidence":
+			out.Confidence = Confidence(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson692db02bEncodeGithubComGui774umeKriePkgKrieEvents1(out *jwriter.Writer, in PolicyContext) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.Version != "" {
+		const prefix string = ",\"version\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.String(string(in.Version))
+	}
+	{
+		const prefix string = ",\"hash\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Hash))
+	}
+	{
+		const prefix string = ",\"rule_id\":"
+		out.RawString(prefix)
+		out.String(string(in.RuleID))
+	}
+	if in.Mode != "" {
+		const prefix string = ",\"mode\":"
+		out.RawString(prefix)
+		out.String(string(in.Mode))
+	}
+	if in.Confidence != "" {
+		const prefix string = ",\"confidence\":"
+		out.RawString(prefix)
+		out.String(string(in.Confidence))
+	}
+	out.RawByte('}')
+}
+func easyjson692db02bDecodeGithubComGui774umeKriePkgKrieEvents2(in *jlexer.Lexer, out *ModuleLifecycle) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = string(in.String())
+		case "module":
+			out.Module = string(in.String())
+		case "stage":
+			out.Stage = string(in.String())
+		case "stages":
+			if in.IsNull() {
+				in.Skip()
+				out.Stages = nil
+			} else {
+				in.Delim('[')
+				if out.Stages == nil {
+					if !in.IsDelim(']') {
+						out.Stages = make([]string, 0, 4)
+					} else {
+						out.Stages = []string{}
+					}
+				} else {
+					out.Stages = (out.Stages)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.Stages = append(out.Stages, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "sequence":
+			out.Sequence = uint64(in.Uint64())
+		case "first_seen":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.FirstSeen).UnmarshalJSON(data))
+			}
+		case "taint":
+			out.Taint = string(in.String())
+		case "load_event_id":
+			out.LoadEventID = string(in.String())
+		case "previous_event_id":
+			out.PreviousEventID = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson692db02bEncodeGithubComGui774umeKriePkgKrieEvents2(out *jwriter.Writer, in ModuleLifecycle) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ID))
+	}
+	{
+		const prefix string = ",\"module\":"
+		out.RawString(prefix)
+		out.String(string(in.Module))
+	}
+	if in.Stage != "" {
+		const prefix string = ",\"stage\":"
+		out.RawString(prefix)
+		out.String(string(in.Stage))
+	}
+	if len(in.Stages) != 0 {
+		const prefix string = ",\"stages\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.Stages {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"sequence\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Sequence))
+	}
+	{
+		const prefix string = ",\"first_seen\":"
+		out.RawString(prefix)
+		out.Raw((in.FirstSeen).MarshalJSON())
+	}
+	if in.Taint != "" {
+		const prefix string = ",\"taint\":"
+		out.RawString(prefix)
+		out.String(string(in.Taint))
+	}
+	if in.LoadEventID != "" {
+		const prefix string = ",\"load_event_id\":"
+		out.RawString(prefix)
+		out.String(string(in.LoadEventID))
+	}
+	if in.PreviousEventID != "" {
+		const prefix string = ",\"previous_event_id\":"
+		out.RawString(prefix)
+		out.String(string(in.PreviousEventID))
+	}
+	out.RawByte('}')
+}
+func easyjson692db02bDecodeGithubComGui774umeKriePkgKrieEvents3(in *jlexer.Lexer, out *Anomaly) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "key":
+			out.Key = string(in.String())
+		case "score":
+			out.Score = float64(in.Float64())
+		case "entropy":
+			out.Entropy = float64(in.Float64())
+		case "occurrences":
+			out.Occurrences = uint64(in.Uint64())
+		case "observations":
+			out.Observations = uint64(in.Uint64())
+		case "rare":
+			out.Rare = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson692db02bEncodeGithubComGui774umeKriePkgKrieEvents3(out *jwriter.Writer, in Anomaly) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"key\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Key))
+	}
+	{
+		const prefix string = ",\"score\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Score))
+	}
+	{
+		const prefix string = ",\"entropy\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Entropy))
+	}
+	{
+		const prefix string = ",\"occurrences\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Occurrences))
+	}
+	{
+		const prefix string = ",\"observations\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Observations))
+	}
+	if in.Rare {
+		const prefix string = ",\"rare\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Rare))
+	}
+	out.RawByte('}')
+}
+func easyjson692db02bDecodeGithubComGui774umeKriePkgKrieEvents4(in *jlexer.Lexer, out *IntelMatch) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "feed":
+			out.Feed = string(in.String())
+		case "type":
+			out.Type = string(in.String())
+		case "value":
+			out.Value = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson692db02bEncodeGithubComGui774umeKriePkgKrieEvents4(out *jwriter.Writer, in IntelMatch) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"feed\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Feed))
+	}
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.Type))
+	}
+	{
+		const prefix string = ",\"value\":"
+		out.RawString(prefix)
+		out.String(string(in.Value))
+	}
+	out.RawByte('}')
+}
+func easyjson692db02bDecodeGithubComGui774umeKriePkgKrieEvents5(in *jlexer.Lexer, out *Failure) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "errno":
+			out.Errno = string(in.String())
+		case "code":
+			out.Code = int64(in.Int64())
+		case "reason":
+			out.Reason = string(in.String())
+		case "count":
+			out.Count = uint64(in.Uint64())
+		case "repeated":
+			out.Repeated = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson692db02bEncodeGithubComGui774umeKriePkgKrieEvents5(out *jwriter.Writer, in Failure) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"errno\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Errno))
+	}
+	{
+		const prefix string = ",\"code\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Code))
+	}
+	{
+		const prefix string = ",\"reason\":"
+		out.RawString(prefix)
+		out.String(string(in.Reason))
+	}
+	{
+		const prefix string = ",\"count\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Count))
+	}
+	if in.Repeated {
+		const prefix string = ",\"repeated\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Repeated))
+	}
+	out.RawByte('}')
+}
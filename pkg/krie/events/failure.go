@@ -0,0 +1,102 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// RepeatedFailureRule is the sub rule of the events of a process whose calls failed repeatedly
+	RepeatedFailureRule = "repeated_failure"
+
+	// maxErrno is the highest error code returned by a syscall, the lower return values aren't errors
+	maxErrno = 4095
+)
+
+// Failure reasons
+const (
+	// FailurePermissionDenied is a call rejected because the caller lacks a capability (EPERM)
+	FailurePermissionDenied = "permission_denied"
+	// FailureInvalidModule is a module load rejected because the module isn't a valid ELF module (ENOEXEC)
+	FailureInvalidModule = "invalid_module"
+	// FailureSignatureRejected is a module load rejected because of the signature of the module (EKEYREJECTED,
+	// ENOKEY, EBADMSG)
+	FailureSignatureRejected = "signature_rejected"
+	// FailureVerifierRejected is a bpf program rejected by the verifier (EACCES, EINVAL)
+	FailureVerifierRejected = "verifier_rejected"
+	// FailureOther is any other error
+	FailureOther = "other"
+)
+
+// Failure is the error returned by a monitored syscall. Failed attempts are often the earliest sign of an attacker
+// iterating on an exploit, the failures of each process are counted so that the repeated failures stand out.
+type Failure struct {
+	// Errno is the name of the error code, for example EPERM
+	Errno string `json:"errno"`
+	// Code is the error code
+	Code int64 `json:"code"`
+	// Reason is the interpretation of the error code for the event type
+	Reason string `json:"reason"`
+	// Count is the number of failed calls of the process for this event type, in the failure window
+	Count uint64 `json:"count"`
+	// Repeated is set when the count reached the failure threshold
+	Repeated bool `json:"repeated,omitempty"`
+}
+
+// IsFailureEventType returns true if the return value of the events of the provided type is the return value of a
+// syscall
+func IsFailureEventType(eventType EventType) bool {
+	switch eventType {
+	case InitModuleEventType, DeleteModuleEventType, BPFEventType, BPFFilterEventType, PTraceEventType, KexecEventType,
+		IOUringEventType, PerfEventOpenEventType, ProcessVMEventType, CapsetEventType,
+		BPFMapTamperEventType, KernelLogTamperEventType, KeyringEventType,
+		NamespaceEventType, UserfaultfdEventType:
+		return true
+	}
+	return false
+}
+
+// NewFailure returns the failure of an event, or nil if the monitored syscall succeeded
+func (e *Event) NewFailure() *Failure {
+	if !IsFailureEventType(e.Kernel.Type) || e.Kernel.Retval >= 0 || e.Kernel.Retval < -maxErrno {
+		return nil
+	}
+	errno := unix.Errno(-e.Kernel.Retval)
+	failure := &Failure{
+		Errno:  unix.ErrnoName(errno),
+		Code:   -e.Kernel.Retval,
+		Reason: FailureOther,
+	}
+	if len(failure.Errno) == 0 {
+		failure.Errno = fmt.Sprintf("errno(%d)", failure.Code)
+	}
+
+	switch {
+	case errno == unix.EPERM:
+		failure.Reason = FailurePermissionDenied
+	case e.Kernel.Type == InitModuleEventType && errno == unix.ENOEXEC:
+		failure.Reason = FailureInvalidModule
+	case e.Kernel.Type == InitModuleEventType && (errno == unix.EKEYREJECTED || errno == unix.ENOKEY || errno == unix.EBADMSG):
+		failure.Reason = FailureSignatureRejected
+	case e.Kernel.Type == BPFEventType && e.BPFEvent.Cmd == BpfProgLoadCmd && (errno == unix.EACCES || errno == unix.EINVAL):
+		failure.Reason = FailureVerifierRejected
+	}
+	return failure
+}
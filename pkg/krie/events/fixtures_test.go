@@ -0,0 +1,302 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cilium/ebpf/btf"
+
+	"github.com/Gui774ume/krie/pkg/assets"
+)
+
+var synthesize = flag.Bool("synthesize", false, "regenerate the fixtures from the BTF of the embedded eBPF programs")
+
+// fixtureStructs are the kernel structures of the event types which aren't sent as a <event_type>_event_t structure
+var fixtureStructs = map[EventType]string{
+	HookedSyscallTableEventType:      "syscall_table_event_t",
+	HookedSyscallEventType:           "syscall_table_event_t",
+	PeriodicKernelParameterEventType: "kernel_parameter_event_t",
+	RegisterCheckEventType:           "register_event_t",
+}
+
+// fixtureValues are the values of the integer fields holding a constant known to their decoder, such as a command
+// or a flag selecting how the rest of the event is decoded, indexed by the path of the field in its structure
+var fixtureValues = map[string]uint64{
+	"bpf_event_t.map.map_type":                    1,  // BPF_MAP_TYPE_HASH
+	"bpf_event_t.prog.prog_type":                  2,  // BPF_PROG_TYPE_KPROBE
+	"bpf_event_t.prog.attach_type":                3,  // BPF_CGROUP_SOCK_OPS
+	"bpf_event_t.cmd":                             5,  // BPF_PROG_LOAD
+	"bpf_filter_event_t.family":                   17, // AF_PACKET
+	"bpf_filter_event_t.type":                     3,  // SOCK_RAW
+	"bpf_filter_event_t.protocol":                 0x0800,
+	"bpf_filter_event_t.bpf_filter_cmd":           26, // SO_ATTACH_FILTER
+	"kprobe_event_t.cmd":                          1,  // REGISTER_KPROBE
+	"kprobe_event_t.kprobe_type":                  2,  // KRETPROBE_TYPE
+	"ptrace_event_t.request":                      4,  // PTRACE_POKETEXT
+	"register_event_t.hookpoint":                  1,  // commit_creds
+	"sysctl_event_t.write_access":                 1,
+	"sysctl_event_t.action":                       2, // SYSCTL_OVERRIDE
+	"event_check_event_t.checked_event_type":      1, // init_module
+	"kexec_event_t.file_load":                     1,
+	"integrity_switch_event_t.fs_magic":           0x62656572, // SYSFS_MAGIC
+	"bpf_map_tamper_event_t.map.map_type":         1,          // BPF_MAP_TYPE_HASH
+	"bpf_map_tamper_event_t.cmd":                  2,          // BPF_MAP_UPDATE_ELEM
+	"bpf_map_tamper_event_t.krie_map":             1,
+	"capset_event_t.effective_after":              1<<16 | 1<<39, // CAP_SYS_MODULE, CAP_BPF
+	"tracefs_probe_event_t.file":                  1,             // kprobe_events
+	"tracefs_probe_event_t.size":                  46,
+	"netfilter_hook_event_t.pf":                   2,          // NFPROTO_IPV4
+	"netfilter_hook_event_t.hooknum":              1,          // NF_INET_LOCAL_IN
+	"netfilter_hook_event_t.priority":             0xffffff9c, // -100
+	"kernel_log_tamper_event_t.source":            1,          // KERNEL_LOG_SYSLOG
+	"kernel_log_tamper_event_t.syslog_action":     5,          // SYSLOG_ACTION_CLEAR
+	"keyring_event_t.syscall":                     1,          // KEYRING_ADD_KEY
+	"keyring_event_t.keyctl_cmd":                  0,
+	"keyring_event_t.key":                         0xfffffffc, // KEY_SPEC_USER_KEYRING
+	"keyring_event_t.payload_len":                 294,
+	"namespace_event_t.previous.cgroup_namespace": 5,
+	"namespace_event_t.previous.ipc_namespace":    6,
+	"namespace_event_t.previous.net_namespace":    7,
+	"namespace_event_t.previous.mnt_namespace":    8,
+	"namespace_event_t.previous.pid_namespace":    4026532201, // the pid namespace of a container
+	"namespace_event_t.previous.time_namespace":   10,
+	"namespace_event_t.previous.user_namespace":   11,
+	"namespace_event_t.previous.uts_namespace":    12,
+	"namespace_event_t.flags":                     0x20000000, // CLONE_NEWPID
+	"namespace_event_t.syscall":                   1,          // NAMESPACE_SETNS
+	"namespace_event_t.fd":                        3,
+	"userfaultfd_event_t.api":                     0xaa,        // UFFD_API
+	"userfaultfd_event_t.features":                1<<1 | 1<<8, // UFFD_FEATURE_EVENT_FORK, UFFD_FEATURE_THREAD_ID
+	"userfaultfd_event_t.operation":               2,           // USERFAULTFD_API
+	"userfaultfd_event_t.flags":                   0x80000,     // O_CLOEXEC
+	"binfmt_misc_event_t.size":                    54,
+	"module_signature_event_t.kind":               1,          // MODULE_SIGNATURE_LOAD
+	"module_signature_event_t.sig_enforce":        1,          // SIG_ENFORCE_ENABLED
+	"reboot_event_t.magic1":                       0xfee1dead, // LINUX_REBOOT_MAGIC1
+	"reboot_event_t.magic2":                       672274793,  // LINUX_REBOOT_MAGIC2
+	"reboot_event_t.cmd":                          0x45584543, // LINUX_REBOOT_CMD_KEXEC
+}
+
+// fixtureStrings are the values of the character arrays parsed by their decoder, indexed by the path of the field in
+// its structure
+var fixtureStrings = map[string]string{
+	"sysctl_event_t.name_value":         "kernel/modules_disabled\x000\x001",
+	"integrity_switch_event_t.names[0]": "sig_enforce",
+	"integrity_switch_event_t.names[1]": "parameters",
+	"integrity_switch_event_t.names[2]": "module",
+	"integrity_switch_event_t.names[3]": "/",
+	"tracefs_probe_event_t.definition":  "p:krie/open do_sys_openat2 dfd=%di\n-:krie/old\n",
+	"keyring_event_t.type":              "asymmetric",
+	"keyring_event_t.description":       "krie: test signing key",
+	"module_signature_event_t.name":     "krie_unsigned",
+	"binfmt_misc_event_t.definition":    ":krie:M::\\x7fELF\\x02\\x01::/tmp/.krie/interpreter:POCF\n",
+}
+
+// kernelEventTypes returns the event types decoded from a kernel payload
+func kernelEventTypes() []EventType {
+	var eventTypes []EventType
+	for eventType, decoder := range eventDecoders {
+		if decoder != nil && decoder.decode != nil {
+			eventTypes = append(eventTypes, EventType(eventType))
+		}
+	}
+	return eventTypes
+}
+
+// synthesizeFixtures writes the fixture of each event type decoded from a kernel payload, see fixtureWriter
+func synthesizeFixtures() error {
+	data, err := assets.Asset("/probe.o")
+	if err != nil {
+		return err
+	}
+	spec, err := btf.LoadSpecFromReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("couldn't load the BTF of the eBPF programs: %w", err)
+	}
+
+	for _, eventType := range kernelEventTypes() {
+		name, ok := fixtureStructs[eventType]
+		if !ok {
+			name = eventType.String() + "_event_t"
+		}
+		var structure *btf.Struct
+		if err = spec.TypeByName(name, &structure); err != nil {
+			return fmt.Errorf("couldn't find the structure of %s: %w", eventType, err)
+		}
+
+		w := fixtureWriter{
+			data:      make([]byte, structure.Size),
+			eventType: eventType,
+			structure: structure.Name,
+		}
+		if err = w.write(structure, 0, ""); err != nil {
+			return fmt.Errorf("couldn't synthesize the fixture of %s: %w", eventType, err)
+		}
+		if err = os.WriteFile(filepath.Join("testdata", "fixtures", eventType.String()+".bin"), w.data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fixtureWriter fills the kernel structure of an event so that every field holds a distinct non-zero value: integers
+// are numbered in the order of the fields, and character arrays hold the path of the field, unless fixtureValues or
+// fixtureStrings provide a value. A field read at the wrong offset or with the wrong size changes the golden file of
+// the event.
+type fixtureWriter struct {
+	data      []byte
+	eventType EventType
+	structure string
+	counter   uint64
+}
+
+func (w *fixtureWriter) next() uint64 {
+	w.counter++
+	return w.counter
+}
+
+func (w *fixtureWriter) write(typ btf.Type, offset uint32, path string) error {
+	if value, ok := fixtureValues[w.structure+"."+path]; ok {
+		size, err := btf.Sizeof(typ)
+		if err != nil {
+			return err
+		}
+		w.writeInt(offset, uint32(size), value)
+		return nil
+	}
+
+	switch t := btf.UnderlyingType(typ).(type) {
+	case *btf.Struct:
+		return w.writeMembers(t.Members, offset, path)
+	case *btf.Union:
+		// only the first member of a union is written
+		if len(t.Members) == 0 {
+			return nil
+		}
+		return w.writeMembers(t.Members[:1], offset, path)
+	case *btf.Array:
+		if isCharArray(t) {
+			w.writeString(t, offset, path)
+			return nil
+		}
+		return w.writeArray(t, offset, path)
+	case *btf.Int:
+		w.writeInt(offset, t.Size, w.next())
+	case *btf.Enum:
+		w.writeInt(offset, 4, w.next())
+	case *btf.Pointer:
+		w.writeInt(offset, 8, w.next())
+	default:
+		return fmt.Errorf("%s: unsupported type %s", path, typ)
+	}
+	return nil
+}
+
+func (w *fixtureWriter) writeMembers(members []btf.Member, offset uint32, path string) error {
+	strlens := make(map[string]uint64)
+	for _, member := range members {
+		if member.BitfieldSize > 0 {
+			return fmt.Errorf("%s.%s: bitfields aren't supported", path, member.Name)
+		}
+		if member.Name == "padding" {
+			continue
+		}
+		memberPath := member.Name
+		if path != "" {
+			memberPath = path + "." + member.Name
+		}
+		memberOffset := offset + member.Offset.Bytes()
+
+		if memberPath == "event.type" {
+			// the type of the kernel event selects the decoder
+			w.writeInt(memberOffset, 4, uint64(w.eventType))
+			continue
+		}
+		if array, ok := btf.UnderlyingType(member.Type).(*btf.Array); ok && isCharArray(array) {
+			strlens[member.Name] = uint64(w.writeString(array, memberOffset, memberPath))
+			continue
+		}
+		if err := w.write(member.Type, memberOffset, memberPath); err != nil {
+			return err
+		}
+	}
+
+	// the lengths of the character arrays are the lengths of their strings
+	for _, member := range members {
+		length, ok := strlens[strings.TrimSuffix(member.Name, "_len")]
+		if !ok || !strings.HasSuffix(member.Name, "_len") {
+			continue
+		}
+		if integer, ok := btf.UnderlyingType(member.Type).(*btf.Int); ok {
+			w.writeInt(offset+member.Offset.Bytes(), integer.Size, length)
+		}
+	}
+	return nil
+}
+
+func (w *fixtureWriter) writeArray(array *btf.Array, offset uint32, path string) error {
+	size, err := btf.Sizeof(array.Type)
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < array.Nelems; i++ {
+		if err = w.write(array.Type, offset+i*uint32(size), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeString writes the value of the field in fixtureStrings or its path, truncated to leave room for the trailing
+// NULL character
+func (w *fixtureWriter) writeString(array *btf.Array, offset uint32, path string) int {
+	value, ok := fixtureStrings[w.structure+"."+path]
+	if !ok {
+		value = path
+	}
+	if len(value) >= int(array.Nelems) {
+		value = value[:array.Nelems-1]
+	}
+	copy(w.data[offset:], value)
+	return len(value)
+}
+
+func (w *fixtureWriter) writeInt(offset uint32, size uint32, value uint64) {
+	switch size {
+	case 1:
+		// wraps without reaching 0
+		w.data[offset] = uint8((value-1)%0xff + 1)
+	case 2:
+		binary.LittleEndian.PutUint16(w.data[offset:], uint16(value))
+	case 4:
+		binary.LittleEndian.PutUint32(w.data[offset:], uint32(value))
+	case 8:
+		binary.LittleEndian.PutUint64(w.data[offset:], value)
+	}
+}
+
+func isCharArray(array *btf.Array) bool {
+	integer, ok := btf.UnderlyingType(array.Type).(*btf.Int)
+	return ok && integer.Size == 1
+}
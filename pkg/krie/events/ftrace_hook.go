@@ -0,0 +1,118 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+// FTraceHookCmd is the ftrace operation of an ftrace_hook event
+type FTraceHookCmd uint32
+
+const (
+	// RegisterFTraceFunction is the command of a call to register_ftrace_function
+	RegisterFTraceFunction FTraceHookCmd = iota + 1
+	// UnregisterFTraceFunction is the command of a call to unregister_ftrace_function
+	UnregisterFTraceFunction
+	// FTraceSetFilterIP is the command of a call to ftrace_set_filter_ip
+	FTraceSetFilterIP
+)
+
+func (cmd FTraceHookCmd) String() string {
+	switch cmd {
+	case RegisterFTraceFunction:
+		return "register_ftrace_function"
+	case UnregisterFTraceFunction:
+		return "unregister_ftrace_function"
+	case FTraceSetFilterIP:
+		return "ftrace_set_filter_ip"
+	default:
+		return fmt.Sprintf("FTraceHookCmd(%d)", cmd)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the command
+func (cmd FTraceHookCmd) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", cmd)), nil
+}
+
+func ftraceHookProbes() []manager.ProbeIdentificationPair {
+	return []manager.ProbeIdentificationPair{
+		{UID: KRIEUID, EBPFSection: "kprobe/register_ftrace_function", EBPFFuncName: "kprobe_register_ftrace_function"},
+		{UID: KRIEUID, EBPFSection: "kprobe/unregister_ftrace_function", EBPFFuncName: "kprobe_unregister_ftrace_function"},
+		{UID: KRIEUID, EBPFSection: "kprobe/ftrace_set_filter_ip", EBPFFuncName: "kprobe_ftrace_set_filter_ip"},
+	}
+}
+
+func addFTraceHookProbes(all *[]*manager.Probe) {
+	for _, id := range ftraceHookProbes() {
+		*all = append(*all, &manager.Probe{ProbeIdentificationPair: id})
+	}
+}
+
+func addFTraceHookSelectors(all *[]manager.ProbesSelector) {
+	// the kernels built without CONFIG_DYNAMIC_FTRACE don't have ftrace_set_filter_ip
+	var selectors []manager.ProbesSelector
+	for _, id := range ftraceHookProbes() {
+		selectors = append(selectors, &manager.ProbeSelector{ProbeIdentificationPair: id})
+	}
+	*all = append(*all, &manager.BestEffort{Selectors: selectors})
+}
+
+// FTraceHookEvent represents the registration of an ftrace callback located outside of the kernel text, the way
+// ftrace based rootkits (khook style) hook kernel functions
+type FTraceHookEvent struct {
+	Cmd FTraceHookCmd `json:"cmd"`
+	// Ops is the address of the ftrace_ops structure
+	Ops MemoryPointer `json:"ops"`
+	// Callback is the function called by ftrace
+	Callback KernelSymbol `json:"callback"`
+	// Target is the traced function, only set by ftrace_set_filter_ip
+	Target KernelSymbol `json:"target"`
+	// Remove is set when ftrace_set_filter_ip removes the target from the filter of the ftrace_ops
+	Remove bool `json:"remove,omitempty"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *FTraceHookEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < 32 {
+		return 0, fmt.Errorf("while parsing FTraceHookEvent, got len %d, needed %d: %w", len(data), 32, ErrNotEnoughData)
+	}
+	e.Ops = MemoryPointer(ByteOrder.Uint64(data[0:8]))
+	e.Callback = KernelSymbol{Address: MemoryPointer(ByteOrder.Uint64(data[8:16]))}
+	e.Target = KernelSymbol{Address: MemoryPointer(ByteOrder.Uint64(data[16:24]))}
+	e.Cmd = FTraceHookCmd(ByteOrder.Uint32(data[24:28]))
+	e.Remove = ByteOrder.Uint32(data[28:32]) == 1
+	return 32, nil
+}
+
+// FTraceHookEventSerializer is used to serialize FTraceHookEvent
+// easyjson:json
+type FTraceHookEventSerializer struct {
+	*FTraceHookEvent
+}
+
+// NewFTraceHookEventSerializer returns a new instance of FTraceHookEventSerializer
+func NewFTraceHookEventSerializer(e *FTraceHookEvent) *FTraceHookEventSerializer {
+	return &FTraceHookEventSerializer{
+		FTraceHookEvent: e,
+	}
+}
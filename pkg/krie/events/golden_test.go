@@ -0,0 +1,95 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "regenerate the golden files")
+
+// TestGolden decodes the raw kernel payloads of testdata/fixtures and compares their JSON serialization with the
+// golden files of testdata/golden.
+func TestGolden(t *testing.T) {
+	if *synthesize {
+		if err := synthesizeFixtures(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "fixtures", "*.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every event type decoded from a kernel payload must have a fixture
+	for _, eventType := range kernelEventTypes() {
+		fixture := filepath.Join("testdata", "fixtures", eventType.String()+".bin")
+		if _, err = os.Stat(fixture); err != nil {
+			t.Errorf("missing fixture for %s, see testdata/README.md: %v", eventType, err)
+		}
+	}
+
+	for _, fixture := range fixtures {
+		name := strings.TrimSuffix(filepath.Base(fixture), ".bin")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			event := NewEvent()
+			if _, err = event.UnmarshalBinary(data, &TimeResolver{}); err != nil {
+				t.Fatalf("couldn't decode %s: %v", fixture, err)
+			}
+			assert.Equal(t, name, event.Kernel.Type.String())
+
+			// the time depends on the boot time of the host
+			event.Kernel.Time = time.Time{}
+			output, err := event.MarshalJSON()
+			if err != nil {
+				t.Fatalf("couldn't serialize %s: %v", fixture, err)
+			}
+			output = append(output, '\n')
+
+			golden := filepath.Join("testdata", "golden", name+".json")
+			if *update {
+				if err = os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err = os.WriteFile(golden, output, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("missing golden file, run make golden: %v", err)
+			}
+			assert.True(t, bytes.Equal(expected, output), "%s serialization changed, run make golden if this is expected:\n%s", name, output)
+		})
+	}
+}
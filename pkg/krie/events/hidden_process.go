@@ -0,0 +1,55 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"time"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+// TaskIteratorProbe is the task iterator of the hidden process scans, it is attached by the scanner instead of the
+// manager
+var TaskIteratorProbe = manager.ProbeIdentificationPair{EBPFSection: "iter/task", EBPFFuncName: "iter_task"}
+
+// HiddenProcessEvent represents a process known to the kernel but missing from the listing of /proc, the process was
+// hidden by a rootkit. The event has no process context: the hidden process is the subject of the event, not the actor.
+type HiddenProcessEvent struct {
+	PID  uint32 `json:"pid"`
+	PPID uint32 `json:"ppid"`
+	Comm string `json:"comm"`
+	// StartTime is the start time of the process
+	StartTime time.Time `json:"start_time"`
+	// Reachable is set when /proc/<pid> can still be opened: the listing of /proc is filtered (a hooked getdents), but
+	// the lookup of the process isn't
+	Reachable bool `json:"reachable"`
+}
+
+// HiddenProcessEventSerializer is used to serialize HiddenProcessEvent
+// easyjson:json
+type HiddenProcessEventSerializer struct {
+	*HiddenProcessEvent
+}
+
+// NewHiddenProcessEventSerializer returns a new instance of HiddenProcessEventSerializer
+func NewHiddenProcessEventSerializer(e *HiddenProcessEvent) *HiddenProcessEventSerializer {
+	return &HiddenProcessEventSerializer{
+		HiddenProcessEvent: e,
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	// integritySwitchPathDepth is the number of path components collected in kernel space
+	integritySwitchPathDepth = 4
+	// integritySwitchNameLen is the maximum length of each path component collected in kernel space
+	integritySwitchNameLen = 64
+
+	securityFSMagic = 0x73636673
+	sysFSMagic      = 0x62656572
+)
+
+// integritySwitchProbe returns the probe used to monitor integrity switches: the LSM hook can block the write, the
+// kprobe fallback can only report it (or kill the writer)
+func integritySwitchProbe() manager.ProbeIdentificationPair {
+	if IsBPFLSMAvailable() {
+		return manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: "lsm/file_permission", EBPFFuncName: "lsm_integrity_switch_file_permission"}
+	}
+	return manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: "kprobe/security_file_permission", EBPFFuncName: "kprobe_security_file_permission"}
+}
+
+func addIntegritySwitchProbes(all *[]*manager.Probe) {
+	*all = append(*all, &manager.Probe{ProbeIdentificationPair: integritySwitchProbe()})
+}
+
+func addIntegritySwitchSelectors(all *[]manager.ProbesSelector) {
+	*all = append(*all,
+		&manager.ProbeSelector{ProbeIdentificationPair: integritySwitchProbe()},
+	)
+}
+
+// IntegritySwitchEvent represents a write to a switch controlling the integrity enforcement of the kernel: the IMA, EVM
+// and lockdown entries of securityfs, or the module.sig_enforce parameter
+type IntegritySwitchEvent struct {
+	Filesystem string `json:"filesystem"`
+	Path       string `json:"path"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *IntegritySwitchEvent) UnmarshallBinary(data []byte) (int, error) {
+	size := 8 + integritySwitchPathDepth*integritySwitchNameLen
+	if len(data) < size {
+		return 0, fmt.Errorf("while parsing IntegritySwitchEvent, got len %d, needed %d: %w", len(data), size, ErrNotEnoughData)
+	}
+
+	prefix := "/"
+	switch ByteOrder.Uint32(data[0:4]) {
+	case securityFSMagic:
+		e.Filesystem = "securityfs"
+		prefix = "/sys/kernel/security/"
+	case sysFSMagic:
+		e.Filesystem = "sysfs"
+		prefix = "/sys/"
+	}
+
+	// the components are collected from the leaf up, and the root of the filesystem is its own parent
+	var components []string
+	for i := 0; i < integritySwitchPathDepth; i++ {
+		cursor := 8 + i*integritySwitchNameLen
+		name, err := UnmarshalString(data[cursor:cursor+integritySwitchNameLen], integritySwitchNameLen)
+		if err != nil {
+			return 0, err
+		}
+		if len(name) == 0 || name == "/" {
+			break
+		}
+		components = append([]string{name}, components...)
+	}
+	e.Path = prefix + strings.Join(components, "/")
+	return size, nil
+}
+
+// IntegritySwitchEventSerializer is used to serialize IntegritySwitchEvent
+// easyjson:json
+type IntegritySwitchEventSerializer struct {
+	*IntegritySwitchEvent
+}
+
+// NewIntegritySwitchEventSerializer returns a new instance of IntegritySwitchEventSerializer
+func NewIntegritySwitchEventSerializer(e *IntegritySwitchEvent) *IntegritySwitchEventSerializer {
+	return &IntegritySwitchEventSerializer{
+		IntegritySwitchEvent: e,
+	}
+}
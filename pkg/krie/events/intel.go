@@ -0,0 +1,80 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+// Intel indicator types
+const (
+	// IntelSHA256 is the SHA-256 hash of a file
+	IntelSHA256 = "sha256"
+	// IntelModule is the name of a kernel module
+	IntelModule = "module"
+	// IntelSymbol is a kernel symbol, the target of a hook or of a symbol resolution
+	IntelSymbol = "symbol"
+	// IntelBPFTag is the tag of a bpf program
+	IntelBPFTag = "bpf_tag"
+)
+
+// IntelMatch is a value of an event found in a threat intel feed
+type IntelMatch struct {
+	Feed  string `json:"feed"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// IntelValues returns the values of the event which can be matched against the indicators of the threat intel feeds,
+// indexed by indicator type
+func (e *Event) IntelValues() map[string][]string {
+	values := make(map[string][]string)
+	add := func(kind string, value string) {
+		if len(value) > 0 && value != "unknown" && value != "system" {
+			values[kind] = append(values[kind], value)
+		}
+	}
+	addSymbol := func(symbol KernelSymbol) {
+		add(IntelSymbol, symbol.Symbol)
+		add(IntelModule, symbol.Module)
+	}
+
+	switch e.Kernel.Type {
+	case InitModuleEventType:
+		add(IntelModule, e.InitModule.Name)
+		add(IntelSHA256, e.InitModule.SHA256)
+	case DeleteModuleEventType:
+		add(IntelModule, e.DeleteModule.Name)
+	case BPFEventType:
+		add(IntelBPFTag, e.BPFEvent.Program.Tag)
+	case KProbeEventType:
+		add(IntelSymbol, e.KProbeEvent.Symbol)
+	case FTraceHookEventType:
+		addSymbol(e.FTraceHookEvent.Callback)
+		addSymbol(e.FTraceHookEvent.Target)
+	case NetfilterHookEventType:
+		addSymbol(e.NetfilterHookEvent.Hook)
+	case HookedSyscallEventType, HookedSyscallTableEventType:
+		addSymbol(e.HookedSyscallEvent.NewHandler)
+	case SymbolResolutionEventType:
+		add(IntelSymbol, e.SymbolResolutionEvent.Symbol)
+		if caller := e.SymbolResolutionEvent.Caller; caller != nil {
+			add(IntelModule, caller.Module)
+		}
+	case MSRWriteEventType:
+		if target := e.MSRWriteEvent.Target; target != nil {
+			addSymbol(*target)
+		}
+	}
+	return values
+}
@@ -0,0 +1,204 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+package events
+
+import (
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	// ioUringMaxBuffers is the maximum number of buffers captured for an IORING_REGISTER_BUFFERS call
+	ioUringMaxBuffers = 4
+	// ioUringEventLen is the length of the binary representation of an io_uring event
+	ioUringEventLen = 16*ioUringMaxBuffers + 40
+	// ioUringSetupCmd is the command of an io_uring_setup call
+	ioUringSetupCmd = 1
+	// ioUringRegisterBuffers is the IORING_REGISTER_BUFFERS opcode
+	ioUringRegisterBuffers = 0
+	// ioUringUseRegisteredRing is the flag of the opcodes called with a registered ring file descriptor
+	ioUringUseRegisteredRing = 1 << 31
+)
+
+func addIOUringProbes(all *[]*manager.Probe) {
+	for _, syscall := range []string{"io_uring_setup", "io_uring_register"} {
+		*all = append(*all, ExpandSyscallProbes(&manager.Probe{
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				UID: KRIEUID,
+			},
+			SyscallFuncName: syscall,
+		}, EntryAndExit)...)
+	}
+}
+
+func addIOUringRoutes(all *[]manager.TailCallRoute) {
+	*all = append(*all, []manager.TailCallRoute{
+		{
+			ProgArrayName: "sys_exit_progs",
+			Key:           uint32(IOUringEventType),
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				EBPFSection:  "tracepoint/handle_sys_io_uring_exit",
+				EBPFFuncName: "tracepoint_handle_sys_io_uring_exit",
+			},
+		},
+	}...)
+}
+
+func addIOUringSelectors(all *[]manager.ProbesSelector) {
+	// io_uring was introduced in 5.1, and can be compiled out
+	*all = append(*all,
+		&manager.BestEffort{Selectors: append(
+			ExpandSyscallProbesSelector(manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: "io_uring_setup"}, EntryAndExit),
+			ExpandSyscallProbesSelector(manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: "io_uring_register"}, EntryAndExit)...,
+		)},
+	)
+}
+
+var (
+	// ioUringSetupFlags are the IORING_SETUP_* flags of io_uring_setup
+	ioUringSetupFlags = []string{
+		"IORING_SETUP_IOPOLL", "IORING_SETUP_SQPOLL", "IORING_SETUP_SQ_AFF", "IORING_SETUP_CQSIZE", "IORING_SETUP_CLAMP",
+		"IORING_SETUP_ATTACH_WQ", "IORING_SETUP_R_DISABLED", "IORING_SETUP_SUBMIT_ALL", "IORING_SETUP_COOP_TASKRUN",
+		"IORING_SETUP_TASKRUN_FLAG", "IORING_SETUP_SQE128", "IORING_SETUP_CQE32", "IORING_SETUP_SINGLE_ISSUER",
+		"IORING_SETUP_DEFER_TASKRUN", "IORING_SETUP_NO_MMAP", "IORING_SETUP_REGISTERED_FD_ONLY", "IORING_SETUP_NO_SQARRAY",
+	}
+	// ioUringFeatures are the IORING_FEAT_* features returned by io_uring_setup
+	ioUringFeatures = []string{
+		"IORING_FEAT_SINGLE_MMAP", "IORING_FEAT_NODROP", "IORING_FEAT_SUBMIT_STABLE", "IORING_FEAT_RW_CUR_POS",
+		"IORING_FEAT_CUR_PERSONALITY", "IORING_FEAT_FAST_POLL", "IORING_FEAT_POLL_32BITS", "IORING_FEAT_SQPOLL_NONFIXED",
+		"IORING_FEAT_EXT_ARG", "IORING_FEAT_NATIVE_WORKERS", "IORING_FEAT_RSRC_TAGS", "IORING_FEAT_CQE_SKIP",
+		"IORING_FEAT_LINKED_FILE", "IORING_FEAT_REG_REG_RING",
+	}
+	// ioUringRegisterOpcodes are the IORING_REGISTER_* opcodes of io_uring_register, indexed by opcode
+	ioUringRegisterOpcodes = []string{
+		"IORING_REGISTER_BUFFERS", "IORING_UNREGISTER_BUFFERS", "IORING_REGISTER_FILES", "IORING_UNREGISTER_FILES",
+		"IORING_REGISTER_EVENTFD", "IORING_UNREGISTER_EVENTFD", "IORING_REGISTER_FILES_UPDATE",
+		"IORING_REGISTER_EVENTFD_ASYNC", "IORING_REGISTER_PROBE", "IORING_REGISTER_PERSONALITY",
+		"IORING_UNREGISTER_PERSONALITY", "IORING_REGISTER_RESTRICTIONS", "IORING_REGISTER_ENABLE_RINGS",
+		"IORING_REGISTER_FILES2", "IORING_REGISTER_FILES_UPDATE2", "IORING_REGISTER_BUFFERS2",
+		"IORING_REGISTER_BUFFERS_UPDATE", "IORING_REGISTER_IOWQ_AFF", "IORING_UNREGISTER_IOWQ_AFF",
+		"IORING_REGISTER_IOWQ_MAX_WORKERS", "IORING_REGISTER_RING_FDS", "IORING_UNREGISTER_RING_FDS",
+		"IORING_REGISTER_PBUF_RING", "IORING_UNREGISTER_PBUF_RING", "IORING_REGISTER_SYNC_CANCEL",
+		"IORING_REGISTER_FILE_ALLOC_RANGE",
+	}
+)
+
+// bitNames returns the names of the bits set in value, the unknown bits are reported in hexadecimal
+func bitNames(value uint64, names []string) []string {
+	var out []string
+	for i, name := range names {
+		if value&(1<<i) != 0 {
+			out = append(out, name)
+			value &^= 1 << i
+		}
+	}
+	if value != 0 {
+		out = append(out, fmt.Sprintf("%#x", value))
+	}
+	return out
+}
+
+// IOUringBuffer is a buffer registered with IORING_REGISTER_BUFFERS
+type IOUringBuffer struct {
+	Address MemoryPointer `json:"address"`
+	Length  uint64        `json:"length"`
+}
+
+// IOUringEvent represents an io_uring_setup or io_uring_register call. The operations submitted through an io_uring
+// instance don't go through the syscalls, in particular with IORING_SETUP_SQPOLL where a kernel thread polls the
+// submission queue: io_uring is used to bypass the syscall based monitoring.
+type IOUringEvent struct {
+	Syscall string `json:"syscall"`
+	// Entries is the requested number of submission queue entries of io_uring_setup
+	Entries      uint32   `json:"entries,omitempty"`
+	Flags        []string `json:"flags,omitempty"`
+	SQThreadCPU  uint32   `json:"sq_thread_cpu,omitempty"`
+	SQThreadIdle uint32   `json:"sq_thread_idle,omitempty"`
+	// Features are the features returned by a successful io_uring_setup
+	Features []string `json:"features,omitempty"`
+	// FD is the io_uring file descriptor of io_uring_register
+	FD     uint32 `json:"fd,omitempty"`
+	Opcode string `json:"opcode,omitempty"`
+	NrArgs uint32 `json:"nr_args,omitempty"`
+	// Buffers are the first buffers registered with IORING_REGISTER_BUFFERS
+	Buffers []IOUringBuffer `json:"buffers,omitempty"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *IOUringEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < ioUringEventLen {
+		return 0, fmt.Errorf("while parsing IOUringEvent, got len %d, needed %d: %w", len(data), ioUringEventLen, ErrNotEnoughData)
+	}
+	cursor := 16 * ioUringMaxBuffers
+	cmd := ByteOrder.Uint32(data[cursor : cursor+4])
+	entries := ByteOrder.Uint32(data[cursor+4 : cursor+8])
+	flags := ByteOrder.Uint32(data[cursor+8 : cursor+12])
+	sqThreadCPU := ByteOrder.Uint32(data[cursor+12 : cursor+16])
+	sqThreadIdle := ByteOrder.Uint32(data[cursor+16 : cursor+20])
+	features := ByteOrder.Uint32(data[cursor+20 : cursor+24])
+	fd := ByteOrder.Uint32(data[cursor+24 : cursor+28])
+	opcode := ByteOrder.Uint32(data[cursor+28 : cursor+32])
+	nrArgs := ByteOrder.Uint32(data[cursor+32 : cursor+36])
+
+	*e = IOUringEvent{}
+	if cmd == ioUringSetupCmd {
+		e.Syscall = "io_uring_setup"
+		e.Entries = entries
+		e.Flags = bitNames(uint64(flags), ioUringSetupFlags)
+		e.SQThreadIdle = sqThreadIdle
+		// the CPU of the polling thread is only set with IORING_SETUP_SQ_AFF
+		if flags&4 != 0 {
+			e.SQThreadCPU = sqThreadCPU
+		}
+		e.Features = bitNames(uint64(features), ioUringFeatures)
+		return ioUringEventLen, nil
+	}
+
+	e.Syscall = "io_uring_register"
+	e.FD = fd
+	e.NrArgs = nrArgs
+	code := opcode &^ ioUringUseRegisteredRing
+	if int(code) < len(ioUringRegisterOpcodes) {
+		e.Opcode = ioUringRegisterOpcodes[code]
+	} else {
+		e.Opcode = fmt.Sprintf("IORING_REGISTER(%d)", code)
+	}
+	if code == ioUringRegisterBuffers {
+		for i := 0; i < ioUringMaxBuffers && uint32(i) < nrArgs; i++ {
+			e.Buffers = append(e.Buffers, IOUringBuffer{
+				Address: MemoryPointer(ByteOrder.Uint64(data[i*8 : i*8+8])),
+				Length:  ByteOrder.Uint64(data[8*ioUringMaxBuffers+i*8 : 8*ioUringMaxBuffers+i*8+8]),
+			})
+		}
+	}
+	return ioUringEventLen, nil
+}
+
+// IOUringEventSerializer is used to serialize IOUringEvent
+// easyjson:json
+type IOUringEventSerializer struct {
+	*IOUringEvent
+}
+
+// NewIOUringEventSerializer returns a new instance of IOUringEventSerializer
+func NewIOUringEventSerializer(e *IOUringEvent) *IOUringEventSerializer {
+	return &IOUringEventSerializer{
+		IOUringEvent: e,
+	}
+}
@@ -14,11 +14,63 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers $GOFILE
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
 
 package events
 
-import "time"
+import (
+	"sort"
+	"time"
+
+	manager "github.com/DataDog/ebpf-manager"
+	"github.com/cilium/ebpf"
+)
+
+// ProbeOriginConstant is the constant holding the identifier of an eBPF program, written in the events it sends
+const ProbeOriginConstant = "krie_probe_origin"
+
+// ProbeOrigin identifies the eBPF program which sent an event, several programs can send the events of an event type
+type ProbeOrigin struct {
+	Section string `json:"section"`
+	Func    string `json:"func"`
+}
+
+// ProbeOrigins are the eBPF programs of a collection, indexed by their identifier
+type ProbeOrigins map[uint32]*ProbeOrigin
+
+// NewProbeOrigins assigns an identifier to each program of a collection, in the order of their function names
+func NewProbeOrigins(spec *ebpf.CollectionSpec) ProbeOrigins {
+	names := make([]string, 0, len(spec.Programs))
+	for name := range spec.Programs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	origins := make(ProbeOrigins, len(names))
+	for i, name := range names {
+		// 0 is left to the events sent before the identifiers were introduced
+		origins[uint32(i+1)] = &ProbeOrigin{
+			Section: spec.Programs[name].SectionName,
+			Func:    name,
+		}
+	}
+	return origins
+}
+
+// ConstantEditors returns the constant editors writing its identifier in each program
+func (po ProbeOrigins) ConstantEditors() []manager.ConstantEditor {
+	editors := make([]manager.ConstantEditor, 0, len(po))
+	for id, origin := range po {
+		editors = append(editors, manager.ConstantEditor{
+			Name:  ProbeOriginConstant,
+			Value: uint64(id),
+			ProbeIdentificationPairs: []manager.ProbeIdentificationPair{
+				{EBPFSection: origin.Section, EBPFFuncName: origin.Func},
+			},
+		})
+	}
+	return editors
+}
 
 // KernelEvent represents the default kernel event context
 type KernelEvent struct {
@@ -27,6 +79,10 @@ type KernelEvent struct {
 	CPU    uint32    `json:"cpu"`
 	Type   EventType `json:"type"`
 	Action Action    `json:"action"`
+	// Probe is the eBPF program which sent the event, resolved from ProbeID
+	Probe *ProbeOrigin `json:"probe,omitempty"`
+	// ProbeID is the identifier of the eBPF program which sent the event, see ProbeOrigins
+	ProbeID uint32 `json:"-"`
 }
 
 // UnmarshalBinary unmarshalls a binary representation of itself
@@ -39,7 +95,8 @@ func (ke *KernelEvent) UnmarshalBinary(data []byte, resolver *TimeResolver) (int
 	ke.CPU = ByteOrder.Uint32(data[16:20])
 	ke.Type = EventType(ByteOrder.Uint32(data[20:24]))
 	ke.Action = Action(ByteOrder.Uint32(data[24:28]))
-	// padding 4 bytes
+	ke.ProbeID = ByteOrder.Uint32(data[28:32])
+	ke.Probe = nil
 	return 32, nil
 }
 
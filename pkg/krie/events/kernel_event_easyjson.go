@@ -1,3 +1,5 @@
+//go:build !krie_minimal
+
 // Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
 
 package events
@@ -49,6 +51,16 @@ func easyjson2269c87aDecodeGithubComGui774umeKriePkgKrieEvents(in *jlexer.Lexer,
 			out.Type = EventType(in.Uint32())
 		case "action":
 			out.Action = Action(in.Uint32())
+		case "probe":
+			if in.IsNull() {
+				in.Skip()
+				out.Probe = nil
+			} else {
+				if out.Probe == nil {
+					out.Probe = new(ProbeOrigin)
+				}
+				easyjson2269c87aDecodeGithubComGui774umeKriePkgKrieEvents1(in, out.Probe)
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -88,6 +100,11 @@ func easyjson2269c87aEncodeGithubComGui774umeKriePkgKrieEvents(out *jwriter.Writ
 		out.RawString(prefix)
 		out.Raw((in.Action).MarshalJSON())
 	}
+	if in.Probe != nil {
+		const prefix string = ",\"probe\":"
+		out.RawString(prefix)
+		easyjson2269c87aEncodeGithubComGui774umeKriePkgKrieEvents1(out, *in.Probe)
+	}
 	out.RawByte('}')
 }
 
@@ -100,3 +117,52 @@ func (v KernelEventSerializer) MarshalEasyJSON(w *jwriter.Writer) {
 func (v *KernelEventSerializer) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjson2269c87aDecodeGithubComGui774umeKriePkgKrieEvents(l, v)
 }
+func easyjson2269c87aDecodeGithubComGui774umeKriePkgKrieEvents1(in *jlexer.Lexer, out *ProbeOrigin) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "section":
+			out.Section = string(in.String())
+		case "func":
+			out.Func = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson2269c87aEncodeGithubComGui774umeKriePkgKrieEvents1(out *jwriter.Writer, in ProbeOrigin) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"section\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Section))
+	}
+	{
+		const prefix string = ",\"func\":"
+		out.RawString(prefix)
+		out.String(string(in.Func))
+	}
+	out.RawByte('}')
+}
@@ -0,0 +1,158 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+// kernelLogTamperEventLen is the length of the binary representation of a kernel_log_tamper event
+const kernelLogTamperEventLen = 16
+
+const (
+	// kernelLogSyslog is the source of the events generated by the syslog syscall
+	kernelLogSyslog = 1
+	// kernelLogKmsg is the source of the events generated by an open of /dev/kmsg
+	kernelLogKmsg = 2
+)
+
+// KernelLogOperation is the operation of a kernel_log_tamper event
+type KernelLogOperation uint32
+
+const (
+	// KernelLogReadClear is a syslog SYSLOG_ACTION_READ_CLEAR call, the ring buffer is read then cleared
+	KernelLogReadClear KernelLogOperation = 4
+	// KernelLogClear is a syslog SYSLOG_ACTION_CLEAR call (dmesg -C)
+	KernelLogClear KernelLogOperation = 5
+	// KernelLogConsoleOff is a syslog SYSLOG_ACTION_CONSOLE_OFF call, the messages are no longer printed on the console
+	KernelLogConsoleOff KernelLogOperation = 6
+	// KernelLogKmsgTruncate is an open of /dev/kmsg with O_TRUNC
+	KernelLogKmsgTruncate KernelLogOperation = 100
+)
+
+func (o KernelLogOperation) String() string {
+	switch o {
+	case KernelLogReadClear:
+		return "read_clear"
+	case KernelLogClear:
+		return "clear"
+	case KernelLogConsoleOff:
+		return "console_off"
+	case KernelLogKmsgTruncate:
+		return "kmsg_truncate"
+	default:
+		return fmt.Sprintf("KernelLogOperation(%d)", o)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the operation
+func (o KernelLogOperation) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", o)), nil
+}
+
+func addKernelLogTamperProbes(all *[]*manager.Probe) {
+	*all = append(*all, ExpandSyscallProbes(&manager.Probe{
+		ProbeIdentificationPair: manager.ProbeIdentificationPair{
+			UID: KRIEUID,
+		},
+		SyscallFuncName: "syslog",
+	}, EntryAndExit)...)
+	*all = append(*all, &manager.Probe{
+		ProbeIdentificationPair: manager.ProbeIdentificationPair{
+			UID:          KRIEUID,
+			EBPFSection:  "kprobe/devkmsg_open",
+			EBPFFuncName: "kprobe_devkmsg_open",
+		},
+	})
+}
+
+func addKernelLogTamperRoutes(all *[]manager.TailCallRoute) {
+	*all = append(*all, []manager.TailCallRoute{
+		{
+			ProgArrayName: "sys_exit_progs",
+			Key:           uint32(KernelLogTamperEventType),
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				EBPFSection:  "tracepoint/handle_sys_syslog_exit",
+				EBPFFuncName: "tracepoint_handle_sys_syslog_exit",
+			},
+		},
+	}...)
+}
+
+func addKernelLogTamperSelectors(all *[]manager.ProbesSelector) {
+	*all = append(*all,
+		&manager.OneOf{Selectors: ExpandSyscallProbesSelector(
+			manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: "syslog"}, EntryAndExit),
+		},
+		&manager.AllOf{Selectors: []manager.ProbesSelector{
+			&manager.ProbeSelector{
+				ProbeIdentificationPair: manager.ProbeIdentificationPair{
+					UID:          KRIEUID,
+					EBPFSection:  "kprobe/devkmsg_open",
+					EBPFFuncName: "kprobe_devkmsg_open",
+				},
+			},
+		}},
+	)
+}
+
+// KernelLogTamperEvent represents an attempt to erase the kernel log, or to hide the kernel messages from the console.
+// Clearing the kernel log right after a module load is a common way to hide the warnings of an unsigned or out of tree
+// module.
+type KernelLogTamperEvent struct {
+	Operation KernelLogOperation `json:"operation"`
+	// Flags are the open flags of /dev/kmsg, only set by kmsg_truncate
+	Flags uint32 `json:"flags,omitempty"`
+	// AfterModuleLoad is the module loaded shortly before the event, if any
+	AfterModuleLoad string `json:"after_module_load,omitempty"`
+	// SinceModuleLoad is the time elapsed since the load of AfterModuleLoad, in seconds
+	SinceModuleLoad float64 `json:"since_module_load,omitempty"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *KernelLogTamperEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < kernelLogTamperEventLen {
+		return 0, fmt.Errorf("while parsing KernelLogTamperEvent, got len %d, needed %d: %w", len(data), kernelLogTamperEventLen, ErrNotEnoughData)
+	}
+	switch ByteOrder.Uint32(data[0:4]) {
+	case kernelLogKmsg:
+		e.Operation = KernelLogKmsgTruncate
+	default:
+		e.Operation = KernelLogOperation(ByteOrder.Uint32(data[4:8]))
+	}
+	e.Flags = ByteOrder.Uint32(data[8:12])
+	e.AfterModuleLoad = ""
+	e.SinceModuleLoad = 0
+	return kernelLogTamperEventLen, nil
+}
+
+// KernelLogTamperEventSerializer is used to serialize KernelLogTamperEvent
+// easyjson:json
+type KernelLogTamperEventSerializer struct {
+	*KernelLogTamperEvent
+}
+
+// NewKernelLogTamperEventSerializer returns a new instance of KernelLogTamperEventSerializer
+func NewKernelLogTamperEventSerializer(e *KernelLogTamperEvent) *KernelLogTamperEventSerializer {
+	return &KernelLogTamperEventSerializer{
+		KernelLogTamperEvent: e,
+	}
+}
@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers $GOFILE
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
 
 package events
 
@@ -25,8 +25,17 @@ import (
 	manager "github.com/DataDog/ebpf-manager"
 )
 
-// ModuleNameLen is the length of the name of a kernel module
-const ModuleNameLen = 56
+const (
+	// ModuleNameLen is the length of the name of a kernel module
+	ModuleNameLen = 56
+	// ModuleFileNameLen is the length of the file name of a module loaded by finit_module
+	ModuleFileNameLen = 64
+	// initModuleEventLen is the length of the binary representation of an init_module event
+	initModuleEventLen = 8 + ModuleNameLen + 16 + ModuleFileNameLen
+
+	// FilelessRule is the sub rule of the module and bpf program loads which don't come from a file on disk
+	FilelessRule = "fileless"
+)
 
 func addKernelModuleProbes(all *[]*manager.Probe, events EventTypeList) {
 	// init_module
@@ -131,23 +140,82 @@ func addAllKernelModuleProbesSelectors(all *[]manager.ProbesSelector, events Eve
 type InitModuleEvent struct {
 	LoadedFromMemory bool   `json:"loaded_from_memory"`
 	Name             string `json:"name"`
+
+	// FileName is the name of the file loaded by finit_module
+	FileName string `json:"file_name,omitempty"`
+	// Path is the path of the file loaded by finit_module, when it could be resolved
+	Path string `json:"path,omitempty"`
+	// SHA256 is the checksum of the file loaded by finit_module, when it could be resolved
+	SHA256 string `json:"sha256,omitempty"`
+	// Inode is the inode of the file loaded by finit_module
+	Inode uint64 `json:"inode,omitempty"`
+	// FD is the file descriptor passed to finit_module
+	FD int32 `json:"-"`
+	// Device is the device of the file loaded by finit_module, in the kernel encoding
+	Device uint32 `json:"-"`
+	// Unlinked is set when the file loaded by finit_module has no link left: a memfd or a deleted file
+	Unlinked bool `json:"-"`
+	// Fileless is set when the module doesn't come from a file on disk: the module file or the executable of the
+	// loader is a memfd or a deleted file
+	Fileless bool `json:"fileless,omitempty"`
+	// FilelessOrigin is the memfd or the deleted file the module, or its loader, comes from
+	FilelessOrigin string `json:"fileless_origin,omitempty"`
+
+	// ModInfo is the module information parsed from the module file, when it could be resolved
+	ModInfo *ModuleInfo `json:"modinfo,omitempty"`
+	// SignerPolicy is the decision of the module signer policy, if any
+	SignerPolicy string `json:"signer_policy,omitempty"`
+	// ImagePolicy is the decision of the container image policy, if the module was loaded from a container
+	ImagePolicy string `json:"image_policy,omitempty"`
+	// Unloaded is set when the module was unloaded by the module signer policy or the container image policy
+	Unloaded bool `json:"unloaded,omitempty"`
+}
+
+// ModuleInfo is the information of a kernel module file, as reported by modinfo
+type ModuleInfo struct {
+	Path        string   `json:"path"`
+	Version     string   `json:"version,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	License     string   `json:"license,omitempty"`
+	Depends     []string `json:"depends,omitempty"`
+	Vermagic    string   `json:"vermagic,omitempty"`
+	InTree      bool     `json:"intree"`
+	Signer      string   `json:"signer,omitempty"`
+	SigKey      string   `json:"sig_key,omitempty"`
+	SigHashAlgo string   `json:"sig_hashalgo,omitempty"`
+
+	// ImplantHeuristic is set for a GPL module without signer nor dependencies, a strong indicator of an out-of-tree
+	// implant
+	ImplantHeuristic bool   `json:"implant_heuristic,omitempty"`
+	Error            string `json:"error,omitempty"`
 }
 
 // UnmarshallBinary unmarshalls a binary representation of itself
 func (e *InitModuleEvent) UnmarshallBinary(data []byte) (int, error) {
-	if len(data) < 8+ModuleNameLen {
-		return 0, fmt.Errorf("while parsing InitModuleEvent, got len %d, needed %d: %w", len(data), 8+ModuleNameLen, ErrNotEnoughData)
+	if len(data) < initModuleEventLen {
+		return 0, fmt.Errorf("while parsing InitModuleEvent, got len %d, needed %d: %w", len(data), initModuleEventLen, ErrNotEnoughData)
 	}
 
 	if ByteOrder.Uint32(data[0:4]) == 1 {
 		e.LoadedFromMemory = true
 	}
+	e.FD = int32(ByteOrder.Uint32(data[4:8]))
 	var err error
 	e.Name, err = UnmarshalString(data[8:8+ModuleNameLen], ModuleNameLen)
 	if err != nil {
 		return 0, err
 	}
-	return 8 + ModuleNameLen, nil
+	cursor := 8 + ModuleNameLen
+	e.Inode = ByteOrder.Uint64(data[cursor : cursor+8])
+	e.Device = ByteOrder.Uint32(data[cursor+8 : cursor+12])
+	e.Unlinked = !e.LoadedFromMemory && ByteOrder.Uint32(data[cursor+12:cursor+16]) == 0
+	cursor += 16
+	e.FileName, err = UnmarshalString(data[cursor:cursor+ModuleFileNameLen], ModuleFileNameLen)
+	if err != nil {
+		return 0, err
+	}
+	return initModuleEventLen, nil
 }
 
 // InitModuleEventSerializer is used to serialize InitModuleEvent
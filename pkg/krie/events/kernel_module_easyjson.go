@@ -1,3 +1,5 @@
+//go:build !krie_minimal
+
 // Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
 
 package events
@@ -41,6 +43,34 @@ func easyjson6d00de40DecodeGithubComGui774umeKriePkgKrieEvents(in *jlexer.Lexer,
 			out.LoadedFromMemory = bool(in.Bool())
 		case "name":
 			out.Name = string(in.String())
+		case "file_name":
+			out.FileName = string(in.String())
+		case "path":
+			out.Path = string(in.String())
+		case "sha256":
+			out.SHA256 = string(in.String())
+		case "inode":
+			out.Inode = uint64(in.Uint64())
+		case "modinfo":
+			if in.IsNull() {
+				in.Skip()
+				out.ModInfo = nil
+			} else {
+				if out.ModInfo == nil {
+					out.ModInfo = new(ModuleInfo)
+				}
+				easyjson6d00de40DecodeGithubComGui774umeKriePkgKrieEvents2(in, out.ModInfo)
+			}
+		case "signer_policy":
+			out.SignerPolicy = string(in.String())
+		case "image_policy":
+			out.ImagePolicy = string(in.String())
+		case "unloaded":
+			out.Unloaded = bool(in.Bool())
+		case "fileless":
+			out.Fileless = bool(in.Bool())
+		case "fileless_origin":
+			out.FilelessOrigin = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -65,6 +95,56 @@ func easyjson6d00de40EncodeGithubComGui774umeKriePkgKrieEvents(out *jwriter.Writ
 		out.RawString(prefix)
 		out.String(string(in.Name))
 	}
+	if in.FileName != "" {
+		const prefix string = ",\"file_name\":"
+		out.RawString(prefix)
+		out.String(string(in.FileName))
+	}
+	if in.Path != "" {
+		const prefix string = ",\"path\":"
+		out.RawString(prefix)
+		out.String(string(in.Path))
+	}
+	if in.SHA256 != "" {
+		const prefix string = ",\"sha256\":"
+		out.RawString(prefix)
+		out.String(string(in.SHA256))
+	}
+	if in.Inode != 0 {
+		const prefix string = ",\"inode\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Inode))
+	}
+	if in.ModInfo != nil {
+		const prefix string = ",\"modinfo\":"
+		out.RawString(prefix)
+		easyjson6d00de40EncodeGithubComGui774umeKriePkgKrieEvents2(out, *in.ModInfo)
+	}
+	if in.SignerPolicy != "" {
+		const prefix string = ",\"signer_policy\":"
+		out.RawString(prefix)
+		out.String(string(in.SignerPolicy))
+	}
+	if in.ImagePolicy != "" {
+		const prefix string = ",\"image_policy\":"
+		out.RawString(prefix)
+		out.String(string(in.ImagePolicy))
+	}
+	if in.Unloaded {
+		const prefix string = ",\"unloaded\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Unloaded))
+	}
+	if in.Fileless {
+		const prefix string = ",\"fileless\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Fileless))
+	}
+	if in.FilelessOrigin != "" {
+		const prefix string = ",\"fileless_origin\":"
+		out.RawString(prefix)
+		out.String(string(in.FilelessOrigin))
+	}
 	out.RawByte('}')
 }
 
@@ -130,3 +210,159 @@ func (v DeleteModuleEventSerializer) MarshalEasyJSON(w *jwriter.Writer) {
 func (v *DeleteModuleEventSerializer) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjson6d00de40DecodeGithubComGui774umeKriePkgKrieEvents1(l, v)
 }
+func easyjson6d00de40DecodeGithubComGui774umeKriePkgKrieEvents2(in *jlexer.Lexer, out *ModuleInfo) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "path":
+			out.Path = string(in.String())
+		case "version":
+			out.Version = string(in.String())
+		case "description":
+			out.Description = string(in.String())
+		case "author":
+			out.Author = string(in.String())
+		case "license":
+			out.License = string(in.String())
+		case "depends":
+			if in.IsNull() {
+				in.Skip()
+				out.Depends = nil
+			} else {
+				in.Delim('[')
+				if out.Depends == nil {
+					if !in.IsDelim(']') {
+						out.Depends = make([]string, 0, 4)
+					} else {
+						out.Depends = []string{}
+					}
+				} else {
+					out.Depends = (out.Depends)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.Depends = append(out.Depends, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "vermagic":
+			out.Vermagic = string(in.String())
+		case "intree":
+			out.InTree = bool(in.Bool())
+		case "signer":
+			out.Signer = string(in.String())
+		case "sig_key":
+			out.SigKey = string(in.String())
+		case "sig_hashalgo":
+			out.SigHashAlgo = string(in.String())
+		case "implant_heuristic":
+			out.ImplantHeuristic = bool(in.Bool())
+		case "error":
+			out.Error = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson6d00de40EncodeGithubComGui774umeKriePkgKrieEvents2(out *jwriter.Writer, in ModuleInfo) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"path\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Path))
+	}
+	if in.Version != "" {
+		const prefix string = ",\"version\":"
+		out.RawString(prefix)
+		out.String(string(in.Version))
+	}
+	if in.Description != "" {
+		const prefix string = ",\"description\":"
+		out.RawString(prefix)
+		out.String(string(in.Description))
+	}
+	if in.Author != "" {
+		const prefix string = ",\"author\":"
+		out.RawString(prefix)
+		out.String(string(in.Author))
+	}
+	if in.License != "" {
+		const prefix string = ",\"license\":"
+		out.RawString(prefix)
+		out.String(string(in.License))
+	}
+	if len(in.Depends) != 0 {
+		const prefix string = ",\"depends\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.Depends {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.Vermagic != "" {
+		const prefix string = ",\"vermagic\":"
+		out.RawString(prefix)
+		out.String(string(in.Vermagic))
+	}
+	{
+		const prefix string = ",\"intree\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.InTree))
+	}
+	if in.Signer != "" {
+		const prefix string = ",\"signer\":"
+		out.RawString(prefix)
+		out.String(string(in.Signer))
+	}
+	if in.SigKey != "" {
+		const prefix string = ",\"sig_key\":"
+		out.RawString(prefix)
+		out.String(string(in.SigKey))
+	}
+	if in.SigHashAlgo != "" {
+		const prefix string = ",\"sig_hashalgo\":"
+		out.RawString(prefix)
+		out.String(string(in.SigHashAlgo))
+	}
+	if in.ImplantHeuristic {
+		const prefix string = ",\"implant_heuristic\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.ImplantHeuristic))
+	}
+	if in.Error != "" {
+		const prefix string = ",\"error\":"
+		out.RawString(prefix)
+		out.String(string(in.Error))
+	}
+	out.RawByte('}')
+}
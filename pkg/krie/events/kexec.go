@@ -0,0 +1,169 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"bytes"
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	// kexecCmdlineLen is the maximum length of the captured command line of a kexec_file_load call
+	kexecCmdlineLen = 256
+	// kexecEventLen is the length of the binary representation of a kexec event
+	kexecEventLen = 40 + kexecCmdlineLen
+	// kexecArchMask is the mask of the architecture bits of the kexec_load flags
+	kexecArchMask = 0xffff0000
+)
+
+func addKexecProbes(all *[]*manager.Probe) {
+	*all = append(*all, ExpandSyscallProbes(&manager.Probe{
+		ProbeIdentificationPair: manager.ProbeIdentificationPair{
+			UID: KRIEUID,
+		},
+		SyscallFuncName: "kexec_load",
+	}, EntryAndExit)...)
+	*all = append(*all, ExpandSyscallProbes(&manager.Probe{
+		ProbeIdentificationPair: manager.ProbeIdentificationPair{
+			UID: KRIEUID,
+		},
+		SyscallFuncName: "kexec_file_load",
+	}, EntryAndExit)...)
+}
+
+func addKexecRoutes(all *[]manager.TailCallRoute) {
+	*all = append(*all, []manager.TailCallRoute{
+		{
+			ProgArrayName: "sys_exit_progs",
+			Key:           uint32(KexecEventType),
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				EBPFSection:  "tracepoint/handle_sys_kexec_exit",
+				EBPFFuncName: "tracepoint_handle_sys_kexec_exit",
+			},
+		},
+	}...)
+}
+
+func addKexecSelectors(all *[]manager.ProbesSelector) {
+	// kexec_file_load isn't available on every architecture
+	*all = append(*all,
+		&manager.OneOf{Selectors: ExpandSyscallProbesSelector(
+			manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: "kexec_load"}, EntryAndExit),
+		},
+		&manager.BestEffort{Selectors: ExpandSyscallProbesSelector(
+			manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: "kexec_file_load"}, EntryAndExit),
+		},
+	)
+}
+
+var (
+	// kexecLoadFlags are the flags of the kexec_load syscall
+	kexecLoadFlags = []struct {
+		flag uint64
+		name string
+	}{
+		{1, "KEXEC_ON_CRASH"},
+		{2, "KEXEC_PRESERVE_CONTEXT"},
+	}
+	// kexecFileLoadFlags are the flags of the kexec_file_load syscall
+	kexecFileLoadFlags = []struct {
+		flag uint64
+		name string
+	}{
+		{1, "KEXEC_FILE_UNLOAD"},
+		{2, "KEXEC_FILE_ON_CRASH"},
+		{4, "KEXEC_FILE_NO_INITRAMFS"},
+	}
+)
+
+// KexecEvent represents a kexec_load or kexec_file_load event
+type KexecEvent struct {
+	Syscall    string        `json:"syscall"`
+	Flags      []string      `json:"flags,omitempty"`
+	Entry      MemoryPointer `json:"entry,omitempty"`
+	Segments   uint64        `json:"segments,omitempty"`
+	KernelFD   int32         `json:"kernel_fd,omitempty"`
+	InitrdFD   int32         `json:"initrd_fd,omitempty"`
+	Cmdline    string        `json:"cmdline,omitempty"`
+	KernelPath string        `json:"kernel_path,omitempty"`
+	InitrdPath string        `json:"initrd_path,omitempty"`
+	// Unload is set when the call unloads the kernel image loaded previously
+	Unload bool `json:"unload,omitempty"`
+}
+
+// IsFileLoad returns true if the event was generated by the kexec_file_load syscall
+func (e *KexecEvent) IsFileLoad() bool {
+	return e.Syscall == "kexec_file_load"
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *KexecEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < kexecEventLen {
+		return 0, fmt.Errorf("while parsing KexecEvent, got len %d, needed %d: %w", len(data), kexecEventLen, ErrNotEnoughData)
+	}
+	e.Entry = MemoryPointer(ByteOrder.Uint64(data[0:8]))
+	e.Segments = ByteOrder.Uint64(data[8:16])
+	flags := ByteOrder.Uint64(data[16:24])
+	e.KernelFD = int32(ByteOrder.Uint32(data[24:28]))
+	e.InitrdFD = int32(ByteOrder.Uint32(data[28:32]))
+	fileLoad := ByteOrder.Uint32(data[32:36]) == 1
+	cmdlineLen := ByteOrder.Uint32(data[36:40])
+
+	known := kexecLoadFlags
+	e.Syscall = "kexec_load"
+	if fileLoad {
+		known = kexecFileLoadFlags
+		e.Syscall = "kexec_file_load"
+		if cmdlineLen > 0 {
+			e.Cmdline = string(bytes.TrimRight(bytes.SplitN(data[40:kexecEventLen], []byte{0}, 2)[0], "\n"))
+		}
+		e.Unload = flags&1 == 1
+	} else {
+		// kexec_load unloads the current image when no segment is provided
+		e.Unload = e.Segments == 0
+		e.KernelFD, e.InitrdFD = 0, 0
+		// the upper bits of the flags of kexec_load are the architecture of the new kernel
+		flags &^= kexecArchMask
+	}
+	for _, f := range known {
+		if flags&f.flag == f.flag {
+			e.Flags = append(e.Flags, f.name)
+			flags &^= f.flag
+		}
+	}
+	if flags != 0 {
+		e.Flags = append(e.Flags, fmt.Sprintf("%#x", flags))
+	}
+	return kexecEventLen, nil
+}
+
+// KexecEventSerializer is used to serialize KexecEvent
+// easyjson:json
+type KexecEventSerializer struct {
+	*KexecEvent
+}
+
+// NewKexecEventSerializer returns a new instance of KexecEventSerializer
+func NewKexecEventSerializer(e *KexecEvent) *KexecEventSerializer {
+	return &KexecEventSerializer{
+		KexecEvent: e,
+	}
+}
@@ -0,0 +1,285 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+	"strconv"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	// KeyringTypeLen is the maximum length of the type of a key
+	KeyringTypeLen = 32
+	// KeyringDescriptionLen is the maximum length of the description of a key
+	KeyringDescriptionLen = 128
+	// keyringEventLen is the length of the binary representation of a keyring event
+	keyringEventLen = 24 + KeyringTypeLen + KeyringDescriptionLen
+
+	// AsymmetricKeyType is the type of the public keys, the keys of the trusted keyrings verify the signatures of the
+	// kernel modules
+	AsymmetricKeyType = "asymmetric"
+)
+
+// keyringSyscalls are the syscalls of the keyring events
+var keyringSyscalls = []string{"add_key", "request_key", "keyctl"}
+
+// KeyringSyscall is the syscall of a keyring event
+type KeyringSyscall uint32
+
+const (
+	// KeyringAddKey is an add_key call
+	KeyringAddKey KeyringSyscall = iota + 1
+	// KeyringRequestKey is a request_key call
+	KeyringRequestKey
+	// KeyringKeyctl is a keyctl call
+	KeyringKeyctl
+)
+
+func (s KeyringSyscall) String() string {
+	switch s {
+	case KeyringAddKey:
+		return "add_key"
+	case KeyringRequestKey:
+		return "request_key"
+	case KeyringKeyctl:
+		return "keyctl"
+	default:
+		return fmt.Sprintf("KeyringSyscall(%d)", s)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the syscall
+func (s KeyringSyscall) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", s)), nil
+}
+
+// KeyctlCommand is the command of a keyctl call
+type KeyctlCommand uint32
+
+// the keyctl commands, see include/uapi/linux/keyctl.h
+const (
+	KeyctlGetKeyringID KeyctlCommand = iota
+	KeyctlJoinSessionKeyring
+	KeyctlUpdate
+	KeyctlRevoke
+	KeyctlChown
+	KeyctlSetPerm
+	KeyctlDescribe
+	KeyctlClear
+	KeyctlLink
+	KeyctlUnlink
+	KeyctlSearch
+	KeyctlRead
+	KeyctlInstantiate
+	KeyctlNegate
+	KeyctlSetReqKeyKeyring
+	KeyctlSetTimeout
+	KeyctlAssumeAuthority
+	KeyctlGetSecurity
+	KeyctlSessionToParent
+	KeyctlReject
+	KeyctlInstantiateIOV
+	KeyctlInvalidate
+	KeyctlGetPersistent
+	KeyctlDHCompute
+	KeyctlPKeyQuery
+	KeyctlPKeyEncrypt
+	KeyctlPKeyDecrypt
+	KeyctlPKeySign
+	KeyctlPKeyVerify
+	KeyctlRestrictKeyring
+	KeyctlMove
+	KeyctlCapabilities
+	KeyctlWatchKey
+)
+
+var keyctlCommandNames = []string{
+	"get_keyring_id",
+	"join_session_keyring",
+	"update",
+	"revoke",
+	"chown",
+	"setperm",
+	"describe",
+	"clear",
+	"link",
+	"unlink",
+	"search",
+	"read",
+	"instantiate",
+	"negate",
+	"set_reqkey_keyring",
+	"set_timeout",
+	"assume_authority",
+	"get_security",
+	"session_to_parent",
+	"reject",
+	"instantiate_iov",
+	"invalidate",
+	"get_persistent",
+	"dh_compute",
+	"pkey_query",
+	"pkey_encrypt",
+	"pkey_decrypt",
+	"pkey_sign",
+	"pkey_verify",
+	"restrict_keyring",
+	"move",
+	"capabilities",
+	"watch_key",
+}
+
+func (c KeyctlCommand) String() string {
+	if int(c) < len(keyctlCommandNames) {
+		return keyctlCommandNames[c]
+	}
+	return fmt.Sprintf("KeyctlCommand(%d)", c)
+}
+
+// MarshalJSON returns the JSON encoding of the command
+func (c KeyctlCommand) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", c)), nil
+}
+
+// ChangesAccess returns true if the command changes who can use a key: its owner, its permissions, or the keyrings
+// it is linked to
+func (c KeyctlCommand) ChangesAccess() bool {
+	switch c {
+	case KeyctlChown, KeyctlSetPerm, KeyctlLink, KeyctlSessionToParent, KeyctlRestrictKeyring, KeyctlMove:
+		return true
+	}
+	return false
+}
+
+// KeySerial is the serial number of a key, or one of the special keyrings of the calling process
+type KeySerial int32
+
+var specialKeyrings = map[KeySerial]string{
+	-1: "@t",
+	-2: "@p",
+	-3: "@s",
+	-4: "@u",
+	-5: "@us",
+	-6: "@g",
+	-7: "@a",
+}
+
+// String returns the serial number of the key, the special keyrings use the notation of keyctl(1) (@s, @u, ...)
+func (s KeySerial) String() string {
+	if name, ok := specialKeyrings[s]; ok {
+		return name
+	}
+	return strconv.FormatInt(int64(s), 10)
+}
+
+// MarshalJSON returns the JSON encoding of the serial number
+func (s KeySerial) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", s)), nil
+}
+
+func addKeyringProbes(all *[]*manager.Probe) {
+	for _, syscall := range keyringSyscalls {
+		*all = append(*all, ExpandSyscallProbes(&manager.Probe{
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				UID: KRIEUID,
+			},
+			SyscallFuncName: syscall,
+		}, EntryAndExit)...)
+	}
+}
+
+func addKeyringRoutes(all *[]manager.TailCallRoute) {
+	*all = append(*all, []manager.TailCallRoute{
+		{
+			ProgArrayName: "sys_exit_progs",
+			Key:           uint32(KeyringEventType),
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				EBPFSection:  "tracepoint/handle_sys_keyring_exit",
+				EBPFFuncName: "tracepoint_handle_sys_keyring_exit",
+			},
+		},
+	}...)
+}
+
+func addKeyringSelectors(all *[]manager.ProbesSelector) {
+	// the keyring syscalls can be compiled out (CONFIG_KEYS)
+	for _, syscall := range keyringSyscalls {
+		*all = append(*all,
+			&manager.BestEffort{Selectors: ExpandSyscallProbesSelector(
+				manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: syscall}, EntryAndExit),
+			},
+		)
+	}
+}
+
+// KeyringEvent represents an add_key, request_key or keyctl call. The queries of keyctl (describe, read, ...) aren't
+// monitored. The kernel keyrings hold the keys trusted for the module signatures, adding a key to them, or a key
+// injected in the keyring of another process, is how an attacker gets its code trusted.
+type KeyringEvent struct {
+	Syscall KeyringSyscall `json:"syscall"`
+	// Command is the command of a keyctl call
+	Command KeyctlCommand `json:"command,omitempty"`
+	// Type is the type of the key (user, logon, keyring, asymmetric, ...), set by add_key, request_key and the keyctl
+	// search command
+	Type string `json:"type,omitempty"`
+	// Description is the description of the key, or the name of the session keyring joined by keyctl
+	Description string `json:"description,omitempty"`
+	// Key is the destination keyring of add_key and request_key, or the key of the keyctl command
+	Key KeySerial `json:"key,omitempty"`
+	// PayloadLen is the length of the payload of add_key, or of the keyctl update and instantiate commands
+	PayloadLen uint64 `json:"payload_len,omitempty"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *KeyringEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < keyringEventLen {
+		return 0, fmt.Errorf("while parsing KeyringEvent, got len %d, needed %d: %w", len(data), keyringEventLen, ErrNotEnoughData)
+	}
+	e.PayloadLen = ByteOrder.Uint64(data[0:8])
+	e.Syscall = KeyringSyscall(ByteOrder.Uint32(data[8:12]))
+	e.Command = KeyctlCommand(ByteOrder.Uint32(data[12:16]))
+	e.Key = KeySerial(int32(ByteOrder.Uint32(data[16:20])))
+	// padding
+
+	var err error
+	e.Type, err = UnmarshalString(data[24:24+KeyringTypeLen], KeyringTypeLen)
+	if err != nil {
+		return 0, err
+	}
+	e.Description, err = UnmarshalString(data[24+KeyringTypeLen:keyringEventLen], KeyringDescriptionLen)
+	if err != nil {
+		return 0, err
+	}
+	return keyringEventLen, nil
+}
+
+// KeyringEventSerializer is used to serialize KeyringEvent
+// easyjson:json
+type KeyringEventSerializer struct {
+	*KeyringEvent
+}
+
+// NewKeyringEventSerializer returns a new instance of KeyringEventSerializer
+func NewKeyringEventSerializer(e *KeyringEvent) *KeyringEventSerializer {
+	return &KeyringEventSerializer{
+		KeyringEvent: e,
+	}
+}
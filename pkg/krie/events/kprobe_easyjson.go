@@ -1,3 +1,5 @@
+//go:build !krie_minimal
+
 // Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
 
 package events
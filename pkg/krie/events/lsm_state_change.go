@@ -0,0 +1,133 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+package events
+
+import (
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+// LSMStateProfileLen is the maximum length of the name of an AppArmor profile
+const LSMStateProfileLen = 128
+
+// LSMStateChange is the change of an lsm_state_change event
+type LSMStateChange uint32
+
+const (
+	// SELinuxPermissive is a write of 0 to /sys/fs/selinux/enforce
+	SELinuxPermissive LSMStateChange = iota + 1
+	// SELinuxDisable is a runtime disable of SELinux through /sys/fs/selinux/disable
+	SELinuxDisable
+	// SELinuxStateTampering is a switch of SELinux to permissive mode without any write to /sys/fs/selinux/enforce, the
+	// way a rootkit patches selinux_state
+	SELinuxStateTampering
+	// AppArmorProfileRemoval is the removal of an AppArmor profile
+	AppArmorProfileRemoval
+)
+
+func (c LSMStateChange) String() string {
+	switch c {
+	case SELinuxPermissive:
+		return "selinux_permissive"
+	case SELinuxDisable:
+		return "selinux_disable"
+	case SELinuxStateTampering:
+		return "selinux_state_tampering"
+	case AppArmorProfileRemoval:
+		return "apparmor_profile_removal"
+	default:
+		return fmt.Sprintf("LSMStateChange(%d)", c)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the change
+func (c LSMStateChange) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", c)), nil
+}
+
+// LSM returns the LSM weakened by the change
+func (c LSMStateChange) LSM() string {
+	if c == AppArmorProfileRemoval {
+		return "apparmor"
+	}
+	return "selinux"
+}
+
+func lsmStateChangeProbes() []manager.ProbeIdentificationPair {
+	return []manager.ProbeIdentificationPair{
+		{UID: KRIEUID, EBPFSection: "kprobe/sel_write_enforce", EBPFFuncName: "kprobe_sel_write_enforce"},
+		{UID: KRIEUID, EBPFSection: "kprobe/sel_write_disable", EBPFFuncName: "kprobe_sel_write_disable"},
+		{UID: KRIEUID, EBPFSection: "kprobe/aa_remove_profiles", EBPFFuncName: "kprobe_aa_remove_profiles"},
+	}
+}
+
+func addLSMStateChangeProbes(all *[]*manager.Probe) {
+	for _, id := range lsmStateChangeProbes() {
+		*all = append(*all, &manager.Probe{ProbeIdentificationPair: id})
+	}
+}
+
+func addLSMStateChangeSelectors(all *[]manager.ProbesSelector) {
+	// a kernel usually runs either SELinux or AppArmor, and the runtime disable of SELinux was removed in 6.4
+	var selectors []manager.ProbesSelector
+	for _, id := range lsmStateChangeProbes() {
+		selectors = append(selectors, &manager.ProbeSelector{ProbeIdentificationPair: id})
+	}
+	*all = append(*all, &manager.BestEffort{Selectors: selectors})
+}
+
+// LSMStateChangeEvent represents a change weakening the enforcement of an LSM: SELinux switched to permissive mode or
+// disabled, or an AppArmor profile removed. Attackers disable the LSMs right after a privilege escalation.
+type LSMStateChangeEvent struct {
+	Change LSMStateChange `json:"change"`
+	LSM    string         `json:"lsm"`
+	// Value is the value written to /sys/fs/selinux (-1 if it isn't a number), or the value read from
+	// /sys/fs/selinux/enforce for a selinux_state_tampering change
+	Value int32 `json:"value"`
+	// Profile is the name of the removed AppArmor profile
+	Profile string `json:"profile,omitempty"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *LSMStateChangeEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < 136 {
+		return 0, fmt.Errorf("while parsing LSMStateChangeEvent, got len %d, needed %d: %w", len(data), 136, ErrNotEnoughData)
+	}
+	e.Change = LSMStateChange(ByteOrder.Uint32(data[0:4]))
+	e.LSM = e.Change.LSM()
+	e.Value = int32(ByteOrder.Uint32(data[4:8]))
+	var err error
+	if e.Profile, err = UnmarshalString(data[8:136], LSMStateProfileLen); err != nil {
+		return 0, err
+	}
+	return 136, nil
+}
+
+// LSMStateChangeEventSerializer is used to serialize LSMStateChangeEvent
+// easyjson:json
+type LSMStateChangeEventSerializer struct {
+	*LSMStateChangeEvent
+}
+
+// NewLSMStateChangeEventSerializer returns a new instance of LSMStateChangeEventSerializer
+func NewLSMStateChangeEventSerializer(e *LSMStateChangeEvent) *LSMStateChangeEventSerializer {
+	return &LSMStateChangeEventSerializer{
+		LSMStateChangeEvent: e,
+	}
+}
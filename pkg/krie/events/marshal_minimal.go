@@ -0,0 +1,35 @@
+//go:build krie_minimal
+
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"encoding/json"
+)
+
+// MarshalJSON serializes the event with encoding/json: the minimal build profile strips the easyjson serializers. The
+// output is the same as the output of the easyjson serializers, except for the empty lists and maps which are
+// serialized as null.
+func (e *Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewEventSerializer(e))
+}
+
+// UnmarshalEventSerializer parses a JSON event
+func UnmarshalEventSerializer(data []byte, s *EventSerializer) error {
+	return json.Unmarshal(data, s)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"time"
+)
+
+const (
+	// ModuleLifecycleLoad is the lifecycle stage of an init_module event
+	ModuleLifecycleLoad = "load"
+	// ModuleLifecycleHook is the lifecycle stage of a hook installed by a module (ftrace callback, syscall handler)
+	ModuleLifecycleHook = "hook"
+	// ModuleLifecycleTaint is the lifecycle stage of a module_taint_change event
+	ModuleLifecycleTaint = "taint"
+	// ModuleLifecycleUnload is the lifecycle stage of a delete_module event
+	ModuleLifecycleUnload = "unload"
+)
+
+// ModuleLifecycle links the events of the lifecycle of a kernel module: its load, the hooks it installs, the changes of
+// its taint flags and its unload share the same instance ID
+type ModuleLifecycle struct {
+	// ID identifies the instance of the module until it is unloaded, a module reloaded with the same name gets a new ID
+	ID     string `json:"id"`
+	Module string `json:"module"`
+	// Stage is the lifecycle stage of the event
+	Stage string `json:"stage,omitempty"`
+	// Stages are the lifecycle stages observed since the module was first seen
+	Stages []string `json:"stages,omitempty"`
+	// Sequence is the rank of the event in the lifecycle of the module
+	Sequence uint64 `json:"sequence"`
+	// FirstSeen is the time of the first event of the module
+	FirstSeen time.Time `json:"first_seen"`
+	// Taint are the taint flags of the module (/sys/module/[name]/taint)
+	Taint string `json:"taint,omitempty"`
+	// LoadEventID is the ID of the event which loaded the module, it is empty if the module was loaded before KRIE
+	LoadEventID string `json:"load_event_id,omitempty"`
+	// PreviousEventID is the ID of the previous event of the module
+	PreviousEventID string `json:"previous_event_id,omitempty"`
+}
+
+// LifecycleModule returns the kernel module the event is attributed to, and the lifecycle stage of the event. The
+// modules of the hooks are resolved from the kernel symbols, the hooks of a module hidden from kallsyms can't be
+// attributed.
+func (e *Event) LifecycleModule() (string, string) {
+	var module, stage string
+	switch e.Kernel.Type {
+	case InitModuleEventType:
+		module, stage = e.InitModule.Name, ModuleLifecycleLoad
+	case DeleteModuleEventType:
+		module, stage = e.DeleteModule.Name, ModuleLifecycleUnload
+	case FTraceHookEventType:
+		module, stage = e.FTraceHookEvent.Callback.Module, ModuleLifecycleHook
+	case NetfilterHookEventType:
+		module, stage = e.NetfilterHookEvent.Hook.Module, ModuleLifecycleHook
+	case HookedSyscallEventType, HookedSyscallTableEventType:
+		module, stage = e.HookedSyscallEvent.NewHandler.Module, ModuleLifecycleHook
+	}
+	if module == "system" || module == "unknown" {
+		return "", ""
+	}
+	return module, stage
+}
+
+// ModuleTaintChangeEvent is the event generated when the taint flags of a module tracked by the module lifecycle
+// tracker change
+type ModuleTaintChangeEvent struct {
+	Module        string          `json:"module"`
+	Taint         string          `json:"taint"`
+	PreviousTaint string          `json:"previous_taint"`
+	Lifecycle     ModuleLifecycle `json:"lifecycle"`
+}
+
+// ModuleTaintChangeEventSerializer is used to serialize ModuleTaintChangeEvent
+// easyjson:json
+type ModuleTaintChangeEventSerializer struct {
+	*ModuleTaintChangeEvent
+}
+
+// NewModuleTaintChangeEventSerializer returns a new instance of ModuleTaintChangeEventSerializer
+func NewModuleTaintChangeEventSerializer(e *ModuleTaintChangeEvent) *ModuleTaintChangeEventSerializer {
+	return &ModuleTaintChangeEventSerializer{
+		ModuleTaintChangeEvent: e,
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+// moduleSignatureEventLen is the length of the binary representation of a module_signature event
+const moduleSignatureEventLen = 16 + ModuleNameLen
+
+// ModuleSignatureKind is the kind of a module_signature event
+type ModuleSignatureKind uint32
+
+const (
+	// ModuleSignatureLoad is the load of a module without a valid signature
+	ModuleSignatureLoad ModuleSignatureKind = iota + 1
+	// ModuleSignatureFlip is a runtime change of module.sig_enforce
+	ModuleSignatureFlip
+	// ModuleSignatureStartup is the check of module.sig_enforce run when KRIE starts, it is generated in user space
+	ModuleSignatureStartup
+)
+
+func (k ModuleSignatureKind) String() string {
+	switch k {
+	case ModuleSignatureLoad:
+		return "load"
+	case ModuleSignatureFlip:
+		return "flip"
+	case ModuleSignatureStartup:
+		return "startup"
+	default:
+		return fmt.Sprintf("ModuleSignatureKind(%d)", k)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the kind
+func (k ModuleSignatureKind) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", k)), nil
+}
+
+// SigEnforceState is the value of module.sig_enforce
+type SigEnforceState uint32
+
+const (
+	// SigEnforceDisabled means that the modules without a valid signature are loaded, and taint the kernel
+	SigEnforceDisabled SigEnforceState = iota
+	// SigEnforceEnabled means that the modules without a valid signature are rejected
+	SigEnforceEnabled
+	// SigEnforceUnknown means that module.sig_enforce couldn't be read (no CONFIG_MODULE_SIG, or the symbol isn't in
+	// kallsyms)
+	SigEnforceUnknown
+)
+
+func (s SigEnforceState) String() string {
+	switch s {
+	case SigEnforceDisabled:
+		return "disabled"
+	case SigEnforceEnabled:
+		return "enabled"
+	case SigEnforceUnknown:
+		return "unknown"
+	default:
+		return fmt.Sprintf("SigEnforceState(%d)", s)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the state
+func (s SigEnforceState) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", s)), nil
+}
+
+// ModuleSignatureOptions is used to configure the module_signature events
+type ModuleSignatureOptions struct {
+	Action Action `yaml:"action"`
+	// ExpectSigEnforce declares that module.sig_enforce is expected to be enabled on the host: the loads of modules
+	// without a valid signature are reported as bypasses, and KRIE reports a disabled enforcement when it starts
+	ExpectSigEnforce bool `yaml:"expect_sig_enforce"`
+}
+
+// NewModuleSignatureOptions returns a new instance of ModuleSignatureOptions
+func NewModuleSignatureOptions() *ModuleSignatureOptions {
+	return &ModuleSignatureOptions{}
+}
+
+func moduleSignatureProbes() []manager.ProbeIdentificationPair {
+	return []manager.ProbeIdentificationPair{
+		{UID: KRIEUID, EBPFSection: "kprobe/do_init_module", EBPFFuncName: "kprobe_module_signature_do_init_module"},
+	}
+}
+
+func addModuleSignatureProbes(all *[]*manager.Probe) {
+	for _, id := range moduleSignatureProbes() {
+		*all = append(*all, &manager.Probe{ProbeIdentificationPair: id})
+	}
+}
+
+func addModuleSignatureSelectors(all *[]manager.ProbesSelector) {
+	var selectors []manager.ProbesSelector
+	for _, id := range moduleSignatureProbes() {
+		selectors = append(selectors, &manager.ProbeSelector{ProbeIdentificationPair: id})
+	}
+	*all = append(*all, &manager.AllOf{Selectors: selectors})
+}
+
+// ModuleSignatureEvent represents the load of a module without a valid signature (unsigned, wrongly signed or signed by
+// an unknown key), or a change of module.sig_enforce. The runtime changes are detected by the checks of the kernel
+// parameters: the parameter can only be enabled through sysfs, disabling it requires a write to kernel memory.
+type ModuleSignatureEvent struct {
+	Kind ModuleSignatureKind `json:"kind"`
+	// Module is the name of the loaded module
+	Module string `json:"module,omitempty"`
+	// SigEnforce is the value of module.sig_enforce when the event was sent
+	SigEnforce SigEnforceState `json:"sig_enforce"`
+	// PreviousSigEnforce is the value of module.sig_enforce before a flip
+	PreviousSigEnforce *SigEnforceState `json:"previous_sig_enforce,omitempty"`
+	// Expected is set when the policies expect module.sig_enforce to be enabled
+	Expected bool `json:"expected,omitempty"`
+	// Bypass is set when the signature enforcement was bypassed: a module without a valid signature was loaded while
+	// the enforcement was enabled or expected, or the enforcement was disabled
+	Bypass bool `json:"bypass,omitempty"`
+}
+
+// ResolveBypass sets Expected and Bypass, depending on the expectation of the policies
+func (e *ModuleSignatureEvent) ResolveBypass(expected bool) {
+	e.Expected = expected
+	switch e.Kind {
+	case ModuleSignatureLoad:
+		// the kernel rejects the modules without a valid signature when the enforcement is enabled
+		e.Bypass = e.SigEnforce == SigEnforceEnabled || expected
+	case ModuleSignatureFlip:
+		e.Bypass = e.SigEnforce == SigEnforceDisabled
+	case ModuleSignatureStartup:
+		e.Bypass = expected && e.SigEnforce == SigEnforceDisabled
+	}
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *ModuleSignatureEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < moduleSignatureEventLen {
+		return 0, fmt.Errorf("while parsing ModuleSignatureEvent, got len %d, needed %d: %w", len(data), moduleSignatureEventLen, ErrNotEnoughData)
+	}
+	e.Kind = ModuleSignatureKind(ByteOrder.Uint32(data[0:4]))
+	e.SigEnforce = SigEnforceState(ByteOrder.Uint32(data[4:8]))
+	if e.Kind == ModuleSignatureFlip {
+		previous := SigEnforceState(ByteOrder.Uint32(data[8:12]))
+		e.PreviousSigEnforce = &previous
+	}
+	var err error
+	e.Module, err = UnmarshalString(data[16:moduleSignatureEventLen], ModuleNameLen)
+	if err != nil {
+		return 0, err
+	}
+	return moduleSignatureEventLen, nil
+}
+
+// ModuleSignatureEventSerializer is used to serialize ModuleSignatureEvent
+// easyjson:json
+type ModuleSignatureEventSerializer struct {
+	*ModuleSignatureEvent
+}
+
+// NewModuleSignatureEventSerializer returns a new instance of ModuleSignatureEventSerializer
+func NewModuleSignatureEventSerializer(e *ModuleSignatureEvent) *ModuleSignatureEventSerializer {
+	return &ModuleSignatureEventSerializer{
+		ModuleSignatureEvent: e,
+	}
+}
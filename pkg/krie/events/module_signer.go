@@ -0,0 +1,106 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+const (
+	SignerPolicyAllowed = "allowed"
+	SignerPolicyDenied  = "denied"
+
+	// UnauthorizedSignerRule is the rule of the init_module events of the modules which aren't signed by an allowed key
+	UnauthorizedSignerRule = "unauthorized_signer"
+)
+
+// ModuleSignerOptions configures the module signer policy: only the modules signed by one of the allowed certificates
+// can be loaded, regardless of the signature enforcement of the kernel
+type ModuleSignerOptions struct {
+	// Action is the action taken for the modules which aren't signed by an allowed certificate: "nop" disables the
+	// policy, "log" alerts, "block" unloads the module
+	Action Action `yaml:"action"`
+	// Certificates are the PEM or DER files of the allowed signing certificates
+	Certificates []string `yaml:"certificates"`
+
+	certificates []*x509.Certificate `yaml:"-"`
+}
+
+// NewModuleSignerOptions returns a default set of module signer options
+func NewModuleSignerOptions() *ModuleSignerOptions {
+	return &ModuleSignerOptions{}
+}
+
+// IsValid returns an error if the module signer options are invalid, and loads the allowed certificates
+func (o *ModuleSignerOptions) IsValid() error {
+	if o.Action == NopAction {
+		return nil
+	}
+	if o.Action > BlockAction {
+		return fmt.Errorf("module_signers action cannot be set to \"%s\"", o.Action)
+	}
+	if len(o.Certificates) == 0 {
+		return fmt.Errorf("no allowed certificate")
+	}
+	o.certificates = o.certificates[:0]
+	for _, path := range o.Certificates {
+		certs, err := loadCertificates(path)
+		if err != nil {
+			return err
+		}
+		o.certificates = append(o.certificates, certs...)
+	}
+	return nil
+}
+
+// AllowedCertificates returns the allowed signing certificates, loaded by IsValid
+func (o *ModuleSignerOptions) AllowedCertificates() []*x509.Certificate {
+	return o.certificates
+}
+
+// loadCertificates loads the certificates of a PEM or DER file
+func loadCertificates(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read certificate: %w", err)
+	}
+
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse certificate %s: %w", path, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) > 0 {
+		return certs, nil
+	}
+
+	// the signing keys of the kernel build (signing_key.x509) are DER encoded
+	cert, err := x509.ParseCertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse certificate %s: %w", path, err)
+	}
+	return []*x509.Certificate{cert}, nil
+}
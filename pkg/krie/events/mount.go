@@ -0,0 +1,146 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	// MountPathLen is the maximum length of the source and the target of a mount
+	MountPathLen = 128
+	// mountEventLen is the length of the binary representation of a mount event
+	mountEventLen = 16 + 2*MountPathLen
+)
+
+// MountFS is a sensitive file system
+type MountFS uint32
+
+const (
+	// MountDebugFS is debugfs, it exposes the internals of the kernel
+	MountDebugFS MountFS = iota + 1
+	// MountTraceFS is tracefs, it controls ftrace and the kprobe events
+	MountTraceFS
+	// MountSecurityFS is securityfs, it exposes the LSM policies
+	MountSecurityFS
+	// MountBPFFS is bpffs, it holds the pinned bpf objects
+	MountBPFFS
+	// MountProcFS is procfs
+	MountProcFS
+)
+
+func (fs MountFS) String() string {
+	switch fs {
+	case MountDebugFS:
+		return "debugfs"
+	case MountTraceFS:
+		return "tracefs"
+	case MountSecurityFS:
+		return "securityfs"
+	case MountBPFFS:
+		return "bpf"
+	case MountProcFS:
+		return "proc"
+	default:
+		return fmt.Sprintf("MountFS(%d)", fs)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the file system
+func (fs MountFS) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", fs)), nil
+}
+
+func addMountProbes(all *[]*manager.Probe) {
+	*all = append(*all, ExpandSyscallProbes(&manager.Probe{
+		ProbeIdentificationPair: manager.ProbeIdentificationPair{
+			UID: KRIEUID,
+		},
+		SyscallFuncName: "mount",
+	}, EntryAndExit)...)
+}
+
+func addMountRoutes(all *[]manager.TailCallRoute) {
+	*all = append(*all, []manager.TailCallRoute{
+		{
+			ProgArrayName: "sys_exit_progs",
+			Key:           uint32(MountEventType),
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				EBPFSection:  "tracepoint/handle_sys_mount_exit",
+				EBPFFuncName: "tracepoint_handle_sys_mount_exit",
+			},
+		},
+	}...)
+}
+
+func addMountSelectors(all *[]manager.ProbesSelector) {
+	*all = append(*all,
+		&manager.OneOf{Selectors: ExpandSyscallProbesSelector(
+			manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: "mount"}, EntryAndExit),
+		},
+	)
+}
+
+// MountEvent represents a mount of a sensitive file system (debugfs, tracefs, securityfs, bpffs and procfs). Mounting
+// tracefs or debugfs inside a container is often the first step of a kernel attack.
+type MountEvent struct {
+	FSType MountFS `json:"fstype"`
+	Source string  `json:"source,omitempty"`
+	Target string  `json:"target"`
+	Flags  uint64  `json:"flags,omitempty"`
+	// NewNamespace is set when the mount namespace of the process isn't the mount namespace of the host, it is
+	// resolved in user space
+	NewNamespace bool `json:"new_namespace"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *MountEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < mountEventLen {
+		return 0, fmt.Errorf("while parsing MountEvent, got len %d, needed %d: %w", len(data), mountEventLen, ErrNotEnoughData)
+	}
+	e.Flags = ByteOrder.Uint64(data[0:8])
+	e.FSType = MountFS(ByteOrder.Uint32(data[8:12]))
+	// padding
+
+	var err error
+	e.Source, err = UnmarshalString(data[16:16+MountPathLen], MountPathLen)
+	if err != nil {
+		return 0, err
+	}
+	e.Target, err = UnmarshalString(data[16+MountPathLen:16+2*MountPathLen], MountPathLen)
+	if err != nil {
+		return 0, err
+	}
+	return mountEventLen, nil
+}
+
+// MountEventSerializer is used to serialize MountEvent
+// easyjson:json
+type MountEventSerializer struct {
+	*MountEvent
+}
+
+// NewMountEventSerializer returns a new instance of MountEventSerializer
+func NewMountEventSerializer(e *MountEvent) *MountEventSerializer {
+	return &MountEventSerializer{
+		MountEvent: e,
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	// msrWriteEventLen is the length of the binary representation of an msr_write event
+	msrWriteEventLen = 24
+)
+
+// MSRWriteSource is the kernel path that reported an msr_write event
+type MSRWriteSource uint32
+
+const (
+	// MSRWriteTracepoint is a write done by the kernel, reported by the msr/write_msr tracepoint
+	MSRWriteTracepoint MSRWriteSource = iota + 1
+	// MSRWriteDevice is a write to /dev/cpu/*/msr
+	MSRWriteDevice
+)
+
+func (s MSRWriteSource) String() string {
+	switch s {
+	case MSRWriteTracepoint:
+		return "wrmsr"
+	case MSRWriteDevice:
+		return "msr_device"
+	default:
+		return fmt.Sprintf("MSRWriteSource(%d)", s)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the source
+func (s MSRWriteSource) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", s)), nil
+}
+
+const (
+	msrSysenterCS  = 0x174
+	msrSysenterESP = 0x175
+	msrSysenterEIP = 0x176
+	msrEFER        = 0xc0000080
+	msrSTAR        = 0xc0000081
+	msrLSTAR       = 0xc0000082
+	msrCSTAR       = 0xc0000083
+	msrSyscallMask = 0xc0000084
+)
+
+// msrNames are the names of the MSRs that control the syscall entry points
+var msrNames = map[uint32]string{
+	msrSysenterCS:  "IA32_SYSENTER_CS",
+	msrSysenterESP: "IA32_SYSENTER_ESP",
+	msrSysenterEIP: "IA32_SYSENTER_EIP",
+	msrEFER:        "EFER",
+	msrSTAR:        "STAR",
+	msrLSTAR:       "LSTAR",
+	msrCSTAR:       "CSTAR",
+	msrSyscallMask: "SYSCALL_MASK",
+}
+
+// msrSyscallTargets are the MSRs that hold the address of a syscall entry point
+var msrSyscallTargets = map[uint32]bool{
+	msrSysenterEIP: true,
+	msrLSTAR:       true,
+	msrCSTAR:       true,
+}
+
+func msrWriteProbes() []manager.ProbeIdentificationPair {
+	return []manager.ProbeIdentificationPair{
+		{UID: KRIEUID, EBPFSection: "tracepoint/msr/write_msr", EBPFFuncName: "tracepoint_msr_write_msr"},
+		{UID: KRIEUID, EBPFSection: "kprobe/msr_write", EBPFFuncName: "kprobe_msr_write"},
+	}
+}
+
+func addMSRWriteProbes(all *[]*manager.Probe) {
+	for _, id := range msrWriteProbes() {
+		*all = append(*all, &manager.Probe{ProbeIdentificationPair: id})
+	}
+}
+
+func addMSRWriteSelectors(all *[]manager.ProbesSelector) {
+	// the msr tracepoints only exist on x86, and the msr driver can be compiled out (CONFIG_X86_MSR)
+	var selectors []manager.ProbesSelector
+	for _, id := range msrWriteProbes() {
+		selectors = append(selectors, &manager.ProbeSelector{ProbeIdentificationPair: id})
+	}
+	*all = append(*all, &manager.BestEffort{Selectors: selectors})
+}
+
+// MSRWriteEvent represents a write to a model specific register. Rootkits overwrite LSTAR (or the other syscall entry
+// MSRs) to redirect every syscall to their own handler without touching the syscall table.
+type MSRWriteEvent struct {
+	MSR    uint32         `json:"msr"`
+	Name   string         `json:"name,omitempty"`
+	Value  MemoryPointer  `json:"value"`
+	Source MSRWriteSource `json:"source"`
+	// CPU is the CPU of the MSR, the CPU that ran wrmsr or the CPU of the /dev/cpu/*/msr file
+	CPU uint32 `json:"cpu"`
+	// SyscallEntry is set when the MSR holds the address of a syscall entry point
+	SyscallEntry bool `json:"syscall_entry,omitempty"`
+	// Target is the new syscall entry point, only set when SyscallEntry is set
+	Target *KernelSymbol `json:"target,omitempty"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *MSRWriteEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < msrWriteEventLen {
+		return 0, fmt.Errorf("while parsing MSRWriteEvent, got len %d, needed %d: %w", len(data), msrWriteEventLen, ErrNotEnoughData)
+	}
+	e.Value = MemoryPointer(ByteOrder.Uint64(data[0:8]))
+	e.MSR = ByteOrder.Uint32(data[8:12])
+	e.Source = MSRWriteSource(ByteOrder.Uint32(data[12:16]))
+	e.CPU = ByteOrder.Uint32(data[16:20])
+	e.Name = msrNames[e.MSR]
+	e.SyscallEntry = msrSyscallTargets[e.MSR]
+	e.Target = nil
+	if e.SyscallEntry {
+		e.Target = &KernelSymbol{Address: e.Value}
+	}
+	return msrWriteEventLen, nil
+}
+
+// MSRWriteEventSerializer is used to serialize MSRWriteEvent
+// easyjson:json
+type MSRWriteEventSerializer struct {
+	*MSRWriteEvent
+}
+
+// NewMSRWriteEventSerializer returns a new instance of MSRWriteEventSerializer
+func NewMSRWriteEventSerializer(e *MSRWriteEvent) *MSRWriteEventSerializer {
+	return &MSRWriteEventSerializer{
+		MSRWriteEvent: e,
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+// namespaceEventLen is the length of the binary representation of a namespace event
+const namespaceEventLen = 48
+
+// NamespaceSyscall is the syscall of a namespace event
+type NamespaceSyscall uint32
+
+const (
+	// NamespaceSetns is a setns call, the process enters the namespaces of a file descriptor
+	NamespaceSetns NamespaceSyscall = iota + 1
+	// NamespaceUnshare is an unshare call, the process creates new namespaces
+	NamespaceUnshare
+)
+
+func (s NamespaceSyscall) String() string {
+	switch s {
+	case NamespaceSetns:
+		return "setns"
+	case NamespaceUnshare:
+		return "unshare"
+	default:
+		return fmt.Sprintf("NamespaceSyscall(%d)", s)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the syscall
+func (s NamespaceSyscall) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", s)), nil
+}
+
+// NamespaceTypes are the namespace types, named after their link in /proc/[pid]/ns
+var NamespaceTypes = []string{"cgroup", "ipc", "net", "mnt", "pid", "time", "user", "uts"}
+
+// namespaceCloneFlags are the CLONE_NEW* flags of the namespace types
+var namespaceCloneFlags = map[string]uint64{
+	"cgroup": 0x02000000,
+	"ipc":    0x08000000,
+	"net":    0x40000000,
+	"mnt":    0x00020000,
+	"pid":    0x20000000,
+	"time":   0x00000080,
+	"user":   0x10000000,
+	"uts":    0x04000000,
+}
+
+// namespaceCloneFlagNames returns the namespace types of CLONE_NEW* flags
+func namespaceCloneFlagNames(flags uint64) []string {
+	var names []string
+	for _, nsType := range NamespaceTypes {
+		if flags&namespaceCloneFlags[nsType] != 0 {
+			names = append(names, nsType)
+		}
+	}
+	return names
+}
+
+// Inode returns the inode of a namespace type, the pid namespace is the namespace of the children of the process
+func (nc *NamespaceContext) Inode(nsType string) uint32 {
+	switch nsType {
+	case "cgroup":
+		return nc.CgroupNamespace
+	case "ipc":
+		return nc.IPCNamespace
+	case "net":
+		return nc.NetNamespace
+	case "mnt":
+		return nc.MntNamespace
+	case "pid":
+		return nc.PIDNamespace
+	case "time":
+		return nc.TimeNamespace
+	case "user":
+		return nc.UserNamespace
+	case "uts":
+		return nc.UTSNamespace
+	default:
+		return 0
+	}
+}
+
+func addNamespaceProbes(all *[]*manager.Probe) {
+	for _, syscall := range []string{"setns", "unshare"} {
+		*all = append(*all, ExpandSyscallProbes(&manager.Probe{
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				UID: KRIEUID,
+			},
+			SyscallFuncName: syscall,
+		}, EntryAndExit)...)
+	}
+}
+
+func addNamespaceRoutes(all *[]manager.TailCallRoute) {
+	*all = append(*all, []manager.TailCallRoute{
+		{
+			ProgArrayName: "sys_exit_progs",
+			Key:           uint32(NamespaceEventType),
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				EBPFSection:  "tracepoint/handle_sys_namespace_exit",
+				EBPFFuncName: "tracepoint_handle_sys_namespace_exit",
+			},
+		},
+	}...)
+}
+
+func addNamespaceSelectors(all *[]manager.ProbesSelector) {
+	for _, syscall := range []string{"setns", "unshare"} {
+		*all = append(*all,
+			&manager.OneOf{Selectors: ExpandSyscallProbesSelector(
+				manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: syscall}, EntryAndExit),
+			},
+		)
+	}
+}
+
+// NamespaceEvent represents a setns or an unshare call creating a namespace. The namespaces of the process context
+// are the namespaces after the call. A process of a container entering the namespaces of the host (nsenter -t 1) has
+// escaped its container.
+type NamespaceEvent struct {
+	Syscall NamespaceSyscall `json:"syscall"`
+	// Namespaces are the namespace types requested by the call: the nstype of setns (empty when setns accepts any
+	// type) or the CLONE_NEW* flags of unshare
+	Namespaces []string `json:"namespaces,omitempty"`
+	Flags      uint64   `json:"flags"`
+	// FD is the namespace file descriptor of setns
+	FD int32 `json:"fd,omitempty"`
+	// Previous are the namespaces of the process before the call
+	Previous NamespaceContext `json:"previous"`
+	// Changed are the namespace types the process entered or created
+	Changed []string `json:"changed,omitempty"`
+	// EnteredHost are the namespaces of the host the process entered, resolved in user space
+	EnteredHost []string `json:"entered_host,omitempty"`
+	// HostEscape is set when a process of a container pid namespace entered namespaces of the host, resolved in user
+	// space
+	HostEscape bool `json:"host_escape"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *NamespaceEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < namespaceEventLen {
+		return 0, fmt.Errorf("while parsing NamespaceEvent, got len %d, needed %d: %w", len(data), namespaceEventLen, ErrNotEnoughData)
+	}
+	if _, err := e.Previous.UnmarshalBinary(data[0:32]); err != nil {
+		return 0, err
+	}
+	e.Flags = ByteOrder.Uint64(data[32:40])
+	e.Syscall = NamespaceSyscall(ByteOrder.Uint32(data[40:44]))
+	e.FD = int32(ByteOrder.Uint32(data[44:48]))
+	e.Namespaces = namespaceCloneFlagNames(e.Flags)
+	e.Changed = nil
+	e.EnteredHost = nil
+	e.HostEscape = false
+	return namespaceEventLen, nil
+}
+
+// resolveChanges lists the namespace types changed by the call
+func (e *NamespaceEvent) resolveChanges(current *NamespaceContext) {
+	for _, nsType := range NamespaceTypes {
+		if e.Previous.Inode(nsType) != current.Inode(nsType) {
+			e.Changed = append(e.Changed, nsType)
+		}
+	}
+}
+
+// NamespaceEventSerializer is used to serialize NamespaceEvent
+// easyjson:json
+type NamespaceEventSerializer struct {
+	*NamespaceEvent
+}
+
+// NewNamespaceEventSerializer returns a new instance of NamespaceEventSerializer
+func NewNamespaceEventSerializer(e *NamespaceEvent) *NamespaceEventSerializer {
+	return &NamespaceEventSerializer{
+		NamespaceEvent: e,
+	}
+}
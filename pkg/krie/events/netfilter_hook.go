@@ -0,0 +1,176 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+// netfilterHookEventLen is the length of the binary representation of a netfilter_hook event
+const netfilterHookEventLen = 32
+
+// NetfilterFamily is the protocol family of a netfilter hook (NFPROTO_*)
+type NetfilterFamily uint32
+
+const (
+	// NetfilterInet is NFPROTO_INET, the hooks of both IPv4 and IPv6
+	NetfilterInet NetfilterFamily = 1
+	// NetfilterIPv4 is NFPROTO_IPV4
+	NetfilterIPv4 NetfilterFamily = 2
+	// NetfilterARP is NFPROTO_ARP
+	NetfilterARP NetfilterFamily = 3
+	// NetfilterNetdev is NFPROTO_NETDEV, the hooks of a network device
+	NetfilterNetdev NetfilterFamily = 5
+	// NetfilterBridge is NFPROTO_BRIDGE
+	NetfilterBridge NetfilterFamily = 7
+	// NetfilterIPv6 is NFPROTO_IPV6
+	NetfilterIPv6 NetfilterFamily = 10
+)
+
+func (f NetfilterFamily) String() string {
+	switch f {
+	case NetfilterInet:
+		return "inet"
+	case NetfilterIPv4:
+		return "ipv4"
+	case NetfilterARP:
+		return "arp"
+	case NetfilterNetdev:
+		return "netdev"
+	case NetfilterBridge:
+		return "bridge"
+	case NetfilterIPv6:
+		return "ipv6"
+	default:
+		return fmt.Sprintf("NetfilterFamily(%d)", f)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the family
+func (f NetfilterFamily) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", f)), nil
+}
+
+// netfilterInetHooks are the hooks of the inet, ipv4, ipv6 and bridge families (NF_INET_*)
+var netfilterInetHooks = []string{"pre_routing", "local_in", "forward", "local_out", "post_routing", "ingress"}
+
+// netfilterARPHooks are the hooks of the arp family (NF_ARP_*)
+var netfilterARPHooks = []string{"in", "out", "forward"}
+
+// netfilterNetdevHooks are the hooks of the netdev family (NF_NETDEV_*)
+var netfilterNetdevHooks = []string{"ingress", "egress"}
+
+// netfilterHookName returns the name of a hook number of a family
+func netfilterHookName(family NetfilterFamily, hooknum uint32) string {
+	var names []string
+	switch family {
+	case NetfilterARP:
+		names = netfilterARPHooks
+	case NetfilterNetdev:
+		names = netfilterNetdevHooks
+	default:
+		names = netfilterInetHooks
+	}
+	if int(hooknum) < len(names) {
+		return names[hooknum]
+	}
+	return fmt.Sprintf("hook_%d", hooknum)
+}
+
+// netfilterModulePrefixes are the prefixes of the modules of the netfilter subsystem (conntrack, nat, iptables,
+// nftables, bridge netfilter, ...)
+var netfilterModulePrefixes = []string{"nf_", "nft_", "xt_", "ip_tables", "ip6_tables", "iptable_", "ip6table_", "arp_tables", "arptable_", "ebtable", "br_netfilter", "nf_tables"}
+
+// isNetfilterModule returns true if the module belongs to the netfilter subsystem
+func isNetfilterModule(module string) bool {
+	for _, prefix := range netfilterModulePrefixes {
+		if strings.HasPrefix(module, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func netfilterHookProbes() []manager.ProbeIdentificationPair {
+	return []manager.ProbeIdentificationPair{
+		{UID: KRIEUID, EBPFSection: "kprobe/nf_register_net_hook", EBPFFuncName: "kprobe_nf_register_net_hook"},
+	}
+}
+
+func addNetfilterHookProbes(all *[]*manager.Probe) {
+	for _, id := range netfilterHookProbes() {
+		*all = append(*all, &manager.Probe{ProbeIdentificationPair: id})
+	}
+}
+
+func addNetfilterHookSelectors(all *[]manager.ProbesSelector) {
+	var selectors []manager.ProbesSelector
+	for _, id := range netfilterHookProbes() {
+		selectors = append(selectors, &manager.ProbeSelector{ProbeIdentificationPair: id})
+	}
+	*all = append(*all, &manager.AllOf{Selectors: selectors})
+}
+
+// NetfilterHookEvent represents the registration of a netfilter hook. A module hooking the network stack can hide its
+// traffic, or wait for a magic packet to trigger a backdoor.
+type NetfilterHookEvent struct {
+	// Ops is the address of the nf_hook_ops structure
+	Ops MemoryPointer `json:"ops"`
+	// Hook is the function called by netfilter, resolved against kallsyms
+	Hook KernelSymbol `json:"hook"`
+	// NetNamespace is the network namespace the hook is registered in
+	NetNamespace uint32          `json:"net_namespace"`
+	Family       NetfilterFamily `json:"family"`
+	// HookNum is the hook point of the family (pre_routing, local_in, ...)
+	HookNum  uint32 `json:"hooknum"`
+	HookName string `json:"hook_name"`
+	// Priority is the priority of the hook, the hooks with the lowest priority run first
+	Priority int32 `json:"priority"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *NetfilterHookEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < netfilterHookEventLen {
+		return 0, fmt.Errorf("while parsing NetfilterHookEvent, got len %d, needed %d: %w", len(data), netfilterHookEventLen, ErrNotEnoughData)
+	}
+	e.Ops = MemoryPointer(ByteOrder.Uint64(data[0:8]))
+	e.Hook = KernelSymbol{Address: MemoryPointer(ByteOrder.Uint64(data[8:16]))}
+	e.NetNamespace = ByteOrder.Uint32(data[16:20])
+	e.Family = NetfilterFamily(ByteOrder.Uint32(data[20:24]))
+	e.HookNum = ByteOrder.Uint32(data[24:28])
+	e.HookName = netfilterHookName(e.Family, e.HookNum)
+	e.Priority = int32(ByteOrder.Uint32(data[28:32]))
+	return netfilterHookEventLen, nil
+}
+
+// NetfilterHookEventSerializer is used to serialize NetfilterHookEvent
+// easyjson:json
+type NetfilterHookEventSerializer struct {
+	*NetfilterHookEvent
+}
+
+// NewNetfilterHookEventSerializer returns a new instance of NetfilterHookEventSerializer
+func NewNetfilterHookEventSerializer(e *NetfilterHookEvent) *NetfilterHookEventSerializer {
+	return &NetfilterHookEventSerializer{
+		NetfilterHookEvent: e,
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	// perfEventProbeLen is the maximum length of the captured function or binary of a kprobe or uprobe perf event
+	perfEventProbeLen = 128
+	// perfEventOpenEventLen is the length of the binary representation of a perf_event_open event
+	perfEventOpenEventLen = 64 + perfEventProbeLen
+	// perfTypeMax is the first dynamic PMU type, the types above are resolved in user space
+	perfTypeMax = 6
+	// perfEventAttrFlagsMask is the mask of the single bit flags of perf_event_attr reported by the event
+	perfEventAttrFlagsMask = 1<<15 - 1
+	// perfEventExcludeKernel is the exclude_kernel flag of perf_event_attr
+	perfEventExcludeKernel = 1 << 5
+	// perfEventSampleKernelAddresses are the sample types which leak addresses when the kernel is sampled: IP, ADDR,
+	// CALLCHAIN, BRANCH_STACK, REGS_INTR and PHYS_ADDR
+	perfEventSampleKernelAddresses = 1<<0 | 1<<3 | 1<<5 | 1<<11 | 1<<18 | 1<<19
+)
+
+func addPerfEventOpenProbes(all *[]*manager.Probe) {
+	*all = append(*all, ExpandSyscallProbes(&manager.Probe{
+		ProbeIdentificationPair: manager.ProbeIdentificationPair{
+			UID: KRIEUID,
+		},
+		SyscallFuncName: "perf_event_open",
+	}, EntryAndExit)...)
+}
+
+func addPerfEventOpenRoutes(all *[]manager.TailCallRoute) {
+	*all = append(*all, []manager.TailCallRoute{
+		{
+			ProgArrayName: "sys_exit_progs",
+			Key:           uint32(PerfEventOpenEventType),
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				EBPFSection:  "tracepoint/handle_sys_perf_event_open_exit",
+				EBPFFuncName: "tracepoint_handle_sys_perf_event_open_exit",
+			},
+		},
+	}...)
+}
+
+func addPerfEventOpenSelectors(all *[]manager.ProbesSelector) {
+	// perf_event_open can be compiled out (CONFIG_PERF_EVENTS)
+	*all = append(*all,
+		&manager.BestEffort{Selectors: ExpandSyscallProbesSelector(
+			manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: "perf_event_open"}, EntryAndExit),
+		},
+	)
+}
+
+var (
+	// perfTypes are the PERF_TYPE_* types of perf_event_attr, indexed by type
+	perfTypes = []string{
+		"PERF_TYPE_HARDWARE", "PERF_TYPE_SOFTWARE", "PERF_TYPE_TRACEPOINT", "PERF_TYPE_HW_CACHE", "PERF_TYPE_RAW",
+		"PERF_TYPE_BREAKPOINT",
+	}
+	// perfSampleTypes are the PERF_SAMPLE_* bits of perf_event_attr.sample_type
+	perfSampleTypes = []string{
+		"PERF_SAMPLE_IP", "PERF_SAMPLE_TID", "PERF_SAMPLE_TIME", "PERF_SAMPLE_ADDR", "PERF_SAMPLE_READ",
+		"PERF_SAMPLE_CALLCHAIN", "PERF_SAMPLE_ID", "PERF_SAMPLE_CPU", "PERF_SAMPLE_PERIOD", "PERF_SAMPLE_STREAM_ID",
+		"PERF_SAMPLE_RAW", "PERF_SAMPLE_BRANCH_STACK", "PERF_SAMPLE_REGS_USER", "PERF_SAMPLE_STACK_USER",
+		"PERF_SAMPLE_WEIGHT", "PERF_SAMPLE_DATA_SRC", "PERF_SAMPLE_IDENTIFIER", "PERF_SAMPLE_TRANSACTION",
+		"PERF_SAMPLE_REGS_INTR", "PERF_SAMPLE_PHYS_ADDR", "PERF_SAMPLE_AUX", "PERF_SAMPLE_CGROUP",
+		"PERF_SAMPLE_DATA_PAGE_SIZE", "PERF_SAMPLE_CODE_PAGE_SIZE", "PERF_SAMPLE_WEIGHT_STRUCT",
+	}
+	// perfEventAttrFlags are the single bit flags at the head of the perf_event_attr bit field
+	perfEventAttrFlags = []string{
+		"disabled", "inherit", "pinned", "exclusive", "exclude_user", "exclude_kernel", "exclude_hv", "exclude_idle",
+		"mmap", "comm", "freq", "inherit_stat", "enable_on_exec", "task", "watermark",
+	}
+	// perfEventOpenFlags are the PERF_FLAG_* flags of perf_event_open
+	perfEventOpenFlags = []string{
+		"PERF_FLAG_FD_NO_GROUP", "PERF_FLAG_FD_OUTPUT", "PERF_FLAG_PID_CGROUP", "PERF_FLAG_FD_CLOEXEC",
+	}
+)
+
+// PerfEventOpenEvent represents a perf_event_open event
+type PerfEventOpenEvent struct {
+	// Type is the PERF_TYPE_* type of the event, or the name of its dynamic PMU (kprobe, uprobe, ...)
+	Type   string `json:"type"`
+	TypeID uint32 `json:"type_id"`
+	Config uint64 `json:"config"`
+	// Config1 is the probed function or binary of a kprobe or uprobe, or the address of a breakpoint
+	Config1 MemoryPointer `json:"config1,omitempty"`
+	// Config2 is the probed address or offset of a kprobe or uprobe, or the length of a breakpoint
+	Config2    MemoryPointer `json:"config2,omitempty"`
+	SampleType []string      `json:"sample_type,omitempty"`
+	AttrFlags  []string      `json:"attr_flags,omitempty"`
+	Flags      []string      `json:"flags,omitempty"`
+	PID        int32         `json:"pid"`
+	CPU        int32         `json:"cpu"`
+	GroupFD    int32         `json:"group_fd"`
+	// Probe is the function or the binary of a kprobe or uprobe
+	Probe string `json:"probe,omitempty"`
+	// KernelAddresses is set when the kernel is sampled with a sample type which reports kernel addresses
+	KernelAddresses bool `json:"kernel_addresses,omitempty"`
+}
+
+// IsDynamicPMU returns true if the type of the event is a dynamic PMU
+func (e *PerfEventOpenEvent) IsDynamicPMU() bool {
+	return e.TypeID >= perfTypeMax
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *PerfEventOpenEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < perfEventOpenEventLen {
+		return 0, fmt.Errorf("while parsing PerfEventOpenEvent, got len %d, needed %d: %w", len(data), perfEventOpenEventLen, ErrNotEnoughData)
+	}
+	e.Config = ByteOrder.Uint64(data[0:8])
+	e.Config1 = MemoryPointer(ByteOrder.Uint64(data[8:16]))
+	e.Config2 = MemoryPointer(ByteOrder.Uint64(data[16:24]))
+	sampleType := ByteOrder.Uint64(data[24:32])
+	attrFlags := ByteOrder.Uint64(data[32:40])
+	e.Flags = bitNames(ByteOrder.Uint64(data[40:48]), perfEventOpenFlags)
+	e.TypeID = ByteOrder.Uint32(data[48:52])
+	e.PID = int32(ByteOrder.Uint32(data[52:56]))
+	e.CPU = int32(ByteOrder.Uint32(data[56:60]))
+	e.GroupFD = int32(ByteOrder.Uint32(data[60:64]))
+
+	if e.IsDynamicPMU() {
+		e.Type = fmt.Sprintf("PMU(%d)", e.TypeID)
+		var err error
+		e.Probe, err = UnmarshalString(data[64:perfEventOpenEventLen], perfEventProbeLen)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		e.Type = perfTypes[e.TypeID]
+	}
+	e.SampleType = bitNames(sampleType, perfSampleTypes)
+	e.AttrFlags = bitNames(attrFlags&perfEventAttrFlagsMask, perfEventAttrFlags)
+	e.KernelAddresses = attrFlags&perfEventExcludeKernel == 0 && sampleType&perfEventSampleKernelAddresses != 0
+	return perfEventOpenEventLen, nil
+}
+
+// PerfEventOpenEventSerializer is used to serialize PerfEventOpenEvent
+// easyjson:json
+type PerfEventOpenEventSerializer struct {
+	*PerfEventOpenEvent
+}
+
+// NewPerfEventOpenEventSerializer returns a new instance of PerfEventOpenEventSerializer
+func NewPerfEventOpenEventSerializer(e *PerfEventOpenEvent) *PerfEventOpenEventSerializer {
+	return &PerfEventOpenEventSerializer{
+		PerfEventOpenEvent: e,
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyContext is used to serialize the policy that produced an event
+type PolicyContext struct {
+	Version string `json:"version,omitempty"`
+	Hash    string `json:"hash"`
+	RuleID  string `json:"rule_id"`
+	// Mode is the mode of the rule, it is only set for the rules configured to block, kill or alter the monitored
+	// operations
+	Mode RuleMode `json:"mode,omitempty"`
+	// Confidence is the confidence of the detection, it is only set for the events that report a detection
+	Confidence Confidence `json:"confidence,omitempty"`
+}
+
+// Hash returns a hash of the policies, so that an event can be correlated with the policies that produced it
+func (o *Options) Hash() (string, error) {
+	data, err := yaml.Marshal(o)
+	if err != nil {
+		return "", fmt.Errorf("couldn't marshal events options: %w", err)
+	}
+	h := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(h[:]), nil
+}
+
+// RuleID returns the ID of the rule that matched the provided event
+func (o *Options) RuleID(e *Event) string {
+	if e.Failure != nil && e.Failure.Repeated {
+		return fmt.Sprintf("%s/%s", e.Kernel.Type, RepeatedFailureRule)
+	}
+	switch e.Kernel.Type {
+	case SysCtlEventType:
+		if _, ok := o.SysCtlEvent.List[e.SysCtlEvent.Name]; ok {
+			return fmt.Sprintf("%s/%s", e.Kernel.Type, e.SysCtlEvent.Name)
+		}
+		return fmt.Sprintf("%s/default", e.Kernel.Type)
+	case SysCtlDriftEventType:
+		return fmt.Sprintf("%s/%s", e.Kernel.Type, e.SysCtlDriftEvent.Name)
+	case KernelParameterEventType, PeriodicKernelParameterEventType:
+		return fmt.Sprintf("%s/%s/%s", e.Kernel.Type, e.KernelParameterEvent.Parameter.Module, e.KernelParameterEvent.Parameter.Symbol)
+	case InitModuleEventType:
+		if e.InitModule.SignerPolicy == SignerPolicyDenied {
+			return fmt.Sprintf("%s/%s", e.Kernel.Type, UnauthorizedSignerRule)
+		}
+		if e.InitModule.ImagePolicy == ImagePolicyDenied {
+			return fmt.Sprintf("%s/%s", e.Kernel.Type, UnallowedImageRule)
+		}
+		if e.InitModule.Fileless {
+			return fmt.Sprintf("%s/%s", e.Kernel.Type, FilelessRule)
+		}
+		return e.Kernel.Type.String()
+	case BPFEventType:
+		if e.BPFEvent.Fileless {
+			return fmt.Sprintf("%s/%s", e.Kernel.Type, FilelessRule)
+		}
+		return e.Kernel.Type.String()
+	default:
+		return e.Kernel.Type.String()
+	}
+}
+
+// EnforcedRules returns the sorted IDs of the rules that block or kill, the rules in monitor mode are ignored
+func (o *Options) EnforcedRules() []string {
+	var out []string
+	for rule := range o.enforceableRules() {
+		if !o.IsMonitored(rule) {
+			out = append(out, rule)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Escalations returns the sorted IDs of the rules that are enforced by o, but not by previous
+func (o *Options) Escalations(previous *Options) []string {
+	enforced := make(map[string]bool)
+	if previous != nil {
+		for _, rule := range previous.EnforcedRules() {
+			enforced[rule] = true
+		}
+	}
+
+	var out []string
+	for _, rule := range o.EnforcedRules() {
+		if !enforced[rule] {
+			out = append(out, rule)
+		}
+	}
+	return out
+}
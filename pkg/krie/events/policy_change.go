@@ -0,0 +1,46 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+// PolicyChangeEvent is the audit event generated when the policies are loaded or modified
+type PolicyChangeEvent struct {
+	Status       string `json:"status"`
+	Version      string `json:"version,omitempty"`
+	Hash         string `json:"hash"`
+	PreviousHash string `json:"previous_hash,omitempty"`
+
+	// Escalations lists the rules switched from detection to enforcement by the change
+	Escalations []string `json:"escalations,omitempty"`
+	// Approvers lists the approvers whose approval tokens were accepted
+	Approvers []string `json:"approvers,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// PolicyChangeEventSerializer is used to serialize PolicyChangeEvent
+// easyjson:json
+type PolicyChangeEventSerializer struct {
+	*PolicyChangeEvent
+}
+
+// NewPolicyChangeEventSerializer returns a new instance of PolicyChangeEventSerializer
+func NewPolicyChangeEventSerializer(e *PolicyChangeEvent) *PolicyChangeEventSerializer {
+	return &PolicyChangeEventSerializer{
+		PolicyChangeEvent: e,
+	}
+}
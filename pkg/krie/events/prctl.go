@@ -0,0 +1,175 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	// prctlEventLen is the length of the binary representation of a prctl event
+	prctlEventLen = 24
+	// prctlPtracerAny is PR_SET_PTRACER_ANY, any process can ptrace the caller
+	prctlPtracerAny = ^uint64(0)
+)
+
+// PrctlOperation is the sandbox change of a prctl event
+type PrctlOperation uint32
+
+const (
+	// PrctlSetSeccomp is a prctl(PR_SET_SECCOMP) call
+	PrctlSetSeccomp PrctlOperation = iota + 1
+	// PrctlSetDumpable is a prctl(PR_SET_DUMPABLE) call
+	PrctlSetDumpable
+	// PrctlSetPtracer is a prctl(PR_SET_PTRACER) call
+	PrctlSetPtracer
+	// PrctlSeccomp is a seccomp(SECCOMP_SET_MODE_STRICT) or seccomp(SECCOMP_SET_MODE_FILTER) call
+	PrctlSeccomp
+)
+
+func (o PrctlOperation) String() string {
+	switch o {
+	case PrctlSetSeccomp:
+		return "PR_SET_SECCOMP"
+	case PrctlSetDumpable:
+		return "PR_SET_DUMPABLE"
+	case PrctlSetPtracer:
+		return "PR_SET_PTRACER"
+	case PrctlSeccomp:
+		return "seccomp"
+	default:
+		return fmt.Sprintf("PrctlOperation(%d)", o)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the operation
+func (o PrctlOperation) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", o)), nil
+}
+
+// SeccompMode is a seccomp mode
+type SeccompMode uint64
+
+const (
+	// SeccompModeStrict only allows read, write, _exit and sigreturn
+	SeccompModeStrict SeccompMode = 1
+	// SeccompModeFilter filters the syscalls with a bpf program
+	SeccompModeFilter SeccompMode = 2
+)
+
+func (m SeccompMode) String() string {
+	switch m {
+	case SeccompModeStrict:
+		return "strict"
+	case SeccompModeFilter:
+		return "filter"
+	default:
+		return fmt.Sprintf("SeccompMode(%d)", m)
+	}
+}
+
+func addPrctlProbes(all *[]*manager.Probe) {
+	for _, syscall := range []string{"prctl", "seccomp"} {
+		*all = append(*all, ExpandSyscallProbes(&manager.Probe{
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				UID: KRIEUID,
+			},
+			SyscallFuncName: syscall,
+		}, EntryAndExit)...)
+	}
+}
+
+func addPrctlRoutes(all *[]manager.TailCallRoute) {
+	*all = append(*all, []manager.TailCallRoute{
+		{
+			ProgArrayName: "sys_exit_progs",
+			Key:           uint32(PrctlEventType),
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				EBPFSection:  "tracepoint/handle_sys_prctl_exit",
+				EBPFFuncName: "tracepoint_handle_sys_prctl_exit",
+			},
+		},
+	}...)
+}
+
+func addPrctlSelectors(all *[]manager.ProbesSelector) {
+	*all = append(*all,
+		&manager.OneOf{Selectors: ExpandSyscallProbesSelector(
+			manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: "prctl"}, EntryAndExit),
+		},
+		// seccomp() was added in 3.17, and can be compiled out (CONFIG_SECCOMP)
+		&manager.BestEffort{Selectors: ExpandSyscallProbesSelector(
+			manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: "seccomp"}, EntryAndExit),
+		},
+	)
+}
+
+// PrctlEvent represents a change of the sandbox of a process: a seccomp mode is set with prctl(PR_SET_SECCOMP) or
+// seccomp(), the process is made dumpable with prctl(PR_SET_DUMPABLE), or another process is allowed to ptrace it with
+// prctl(PR_SET_PTRACER)
+type PrctlEvent struct {
+	Operation PrctlOperation `json:"operation"`
+	// Value is the second argument of prctl (the seccomp mode, the dumpable flag or the ptracer pid), or the seccomp
+	// mode of a seccomp call
+	Value uint64 `json:"value"`
+	// Flags are the flags of a seccomp call
+	Flags       uint64 `json:"flags,omitempty"`
+	SeccompMode string `json:"seccomp_mode,omitempty"`
+	// PtracerAny is set when any process of the host is allowed to ptrace the caller (PR_SET_PTRACER_ANY)
+	PtracerAny bool `json:"ptracer_any,omitempty"`
+	// Weakening is set when the change weakens the sandbox: the process is made dumpable, or a ptracer is set
+	Weakening bool `json:"weakening"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *PrctlEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < prctlEventLen {
+		return 0, fmt.Errorf("while parsing PrctlEvent, got len %d, needed %d: %w", len(data), prctlEventLen, ErrNotEnoughData)
+	}
+	e.Value = ByteOrder.Uint64(data[0:8])
+	e.Flags = ByteOrder.Uint64(data[8:16])
+	e.Operation = PrctlOperation(ByteOrder.Uint32(data[16:20]))
+	// padding
+
+	switch e.Operation {
+	case PrctlSetSeccomp, PrctlSeccomp:
+		e.SeccompMode = SeccompMode(e.Value).String()
+	case PrctlSetDumpable:
+		e.Weakening = e.Value != 0
+	case PrctlSetPtracer:
+		e.PtracerAny = e.Value == prctlPtracerAny
+		e.Weakening = e.Value != 0
+	}
+	return prctlEventLen, nil
+}
+
+// PrctlEventSerializer is used to serialize PrctlEvent
+// easyjson:json
+type PrctlEventSerializer struct {
+	*PrctlEvent
+}
+
+// NewPrctlEventSerializer returns a new instance of PrctlEventSerializer
+func NewPrctlEventSerializer(e *PrctlEvent) *PrctlEventSerializer {
+	return &PrctlEventSerializer{
+		PrctlEvent: e,
+	}
+}
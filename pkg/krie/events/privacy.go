@@ -0,0 +1,146 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+// DataCategory is a category of the personal data processed by KRIE
+type DataCategory string
+
+const (
+	// ProcessDataCategory is the identity of a process: pid, command name, executable and ancestors
+	ProcessDataCategory DataCategory = "process"
+	// CommandLineDataCategory is the command line of a process
+	CommandLineDataCategory DataCategory = "command_line"
+	// UserIDDataCategory is the numeric identity of a user: uid, gid and capabilities (never the user name)
+	UserIDDataCategory DataCategory = "user_id"
+	// ContainerDataCategory is the identity of a container: cgroups, container ID, pod and image
+	ContainerDataCategory DataCategory = "container"
+	// FilePathDataCategory is a path of the host, which may contain a user name (/home/<user>/...)
+	FilePathDataCategory DataCategory = "file_path"
+	// KeyDescriptionDataCategory is the description of a kernel key, which may contain a user name or a host
+	// (krb_ccache, cifs)
+	KeyDescriptionDataCategory DataCategory = "key_description"
+	// AnalystDataCategory is the triage note of an analyst: the author and the comment of an annotation
+	AnalystDataCategory DataCategory = "analyst"
+)
+
+// PrivacyTreatment is the treatment of a field in privacy mode
+type PrivacyTreatment string
+
+const (
+	// PrivacyKept fields are kept in privacy mode, the redaction section can still drop or hash them
+	PrivacyKept PrivacyTreatment = "kept"
+	// PrivacyNotCaptured fields aren't captured in privacy mode
+	PrivacyNotCaptured PrivacyTreatment = "not_captured"
+)
+
+// DataField is a field of an event carrying personal data
+type DataField struct {
+	Field    string           `json:"field"`
+	Category DataCategory     `json:"category"`
+	Privacy  PrivacyTreatment `json:"privacy_mode"`
+}
+
+// EventSchema lists the personal data processed for an event type
+type EventSchema struct {
+	Type EventType   `json:"type"`
+	Data []DataField `json:"data"`
+}
+
+// processContextData is the personal data of the process context, for the event types with a process context
+var processContextData = []DataField{
+	{Field: "process.pid", Category: ProcessDataCategory, Privacy: PrivacyKept},
+	{Field: "process.tid", Category: ProcessDataCategory, Privacy: PrivacyKept},
+	{Field: "process.comm", Category: ProcessDataCategory, Privacy: PrivacyKept},
+	{Field: "process.exe", Category: ProcessDataCategory, Privacy: PrivacyKept},
+	{Field: "process.ancestors", Category: ProcessDataCategory, Privacy: PrivacyKept},
+	{Field: "process.args", Category: CommandLineDataCategory, Privacy: PrivacyNotCaptured},
+	{Field: "process.credentials", Category: UserIDDataCategory, Privacy: PrivacyKept},
+	{Field: "process.cgroups", Category: ContainerDataCategory, Privacy: PrivacyKept},
+	{Field: "process.container", Category: ContainerDataCategory, Privacy: PrivacyKept},
+}
+
+// eventTypeData is the personal data of the event types, on top of their process context
+var eventTypeData = map[EventType][]DataField{
+	InitModuleEventType: {
+		{Field: "init_module.file_name", Category: FilePathDataCategory, Privacy: PrivacyKept},
+		{Field: "init_module.path", Category: FilePathDataCategory, Privacy: PrivacyKept},
+		{Field: "init_module.fileless_origin", Category: FilePathDataCategory, Privacy: PrivacyKept},
+	},
+	PTraceEventType: {
+		{Field: "ptrace.pid", Category: ProcessDataCategory, Privacy: PrivacyKept},
+	},
+	ProcessVMEventType: {
+		{Field: "process_vm.pid", Category: ProcessDataCategory, Privacy: PrivacyKept},
+	},
+	BPFEventType: {
+		{Field: "bpf.fileless_origin", Category: FilePathDataCategory, Privacy: PrivacyKept},
+	},
+	KexecEventType: {
+		{Field: "kexec.cmdline", Category: CommandLineDataCategory, Privacy: PrivacyKept},
+		{Field: "kexec.kernel_path", Category: FilePathDataCategory, Privacy: PrivacyKept},
+		{Field: "kexec.initrd_path", Category: FilePathDataCategory, Privacy: PrivacyKept},
+	},
+	UsermodeHelperEventType: {
+		{Field: "usermode_helper.old_path", Category: FilePathDataCategory, Privacy: PrivacyKept},
+		{Field: "usermode_helper.new_path", Category: FilePathDataCategory, Privacy: PrivacyKept},
+	},
+	BPFMapTamperEventType: {
+		{Field: "bpf_map_tamper.owner.pid", Category: ProcessDataCategory, Privacy: PrivacyKept},
+		{Field: "bpf_map_tamper.owner.comm", Category: ProcessDataCategory, Privacy: PrivacyKept},
+	},
+	TracefsProbeEventType: {
+		{Field: "tracefs_probe.definition", Category: FilePathDataCategory, Privacy: PrivacyKept},
+		{Field: "tracefs_probe.commands.target", Category: FilePathDataCategory, Privacy: PrivacyKept},
+	},
+	MountEventType: {
+		{Field: "mount.source", Category: FilePathDataCategory, Privacy: PrivacyKept},
+		{Field: "mount.target", Category: FilePathDataCategory, Privacy: PrivacyKept},
+	},
+	KeyringEventType: {
+		{Field: "keyring.description", Category: KeyDescriptionDataCategory, Privacy: PrivacyKept},
+	},
+	BinfmtMiscEventType: {
+		{Field: "binfmt_misc.definition", Category: FilePathDataCategory, Privacy: PrivacyKept},
+		{Field: "binfmt_misc.interpreter", Category: FilePathDataCategory, Privacy: PrivacyKept},
+	},
+	HiddenProcessEventType: {
+		{Field: "hidden_process.pid", Category: ProcessDataCategory, Privacy: PrivacyKept},
+		{Field: "hidden_process.ppid", Category: ProcessDataCategory, Privacy: PrivacyKept},
+		{Field: "hidden_process.comm", Category: ProcessDataCategory, Privacy: PrivacyKept},
+	},
+	AnnotationEventType: {
+		{Field: "annotation.author", Category: AnalystDataCategory, Privacy: PrivacyKept},
+		{Field: "annotation.comment", Category: AnalystDataCategory, Privacy: PrivacyKept},
+	},
+}
+
+// Schema returns the personal data processed for each event type, in machine-readable form
+func Schema() []EventSchema {
+	var schema []EventSchema
+	for eventType := UnknownEventType + 1; eventType < MaxEventType; eventType++ {
+		entry := EventSchema{
+			Type: eventType,
+			Data: []DataField{},
+		}
+		if decoder := getEventDecoder(eventType); decoder == nil || !decoder.noProcessContext {
+			entry.Data = append(entry.Data, processContextData...)
+		}
+		entry.Data = append(entry.Data, eventTypeData[eventType]...)
+		schema = append(schema, entry)
+	}
+	return schema
+}
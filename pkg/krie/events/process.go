@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers $GOFILE
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
 
 package events
 
@@ -22,11 +22,13 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"regexp"
 )
 
 const (
 	CgroupNameLength = 128
 	TaskCommLength   = 16
+	ArgsMaxLength    = 256
 )
 
 // CgroupContext is used to parse the cgroup context of an event
@@ -116,6 +118,21 @@ func (nc *NamespaceContext) UnmarshalBinary(data []byte) (int, error) {
 	return 32, nil
 }
 
+// ContainerContext is used to serialize the container context of an event
+type ContainerContext struct {
+	ID           string `json:"id"`
+	Image        string `json:"image,omitempty"`
+	ImageDigest  string `json:"image_digest,omitempty"`
+	RuntimeClass string `json:"runtime_class,omitempty"`
+}
+
+// ProcessAncestor is an ancestor of the process of an event, resolved from the process history
+type ProcessAncestor struct {
+	PID  uint32 `json:"pid"`
+	Comm string `json:"comm"`
+	Exe  string `json:"exe,omitempty"`
+}
+
 // Cgroups is used to wrap the CgroupContext and ease serialization
 type Cgroups [CgroupSubsystemMax]CgroupContext
 
@@ -135,6 +152,23 @@ type ProcessContext struct {
 	Comm             string             `json:"comm"`
 	PID              uint32             `json:"pid"`
 	TID              uint32             `json:"tid"`
+	Args             []string           `json:"args,omitempty"`
+	ArgsTruncated    bool               `json:"args_truncated,omitempty"`
+	Container        *ContainerContext  `json:"container,omitempty"`
+	Exe              string             `json:"exe,omitempty"`
+	Ancestors        []ProcessAncestor  `json:"ancestors,omitempty"`
+}
+
+var containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// ContainerID returns the ID of the container of the process, if any
+func (pc *ProcessContext) ContainerID() string {
+	for _, cgroup := range pc.Cgroups {
+		if id := containerIDPattern.FindString(cgroup.Name); len(id) > 0 {
+			return id
+		}
+	}
+	return ""
 }
 
 // UnmarshalBinary unmarshalls a binary representation of itself
@@ -175,9 +209,34 @@ func (pc *ProcessContext) UnmarshalBinary(data []byte) (int, error) {
 	pc.TID = ByteOrder.Uint32(data[cursor+4 : cursor+8])
 	cursor += 8
 
+	if len(data[cursor:]) < 8+ArgsMaxLength {
+		return 0, fmt.Errorf("while parsing ProcessContext.Args: got len %d, needed %d: %w", len(data[cursor:]), 8+ArgsMaxLength, ErrNotEnoughData)
+	}
+	argsLen := ByteOrder.Uint32(data[cursor : cursor+4])
+	pc.ArgsTruncated = ByteOrder.Uint32(data[cursor+4:cursor+8]) == 1
+	cursor += 8
+	if argsLen > ArgsMaxLength {
+		argsLen = ArgsMaxLength
+	}
+	pc.Args = parseArgs(data[cursor : cursor+int(argsLen)])
+	cursor += ArgsMaxLength
+
 	return cursor, nil
 }
 
+// parseArgs splits the NUL separated arguments snapshot of a process
+func parseArgs(data []byte) []string {
+	data = bytes.TrimRight(data, "\x00")
+	if len(data) == 0 {
+		return nil
+	}
+	var args []string
+	for _, arg := range bytes.Split(data, []byte{0}) {
+		args = append(args, string(arg))
+	}
+	return args
+}
+
 // ProcessContextSerializer is used to serialize ProcessContext
 // easyjson:json
 type ProcessContextSerializer struct {
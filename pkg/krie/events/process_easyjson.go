@@ -1,3 +1,5 @@
+//go:build !krie_minimal
+
 // Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
 
 package events
@@ -64,6 +66,66 @@ func easyjson5c87105dDecodeGithubComGui774umeKriePkgKrieEvents(in *jlexer.Lexer,
 			out.PID = uint32(in.Uint32())
 		case "tid":
 			out.TID = uint32(in.Uint32())
+		case "args":
+			if in.IsNull() {
+				in.Skip()
+				out.Args = nil
+			} else {
+				in.Delim('[')
+				if out.Args == nil {
+					if !in.IsDelim(']') {
+						out.Args = make([]string, 0, 4)
+					} else {
+						out.Args = []string{}
+					}
+				} else {
+					out.Args = (out.Args)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v2 string
+					v2 = string(in.String())
+					out.Args = append(out.Args, v2)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "args_truncated":
+			out.ArgsTruncated = bool(in.Bool())
+		case "container":
+			if in.IsNull() {
+				in.Skip()
+				out.Container = nil
+			} else {
+				if out.Container == nil {
+					out.Container = new(ContainerContext)
+				}
+				easyjson5c87105dDecodeGithubComGui774umeKriePkgKrieEvents4(in, out.Container)
+			}
+		case "exe":
+			out.Exe = string(in.String())
+		case "ancestors":
+			if in.IsNull() {
+				in.Skip()
+				out.Ancestors = nil
+			} else {
+				in.Delim('[')
+				if out.Ancestors == nil {
+					if !in.IsDelim(']') {
+						out.Ancestors = make([]ProcessAncestor, 0, 1)
+					} else {
+						out.Ancestors = []ProcessAncestor{}
+					}
+				} else {
+					out.Ancestors = (out.Ancestors)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v5 ProcessAncestor
+					easyjson5c87105dDecodeGithubComGui774umeKriePkgKrieEvents5(in, &v5)
+					out.Ancestors = append(out.Ancestors, v5)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -108,6 +170,49 @@ func easyjson5c87105dEncodeGithubComGui774umeKriePkgKrieEvents(out *jwriter.Writ
 		out.RawString(prefix)
 		out.Uint32(uint32(in.TID))
 	}
+	if len(in.Args) != 0 {
+		const prefix string = ",\"args\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v3, v4 := range in.Args {
+				if v3 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v4))
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.ArgsTruncated {
+		const prefix string = ",\"args_truncated\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.ArgsTruncated))
+	}
+	if in.Container != nil {
+		const prefix string = ",\"container\":"
+		out.RawString(prefix)
+		easyjson5c87105dEncodeGithubComGui774umeKriePkgKrieEvents4(out, *in.Container)
+	}
+	if in.Exe != "" {
+		const prefix string = ",\"exe\":"
+		out.RawString(prefix)
+		out.String(string(in.Exe))
+	}
+	if len(in.Ancestors) != 0 {
+		const prefix string = ",\"ancestors\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v6, v7 := range in.Ancestors {
+				if v6 > 0 {
+					out.RawByte(',')
+				}
+				easyjson5c87105dEncodeGithubComGui774umeKriePkgKrieEvents5(out, v7)
+			}
+			out.RawByte(']')
+		}
+	}
 	out.RawByte('}')
 }
 
@@ -120,6 +225,125 @@ func (v ProcessContextSerializer) MarshalEasyJSON(w *jwriter.Writer) {
 func (v *ProcessContextSerializer) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjson5c87105dDecodeGithubComGui774umeKriePkgKrieEvents(l, v)
 }
+func easyjson5c87105dDecodeGithubComGui774umeKriePkgKrieEvents5(in *jlexer.Lexer, out *ProcessAncestor) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "pid":
+			out.PID = uint32(in.Uint32())
+		case "comm":
+			out.Comm = string(in.String())
+		case "exe":
+			out.Exe = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson5c87105dEncodeGithubComGui774umeKriePkgKrieEvents5(out *jwriter.Writer, in ProcessAncestor) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"pid\":"
+		out.RawString(prefix[1:])
+		out.Uint32(uint32(in.PID))
+	}
+	{
+		const prefix string = ",\"comm\":"
+		out.RawString(prefix)
+		out.String(string(in.Comm))
+	}
+	if in.Exe != "" {
+		const prefix string = ",\"exe\":"
+		out.RawString(prefix)
+		out.String(string(in.Exe))
+	}
+	out.RawByte('}')
+}
+func easyjson5c87105dDecodeGithubComGui774umeKriePkgKrieEvents4(in *jlexer.Lexer, out *ContainerContext) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = string(in.String())
+		case "image":
+			out.Image = string(in.String())
+		case "image_digest":
+			out.ImageDigest = string(in.String())
+		case "runtime_class":
+			out.RuntimeClass = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson5c87105dEncodeGithubComGui774umeKriePkgKrieEvents4(out *jwriter.Writer, in ContainerContext) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ID))
+	}
+	if in.Image != "" {
+		const prefix string = ",\"image\":"
+		out.RawString(prefix)
+		out.String(string(in.Image))
+	}
+	if in.ImageDigest != "" {
+		const prefix string = ",\"image_digest\":"
+		out.RawString(prefix)
+		out.String(string(in.ImageDigest))
+	}
+	if in.RuntimeClass != "" {
+		const prefix string = ",\"runtime_class\":"
+		out.RawString(prefix)
+		out.String(string(in.RuntimeClass))
+	}
+	out.RawByte('}')
+}
 func easyjson5c87105dDecodeGithubComGui774umeKriePkgKrieEvents3(in *jlexer.Lexer, out *CredentialsContext) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
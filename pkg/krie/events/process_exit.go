@@ -0,0 +1,77 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"time"
+)
+
+// ProcessExitEvent is the event generated when a process which triggered events exits, it summarizes the events of the
+// process. The process context of the event is the one of the last event of the process.
+type ProcessExitEvent struct {
+	FirstEvent time.Time `json:"first_event"`
+	LastEvent  time.Time `json:"last_event"`
+	// ExitDetected is the time at which the exit was detected, the process exited between the last event and this time
+	ExitDetected time.Time `json:"exit_detected"`
+	EventCount   uint64    `json:"event_count"`
+	// EventTypes is the number of events per event type
+	EventTypes map[string]uint64 `json:"event_types"`
+	// Actions is the number of events per action enforced in kernel space (block, kill, ...)
+	Actions map[string]uint64 `json:"actions,omitempty"`
+	// RuleIDs lists the rules which matched the events of the process
+	RuleIDs []string `json:"rule_ids,omitempty"`
+}
+
+// Record adds an event of the process to the summary
+func (e *ProcessExitEvent) Record(event *Event) {
+	if e.EventCount == 0 {
+		e.FirstEvent = event.Kernel.Time
+		e.EventTypes = make(map[string]uint64)
+	}
+	e.LastEvent = event.Kernel.Time
+	e.EventCount++
+	e.EventTypes[event.Kernel.Type.String()]++
+	if event.Kernel.Action > LogAction {
+		if e.Actions == nil {
+			e.Actions = make(map[string]uint64)
+		}
+		e.Actions[event.Kernel.Action.String()]++
+	}
+	if len(event.Policy.RuleID) > 0 {
+		for _, ruleID := range e.RuleIDs {
+			if ruleID == event.Policy.RuleID {
+				return
+			}
+		}
+		e.RuleIDs = append(e.RuleIDs, event.Policy.RuleID)
+	}
+}
+
+// ProcessExitEventSerializer is used to serialize ProcessExitEvent
+// easyjson:json
+type ProcessExitEventSerializer struct {
+	*ProcessExitEvent
+}
+
+// NewProcessExitEventSerializer returns a new instance of ProcessExitEventSerializer
+func NewProcessExitEventSerializer(e *ProcessExitEvent) *ProcessExitEventSerializer {
+	return &ProcessExitEventSerializer{
+		ProcessExitEvent: e,
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+// processVMEventLen is the length of the binary representation of a process_vm event
+const processVMEventLen = 56
+
+// ProcessVMOperation is the syscall of a process_vm event
+type ProcessVMOperation uint32
+
+const (
+	// ProcessVMRead is a process_vm_readv call
+	ProcessVMRead ProcessVMOperation = iota + 1
+	// ProcessVMWrite is a process_vm_writev call
+	ProcessVMWrite
+)
+
+func (o ProcessVMOperation) String() string {
+	switch o {
+	case ProcessVMRead:
+		return "process_vm_readv"
+	case ProcessVMWrite:
+		return "process_vm_writev"
+	default:
+		return fmt.Sprintf("ProcessVMOperation(%d)", o)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the operation
+func (o ProcessVMOperation) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", o)), nil
+}
+
+func addProcessVMProbes(all *[]*manager.Probe) {
+	for _, syscall := range []string{"process_vm_readv", "process_vm_writev"} {
+		*all = append(*all, ExpandSyscallProbes(&manager.Probe{
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				UID: KRIEUID,
+			},
+			SyscallFuncName: syscall,
+		}, EntryAndExit)...)
+	}
+}
+
+func addProcessVMRoutes(all *[]manager.TailCallRoute) {
+	*all = append(*all, []manager.TailCallRoute{
+		{
+			ProgArrayName: "sys_exit_progs",
+			Key:           uint32(ProcessVMEventType),
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				EBPFSection:  "tracepoint/handle_sys_process_vm_exit",
+				EBPFFuncName: "tracepoint_handle_sys_process_vm_exit",
+			},
+		},
+	}...)
+}
+
+func addProcessVMSelectors(all *[]manager.ProbesSelector) {
+	// process_vm_readv and process_vm_writev can be compiled out (CONFIG_CROSS_MEMORY_ATTACH)
+	for _, syscall := range []string{"process_vm_readv", "process_vm_writev"} {
+		*all = append(*all,
+			&manager.BestEffort{Selectors: ExpandSyscallProbesSelector(
+				manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: syscall}, EntryAndExit),
+			},
+		)
+	}
+}
+
+// ProcessVMEvent represents a process_vm_readv or process_vm_writev call: the memory of the target process is read or
+// written without going through ptrace, a common code injection technique
+type ProcessVMEvent struct {
+	Operation ProcessVMOperation `json:"operation"`
+	// PID is the target process
+	PID uint32 `json:"pid"`
+	// RemoteAddress is the address of the first buffer in the target process
+	RemoteAddress MemoryPointer `json:"remote_address,omitempty"`
+	// RequestedBytes is the total length of the buffers in the target process, only the first 8 buffers are counted
+	RequestedBytes uint64 `json:"requested_bytes"`
+	// TransferredBytes is the number of bytes read or written, 0 when the call failed
+	TransferredBytes uint64 `json:"transferred_bytes"`
+	LocalIOVCount    uint64 `json:"local_iovcnt"`
+	RemoteIOVCount   uint64 `json:"remote_iovcnt"`
+	Flags            uint64 `json:"flags,omitempty"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *ProcessVMEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < processVMEventLen {
+		return 0, fmt.Errorf("while parsing ProcessVMEvent, got len %d, needed %d: %w", len(data), processVMEventLen, ErrNotEnoughData)
+	}
+	e.RemoteAddress = MemoryPointer(ByteOrder.Uint64(data[0:8]))
+	e.RequestedBytes = ByteOrder.Uint64(data[8:16])
+	e.TransferredBytes = ByteOrder.Uint64(data[16:24])
+	e.LocalIOVCount = ByteOrder.Uint64(data[24:32])
+	e.RemoteIOVCount = ByteOrder.Uint64(data[32:40])
+	e.Flags = ByteOrder.Uint64(data[40:48])
+	e.Operation = ProcessVMOperation(ByteOrder.Uint32(data[48:52]))
+	e.PID = ByteOrder.Uint32(data[52:56])
+	return processVMEventLen, nil
+}
+
+// ProcessVMEventSerializer is used to serialize ProcessVMEvent
+// easyjson:json
+type ProcessVMEventSerializer struct {
+	*ProcessVMEvent
+}
+
+// NewProcessVMEventSerializer returns a new instance of ProcessVMEventSerializer
+func NewProcessVMEventSerializer(e *ProcessVMEvent) *ProcessVMEventSerializer {
+	return &ProcessVMEventSerializer{
+		ProcessVMEvent: e,
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+// ProfileSnapshotEvent is the diagnostic event generated when the RSS or the goroutine count of KRIE crosses its
+// threshold: a heap and a goroutine profile are written to the state directory, to debug the leaks post-hoc
+type ProfileSnapshotEvent struct {
+	// Trigger is the resource which crossed its threshold, either "rss" or "goroutines"
+	Trigger   string `json:"trigger"`
+	Value     uint64 `json:"value"`
+	Threshold uint64 `json:"threshold"`
+	// RSS is the resident set size of KRIE, in bytes
+	RSS        uint64 `json:"rss"`
+	Goroutines uint64 `json:"goroutines"`
+	// Profiles are the paths of the written profiles
+	Profiles []string `json:"profiles,omitempty"`
+	// Error is set when a profile couldn't be written
+	Error string `json:"error,omitempty"`
+}
+
+// ProfileSnapshotEventSerializer is used to serialize ProfileSnapshotEvent
+// easyjson:json
+type ProfileSnapshotEventSerializer struct {
+	*ProfileSnapshotEvent
+}
+
+// NewProfileSnapshotEventSerializer returns a new instance of ProfileSnapshotEventSerializer
+func NewProfileSnapshotEventSerializer(e *ProfileSnapshotEvent) *ProfileSnapshotEventSerializer {
+	return &ProfileSnapshotEventSerializer{
+		ProfileSnapshotEvent: e,
+	}
+}
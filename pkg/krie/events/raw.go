@@ -0,0 +1,44 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+// RawEvent is the event generated in lenient decoding mode, for an event sent by the kernel that couldn't be decoded
+type RawEvent struct {
+	// TypeID is the type of the event, as sent by the kernel
+	TypeID uint32 `json:"type_id"`
+	// Type is the string representation of TypeID
+	Type string `json:"type"`
+	// Payload is the hex encoded payload of the event
+	Payload string `json:"payload"`
+	Size    int    `json:"size"`
+	Error   string `json:"error"`
+}
+
+// RawEventSerializer is used to serialize RawEvent
+// easyjson:json
+type RawEventSerializer struct {
+	*RawEvent
+}
+
+// NewRawEventSerializer returns a new instance of RawEventSerializer
+func NewRawEventSerializer(e *RawEvent) *RawEventSerializer {
+	return &RawEventSerializer{
+		RawEvent: e,
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	// rawMemoryAccessEventLen is the length of the binary representation of a raw_memory_access event
+	rawMemoryAccessEventLen = 32
+)
+
+// RawMemoryOperation is the operation of a raw_memory_access event
+type RawMemoryOperation uint32
+
+const (
+	// RawMemoryOpen is an open of a raw memory device
+	RawMemoryOpen RawMemoryOperation = iota + 1
+	// RawMemoryRead is a read of a raw memory device
+	RawMemoryRead
+	// RawMemoryWrite is a write to a raw memory device
+	RawMemoryWrite
+	// RawMemoryMmap is a mapping of a raw memory device
+	RawMemoryMmap
+)
+
+func (o RawMemoryOperation) String() string {
+	switch o {
+	case RawMemoryOpen:
+		return "open"
+	case RawMemoryRead:
+		return "read"
+	case RawMemoryWrite:
+		return "write"
+	case RawMemoryMmap:
+		return "mmap"
+	default:
+		return fmt.Sprintf("RawMemoryOperation(%d)", o)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the operation
+func (o RawMemoryOperation) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", o)), nil
+}
+
+// rawMemoryDevices are the raw memory devices, indexed by minor number (the major number of the memory devices is 1)
+var rawMemoryDevices = map[uint32]string{
+	1: "/dev/mem",
+	2: "/dev/kmem",
+	4: "/dev/port",
+}
+
+func rawMemoryAccessProbes() []manager.ProbeIdentificationPair {
+	var ids []manager.ProbeIdentificationPair
+	for _, fn := range []string{"open_port", "read_mem", "write_mem", "mmap_mem", "read_kmem", "write_kmem", "mmap_kmem", "read_port", "write_port"} {
+		ids = append(ids, manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: "kprobe/" + fn, EBPFFuncName: "kprobe_" + fn})
+	}
+	return ids
+}
+
+func addRawMemoryAccessProbes(all *[]*manager.Probe) {
+	for _, id := range rawMemoryAccessProbes() {
+		*all = append(*all, &manager.Probe{ProbeIdentificationPair: id})
+	}
+}
+
+func addRawMemoryAccessSelectors(all *[]manager.ProbesSelector) {
+	// the memory devices can be compiled out (CONFIG_DEVMEM, CONFIG_DEVPORT), and /dev/kmem was removed in 5.13
+	var selectors []manager.ProbesSelector
+	for _, id := range rawMemoryAccessProbes() {
+		selectors = append(selectors, &manager.ProbeSelector{ProbeIdentificationPair: id})
+	}
+	*all = append(*all, &manager.BestEffort{Selectors: selectors})
+}
+
+// RawMemoryAccessEvent represents an access to /dev/mem, /dev/kmem or /dev/port. Direct physical memory access bypasses
+// the integrity checks of the kernel.
+type RawMemoryAccessEvent struct {
+	Device    string             `json:"device"`
+	Operation RawMemoryOperation `json:"operation"`
+	// Offset is the physical address (or the I/O port) of the access, it isn't set for an open
+	Offset MemoryPointer `json:"offset,omitempty"`
+	Size   uint64        `json:"size,omitempty"`
+	// Writable is set for a write, a writable mapping, or an open for writing
+	Writable bool `json:"writable,omitempty"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *RawMemoryAccessEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < rawMemoryAccessEventLen {
+		return 0, fmt.Errorf("while parsing RawMemoryAccessEvent, got len %d, needed %d: %w", len(data), rawMemoryAccessEventLen, ErrNotEnoughData)
+	}
+	e.Offset = MemoryPointer(ByteOrder.Uint64(data[0:8]))
+	e.Size = ByteOrder.Uint64(data[8:16])
+	minor := ByteOrder.Uint32(data[16:20])
+	e.Operation = RawMemoryOperation(ByteOrder.Uint32(data[20:24]))
+	e.Writable = ByteOrder.Uint32(data[24:28]) == 1
+
+	var ok bool
+	if e.Device, ok = rawMemoryDevices[minor]; !ok {
+		e.Device = fmt.Sprintf("1:%d", minor)
+	}
+	return rawMemoryAccessEventLen, nil
+}
+
+// RawMemoryAccessEventSerializer is used to serialize RawMemoryAccessEvent
+// easyjson:json
+type RawMemoryAccessEventSerializer struct {
+	*RawMemoryAccessEvent
+}
+
+// NewRawMemoryAccessEventSerializer returns a new instance of RawMemoryAccessEventSerializer
+func NewRawMemoryAccessEventSerializer(e *RawMemoryAccessEvent) *RawMemoryAccessEventSerializer {
+	return &RawMemoryAccessEventSerializer{
+		RawMemoryAccessEvent: e,
+	}
+}
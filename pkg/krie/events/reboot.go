@@ -0,0 +1,160 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+// rebootEventLen is the length of the binary representation of a reboot event
+const rebootEventLen = 16
+
+// rebootMagic1 is LINUX_REBOOT_MAGIC1, the first magic value expected by the reboot syscall
+const rebootMagic1 = 0xfee1dead
+
+// rebootMagic2 are the LINUX_REBOOT_MAGIC2* values, one of them is expected as the second magic of the reboot syscall
+var rebootMagic2 = []uint32{
+	672274793, // LINUX_REBOOT_MAGIC2
+	85072278,  // LINUX_REBOOT_MAGIC2A
+	369367448, // LINUX_REBOOT_MAGIC2B
+	537993216, // LINUX_REBOOT_MAGIC2C
+}
+
+// RebootCmd is the command of a reboot call
+type RebootCmd uint32
+
+const (
+	// RebootCmdRestart is LINUX_REBOOT_CMD_RESTART
+	RebootCmdRestart RebootCmd = 0x01234567
+	// RebootCmdHalt is LINUX_REBOOT_CMD_HALT
+	RebootCmdHalt RebootCmd = 0xCDEF0123
+	// RebootCmdCADOn is LINUX_REBOOT_CMD_CAD_ON, Ctrl-Alt-Del reboots the machine immediately
+	RebootCmdCADOn RebootCmd = 0x89ABCDEF
+	// RebootCmdCADOff is LINUX_REBOOT_CMD_CAD_OFF, Ctrl-Alt-Del sends SIGINT to init
+	RebootCmdCADOff RebootCmd = 0x00000000
+	// RebootCmdPowerOff is LINUX_REBOOT_CMD_POWER_OFF
+	RebootCmdPowerOff RebootCmd = 0x4321FEDC
+	// RebootCmdRestart2 is LINUX_REBOOT_CMD_RESTART2, the restart takes a user provided command
+	RebootCmdRestart2 RebootCmd = 0xA1B2C3D4
+	// RebootCmdSoftwareSuspend is LINUX_REBOOT_CMD_SW_SUSPEND, the machine hibernates
+	RebootCmdSoftwareSuspend RebootCmd = 0xD000FCE2
+	// RebootCmdKexec is LINUX_REBOOT_CMD_KEXEC, the kernel staged by kexec_load or kexec_file_load is executed
+	RebootCmdKexec RebootCmd = 0x45584543
+)
+
+func (c RebootCmd) String() string {
+	switch c {
+	case RebootCmdRestart:
+		return "restart"
+	case RebootCmdHalt:
+		return "halt"
+	case RebootCmdCADOn:
+		return "cad_on"
+	case RebootCmdCADOff:
+		return "cad_off"
+	case RebootCmdPowerOff:
+		return "power_off"
+	case RebootCmdRestart2:
+		return "restart2"
+	case RebootCmdSoftwareSuspend:
+		return "sw_suspend"
+	case RebootCmdKexec:
+		return "kexec"
+	default:
+		return fmt.Sprintf("RebootCmd(%#x)", uint32(c))
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the command
+func (c RebootCmd) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", c)), nil
+}
+
+// IsCtrlAltDel returns true when the command only changes the behavior of Ctrl-Alt-Del
+func (c RebootCmd) IsCtrlAltDel() bool {
+	return c == RebootCmdCADOn || c == RebootCmdCADOff
+}
+
+func addRebootProbes(all *[]*manager.Probe) {
+	*all = append(*all, ExpandSyscallProbes(&manager.Probe{
+		ProbeIdentificationPair: manager.ProbeIdentificationPair{
+			UID: KRIEUID,
+		},
+		SyscallFuncName: "reboot",
+	}, Entry)...)
+}
+
+func addRebootSelectors(all *[]manager.ProbesSelector) {
+	*all = append(*all,
+		&manager.OneOf{Selectors: ExpandSyscallProbesSelector(
+			manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: "reboot"}, Entry),
+		},
+	)
+}
+
+// RebootEvent represents a reboot call. A successful reboot doesn't return, the event is sent from the entry of the
+// syscall: a kexec into a kernel staged by an attacker is reported before the monitored kernel is gone.
+type RebootEvent struct {
+	// Magic1 and Magic2 are the magic values of the call, the kernel rejects the call unless they are valid
+	Magic1 uint32    `json:"magic1"`
+	Magic2 uint32    `json:"magic2"`
+	Cmd    RebootCmd `json:"cmd"`
+	// InvalidMagic is set when the magic values are rejected by the kernel, the call fails with EINVAL
+	InvalidMagic bool `json:"invalid_magic,omitempty"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *RebootEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < rebootEventLen {
+		return 0, fmt.Errorf("while parsing RebootEvent, got len %d, needed %d: %w", len(data), rebootEventLen, ErrNotEnoughData)
+	}
+	e.Magic1 = ByteOrder.Uint32(data[0:4])
+	e.Magic2 = ByteOrder.Uint32(data[4:8])
+	e.Cmd = RebootCmd(ByteOrder.Uint32(data[8:12]))
+	e.InvalidMagic = !e.ValidMagic()
+	return rebootEventLen, nil
+}
+
+// ValidMagic returns true when the magic values of the call are accepted by the kernel
+func (e *RebootEvent) ValidMagic() bool {
+	if e.Magic1 != rebootMagic1 {
+		return false
+	}
+	for _, magic := range rebootMagic2 {
+		if e.Magic2 == magic {
+			return true
+		}
+	}
+	return false
+}
+
+// RebootEventSerializer is used to serialize RebootEvent
+// easyjson:json
+type RebootEventSerializer struct {
+	*RebootEvent
+}
+
+// NewRebootEventSerializer returns a new instance of RebootEventSerializer
+func NewRebootEventSerializer(e *RebootEvent) *RebootEventSerializer {
+	return &RebootEventSerializer{
+		RebootEvent: e,
+	}
+}
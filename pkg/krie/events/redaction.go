@@ -0,0 +1,68 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+// RedactableField gives access to a string field of an event that can be redacted
+type RedactableField struct {
+	// EventTypes is the list of event types that have this field, an empty list means all the event types
+	EventTypes EventTypeList
+	Get        func(e *Event) []string
+	Set        func(e *Event, values []string)
+}
+
+// AppliesTo returns true if the field is set for the provided event type
+func (rf RedactableField) AppliesTo(eventType EventType) bool {
+	return rf.EventTypes.Contains(eventType)
+}
+
+// scalarField returns accessors for a single string field
+func scalarField(field func(e *Event) *string, eventTypes ...EventType) RedactableField {
+	return RedactableField{
+		EventTypes: eventTypes,
+		Get: func(e *Event) []string {
+			if value := *field(e); len(value) > 0 {
+				return []string{value}
+			}
+			return nil
+		},
+		Set: func(e *Event, values []string) {
+			if len(values) == 0 {
+				*field(e) = ""
+				return
+			}
+			*field(e) = values[0]
+		},
+	}
+}
+
+// RedactableFields lists the fields that can be redacted, indexed by their path in the JSON output
+var RedactableFields = map[string]RedactableField{
+	"process.comm": scalarField(func(e *Event) *string { return &e.Process.Comm }),
+	"process.args": {
+		Get: func(e *Event) []string { return e.Process.Args },
+		Set: func(e *Event, values []string) { e.Process.Args = values },
+	},
+	"init_module.name":                 scalarField(func(e *Event) *string { return &e.InitModule.Name }, InitModuleEventType),
+	"delete_module.name":               scalarField(func(e *Event) *string { return &e.DeleteModule.Name }, DeleteModuleEventType),
+	"bpf.map.name":                     scalarField(func(e *Event) *string { return &e.BPFEvent.Map.Name }, BPFEventType),
+	"bpf.program.name":                 scalarField(func(e *Event) *string { return &e.BPFEvent.Program.Name }, BPFEventType),
+	"kprobe.symbol":                    scalarField(func(e *Event) *string { return &e.KProbeEvent.Symbol }, KProbeEventType),
+	"sysctl.name":                      scalarField(func(e *Event) *string { return &e.SysCtlEvent.Name }, SysCtlEventType),
+	"sysctl.current_value":             scalarField(func(e *Event) *string { return &e.SysCtlEvent.CurrentValue }, SysCtlEventType),
+	"sysctl.new_value":                 scalarField(func(e *Event) *string { return &e.SysCtlEvent.NewValue }, SysCtlEventType),
+	"sysctl.new_value_overridden_with": scalarField(func(e *Event) *string { return &e.SysCtlEvent.NewValueOverriddenWith }, SysCtlEventType),
+}
@@ -0,0 +1,47 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+// ResponseEvent is the audit event generated when a responder is triggered
+type ResponseEvent struct {
+	Responder      string `json:"responder"`
+	Command        string `json:"command"`
+	TriggerEventID string `json:"trigger_event_id"`
+	TriggerRuleID  string `json:"trigger_rule_id"`
+	Status         string `json:"status"`
+	ExitCode       int    `json:"exit_code"`
+	Duration       int64  `json:"duration_ms"`
+	Error          string `json:"error,omitempty"`
+
+	// SkippedTriggers is the number of triggers skipped by the rate limiter since the previous run
+	SkippedTriggers uint64 `json:"skipped_triggers,omitempty"`
+}
+
+// ResponseEventSerializer is used to serialize ResponseEvent
+// easyjson:json
+type ResponseEventSerializer struct {
+	*ResponseEvent
+}
+
+// NewResponseEventSerializer returns a new instance of ResponseEventSerializer
+func NewResponseEventSerializer(e *ResponseEvent) *ResponseEventSerializer {
+	return &ResponseEventSerializer{
+		ResponseEvent: e,
+	}
+}
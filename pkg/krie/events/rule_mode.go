@@ -0,0 +1,129 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RuleMode is the enforcement mode of a rule
+type RuleMode string
+
+const (
+	// EnforceMode applies the configured action of the rule
+	EnforceMode RuleMode = "enforce"
+	// MonitorMode only logs the events of the rule, whatever its configured action
+	MonitorMode RuleMode = "monitor"
+)
+
+// IsMonitored returns true if the provided rule is in monitor mode. A mode applies to the rule and to its sub rules
+// (for example "sysctl" applies to "sysctl/kernel/yama/ptrace_scope"), the most specific mode wins.
+func (o *Options) IsMonitored(ruleID string) bool {
+	mode, matched := EnforceMode, -1
+	for rule, m := range o.Modes {
+		if rule != ruleID && !strings.HasPrefix(ruleID, rule+"/") {
+			continue
+		}
+		if len(rule) > matched {
+			mode, matched = m, len(rule)
+		}
+	}
+	return mode == MonitorMode
+}
+
+// enforceableRules returns the IDs of the rules configured to block, kill or alter the monitored operations,
+// regardless of their mode
+func (o *Options) enforceableRules() map[string]bool {
+	if o.enforceable != nil {
+		return o.enforceable
+	}
+	out := make(map[string]bool)
+	for eventType, action := range o.configuredEventsActions() {
+		if action >= BlockAction {
+			out[eventType.String()] = true
+		}
+	}
+	if o.ModuleSigners.Action >= BlockAction {
+		out[fmt.Sprintf("%s/%s", InitModuleEventType, UnauthorizedSignerRule)] = true
+	}
+	if o.ContainerImages.Action >= BlockAction {
+		out[fmt.Sprintf("%s/%s", InitModuleEventType, UnallowedImageRule)] = true
+	}
+	if o.SysCtlEvent.Default.isEnforced() {
+		out[fmt.Sprintf("%s/default", SysCtlEventType)] = true
+	}
+	for name, param := range o.SysCtlEvent.List {
+		if param.isEnforced() {
+			out[fmt.Sprintf("%s/%s", SysCtlEventType, name)] = true
+		}
+	}
+	o.enforceable = out
+	return out
+}
+
+// RuleMode returns the mode of the provided rule, or an empty string if the rule only logs
+func (o *Options) RuleMode(ruleID string) RuleMode {
+	if !o.enforceableRules()[ruleID] {
+		return ""
+	}
+	if o.IsMonitored(ruleID) {
+		return MonitorMode
+	}
+	return EnforceMode
+}
+
+// RuleModes returns the mode of each rule configured to block, kill or alter the monitored operations
+func (o *Options) RuleModes() map[string]RuleMode {
+	out := make(map[string]RuleMode)
+	for rule := range o.enforceableRules() {
+		out[rule] = o.RuleMode(rule)
+	}
+	return out
+}
+
+// WithRuleMode returns a copy of the options with the provided rule switched to the provided mode
+func (o *Options) WithRuleMode(ruleID string, mode RuleMode) (*Options, error) {
+	if mode != EnforceMode && mode != MonitorMode {
+		return nil, fmt.Errorf("invalid mode: %q", mode)
+	}
+	if len(ruleID) == 0 {
+		return nil, fmt.Errorf("empty rule")
+	}
+
+	out := *o
+	out.Modes = make(map[string]RuleMode, len(o.Modes)+1)
+	for rule, m := range o.Modes {
+		out.Modes[rule] = m
+	}
+	out.Modes[ruleID] = mode
+	out.eventsAction = make(map[EventType]Action)
+	out.activatedEvents = nil
+	out.enforceable = nil
+	return &out, nil
+}
+
+// MonitoredSysCtlParameter returns the provided parameter without its enforcement settings if its rule is in monitor
+// mode
+func (o *Options) MonitoredSysCtlParameter(ruleID string, param SysCtlParameter) SysCtlParameter {
+	if !o.IsMonitored(ruleID) {
+		return param
+	}
+	return SysCtlParameter{
+		CaptureReadAccess: param.CaptureReadAccess,
+	}
+}
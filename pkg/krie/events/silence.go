@@ -0,0 +1,49 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import "time"
+
+// SilenceEvent is the diagnostic event generated when an active event type stops producing events for an abnormal
+// period while its probes are attached, and when it produces events again
+type SilenceEvent struct {
+	// Status is either "silent" or "resumed"
+	Status    string    `json:"status"`
+	EventType string    `json:"event_type"`
+	LastEvent time.Time `json:"last_event"`
+	// Silence is the duration of the silence, in seconds
+	Silence int64 `json:"silence_s"`
+	// ExpectedInterval is the mean interval between two events of the event type, in milliseconds
+	ExpectedInterval int64  `json:"expected_interval_ms"`
+	BaselineEvents   uint64 `json:"baseline_events"`
+	RunningProbes    int    `json:"running_probes"`
+}
+
+// SilenceEventSerializer is used to serialize SilenceEvent
+// easyjson:json
+type SilenceEventSerializer struct {
+	*SilenceEvent
+}
+
+// NewSilenceEventSerializer returns a new instance of SilenceEventSerializer
+func NewSilenceEventSerializer(e *SilenceEvent) *SilenceEventSerializer {
+	return &SilenceEventSerializer{
+		SilenceEvent: e,
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"bytes"
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	// symbolResolutionEventLen is the length of the binary representation of a symbol_resolution event
+	symbolResolutionEventLen = 16 + SymbolNameLength
+)
+
+// SymbolResolutionSource is the way a kernel symbol was resolved
+type SymbolResolutionSource uint32
+
+const (
+	// SymbolResolutionLookupName is a call to kallsyms_lookup_name
+	SymbolResolutionLookupName SymbolResolutionSource = iota + 1
+	// SymbolResolutionProcKallsyms is an open of /proc/kallsyms
+	SymbolResolutionProcKallsyms
+)
+
+func (s SymbolResolutionSource) String() string {
+	switch s {
+	case SymbolResolutionLookupName:
+		return "kallsyms_lookup_name"
+	case SymbolResolutionProcKallsyms:
+		return "proc_kallsyms"
+	default:
+		return fmt.Sprintf("SymbolResolutionSource(%d)", s)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the source
+func (s SymbolResolutionSource) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", s)), nil
+}
+
+func symbolResolutionProbes() []manager.ProbeIdentificationPair {
+	return []manager.ProbeIdentificationPair{
+		{UID: KRIEUID, EBPFSection: "kprobe/kallsyms_lookup_name", EBPFFuncName: "kprobe_kallsyms_lookup_name"},
+		{UID: KRIEUID, EBPFSection: "kprobe/kallsyms_open", EBPFFuncName: "kprobe_kallsyms_open"},
+	}
+}
+
+func addSymbolResolutionProbes(all *[]*manager.Probe) {
+	for _, id := range symbolResolutionProbes() {
+		*all = append(*all, &manager.Probe{ProbeIdentificationPair: id})
+	}
+}
+
+func addSymbolResolutionSelectors(all *[]manager.ProbesSelector) {
+	// kallsyms can be compiled out (CONFIG_KALLSYMS)
+	var selectors []manager.ProbesSelector
+	for _, id := range symbolResolutionProbes() {
+		selectors = append(selectors, &manager.ProbeSelector{ProbeIdentificationPair: id})
+	}
+	*all = append(*all, &manager.BestEffort{Selectors: selectors})
+}
+
+// SymbolResolutionEvent represents the resolution of a kernel symbol, through kallsyms_lookup_name or /proc/kallsyms.
+// Resolving the unexported symbols is the first step of most kernel tampering techniques.
+type SymbolResolutionEvent struct {
+	Source SymbolResolutionSource `json:"source"`
+	// Symbol is the requested symbol, only set for kallsyms_lookup_name
+	Symbol string `json:"symbol,omitempty"`
+	// Caller is the function that called kallsyms_lookup_name, its module identifies the module (or
+	// bpf_kallsyms_lookup_name the bpf program) that resolved the symbol
+	Caller *KernelSymbol `json:"caller,omitempty"`
+	// Count is the number of symbol resolutions of the process since KRIE started, this one included
+	Count uint64 `json:"count"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *SymbolResolutionEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < symbolResolutionEventLen {
+		return 0, fmt.Errorf("while parsing SymbolResolutionEvent, got len %d, needed %d: %w", len(data), symbolResolutionEventLen, ErrNotEnoughData)
+	}
+	caller := MemoryPointer(ByteOrder.Uint64(data[0:8]))
+	e.Source = SymbolResolutionSource(ByteOrder.Uint32(data[8:12]))
+	e.Symbol = string(bytes.Trim(data[16:16+SymbolNameLength], "\x00"))
+	e.Caller = nil
+	if caller != 0 {
+		e.Caller = &KernelSymbol{Address: caller}
+	}
+	return symbolResolutionEventLen, nil
+}
+
+// SymbolResolutionEventSerializer is used to serialize SymbolResolutionEvent
+// easyjson:json
+type SymbolResolutionEventSerializer struct {
+	*SymbolResolutionEvent
+}
+
+// NewSymbolResolutionEventSerializer returns a new instance of SymbolResolutionEventSerializer
+func NewSymbolResolutionEventSerializer(e *SymbolResolutionEvent) *SymbolResolutionEventSerializer {
+	return &SymbolResolutionEventSerializer{
+		SymbolResolutionEvent: e,
+	}
+}
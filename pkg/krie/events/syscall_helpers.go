@@ -139,6 +139,15 @@ func HasOneMillionInstructionsAvailable() bool {
 	return false
 }
 
+// IsBPFTaskIteratorAvailable returns true if the current kernel supports the task iterators
+func IsBPFTaskIteratorAvailable() bool {
+	_ = resolveCurrentHost()
+	if currentHost != nil && (currentHost.Code >= kernel.Kernel5_8) {
+		return true
+	}
+	return false
+}
+
 func IsBPFLSMAvailable() bool {
 	_ = resolveCurrentHost()
 	if currentHost != nil && (currentHost.Code >= kernel.Kernel5_7) {
@@ -0,0 +1,68 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import "time"
+
+const (
+	// SyscallOrphanOverwritten is an entry replaced by a later entry of the same thread before its exit
+	SyscallOrphanOverwritten = "overwritten"
+	// SyscallOrphanExpired is an entry older than the correlation timeout
+	SyscallOrphanExpired = "expired"
+	// SyscallOrphanUnmatchedExit is an exit which found the entry of another event type
+	SyscallOrphanUnmatchedExit = "unmatched_exit"
+)
+
+// SyscallOrphanSample is an orphaned syscall entry or exit
+type SyscallOrphanSample struct {
+	Kind string `json:"kind"`
+	PID  uint32 `json:"pid"`
+	TID  uint32 `json:"tid"`
+	// Age is the age of the entry when it became an orphan, in milliseconds
+	Age  float64   `json:"age_ms"`
+	Time time.Time `json:"time"`
+}
+
+// SyscallOrphansEvent is the diagnostic event reporting the syscall entries and exits of an event type which couldn't
+// be correlated since the previous report: the events assembled from them were lost
+type SyscallOrphansEvent struct {
+	EventType string `json:"event_type"`
+	// Overwritten is the number of entries replaced by a later entry of the same thread before their exit
+	Overwritten uint64 `json:"overwritten"`
+	// Expired is the number of entries older than the correlation timeout, dropped at exit or by the periodic sweep
+	Expired uint64 `json:"expired"`
+	// UnmatchedExits is the number of exits which found the entry of another event type
+	UnmatchedExits uint64 `json:"unmatched_exits"`
+	// Since is the time of the previous report
+	Since   time.Time             `json:"since"`
+	Samples []SyscallOrphanSample `json:"samples,omitempty"`
+}
+
+// SyscallOrphansEventSerializer is used to serialize SyscallOrphansEvent
+// easyjson:json
+type SyscallOrphansEventSerializer struct {
+	*SyscallOrphansEvent
+}
+
+// NewSyscallOrphansEventSerializer returns a new instance of SyscallOrphansEventSerializer
+func NewSyscallOrphansEventSerializer(e *SyscallOrphansEvent) *SyscallOrphansEventSerializer {
+	return &SyscallOrphansEventSerializer{
+		SyscallOrphansEvent: e,
+	}
+}
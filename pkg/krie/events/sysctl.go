@@ -14,12 +14,13 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers $GOFILE
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
 
 package events
 
 import (
 	"fmt"
+	"path/filepath"
 
 	manager "github.com/DataDog/ebpf-manager"
 )
@@ -132,11 +133,20 @@ type SysCtlParameter struct {
 	BlockWriteAccess       bool   `yaml:"block_write_access"`
 	BlockReadAccess        bool   `yaml:"block_read_access"`
 	OverrideInputValueWith string `yaml:"override_input_value_with"`
+	// CaptureReadAccess enables the sysctl events of read accesses, write accesses are always captured
+	CaptureReadAccess bool `yaml:"capture_read_access"`
+	// PinnedValue is written back to the parameter whenever it holds another value, leave empty to disable
+	PinnedValue string `yaml:"pinned_value"`
+}
+
+// isEnforced returns true if the parameter blocks, overrides or reverts sysctl accesses
+func (scp SysCtlParameter) isEnforced() bool {
+	return scp.BlockWriteAccess || scp.BlockReadAccess || len(scp.OverrideInputValueWith) > 0 || len(scp.PinnedValue) > 0
 }
 
 // MarshalBinary returns a binary representation of itself
 func (scp SysCtlParameter) MarshalBinary() ([]byte, error) {
-	b := make([]byte, 264)
+	b := make([]byte, 272)
 	ByteOrder.PutUint32(b[0:4], uint32(len(scp.OverrideInputValueWith)))
 	if scp.BlockWriteAccess {
 		ByteOrder.PutUint16(b[4:6], 1)
@@ -147,18 +157,69 @@ func (scp SysCtlParameter) MarshalBinary() ([]byte, error) {
 	if len(scp.OverrideInputValueWith) > 0 {
 		copy(b[8:264], scp.OverrideInputValueWith)
 	}
+	if scp.CaptureReadAccess {
+		ByteOrder.PutUint32(b[264:268], 1)
+	}
 	return b, nil
 }
 
+// MaxSysCtlParameters is the max_entries of the sysctl_parameters map: the configured parameters and the parameters
+// matched by the capture_reads patterns share it
+const MaxSysCtlParameters = 1024
+
 type SysCtlOptions struct {
 	Action  Action                     `yaml:"action"`
 	Default SysCtlParameter            `yaml:"default"`
 	List    map[string]SysCtlParameter `yaml:"list"`
+	// CaptureReads lists the patterns (see filepath.Match) of the parameters whose read accesses are captured, on
+	// top of the parameters with capture_read_access. Patterns are resolved against /proc/sys at startup.
+	CaptureReads []string `yaml:"capture_reads"`
+	// RevertInterval is the number of seconds between two checks of the pinned parameters, 0 disables the periodic
+	// checks (the pinned parameters are still checked after each write event)
+	RevertInterval int64 `yaml:"revert_interval"`
 }
 
 // NewSysCtlOptions returns a new instance of SysCtlOptions
 func NewSysCtlOptions() *SysCtlOptions {
 	return &SysCtlOptions{
-		List: make(map[string]SysCtlParameter),
+		List:           make(map[string]SysCtlParameter),
+		RevertInterval: 5,
+	}
+}
+
+// IsValid returns an error if the options are invalid
+func (o *SysCtlOptions) IsValid() error {
+	if o.RevertInterval < 0 {
+		return fmt.Errorf("invalid revert_interval: %d", o.RevertInterval)
+	}
+	if len(o.List) > MaxSysCtlParameters {
+		return fmt.Errorf("invalid list: %d parameters, the maximum is %d", len(o.List), MaxSysCtlParameters)
+	}
+	for _, pattern := range o.CaptureReads {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid capture_reads pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// PinnedParameters returns the pinned value of each pinned parameter
+func (o *SysCtlOptions) PinnedParameters() map[string]string {
+	out := make(map[string]string)
+	for name, param := range o.List {
+		if len(param.PinnedValue) > 0 {
+			out[name] = param.PinnedValue
+		}
+	}
+	return out
+}
+
+// CapturesReads returns true if the read accesses to the provided parameter are captured
+func (o *SysCtlOptions) CapturesReads(name string) bool {
+	for _, pattern := range o.CaptureReads {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
 	}
+	return false
 }
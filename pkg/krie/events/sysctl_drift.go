@@ -0,0 +1,41 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+// SysCtlDriftEvent is the event generated when a sysctl parameter no longer holds its pinned value
+type SysCtlDriftEvent struct {
+	Name         string `json:"name"`
+	PinnedValue  string `json:"pinned_value"`
+	CurrentValue string `json:"current_value"`
+	// TriggerEventID is the ID of the sysctl write event that led to the check, empty for the periodic checks
+	TriggerEventID string `json:"trigger_event_id,omitempty"`
+}
+
+// SysCtlDriftEventSerializer is used to serialize SysCtlDriftEvent
+// easyjson:json
+type SysCtlDriftEventSerializer struct {
+	*SysCtlDriftEvent
+}
+
+// NewSysCtlDriftEventSerializer returns a new instance of SysCtlDriftEventSerializer
+func NewSysCtlDriftEventSerializer(e *SysCtlDriftEvent) *SysCtlDriftEventSerializer {
+	return &SysCtlDriftEventSerializer{
+		SysCtlDriftEvent: e,
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSysCtlOptionsCapturesReads(t *testing.T) {
+	opts := NewSysCtlOptions()
+	opts.CaptureReads = []string{"kernel/yama/*", "net/core/bpf_jit_*"}
+
+	for name, expected := range map[string]bool{
+		"kernel/yama/ptrace_scope":     true,
+		"net/core/bpf_jit_enable":      true,
+		"net/core/bpf_jit_harden":      true,
+		"kernel/yama":                  false,
+		"kernel/yama/sub/ptrace_scope": false,
+		"kernel/modules_disabled":      false,
+		"net/core/somaxconn":           false,
+	} {
+		assert.Equal(t, expected, opts.CapturesReads(name), name)
+	}
+	assert.False(t, NewSysCtlOptions().CapturesReads("kernel/yama/ptrace_scope"))
+}
+
+func TestSysCtlOptionsIsValid(t *testing.T) {
+	opts := NewSysCtlOptions()
+	opts.CaptureReads = []string{"kernel/["}
+	assert.Error(t, opts.IsValid())
+
+	opts = NewSysCtlOptions()
+	for i := 0; i <= MaxSysCtlParameters; i++ {
+		opts.List[fmt.Sprintf("kernel/param_%d", i)] = SysCtlParameter{CaptureReadAccess: true}
+	}
+	assert.Error(t, opts.IsValid())
+	delete(opts.List, "kernel/param_0")
+	assert.NoError(t, opts.IsValid())
+}
+
+func TestSysCtlParameterMarshalBinary(t *testing.T) {
+	u16 := func(v uint16) []byte {
+		b := make([]byte, 2)
+		ByteOrder.PutUint16(b, v)
+		return b
+	}
+	u32 := func(v uint32) []byte {
+		b := make([]byte, 4)
+		ByteOrder.PutUint32(b, v)
+		return b
+	}
+
+	// struct sysctl_parameter_value_t: override length, block write, block read, override value, capture read
+	for name, test := range map[string]struct {
+		param  SysCtlParameter
+		fields map[int][]byte
+	}{
+		"empty": {},
+		"block": {
+			param:  SysCtlParameter{BlockWriteAccess: true, BlockReadAccess: true},
+			fields: map[int][]byte{4: u16(1), 6: u16(1)},
+		},
+		"override": {
+			param:  SysCtlParameter{OverrideInputValueWith: "2"},
+			fields: map[int][]byte{0: u32(1), 8: []byte("2")},
+		},
+		"capture read": {
+			param:  SysCtlParameter{CaptureReadAccess: true, PinnedValue: "1"},
+			fields: map[int][]byte{264: u32(1)},
+		},
+	} {
+		data, err := test.param.MarshalBinary()
+		assert.NoError(t, err, name)
+		assert.Len(t, data, 272, name)
+
+		expected := make([]byte, 272)
+		for offset, value := range test.fields {
+			copy(expected[offset:], value)
+		}
+		assert.Equal(t, expected, data, name)
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+// TailCallTamperEvent is the event generated when an entry of a program array used by the tail call routes of KRIE
+// doesn't contain the expected program anymore
+type TailCallTamperEvent struct {
+	ProgArray string `json:"prog_array"`
+	Key       uint32 `json:"key"`
+	// Program is the function name of the expected program
+	Program           string `json:"program"`
+	ExpectedProgramID uint32 `json:"expected_program_id"`
+	// FoundProgramID is the ID of the program found in the program array, 0 if the entry was deleted
+	FoundProgramID uint32 `json:"found_program_id"`
+	Repaired       bool   `json:"repaired"`
+	Error          string `json:"error,omitempty"`
+}
+
+// TailCallTamperEventSerializer is used to serialize TailCallTamperEvent
+// easyjson:json
+type TailCallTamperEventSerializer struct {
+	*TailCallTamperEvent
+}
+
+// NewTailCallTamperEventSerializer returns a new instance of TailCallTamperEventSerializer
+func NewTailCallTamperEventSerializer(e *TailCallTamperEvent) *TailCallTamperEventSerializer {
+	return &TailCallTamperEventSerializer{
+		TailCallTamperEvent: e,
+	}
+}
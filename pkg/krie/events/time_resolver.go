@@ -31,17 +31,29 @@ type TimeResolver struct {
 
 // NewTimeResolver returns a new time resolver
 func NewTimeResolver() (*TimeResolver, error) {
-	bt, err := host.BootTime()
-	if err != nil {
-		return nil, err
+	var bootTime time.Time
+	if bt, err := host.BootTime(); err == nil {
+		bootTime = time.Unix(int64(bt), 0)
+	} else {
+		// /proc/stat isn't available (for example in a scratch container without /proc), use the boot clock instead
+		upTime := new(unix.Timespec)
+		if err = unix.ClockGettime(unix.CLOCK_BOOTTIME, upTime); err != nil {
+			return nil, fmt.Errorf("couldn't resolve boot time: %w", err)
+		}
+		bootTime = time.Now().Add(-time.Duration(upTime.Nano()))
 	}
 
 	tr := TimeResolver{
-		bootTime: time.Unix(int64(bt), 0),
+		bootTime: bootTime,
 	}
 	return &tr, nil
 }
 
+// BootTime returns the boot time of the host
+func (tr *TimeResolver) BootTime() time.Time {
+	return tr.bootTime
+}
+
 func (tr *TimeResolver) getUptimeOffset() (time.Duration, error) {
 	upTime := new(unix.Timespec)
 	err := unix.ClockGettime(unix.CLOCK_MONOTONIC, upTime)
@@ -0,0 +1,177 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	// tracefsProbeDefinitionLen is the maximum length of the definition captured by a tracefs_probe event
+	tracefsProbeDefinitionLen = 256
+	// tracefsProbeEventLen is the length of the binary representation of a tracefs_probe event
+	tracefsProbeEventLen = 8 + tracefsProbeDefinitionLen
+
+	// KProbeEventsFile is the tracefs file used to create kprobes and kretprobes
+	KProbeEventsFile = "kprobe_events"
+	// UProbeEventsFile is the tracefs file used to create uprobes and uretprobes
+	UProbeEventsFile = "uprobe_events"
+	// DynamicEventsFile is the tracefs file used to create any dynamic event, including kprobes and uprobes
+	DynamicEventsFile = "dynamic_events"
+)
+
+// tracefsFiles are the files tracked by the tracefs_probe event, indexed by their kernel identifier
+var tracefsFiles = map[uint32]string{
+	1: KProbeEventsFile,
+	2: UProbeEventsFile,
+	3: DynamicEventsFile,
+}
+
+func tracefsProbeProbes() []manager.ProbeIdentificationPair {
+	return []manager.ProbeIdentificationPair{
+		{UID: KRIEUID, EBPFSection: "kprobe/trace_parse_run_command", EBPFFuncName: "kprobe_trace_parse_run_command"},
+	}
+}
+
+func addTracefsProbeProbes(all *[]*manager.Probe) {
+	for _, id := range tracefsProbeProbes() {
+		*all = append(*all, &manager.Probe{ProbeIdentificationPair: id})
+	}
+}
+
+func addTracefsProbeSelectors(all *[]manager.ProbesSelector) {
+	var selectors []manager.ProbesSelector
+	for _, id := range tracefsProbeProbes() {
+		selectors = append(selectors, &manager.ProbeSelector{ProbeIdentificationPair: id})
+	}
+	*all = append(*all, &manager.AllOf{Selectors: selectors})
+}
+
+// TracefsCommandKind is the kind of a command written to a tracefs probe file
+type TracefsCommandKind string
+
+const (
+	// TracefsProbeCommand creates a kprobe or a uprobe
+	TracefsProbeCommand TracefsCommandKind = "probe"
+	// TracefsReturnProbeCommand creates a kretprobe or a uretprobe
+	TracefsReturnProbeCommand TracefsCommandKind = "return_probe"
+	// TracefsRemoveCommand removes a probe
+	TracefsRemoveCommand TracefsCommandKind = "remove"
+	// TracefsOtherCommand is any other dynamic event (eprobe, fprobe, synthetic event)
+	TracefsOtherCommand TracefsCommandKind = "other"
+)
+
+// TracefsCommand is a command of a write to a tracefs probe file, a write can contain a command per line
+type TracefsCommand struct {
+	Kind TracefsCommandKind `json:"kind"`
+	// Event is the [group/]event name of the probe, it is empty when the kernel generates it
+	Event string `json:"event,omitempty"`
+	// Target is the probed symbol, address or [path]:[offset] of the probe
+	Target string `json:"target,omitempty"`
+}
+
+// parseTracefsCommands parses the commands of a probe definition: [p|r][maxactive][:[group/]event] target [args],
+// -:[group/]event, the comments and the empty lines are ignored
+func parseTracefsCommands(definition string) []TracefsCommand {
+	var out []TracefsCommand
+	for _, line := range strings.Split(definition, "\n") {
+		tokens := strings.Fields(line)
+		if len(tokens) == 0 || strings.HasPrefix(tokens[0], "#") {
+			continue
+		}
+
+		var cmd TracefsCommand
+		switch tokens[0][0] {
+		case 'p':
+			cmd.Kind = TracefsProbeCommand
+		case 'r':
+			cmd.Kind = TracefsReturnProbeCommand
+		case '-':
+			cmd.Kind = TracefsRemoveCommand
+		default:
+			cmd.Kind = TracefsOtherCommand
+		}
+		if i := strings.IndexByte(tokens[0], ':'); i >= 0 {
+			cmd.Event = tokens[0][i+1:]
+		}
+		if cmd.Kind != TracefsRemoveCommand && len(tokens) > 1 {
+			cmd.Target = tokens[1]
+		}
+		out = append(out, cmd)
+	}
+	return out
+}
+
+// TracefsProbeEvent represents a write to kprobe_events, uprobe_events or dynamic_events. The probes created through
+// tracefs are parsed from text by the kernel, they never go through the register_kprobe path of a module.
+type TracefsProbeEvent struct {
+	// File is the tracefs file written to
+	File string `json:"file"`
+	// Definition is the text written to the file
+	Definition string `json:"definition"`
+	// Size is the length of the write
+	Size uint32 `json:"size"`
+	// Truncated is set when the write is longer than the captured definition
+	Truncated bool `json:"truncated,omitempty"`
+	// Commands are the commands parsed from the definition
+	Commands []TracefsCommand `json:"commands,omitempty"`
+}
+
+// RemovalOnly returns true when the write only removes probes
+func (e *TracefsProbeEvent) RemovalOnly() bool {
+	if len(e.Commands) == 0 {
+		return false
+	}
+	for _, cmd := range e.Commands {
+		if cmd.Kind != TracefsRemoveCommand {
+			return false
+		}
+	}
+	return true
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *TracefsProbeEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < tracefsProbeEventLen {
+		return 0, fmt.Errorf("while parsing TracefsProbeEvent, got len %d, needed %d: %w", len(data), tracefsProbeEventLen, ErrNotEnoughData)
+	}
+	e.File = tracefsFiles[ByteOrder.Uint32(data[0:4])]
+	e.Size = ByteOrder.Uint32(data[4:8])
+	e.Definition = string(bytes.Trim(data[8:tracefsProbeEventLen], "\x00"))
+	e.Truncated = e.Size >= tracefsProbeDefinitionLen
+	e.Commands = parseTracefsCommands(e.Definition)
+	return tracefsProbeEventLen, nil
+}
+
+// TracefsProbeEventSerializer is used to serialize TracefsProbeEvent
+// easyjson:json
+type TracefsProbeEventSerializer struct {
+	*TracefsProbeEvent
+}
+
+// NewTracefsProbeEventSerializer returns a new instance of TracefsProbeEventSerializer
+func NewTracefsProbeEventSerializer(e *TracefsProbeEvent) *TracefsProbeEventSerializer {
+	return &TracefsProbeEventSerializer{
+		TracefsProbeEvent: e,
+	}
+}
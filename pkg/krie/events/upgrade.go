@@ -0,0 +1,60 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+const (
+	// UpgradeHandoff is the stage of the upgrade event of the running instance, when a new instance asks it to hand off
+	UpgradeHandoff = "handoff"
+	// UpgradeCompleted is the stage of the upgrade event of the new instance, once its probes are attached
+	UpgradeCompleted = "completed"
+)
+
+// KRIEInstance identifies a KRIE process
+type KRIEInstance struct {
+	PID        uint32 `json:"pid"`
+	Executable string `json:"executable,omitempty"`
+	// Revision is the VCS revision of the binary, when it was stamped at build time
+	Revision   string `json:"revision,omitempty"`
+	PolicyHash string `json:"policy_hash,omitempty"`
+}
+
+// UpgradeEvent is the event generated when a new KRIE instance takes over from a running one: the running instance
+// records the handoff before detaching its probes, the new instance records the monitoring gap once its probes are
+// attached
+type UpgradeEvent struct {
+	Stage       string        `json:"stage"`
+	Predecessor *KRIEInstance `json:"predecessor,omitempty"`
+	Successor   *KRIEInstance `json:"successor,omitempty"`
+	// Gap is the number of seconds between the detach of the probes of the predecessor and the attach of the probes
+	// of the successor, during which the kernel wasn't monitored
+	Gap float64 `json:"gap,omitempty"`
+}
+
+// UpgradeEventSerializer is used to serialize UpgradeEvent
+// easyjson:json
+type UpgradeEventSerializer struct {
+	*UpgradeEvent
+}
+
+// NewUpgradeEventSerializer returns a new instance of UpgradeEventSerializer
+func NewUpgradeEventSerializer(e *UpgradeEvent) *UpgradeEventSerializer {
+	return &UpgradeEventSerializer{
+		UpgradeEvent: e,
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+// userfaultfdEventLen is the length of the binary representation of a userfaultfd event
+const userfaultfdEventLen = 24
+
+// UserfaultfdOperation is the operation of a userfaultfd event
+type UserfaultfdOperation uint32
+
+const (
+	// UserfaultfdCreate is a userfaultfd call
+	UserfaultfdCreate UserfaultfdOperation = iota + 1
+	// UserfaultfdAPI is a UFFDIO_API ioctl, the handshake enabling the features of a userfaultfd
+	UserfaultfdAPI
+)
+
+func (o UserfaultfdOperation) String() string {
+	switch o {
+	case UserfaultfdCreate:
+		return "create"
+	case UserfaultfdAPI:
+		return "api"
+	default:
+		return fmt.Sprintf("UserfaultfdOperation(%d)", o)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the operation
+func (o UserfaultfdOperation) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", o)), nil
+}
+
+// userfaultfdUserModeOnly is the UFFD_USER_MODE_ONLY flag, the faults of the kernel aren't handled by the userfaultfd
+const userfaultfdUserModeOnly = 1
+
+// userfaultfdFlags are the names of the flags of a userfaultfd
+var userfaultfdFlags = []struct {
+	flag uint32
+	name string
+}{
+	{userfaultfdUserModeOnly, "user_mode_only"},
+	{0x800, "nonblock"},  // O_NONBLOCK
+	{0x80000, "cloexec"}, // O_CLOEXEC
+}
+
+// userfaultfdFeatures are the names of the UFFD_FEATURE_* flags, indexed by bit
+var userfaultfdFeatures = []string{
+	"pagefault_flag_wp",
+	"event_fork",
+	"event_remap",
+	"event_remove",
+	"missing_hugetlbfs",
+	"missing_shmem",
+	"event_unmap",
+	"sigbus",
+	"thread_id",
+	"minor_hugetlbfs",
+	"minor_shmem",
+	"exact_address",
+	"wp_hugetlbfs_shmem",
+	"wp_unpopulated",
+	"poison",
+	"wp_async",
+	"move",
+}
+
+// userfaultfdFeatureNames returns the names of the UFFD_FEATURE_* flags
+func userfaultfdFeatureNames(features uint64) []string {
+	var names []string
+	for bit := 0; bit < 64; bit++ {
+		if features&(1<<bit) == 0 {
+			continue
+		}
+		if bit < len(userfaultfdFeatures) {
+			names = append(names, userfaultfdFeatures[bit])
+		} else {
+			names = append(names, fmt.Sprintf("feature_%d", bit))
+		}
+	}
+	return names
+}
+
+func addUserfaultfdProbes(all *[]*manager.Probe) {
+	*all = append(*all, ExpandSyscallProbes(&manager.Probe{
+		ProbeIdentificationPair: manager.ProbeIdentificationPair{
+			UID: KRIEUID,
+		},
+		SyscallFuncName: "userfaultfd",
+	}, EntryAndExit)...)
+	*all = append(*all, &manager.Probe{
+		ProbeIdentificationPair: manager.ProbeIdentificationPair{
+			UID:          KRIEUID,
+			EBPFSection:  "kprobe/userfaultfd_ioctl",
+			EBPFFuncName: "kprobe_userfaultfd_ioctl",
+		},
+	})
+}
+
+func addUserfaultfdRoutes(all *[]manager.TailCallRoute) {
+	*all = append(*all, []manager.TailCallRoute{
+		{
+			ProgArrayName: "sys_exit_progs",
+			Key:           uint32(UserfaultfdEventType),
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				EBPFSection:  "tracepoint/handle_sys_userfaultfd_exit",
+				EBPFFuncName: "tracepoint_handle_sys_userfaultfd_exit",
+			},
+		},
+	}...)
+}
+
+func addUserfaultfdSelectors(all *[]manager.ProbesSelector) {
+	// userfaultfd can be compiled out (CONFIG_USERFAULTFD)
+	*all = append(*all,
+		&manager.BestEffort{Selectors: ExpandSyscallProbesSelector(
+			manager.ProbeIdentificationPair{UID: KRIEUID, EBPFSection: "userfaultfd"}, EntryAndExit),
+		},
+		&manager.BestEffort{Selectors: []manager.ProbesSelector{
+			&manager.ProbeSelector{
+				ProbeIdentificationPair: manager.ProbeIdentificationPair{
+					UID:          KRIEUID,
+					EBPFSection:  "kprobe/userfaultfd_ioctl",
+					EBPFFuncName: "kprobe_userfaultfd_ioctl",
+				},
+			},
+		}},
+	)
+}
+
+// UserfaultfdEvent represents the creation of a userfaultfd, or the UFFDIO_API handshake enabling its features. A
+// userfaultfd pauses the thread faulting on a registered page until user space resolves the fault: it is the standard
+// trick to widen the window of a kernel race condition, when the faults of the kernel (copy_from_user) are handled.
+type UserfaultfdEvent struct {
+	Operation UserfaultfdOperation `json:"operation"`
+	// Flags are the flags of the userfaultfd call, or of the userfaultfd of the handshake
+	Flags []string `json:"flags,omitempty"`
+	// KernelFaults is set when the faults of the kernel are handled by the userfaultfd (no UFFD_USER_MODE_ONLY)
+	KernelFaults bool `json:"kernel_faults"`
+	// API is the API version requested by the handshake
+	API uint64 `json:"api,omitempty"`
+	// Features are the features requested by the handshake
+	Features []string `json:"features,omitempty"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *UserfaultfdEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < userfaultfdEventLen {
+		return 0, fmt.Errorf("while parsing UserfaultfdEvent, got len %d, needed %d: %w", len(data), userfaultfdEventLen, ErrNotEnoughData)
+	}
+	e.API = ByteOrder.Uint64(data[0:8])
+	e.Features = userfaultfdFeatureNames(ByteOrder.Uint64(data[8:16]))
+	e.Operation = UserfaultfdOperation(ByteOrder.Uint32(data[16:20]))
+
+	flags := ByteOrder.Uint32(data[20:24])
+	e.Flags = nil
+	for _, f := range userfaultfdFlags {
+		if flags&f.flag != 0 {
+			e.Flags = append(e.Flags, f.name)
+		}
+	}
+	e.KernelFaults = flags&userfaultfdUserModeOnly == 0
+	return userfaultfdEventLen, nil
+}
+
+// UserfaultfdEventSerializer is used to serialize UserfaultfdEvent
+// easyjson:json
+type UserfaultfdEventSerializer struct {
+	*UserfaultfdEvent
+}
+
+// NewUserfaultfdEventSerializer returns a new instance of UserfaultfdEventSerializer
+func NewUserfaultfdEventSerializer(e *UserfaultfdEvent) *UserfaultfdEventSerializer {
+	return &UserfaultfdEventSerializer{
+		UserfaultfdEvent: e,
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate go run github.com/mailru/easyjson/easyjson -no_std_marshalers -build_tags !krie_minimal $GOFILE
+
+package events
+
+import (
+	"bytes"
+	"fmt"
+
+	manager "github.com/DataDog/ebpf-manager"
+)
+
+const (
+	// usermodeHelperPathLen is the maximum length of the path of a usermode helper (KMOD_PATH_LEN)
+	usermodeHelperPathLen = 256
+	// usermodeHelperEventLen is the length of the binary representation of a usermode_helper event
+	usermodeHelperEventLen = 8 + 2*usermodeHelperPathLen
+
+	// ModprobeHelper is the helper of kernel.modprobe, executed by request_module
+	ModprobeHelper = "modprobe"
+	// CorePatternHelper is the helper of kernel.core_pattern, executed on a core dump when the pattern starts with a pipe
+	CorePatternHelper = "core_pattern"
+)
+
+// UsermodeHelperOperation is the operation of a usermode_helper event
+type UsermodeHelperOperation uint32
+
+const (
+	// UsermodeHelperWrite is a write to kernel.modprobe or kernel.core_pattern
+	UsermodeHelperWrite UsermodeHelperOperation = iota + 1
+	// UsermodeHelperExec is an execution of a usermode helper
+	UsermodeHelperExec
+)
+
+func (o UsermodeHelperOperation) String() string {
+	switch o {
+	case UsermodeHelperWrite:
+		return "write"
+	case UsermodeHelperExec:
+		return "exec"
+	default:
+		return fmt.Sprintf("UsermodeHelperOperation(%d)", o)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the operation
+func (o UsermodeHelperOperation) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%s\"", o)), nil
+}
+
+// usermodeHelpers are the helpers tracked by the usermode_helper event, indexed by their kernel identifier
+var usermodeHelpers = map[uint32]string{
+	1: ModprobeHelper,
+	2: CorePatternHelper,
+}
+
+func usermodeHelperProbes() []manager.ProbeIdentificationPair {
+	return []manager.ProbeIdentificationPair{
+		{UID: KRIEUID, EBPFSection: "kprobe/proc_dostring", EBPFFuncName: "kprobe_proc_dostring"},
+		{UID: KRIEUID, EBPFSection: "kretprobe/proc_dostring", EBPFFuncName: "kretprobe_proc_dostring"},
+		{UID: KRIEUID, EBPFSection: "kprobe/call_usermodehelper_exec", EBPFFuncName: "kprobe_call_usermodehelper_exec"},
+	}
+}
+
+func addUsermodeHelperProbes(all *[]*manager.Probe) {
+	for _, id := range usermodeHelperProbes() {
+		*all = append(*all, &manager.Probe{ProbeIdentificationPair: id})
+	}
+}
+
+func addUsermodeHelperSelectors(all *[]manager.ProbesSelector) {
+	var selectors []manager.ProbesSelector
+	for _, id := range usermodeHelperProbes() {
+		selectors = append(selectors, &manager.ProbeSelector{ProbeIdentificationPair: id})
+	}
+	*all = append(*all, &manager.AllOf{Selectors: selectors})
+}
+
+// UsermodeHelperEvent represents a change of kernel.modprobe or kernel.core_pattern, or an execution of a usermode
+// helper. Overwriting modprobe_path, then triggering request_module, is one of the most common kernel exploit payloads.
+type UsermodeHelperEvent struct {
+	Operation UsermodeHelperOperation `json:"operation"`
+	// Helper is the overwritten helper, or the helper executed, it is empty for the other usermode helpers
+	Helper string `json:"helper,omitempty"`
+	// OldPath is the value of the helper before the write, or the expected path of an hijacked helper
+	OldPath string `json:"old_path,omitempty"`
+	// NewPath is the value of the helper after the write, or the path of the executed helper
+	NewPath string `json:"new_path"`
+	// Hijacked is set when modprobe runs a path which wasn't written through kernel.modprobe: modprobe_path was
+	// overwritten in memory
+	Hijacked bool `json:"hijacked,omitempty"`
+}
+
+// UnmarshallBinary unmarshalls a binary representation of itself
+func (e *UsermodeHelperEvent) UnmarshallBinary(data []byte) (int, error) {
+	if len(data) < usermodeHelperEventLen {
+		return 0, fmt.Errorf("while parsing UsermodeHelperEvent, got len %d, needed %d: %w", len(data), usermodeHelperEventLen, ErrNotEnoughData)
+	}
+	e.Operation = UsermodeHelperOperation(ByteOrder.Uint32(data[0:4]))
+	e.Helper = usermodeHelpers[ByteOrder.Uint32(data[4:8])]
+	e.OldPath = string(bytes.Trim(data[8:8+usermodeHelperPathLen], "\x00"))
+	e.NewPath = string(bytes.Trim(data[8+usermodeHelperPathLen:usermodeHelperEventLen], "\x00"))
+	return usermodeHelperEventLen, nil
+}
+
+// UsermodeHelperEventSerializer is used to serialize UsermodeHelperEvent
+// easyjson:json
+type UsermodeHelperEventSerializer struct {
+	*UsermodeHelperEvent
+}
+
+// NewUsermodeHelperEventSerializer returns a new instance of UsermodeHelperEventSerializer
+func NewUsermodeHelperEventSerializer(e *UsermodeHelperEvent) *UsermodeHelperEventSerializer {
+	return &UsermodeHelperEventSerializer{
+		UsermodeHelperEvent: e,
+	}
+}
@@ -1,6 +1,12 @@
 package events
 
-import "bytes"
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+)
 
 // UnmarshalString unmarshal string
 func UnmarshalString(data []byte, size int) (string, error) {
@@ -10,3 +16,20 @@ func UnmarshalString(data []byte, size int) (string, error) {
 
 	return string(bytes.SplitN(data[:size], []byte{0}, 2)[0]), nil
 }
+
+var (
+	eventIDPrefix  string
+	eventIDCounter uint64
+)
+
+func init() {
+	prefix := make([]byte, 8)
+	_, _ = rand.Read(prefix)
+	eventIDPrefix = hex.EncodeToString(prefix)
+}
+
+// NewEventID returns a new unique event ID. IDs are made of a random per instance prefix and of a counter, so that they
+// remain unique across restarts.
+func NewEventID() string {
+	return fmt.Sprintf("%s-%d", eventIDPrefix, atomic.AddUint64(&eventIDCounter, 1))
+}
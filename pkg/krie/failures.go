@@ -0,0 +1,105 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// maxFailureKeys is the maximum number of (process, event type) pairs tracked by the failure counter, the counts are
+// reset once the limit is reached
+const maxFailureKeys = 4096
+
+// FailureOptions configures the detection of the repeated failures of the monitored syscalls: a process whose module
+// loads or bpf() calls keep failing is likely iterating on an exploit
+type FailureOptions struct {
+	// Window is the number of seconds during which the failures of a process are counted
+	Window int64 `yaml:"window"`
+	// Threshold is the number of failures of a process, for one event type, from which its failures are reported with
+	// the repeated_failure rule of the event type. 0 disables the rule, the failures are still reported.
+	Threshold uint64 `yaml:"threshold"`
+}
+
+// IsValid returns an error if the failure options are invalid
+func (o *FailureOptions) IsValid() error {
+	if o.Window <= 0 {
+		return fmt.Errorf("invalid window: %d", o.Window)
+	}
+	return nil
+}
+
+// NewFailureOptions returns a default set of failure options
+func NewFailureOptions() *FailureOptions {
+	return &FailureOptions{
+		Window:    60,
+		Threshold: 3,
+	}
+}
+
+type failureKey struct {
+	pid       uint32
+	eventType events.EventType
+}
+
+type failureWindow struct {
+	start time.Time
+	count uint64
+}
+
+// failureCounter counts the failed calls of each process, per event type
+type failureCounter struct {
+	options *FailureOptions
+
+	lock    sync.Mutex
+	windows map[failureKey]*failureWindow
+}
+
+func newFailureCounter(options *FailureOptions) *failureCounter {
+	return &failureCounter{
+		options: options,
+		windows: make(map[failureKey]*failureWindow),
+	}
+}
+
+// record sets the failure of an event, with the number of failures of its process in the current window
+func (fc *failureCounter) record(event *events.Event) {
+	failure := event.NewFailure()
+	if failure == nil {
+		return
+	}
+	event.Failure = failure
+
+	fc.lock.Lock()
+	defer fc.lock.Unlock()
+
+	key := failureKey{pid: event.Process.PID, eventType: event.Kernel.Type}
+	window, ok := fc.windows[key]
+	if !ok || event.Kernel.Time.Sub(window.start) > time.Duration(fc.options.Window)*time.Second {
+		if !ok && len(fc.windows) >= maxFailureKeys {
+			fc.windows = make(map[failureKey]*failureWindow)
+		}
+		window = &failureWindow{start: event.Kernel.Time}
+		fc.windows[key] = window
+	}
+	window.count++
+	failure.Count = window.count
+	failure.Repeated = fc.options.Threshold > 0 && window.count >= fc.options.Threshold
+}
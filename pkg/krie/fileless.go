@@ -0,0 +1,71 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// filelessOrigin returns the path if it points to a memfd or to a deleted file, or an empty string for a file on disk
+func filelessOrigin(path string) string {
+	if strings.HasPrefix(path, "/memfd:") || strings.HasPrefix(path, "memfd:") || strings.HasSuffix(path, " (deleted)") {
+		return path
+	}
+	return ""
+}
+
+// loaderExecutable returns the executable of the process of an event
+func loaderExecutable(event *events.Event) string {
+	if len(event.Process.Exe) > 0 {
+		return event.Process.Exe
+	}
+	exe, _ := os.Readlink(fmt.Sprintf("/proc/%d/exe", event.Process.PID))
+	return exe
+}
+
+// resolveFileless flags the module loads and the bpf program loads which don't come from a file on disk: the module
+// file, or the executable of the loader, is a memfd or a deleted file. Fileless loading is a strong malicious indicator.
+func resolveFileless(event *events.Event) {
+	switch event.Kernel.Type {
+	case events.InitModuleEventType:
+		im := &event.InitModule
+		if im.Unlinked {
+			im.Fileless = true
+			im.FilelessOrigin = im.FileName
+			if filelessOrigin(im.FileName) == "" {
+				im.FilelessOrigin += " (deleted)"
+			}
+			return
+		}
+		if origin := filelessOrigin(loaderExecutable(event)); len(origin) > 0 {
+			im.Fileless = true
+			im.FilelessOrigin = origin
+		}
+	case events.BPFEventType:
+		if event.BPFEvent.Cmd != events.BpfProgLoadCmd {
+			return
+		}
+		if origin := filelessOrigin(loaderExecutable(event)); len(origin) > 0 {
+			event.BPFEvent.Fileless = true
+			event.BPFEvent.FilelessOrigin = origin
+		}
+	}
+}
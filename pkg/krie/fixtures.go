@@ -0,0 +1,65 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// fixtureRecorder records the raw kernel payload of the first event of each type. The recorded payloads are the
+// fixtures of the golden file tests of the serializers (see pkg/krie/events/testdata).
+type fixtureRecorder struct {
+	sync.Mutex
+	directory string
+	recorded  map[events.EventType]bool
+}
+
+func newFixtureRecorder(directory string) *fixtureRecorder {
+	return &fixtureRecorder{
+		directory: directory,
+		recorded:  make(map[events.EventType]bool),
+	}
+}
+
+func (e *KRIE) recordFixture(eventType events.EventType, data []byte) {
+	if e.fixtures == nil {
+		return
+	}
+	e.fixtures.Lock()
+	defer e.fixtures.Unlock()
+	if e.fixtures.recorded[eventType] {
+		return
+	}
+	e.fixtures.recorded[eventType] = true
+
+	if err := os.MkdirAll(e.fixtures.directory, 0700); err != nil {
+		logrus.Errorf("couldn't create fixtures directory: %v", err)
+		return
+	}
+	path := filepath.Join(e.fixtures.directory, eventType.String()+".bin")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		logrus.Errorf("couldn't record %s fixture: %v", eventType, err)
+		return
+	}
+	logrus.Infof("recorded %s fixture to %s", eventType, path)
+}
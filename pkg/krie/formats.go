@@ -0,0 +1,208 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// OutputFormat is the representation of the events written to a sink
+type OutputFormat string
+
+const (
+	// JSONFormat is the native JSON representation of the events
+	JSONFormat OutputFormat = "json"
+	// ECSFormat maps the events to the Elastic Common Schema, the native representation is kept in the "krie" field
+	ECSFormat OutputFormat = "ecs"
+	// CEFFormat writes the events in the ArcSight Common Event Format
+	CEFFormat OutputFormat = "cef"
+)
+
+// UnmarshalYAML parses an output format
+func (of *OutputFormat) UnmarshalYAML(value *yaml.Node) error {
+	var format string
+	if err := value.Decode(&format); err != nil {
+		return fmt.Errorf("failed to parse output format: %w", err)
+	}
+	switch OutputFormat(format) {
+	case JSONFormat, ECSFormat, CEFFormat:
+		*of = OutputFormat(format)
+	case "":
+		*of = JSONFormat
+	default:
+		return fmt.Errorf("unknown output format: %s", format)
+	}
+	return nil
+}
+
+// contentType returns the MIME type of the format
+func (of OutputFormat) contentType() string {
+	if of == CEFFormat {
+		return "text/plain"
+	}
+	return "application/json"
+}
+
+// FormattedOutputOptions configures an additional output file, written in the provided format
+type FormattedOutputOptions struct {
+	Format OutputFormat `yaml:"format"`
+	Output string       `yaml:"output"`
+}
+
+// IsValid returns an error if the formatted output options are invalid
+func (o *FormattedOutputOptions) IsValid() error {
+	if len(o.Output) == 0 {
+		return fmt.Errorf("empty output")
+	}
+	return nil
+}
+
+// formatEvent returns the representation of the event in the provided format, jsonData is its native JSON
+// representation, terminated by a new line
+func formatEvent(format OutputFormat, event *events.Event, jsonData []byte) ([]byte, error) {
+	switch format {
+	case ECSFormat:
+		return formatECS(event, jsonData)
+	case CEFFormat:
+		return formatCEF(event), nil
+	default:
+		return jsonData, nil
+	}
+}
+
+// ecsSeverity returns the severity of an event, from the action taken by KRIE
+func ecsSeverity(event *events.Event) int {
+	switch {
+	case event.Kernel.Action >= events.KillAction:
+		return 10
+	case event.Kernel.Action == events.BlockAction:
+		return 8
+	default:
+		return 5
+	}
+}
+
+func formatECS(event *events.Event, jsonData []byte) ([]byte, error) {
+	outcome := "success"
+	if event.Kernel.Action >= events.BlockAction {
+		outcome = "failure"
+	}
+	doc := map[string]interface{}{
+		"@timestamp": event.Kernel.Time.UTC().Format(time.RFC3339Nano),
+		"event": map[string]interface{}{
+			"id":       event.ID,
+			"kind":     "alert",
+			"category": []string{"host"},
+			"action":   event.Kernel.Type.String(),
+			"outcome":  outcome,
+			"severity": ecsSeverity(event),
+			"module":   "krie",
+			"dataset":  "krie." + event.Kernel.Type.String(),
+		},
+		"rule": map[string]interface{}{
+			"id":      event.Policy.RuleID,
+			"ruleset": event.Policy.Hash,
+			"version": event.Policy.Version,
+		},
+		"krie": json.RawMessage(strings.TrimSuffix(string(jsonData), "\n")),
+	}
+	if event.HasProcessContext() {
+		process := map[string]interface{}{
+			"pid":  event.Process.PID,
+			"name": event.Process.Comm,
+			"thread": map[string]interface{}{
+				"id": event.Process.TID,
+			},
+		}
+		if len(event.Process.Exe) > 0 {
+			process["executable"] = event.Process.Exe
+		}
+		if len(event.Process.Args) > 0 {
+			process["args"] = event.Process.Args
+		}
+		doc["process"] = process
+		doc["user"] = map[string]interface{}{
+			"id": strconv.FormatUint(uint64(event.Process.Credentials.UID), 10),
+			"effective": map[string]interface{}{
+				"id": strconv.FormatUint(uint64(event.Process.Credentials.EUID), 10),
+			},
+		}
+		if id := event.Process.ContainerID(); len(id) > 0 {
+			doc["container"] = map[string]interface{}{
+				"id": id,
+			}
+		}
+	}
+	if len(event.Labels) > 0 {
+		doc["labels"] = event.Labels
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't marshal ECS event: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", `\n`, "\r", `\r`)
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+)
+
+func formatCEF(event *events.Event) []byte {
+	// the Name field is mandatory, the events without a rule are named after their type
+	name := event.Policy.RuleID
+	if len(name) == 0 {
+		name = event.Kernel.Type.String()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|Gui774ume|KRIE|1|%s|%s|%d|",
+		cefHeaderEscaper.Replace(event.Kernel.Type.String()),
+		cefHeaderEscaper.Replace(name),
+		ecsSeverity(event),
+	)
+
+	extensions := []string{
+		"rt=" + strconv.FormatInt(event.Kernel.Time.UnixMilli(), 10),
+		"act=" + cefExtensionEscaper.Replace(event.Kernel.Action.String()),
+		"externalId=" + cefExtensionEscaper.Replace(event.ID),
+		"cs1Label=policy_hash",
+		"cs1=" + cefExtensionEscaper.Replace(event.Policy.Hash),
+	}
+	if event.HasProcessContext() {
+		extensions = append(extensions,
+			"spid="+strconv.FormatUint(uint64(event.Process.PID), 10),
+			"sproc="+cefExtensionEscaper.Replace(event.Process.Comm),
+			"suid="+strconv.FormatUint(uint64(event.Process.Credentials.UID), 10),
+		)
+		if len(event.Process.Exe) > 0 {
+			extensions = append(extensions, "filePath="+cefExtensionEscaper.Replace(event.Process.Exe))
+		}
+	}
+	b.WriteString(strings.Join(extensions, " "))
+	b.WriteByte('\n')
+	return []byte(b.String())
+}
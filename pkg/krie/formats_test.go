@@ -0,0 +1,55 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+func cefHeader(t *testing.T, event *events.Event) []string {
+	line := string(formatCEF(event))
+	assert.Equal(t, 1, strings.Count(line, "\n"))
+	assert.True(t, strings.HasSuffix(line, "\n"))
+	return strings.SplitN(line, "|", 8)
+}
+
+func TestCEFNameFallsBackToEventType(t *testing.T) {
+	event := events.NewEvent()
+	event.Kernel.Type = events.BPFEventType
+
+	header := cefHeader(t, event)
+	assert.Equal(t, "bpf", header[4])
+	assert.Equal(t, "bpf", header[5])
+
+	event.Policy.RuleID = "bpf/load"
+	assert.Equal(t, "bpf/load", cefHeader(t, event)[5])
+}
+
+func TestCEFHeaderEscapesLineBreaks(t *testing.T) {
+	event := events.NewEvent()
+	event.Kernel.Type = events.BPFEventType
+	event.Policy.RuleID = "rule\r\nCEF:0|forged|header"
+
+	line := string(formatCEF(event))
+	assert.Equal(t, 1, strings.Count(line, "\n"))
+	assert.Contains(t, line, `|rule\r\nCEF:0\|forged\|header|`)
+}
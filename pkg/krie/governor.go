@@ -0,0 +1,203 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GovernorOptions configures the CPU usage governor: when the CPU usage of KRIE exceeds the budget, the readers of the
+// perf ring buffers pause between batches of events, so that the events accumulate in the ring buffers and are
+// processed in fewer, larger batches. The governor trades latency for overhead, and may cause drops if the ring
+// buffers are too small.
+type GovernorOptions struct {
+	CPUBudget       float64 `yaml:"cpu_budget"`
+	Interval        int64   `yaml:"interval"`
+	MaxPollInterval int64   `yaml:"max_poll_interval"`
+	MinBatchSize    int     `yaml:"min_batch_size"`
+	MaxBatchSize    int     `yaml:"max_batch_size"`
+}
+
+// IsValid returns an error if the governor options are invalid
+func (o *GovernorOptions) IsValid() error {
+	if o.CPUBudget < 0 {
+		return fmt.Errorf("invalid cpu_budget: %f", o.CPUBudget)
+	}
+	if !o.isEnabled() {
+		return nil
+	}
+	if o.Interval <= 0 {
+		return fmt.Errorf("invalid interval: %d", o.Interval)
+	}
+	if o.MaxPollInterval <= 0 {
+		return fmt.Errorf("invalid max_poll_interval: %d", o.MaxPollInterval)
+	}
+	if o.MinBatchSize <= 0 || o.MaxBatchSize < o.MinBatchSize {
+		return fmt.Errorf("invalid batch sizes: min_batch_size %d, max_batch_size %d", o.MinBatchSize, o.MaxBatchSize)
+	}
+	return nil
+}
+
+func (o *GovernorOptions) isEnabled() bool {
+	return o.CPUBudget > 0
+}
+
+// NewGovernorOptions returns a default set of governor options
+func NewGovernorOptions() *GovernorOptions {
+	return &GovernorOptions{
+		Interval:        1,
+		MaxPollInterval: 200,
+		MinBatchSize:    16,
+		MaxBatchSize:    1024,
+	}
+}
+
+// governor adjusts the poll interval and the batch size of the readers of the perf ring buffers to keep the CPU usage
+// of KRIE under its budget
+type governor struct {
+	options *GovernorOptions
+
+	// pollInterval is the pause between two batches, in nanoseconds
+	pollInterval int64
+	batchSize    int64
+
+	lastCPU  time.Duration
+	lastWall time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newGovernor(options *GovernorOptions) *governor {
+	return &governor{
+		options:   options,
+		batchSize: int64(options.MaxBatchSize),
+		stop:      make(chan struct{}),
+	}
+}
+
+// wrap returns a handler that pauses after each batch of events. Each reader must wrap its own handler.
+func (g *governor) wrap(handler func(data []byte) error) func(data []byte) error {
+	var count int64
+	return func(data []byte) error {
+		err := handler(data)
+		count++
+		if count >= atomic.LoadInt64(&g.batchSize) {
+			count = 0
+			if pause := atomic.LoadInt64(&g.pollInterval); pause > 0 {
+				time.Sleep(time.Duration(pause))
+			}
+		}
+		return err
+	}
+}
+
+func (g *governor) start() {
+	g.lastCPU, _ = processCPUTime()
+	g.lastWall = time.Now()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		ticker := time.NewTicker(time.Duration(g.options.Interval) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-g.stop:
+				return
+			case <-ticker.C:
+				g.adjust()
+			}
+		}
+	}()
+}
+
+// adjust measures the CPU usage since the last adjustment, and updates the poll interval and the batch size
+func (g *governor) adjust() {
+	cpu, err := processCPUTime()
+	if err != nil {
+		logrus.Debugf("governor: %v", err)
+		return
+	}
+	now := time.Now()
+	wall := now.Sub(g.lastWall)
+	if wall <= 0 {
+		return
+	}
+	usage := float64(cpu-g.lastCPU) / float64(wall) * 100
+	g.lastCPU, g.lastWall = cpu, now
+
+	pollInterval := atomic.LoadInt64(&g.pollInterval)
+	batchSize := atomic.LoadInt64(&g.batchSize)
+	maxPollInterval := int64(time.Duration(g.options.MaxPollInterval) * time.Millisecond)
+
+	switch {
+	case usage > g.options.CPUBudget:
+		// slow down: longer pauses, after smaller batches
+		if pollInterval == 0 {
+			pollInterval = int64(time.Millisecond)
+		} else {
+			pollInterval *= 2
+		}
+		if pollInterval > maxPollInterval {
+			pollInterval = maxPollInterval
+		}
+		batchSize /= 2
+		if batchSize < int64(g.options.MinBatchSize) {
+			batchSize = int64(g.options.MinBatchSize)
+		}
+	case usage < g.options.CPUBudget*0.8:
+		// speed up, until the readers don't pause anymore
+		pollInterval /= 2
+		if pollInterval < int64(time.Millisecond) {
+			pollInterval = 0
+		}
+		batchSize *= 2
+		if batchSize > int64(g.options.MaxBatchSize) {
+			batchSize = int64(g.options.MaxBatchSize)
+		}
+	default:
+		return
+	}
+
+	if pollInterval != atomic.LoadInt64(&g.pollInterval) || batchSize != atomic.LoadInt64(&g.batchSize) {
+		logrus.Debugf("governor: cpu usage %.1f%% (budget %.1f%%), poll interval %s, batch size %d", usage, g.options.CPUBudget, time.Duration(pollInterval), batchSize)
+	}
+	atomic.StoreInt64(&g.pollInterval, pollInterval)
+	atomic.StoreInt64(&g.batchSize, batchSize)
+}
+
+func (g *governor) close() {
+	close(g.stop)
+	g.wg.Wait()
+}
+
+// processCPUTime returns the user and system CPU time consumed by KRIE
+func processCPUTime() (time.Duration, error) {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0, fmt.Errorf("couldn't get the cpu usage: %w", err)
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano()), nil
+}
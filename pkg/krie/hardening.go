@@ -0,0 +1,444 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+	"kernel.org/pub/linux/libs/security/libcap/cap"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// HardeningOptions configures the de-privileged operation of KRIE: once its probes are attached, KRIE drops the
+// capabilities it no longer needs, moves into a restricted root directory and applies a seccomp profile to itself. A
+// compromised agent can then still read and write the maps of KRIE and attach programs, but it can no longer load a
+// module, kexec a kernel or remount the file systems of the host (the seccomp profile denies the mounts, since
+// CAP_SYS_ADMIN is kept for the programs attached at runtime).
+type HardeningOptions struct {
+	Enabled bool `yaml:"enabled"`
+	// Capabilities are the capabilities kept once the probes are attached (for example bpf or cap_sys_ptrace), leave
+	// empty to keep the capabilities required by the enabled features. The commands of the responders inherit them.
+	Capabilities []string `yaml:"capabilities"`
+	// RootDirectory is the directory KRIE chroots into once the probes are attached, leave empty to keep the file
+	// system view of the host. The paths used after the startup are resolved in the new root: it has to provide /proc,
+	// /sys, the state directory, the configuration and approval tokens files and the paths of the enabled features (the modules of the running kernel, the
+	// directories of the upgrade and companion sockets, crictl and the CRI socket, the commands of the responders),
+	// usually with bind mounts. KRIE refuses to start if one of them is missing.
+	RootDirectory string `yaml:"root_directory"`
+	// Seccomp denies the syscalls KRIE never makes once its probes are attached
+	Seccomp bool `yaml:"seccomp"`
+}
+
+// IsValid returns an error if the hardening options are invalid
+func (o *HardeningOptions) IsValid() error {
+	if !o.Enabled {
+		return nil
+	}
+	for _, name := range o.Capabilities {
+		if _, err := parseCapability(name); err != nil {
+			return err
+		}
+	}
+	if len(o.RootDirectory) > 0 && !strings.HasPrefix(o.RootDirectory, "/") {
+		return fmt.Errorf("invalid root_directory: %s isn't an absolute path", o.RootDirectory)
+	}
+	return nil
+}
+
+// keeps returns true if the provided capability is kept once the probes are attached, when the kept capabilities are
+// configured
+func (o *HardeningOptions) keeps(value cap.Value) bool {
+	for _, name := range o.Capabilities {
+		if parsed, err := parseCapability(name); err == nil && parsed == value {
+			return true
+		}
+	}
+	return false
+}
+
+// NewHardeningOptions returns a default set of hardening options
+func NewHardeningOptions() *HardeningOptions {
+	return &HardeningOptions{
+		Seccomp: true,
+	}
+}
+
+// parseCapability parses the name of a capability, with or without its cap_ prefix
+func parseCapability(name string) (cap.Value, error) {
+	normalized := strings.ToLower(name)
+	if !strings.HasPrefix(normalized, "cap_") {
+		normalized = "cap_" + normalized
+	}
+	value, err := cap.FromName(normalized)
+	if err != nil {
+		return 0, fmt.Errorf("invalid capability %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// requiredCapabilities returns the capabilities used by KRIE once its probes are attached
+func (e *KRIE) requiredCapabilities() []cap.Value {
+	// CAP_BPF and CAP_PERFMON were split from CAP_SYS_ADMIN in 5.8
+	split := cap.MaxBits() > cap.BPF
+	required := map[cap.Value]bool{
+		// the /proc entries of the processes of the other users (exe, environ, fd)
+		cap.SYS_PTRACE:      true,
+		cap.DAC_READ_SEARCH: true,
+		// the addresses of /proc/kallsyms, reloaded on every module load
+		cap.SYSLOG: true,
+	}
+
+	// the maps of KRIE, and the programs attached after the startup: the probes of the modules loaded later (symbol
+	// watcher) and the task iterator of the hidden process scans. The kprobes are created through perf_event_open and
+	// tracefs, which still require CAP_SYS_ADMIN with a restrictive perf_event_paranoid.
+	if split {
+		required[cap.BPF] = true
+		required[cap.PERFMON] = true
+	}
+	required[cap.SYS_ADMIN] = true
+
+	// the block actions of the module signer and container image policies unload the denied modules
+	if unloadsModules(e.options.Events) {
+		required[cap.SYS_MODULE] = true
+	}
+
+	// kernel.kptr_restrict, overridden on every reload of the kernel symbols, is written with CAP_SYS_ADMIN. The
+	// pinned parameters are reverted: the parameters of the network namespace require CAP_NET_ADMIN.
+	for name := range e.options.Events.SysCtlEvent.PinnedParameters() {
+		if strings.HasPrefix(name, "net/") {
+			required[cap.NET_ADMIN] = true
+		}
+	}
+
+	values := make([]cap.Value, 0, len(required))
+	for value := range required {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i] < values[j]
+	})
+	return values
+}
+
+// unloadsModules returns true if the provided policies unload modules: the module signer or the container image policy
+// blocks
+func unloadsModules(policies *events.Options) bool {
+	return policies.ModuleSigners.Action >= events.BlockAction || policies.ContainerImages.Action >= events.BlockAction
+}
+
+// harden drops the privileges that KRIE no longer needs once its probes are attached: the root directory is changed
+// first, while KRIE still holds CAP_SYS_CHROOT, then the capabilities are dropped, and the seccomp profile is applied
+// last since it denies chroot and capset.
+func (e *KRIE) harden() error {
+	options := e.options.Hardening
+	if !options.Enabled {
+		return nil
+	}
+
+	if len(options.RootDirectory) > 0 {
+		if err := checkRootDirectory(options.RootDirectory, e.rootDirectoryPaths()); err != nil {
+			return err
+		}
+		if err := unix.Chroot(options.RootDirectory); err != nil {
+			return fmt.Errorf("couldn't change root directory to %s: %w", options.RootDirectory, err)
+		}
+		if err := os.Chdir("/"); err != nil {
+			return fmt.Errorf("couldn't change directory to the new root: %w", err)
+		}
+	}
+
+	keep := e.requiredCapabilities()
+	if len(options.Capabilities) > 0 {
+		keep = keep[:0]
+		for _, name := range options.Capabilities {
+			value, _ := parseCapability(name)
+			keep = append(keep, value)
+		}
+	}
+	if err := dropCapabilities(keep); err != nil {
+		return fmt.Errorf("couldn't drop capabilities: %w", err)
+	}
+
+	if options.Seccomp {
+		if err := applySeccompProfile(e.seccompDeniedSyscalls()); err != nil {
+			return fmt.Errorf("couldn't apply seccomp profile: %w", err)
+		}
+	}
+
+	// the policies reloaded later can't unload modules with the privileges of the startup
+	e.moduleUnloadDropped = !unloadsModules(e.options.Events)
+
+	names := make([]string, 0, len(keep))
+	for _, value := range keep {
+		names = append(names, value.String())
+	}
+	logrus.Infof("privileges dropped: kept capabilities [%s], root directory \"%s\", seccomp %v", strings.Join(names, ", "), options.RootDirectory, options.Seccomp)
+	return nil
+}
+
+// rootDirectoryPaths returns the host paths that KRIE resolves after the startup, for the enabled features
+func (e *KRIE) rootDirectoryPaths() []string {
+	// the process contexts, the kernel symbols, the parameters of /proc/sys, the files of finit_module (through the
+	// file descriptors of the loaders) and tracefs
+	paths := []string{"/proc", "/sys"}
+	if len(e.options.StateDirectory) > 0 {
+		paths = append(paths, e.options.StateDirectory)
+	}
+	// the policy reloads read the configuration file and the approval tokens
+	if len(e.options.ConfigFile) > 0 {
+		paths = append(paths, e.options.ConfigFile)
+	}
+	if len(e.options.Approval.TokensFile) > 0 {
+		paths = append(paths, e.options.Approval.TokensFile)
+	}
+	if activated := e.options.Events.ActivatedEventTypes(); activated.Contains(events.InitModuleEventType) {
+		var uname unix.Utsname
+		if err := unix.Uname(&uname); err == nil {
+			paths = append(paths, filepath.Join("/lib/modules", unix.ByteSliceToString(uname.Release[:])))
+		}
+	}
+	if e.upgrade != nil {
+		// the socket is removed when KRIE stops
+		paths = append(paths, filepath.Dir(e.options.Upgrade.Socket))
+	}
+	if len(e.options.Companion.Socket) > 0 {
+		paths = append(paths, filepath.Dir(e.options.Companion.Socket))
+	}
+	if e.criResolver != nil {
+		paths = append(paths, e.options.CRI.CRICtl)
+		if endpoint := strings.TrimPrefix(e.options.CRI.RuntimeEndpoint, "unix://"); strings.HasPrefix(endpoint, "/") {
+			paths = append(paths, endpoint)
+		}
+	}
+	for _, responder := range e.options.Responders {
+		if strings.HasPrefix(responder.Command, "/") {
+			paths = append(paths, responder.Command)
+		}
+	}
+	if e.compactor != nil {
+		if fs, ok := e.compactor.storage.(*fileStorage); ok {
+			// the store is compacted into a new file of its directory
+			paths = append(paths, filepath.Dir(fs.path))
+		}
+	}
+	return paths
+}
+
+// checkRootDirectory returns an error if the provided root directory doesn't provide one of the paths: the features
+// using it would fail once KRIE moved into the root directory
+func checkRootDirectory(root string, paths []string) error {
+	var missing []string
+	for _, path := range paths {
+		if _, err := os.Stat(filepath.Join(root, path)); err != nil {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the root directory %s doesn't provide %s: bind mount them in the root directory, or disable the features using them", root, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// dropCapabilities removes the capabilities that aren't kept from the bounding set, so that the executed commands
+// can't regain them, then restricts the effective and permitted sets of all the threads of KRIE to the kept ones
+func dropCapabilities(keep []cap.Value) error {
+	kept := make(map[cap.Value]bool)
+	for _, value := range keep {
+		kept[value] = true
+	}
+	var dropped []cap.Value
+	for value := cap.Value(0); value < cap.MaxBits(); value++ {
+		if !kept[value] {
+			dropped = append(dropped, value)
+		}
+	}
+	if err := cap.DropBound(dropped...); err != nil {
+		return fmt.Errorf("couldn't drop the bounding set: %w", err)
+	}
+	if err := cap.ResetAmbient(); err != nil {
+		return fmt.Errorf("couldn't reset the ambient set: %w", err)
+	}
+
+	// the capabilities unknown to the running kernel can't be raised
+	var supported []cap.Value
+	for _, value := range keep {
+		if value < cap.MaxBits() {
+			supported = append(supported, value)
+		}
+	}
+	set := cap.NewSet()
+	if err := set.SetFlag(cap.Permitted, true, supported...); err != nil {
+		return err
+	}
+	if err := set.SetFlag(cap.Effective, true, supported...); err != nil {
+		return err
+	}
+	return set.SetProc()
+}
+
+const (
+	// seccompSetModeFilter is the SECCOMP_SET_MODE_FILTER operation of the seccomp syscall
+	seccompSetModeFilter = 1
+	// seccompFilterFlagTSync is SECCOMP_FILTER_FLAG_TSYNC, the filter is applied to all the threads of the process
+	seccompFilterFlagTSync = 1
+	// seccompRetErrno and seccompRetAllow are the SECCOMP_RET_ERRNO and SECCOMP_RET_ALLOW actions
+	seccompRetErrno = 0x00050000
+	seccompRetAllow = 0x7fff0000
+	// seccompDataNr and seccompDataArch are the offsets of the syscall number and of the architecture in seccomp_data
+	seccompDataNr   = 0
+	seccompDataArch = 4
+	// x32SyscallBit is set in the numbers of the syscalls of the x32 ABI
+	x32SyscallBit = 0x40000000
+)
+
+// seccompDeniedSyscalls are the syscalls denied by the seccomp profile: KRIE never makes them once its probes are
+// attached (except delete_module, see KRIE.seccompDeniedSyscalls), they are how a compromised agent would take over the
+// kernel or escape its root directory
+var seccompDeniedSyscalls = []uintptr{
+	unix.SYS_INIT_MODULE,
+	unix.SYS_FINIT_MODULE,
+	unix.SYS_DELETE_MODULE,
+	unix.SYS_KEXEC_LOAD,
+	unix.SYS_KEXEC_FILE_LOAD,
+	unix.SYS_REBOOT,
+	unix.SYS_MOUNT,
+	unix.SYS_UMOUNT2,
+	unix.SYS_PIVOT_ROOT,
+	unix.SYS_CHROOT,
+	unix.SYS_FSOPEN,
+	unix.SYS_FSCONFIG,
+	unix.SYS_FSMOUNT,
+	unix.SYS_FSPICK,
+	unix.SYS_MOVE_MOUNT,
+	unix.SYS_OPEN_TREE,
+	unix.SYS_MOUNT_SETATTR,
+	unix.SYS_SWAPON,
+	unix.SYS_SWAPOFF,
+	unix.SYS_PTRACE,
+	unix.SYS_PROCESS_VM_READV,
+	unix.SYS_PROCESS_VM_WRITEV,
+	unix.SYS_SETNS,
+	unix.SYS_UNSHARE,
+	unix.SYS_CAPSET,
+	unix.SYS_USERFAULTFD,
+	unix.SYS_IO_URING_SETUP,
+	unix.SYS_ADD_KEY,
+	unix.SYS_REQUEST_KEY,
+	unix.SYS_KEYCTL,
+	unix.SYS_OPEN_BY_HANDLE_AT,
+	unix.SYS_SETTIMEOFDAY,
+	unix.SYS_CLOCK_SETTIME,
+	unix.SYS_ADJTIMEX,
+	unix.SYS_CLOCK_ADJTIME,
+	unix.SYS_SETHOSTNAME,
+	unix.SYS_SETDOMAINNAME,
+	unix.SYS_ACCT,
+	unix.SYS_QUOTACTL,
+}
+
+// seccompDeniedSyscalls returns the syscalls denied by the seccomp profile of KRIE: delete_module is allowed when the
+// policies unload modules
+func (e *KRIE) seccompDeniedSyscalls() []uintptr {
+	if !unloadsModules(e.options.Events) {
+		return seccompDeniedSyscalls
+	}
+	denied := make([]uintptr, 0, len(seccompDeniedSyscalls))
+	for _, nr := range seccompDeniedSyscalls {
+		if nr != unix.SYS_DELETE_MODULE {
+			denied = append(denied, nr)
+		}
+	}
+	return denied
+}
+
+// seccompArch returns the AUDIT_ARCH_* value of the syscalls of KRIE, and whether the x32 syscalls share it
+func seccompArch() (uint32, bool, error) {
+	switch runtime.GOARCH {
+	case "amd64":
+		return unix.AUDIT_ARCH_X86_64, true, nil
+	case "arm64":
+		return unix.AUDIT_ARCH_AARCH64, false, nil
+	default:
+		return 0, false, fmt.Errorf("unsupported architecture %s", runtime.GOARCH)
+	}
+}
+
+func bpfStmt(code uint16, k uint32) unix.SockFilter {
+	return unix.SockFilter{Code: code, K: k}
+}
+
+func bpfJump(code uint16, k uint32, jt uint8, jf uint8) unix.SockFilter {
+	return unix.SockFilter{Code: code, Jt: jt, Jf: jf, K: k}
+}
+
+// seccompFilter returns the classic BPF program of the seccomp profile: the syscalls of the other architectures (the
+// int 0x80 entry of a 64-bit process) and the denied syscalls fail with EPERM, the others are allowed
+func seccompFilter(arch uint32, x32 bool, denied []uintptr) []unix.SockFilter {
+	deny := bpfStmt(unix.BPF_RET|unix.BPF_K, seccompRetErrno|uint32(unix.EPERM))
+	filter := []unix.SockFilter{
+		bpfStmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, seccompDataArch),
+		bpfJump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, arch, 1, 0),
+		deny,
+		bpfStmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, seccompDataNr),
+	}
+	if x32 {
+		filter = append(filter, bpfJump(unix.BPF_JMP|unix.BPF_JGE|unix.BPF_K, x32SyscallBit, 0, 1), deny)
+	}
+	for _, nr := range denied {
+		filter = append(filter, bpfJump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, uint32(nr), 0, 1), deny)
+	}
+	return append(filter, bpfStmt(unix.BPF_RET|unix.BPF_K, seccompRetAllow))
+}
+
+// applySeccompProfile applies the seccomp profile to all the threads of KRIE. no_new_privs is set on the calling
+// thread, the synchronization of the filter propagates it to the other threads.
+func applySeccompProfile(denied []uintptr) error {
+	arch, x32, err := seccompArch()
+	if err != nil {
+		return err
+	}
+	filter := seccompFilter(arch, x32, denied)
+	program := unix.SockFprog{
+		Len:    uint16(len(filter)),
+		Filter: &filter[0],
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err = unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("couldn't set no_new_privs: %w", err)
+	}
+	ret, _, errno := unix.Syscall(unix.SYS_SECCOMP, seccompSetModeFilter, seccompFilterFlagTSync, uintptr(unsafe.Pointer(&program)))
+	if errno != 0 {
+		return errno
+	}
+	if ret != 0 {
+		// the ID of the thread that couldn't be synchronized
+		return fmt.Errorf("couldn't synchronize thread %d", ret)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+	"kernel.org/pub/linux/libs/security/libcap/cap"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+func TestRequiredCapabilities(t *testing.T) {
+	e := &KRIE{options: NewOptions()}
+	required := e.requiredCapabilities()
+	assert.True(t, sort.SliceIsSorted(required, func(i, j int) bool { return required[i] < required[j] }))
+
+	// the programs attached at runtime need the capabilities of the startup
+	assert.Contains(t, required, cap.SYS_ADMIN)
+	if cap.MaxBits() > cap.BPF {
+		assert.Contains(t, required, cap.BPF)
+		assert.Contains(t, required, cap.PERFMON)
+	}
+	for _, dropped := range []cap.Value{cap.SYS_MODULE, cap.SYS_BOOT, cap.SYS_CHROOT, cap.NET_ADMIN} {
+		assert.NotContains(t, required, dropped)
+	}
+
+	e.options.Events.SysCtlEvent.List = map[string]events.SysCtlParameter{
+		"kernel/modules_disabled": {PinnedValue: "1"},
+	}
+	assert.NotContains(t, e.requiredCapabilities(), cap.NET_ADMIN)
+	e.options.Events.SysCtlEvent.List["net/core/bpf_jit_harden"] = events.SysCtlParameter{PinnedValue: "2"}
+	assert.Contains(t, e.requiredCapabilities(), cap.NET_ADMIN)
+}
+
+func TestRootDirectoryPaths(t *testing.T) {
+	e := &KRIE{options: NewOptions()}
+	e.options.ConfigFile = "/etc/krie/config.yaml"
+	e.options.Approval.TokensFile = "/etc/krie/tokens"
+
+	// the policy reloads happen after the chroot
+	paths := e.rootDirectoryPaths()
+	assert.Contains(t, paths, "/etc/krie/config.yaml")
+	assert.Contains(t, paths, "/etc/krie/tokens")
+}
+
+func TestHardeningModuleUnload(t *testing.T) {
+	e := &KRIE{options: NewOptions(), policyLock: &sync.RWMutex{}, criResolver: newCRIResolver(NewCRIOptions())}
+	assert.NotContains(t, e.requiredCapabilities(), cap.SYS_MODULE)
+	assert.Contains(t, e.seccompDeniedSyscalls(), uintptr(unix.SYS_DELETE_MODULE))
+
+	// the block actions unload the denied modules
+	e.options.Events.ContainerImages.Action = events.BlockAction
+	e.options.Events.ContainerImages.Digests = []string{"sha256:" + strings.Repeat("a", 64)}
+	assert.Contains(t, e.requiredCapabilities(), cap.SYS_MODULE)
+	assert.NotContains(t, e.seccompDeniedSyscalls(), uintptr(unix.SYS_DELETE_MODULE))
+	assert.Contains(t, e.seccompDeniedSyscalls(), uintptr(unix.SYS_INIT_MODULE))
+
+	// a reload can't enable them once the privileges were dropped
+	policies := e.options.Events
+	e.options.Events = events.NewEventsOptions()
+	e.moduleUnloadDropped = true
+	err := e.updatePolicies("", policies, nil, &events.PolicyChangeEvent{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "requires a restart")
+	}
+}
+
+func TestCheckRootDirectory(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "proc"), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "var/lib/krie"), 0755))
+
+	assert.NoError(t, checkRootDirectory(root, []string{"/proc", "/var/lib/krie"}))
+	err := checkRootDirectory(root, []string{"/proc", "/sys", "/usr/local/bin/crictl"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "/sys, /usr/local/bin/crictl")
+	}
+}
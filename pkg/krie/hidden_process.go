@@ -0,0 +1,313 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cilium/ebpf/link"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	HiddenProcessesScannerName = "hidden_processes"
+
+	// hiddenProcessTaskLen is the length of a hidden_process_task_t record written by the task iterator
+	hiddenProcessTaskLen = 32
+)
+
+// HiddenProcessOptions configures the periodic comparison of the processes known to the kernel, listed by a BPF task
+// iterator, with the processes listed in /proc
+type HiddenProcessOptions struct {
+	// Interval is the number of seconds between two scans, 0 disables the periodic scans. The hidden_processes
+	// scanner can still be run on demand.
+	Interval int64 `yaml:"interval"`
+}
+
+// IsValid returns an error if the hidden process options are invalid
+func (o *HiddenProcessOptions) IsValid() error {
+	if o.Interval < 0 {
+		return fmt.Errorf("invalid interval: %d", o.Interval)
+	}
+	return nil
+}
+
+// NewHiddenProcessOptions returns a default set of hidden process options
+func NewHiddenProcessOptions() *HiddenProcessOptions {
+	return &HiddenProcessOptions{
+		Interval: 60,
+	}
+}
+
+// hiddenProcessTask is a thread group leader listed by the task iterator
+type hiddenProcessTask struct {
+	pid       uint32
+	ppid      uint32
+	comm      string
+	startTime uint64
+}
+
+// hiddenProcessScanner looks for the processes known to the kernel but missing from the listing of /proc. It runs on
+// demand, and periodically when an interval is set: each hidden process is then reported once by a hidden_process
+// event.
+type hiddenProcessScanner struct {
+	krie    *KRIE
+	options *HiddenProcessOptions
+
+	lock sync.Mutex
+	iter *link.Iter
+	// reported are the hidden processes already reported, indexed by pid
+	reported map[uint32]uint64
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newHiddenProcessScanner(krie *KRIE, options *HiddenProcessOptions) *hiddenProcessScanner {
+	return &hiddenProcessScanner{
+		krie:     krie,
+		options:  options,
+		reported: make(map[uint32]uint64),
+		stop:     make(chan struct{}),
+	}
+}
+
+func (hs *hiddenProcessScanner) Name() string {
+	return HiddenProcessesScannerName
+}
+
+func (hs *hiddenProcessScanner) Scan(_ context.Context) ([]Finding, error) {
+	hidden, err := hs.scan()
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	for _, process := range hidden {
+		findings = append(findings, Finding{
+			Message: fmt.Sprintf("process %d (%s) is known to the kernel but not listed in /proc", process.PID, process.Comm),
+			Details: map[string]string{
+				"pid":       strconv.FormatUint(uint64(process.PID), 10),
+				"ppid":      strconv.FormatUint(uint64(process.PPID), 10),
+				"comm":      process.Comm,
+				"reachable": strconv.FormatBool(process.Reachable),
+			},
+		})
+	}
+	return findings, nil
+}
+
+// attach creates the link of the task iterator, it is kept until KRIE stops so that the scans don't need the
+// privileges of the attachment
+func (hs *hiddenProcessScanner) attach() (*link.Iter, error) {
+	hs.lock.Lock()
+	defer hs.lock.Unlock()
+	if hs.iter != nil {
+		return hs.iter, nil
+	}
+	if hs.krie.manager == nil {
+		return nil, errors.New("KRIE isn't started")
+	}
+	programs, found, err := hs.krie.manager.GetProgram(events.TaskIteratorProbe)
+	if err != nil || !found || len(programs) == 0 {
+		return nil, fmt.Errorf("the task iterator isn't available (it requires a 5.8+ kernel): %v", err)
+	}
+	if hs.iter, err = link.AttachIter(link.IterOptions{Program: programs[0]}); err != nil {
+		return nil, fmt.Errorf("couldn't attach the task iterator: %w", err)
+	}
+	return hs.iter, nil
+}
+
+// start attaches the task iterator, and begins the periodic scans
+func (hs *hiddenProcessScanner) start() {
+	if _, err := hs.attach(); err != nil {
+		// the periodic scans were requested, their absence must not go unnoticed
+		if hs.options.Interval > 0 {
+			logrus.Warnf("the hidden process scans are disabled: %v", err)
+		} else {
+			logrus.Debugf("the hidden process scans are disabled: %v", err)
+		}
+		return
+	}
+	if hs.options.Interval == 0 {
+		return
+	}
+
+	hs.wg.Add(1)
+	go func() {
+		defer hs.wg.Done()
+		ticker := time.NewTicker(time.Duration(hs.options.Interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-hs.stop:
+				return
+			case <-ticker.C:
+				hs.check()
+			}
+		}
+	}()
+}
+
+func (hs *hiddenProcessScanner) close() {
+	close(hs.stop)
+	hs.wg.Wait()
+
+	hs.lock.Lock()
+	defer hs.lock.Unlock()
+	if hs.iter != nil {
+		_ = hs.iter.Close()
+		hs.iter = nil
+	}
+}
+
+// kernelTasks runs the task iterator, and returns the thread group leaders indexed by pid
+func (hs *hiddenProcessScanner) kernelTasks() (map[uint32]hiddenProcessTask, error) {
+	iter, err := hs.attach()
+	if err != nil {
+		return nil, err
+	}
+	reader, err := iter.Open()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't run the task iterator: %w", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the task iterator: %w", err)
+	}
+
+	tasks := make(map[uint32]hiddenProcessTask, len(data)/hiddenProcessTaskLen)
+	for cursor := 0; cursor+hiddenProcessTaskLen <= len(data); cursor += hiddenProcessTaskLen {
+		record := data[cursor : cursor+hiddenProcessTaskLen]
+		task := hiddenProcessTask{
+			startTime: events.ByteOrder.Uint64(record[0:8]),
+			pid:       events.ByteOrder.Uint32(record[8:12]),
+			ppid:      events.ByteOrder.Uint32(record[12:16]),
+		}
+		comm := record[16:32]
+		if end := bytes.IndexByte(comm, 0); end >= 0 {
+			comm = comm[:end]
+		}
+		task.comm = string(comm)
+		tasks[task.pid] = task
+	}
+	return tasks, nil
+}
+
+// listProcPIDs returns the pids listed in /proc
+func listProcPIDs() (map[uint32]bool, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	pids := make(map[uint32]bool, len(entries))
+	for _, entry := range entries {
+		if pid, err := strconv.ParseUint(entry.Name(), 10, 32); err == nil {
+			pids[uint32(pid)] = true
+		}
+	}
+	return pids, nil
+}
+
+// scan returns the processes listed by the task iterator but not in /proc. A process which exits between the
+// iteration and the listing of /proc isn't listed either: the candidates are confirmed by a second iteration and a
+// second listing, and only the processes found by both iterations with the same start time are reported.
+func (hs *hiddenProcessScanner) scan() ([]events.HiddenProcessEvent, error) {
+	tasks, err := hs.kernelTasks()
+	if err != nil {
+		return nil, err
+	}
+	listed, err := listProcPIDs()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list /proc: %w", err)
+	}
+	var candidates []hiddenProcessTask
+	for pid, task := range tasks {
+		if !listed[pid] {
+			candidates = append(candidates, task)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	if tasks, err = hs.kernelTasks(); err != nil {
+		return nil, err
+	}
+	if listed, err = listProcPIDs(); err != nil {
+		return nil, fmt.Errorf("couldn't list /proc: %w", err)
+	}
+	var hidden []events.HiddenProcessEvent
+	for _, candidate := range candidates {
+		task, ok := tasks[candidate.pid]
+		if !ok || task.startTime != candidate.startTime || listed[candidate.pid] {
+			continue
+		}
+		_, err = os.Stat(fmt.Sprintf("/proc/%d", task.pid))
+		hidden = append(hidden, events.HiddenProcessEvent{
+			PID:       task.pid,
+			PPID:      task.ppid,
+			Comm:      task.comm,
+			StartTime: hs.krie.timeResolver.ResolveMonotonicTimestamp(task.startTime),
+			Reachable: err == nil,
+		})
+	}
+	return hidden, nil
+}
+
+// check runs a scan, and reports the hidden processes which weren't reported yet
+func (hs *hiddenProcessScanner) check() {
+	hidden, err := hs.scan()
+	if err != nil {
+		logrus.Debugf("couldn't scan for hidden processes: %v", err)
+		return
+	}
+
+	hs.lock.Lock()
+	stillHidden := make(map[uint32]uint64, len(hidden))
+	var toReport []events.HiddenProcessEvent
+	for _, process := range hidden {
+		startTime := uint64(process.StartTime.UnixNano())
+		stillHidden[process.PID] = startTime
+		if reported, ok := hs.reported[process.PID]; !ok || reported != startTime {
+			toReport = append(toReport, process)
+		}
+	}
+	// the processes which exited or were unhidden are forgotten, they are reported again if they are hidden again
+	hs.reported = stillHidden
+	hs.lock.Unlock()
+
+	for _, process := range toReport {
+		logrus.Warnf("process %d (%s) is hidden from /proc", process.PID, process.Comm)
+		event := events.NewEvent()
+		event.Kernel.Type = events.HiddenProcessEventType
+		event.HiddenProcessEvent = process
+		if err = hs.krie.emitEvent(event); err != nil {
+			logrus.Errorf("couldn't send hidden_process event: %v", err)
+		}
+	}
+}
@@ -0,0 +1,465 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// Intel feed formats
+const (
+	// IntelPlainFormat is a list of values, one per line, of the indicator type of the feed
+	IntelPlainFormat = "plain"
+	// IntelMISPFormat is a MISP event or a MISP restSearch response, the attributes are the indicators
+	IntelMISPFormat = "misp"
+	// IntelSTIXFormat is a STIX 2.1 bundle or a TAXII 2.1 envelope, the patterns of the indicators are the indicators
+	IntelSTIXFormat = "stix"
+)
+
+const (
+	intelMatchesMetricName = "krie_intel_matches_total"
+	intelMatchesMetricHelp = "Number of event values found in the threat intel feeds, by feed"
+
+	// taxiiContentType is the media type of the TAXII 2.1 requests
+	taxiiContentType = "application/taxii+json;version=2.1"
+)
+
+// IntelOptions configures the threat intel feeds: the module names, the hashes, the bpf program tags and the kernel
+// symbols of the events are matched against the indicators of the feeds
+type IntelOptions struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is the number of seconds between two refreshes of the feeds, 0 loads the feeds once at startup
+	Interval int64 `yaml:"interval"`
+	// Feeds are the threat intel feeds
+	Feeds []*IntelFeedOptions `yaml:"feeds"`
+}
+
+// IsValid returns an error if the intel options are invalid
+func (o *IntelOptions) IsValid() error {
+	if !o.Enabled {
+		return nil
+	}
+	if o.Interval < 0 {
+		return fmt.Errorf("invalid interval: %d", o.Interval)
+	}
+	if len(o.Feeds) == 0 {
+		return fmt.Errorf("no feed configured")
+	}
+	names := make(map[string]bool)
+	for i, feed := range o.Feeds {
+		if err := feed.IsValid(); err != nil {
+			return fmt.Errorf("invalid feed %d: %w", i, err)
+		}
+		if names[feed.Name] {
+			return fmt.Errorf("duplicate feed name: %s", feed.Name)
+		}
+		names[feed.Name] = true
+	}
+	return nil
+}
+
+// NewIntelOptions returns a default set of intel options
+func NewIntelOptions() *IntelOptions {
+	return &IntelOptions{
+		Interval: 3600,
+	}
+}
+
+// IntelFeedOptions configures a threat intel feed, read from a local file or downloaded
+type IntelFeedOptions struct {
+	// Name is the name of the feed, it is reported in the intel field of the matching events
+	Name string `yaml:"name"`
+	// Path is the path of the local file of the feed
+	Path string `yaml:"path"`
+	// URL is the URL of the feed, a TAXII 2.1 collection is queried on its objects endpoint
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+	Timeout int64             `yaml:"timeout"`
+	// Format is the format of the feed: plain, misp or stix
+	Format string `yaml:"format"`
+	// Type is the indicator type of the values of a plain feed: sha256, module, symbol or bpf_tag
+	Type string `yaml:"type"`
+}
+
+// IsValid returns an error if the feed options are invalid
+func (o *IntelFeedOptions) IsValid() error {
+	if len(o.Name) == 0 {
+		return fmt.Errorf("missing name")
+	}
+	if (len(o.Path) == 0) == (len(o.URL) == 0) {
+		return fmt.Errorf("exactly one of path and url is required")
+	}
+	if len(o.URL) > 0 {
+		if !strings.HasPrefix(o.URL, "https://") && !strings.HasPrefix(o.URL, "http://") {
+			return fmt.Errorf("invalid url: %s", o.URL)
+		}
+		if o.Timeout <= 0 {
+			return fmt.Errorf("invalid timeout: %d", o.Timeout)
+		}
+	}
+	switch o.Format {
+	case IntelPlainFormat:
+		if !isIntelType(o.Type) {
+			return fmt.Errorf("invalid type: %s", o.Type)
+		}
+	case IntelMISPFormat, IntelSTIXFormat:
+	default:
+		return fmt.Errorf("invalid format: %s", o.Format)
+	}
+	return nil
+}
+
+// UnmarshalYAML sets the default values of a feed
+func (o *IntelFeedOptions) UnmarshalYAML(value *yaml.Node) error {
+	type rawFeed IntelFeedOptions
+	raw := rawFeed{
+		Timeout: 30,
+		Format:  IntelPlainFormat,
+	}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	*o = IntelFeedOptions(raw)
+	return nil
+}
+
+func isIntelType(kind string) bool {
+	switch kind {
+	case events.IntelSHA256, events.IntelModule, events.IntelSymbol, events.IntelBPFTag:
+		return true
+	}
+	return false
+}
+
+// normalizeIntelValue returns the form of a value used to match it against the indicators, the hashes and the tags
+// are case insensitive
+func normalizeIntelValue(kind string, value string) string {
+	switch kind {
+	case events.IntelSHA256, events.IntelBPFTag:
+		return strings.ToLower(value)
+	}
+	return value
+}
+
+// intelIndicators are the indicators of a feed, indexed by indicator type
+type intelIndicators map[string]map[string]bool
+
+func (ii intelIndicators) add(kind string, value string) {
+	value = strings.TrimSpace(value)
+	if !isIntelType(kind) || len(value) == 0 {
+		return
+	}
+	if ii[kind] == nil {
+		ii[kind] = make(map[string]bool)
+	}
+	ii[kind][normalizeIntelValue(kind, value)] = true
+}
+
+func (ii intelIndicators) len() int {
+	var count int
+	for _, values := range ii {
+		count += len(values)
+	}
+	return count
+}
+
+// intelMatcher loads the threat intel feeds, refreshes them periodically and annotates the events whose values are
+// indicators of a feed
+type intelMatcher struct {
+	krie    *KRIE
+	options *IntelOptions
+	client  *http.Client
+
+	lock sync.RWMutex
+	// feeds are the indicators of each feed, a feed keeps its previous indicators when a refresh fails
+	feeds map[string]intelIndicators
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newIntelMatcher(krie *KRIE, options *IntelOptions) *intelMatcher {
+	return &intelMatcher{
+		krie:    krie,
+		options: options,
+		client:  &http.Client{},
+		feeds:   make(map[string]intelIndicators),
+		stop:    make(chan struct{}),
+	}
+}
+
+// start loads the feeds, then refreshes them periodically
+func (im *intelMatcher) start() {
+	im.refresh()
+	if im.options.Interval == 0 {
+		return
+	}
+
+	im.wg.Add(1)
+	go func() {
+		defer im.wg.Done()
+		ticker := time.NewTicker(time.Duration(im.options.Interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-im.stop:
+				return
+			case <-ticker.C:
+				im.refresh()
+			}
+		}
+	}()
+}
+
+func (im *intelMatcher) close() {
+	close(im.stop)
+	im.wg.Wait()
+	im.client.CloseIdleConnections()
+}
+
+// refresh reloads the indicators of all the feeds
+func (im *intelMatcher) refresh() {
+	for _, feed := range im.options.Feeds {
+		indicators, err := im.load(feed)
+		if err != nil {
+			logrus.Warnf("couldn't load intel feed %s: %v", feed.Name, err)
+			continue
+		}
+		im.lock.Lock()
+		im.feeds[feed.Name] = indicators
+		im.lock.Unlock()
+		logrus.Debugf("intel feed %s: %d indicators loaded", feed.Name, indicators.len())
+	}
+}
+
+// load reads and parses a feed
+func (im *intelMatcher) load(feed *IntelFeedOptions) (intelIndicators, error) {
+	data, err := im.read(feed)
+	if err != nil {
+		return nil, err
+	}
+	indicators := make(intelIndicators)
+	switch feed.Format {
+	case IntelMISPFormat:
+		err = parseMISPFeed(data, indicators)
+	case IntelSTIXFormat:
+		err = parseSTIXFeed(data, indicators)
+	default:
+		parsePlainFeed(data, feed.Type, indicators)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return indicators, nil
+}
+
+// read returns the content of a feed
+func (im *intelMatcher) read(feed *IntelFeedOptions) ([]byte, error) {
+	if len(feed.Path) > 0 {
+		return os.ReadFile(feed.Path)
+	}
+
+	url := feed.URL
+	accept := "application/json"
+	if feed.Format == IntelSTIXFormat && strings.Contains(url, "/collections/") {
+		url = strings.TrimSuffix(url, "/") + "/objects/"
+		accept = taxiiContentType
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if feed.Format != IntelPlainFormat {
+		req.Header.Set("Accept", accept)
+	}
+	for key, value := range feed.Headers {
+		req.Header.Set(key, value)
+	}
+	client := *im.client
+	client.Timeout = time.Duration(feed.Timeout) * time.Second
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("unexpected status code from %s: %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// match annotates an event with the values found in the feeds
+func (im *intelMatcher) match(event *events.Event) bool {
+	values := event.IntelValues()
+	if len(values) == 0 {
+		return false
+	}
+
+	im.lock.RLock()
+	defer im.lock.RUnlock()
+	for _, feed := range im.options.Feeds {
+		indicators := im.feeds[feed.Name]
+		for kind, kindValues := range values {
+			for _, value := range kindValues {
+				if !indicators[kind][normalizeIntelValue(kind, value)] {
+					continue
+				}
+				event.Intel = append(event.Intel, events.IntelMatch{
+					Feed:  feed.Name,
+					Type:  kind,
+					Value: value,
+				})
+				im.krie.metrics.add(intelMatchesMetricName, intelMatchesMetricHelp, 1, "feed", feed.Name)
+			}
+		}
+	}
+	return len(event.Intel) > 0
+}
+
+// parsePlainFeed parses a list of values, the empty lines and the lines starting with # are ignored
+func parsePlainFeed(data []byte, kind string, indicators intelIndicators) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		indicators.add(kind, line)
+	}
+}
+
+// mispAttribute is a MISP attribute
+type mispAttribute struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// mispFeed holds the attributes of a MISP event, of a list of MISP events or of a restSearch response
+type mispFeed struct {
+	Attribute []mispAttribute `json:"Attribute"`
+	Event     *struct {
+		Attribute []mispAttribute `json:"Attribute"`
+		Object    []struct {
+			Attribute []mispAttribute `json:"Attribute"`
+		} `json:"Object"`
+	} `json:"Event"`
+	Response json.RawMessage `json:"response"`
+}
+
+// parseMISPFeed parses a MISP feed: the sha256 attributes (and the sha256 of the filename|sha256 attributes) are hash
+// indicators, the attributes of the krie indicator types (module, symbol, bpf_tag) are kept as is
+func parseMISPFeed(data []byte, indicators intelIndicators) error {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var feeds []mispFeed
+		if err := json.Unmarshal(data, &feeds); err != nil {
+			return fmt.Errorf("couldn't parse MISP feed: %w", err)
+		}
+		for _, feed := range feeds {
+			addMISPFeed(&feed, indicators)
+		}
+		return nil
+	}
+
+	var feed mispFeed
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return fmt.Errorf("couldn't parse MISP feed: %w", err)
+	}
+	addMISPFeed(&feed, indicators)
+	if len(feed.Response) > 0 {
+		return parseMISPFeed(feed.Response, indicators)
+	}
+	return nil
+}
+
+func addMISPFeed(feed *mispFeed, indicators intelIndicators) {
+	attributes := feed.Attribute
+	if feed.Event != nil {
+		attributes = append(attributes, feed.Event.Attribute...)
+		for _, object := range feed.Event.Object {
+			attributes = append(attributes, object.Attribute...)
+		}
+	}
+	for _, attribute := range attributes {
+		switch attribute.Type {
+		case "filename|sha256":
+			if i := strings.LastIndex(attribute.Value, "|"); i >= 0 {
+				indicators.add(events.IntelSHA256, attribute.Value[i+1:])
+			}
+		default:
+			indicators.add(attribute.Type, attribute.Value)
+		}
+	}
+}
+
+// stixComparison matches the equality comparisons of a STIX pattern
+var stixComparison = regexp.MustCompile(`([a-z0-9-]+:[^\s=\[\]]+)\s*=\s*'((?:[^'\\]|\\.)*)'`)
+
+// stixIntelTypes maps the object paths of the STIX patterns to the indicator types
+var stixIntelTypes = map[string]string{
+	"file:hashes.'SHA-256'":     events.IntelSHA256,
+	"file:hashes.SHA-256":       events.IntelSHA256,
+	"x-krie-bpf-program:tag":    events.IntelBPFTag,
+	"x-krie-kernel-module:name": events.IntelModule,
+	"x-krie-kernel-symbol:name": events.IntelSymbol,
+}
+
+// parseSTIXFeed parses the patterns of the indicators of a STIX bundle (or of a TAXII envelope): each equality
+// comparison of a supported object path is an indicator
+func parseSTIXFeed(data []byte, indicators intelIndicators) error {
+	var feed struct {
+		Objects []struct {
+			Type        string `json:"type"`
+			Pattern     string `json:"pattern"`
+			PatternType string `json:"pattern_type"`
+			Revoked     bool   `json:"revoked"`
+		} `json:"objects"`
+	}
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return fmt.Errorf("couldn't parse STIX feed: %w", err)
+	}
+	for _, object := range feed.Objects {
+		if object.Type != "indicator" || object.Revoked || (len(object.PatternType) > 0 && object.PatternType != "stix") {
+			continue
+		}
+		for _, comparison := range stixComparison.FindAllStringSubmatch(object.Pattern, -1) {
+			kind, ok := stixIntelTypes[comparison[1]]
+			if !ok {
+				continue
+			}
+			indicators.add(kind, strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(comparison[2]))
+		}
+	}
+	return nil
+}
@@ -64,6 +64,8 @@ var (
 		"system/ia32_sys_call_table",
 		"system/_stext",
 		"system/_etext",
+		"system/modprobe_path",
+		"system/sig_enforce",
 	}
 )
 
@@ -71,7 +73,9 @@ func (e *KRIE) pushKernelSymbols() error {
 	for key, symbol := range krieSymbols {
 		address, ok := e.kernelSymbols[symbol]
 		if !ok {
-			if symbol == "system/x32_sys_call_table" {
+			// modprobe_path is only exported with CONFIG_MODULES and CONFIG_KALLSYMS_ALL, sig_enforce also needs
+			// CONFIG_MODULE_SIG
+			if symbol == "system/x32_sys_call_table" || symbol == "system/modprobe_path" || symbol == "system/sig_enforce" {
 				continue
 			}
 			return fmt.Errorf("couldn't find %s symbol", symbol)
@@ -214,6 +218,9 @@ func (e *KRIE) parseKallsyms() error {
 }
 
 func (e *KRIE) resolveFuncSymbol(k *events.KernelSymbol) error {
+	e.kernelSymbolsLock.Lock()
+	defer e.kernelSymbolsLock.Unlock()
+
 	for symbolAddr, symbol := range e.kernelAddresses {
 		if k.Address >= symbolAddr && k.Address < symbolAddr+events.MemoryPointer(symbol.Size) && symbol.Info == uint8(elf.STT_FUNC) {
 			k.Symbol = symbol.Name
@@ -227,6 +234,9 @@ func (e *KRIE) resolveFuncSymbol(k *events.KernelSymbol) error {
 }
 
 func (e *KRIE) resolveObjectSymbol(k *events.KernelSymbol) error {
+	e.kernelSymbolsLock.Lock()
+	defer e.kernelSymbolsLock.Unlock()
+
 	for symbolAddr, symbol := range e.kernelAddresses {
 		if k.Address == symbolAddr && symbol.Info == uint8(elf.STT_OBJECT) {
 			k.Symbol = symbol.Name
@@ -0,0 +1,61 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// kernelLogTamperWindow is the delay after a module load during which a clear of the kernel log is attributed to the
+// module
+const kernelLogTamperWindow = 5 * time.Minute
+
+// kernelLogTracker remembers the last module loaded, so that the kernel log clears following a module load stand out
+type kernelLogTracker struct {
+	lock       sync.Mutex
+	lastModule string
+	lastLoad   time.Time
+}
+
+func newKernelLogTracker() *kernelLogTracker {
+	return &kernelLogTracker{}
+}
+
+// check records the module loads, and links the kernel_log_tamper events to the module loaded shortly before them
+func (kt *kernelLogTracker) check(event *events.Event) {
+	kt.lock.Lock()
+	defer kt.lock.Unlock()
+
+	switch event.Kernel.Type {
+	case events.InitModuleEventType:
+		if event.Kernel.Retval == 0 {
+			kt.lastModule = event.InitModule.Name
+			kt.lastLoad = event.Kernel.Time
+		}
+	case events.KernelLogTamperEventType:
+		if len(kt.lastModule) == 0 {
+			return
+		}
+		if since := event.Kernel.Time.Sub(kt.lastLoad); since >= 0 && since <= kernelLogTamperWindow {
+			event.KernelLogTamperEvent.AfterModuleLoad = kt.lastModule
+			event.KernelLogTamperEvent.SinceModuleLoad = since.Seconds()
+		}
+	}
+}
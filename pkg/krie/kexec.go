@@ -0,0 +1,47 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// resolveKexecFiles resolves the paths of the kernel and initrd files of a kexec_file_load call from the file
+// descriptors of the process. The event is sent on syscall exit, so this is a best effort: the files can't be resolved
+// once the process closed them.
+func resolveKexecFiles(event *events.Event) {
+	if !event.KexecEvent.IsFileLoad() || event.KexecEvent.Unload {
+		return
+	}
+	event.KexecEvent.KernelPath = resolveProcessFD(event.Process.PID, event.KexecEvent.KernelFD)
+	event.KexecEvent.InitrdPath = resolveProcessFD(event.Process.PID, event.KexecEvent.InitrdFD)
+}
+
+// resolveProcessFD returns the path of the file opened by a process on the provided file descriptor
+func resolveProcessFD(pid uint32, fd int32) string {
+	if fd < 0 {
+		return ""
+	}
+	path, err := os.Readlink(fmt.Sprintf("/proc/%d/fd/%d", pid, fd))
+	if err != nil {
+		return ""
+	}
+	return path
+}
@@ -19,7 +19,7 @@ package krie
 import (
 	"debug/elf"
 	"fmt"
-	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,30 +27,83 @@ import (
 	"github.com/cilium/ebpf"
 	"github.com/sirupsen/logrus"
 
+	"github.com/Gui774ume/krie/pkg/assets"
 	"github.com/Gui774ume/krie/pkg/krie/events"
 )
 
 // KRIE is the main KRIE structure
 type KRIE struct {
-	event        *events.Event
-	handleEvent  func(data []byte) error
 	timeResolver *events.TimeResolver
-	outputFile   *os.File
+	pipeline     *pipeline
+	redactor     *redactor
+	criResolver  *criResolver
+	responders   *responders
+	bursts       *burstRecorder
+	captures     *captureRecorder
+	fixtures     *fixtureRecorder
+	approvals    *approvalVerifier
+	metrics      *metricsRegistry
+	metricsSrv   *metricsServer
+	metricsFile  *metricsTextfile
+	latency      *latencyTracer
+	governor     *governor
+	chaos        *chaos
+	scanners     *scanners
+	apiSrv       *apiServer
+	processTree  *processTree
+	clock        *clockChecker
+	silence      *silenceDetector
+	profiler     *profiler
+	tailCalls    *tailCallVerifier
+	correlation  *correlationMonitor
+	sysctlRevert *sysctlReverter
+	noise        *noiseTracker
+	annotations  *annotationStore
+	symbols      *symbolWatcher
+	status       *statusWriter
+	exits        *processExitTracker
+	bpfLifecycle *bpfLifecycleTracker
+	modules      *moduleLifecycleTracker
+	intel        *intelMatcher
+	resolutions  *symbolResolutionCounter
+	failures     *failureCounter
+	helpers      *usermodeHelperTracker
+	kernelLog    *kernelLogTracker
+	upgrade      *upgrader
+	middlewares  map[EventStage][]Middleware
+	lsmState     *lsmStateWatcher
+	hidden       *hiddenProcessScanner
+	enricher     *enricher
+	compactor    *storageCompactor
+
+	policyLock      *sync.RWMutex
+	policyHash      string
+	policyApprovers []string
+	// monitoredRules are the enforced rules started in monitor mode, because the approval of the policies expired
+	monitoredRules []string
 
 	options        *Options
+	pid            uint32
 	manager        *manager.Manager
 	managerOptions manager.Options
+	variant        *assets.Variant
+	probeOrigins   events.ProbeOrigins
 
 	kernelSymbolsLock  *sync.Mutex
 	kernelSymbols      map[string]*elf.Symbol
 	kernelAddresses    map[events.MemoryPointer]*elf.Symbol
 	kernelKPTRRestrict string
 
+	// moduleUnloadDropped is set when the hardening dropped the privileges required to unload modules
+	moduleUnloadDropped bool
+
 	sysctlParametersMap *ebpf.Map
 	sysctlDefaultMap    *ebpf.Map
 	kallsymsMap         *ebpf.Map
 	policiesMap         *ebpf.Map
 	kernelParametersMap *ebpf.Map
+	eventShardsMap      *ebpf.Map
+	burstPidsMap        *ebpf.Map
 
 	startTime time.Time
 	numCPU    int
@@ -64,16 +117,137 @@ func NewKRIE(options *Options) (*KRIE, error) {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	// the privacy mode applies to the sinks, set it before they are created
+	if hostPrivacy, err = newPrivacy(options.Privacy); err != nil {
+		return nil, err
+	}
+
 	e := &KRIE{
-		event:             events.NewEvent(),
 		options:           options,
-		handleEvent:       options.EventHandler,
+		pid:               uint32(Getpid()),
 		kernelSymbols:     make(map[string]*elf.Symbol),
 		kernelAddresses:   make(map[events.MemoryPointer]*elf.Symbol),
 		kernelSymbolsLock: &sync.Mutex{},
+		policyLock:        &sync.RWMutex{},
+		pipeline:          newPipeline(options.Pipeline),
+		redactor:          newRedactor(options.Redaction),
+		metrics:           newMetricsRegistry(),
+		scanners:          newScanners(),
+		middlewares:       newMiddlewares(options.Middlewares),
+		resolutions:       newSymbolResolutionCounter(),
+		failures:          newFailureCounter(options.Failures),
+		helpers:           newUsermodeHelperTracker(),
+		kernelLog:         newKernelLogTracker(),
+	}
+	e.hidden = newHiddenProcessScanner(e, options.HiddenProcess)
+	if options.Metrics.Latency {
+		e.latency = newLatencyTracer(e.metrics)
+		e.pipeline.latency = e.latency
+	}
+	if options.Chaos.Enabled {
+		e.chaos = newChaos(options.Chaos, e.metrics)
+	}
+	e.pipeline.metrics = e.metrics
+	if options.Governor.isEnabled() {
+		e.governor = newGovernor(options.Governor)
+	}
+	if len(options.Metrics.Listen) > 0 {
+		e.metricsSrv = newMetricsServer(options.Metrics.Listen, e.metrics)
+	}
+	if len(options.Metrics.TextfileDir) > 0 {
+		e.metricsFile = newMetricsTextfile(options.Metrics.TextfileDir, options.Metrics.TextfileInterval, e.metrics)
+	}
+	if len(options.API.Listen) > 0 {
+		if e.apiSrv, err = newAPIServer(e, options.API); err != nil {
+			return nil, err
+		}
+	}
+	e.registerBuiltinScanners()
+	e.clock = newClockChecker(e, options.Clock)
+	if options.Silence.Enabled {
+		e.silence = newSilenceDetector(e, options.Silence)
+	}
+	if options.Profiling.Enabled {
+		if e.profiler, err = newProfiler(e, options.Profiling, options.StateDirectory); err != nil {
+			return nil, err
+		}
+	}
+	if len(options.Status.Path) > 0 {
+		e.status = newStatusWriter(e, options.Status)
+	}
+	if options.ProcessExit.Enabled {
+		e.exits = newProcessExitTracker(e, options.ProcessExit)
+	}
+	if options.BPFLifecycle.Enabled {
+		e.bpfLifecycle = newBPFLifecycleTracker(e, options.BPFLifecycle)
+	}
+	if options.ModuleLifecycle.Enabled {
+		e.modules = newModuleLifecycleTracker(e, options.ModuleLifecycle)
+	}
+	if options.Anomaly.Enabled {
+		// the anomaly detector runs before the middlewares of the rules stage, so that they can read the score
+		detector := newAnomalyDetector(options.Anomaly)
+		e.middlewares = newMiddlewares(append([]Middleware{detector.middleware()}, options.Middlewares...))
+	}
+	if options.Intel.Enabled {
+		e.intel = newIntelMatcher(e, options.Intel)
 	}
-	if e.handleEvent == nil {
-		e.handleEvent = e.defaultEventHandler
+	if activated := options.Events.ActivatedEventTypes(); options.LSMState.Interval > 0 && len(activated) > 0 && activated.Contains(events.LSMStateChangeEventType) {
+		e.lsmState = newLSMStateWatcher(e, options.LSMState)
+	}
+	if len(options.Enrichment.Deferred) > 0 {
+		e.enricher = newEnricher(e, options.Enrichment)
+	}
+	if options.Noise.Enabled {
+		e.noise = newNoiseTracker(options.Noise)
+	}
+	e.policyHash, err = options.Events.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	// the policies of the configuration file must be approved too
+	e.approvals, err = newApprovalVerifier(options.Approval)
+	if err != nil {
+		return nil, err
+	}
+	if e.approvals.isRequired(options.Events.Escalations(nil)) {
+		var tokens []string
+		if len(options.Approval.TokensFile) > 0 {
+			if tokens, err = ReadApprovalTokens(options.Approval.TokensFile); err != nil {
+				return nil, err
+			}
+		}
+		if e.policyApprovers, err = e.approvals.verify(e.policyHash, tokens); err != nil {
+			// the approval of the running policies can expire before a restart: their enforced rules start in monitor
+			// mode until the policies are approved again
+			if _, expiredErr := e.approvals.verifyExpired(e.policyHash, tokens); expiredErr != nil {
+				return nil, fmt.Errorf("policies not approved: %w", err)
+			}
+			if err = e.monitorEnforcedRules(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// take over from the running instance once the configuration is validated, and before loading the state it persists
+	// on stop
+	if len(options.Upgrade.Socket) > 0 {
+		e.upgrade = newUpgrader(e, options.Upgrade)
+		if err = e.upgrade.takeOver(); err != nil {
+			return nil, fmt.Errorf("couldn't take over from the running instance: %w", err)
+		}
+	}
+
+	e.responders = newResponders(e, options.Responders)
+	e.bursts = newBurstRecorder(e, options.Burst)
+	e.captures = newCaptureRecorder(e, options.Capture)
+	if options.CRI.Enabled {
+		e.criResolver = newCRIResolver(options.CRI)
+	}
+
+	if len(options.RecordFixtures) > 0 {
+		e.fixtures = newFixtureRecorder(options.RecordFixtures)
 	}
 
 	e.timeResolver, err = events.NewTimeResolver()
@@ -81,28 +255,222 @@ func NewKRIE(options *Options) (*KRIE, error) {
 		return nil, err
 	}
 
+	if options.ProcessTree.Enabled {
+		e.processTree, err = newProcessTree(options.ProcessTree, options.StateDirectory, e.timeResolver.BootTime())
+		if err != nil {
+			return nil, err
+		}
+		e.processTree.captureArgs = hostPrivacy.captureArgs()
+	}
+
 	e.numCPU, err = NumCPU()
 	if err != nil {
 		return nil, err
 	}
 
 	if len(options.Output) > 0 {
-		e.outputFile, err = os.Create(options.Output)
+		var output eventSink
+		output, err = e.openSink(OutputSinkName, func() (eventSink, error) {
+			return newFileSink(options.Output)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if e.chaos != nil {
+			output = &chaosEventSink{chaos: e.chaos, sink: output}
+		}
+		e.pipeline.addSink(OutputSinkName, output)
+	}
+	for _, formatted := range options.OutputFormats {
+		name := fmt.Sprintf("%s.%s", OutputSinkName, formatted.Format)
+		path := formatted.Output
+		var output eventSink
+		output, err = e.openSink(name, func() (eventSink, error) {
+			return newFileSink(path)
+		})
 		if err != nil {
-			return nil, fmt.Errorf("couldn't create output file: %w", err)
+			return nil, err
+		}
+		if e.chaos != nil {
+			output = &chaosEventSink{chaos: e.chaos, sink: output}
 		}
+		e.pipeline.addFormattedSink(name, output, formatted.Format)
+	}
+
+	if len(options.Webhook.URL) > 0 {
+		var webhook eventSink
+		if webhook, err = newWebhookSink(options.Webhook); err != nil {
+			return nil, fmt.Errorf("couldn't create webhook sink: %w", err)
+		}
+		if e.chaos != nil {
+			// inject the failures under the spool, to exercise the replay of the spooled events
+			webhook = &chaosEventSink{chaos: e.chaos, sink: webhook}
+		}
+		if len(options.Webhook.Spool.Directory) > 0 {
+			webhook, err = newSpooledSink(WebhookSinkName, webhook, options.Webhook.Spool, options.StateDirectory)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't create webhook spool: %w", err)
+			}
+		}
+		e.pipeline.addFormattedSink(WebhookSinkName, webhook, options.Webhook.Format)
+	}
 
-		_ = os.Chmod(options.Output, 0644)
+	if len(options.Companion.Socket) > 0 {
+		var stream eventSink
+		if stream, err = e.deferStart(CompanionSinkName, newCompanionStream(options.Companion)); err != nil {
+			return nil, err
+		}
+		e.pipeline.addSink(CompanionSinkName, stream)
+	}
+
+	for name, sinkOptions := range options.Sinks {
+		registered, err := newRegisteredSink(name, sinkOptions, e.chaos)
+		if err != nil {
+			return nil, err
+		}
+		sink, err := e.deferStart(name, registered)
+		if err != nil {
+			return nil, err
+		}
+		if tenant, ok := options.API.tenantOfSink(name); ok {
+			e.pipeline.addTenantSink(tenant, name, sink, sinkOptions.Format)
+			continue
+		}
+		e.pipeline.addFormattedSink(name, sink, sinkOptions.Format)
+	}
+
+	var storage Storage
+	if len(options.Storage.Type) > 0 {
+		if storage, err = newStorage(options.Storage); err != nil {
+			return nil, err
+		}
+		sink, err := e.deferStart(StorageSinkName, wrapSink(StorageSinkName, options.Storage.sinkOptions(), storage, e.chaos))
+		if err != nil {
+			return nil, err
+		}
+		e.pipeline.addSink(StorageSinkName, sink)
+		if !options.Storage.Retention.policy().IsEmpty() {
+			if e.compactor, err = newStorageCompactor(e, storage, options.Storage.Retention); err != nil {
+				return nil, fmt.Errorf("invalid storage section: %w", err)
+			}
+		}
+	} else if storage, err = openAnnotationStorage(options); err != nil {
+		// without storage, the annotations are read back from the output file
+		return nil, err
+	}
+	if e.annotations, err = newAnnotationStore(options, storage, e.recordAnnotation); err != nil {
+		return nil, err
 	}
 	return e, nil
 }
 
 // Start hooks on the requested symbols and begins tracing
 func (e *KRIE) Start() error {
+	logStaticBuild()
+	if err := e.pipeline.start(); err != nil {
+		return err
+	}
+	if e.metricsSrv != nil {
+		if err := e.metricsSrv.start(); err != nil {
+			return err
+		}
+	}
+	if e.metricsFile != nil {
+		if err := e.metricsFile.start(); err != nil {
+			return err
+		}
+	}
+	e.responders.start()
+	if e.processTree != nil {
+		e.processTree.start()
+	}
+	if e.enricher != nil {
+		e.enricher.start()
+	}
 	if err := e.startManager(); err != nil {
 		return err
 	}
-	return nil
+	if e.governor != nil {
+		e.governor.start()
+	}
+	if e.silence != nil {
+		e.silence.start()
+	}
+	if e.profiler != nil {
+		e.profiler.start()
+	}
+	if e.exits != nil {
+		e.exits.start()
+	}
+	if e.bpfLifecycle != nil {
+		e.bpfLifecycle.start()
+	}
+	if e.modules != nil {
+		e.modules.start()
+	}
+	if e.intel != nil {
+		e.intel.start()
+	}
+	if e.lsmState != nil {
+		e.lsmState.start()
+	}
+	e.hidden.start()
+	if e.compactor != nil {
+		e.compactor.start()
+	}
+	if e.options.TailCalls.Interval > 0 {
+		e.tailCalls = newTailCallVerifier(e, e.options.TailCalls, e.managerOptions.TailCallRouter)
+		e.tailCalls.start()
+	}
+	if e.options.Correlation.Interval > 0 {
+		e.correlation = newCorrelationMonitor(e, e.options.Correlation)
+		e.correlation.start()
+	}
+	e.sysctlRevert = newSysCtlReverter(e)
+	e.sysctlRevert.start(e.options.Events.SysCtlEvent.RevertInterval)
+	if e.options.SymbolWatcher.Interval > 0 {
+		if watcher := newSymbolWatcher(e, e.options.SymbolWatcher); watcher.start(e.manager.Probes) {
+			e.symbols = watcher
+		}
+	}
+	if e.apiSrv != nil {
+		if err := e.apiSrv.start(); err != nil {
+			return err
+		}
+	}
+	if e.status != nil {
+		if err := e.status.start(); err != nil {
+			return err
+		}
+	}
+
+	// record the initial policies
+	event := events.NewEvent()
+	event.Kernel.Type = events.PolicyChangeEventType
+	event.PolicyChangeEvent = events.PolicyChangeEvent{
+		Status:      PolicyStatusLoaded,
+		Version:     e.options.PolicyVersion,
+		Hash:        e.policyHash,
+		Escalations: e.options.Events.Escalations(nil),
+		Approvers:   e.policyApprovers,
+	}
+	if len(e.monitoredRules) > 0 {
+		event.PolicyChangeEvent.Status = PolicyStatusMonitored
+		event.PolicyChangeEvent.Error = fmt.Sprintf("approval expired, rules started in monitor mode: %s", strings.Join(e.monitoredRules, ", "))
+	}
+	if err := e.emitEvent(event); err != nil {
+		logrus.Errorf("couldn't emit policy_change event: %v", err)
+	}
+	e.checkSigEnforce()
+
+	if e.upgrade != nil {
+		if err := e.upgrade.start(); err != nil {
+			return err
+		}
+	}
+
+	// everything that needs the privileges of the startup is attached, opened or listening
+	return e.harden()
 }
 
 // Stop shuts down KRIE
@@ -112,14 +480,83 @@ func (e *KRIE) Stop() error {
 		return nil
 	}
 
+	// the program arrays and the syscall cache are closed with the manager
+	if e.tailCalls != nil {
+		e.tailCalls.close()
+	}
+	if e.correlation != nil {
+		e.correlation.close()
+	}
+
 	if err := e.manager.Stop(manager.CleanAll); err != nil {
 		logrus.Errorf("couldn't stop manager: %v", err)
 	}
+	detachedAt := time.Now()
 
-	if e.outputFile != nil {
-		if err := e.outputFile.Close(); err != nil {
-			logrus.Errorf("couldn't close output file: %v", err)
-		}
+	if e.apiSrv != nil {
+		e.apiSrv.stop()
+	}
+	if e.governor != nil {
+		e.governor.close()
+	}
+	if e.silence != nil {
+		e.silence.close()
+	}
+	if e.profiler != nil {
+		e.profiler.close()
+	}
+	if e.exits != nil {
+		e.exits.close()
+	}
+	if e.bpfLifecycle != nil {
+		e.bpfLifecycle.close()
+	}
+	if e.modules != nil {
+		e.modules.close()
+	}
+	if e.intel != nil {
+		e.intel.close()
+	}
+	if e.lsmState != nil {
+		e.lsmState.close()
+	}
+	e.hidden.close()
+	if e.compactor != nil {
+		e.compactor.close()
+	}
+	if e.sysctlRevert != nil {
+		e.sysctlRevert.close()
+	}
+	if e.symbols != nil {
+		e.symbols.close()
+	}
+	if e.status != nil {
+		e.status.close()
+	}
+
+	// send the events waiting for their deferred enrichments
+	if e.enricher != nil {
+		e.enricher.close()
+	}
+	if e.processTree != nil {
+		e.processTree.close()
+	}
+
+	// wait for the pending responses, then flush the queued events and close the sinks
+	e.responders.stop()
+	e.bursts.close()
+	e.captures.close()
+	e.pipeline.close()
+	if e.metricsSrv != nil {
+		e.metricsSrv.stop()
+	}
+	if e.metricsFile != nil {
+		e.metricsFile.close()
+	}
+
+	// hand off to the new instance once the state is persisted and the sinks are released
+	if e.upgrade != nil {
+		e.upgrade.close(detachedAt)
 	}
 	return nil
 }
@@ -130,111 +567,265 @@ func (e *KRIE) pushFilters() error {
 
 var eventZero events.Event
 
-func (e *KRIE) zeroEvent() *events.Event {
-	*e.event = eventZero
-	return e.event
+// processingState is the state of a kernel event along the processing chain, it isn't visible to the middlewares
+type processingState struct {
+	read          time.Time
+	policies      *events.Options
+	policyVersion string
+	policyHash    string
+	deferred      []Enrichment
+	burstOnly     bool
+	// excluded is set when the event is self-generated and excluded from the policies
+	excluded bool
 }
 
-func (e *KRIE) defaultEventHandler(data []byte) error {
-	event := e.zeroEvent()
-
-	// unmarshall kernel event
-	cursor, err := event.Kernel.UnmarshalBinary(data, e.timeResolver)
-	if err != nil {
+// defaultEventHandler runs the processing chain of a kernel event: decode, enrich, rules, redact and sinks, with the
+// middlewares inserted after each stage
+func (e *KRIE) defaultEventHandler(event *events.Event, data []byte) error {
+	var state processingState
+	if err := e.decodeStage(event, data, &state); err != nil {
 		return err
 	}
+	return e.runMiddlewares(DecodeStage, event, func(event *events.Event) error {
+		e.enrichStage(event, &state)
+		if state.excluded {
+			return nil
+		}
+		return e.runMiddlewares(EnrichStage, event, func(event *events.Event) error {
+			e.rulesStage(event, &state)
+			return e.runMiddlewares(RulesStage, event, func(event *events.Event) error {
+				// the delayed events are sent by the enricher
+				if len(state.deferred) > 0 && e.enricher.submit(event, state.deferred, state.burstOnly) {
+					return nil
+				}
+				return e.finishEvent(event, state.burstOnly)
+			})
+		})
+	})
+}
 
-	// unmarshall process context
-	read, err := event.Process.UnmarshalBinary(data[cursor:])
-	if err != nil {
-		return err
+// decodeStage unmarshalls the kernel payload of an event
+func (e *KRIE) decodeStage(event *events.Event, data []byte, state *processingState) error {
+	state.read = time.Now()
+	*event = eventZero
+	event.ID = events.NewEventID()
+	event.Labels = e.options.Labels
+	state.policies, state.policyVersion, state.policyHash = e.currentPolicies()
+
+	// unmarshall the event
+	_, err := event.UnmarshalBinary(data, e.timeResolver)
+	if err == nil && e.chaos.decodeError() {
+		err = errChaos
 	}
-	cursor += read
-
-	switch event.Kernel.Type {
-	case events.InitModuleEventType:
-		if read, err = event.InitModule.UnmarshallBinary(data[cursor:]); err != nil {
+	if err != nil {
+		e.metrics.add(decodeErrorsMetricName, decodeErrorsMetricHelp, 1, "mode", string(e.options.Decoding))
+		if e.options.Decoding != LenientDecoding {
 			return err
 		}
-		// update symbols table
-		_ = e.loadKernelSymbols()
-	case events.DeleteModuleEventType:
-		if read, err = event.DeleteModule.UnmarshallBinary(data[cursor:]); err != nil {
-			return err
+		toRawEvent(event, data, err)
+	} else {
+		event.Kernel.Probe = e.probeOrigins[event.Kernel.ProbeID]
+		e.recordFixture(event.Kernel.Type, data)
+		e.clock.check(event, state.read)
+		if e.silence != nil {
+			e.silence.record(event.Kernel.Type, state.read)
 		}
+		if e.status != nil {
+			e.status.record(state.read)
+		}
+	}
+	return nil
+}
+
+// enrichStage resolves the context of an event, the expensive enrichments may be deferred to the enricher
+func (e *KRIE) enrichStage(event *events.Event, state *processingState) {
+	policies := state.policies
+
+	// count the failed calls of the process, the repeated failures change the rule of the event
+	e.failures.record(event)
+
+	// resolve the container of the process
+	if e.criResolver != nil && event.HasProcessContext() && len(event.Process.ContainerID()) > 0 {
+		state.deferred = e.enrich(event, ContainerEnrichment, policies, state.deferred)
+	}
+
+	// resolve the executable and the ancestors of the process, even if it already exited
+	if e.processTree != nil && event.HasProcessContext() {
+		e.processTree.enrich(&event.Process, event.Kernel.Time)
+	}
+
+	// the events caused by KRIE itself are dropped, unless they are explicitly included
+	if state.excluded = e.excludeSelfEvent(event); state.excluded {
+		return
+	}
+
+	switch event.Kernel.Type {
+	case events.InitModuleEventType, events.DeleteModuleEventType:
 		// update symbols table
 		_ = e.loadKernelSymbols()
-	case events.BPFEventType:
-		if read, err = event.BPFEvent.UnmarshallBinary(data[cursor:]); err != nil {
-			return err
-		}
-	case events.BPFFilterEventType:
-		if read, err = event.BPFFilterEvent.UnmarshallBinary(data[cursor:]); err != nil {
-			return err
-		}
-	case events.PTraceEventType:
-		if read, err = event.PTraceEvent.UnmarshallBinary(data[cursor:]); err != nil {
-			return err
+		if e.symbols != nil {
+			e.symbols.notify()
 		}
-	case events.KProbeEventType:
-		if read, err = event.KProbeEvent.UnmarshallBinary(data[cursor:]); err != nil {
-			return err
+		if event.Kernel.Type == events.InitModuleEventType {
+			e.kernelLog.check(event)
+			resolveFileless(event)
+			e.checkContainerImage(event, policies)
+			state.deferred = e.enrich(event, ModuleInfoEnrichment, policies, state.deferred)
 		}
 	case events.SysCtlEventType:
-		if read, err = event.SysCtlEvent.UnmarshallBinary(data[cursor:]); err != nil {
-			return err
-		}
 		if event.SysCtlEvent.Action == 2 {
-			if param, ok := e.options.Events.SysCtlEvent.List[event.SysCtlEvent.Name]; ok {
+			if param, ok := policies.SysCtlEvent.List[event.SysCtlEvent.Name]; ok {
 				event.SysCtlEvent.NewValueOverriddenWith = param.OverrideInputValueWith
 			} else {
-				event.SysCtlEvent.NewValueOverriddenWith = e.options.Events.SysCtlEvent.Default.OverrideInputValueWith
+				event.SysCtlEvent.NewValueOverriddenWith = policies.SysCtlEvent.Default.OverrideInputValueWith
 			}
 		}
-	case events.EventCheckEventType:
-		if read, err = event.EventCheckEvent.UnmarshallBinary(data[cursor:]); err != nil {
-			return err
+		if event.SysCtlEvent.WriteAccess && e.sysctlRevert != nil {
+			if _, ok := policies.SysCtlEvent.PinnedParameters()[event.SysCtlEvent.Name]; ok {
+				e.sysctlRevert.notify(event.ID)
+			}
 		}
-	case events.HookedSyscallEventType, events.HookedSyscallTableEventType:
-		if read, err = event.HookedSyscallEvent.UnmarshallBinary(data[cursor:]); err != nil {
-			return err
+	case events.KexecEventType:
+		resolveKexecFiles(event)
+	case events.PerfEventOpenEventType:
+		resolvePerfEventPMU(event)
+	case events.BPFEventType:
+		resolveFileless(event)
+		if e.bpfLifecycle != nil {
+			e.bpfLifecycle.link(event)
 		}
-
-		// fetch symbol owners
-		if err = e.resolveFuncSymbol(&event.HookedSyscallEvent.InitialHandler); err != nil {
-			logrus.Error(err)
+	case events.FTraceHookEventType, events.HookedSyscallEventType, events.HookedSyscallTableEventType,
+		events.KernelParameterEventType, events.PeriodicKernelParameterEventType, events.NetfilterHookEventType:
+		state.deferred = e.enrich(event, SymbolsEnrichment, policies, state.deferred)
+	case events.MSRWriteEventType:
+		if event.MSRWriteEvent.Target != nil {
+			state.deferred = e.enrich(event, SymbolsEnrichment, policies, state.deferred)
 		}
-		if err = e.resolveFuncSymbol(&event.HookedSyscallEvent.NewHandler); err != nil {
-			logrus.Error(err)
+	case events.SymbolResolutionEventType:
+		event.SymbolResolutionEvent.Count = e.resolutions.add(event.Process.PID)
+		if event.SymbolResolutionEvent.Caller != nil {
+			state.deferred = e.enrich(event, SymbolsEnrichment, policies, state.deferred)
 		}
-	case events.KernelParameterEventType, events.PeriodicKernelParameterEventType:
-		if read, err = event.KernelParameterEvent.UnmarshallBinary(data[cursor:]); err != nil {
+	case events.UsermodeHelperEventType:
+		e.helpers.check(event)
+	case events.KernelLogTamperEventType:
+		e.kernelLog.check(event)
+	case events.MountEventType:
+		resolveMountNamespace(event)
+	case events.NamespaceEventType:
+		resolveNamespaceEscape(event)
+	case events.ModuleSignatureEventType:
+		event.ModuleSignatureEvent.ResolveBypass(policies.ModuleSignatureEvent.ExpectSigEnforce)
+	}
+}
+
+// rulesStage adds the policy that produced the event
+func (e *KRIE) rulesStage(event *events.Event, state *processingState) {
+	decoded := time.Now()
+	policies := state.policies
+	event.Policy.Version = state.policyVersion
+	event.Policy.Hash = state.policyHash
+	event.Policy.RuleID = policies.RuleID(event)
+	event.Policy.Mode = policies.RuleMode(event.Policy.RuleID)
+	event.Policy.Confidence = policies.Confidence(event)
+
+	if e.latency != nil {
+		e.latency.observe(LatencyStagePerfRead, state.read.Sub(event.Kernel.Time), "")
+		e.latency.observe(LatencyStageDecode, decoded.Sub(state.read), "")
+		e.latency.observe(LatencyStageRuleEvaluation, time.Since(decoded), "")
+	}
+	if e.options.Metrics.EmbedLatency {
+		event.Latency = time.Since(event.Kernel.Time)
+	}
+
+	// events of a "nop" event type are only sent during a burst capture, and are only recorded in the burst bundle
+	action, ok := policies.ParseEventsActions()[event.Kernel.Type]
+	state.burstOnly = ok && action == events.NopAction
+}
+
+// finishEvent sends a decoded event to the handler of its event type, or through the redact and sinks stages
+func (e *KRIE) finishEvent(event *events.Event, burstOnly bool) error {
+	// the modules of the hooks are only known once the symbols are resolved
+	if e.modules != nil {
+		e.modules.link(event)
+	}
+	// the hashes of the modules and the symbols of the hooks are only known once the enrichments are done
+	if e.intel != nil && e.intel.match(event) {
+		policies, _, _ := e.currentPolicies()
+		event.Policy.Confidence = policies.Confidence(event)
+	}
+
+	// the handler of the event type replaces the sinks, the responders and the burst captures
+	if handler, ok := e.options.EventTypeHandlers[event.Kernel.Type]; ok {
+		return handler(event)
+	}
+
+	// redact the event before it reaches the sinks
+	e.redactor.redact(event)
+	return e.runMiddlewares(RedactStage, event, func(event *events.Event) error {
+		if err := e.dispatchEvent(event, burstOnly); err != nil {
 			return err
 		}
+		return e.runMiddlewares(SinksStage, event, endOfChain)
+	})
+}
+
+// emitEvent sends an event generated in user space to the sinks, its processing chain starts after the rules stage.
+// The ID of the event is generated, unless the generator of the event already set it.
+func (e *KRIE) emitEvent(event *events.Event) error {
+	if len(event.ID) == 0 {
+		event.ID = events.NewEventID()
+	}
+	event.Kernel.Time = time.Now()
+	event.Labels = e.options.Labels
+
+	policies, policyVersion, policyHash := e.currentPolicies()
+	event.Policy.Version = policyVersion
+	event.Policy.Hash = policyHash
+	event.Policy.RuleID = policies.RuleID(event)
+	event.Policy.Mode = policies.RuleMode(event.Policy.RuleID)
+	event.Policy.Confidence = policies.Confidence(event)
+
+	return e.runMiddlewares(RulesStage, event, func(event *events.Event) error {
+		return e.finishEvent(event, false)
+	})
+}
 
-		// fetch symbol
-		if err = e.resolveObjectSymbol(&event.KernelParameterEvent.Parameter); err != nil {
-			logrus.Error(err)
+// dispatchEvent queues the event for the sinks, triggers the matching responders and records the event in the active
+// burst captures. A burst only event is only recorded in the burst captures.
+func (e *KRIE) dispatchEvent(event *events.Event, burstOnly bool) error {
+	var triggered []*responder
+	if !burstOnly {
+		triggered = e.responders.match(event)
+		e.noise.record(event)
+		if e.exits != nil {
+			e.exits.record(event)
 		}
-	case events.RegisterCheckEventType:
-		if read, err = event.RegisterCheckEvent.UnmarshallBinary(data[cursor:]); err != nil {
-			return err
+		if e.lsmState != nil {
+			e.lsmState.record(event)
 		}
-	default:
-		return fmt.Errorf("unknown event type: %s", event.Kernel.Type)
 	}
-	cursor += read
+	toSinks := !burstOnly && e.pipeline.hasSinks()
 
-	// write to output file
-	if e.outputFile != nil {
-		var jsonData []byte
-		jsonData, err = event.MarshalJSON()
+	captured := e.captures.isActive()
+	if toSinks || len(triggered) > 0 || e.bursts.isEnabled() || captured {
+		jsonData, err := event.MarshalJSON()
 		if err != nil {
 			return fmt.Errorf("couldn't marshall event: %w", err)
 		}
 		jsonData = append(jsonData, "\n"...)
-		if _, err = e.outputFile.Write(jsonData); err != nil {
-			return fmt.Errorf("couldn't write event to output: %w", err)
+
+		if toSinks {
+			e.pipeline.push(event, jsonData)
+		}
+		for _, r := range triggered {
+			r.trigger(event.ID, event.Policy.RuleID, jsonData)
+		}
+		if e.bursts.isEnabled() {
+			e.bursts.record(event, jsonData)
+		}
+		if captured {
+			e.captures.record(event, jsonData)
 		}
 	}
 
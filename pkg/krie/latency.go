@@ -0,0 +1,63 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"time"
+)
+
+const (
+	latencyMetricName = "krie_pipeline_latency_seconds"
+	latencyMetricHelp = "Latency of the stages of the event pipeline"
+
+	// LatencyStagePerfRead is the delay between the emission of an event by the kernel and its read from the perf buffer
+	LatencyStagePerfRead = "perf_read"
+	// LatencyStageDecode is the duration of the decoding and the enrichment of an event
+	LatencyStageDecode = "decode"
+	// LatencyStageRuleEvaluation is the duration of the evaluation of the rules of an event
+	LatencyStageRuleEvaluation = "rule_evaluation"
+	// LatencyStageQueue is the time spent by an event in the queue of a sink
+	LatencyStageQueue = "queue"
+	// LatencyStageSinkWrite is the duration of the write of an event to a sink
+	LatencyStageSinkWrite = "sink_write"
+	// LatencyStageEndToEnd is the delay between the emission of an event by the kernel and its write to a sink
+	LatencyStageEndToEnd = "end_to_end"
+)
+
+// latencyTracer records the latency of each stage of the event pipeline in histograms
+type latencyTracer struct {
+	registry *metricsRegistry
+}
+
+func newLatencyTracer(registry *metricsRegistry) *latencyTracer {
+	return &latencyTracer{
+		registry: registry,
+	}
+}
+
+// observe records the latency of a stage, the sink is empty for the stages that precede the queues of the sinks
+func (lt *latencyTracer) observe(stage string, latency time.Duration, sink string) {
+	if latency < 0 {
+		// the kernel timestamps are resolved with the boot time of the host, they can drift by a few microseconds
+		latency = 0
+	}
+	if len(sink) > 0 {
+		lt.registry.observe(latencyMetricName, latencyMetricHelp, latencyBuckets, latency.Seconds(), "stage", stage, "sink", sink)
+	} else {
+		lt.registry.observe(latencyMetricName, latencyMetricHelp, latencyBuckets, latency.Seconds(), "stage", stage)
+	}
+}
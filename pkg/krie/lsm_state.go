@@ -0,0 +1,166 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	manager "github.com/DataDog/ebpf-manager"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// selinuxEnforcePath is the selinuxfs file holding the enforcing mode of SELinux
+const selinuxEnforcePath = "/sys/fs/selinux/enforce"
+
+// LSMStateOptions configures the detection of the tampering of the SELinux state: a rootkit can switch SELinux to
+// permissive mode by patching selinux_state, without writing to /sys/fs/selinux/enforce
+type LSMStateOptions struct {
+	// Interval is the number of seconds between two reads of the enforcing mode, 0 disables the check
+	Interval int64 `yaml:"interval"`
+}
+
+// IsValid returns an error if the LSM state options are invalid
+func (o *LSMStateOptions) IsValid() error {
+	if o.Interval < 0 {
+		return fmt.Errorf("invalid interval: %d", o.Interval)
+	}
+	return nil
+}
+
+// NewLSMStateOptions returns a default set of LSM state options
+func NewLSMStateOptions() *LSMStateOptions {
+	return &LSMStateOptions{
+		Interval: 5,
+	}
+}
+
+// lsmStateWatcher reads the enforcing mode of SELinux periodically, and reports the switches to permissive mode that
+// weren't made through selinuxfs (see the lsm_state_change events of the sel_write_enforce probe)
+type lsmStateWatcher struct {
+	krie    *KRIE
+	options *LSMStateOptions
+
+	// writes is the number of writes to the enforce file seen since the last check
+	writes   uint64
+	enforce  int
+	awaiting bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newLSMStateWatcher(krie *KRIE, options *LSMStateOptions) *lsmStateWatcher {
+	return &lsmStateWatcher{
+		krie:    krie,
+		options: options,
+		stop:    make(chan struct{}),
+	}
+}
+
+func readSELinuxEnforce() (int, error) {
+	data, err := os.ReadFile(selinuxEnforcePath)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// start begins the periodic check, the check requires SELinux and the sel_write_enforce probe: without the probe, each
+// legitimate switch would be reported as a tampering
+func (lw *lsmStateWatcher) start() {
+	probe, ok := lw.krie.manager.GetProbe(manager.ProbeIdentificationPair{UID: events.KRIEUID, EBPFFuncName: "kprobe_sel_write_enforce"})
+	if !ok || !probe.IsRunning() {
+		logrus.Debugf("sel_write_enforce isn't probed, the SELinux state tampering check is disabled")
+		return
+	}
+	enforce, err := readSELinuxEnforce()
+	if err != nil {
+		logrus.Debugf("SELinux isn't available, the SELinux state tampering check is disabled: %v", err)
+		return
+	}
+	lw.enforce = enforce
+
+	lw.wg.Add(1)
+	go func() {
+		defer lw.wg.Done()
+		ticker := time.NewTicker(time.Duration(lw.options.Interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-lw.stop:
+				return
+			case <-ticker.C:
+				lw.check()
+			}
+		}
+	}()
+}
+
+func (lw *lsmStateWatcher) close() {
+	close(lw.stop)
+	lw.wg.Wait()
+}
+
+// record counts the writes of 0 to the enforce file
+func (lw *lsmStateWatcher) record(event *events.Event) {
+	if event.Kernel.Type == events.LSMStateChangeEventType && event.LSMStateChangeEvent.Change == events.SELinuxPermissive {
+		atomic.AddUint64(&lw.writes, 1)
+	}
+}
+
+// check reports a switch to permissive mode without any write to the enforce file. The write may still be in the perf
+// buffer when the switch is seen: the switch is only reported if no write was seen by the next check either.
+func (lw *lsmStateWatcher) check() {
+	enforce, err := readSELinuxEnforce()
+	if err != nil {
+		logrus.Debugf("couldn't read %s: %v", selinuxEnforcePath, err)
+		return
+	}
+	writes := atomic.SwapUint64(&lw.writes, 0)
+
+	if lw.awaiting {
+		lw.awaiting = false
+		if writes == 0 {
+			lw.emit(enforce)
+		}
+	} else if lw.enforce != 0 && enforce == 0 && writes == 0 {
+		lw.awaiting = true
+	}
+	lw.enforce = enforce
+}
+
+func (lw *lsmStateWatcher) emit(enforce int) {
+	logrus.Warnf("SELinux switched to permissive mode without any write to %s", selinuxEnforcePath)
+	event := events.NewEvent()
+	event.Kernel.Type = events.LSMStateChangeEventType
+	event.LSMStateChangeEvent = events.LSMStateChangeEvent{
+		Change: events.SELinuxStateTampering,
+		LSM:    events.SELinuxStateTampering.LSM(),
+		Value:  int32(enforce),
+	}
+	if err := lw.krie.emitEvent(event); err != nil {
+		logrus.Errorf("couldn't send lsm_state_change event: %v", err)
+	}
+}
@@ -17,13 +17,11 @@ limitations under the License.
 package krie
 
 import (
-	"bytes"
 	"fmt"
 	"io"
 	"math"
 	"net/http"
 	"os"
-	"strings"
 	"time"
 
 	manager "github.com/DataDog/ebpf-manager"
@@ -32,7 +30,6 @@ import (
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sys/unix"
 
-	"github.com/Gui774ume/krie/pkg/assets"
 	"github.com/Gui774ume/krie/pkg/kernel"
 	"github.com/Gui774ume/krie/pkg/krie/events"
 )
@@ -47,6 +44,11 @@ func (e *KRIE) startManager() error {
 	// setup a default manager
 	e.prepareManager()
 
+	// identify the programs in the events they send
+	if err = e.loadProbeOrigins(asset); err != nil {
+		return err
+	}
+
 	// load vmlinux
 	if err = e.loadVMLinux(); err != nil {
 		return fmt.Errorf("couldn't load kernel BTF specs, please try to provide one in the configuration: %w", err)
@@ -67,8 +69,16 @@ func (e *KRIE) startManager() error {
 		return err
 	}
 
-	logrus.Infoln("KRIE is now running (Ctrl + C to stop)\n")
+	// register KRIE as the owner of its maps
+	if err = e.loadMapOwners(asset); err != nil {
+		return err
+	}
+
+	logrus.Infoln("KRIE is now running (Ctrl + C to stop)")
 	logrus.Infof("activated events: [%s]", e.options.Events.ActivatedEventTypes())
+	if e.bursts.isEnabled() {
+		logrus.Infof("burst capture events: [%s]", e.options.Burst.EventTypes)
+	}
 
 	// start the manager
 	if err = e.manager.Start(); err != nil {
@@ -79,26 +89,6 @@ func (e *KRIE) startManager() error {
 	return nil
 }
 
-func (e *KRIE) loadAsset(name string) (*bytes.Reader, error) {
-	buf, err := assets.Asset(name)
-	if err != nil {
-		return nil, fmt.Errorf("couldn't find load asset %s: %w", name, err)
-	}
-	return bytes.NewReader(buf), nil
-}
-
-func (e *KRIE) fetchAssets() (*bytes.Reader, error) {
-	openSyscall, err := manager.GetSyscallFnName("open")
-	if err != nil {
-		return nil, fmt.Errorf("couldn't determine which asset to use: %w", err)
-	}
-
-	if !strings.HasPrefix(openSyscall, "SyS_") && !strings.HasPrefix(openSyscall, "sys_") {
-		return e.loadAsset("/probe_syscall_wrapper.o")
-	}
-	return e.loadAsset("/probe.o")
-}
-
 func (e *KRIE) prepareManager() {
 	e.managerOptions = manager.Options{
 		// DefaultKProbeMaxActive is the maximum number of active kretprobe at a given time
@@ -124,7 +114,7 @@ func (e *KRIE) prepareManager() {
 			Max: math.MaxUint64,
 		},
 
-		TailCallRouter: events.AllTailCallRoutes(e.options.Events.ActivatedEventTypes()),
+		TailCallRouter: events.AllTailCallRoutes(e.probedEventTypes()),
 
 		ConstantEditors: []manager.ConstantEditor{
 			{
@@ -155,28 +145,43 @@ func (e *KRIE) prepareManager() {
 				Name:  "kernel_parameter_count",
 				Value: uint64(len(e.options.Events.KernelParameterEvent.List)),
 			},
+			{
+				Name:  "control_register_cr4_mask",
+				Value: events.GetControlRegisterCR4Mask(),
+			},
+			{
+				Name:  "krie_capture_args",
+				Value: hostPrivacy.captureArgsConstant(),
+			},
+			{
+				Name:  "krie_self_events",
+				Value: e.options.selfEventsConstant(),
+			},
+			{
+				Name:  "syscall_correlation_timeout",
+				Value: e.options.Correlation.timeoutConstant(),
+			},
 		},
-		ActivatedProbes:   events.AllProbesSelectors(e.options.Events.ActivatedEventTypes()),
+		ActivatedProbes:   events.AllProbesSelectors(e.probedEventTypes()),
 		ExcludedFunctions: events.AllExcludedFunctions(),
 	}
 	e.manager = &manager.Manager{
-		Probes: events.AllProbes(e.options.Events.ActivatedEventTypes()),
-		PerfMaps: []*manager.PerfMap{
-			{
-				Map: manager.Map{Name: "events"},
-				PerfMapOptions: manager.PerfMapOptions{
-					PerfRingBufferSize: 8192 * os.Getpagesize(),
-					DataHandler: func(CPU int, data []byte, perfMap *manager.PerfMap, manager *manager.Manager) {
-						if err := e.handleEvent(data); err != nil {
-							logrus.Errorf("couldn't handle event: %v", err)
-						}
-					},
-				},
-			},
-		},
+		Probes:   events.AllProbes(e.probedEventTypes()),
+		PerfMaps: e.shardPerfMaps(),
 	}
 }
 
+// loadProbeOrigins assigns an identifier to each program of the asset, the programs write it in the events they send
+func (e *KRIE) loadProbeOrigins(asset io.ReaderAt) error {
+	spec, err := ebpf.LoadCollectionSpecFromReader(asset)
+	if err != nil {
+		return fmt.Errorf("couldn't load the programs of the asset: %w", err)
+	}
+	e.probeOrigins = events.NewProbeOrigins(spec)
+	e.managerOptions.ConstantEditors = append(e.managerOptions.ConstantEditors, e.probeOrigins.ConstantEditors()...)
+	return nil
+}
+
 func (e *KRIE) loadVMLinux() error {
 	var btfSpec *btf.Spec
 	var err error
@@ -284,6 +289,14 @@ func (e *KRIE) selectMaps() error {
 	if err != nil {
 		return fmt.Errorf("couldn't find maps/kernel_parameters: %w", err)
 	}
+	e.eventShardsMap, _, err = e.manager.GetMap("event_shards")
+	if err != nil {
+		return fmt.Errorf("couldn't find maps/event_shards: %w", err)
+	}
+	e.burstPidsMap, _, err = e.manager.GetMap("burst_pids")
+	if err != nil {
+		return fmt.Errorf("couldn't find maps/burst_pids: %w", err)
+	}
 	return nil
 }
 
@@ -303,6 +316,23 @@ func (e *KRIE) loadFilters() error {
 		return err
 	}
 
+	// load event shards
+	if err := e.loadEventShards(); err != nil {
+		return err
+	}
+
 	// load sysctl parameters
 	return e.loadSysCtlParameters()
 }
+
+// probedEventTypes returns the event types whose probes are loaded: the activated event types and the event types
+// enabled during a burst capture
+func (e *KRIE) probedEventTypes() events.EventTypeList {
+	out := append(events.EventTypeList{}, e.options.Events.ActivatedEventTypes()...)
+	if e.bursts.isEnabled() {
+		for _, eventType := range e.options.Burst.EventTypes {
+			out.Insert(eventType)
+		}
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cilium/ebpf"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// bpfMapOwner is the owner of a map, as defined in kernel space
+type bpfMapOwner struct {
+	PID     uint32
+	Padding uint32
+	Comm    [events.TaskCommLength]byte
+}
+
+// bpfMap is the metadata of a map, as defined in kernel space
+type bpfMap struct {
+	ID   uint32
+	Type uint32
+	Name [events.BPFObjectNameLen]byte
+}
+
+// loadMapOwners registers KRIE as the owner of its maps: they are created before the probes are attached, the writes
+// of the other processes to these maps are then reported as bpf_map_tamper events
+func (e *KRIE) loadMapOwners(asset io.ReaderAt) error {
+	spec, err := ebpf.LoadCollectionSpecFromReader(asset)
+	if err != nil {
+		return fmt.Errorf("couldn't load the collection spec: %w", err)
+	}
+
+	ownersMap, _, err := e.manager.GetMap("bpf_map_owners")
+	if err != nil || ownersMap == nil {
+		return fmt.Errorf("couldn't find maps/bpf_map_owners: %w", err)
+	}
+	mapsMap, _, err := e.manager.GetMap("bpf_maps")
+	if err != nil || mapsMap == nil {
+		return fmt.Errorf("couldn't find maps/bpf_maps: %w", err)
+	}
+
+	owner := bpfMapOwner{PID: uint32(Getpid())}
+	copy(owner.Comm[:], "krie")
+
+	for name := range spec.Maps {
+		m, found, err := e.manager.GetMap(name)
+		if err != nil || !found {
+			// sections such as .rodata aren't exposed by the manager
+			continue
+		}
+		info, err := m.Info()
+		if err != nil {
+			logrus.Debugf("couldn't get the info of maps/%s: %v", name, err)
+			continue
+		}
+		id, ok := info.ID()
+		if !ok {
+			// the kernel doesn't expose the map IDs
+			logrus.Debugf("couldn't get the ID of maps/%s, the writes to the maps of KRIE won't be reported", name)
+			return nil
+		}
+
+		metadata := bpfMap{ID: uint32(id), Type: uint32(info.Type)}
+		copy(metadata.Name[:], info.Name)
+		if err = mapsMap.Put(uint32(id), metadata); err != nil {
+			return fmt.Errorf("couldn't push the metadata of maps/%s: %w", name, err)
+		}
+		if err = ownersMap.Put(uint32(id), owner); err != nil {
+			return fmt.Errorf("couldn't push the owner of maps/%s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// MetricsOptions configures the metrics of KRIE
+type MetricsOptions struct {
+	Listen           string `yaml:"listen"`
+	TextfileDir      string `yaml:"textfile_dir"`
+	TextfileInterval int64  `yaml:"textfile_interval"`
+	Latency          bool   `yaml:"latency"`
+	EmbedLatency     bool   `yaml:"embed_latency"`
+}
+
+// IsValid returns an error if the metrics options are invalid
+func (o *MetricsOptions) IsValid() error {
+	if len(o.Listen) > 0 {
+		if _, _, err := net.SplitHostPort(o.Listen); err != nil {
+			return fmt.Errorf("invalid listen address: %w", err)
+		}
+	}
+	if len(o.TextfileDir) > 0 && o.TextfileInterval <= 0 {
+		return fmt.Errorf("textfile_interval must be positive when textfile_dir is set")
+	}
+	return nil
+}
+
+// NewMetricsOptions returns a default set of metrics options
+func NewMetricsOptions() *MetricsOptions {
+	return &MetricsOptions{
+		TextfileInterval: 15,
+	}
+}
+
+// latencyBuckets are the upper bounds of the buckets of the latency histograms, in seconds
+var latencyBuckets = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// histogram is a cumulative histogram in the Prometheus format
+type histogram struct {
+	lock    sync.Mutex
+	bounds  []float64
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{
+		bounds:  bounds,
+		buckets: make([]uint64, len(bounds)),
+	}
+}
+
+func (h *histogram) observe(value float64) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for i, bound := range h.bounds {
+		if value <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+func (h *histogram) writeTo(w io.Writer, name string, labels string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	sep := ""
+	if len(labels) > 0 {
+		sep = ","
+	}
+	for i, bound := range h.bounds {
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"%g\"} %d\n", name, labels, sep, bound, h.buckets[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", name, labels, sep, h.count)
+	fmt.Fprintf(w, "%s_sum%s %g\n", name, braces(labels), h.sum)
+	fmt.Fprintf(w, "%s_count%s %d\n", name, braces(labels), h.count)
+}
+
+func braces(labels string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+// metricFamily is a set of series sharing the same name, indexed by their formatted labels
+type metricFamily struct {
+	name       string
+	help       string
+	kind       string
+	buckets    []float64
+	histograms map[string]*histogram
+	counters   map[string]*uint64
+}
+
+// metricsRegistry holds the metrics of KRIE, and writes them in the Prometheus text format
+type metricsRegistry struct {
+	lock     sync.RWMutex
+	families map[string]*metricFamily
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{
+		families: make(map[string]*metricFamily),
+	}
+}
+
+// formatLabels formats label pairs ("stage", "decode", ...) in the Prometheus format
+func formatLabels(pairs ...string) string {
+	var labels []string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		labels = append(labels, fmt.Sprintf("%s=%q", pairs[i], pairs[i+1]))
+	}
+	return strings.Join(labels, ",")
+}
+
+func (r *metricsRegistry) family(name string, help string, kind string, buckets []float64) *metricFamily {
+	r.lock.RLock()
+	family, ok := r.families[name]
+	r.lock.RUnlock()
+	if ok {
+		return family
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if family, ok = r.families[name]; !ok {
+		family = &metricFamily{
+			name:       name,
+			help:       help,
+			kind:       kind,
+			buckets:    buckets,
+			histograms: make(map[string]*histogram),
+			counters:   make(map[string]*uint64),
+		}
+		r.families[name] = family
+	}
+	return family
+}
+
+// observe adds a value to a histogram
+func (r *metricsRegistry) observe(name string, help string, buckets []float64, value float64, labels ...string) {
+	family := r.family(name, help, "histogram", buckets)
+	key := formatLabels(labels...)
+
+	r.lock.RLock()
+	h, ok := family.histograms[key]
+	r.lock.RUnlock()
+	if !ok {
+		r.lock.Lock()
+		if h, ok = family.histograms[key]; !ok {
+			h = newHistogram(family.buckets)
+			family.histograms[key] = h
+		}
+		r.lock.Unlock()
+	}
+	h.observe(value)
+}
+
+// add increments a counter
+func (r *metricsRegistry) add(name string, help string, delta uint64, labels ...string) {
+	family := r.family(name, help, "counter", nil)
+	key := formatLabels(labels...)
+
+	r.lock.RLock()
+	c, ok := family.counters[key]
+	r.lock.RUnlock()
+	if !ok {
+		r.lock.Lock()
+		if c, ok = family.counters[key]; !ok {
+			c = new(uint64)
+			family.counters[key] = c
+		}
+		r.lock.Unlock()
+	}
+	atomic.AddUint64(c, delta)
+}
+
+// writeTo writes the metrics in the Prometheus text format
+func (r *metricsRegistry) writeTo(w io.Writer) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		family := r.families[name]
+		fmt.Fprintf(w, "# HELP %s %s\n", family.name, family.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", family.name, family.kind)
+		for _, labels := range sortedKeys(family.histograms) {
+			family.histograms[labels].writeTo(w, family.name, labels)
+		}
+		for _, labels := range sortedKeys(family.counters) {
+			fmt.Fprintf(w, "%s%s %d\n", family.name, braces(labels), atomic.LoadUint64(family.counters[labels]))
+		}
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// metricsTextfileName is the name of the file written in the textfile collector directory, node_exporter only
+// collects the files with the .prom extension
+const metricsTextfileName = "krie.prom"
+
+// metricsTextfile periodically writes the metrics to a node_exporter textfile collector directory, for the hosts where
+// the agent isn't allowed to listen on a port
+type metricsTextfile struct {
+	path     string
+	interval time.Duration
+	registry *metricsRegistry
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func newMetricsTextfile(dir string, interval int64, registry *metricsRegistry) *metricsTextfile {
+	return &metricsTextfile{
+		path:     filepath.Join(dir, metricsTextfileName),
+		interval: time.Duration(interval) * time.Second,
+		registry: registry,
+		stop:     make(chan struct{}),
+	}
+}
+
+func (mt *metricsTextfile) start() error {
+	// fail early on a missing or read-only directory
+	if err := mt.write(); err != nil {
+		return err
+	}
+	mt.wg.Add(1)
+	go func() {
+		defer mt.wg.Done()
+		ticker := time.NewTicker(mt.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-mt.stop:
+				return
+			case <-ticker.C:
+				if err := mt.write(); err != nil {
+					logrus.Errorf("%v", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// close writes the metrics one last time, so that the file reflects the final state of the counters
+func (mt *metricsTextfile) close() {
+	close(mt.stop)
+	mt.wg.Wait()
+	if err := mt.write(); err != nil {
+		logrus.Errorf("%v", err)
+	}
+}
+
+// write atomically replaces the metrics file: the collector never reads a partially written file
+func (mt *metricsTextfile) write() error {
+	var buf bytes.Buffer
+	mt.registry.writeTo(&buf)
+
+	// the temporary file must not have the .prom extension, or the collector could pick it up
+	tmp := mt.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("couldn't write metrics textfile: %w", err)
+	}
+	if err := os.Rename(tmp, mt.path); err != nil {
+		return fmt.Errorf("couldn't write metrics textfile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// EventStage is a stage of the processing chain of the events, see Options.Middlewares
+type EventStage string
+
+const (
+	// DecodeStage unmarshalls the kernel payload of the event
+	DecodeStage EventStage = "decode"
+	// EnrichStage resolves the container, the process ancestors and the kernel symbols of the event
+	EnrichStage EventStage = "enrich"
+	// RulesStage adds the rule, the mode and the confidence of the policy that produced the event
+	RulesStage EventStage = "rules"
+	// RedactStage applies the redaction rules
+	RedactStage EventStage = "redact"
+	// SinksStage queues the event for the sinks, triggers the responders and records the event in the burst captures
+	SinksStage EventStage = "sinks"
+)
+
+// eventStages are the stages of the processing chain, in order
+var eventStages = []EventStage{DecodeStage, EnrichStage, RulesStage, RedactStage, SinksStage}
+
+// EventProcessor processes an event, it is the rest of the processing chain passed to a middleware
+type EventProcessor func(event *events.Event) error
+
+// EventMiddleware is a step of the processing chain of the events: it is called with the event and the rest of the
+// chain, it continues the processing by calling next, and drops the event by returning without calling next
+type EventMiddleware func(event *events.Event, next EventProcessor) error
+
+// Middleware is inserted in the processing chain of the events, right after a stage. The middlewares of a stage run in
+// the order of Options.Middlewares.
+type Middleware struct {
+	Name    string
+	After   EventStage
+	Handler EventMiddleware
+}
+
+// IsValid returns an error if the middleware is invalid
+func (m Middleware) IsValid() error {
+	if len(m.Name) == 0 {
+		return fmt.Errorf("empty name")
+	}
+	if m.Handler == nil {
+		return fmt.Errorf("nil handler for %s", m.Name)
+	}
+	for _, stage := range eventStages {
+		if m.After == stage {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown stage for %s: %s", m.Name, m.After)
+}
+
+// endOfChain ends the processing chain of an event
+func endOfChain(_ *events.Event) error {
+	return nil
+}
+
+// newMiddlewares indexes the middlewares by stage
+func newMiddlewares(middlewares []Middleware) map[EventStage][]Middleware {
+	byStage := make(map[EventStage][]Middleware)
+	for _, middleware := range middlewares {
+		byStage[middleware.After] = append(byStage[middleware.After], middleware)
+	}
+	return byStage
+}
+
+// runMiddlewares runs the middlewares inserted after a stage, then the rest of the chain
+func (e *KRIE) runMiddlewares(stage EventStage, event *events.Event, next EventProcessor) error {
+	middlewares := e.middlewares[stage]
+	if len(middlewares) == 0 {
+		return next(event)
+	}
+
+	var chain func(i int) EventProcessor
+	chain = func(i int) EventProcessor {
+		if i == len(middlewares) {
+			return next
+		}
+		return func(event *events.Event) error {
+			return middlewares[i].Handler(event, chain(i+1))
+		}
+	}
+	return chain(0)(event)
+}
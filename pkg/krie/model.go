@@ -18,9 +18,12 @@ package krie
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
+	"kernel.org/pub/linux/libs/security/libcap/cap"
 
 	"github.com/Gui774ume/krie/pkg/krie/events"
 )
@@ -31,23 +34,356 @@ type Options struct {
 	Output   string   `yaml:"output"`
 	VMLinux  string   `yaml:"vmlinux"`
 
+	// OutputFormats are additional output files, each one receives the events in its own format
+	OutputFormats []*FormattedOutputOptions `yaml:"output_formats"`
+
+	// StateDirectory is where KRIE persists its state across restarts (for example the delivery checkpoints of the
+	// sinks), leave empty to disable persistence
+	StateDirectory string `yaml:"state_directory"`
+
+	// Labels are static labels attached to every event
+	Labels map[string]string `yaml:"labels"`
+
+	// PolicyVersion is the version of the policies, it is added to every event along with a hash of the policies
+	PolicyVersion string `yaml:"policy_version"`
+
+	// Decoding defines what KRIE does with the events that can't be decoded
+	Decoding DecodingMode `yaml:"decoding"`
+
+	// RecordFixtures is the directory where the raw kernel payload of the first event of each type is recorded, to
+	// generate the fixtures of the golden file tests of the serializers
+	RecordFixtures string `yaml:"record_fixtures"`
+
+	// ModInfo parses the file of the loaded modules (modinfo, signer) and adds it to the init_module events
+	ModInfo bool `yaml:"modinfo"`
+
+	// SelfEvents reports the events of KRIE and of the processes it starts (the commands of the responders, the
+	// helpers) and runs them through the policies. The self-generated events are dropped by default, to avoid alert
+	// loops. KRIE never blocks nor kills itself, whatever the policies.
+	SelfEvents bool `yaml:"self_events"`
+
+	// ConfigFile is the configuration file the policies are reloaded from, set by the caller
+	ConfigFile string `yaml:"-"`
+
+	// EventHandler is called concurrently, from one goroutine per active shard
 	EventHandler func(data []byte) error `yaml:"-"`
 
-	Events *events.Options `yaml:"events"`
+	// EventTypeHandlers replace the default processing of the events of their event type: the events are decoded and
+	// enriched, then passed to the handler instead of the sinks. The event is only valid during the call, and handlers
+	// are called concurrently, from one goroutine per active shard. EventTypeHandlers are ignored if EventHandler is set.
+	EventTypeHandlers map[events.EventType]EventTypeHandler `yaml:"-"`
+
+	// Middlewares are inserted in the processing chain of the events (decode, enrich, rules, redact, sinks), right
+	// after their stage. The events generated in user space start after the rules stage, the enrichments deferred to
+	// the enrichment workers are applied after the rules stage, and the events of an event type handler leave the
+	// chain after the rules stage. Middlewares are called concurrently, from one goroutine per active shard, and are
+	// ignored if EventHandler is set.
+	Middlewares []Middleware `yaml:"-"`
+
+	Pipeline        *PipelineOptions        `yaml:"pipeline"`
+	Sharding        *ShardingOptions        `yaml:"sharding"`
+	Redaction       *RedactionOptions       `yaml:"redaction"`
+	Webhook         *WebhookOptions         `yaml:"webhook"`
+	CRI             *CRIOptions             `yaml:"cri"`
+	Approval        *ApprovalOptions        `yaml:"approval"`
+	Burst           *BurstOptions           `yaml:"burst"`
+	Capture         *CaptureOptions         `yaml:"capture"`
+	Metrics         *MetricsOptions         `yaml:"metrics"`
+	Governor        *GovernorOptions        `yaml:"governor"`
+	Chaos           *ChaosOptions           `yaml:"chaos"`
+	API             *APIOptions             `yaml:"api"`
+	ProcessTree     *ProcessTreeOptions     `yaml:"process_tree"`
+	Clock           *ClockOptions           `yaml:"clock"`
+	Silence         *SilenceOptions         `yaml:"silence"`
+	TailCalls       *TailCallsOptions       `yaml:"tail_calls"`
+	Correlation     *CorrelationOptions     `yaml:"correlation"`
+	Noise           *NoiseOptions           `yaml:"noise"`
+	SymbolWatcher   *SymbolWatcherOptions   `yaml:"symbol_watcher"`
+	Status          *StatusOptions          `yaml:"status"`
+	ProcessExit     *ProcessExitOptions     `yaml:"process_exit"`
+	Companion       *CompanionOptions       `yaml:"companion"`
+	Boot            *BootOptions            `yaml:"boot"`
+	LSMState        *LSMStateOptions        `yaml:"lsm_state"`
+	HiddenProcess   *HiddenProcessOptions   `yaml:"hidden_process"`
+	Enrichment      *EnrichmentOptions      `yaml:"enrichment"`
+	BPFLifecycle    *BPFLifecycleOptions    `yaml:"bpf_lifecycle"`
+	ModuleLifecycle *ModuleLifecycleOptions `yaml:"module_lifecycle"`
+	Anomaly         *AnomalyOptions         `yaml:"anomaly"`
+	Intel           *IntelOptions           `yaml:"intel"`
+	Failures        *FailureOptions         `yaml:"failures"`
+	Privacy         *PrivacyOptions         `yaml:"privacy"`
+	Profiling       *ProfilingOptions       `yaml:"profiling"`
+	Upgrade         *UpgradeOptions         `yaml:"upgrade"`
+	Hardening       *HardeningOptions       `yaml:"hardening"`
+	Events          *events.Options         `yaml:"events"`
+
+	// Sinks are the outputs of the sink types registered with RegisterSink, indexed by sink name
+	Sinks map[string]*SinkOptions `yaml:"sinks"`
+
+	// Storage is the local store of the events, queried by the query and noise commands
+	Storage *StorageOptions `yaml:"storage"`
+
+	// Responders are local commands triggered by the rules of the events
+	Responders []*ResponderOptions `yaml:"responders"`
 }
 
 func (o Options) IsValid() error {
+	if err := checkBuildProfile(&o); err != nil {
+		return err
+	}
+	for key := range o.Labels {
+		if len(key) == 0 {
+			return fmt.Errorf("invalid labels section: empty label name")
+		}
+	}
+	for eventType, handler := range o.EventTypeHandlers {
+		if eventType == events.UnknownEventType || eventType >= events.MaxEventType {
+			return fmt.Errorf("invalid event type handler: unknown event type %s", eventType)
+		}
+		if handler == nil {
+			return fmt.Errorf("invalid event type handler: nil handler for %s", eventType)
+		}
+	}
+	middlewares := make(map[string]bool)
+	for _, middleware := range o.Middlewares {
+		if err := middleware.IsValid(); err != nil {
+			return fmt.Errorf("invalid middleware: %w", err)
+		}
+		if middlewares[middleware.Name] {
+			return fmt.Errorf("invalid middleware: duplicate middleware %s", middleware.Name)
+		}
+		middlewares[middleware.Name] = true
+	}
+	if err := o.Pipeline.IsValid(); err != nil {
+		return fmt.Errorf("invalid pipeline section: %w", err)
+	}
+	if err := o.Sharding.IsValid(); err != nil {
+		return fmt.Errorf("invalid sharding section: %w", err)
+	}
+	if err := o.Redaction.IsValid(); err != nil {
+		return fmt.Errorf("invalid redaction section: %w", err)
+	}
+	if err := o.Webhook.IsValid(); err != nil {
+		return fmt.Errorf("invalid webhook section: %w", err)
+	}
+	if err := o.CRI.IsValid(); err != nil {
+		return fmt.Errorf("invalid cri section: %w", err)
+	}
+	if err := o.Approval.IsValid(); err != nil {
+		return fmt.Errorf("invalid approval section: %w", err)
+	}
+	if err := o.Burst.IsValid(); err != nil {
+		return fmt.Errorf("invalid burst section: %w", err)
+	}
+	if err := o.Capture.IsValid(); err != nil {
+		return fmt.Errorf("invalid capture section: %w", err)
+	}
+	if err := o.Metrics.IsValid(); err != nil {
+		return fmt.Errorf("invalid metrics section: %w", err)
+	}
+	if err := o.Governor.IsValid(); err != nil {
+		return fmt.Errorf("invalid governor section: %w", err)
+	}
+	if err := o.Chaos.IsValid(); err != nil {
+		return fmt.Errorf("invalid chaos section: %w", err)
+	}
+	if err := o.API.IsValid(); err != nil {
+		return fmt.Errorf("invalid api section: %w", err)
+	}
+	if err := o.ProcessTree.IsValid(); err != nil {
+		return fmt.Errorf("invalid process_tree section: %w", err)
+	}
+	if err := o.Clock.IsValid(); err != nil {
+		return fmt.Errorf("invalid clock section: %w", err)
+	}
+	if err := o.Silence.IsValid(); err != nil {
+		return fmt.Errorf("invalid silence section: %w", err)
+	}
+	if err := o.TailCalls.IsValid(); err != nil {
+		return fmt.Errorf("invalid tail_calls section: %w", err)
+	}
+	if err := o.Correlation.IsValid(); err != nil {
+		return fmt.Errorf("invalid correlation section: %w", err)
+	}
+	if err := o.Noise.IsValid(); err != nil {
+		return fmt.Errorf("invalid noise section: %w", err)
+	}
+	if err := o.SymbolWatcher.IsValid(); err != nil {
+		return fmt.Errorf("invalid symbol_watcher section: %w", err)
+	}
+	if err := o.Status.IsValid(); err != nil {
+		return fmt.Errorf("invalid status section: %w", err)
+	}
+	if err := o.ProcessExit.IsValid(); err != nil {
+		return fmt.Errorf("invalid process_exit section: %w", err)
+	}
+	if err := o.Companion.IsValid(); err != nil {
+		return fmt.Errorf("invalid companion section: %w", err)
+	}
+	if err := o.Boot.IsValid(); err != nil {
+		return fmt.Errorf("invalid boot section: %w", err)
+	}
+	if err := o.LSMState.IsValid(); err != nil {
+		return fmt.Errorf("invalid lsm_state section: %w", err)
+	}
+	if err := o.HiddenProcess.IsValid(); err != nil {
+		return fmt.Errorf("invalid hidden_process section: %w", err)
+	}
+	if err := o.Enrichment.IsValid(); err != nil {
+		return fmt.Errorf("invalid enrichment section: %w", err)
+	}
+	if err := o.BPFLifecycle.IsValid(); err != nil {
+		return fmt.Errorf("invalid bpf_lifecycle section: %w", err)
+	}
+	if err := o.ModuleLifecycle.IsValid(); err != nil {
+		return fmt.Errorf("invalid module_lifecycle section: %w", err)
+	}
+	if err := o.Anomaly.IsValid(); err != nil {
+		return fmt.Errorf("invalid anomaly section: %w", err)
+	}
+	if err := o.Intel.IsValid(); err != nil {
+		return fmt.Errorf("invalid intel section: %w", err)
+	}
+	if err := o.Failures.IsValid(); err != nil {
+		return fmt.Errorf("invalid failures section: %w", err)
+	}
+	if err := o.Privacy.IsValid(); err != nil {
+		return fmt.Errorf("invalid privacy section: %w", err)
+	}
+	if err := o.Profiling.IsValid(); err != nil {
+		return fmt.Errorf("invalid profiling section: %w", err)
+	}
+	if err := o.Upgrade.IsValid(); err != nil {
+		return fmt.Errorf("invalid upgrade section: %w", err)
+	}
+	if err := o.Hardening.IsValid(); err != nil {
+		return fmt.Errorf("invalid hardening section: %w", err)
+	}
 	if err := o.Events.IsValid(); err != nil {
 		return fmt.Errorf("invalid events section: %w", err)
 	}
+	if o.Hardening.Enabled && len(o.Hardening.Capabilities) > 0 && unloadsModules(o.Events) && !o.Hardening.keeps(cap.SYS_MODULE) {
+		return fmt.Errorf("invalid hardening section: the block action of the module signer and container image policies requires sys_module in capabilities")
+	}
+	if o.Events.ContainerImages.Action != events.NopAction && !o.CRI.Enabled {
+		return fmt.Errorf("invalid events section: the container image policy requires the container enrichment (cri section)")
+	}
+	formats := make(map[OutputFormat]bool)
+	for _, output := range o.OutputFormats {
+		if err := output.IsValid(); err != nil {
+			return fmt.Errorf("invalid output_formats section: %w", err)
+		}
+		if formats[output.Format] {
+			return fmt.Errorf("invalid output_formats section: %s is configured twice", output.Format)
+		}
+		formats[output.Format] = true
+	}
+	for name, sink := range o.Sinks {
+		if name == OutputSinkName || name == WebhookSinkName || name == StorageSinkName || strings.HasPrefix(name, OutputSinkName+".") {
+			return fmt.Errorf("invalid sinks section: %s is a reserved sink name", name)
+		}
+		if sink == nil {
+			return fmt.Errorf("invalid sinks section: %s: empty sink", name)
+		}
+		if err := sink.IsValid(); err != nil {
+			return fmt.Errorf("invalid sinks section: %s: %w", name, err)
+		}
+	}
+	tenantSinks := make(map[string]string)
+	for name, tenant := range o.API.Tenants {
+		for _, sink := range tenant.Sinks {
+			sinkOptions, ok := o.Sinks[sink]
+			if !ok {
+				return fmt.Errorf("invalid api section: tenant %s: unknown sink %s", name, sink)
+			}
+			// the ingested events are forwarded as they were received
+			if sinkOptions.Format != JSONFormat {
+				return fmt.Errorf("invalid api section: tenant %s: sink %s must use the %s format", name, sink, JSONFormat)
+			}
+			if other, ok := tenantSinks[sink]; ok && other != name {
+				return fmt.Errorf("invalid api section: sink %s is shared by tenants %s and %s", sink, other, name)
+			}
+			tenantSinks[sink] = name
+		}
+	}
+	if err := o.Storage.IsValid(); err != nil {
+		return fmt.Errorf("invalid storage section: %w", err)
+	}
+	names := make(map[string]bool)
+	for _, responder := range o.Responders {
+		if err := responder.IsValid(); err != nil {
+			return fmt.Errorf("invalid responders section: %s: %w", responder.Name, err)
+		}
+		if names[responder.Name] {
+			return fmt.Errorf("invalid responders section: duplicate responder %s", responder.Name)
+		}
+		names[responder.Name] = true
+	}
 	return nil
 }
 
+// EventTypeHandler handles the events of an event type, see Options.EventTypeHandlers
+type EventTypeHandler func(event *events.Event) error
+
 // NewOptions returns a default set of options
 func NewOptions() *Options {
 	return &Options{
-		Events: events.NewEventsOptions(),
+		StateDirectory:  "/var/lib/krie",
+		Decoding:        StrictDecoding,
+		ModInfo:         true,
+		Pipeline:        NewPipelineOptions(),
+		Sharding:        NewShardingOptions(),
+		Redaction:       NewRedactionOptions(),
+		Webhook:         NewWebhookOptions(),
+		CRI:             NewCRIOptions(),
+		Approval:        NewApprovalOptions(),
+		Burst:           NewBurstOptions(),
+		Capture:         NewCaptureOptions(),
+		Metrics:         NewMetricsOptions(),
+		Governor:        NewGovernorOptions(),
+		Chaos:           NewChaosOptions(),
+		API:             NewAPIOptions(),
+		ProcessTree:     NewProcessTreeOptions(),
+		Clock:           NewClockOptions(),
+		Silence:         NewSilenceOptions(),
+		TailCalls:       NewTailCallsOptions(),
+		Correlation:     NewCorrelationOptions(),
+		Noise:           NewNoiseOptions(),
+		SymbolWatcher:   NewSymbolWatcherOptions(),
+		Status:          NewStatusOptions(),
+		ProcessExit:     NewProcessExitOptions(),
+		Companion:       NewCompanionOptions(),
+		Boot:            NewBootOptions(),
+		LSMState:        NewLSMStateOptions(),
+		HiddenProcess:   NewHiddenProcessOptions(),
+		Enrichment:      NewEnrichmentOptions(),
+		BPFLifecycle:    NewBPFLifecycleOptions(),
+		ModuleLifecycle: NewModuleLifecycleOptions(),
+		Anomaly:         NewAnomalyOptions(),
+		Intel:           NewIntelOptions(),
+		Failures:        NewFailureOptions(),
+		Privacy:         NewPrivacyOptions(),
+		Profiling:       NewProfilingOptions(),
+		Upgrade:         NewUpgradeOptions(),
+		Hardening:       NewHardeningOptions(),
+		Storage:         NewStorageOptions(),
+		Events:          events.NewEventsOptions(),
+	}
+}
+
+// LoadOptions loads the options of a config file, on top of the default set of options
+func LoadOptions(path string) (*Options, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load config file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	options := NewOptions()
+	if err = yaml.NewDecoder(f).Decode(&options); err != nil {
+		return nil, fmt.Errorf("couldn't decode config file %s: %w", path, err)
 	}
+	return options, nil
 }
 
 // LogLevel is a wrapper around logrus.Level to unmarshal a log level from yaml
@@ -0,0 +1,392 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"debug/elf"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	// moduleSignatureMagic is appended to signed kernel modules
+	moduleSignatureMagic = "~Module signature appended~\n"
+	// moduleSignatureInfoSize is the size of struct module_signature
+	moduleSignatureInfoSize = 12
+	// maxModuleSize is the maximum size of the module files parsed by KRIE
+	maxModuleSize = 64 << 20
+)
+
+// hashAlgorithms are the hash algorithms of struct module_signature, indexed by their ID in the kernel
+var hashAlgorithms = []string{"md4", "md5", "sha1", "rmd160", "sha256", "sha384", "sha512", "sha224"}
+
+// resolveModuleFile returns the path of the file of a loaded module: either a ".ko" argument of the process that loaded
+// the module (insmod), or the path of the module in modules.dep (modprobe)
+func resolveModuleFile(event *events.Event) string {
+	for _, arg := range event.Process.Args {
+		if !strings.HasSuffix(arg, ".ko") && !strings.Contains(arg, ".ko.") {
+			continue
+		}
+		if !filepath.IsAbs(arg) {
+			// resolve relative paths from the working directory of the process, if it is still running
+			cwd, err := os.Readlink(fmt.Sprintf("/proc/%d/cwd", event.Process.PID))
+			if err != nil {
+				continue
+			}
+			arg = filepath.Join(cwd, arg)
+		}
+		if _, err := os.Stat(arg); err == nil {
+			return arg
+		}
+	}
+	return findModuleInModulesDep(event.InitModule.Name)
+}
+
+// findModuleInModulesDep looks for a module in the modules.dep file of the running kernel
+func findModuleInModulesDep(name string) string {
+	var uname unix.Utsname
+	if err := unix.Uname(&uname); err != nil {
+		return ""
+	}
+	directory := filepath.Join("/lib/modules", unix.ByteSliceToString(uname.Release[:]))
+	f, err := os.Open(filepath.Join(directory, "modules.dep"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	// module names use underscores, file names can use dashes
+	name = strings.ReplaceAll(name, "-", "_")
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		path, _, _ := strings.Cut(scanner.Text(), ":")
+		base := filepath.Base(path)
+		if i := strings.Index(base, ".ko"); i >= 0 {
+			base = base[:i]
+		}
+		if strings.ReplaceAll(base, "-", "_") == name {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(directory, path)
+			}
+			return path
+		}
+	}
+	return ""
+}
+
+// resolveModuleBackingFile resolves the path and the SHA-256 of the file loaded by finit_module, and returns a path from
+// which KRIE can read the file. The file descriptor of the process is used first, then the candidates of
+// resolveModuleFile: a candidate is only used if its inode matches the inode of the file loaded by the kernel.
+func resolveModuleBackingFile(event *events.Event) string {
+	im := &event.InitModule
+	if im.LoadedFromMemory || im.Inode == 0 {
+		return ""
+	}
+
+	fdPath := resolveProcessFD(event.Process.PID, im.FD)
+	candidates := []string{fdPath, fmt.Sprintf("/proc/%d/fd/%d", event.Process.PID, im.FD), resolveModuleFile(event)}
+	for _, candidate := range candidates {
+		if len(candidate) == 0 {
+			continue
+		}
+		sum, err := hashModuleFile(candidate, im.Inode, im.Device)
+		if err != nil {
+			continue
+		}
+		im.SHA256 = sum
+		im.Path = candidate
+		if len(fdPath) > 0 {
+			// the path of the file in the mount namespace of the process
+			im.Path = fdPath
+		}
+		return candidate
+	}
+	im.Path = fdPath
+	return ""
+}
+
+// hashModuleFile returns the SHA-256 of a module file, if it is the file identified by the provided inode and device
+// (in the kernel encoding)
+func hashModuleFile(path string, inode uint64, device uint32) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var stat unix.Stat_t
+	if err = unix.Fstat(int(f.Fd()), &stat); err != nil {
+		return "", err
+	}
+	if stat.Ino != inode || unix.Major(uint64(stat.Dev)) != device>>20 || unix.Minor(uint64(stat.Dev)) != device&0xfffff {
+		return "", fmt.Errorf("%s isn't the loaded module file", path)
+	}
+
+	h := sha256.New()
+	if _, err = io.Copy(h, io.LimitReader(f, maxModuleSize)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// readModuleInfo parses the .modinfo section and the signature of a module file
+func readModuleInfo(path string) (*events.ModuleInfo, *moduleSignature) {
+	info := &events.ModuleInfo{
+		Path: path,
+	}
+	data, err := readModuleFile(path)
+	if err != nil {
+		info.Error = err.Error()
+		return info, nil
+	}
+
+	// the signature is appended after the ELF file
+	sig, err := parseModuleSignature(data, info)
+	if err != nil {
+		info.Error = err.Error()
+	}
+
+	file, err := elf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		info.Error = fmt.Sprintf("couldn't parse module: %v", err)
+		return info, sig
+	}
+	defer file.Close()
+	section := file.Section(".modinfo")
+	if section == nil {
+		info.Error = "no .modinfo section"
+		return info, sig
+	}
+	modinfo, err := section.Data()
+	if err != nil {
+		info.Error = fmt.Sprintf("couldn't read .modinfo section: %v", err)
+		return info, sig
+	}
+	for _, entry := range bytes.Split(modinfo, []byte{0}) {
+		key, value, ok := strings.Cut(string(entry), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "version":
+			info.Version = value
+		case "description":
+			info.Description = value
+		case "author":
+			info.Author = value
+		case "license":
+			info.License = value
+		case "vermagic":
+			info.Vermagic = value
+		case "intree":
+			info.InTree = value == "Y"
+		case "depends":
+			for _, dep := range strings.Split(value, ",") {
+				if len(dep) > 0 {
+					info.Depends = append(info.Depends, dep)
+				}
+			}
+		}
+	}
+
+	info.ImplantHeuristic = strings.Contains(info.License, "GPL") && len(info.Signer) == 0 && len(info.Depends) == 0
+	return info, sig
+}
+
+// readModuleFile reads a module file, gzip compressed modules are decompressed
+func readModuleFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var reader io.Reader = f
+	switch {
+	case strings.HasSuffix(path, ".gz"):
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	case strings.HasSuffix(path, ".xz"), strings.HasSuffix(path, ".zst"):
+		return nil, fmt.Errorf("unsupported module compression: %s", filepath.Ext(path))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxModuleSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxModuleSize {
+		return nil, fmt.Errorf("module file too large")
+	}
+	return data, nil
+}
+
+// pkcs7SignedData is the subset of a PKCS#7 SignedData structure required to find the signer of a module
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue     `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue     `asn1:"optional,tag:1"`
+	SignerInfos      []pkcs7SignerInfo `asn1:"set"`
+}
+
+type pkcs7SignerInfo struct {
+	Version             int
+	IssuerAndSerial     pkcs7IssuerAndSerial
+	DigestAlgorithm     pkix.AlgorithmIdentifier
+	AuthenticatedAttrs  asn1.RawValue `asn1:"optional,tag:0"`
+	DigestEncryptionAlg pkix.AlgorithmIdentifier
+	EncryptedDigest     []byte
+}
+
+type pkcs7IssuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+// moduleSignature is the signature appended to a module
+type moduleSignature struct {
+	hashAlgo  string
+	issuer    []byte
+	serial    *big.Int
+	signature []byte
+	// content is the signed content of the module file
+	content []byte
+	// authenticatedAttributes is set when the signature covers PKCS#7 authenticated attributes instead of the content
+	authenticatedAttributes bool
+}
+
+// parseModuleSignature parses the signature appended to a module, if any
+func parseModuleSignature(data []byte, info *events.ModuleInfo) (*moduleSignature, error) {
+	if !bytes.HasSuffix(data, []byte(moduleSignatureMagic)) {
+		return nil, nil
+	}
+	end := len(data) - len(moduleSignatureMagic)
+	if end < moduleSignatureInfoSize {
+		return nil, fmt.Errorf("invalid module signature")
+	}
+	sigInfo := data[end-moduleSignatureInfoSize : end]
+	hashAlgo := sigInfo[1]
+	sigLen := int(binary.BigEndian.Uint32(sigInfo[8:12]))
+	start := end - moduleSignatureInfoSize - sigLen
+	if start < 0 {
+		return nil, fmt.Errorf("invalid module signature length: %d", sigLen)
+	}
+	sig := &moduleSignature{
+		content: data[:start],
+	}
+	if int(hashAlgo) < len(hashAlgorithms) {
+		sig.hashAlgo = hashAlgorithms[hashAlgo]
+		info.SigHashAlgo = sig.hashAlgo
+	}
+
+	// the signature is a PKCS#7 message, the signer is the issuer of the signing certificate
+	var contentInfo pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(data[start:start+sigLen], &contentInfo); err != nil {
+		return nil, fmt.Errorf("couldn't parse module signature: %w", err)
+	}
+	var signedData pkcs7SignedData
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil {
+		return nil, fmt.Errorf("couldn't parse module signature: %w", err)
+	}
+	if len(signedData.SignerInfos) == 0 {
+		return nil, fmt.Errorf("module signature without signer")
+	}
+	signer := signedData.SignerInfos[0]
+	sig.issuer = signer.IssuerAndSerial.Issuer.FullBytes
+	sig.serial = signer.IssuerAndSerial.Serial
+	sig.signature = signer.EncryptedDigest
+	sig.authenticatedAttributes = len(signer.AuthenticatedAttrs.FullBytes) > 0
+
+	var rdn pkix.RDNSequence
+	if _, err := asn1.Unmarshal(sig.issuer, &rdn); err != nil {
+		return nil, fmt.Errorf("couldn't parse module signer: %w", err)
+	}
+	var name pkix.Name
+	name.FillFromRDNSequence(&rdn)
+	info.Signer = name.CommonName
+	if len(info.Signer) == 0 {
+		info.Signer = name.String()
+	}
+	if sig.serial != nil {
+		info.SigKey = strings.ToUpper(fmt.Sprintf("%x", sig.serial))
+	}
+	return sig, nil
+}
+
+// signatureAlgorithm returns the x509 signature algorithm of a module signature made with the provided certificate
+func (sig *moduleSignature) signatureAlgorithm(cert *x509.Certificate) x509.SignatureAlgorithm {
+	algorithms := map[x509.PublicKeyAlgorithm]map[string]x509.SignatureAlgorithm{
+		x509.RSA: {
+			"sha1":   x509.SHA1WithRSA,
+			"sha256": x509.SHA256WithRSA,
+			"sha384": x509.SHA384WithRSA,
+			"sha512": x509.SHA512WithRSA,
+		},
+		x509.ECDSA: {
+			"sha1":   x509.ECDSAWithSHA1,
+			"sha256": x509.ECDSAWithSHA256,
+			"sha384": x509.ECDSAWithSHA384,
+			"sha512": x509.ECDSAWithSHA512,
+		},
+	}
+	return algorithms[cert.PublicKeyAlgorithm][sig.hashAlgo]
+}
+
+// verify checks that the module was signed by one of the provided certificates
+func (sig *moduleSignature) verify(certs []*x509.Certificate) error {
+	if sig.authenticatedAttributes {
+		return fmt.Errorf("signatures with authenticated attributes aren't supported")
+	}
+	for _, cert := range certs {
+		if !bytes.Equal(cert.RawIssuer, sig.issuer) || sig.serial == nil || cert.SerialNumber.Cmp(sig.serial) != 0 {
+			continue
+		}
+		algorithm := sig.signatureAlgorithm(cert)
+		if algorithm == x509.UnknownSignatureAlgorithm {
+			return fmt.Errorf("unsupported signature algorithm: %s with %s", sig.hashAlgo, cert.PublicKeyAlgorithm)
+		}
+		return cert.CheckSignature(algorithm, sig.content, sig.signature)
+	}
+	return fmt.Errorf("the signing key isn't allowed")
+}
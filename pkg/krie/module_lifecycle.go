@@ -0,0 +1,214 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// ModuleLifecycleOptions configures the kernel module lifecycle tracker: the events attributed to a module (init_module,
+// hooks installed by the module, taint changes, delete_module) share a module instance ID
+type ModuleLifecycleOptions struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is the number of seconds between two checks of the taint flags of the tracked modules
+	Interval int64 `yaml:"interval"`
+	// MaxModules is the maximum number of tracked modules, the events of the other modules aren't linked
+	MaxModules int `yaml:"max_modules"`
+}
+
+// IsValid returns an error if the module lifecycle options are invalid
+func (o *ModuleLifecycleOptions) IsValid() error {
+	if !o.Enabled {
+		return nil
+	}
+	if o.Interval <= 0 {
+		return fmt.Errorf("invalid interval: %d", o.Interval)
+	}
+	if o.MaxModules <= 0 {
+		return fmt.Errorf("invalid max_modules: %d", o.MaxModules)
+	}
+	return nil
+}
+
+// NewModuleLifecycleOptions returns a default set of module lifecycle options
+func NewModuleLifecycleOptions() *ModuleLifecycleOptions {
+	return &ModuleLifecycleOptions{
+		Interval:   10,
+		MaxModules: 1024,
+	}
+}
+
+// sysModuleDirectory exposes the loaded modules and their taint flags
+const sysModuleDirectory = "/sys/module"
+
+// readModuleTaint returns the taint flags of a loaded module
+func readModuleTaint(module string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(sysModuleDirectory, module, "taint"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// moduleLifecycleTracker links the events attributed to the same instance of a kernel module, and emits a
+// module_taint_change event when the taint flags of a tracked module change
+type moduleLifecycleTracker struct {
+	krie    *KRIE
+	options *ModuleLifecycleOptions
+	bootID  string
+
+	lock    sync.Mutex
+	modules map[string]*events.ModuleLifecycle
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newModuleLifecycleTracker(krie *KRIE, options *ModuleLifecycleOptions) *moduleLifecycleTracker {
+	return &moduleLifecycleTracker{
+		krie:    krie,
+		options: options,
+		bootID:  readBootID(),
+		modules: make(map[string]*events.ModuleLifecycle),
+		stop:    make(chan struct{}),
+	}
+}
+
+// link sets the module lifecycle of an event attributed to a module
+func (mt *moduleLifecycleTracker) link(event *events.Event) {
+	module, stage := event.LifecycleModule()
+	if len(module) == 0 {
+		return
+	}
+
+	mt.lock.Lock()
+	defer mt.lock.Unlock()
+
+	lifecycle, ok := mt.modules[module]
+	// a module loaded again gets a new instance ID, even if its unload wasn't observed
+	if !ok || (stage == events.ModuleLifecycleLoad && len(lifecycle.LoadEventID) > 0) {
+		if !ok && len(mt.modules) >= mt.options.MaxModules {
+			return
+		}
+		lifecycle = &events.ModuleLifecycle{
+			ID:        fmt.Sprintf("%s-%s-%x", mt.bootID, module, event.Kernel.Time.UnixNano()),
+			Module:    module,
+			FirstSeen: event.Kernel.Time,
+		}
+		mt.modules[module] = lifecycle
+	}
+	if stage == events.ModuleLifecycleLoad {
+		lifecycle.LoadEventID = event.ID
+	}
+	if taint, err := readModuleTaint(module); err == nil {
+		lifecycle.Taint = taint
+	}
+
+	linked := advanceModuleLifecycle(lifecycle, stage, event.ID)
+	event.ModuleLifecycle = &linked
+
+	// the instance ends with a successful delete_module
+	if stage == events.ModuleLifecycleUnload && event.Kernel.Retval == 0 {
+		delete(mt.modules, module)
+	}
+}
+
+// advanceModuleLifecycle records a new event in the lifecycle of a module, and returns the lifecycle of the event
+func advanceModuleLifecycle(lifecycle *events.ModuleLifecycle, stage string, eventID string) events.ModuleLifecycle {
+	lifecycle.Sequence++
+	lifecycle.Stage = stage
+	known := false
+	for _, s := range lifecycle.Stages {
+		known = known || s == stage
+	}
+	if !known {
+		lifecycle.Stages = append(lifecycle.Stages, stage)
+	}
+
+	linked := *lifecycle
+	linked.Stages = append([]string(nil), lifecycle.Stages...)
+	lifecycle.PreviousEventID = eventID
+	return linked
+}
+
+func (mt *moduleLifecycleTracker) start() {
+	mt.wg.Add(1)
+	go func() {
+		defer mt.wg.Done()
+		ticker := time.NewTicker(time.Duration(mt.options.Interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-mt.stop:
+				return
+			case <-ticker.C:
+				mt.check()
+			}
+		}
+	}()
+}
+
+func (mt *moduleLifecycleTracker) close() {
+	close(mt.stop)
+	mt.wg.Wait()
+}
+
+// check emits a module_taint_change event for the tracked modules whose taint flags changed. The modules which aren't
+// loaded anymore are forgotten, a failed load or a missed unload doesn't keep a module tracked.
+func (mt *moduleLifecycleTracker) check() {
+	var changes []*events.Event
+	mt.lock.Lock()
+	for module, lifecycle := range mt.modules {
+		taint, err := readModuleTaint(module)
+		if err != nil {
+			if os.IsNotExist(err) {
+				delete(mt.modules, module)
+			}
+			continue
+		}
+		if taint == lifecycle.Taint {
+			continue
+		}
+
+		// the ID of the event is set beforehand so that the next event of the module can point to it
+		event := events.NewEvent()
+		event.ID = events.NewEventID()
+		event.Kernel.Type = events.ModuleTaintChangeEventType
+		event.ModuleTaintChangeEvent.Module = module
+		event.ModuleTaintChangeEvent.PreviousTaint = lifecycle.Taint
+		event.ModuleTaintChangeEvent.Taint = taint
+		lifecycle.Taint = taint
+		event.ModuleTaintChangeEvent.Lifecycle = advanceModuleLifecycle(lifecycle, events.ModuleLifecycleTaint, event.ID)
+		changes = append(changes, event)
+	}
+	mt.lock.Unlock()
+
+	for _, event := range changes {
+		if err := mt.krie.emitEvent(event); err != nil {
+			logrus.Errorf("couldn't emit module_taint_change event: %v", err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// sigEnforceParameter exposes module.sig_enforce, it only exists with CONFIG_MODULE_SIG
+const sigEnforceParameter = "/sys/module/module/parameters/sig_enforce"
+
+// readSigEnforce returns the current value of module.sig_enforce
+func readSigEnforce() events.SigEnforceState {
+	data, err := os.ReadFile(sigEnforceParameter)
+	if err != nil {
+		return events.SigEnforceUnknown
+	}
+	switch strings.TrimSpace(string(data)) {
+	case "Y", "1":
+		return events.SigEnforceEnabled
+	case "N", "0":
+		return events.SigEnforceDisabled
+	default:
+		return events.SigEnforceUnknown
+	}
+}
+
+// checkSigEnforce reports a disabled module.sig_enforce when the policies expect it to be enabled. The kernel checks
+// only report the changes made after KRIE started.
+func (e *KRIE) checkSigEnforce() {
+	policies, _, _ := e.currentPolicies()
+	if !policies.ModuleSignatureEvent.ExpectSigEnforce || policies.ParseEventsActions()[events.ModuleSignatureEventType] < events.LogAction {
+		return
+	}
+
+	state := readSigEnforce()
+	switch state {
+	case events.SigEnforceEnabled:
+		return
+	case events.SigEnforceUnknown:
+		logrus.Warnf("module.sig_enforce is expected but %s can't be read, the kernel may not verify the module signatures", sigEnforceParameter)
+		return
+	}
+
+	event := events.NewEvent()
+	event.Kernel.Type = events.ModuleSignatureEventType
+	event.ModuleSignatureEvent = events.ModuleSignatureEvent{
+		Kind:       events.ModuleSignatureStartup,
+		SigEnforce: state,
+	}
+	event.ModuleSignatureEvent.ResolveBypass(true)
+	if err := e.emitEvent(event); err != nil {
+		logrus.Errorf("couldn't emit module_signature event: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// inspectModule adds the modinfo of a loaded module to its init_module event, and applies the module signer policy
+func (e *KRIE) inspectModule(event *events.Event, policies *events.Options) {
+	// finit_module: resolve the file that was actually loaded
+	backingFile := resolveModuleBackingFile(event)
+
+	signerPolicy := policies.ModuleSigners
+	if !e.options.ModInfo && signerPolicy.Action == events.NopAction {
+		return
+	}
+
+	var info *events.ModuleInfo
+	var sig *moduleSignature
+	path := backingFile
+	if len(path) == 0 && !event.InitModule.LoadedFromMemory {
+		// the modinfo of an unverified file is only informative, its signature is never checked
+		path = resolveModuleFile(event)
+	}
+	if len(path) > 0 {
+		info, sig = readModuleInfo(path)
+		if len(event.InitModule.Path) > 0 {
+			info.Path = event.InitModule.Path
+		}
+	}
+	if e.options.ModInfo {
+		event.InitModule.ModInfo = info
+	}
+	if signerPolicy.Action == events.NopAction {
+		return
+	}
+
+	// the module signer policy can't be bypassed by loading a module from memory, or by naming a module after a signed
+	// module file: only the file identified by the inode and the device reported by the kernel is verified, a module
+	// that can't be verified is denied
+	var err error
+	switch {
+	case event.InitModule.LoadedFromMemory:
+		err = fmt.Errorf("module loaded from memory")
+	case len(backingFile) == 0:
+		err = fmt.Errorf("couldn't resolve the loaded module file")
+	case sig == nil:
+		err = fmt.Errorf("unsigned module")
+	default:
+		err = sig.verify(signerPolicy.AllowedCertificates())
+	}
+	if err == nil {
+		event.InitModule.SignerPolicy = events.SignerPolicyAllowed
+		return
+	}
+
+	event.InitModule.SignerPolicy = events.SignerPolicyDenied
+	logrus.Warnf("module %s isn't signed by an allowed key: %v", event.InitModule.Name, err)
+	if signerPolicy.Action < events.BlockAction || policies.IsMonitored(fmt.Sprintf("%s/%s", events.InitModuleEventType, events.UnauthorizedSignerRule)) {
+		return
+	}
+	unloadModule(event)
+}
+
+// unloadModule unloads the module of an init_module event: the module is already loaded when its event is received
+func unloadModule(event *events.Event) {
+	if event.InitModule.Unloaded {
+		return
+	}
+	if err := unix.DeleteModule(event.InitModule.Name, unix.O_NONBLOCK); err != nil {
+		logrus.Errorf("couldn't unload module %s: %v", event.InitModule.Name, err)
+		return
+	}
+	event.InitModule.Unloaded = true
+}
@@ -0,0 +1,129 @@
+//go:build !krie_minimal
+
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// writeSignedModule writes a module file signed by a new certificate, and returns the path of the certificate
+func writeSignedModule(t *testing.T, path string) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "test signing key"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	content := []byte("\x7fELF test module")
+	digest := sha256.Sum256(content)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	assert.NoError(t, err)
+
+	signedData, err := asn1.Marshal(pkcs7SignedData{
+		Version:          1,
+		DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+		ContentInfo:      asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true},
+		SignerInfos: []pkcs7SignerInfo{{
+			Version:             1,
+			IssuerAndSerial:     pkcs7IssuerAndSerial{Issuer: asn1.RawValue{FullBytes: cert.RawIssuer}, Serial: cert.SerialNumber},
+			DigestAlgorithm:     pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}},
+			DigestEncryptionAlg: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}},
+			EncryptedDigest:     signature,
+		}},
+	})
+	assert.NoError(t, err)
+	pkcs7, err := asn1.Marshal(pkcs7ContentInfo{
+		ContentType: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2},
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedData},
+	})
+	assert.NoError(t, err)
+
+	// struct module_signature: sha256 (id 4), PKCS#7 (id_type 2), then the big endian signature length
+	sigInfo := make([]byte, moduleSignatureInfoSize)
+	sigInfo[1], sigInfo[2] = 4, 2
+	binary.BigEndian.PutUint32(sigInfo[8:], uint32(len(pkcs7)))
+	module := append(append(append(content, pkcs7...), sigInfo...), moduleSignatureMagic...)
+	assert.NoError(t, os.WriteFile(path, module, 0600))
+
+	certPath := filepath.Join(filepath.Dir(path), "signing_key.pem")
+	assert.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	return certPath
+}
+
+func TestInspectModule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ext4.ko")
+	certPath := writeSignedModule(t, path)
+	var stat unix.Stat_t
+	assert.NoError(t, unix.Stat(path, &stat))
+
+	policies := events.NewEventsOptions()
+	policies.ModuleSigners.Action = events.LogAction
+	policies.ModuleSigners.Certificates = []string{certPath}
+	assert.NoError(t, policies.ModuleSigners.IsValid())
+	e := &KRIE{options: NewOptions()}
+
+	for name, test := range map[string]struct {
+		loadedFromMemory bool
+		inode            uint64
+		expected         string
+	}{
+		"verified file":      {inode: stat.Ino, expected: events.SignerPolicyAllowed},
+		"loaded from memory": {loadedFromMemory: true, expected: events.SignerPolicyDenied},
+		"unverified inode":   {inode: stat.Ino + 1, expected: events.SignerPolicyDenied},
+	} {
+		// the module is named after the signed file, which is also an argument of the process
+		event := newTestModuleEvent("")
+		event.Process.PID = 0
+		event.Process.Args = []string{"insmod", path}
+		event.InitModule.Name = "ext4"
+		event.InitModule.FD = -1
+		event.InitModule.LoadedFromMemory = test.loadedFromMemory
+		event.InitModule.Inode = test.inode
+		event.InitModule.Device = uint32(unix.Major(uint64(stat.Dev))<<20 | unix.Minor(uint64(stat.Dev)))
+
+		e.inspectModule(event, policies)
+		assert.Equal(t, test.expected, event.InitModule.SignerPolicy, name)
+		assert.False(t, event.InitModule.Unloaded, name)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// hostMountNamespacePath is the mount namespace of the init process of the host
+const hostMountNamespacePath = "/proc/1/ns/mnt"
+
+var (
+	hostMountNamespaceOnce sync.Once
+	hostMountNamespace     uint32
+)
+
+// readNamespaceInode returns the inode of a namespace link ("mnt:[4026531841]"), or 0 if it can't be read
+func readNamespaceInode(path string) uint32 {
+	link, err := os.Readlink(path)
+	if err != nil {
+		return 0
+	}
+	start, end := strings.IndexByte(link, '['), strings.IndexByte(link, ']')
+	if start < 0 || end <= start {
+		return 0
+	}
+	inode, err := strconv.ParseUint(link[start+1:end], 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(inode)
+}
+
+// resolveMountNamespace flags the mounts made outside of the mount namespace of the host. KRIE runs in the pid
+// namespace of the host, so the init process of its pid namespace is the init process of the host.
+func resolveMountNamespace(event *events.Event) {
+	hostMountNamespaceOnce.Do(func() {
+		hostMountNamespace = readNamespaceInode(hostMountNamespacePath)
+	})
+	if hostMountNamespace == 0 {
+		return
+	}
+	event.MountEvent.NewNamespace = event.Process.NamespaceContext.MntNamespace != hostMountNamespace
+}
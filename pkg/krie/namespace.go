@@ -0,0 +1,73 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// hostNamespacePath is the namespace directory of the init process of the host
+const hostNamespacePath = "/proc/1/ns"
+
+var (
+	hostNamespacesOnce sync.Once
+	hostNamespaces     events.NamespaceContext
+)
+
+// readHostNamespaces reads the namespaces of the init process of the host. The pid namespace of the namespace context
+// is the namespace of the children of a process, the link of the namespace of the init process is read on the kernels
+// without pid_for_children.
+func readHostNamespaces() events.NamespaceContext {
+	read := func(nsType string) uint32 {
+		return readNamespaceInode(fmt.Sprintf("%s/%s", hostNamespacePath, nsType))
+	}
+	pid := read("pid_for_children")
+	if pid == 0 {
+		pid = read("pid")
+	}
+	return events.NamespaceContext{
+		CgroupNamespace: read("cgroup"),
+		IPCNamespace:    read("ipc"),
+		NetNamespace:    read("net"),
+		MntNamespace:    read("mnt"),
+		PIDNamespace:    pid,
+		TimeNamespace:   read("time"),
+		UserNamespace:   read("user"),
+		UTSNamespace:    read("uts"),
+	}
+}
+
+// resolveNamespaceEscape lists the namespaces of the host entered by a setns or an unshare call, and flags the calls
+// of the processes of a container pid namespace entering them (nsenter -t 1 from a privileged container). KRIE runs
+// in the pid namespace of the host, so the init process of its pid namespace is the init process of the host.
+func resolveNamespaceEscape(event *events.Event) {
+	hostNamespacesOnce.Do(func() {
+		hostNamespaces = readHostNamespaces()
+	})
+	ns := &event.NamespaceEvent
+	for _, nsType := range ns.Changed {
+		host := hostNamespaces.Inode(nsType)
+		if host != 0 && event.Process.NamespaceContext.Inode(nsType) == host && ns.Previous.Inode(nsType) != host {
+			ns.EnteredHost = append(ns.EnteredHost, nsType)
+		}
+	}
+	ns.HostEscape = hostNamespaces.PIDNamespace != 0 && ns.Previous.PIDNamespace != hostNamespaces.PIDNamespace &&
+		len(ns.EnteredHost) > 0
+}
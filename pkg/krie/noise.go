@@ -0,0 +1,325 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// NoiseOptions configures the tracking of the event volume of each rule and process, used to suggest filters
+type NoiseOptions struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxEntries is the maximum number of tracked rule and process combinations, the new combinations are merged into
+	// the "(other)" process of their rule once the limit is reached
+	MaxEntries int `yaml:"max_entries"`
+	// MinShare is the minimum share of the total volume (in percent) of a combination to be suggested as a filter
+	MinShare float64 `yaml:"min_share"`
+	// MaxSuggestions is the maximum number of suggestions of a report
+	MaxSuggestions int `yaml:"max_suggestions"`
+}
+
+// IsValid returns an error if the noise options are invalid
+func (o *NoiseOptions) IsValid() error {
+	if o.MaxEntries <= 0 {
+		return fmt.Errorf("invalid max_entries: %d", o.MaxEntries)
+	}
+	if o.MinShare < 0 || o.MinShare > 100 {
+		return fmt.Errorf("invalid min_share: %v", o.MinShare)
+	}
+	if o.MaxSuggestions < 0 {
+		return fmt.Errorf("invalid max_suggestions: %d", o.MaxSuggestions)
+	}
+	return nil
+}
+
+// NewNoiseOptions returns a default set of noise options
+func NewNoiseOptions() *NoiseOptions {
+	return &NoiseOptions{
+		MaxEntries:     10000,
+		MinShare:       1,
+		MaxSuggestions: 20,
+	}
+}
+
+const (
+	// otherNoiseProcess is the process of the combinations merged once the tracker is full
+	otherNoiseProcess = "(other)"
+
+	// NoiseSuggestionFilterProcess suggests to filter out the events of a process on a rule
+	NoiseSuggestionFilterProcess = "filter_process"
+	// NoiseSuggestionDisableRule suggests to set the action of a log only rule to "nop"
+	NoiseSuggestionDisableRule = "disable_rule"
+)
+
+// NoiseEntry is the event volume of a rule, or of a rule and process combination
+type NoiseEntry struct {
+	RuleID  string  `json:"rule_id"`
+	Comm    string  `json:"comm,omitempty"`
+	Count   uint64  `json:"count"`
+	Share   float64 `json:"share"`
+	PerHour float64 `json:"per_hour"`
+}
+
+// NoiseSuggestion is a candidate filter, along with the share of the total volume it would remove
+type NoiseSuggestion struct {
+	Kind               string  `json:"kind"`
+	RuleID             string  `json:"rule_id"`
+	Comm               string  `json:"comm,omitempty"`
+	Description        string  `json:"description"`
+	ProjectedReduction float64 `json:"projected_reduction"`
+}
+
+// NoiseReport ranks the rules and the rule and process combinations by event volume, and suggests filters
+type NoiseReport struct {
+	Since        time.Time         `json:"since"`
+	Until        time.Time         `json:"until"`
+	Total        uint64            `json:"total"`
+	Rules        []NoiseEntry      `json:"rules"`
+	Combinations []NoiseEntry      `json:"combinations"`
+	Suggestions  []NoiseSuggestion `json:"suggestions"`
+	// Annotations summarizes the triage notes of the analysts
+	Annotations *AnnotationSummary `json:"annotations,omitempty"`
+}
+
+type noiseKey struct {
+	ruleID string
+	comm   string
+}
+
+// noiseCounter counts the events of each rule and process combination
+type noiseCounter struct {
+	maxEntries int
+	counts     map[noiseKey]uint64
+	since      time.Time
+	until      time.Time
+}
+
+func newNoiseCounter(maxEntries int) *noiseCounter {
+	return &noiseCounter{
+		maxEntries: maxEntries,
+		counts:     make(map[noiseKey]uint64),
+	}
+}
+
+func (nc *noiseCounter) add(ruleID string, comm string, at time.Time) {
+	key := noiseKey{ruleID: ruleID, comm: comm}
+	if _, ok := nc.counts[key]; !ok && len(nc.counts) >= nc.maxEntries {
+		key.comm = otherNoiseProcess
+	}
+	nc.counts[key]++
+	if nc.since.IsZero() || at.Before(nc.since) {
+		nc.since = at
+	}
+	if at.After(nc.until) {
+		nc.until = at
+	}
+}
+
+// report builds a noise report from the counted events
+func (nc *noiseCounter) report(options *NoiseOptions, policies *events.Options) *NoiseReport {
+	report := &NoiseReport{
+		Since: nc.since,
+		Until: nc.until,
+	}
+	hours := nc.until.Sub(nc.since).Hours()
+	rules := make(map[string]uint64)
+	for key, count := range nc.counts {
+		report.Total += count
+		rules[key.ruleID] += count
+	}
+	if report.Total == 0 {
+		return report
+	}
+	entry := func(ruleID string, comm string, count uint64) NoiseEntry {
+		e := NoiseEntry{
+			RuleID: ruleID,
+			Comm:   comm,
+			Count:  count,
+			Share:  100 * float64(count) / float64(report.Total),
+		}
+		if hours > 0 {
+			e.PerHour = float64(count) / hours
+		}
+		return e
+	}
+	for ruleID, count := range rules {
+		report.Rules = append(report.Rules, entry(ruleID, "", count))
+	}
+	for key, count := range nc.counts {
+		report.Combinations = append(report.Combinations, entry(key.ruleID, key.comm, count))
+	}
+	sortNoiseEntries(report.Rules)
+	sortNoiseEntries(report.Combinations)
+
+	// a log only rule dominated by many processes is better disabled, a single noisy process is better filtered out.
+	// The rules that block, kill or alter the monitored operations are never suggested for removal.
+	for _, rule := range report.Rules {
+		if rule.Share < options.MinShare || policies.RuleMode(rule.RuleID) != "" {
+			continue
+		}
+		report.Suggestions = append(report.Suggestions, NoiseSuggestion{
+			Kind:               NoiseSuggestionDisableRule,
+			RuleID:             rule.RuleID,
+			Description:        fmt.Sprintf("set the action of %s to \"nop\"", rule.RuleID),
+			ProjectedReduction: rule.Share,
+		})
+	}
+	for _, combination := range report.Combinations {
+		if combination.Share < options.MinShare || len(combination.Comm) == 0 || combination.Comm == otherNoiseProcess {
+			continue
+		}
+		report.Suggestions = append(report.Suggestions, NoiseSuggestion{
+			Kind:               NoiseSuggestionFilterProcess,
+			RuleID:             combination.RuleID,
+			Comm:               combination.Comm,
+			Description:        fmt.Sprintf("filter out the %s events of %s", combination.RuleID, combination.Comm),
+			ProjectedReduction: combination.Share,
+		})
+	}
+	sort.SliceStable(report.Suggestions, func(i, j int) bool {
+		return report.Suggestions[i].ProjectedReduction > report.Suggestions[j].ProjectedReduction
+	})
+	if len(report.Suggestions) > options.MaxSuggestions {
+		report.Suggestions = report.Suggestions[:options.MaxSuggestions]
+	}
+	return report
+}
+
+func sortNoiseEntries(entries []NoiseEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		if entries[i].RuleID != entries[j].RuleID {
+			return entries[i].RuleID < entries[j].RuleID
+		}
+		return entries[i].Comm < entries[j].Comm
+	})
+}
+
+// noiseTracker tracks the volume of the dispatched events
+type noiseTracker struct {
+	options *NoiseOptions
+
+	lock    sync.Mutex
+	counter *noiseCounter
+}
+
+func newNoiseTracker(options *NoiseOptions) *noiseTracker {
+	return &noiseTracker{
+		options: options,
+		counter: newNoiseCounter(options.MaxEntries),
+	}
+}
+
+// record counts the provided event, nt can be nil when the noise tracking is disabled
+func (nt *noiseTracker) record(event *events.Event) {
+	// the annotations are triage notes, not detections
+	if nt == nil || event.Kernel.Type == events.AnnotationEventType {
+		return
+	}
+	nt.lock.Lock()
+	defer nt.lock.Unlock()
+	nt.counter.add(event.Policy.RuleID, event.Process.Comm, event.Kernel.Time)
+}
+
+// NoiseReport returns the noise report of the events dispatched since KRIE started
+func (e *KRIE) NoiseReport() (*NoiseReport, error) {
+	if e.noise == nil {
+		return nil, fmt.Errorf("noise tracking is disabled")
+	}
+	policies, _, _ := e.currentPolicies()
+	e.noise.lock.Lock()
+	report := e.noise.counter.report(e.noise.options, policies)
+	e.noise.lock.Unlock()
+	report.Annotations = e.annotations.summary()
+	return report, nil
+}
+
+// NoiseReportFromFile builds a noise report from the events of a JSON lines file, such as the output file of KRIE. The
+// annotation events of the file are added to the report.
+func NoiseReportFromFile(path string, options *NoiseOptions, policies *events.Options) (*NoiseReport, error) {
+	return NoiseReportFromStorage(OpenFileStorage(path), options, policies)
+}
+
+// NoiseReportFromStorage builds a noise report from the events of a Storage. The annotation events of the storage are
+// added to the report, they aren't counted as noise.
+func NoiseReportFromStorage(storage Storage, options *NoiseOptions, policies *events.Options) (*NoiseReport, error) {
+	counter := newNoiseCounter(options.MaxEntries)
+	annotations := make(map[string]*Annotation)
+	err := storage.Query(StorageQuery{}, func(event *StoredEvent) bool {
+		if event.Type != events.AnnotationEventType {
+			counter.add(event.RuleID, event.Comm, event.Time)
+			return true
+		}
+		if annotation, err := parseAnnotation(event); err == nil {
+			annotations[annotation.EventID] = annotation
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the annotations recorded without a rule are grouped under the rule of the annotated event
+	ruleIDs := make(map[string]string)
+	for eventID, annotation := range annotations {
+		if len(annotation.RuleID) == 0 {
+			ruleIDs[eventID] = ""
+		}
+	}
+	if len(ruleIDs) > 0 {
+		err = storage.Query(StorageQuery{}, func(event *StoredEvent) bool {
+			if _, ok := ruleIDs[event.ID]; ok {
+				ruleIDs[event.ID] = event.RuleID
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := counter.report(options, policies)
+	report.Annotations = summarizeAnnotations(annotations, ruleIDs)
+	return report, nil
+}
+
+// serializedNoiseKey returns the rule, the process name and the time of a serialized event. The events without a rule are
+// counted under their type.
+func serializedNoiseKey(serializer *events.EventSerializer) (string, string, time.Time) {
+	var ruleID, comm string
+	var at time.Time
+	if serializer.Policy != nil {
+		ruleID = serializer.Policy.RuleID
+	}
+	if serializer.ProcessContextSerializer != nil && serializer.ProcessContextSerializer.ProcessContext != nil {
+		comm = serializer.ProcessContextSerializer.ProcessContext.Comm
+	}
+	if serializer.KernelEventSerializer != nil && serializer.KernelEventSerializer.KernelEvent != nil {
+		at = serializer.KernelEventSerializer.KernelEvent.Time
+		if len(ruleID) == 0 {
+			ruleID = serializer.KernelEventSerializer.KernelEvent.Type.String()
+		}
+	}
+	return ruleID, comm, at
+}
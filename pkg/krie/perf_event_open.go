@@ -0,0 +1,76 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// perfEventSourcesDirectory lists the PMUs of the host, each PMU directory exposes the type of the PMU
+const perfEventSourcesDirectory = "/sys/bus/event_source/devices"
+
+var (
+	perfPMUsLock sync.Mutex
+	perfPMUs     map[uint32]string
+)
+
+// loadPerfPMUs returns the names of the PMUs of the host, indexed by type
+func loadPerfPMUs() map[uint32]string {
+	pmus := make(map[uint32]string)
+	entries, err := os.ReadDir(perfEventSourcesDirectory)
+	if err != nil {
+		return pmus
+	}
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(perfEventSourcesDirectory, entry.Name(), "type"))
+		if err != nil {
+			continue
+		}
+		pmuType, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 32)
+		if err != nil {
+			continue
+		}
+		pmus[uint32(pmuType)] = entry.Name()
+	}
+	return pmus
+}
+
+// resolvePerfEventPMU resolves the name of the dynamic PMU of a perf_event_open call (kprobe, uprobe, ...). The PMUs
+// are listed again when the type is unknown, since the PMUs of a module are registered when the module is loaded.
+func resolvePerfEventPMU(event *events.Event) {
+	if !event.PerfEventOpenEvent.IsDynamicPMU() {
+		return
+	}
+
+	perfPMUsLock.Lock()
+	defer perfPMUsLock.Unlock()
+
+	name, ok := perfPMUs[event.PerfEventOpenEvent.TypeID]
+	if !ok {
+		perfPMUs = loadPerfPMUs()
+		name, ok = perfPMUs[event.PerfEventOpenEvent.TypeID]
+	}
+	if ok {
+		event.PerfEventOpenEvent.Type = name
+	}
+}
@@ -0,0 +1,338 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	// OutputSinkName is the name of the sink writing events to the JSON output file
+	OutputSinkName = "output"
+
+	droppedEventsMetricName = "krie_sink_dropped_events_total"
+	droppedEventsMetricHelp = "Events dropped by the queues of the sinks"
+)
+
+// QueueOptions configures the queue in front of a sink
+type QueueOptions struct {
+	QueueSize  int        `yaml:"queue_size"`
+	DropPolicy DropPolicy `yaml:"drop_policy"`
+}
+
+// PipelineOptions configures how events are queued before being written to the sinks
+type PipelineOptions struct {
+	QueueOptions   `yaml:",inline"`
+	Sinks          map[string]QueueOptions `yaml:"sinks"`
+	Priorities     map[string]int          `yaml:"priorities"`
+	ReportInterval int64                   `yaml:"report_interval"`
+	// MinConfidence is the minimum confidence of the events sent to a sink, indexed by sink name. The events that don't
+	// report a detection (responses, policy changes, ...) aren't sent to the sinks with a minimum confidence.
+	MinConfidence map[string]events.Confidence `yaml:"min_confidence"`
+
+	priorities map[events.EventType]int
+}
+
+// IsValid returns an error if the pipeline options are invalid
+func (o *PipelineOptions) IsValid() error {
+	if o.QueueSize <= 0 {
+		return fmt.Errorf("invalid queue_size: %d", o.QueueSize)
+	}
+	for name, sink := range o.Sinks {
+		if sink.QueueSize < 0 {
+			return fmt.Errorf("invalid queue_size for sink %s: %d", name, sink.QueueSize)
+		}
+	}
+	o.priorities = make(map[events.EventType]int)
+	for eventType, priority := range o.Priorities {
+		evt := events.ParseEventType(eventType)
+		if evt == events.UnknownEventType {
+			return fmt.Errorf("unknown event type in priorities: %s", eventType)
+		}
+		o.priorities[evt] = priority
+	}
+	if o.ReportInterval < 0 {
+		return fmt.Errorf("invalid report_interval: %d", o.ReportInterval)
+	}
+	for name, confidence := range o.MinConfidence {
+		if err := confidence.IsValid(); err != nil {
+			return fmt.Errorf("invalid min_confidence for sink %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// SinkQueueOptions returns the queue options of the provided sink
+func (o *PipelineOptions) SinkQueueOptions(name string) QueueOptions {
+	out := o.QueueOptions
+	if sink, ok := o.Sinks[name]; ok {
+		if sink.QueueSize > 0 {
+			out.QueueSize = sink.QueueSize
+		}
+		if len(sink.DropPolicy) > 0 {
+			out.DropPolicy = sink.DropPolicy
+		}
+	}
+	return out
+}
+
+// Priority returns the priority of the provided event type
+func (o *PipelineOptions) Priority(eventType events.EventType) int {
+	return o.priorities[eventType]
+}
+
+// NewPipelineOptions returns a default set of pipeline options
+func NewPipelineOptions() *PipelineOptions {
+	return &PipelineOptions{
+		QueueOptions: QueueOptions{
+			QueueSize:  4096,
+			DropPolicy: DropOldest,
+		},
+		ReportInterval: 60,
+	}
+}
+
+// eventSink is the destination of the serialized events
+type eventSink interface {
+	write(evt *queuedEvent) error
+	close() error
+}
+
+// sinkStarter is implemented by the sinks that need to be started before the first write
+type sinkStarter interface {
+	start() error
+}
+
+// sinkStatsReporter is implemented by the sinks that report delivery statistics along with the dropped events
+type sinkStatsReporter interface {
+	logStats()
+}
+
+// pipelineSink is a queued sink of the pipeline
+type pipelineSink struct {
+	name          string
+	sink          eventSink
+	format        OutputFormat
+	minConfidence events.Confidence
+	queue         *eventQueue
+
+	// tenant is the tenant of the ingestion endpoint of the sink, empty for the sinks of this instance
+	tenant string
+}
+
+// pipeline dispatches serialized events to the sinks, each sink has its own queue so that a slow sink doesn't slow
+// down the others.
+type pipeline struct {
+	options *PipelineOptions
+	sinks   []*pipelineSink
+	latency *latencyTracer
+	metrics *metricsRegistry
+
+	wg       sync.WaitGroup
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func newPipeline(options *PipelineOptions) *pipeline {
+	return &pipeline{
+		options: options,
+		stop:    make(chan struct{}),
+	}
+}
+
+// addSink registers a new sink in the pipeline, the sink receives the native JSON representation of the events
+func (p *pipeline) addSink(name string, sink eventSink) {
+	p.addFormattedSink(name, sink, JSONFormat)
+}
+
+// addFormattedSink registers a new sink in the pipeline, the sink receives the events in the provided format
+func (p *pipeline) addFormattedSink(name string, sink eventSink, format OutputFormat) {
+	opts := p.options.SinkQueueOptions(name)
+	p.sinks = append(p.sinks, &pipelineSink{
+		name:          name,
+		sink:          sink,
+		format:        format,
+		minConfidence: p.options.MinConfidence[name],
+		queue:         newEventQueue(opts.QueueSize, opts.DropPolicy),
+	})
+}
+
+// addTenantSink registers a sink of a tenant of the ingestion endpoint. The sink only receives the events ingested for
+// its tenant, and none of the events of this instance.
+func (p *pipeline) addTenantSink(tenant string, name string, sink eventSink, format OutputFormat) {
+	p.addFormattedSink(name, sink, format)
+	p.sinks[len(p.sinks)-1].tenant = tenant
+}
+
+// hasSinks returns true if at least one sink receives the events of this instance
+func (p *pipeline) hasSinks() bool {
+	for _, sink := range p.sinks {
+		if len(sink.tenant) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// start starts the sinks, the sink workers and the drop reporter
+func (p *pipeline) start() error {
+	for _, sink := range p.sinks {
+		if starter, ok := sink.sink.(sinkStarter); ok {
+			if err := starter.start(); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, sink := range p.sinks {
+		p.wg.Add(1)
+		go p.run(sink)
+	}
+
+	if p.options.ReportInterval > 0 {
+		p.wg.Add(1)
+		go p.reportDrops()
+	}
+	return nil
+}
+
+func (p *pipeline) run(sink *pipelineSink) {
+	defer p.wg.Done()
+	for {
+		evt, ok := sink.queue.pop()
+		if !ok {
+			return
+		}
+		start := time.Now()
+		if err := sink.sink.write(evt); err != nil {
+			logrus.Errorf("couldn't write %s event to sink %s: %v", evt.eventType, sink.name, err)
+		}
+		if p.latency != nil {
+			end := time.Now()
+			p.latency.observe(LatencyStageQueue, start.Sub(evt.queued), sink.name)
+			p.latency.observe(LatencyStageSinkWrite, end.Sub(start), sink.name)
+			if !evt.emitted.IsZero() {
+				p.latency.observe(LatencyStageEndToEnd, end.Sub(evt.emitted), sink.name)
+			}
+		}
+	}
+}
+
+// push queues an event in each sink, in the format of the sink. jsonData is the native JSON representation of the
+// event, each other format is computed once.
+func (p *pipeline) push(event *events.Event, jsonData []byte) {
+	priority := p.options.Priority(event.Kernel.Type)
+	formatted := map[OutputFormat][]byte{
+		JSONFormat: jsonData,
+	}
+	for _, sink := range p.sinks {
+		if len(sink.tenant) > 0 {
+			continue
+		}
+		if len(sink.minConfidence) > 0 && !event.Policy.Confidence.AtLeast(sink.minConfidence) {
+			continue
+		}
+		data, ok := formatted[sink.format]
+		if !ok {
+			var err error
+			if data, err = formatEvent(sink.format, event, jsonData); err != nil {
+				logrus.Errorf("couldn't format %s event for sink %s: %v", event.Kernel.Type, sink.name, err)
+			}
+			formatted[sink.format] = data
+		}
+		if data == nil {
+			continue
+		}
+		sink.queue.pushEvent(&queuedEvent{
+			id:        event.ID,
+			eventType: event.Kernel.Type,
+			priority:  priority,
+			data:      data,
+			emitted:   event.Kernel.Time,
+		})
+	}
+}
+
+// pushTenant queues an ingested event in the sinks of its tenant, the event is queued as it was received
+func (p *pipeline) pushTenant(tenant string, evt *queuedEvent) {
+	evt.priority = p.options.Priority(evt.eventType)
+	for _, sink := range p.sinks {
+		if sink.tenant != tenant {
+			continue
+		}
+		sink.queue.pushEvent(&queuedEvent{
+			id:        evt.id,
+			eventType: evt.eventType,
+			priority:  evt.priority,
+			data:      evt.data,
+		})
+	}
+}
+
+func (p *pipeline) reportDrops() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(time.Duration(p.options.ReportInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.logDrops()
+		}
+	}
+}
+
+// logDrops reports the events dropped since the last report
+func (p *pipeline) logDrops() {
+	for _, sink := range p.sinks {
+		for eventType, count := range sink.queue.flushDropped() {
+			if p.metrics != nil {
+				p.metrics.add(droppedEventsMetricName, droppedEventsMetricHelp, count, "sink", sink.name, "event_type", eventType.String())
+			}
+			logrus.Warnf("sink %s dropped %d %s event(s): queue full (queue_size: %d, drop_policy: %s)", sink.name, count, eventType, sink.queue.size, sink.queue.policy)
+		}
+		if reporter, ok := sink.sink.(sinkStatsReporter); ok {
+			reporter.logStats()
+		}
+	}
+}
+
+// close flushes the queued events, stops the pipeline and closes the sinks
+func (p *pipeline) close() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+		for _, sink := range p.sinks {
+			sink.queue.close()
+		}
+		p.wg.Wait()
+		p.logDrops()
+
+		for _, sink := range p.sinks {
+			if err := sink.sink.close(); err != nil {
+				logrus.Errorf("couldn't close sink %s: %v", sink.name, err)
+			}
+		}
+	})
+}
@@ -18,6 +18,11 @@ package krie
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
 )
 
 func (e *KRIE) loadPolicies() error {
@@ -29,3 +34,181 @@ func (e *KRIE) loadPolicies() error {
 	}
 	return nil
 }
+
+const (
+	PolicyStatusLoaded   = "loaded"
+	PolicyStatusApplied  = "applied"
+	PolicyStatusRejected = "rejected"
+	PolicyStatusFailed   = "failed"
+	// PolicyStatusMonitored is the status of the initial policies when their approval expired: they are loaded with
+	// their enforced rules in monitor mode
+	PolicyStatusMonitored = "monitored"
+)
+
+// monitorEnforcedRules switches the enforced rules of the initial policies to monitor mode
+func (e *KRIE) monitorEnforcedRules() error {
+	var err error
+	policies := e.options.Events
+	rules := policies.EnforcedRules()
+	for _, rule := range rules {
+		if policies, err = policies.WithRuleMode(rule, events.MonitorMode); err != nil {
+			return err
+		}
+	}
+	if e.policyHash, err = policies.Hash(); err != nil {
+		return err
+	}
+	e.options.Events = policies
+	e.monitoredRules = rules
+	logrus.Warnf("the approval of the policies expired, rules started in monitor mode: %s", strings.Join(rules, ", "))
+	return nil
+}
+
+// currentPolicies returns the active policies, their version and their hash
+func (e *KRIE) currentPolicies() (*events.Options, string, string) {
+	e.policyLock.RLock()
+	defer e.policyLock.RUnlock()
+	return e.options.Events, e.options.PolicyVersion, e.policyHash
+}
+
+// UpdatePolicies replaces the active policies. Depending on the approval configuration, the change must be approved
+// by the provided approval tokens. Every call generates a policy_change audit event.
+func (e *KRIE) UpdatePolicies(version string, policies *events.Options, tokens []string) error {
+	event := events.NewEvent()
+	event.Kernel.Type = events.PolicyChangeEventType
+
+	err := e.updatePolicies(version, policies, tokens, &event.PolicyChangeEvent)
+	if err != nil {
+		event.PolicyChangeEvent.Error = err.Error()
+	}
+	if len(event.PolicyChangeEvent.Status) > 0 {
+		if emitErr := e.emitEvent(event); emitErr != nil {
+			logrus.Errorf("couldn't emit policy_change event: %v", emitErr)
+		}
+	}
+	return err
+}
+
+// SetRuleMode switches a rule (and its sub rules) between the monitor and enforce modes, without reloading the
+// configuration. The change goes through the same approval and audit as any other policy change.
+func (e *KRIE) SetRuleMode(ruleID string, mode events.RuleMode, tokens []string) error {
+	current, version, _ := e.currentPolicies()
+	policies, err := current.WithRuleMode(ruleID, mode)
+	if err != nil {
+		return err
+	}
+	return e.UpdatePolicies(version, policies, tokens)
+}
+
+// RuleModes returns the mode of each rule configured to block, kill or alter the monitored operations
+func (e *KRIE) RuleModes() map[string]events.RuleMode {
+	policies, _, _ := e.currentPolicies()
+	return policies.RuleModes()
+}
+
+func (e *KRIE) updatePolicies(version string, policies *events.Options, tokens []string, change *events.PolicyChangeEvent) error {
+	e.policyLock.Lock()
+	defer e.policyLock.Unlock()
+
+	previous := e.options.Events
+	change.Version = version
+	change.PreviousHash = e.policyHash
+	change.Status = PolicyStatusRejected
+
+	if err := policies.IsValid(); err != nil {
+		return fmt.Errorf("invalid policies: %w", err)
+	}
+	if _, err := resolveSysCtlParameters(policies.SysCtlEvent, procSysPath); err != nil {
+		return fmt.Errorf("invalid policies: invalid sysctl section: %w", err)
+	}
+	if policies.ContainerImages.Action != events.NopAction && e.criResolver == nil {
+		return fmt.Errorf("invalid policies: the container image policy requires the container enrichment (cri section)")
+	}
+	if e.moduleUnloadDropped && unloadsModules(policies) {
+		return fmt.Errorf("invalid policies: unloading the denied modules requires a restart, the hardening dropped the privileges required to unload modules")
+	}
+	hash, err := policies.Hash()
+	if err != nil {
+		return err
+	}
+	change.Hash = hash
+	if hash == e.policyHash && version == e.options.PolicyVersion {
+		// nothing to do
+		change.Status = ""
+		return nil
+	}
+
+	// the probes of an event type are selected at startup
+	if !sameEventTypes(previous.ActivatedEventTypes(), policies.ActivatedEventTypes()) {
+		return fmt.Errorf("enabling or disabling an event type requires a restart")
+	}
+	if len(previous.KernelParameterEvent.List) != len(policies.KernelParameterEvent.List) || previous.KernelParameterEvent.Ticker != policies.KernelParameterEvent.Ticker {
+		return fmt.Errorf("changing the kernel parameters count or ticker requires a restart")
+	}
+
+	change.Escalations = policies.Escalations(previous)
+	if e.approvals.isRequired(change.Escalations) {
+		if change.Approvers, err = e.approvals.verify(hash, tokens); err != nil {
+			return fmt.Errorf("policy change not approved: %w", err)
+		}
+	}
+
+	e.options.Events = policies
+	e.options.PolicyVersion = version
+	e.policyHash = hash
+	if err = e.pushPolicies(previous); err != nil {
+		change.Status = PolicyStatusFailed
+		return err
+	}
+	change.Status = PolicyStatusApplied
+	logrus.Infof("policies updated to %s (version: %s)", hash, version)
+	return nil
+}
+
+// pushPolicies pushes the active policies to the kernel
+func (e *KRIE) pushPolicies(previous *events.Options) error {
+	if e.manager == nil {
+		// the policies will be pushed on start
+		return nil
+	}
+
+	if err := e.loadPolicies(); err != nil {
+		return err
+	}
+	if err := e.loadKernelParameters(); err != nil {
+		return err
+	}
+
+	if err := e.removeSysCtlParameters(previous); err != nil {
+		return err
+	}
+	return e.loadSysCtlParameters()
+}
+
+// removeSysCtlParameters removes the sysctl parameters which are no longer configured
+func (e *KRIE) removeSysCtlParameters(previous *events.Options) error {
+	for _, name := range removedSysCtlParameters(previous.SysCtlEvent, e.options.Events.SysCtlEvent, procSysPath) {
+		b := make([]byte, 256)
+		copy(b, name)
+		if err := e.sysctlParametersMap.Delete(b); err != nil {
+			return fmt.Errorf("failed to delete SysCtlParameter for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func sameEventTypes(a events.EventTypeList, b events.EventTypeList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	types := make(map[events.EventType]bool)
+	for _, eventType := range a {
+		types[eventType] = true
+	}
+	for _, eventType := range b {
+		if !types[eventType] {
+			return false
+		}
+	}
+	return true
+}
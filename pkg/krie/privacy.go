@@ -0,0 +1,106 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PrivacyOptions configures the privacy mode, for the deployments subject to strict telemetry rules: the arguments of
+// the processes aren't captured, and the host identifiers (hostnames, user names) are replaced with a keyed hash
+type PrivacyOptions struct {
+	Enabled bool `yaml:"enabled"`
+	// HashKey is the key of the HMAC-SHA256 of the host identifiers
+	HashKey string `yaml:"hash_key"`
+	// HashKeyFile is a file containing the key, it takes precedence over HashKey
+	HashKeyFile string `yaml:"hash_key_file"`
+}
+
+// IsValid returns an error if the privacy options are invalid
+func (o *PrivacyOptions) IsValid() error {
+	if !o.Enabled {
+		return nil
+	}
+	if len(o.HashKey) == 0 && len(o.HashKeyFile) == 0 {
+		return fmt.Errorf("hash_key or hash_key_file is required")
+	}
+	return nil
+}
+
+// NewPrivacyOptions returns a default set of privacy options
+func NewPrivacyOptions() *PrivacyOptions {
+	return &PrivacyOptions{}
+}
+
+// privacy applies the privacy mode
+type privacy struct {
+	enabled bool
+	key     []byte
+}
+
+func newPrivacy(options *PrivacyOptions) (*privacy, error) {
+	p := &privacy{
+		enabled: options.Enabled,
+		key:     []byte(options.HashKey),
+	}
+	if p.enabled && len(options.HashKeyFile) > 0 {
+		key, err := os.ReadFile(options.HashKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read privacy hash key: %w", err)
+		}
+		p.key = []byte(strings.TrimSpace(string(key)))
+	}
+	return p, nil
+}
+
+// hostPrivacy is the privacy mode of the running instance, the sinks read it to identify the host
+var hostPrivacy = &privacy{}
+
+// captureArgs returns true if the arguments of the processes can be captured
+func (p *privacy) captureArgs() bool {
+	return !p.enabled
+}
+
+// captureArgsConstant returns the value of the krie_capture_args constant of the eBPF programs
+func (p *privacy) captureArgsConstant() uint64 {
+	if p.captureArgs() {
+		return 1
+	}
+	return 0
+}
+
+// identifier returns the provided host identifier (hostname, user name), hashed with the key of the privacy mode when
+// it is enabled. The hash is keyed so that the identifiers can't be recovered from a dictionary of hostnames.
+func (p *privacy) identifier(value string) string {
+	if !p.enabled || len(value) == 0 {
+		return value
+	}
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(value))
+	return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// hostname returns the hostname of the host, hashed in privacy mode
+func (p *privacy) hostname() string {
+	hostname, _ := os.Hostname()
+	return p.identifier(hostname)
+}
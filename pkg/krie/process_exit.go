@@ -0,0 +1,182 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// ProcessExitOptions configures the process_exit events: when a process which triggered events exits, KRIE emits a
+// summary of its events
+type ProcessExitOptions struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is the number of seconds between two checks of the tracked processes
+	Interval int64 `yaml:"interval"`
+	// MaxProcesses is the maximum number of tracked processes, the events of the other processes aren't summarized
+	MaxProcesses int `yaml:"max_processes"`
+}
+
+// IsValid returns an error if the process exit options are invalid
+func (o *ProcessExitOptions) IsValid() error {
+	if !o.Enabled {
+		return nil
+	}
+	if o.Interval <= 0 {
+		return fmt.Errorf("invalid interval: %d", o.Interval)
+	}
+	if o.MaxProcesses <= 0 {
+		return fmt.Errorf("invalid max_processes: %d", o.MaxProcesses)
+	}
+	return nil
+}
+
+// NewProcessExitOptions returns a default set of process exit options
+func NewProcessExitOptions() *ProcessExitOptions {
+	return &ProcessExitOptions{
+		Interval:     5,
+		MaxProcesses: 4096,
+	}
+}
+
+// trackedProcess is a process which triggered events
+type trackedProcess struct {
+	// startTicks is the start time of the process in /proc/[pid]/stat, it tells a process apart from a process which
+	// reused its PID
+	startTicks uint64
+	process    events.ProcessContext
+	summary    events.ProcessExitEvent
+}
+
+// processExitTracker tracks the processes which triggered events, and emits their summary when they exit
+type processExitTracker struct {
+	krie    *KRIE
+	options *ProcessExitOptions
+
+	lock      sync.Mutex
+	processes map[uint32]*trackedProcess
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newProcessExitTracker(krie *KRIE, options *ProcessExitOptions) *processExitTracker {
+	return &processExitTracker{
+		krie:      krie,
+		options:   options,
+		processes: make(map[uint32]*trackedProcess),
+		stop:      make(chan struct{}),
+	}
+}
+
+// processStartTicks returns the start time of a process, in clock ticks since boot
+func processStartTicks(pid uint32) (uint64, error) {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, err
+	}
+	// the comm of the process is between parenthesis, and can contain spaces or parenthesis
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, fmt.Errorf("invalid stat file for %d", pid)
+	}
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 20 {
+		return 0, fmt.Errorf("invalid stat file for %d", pid)
+	}
+	return strconv.ParseUint(fields[19], 10, 64)
+}
+
+// record adds an event to the summary of its process
+func (pe *processExitTracker) record(event *events.Event) {
+	if event.Kernel.Type == events.ProcessExitEventType || !event.HasProcessContext() || event.Process.PID == 0 {
+		return
+	}
+
+	pe.lock.Lock()
+	defer pe.lock.Unlock()
+
+	tracked, ok := pe.processes[event.Process.PID]
+	if !ok {
+		if len(pe.processes) >= pe.options.MaxProcesses {
+			return
+		}
+		tracked = &trackedProcess{}
+		// a process which already exited is reported by the next check
+		tracked.startTicks, _ = processStartTicks(event.Process.PID)
+		pe.processes[event.Process.PID] = tracked
+	}
+	tracked.process = event.Process
+	tracked.summary.Record(event)
+}
+
+func (pe *processExitTracker) start() {
+	pe.wg.Add(1)
+	go func() {
+		defer pe.wg.Done()
+		ticker := time.NewTicker(time.Duration(pe.options.Interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-pe.stop:
+				return
+			case <-ticker.C:
+				pe.check()
+			}
+		}
+	}()
+}
+
+func (pe *processExitTracker) close() {
+	close(pe.stop)
+	pe.wg.Wait()
+}
+
+// check emits the summary of the tracked processes which exited
+func (pe *processExitTracker) check() {
+	var exited []*trackedProcess
+	pe.lock.Lock()
+	for pid, tracked := range pe.processes {
+		if startTicks, err := processStartTicks(pid); err == nil && startTicks == tracked.startTicks {
+			continue
+		}
+		exited = append(exited, tracked)
+		delete(pe.processes, pid)
+	}
+	pe.lock.Unlock()
+
+	now := time.Now()
+	for _, tracked := range exited {
+		event := events.NewEvent()
+		event.Kernel.Type = events.ProcessExitEventType
+		event.Process = tracked.process
+		event.ProcessExitEvent = tracked.summary
+		event.ProcessExitEvent.ExitDetected = now
+		if err := pe.krie.emitEvent(event); err != nil {
+			logrus.Errorf("couldn't emit process_exit event: %v", err)
+		}
+	}
+}
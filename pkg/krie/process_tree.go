@@ -0,0 +1,516 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	// processHistoryFile is the file of the process history, in the state directory
+	processHistoryFile = "process_history.jsonl"
+	// clockTicks is the USER_HZ value used by the start times of /proc/[pid]/stat
+	clockTicks = 100
+)
+
+// ProcessTreeOptions configures the process history, used to resolve the ancestry of the processes which exited
+// before their events were processed
+type ProcessTreeOptions struct {
+	Enabled bool `yaml:"enabled"`
+	// ScanInterval is the interval between two scans of /proc, in milliseconds
+	ScanInterval int64 `yaml:"scan_interval"`
+	// MaxEntries is the maximum number of processes kept in the history
+	MaxEntries int `yaml:"max_entries"`
+	// Retention is the number of seconds an exited process is kept in the history
+	Retention int64 `yaml:"retention"`
+	// MaxAncestors is the maximum number of ancestors added to the process context of the events
+	MaxAncestors int `yaml:"max_ancestors"`
+}
+
+// IsValid returns an error if the process tree options are invalid
+func (o *ProcessTreeOptions) IsValid() error {
+	if !o.Enabled {
+		return nil
+	}
+	if o.ScanInterval <= 0 {
+		return fmt.Errorf("invalid scan_interval: %d", o.ScanInterval)
+	}
+	if o.MaxEntries <= 0 {
+		return fmt.Errorf("invalid max_entries: %d", o.MaxEntries)
+	}
+	if o.Retention <= 0 {
+		return fmt.Errorf("invalid retention: %d", o.Retention)
+	}
+	if o.MaxAncestors < 0 {
+		return fmt.Errorf("invalid max_ancestors: %d", o.MaxAncestors)
+	}
+	return nil
+}
+
+// NewProcessTreeOptions returns a default set of process tree options
+func NewProcessTreeOptions() *ProcessTreeOptions {
+	return &ProcessTreeOptions{
+		ScanInterval: 500,
+		MaxEntries:   100000,
+		Retention:    86400,
+		MaxAncestors: 16,
+	}
+}
+
+// ProcessRecord is an entry of the process history
+type ProcessRecord struct {
+	PID       uint32     `json:"pid"`
+	PPID      uint32     `json:"ppid"`
+	Comm      string     `json:"comm"`
+	Exe       string     `json:"exe,omitempty"`
+	Args      []string   `json:"args,omitempty"`
+	StartTime time.Time  `json:"start_time"`
+	ExitTime  *time.Time `json:"exit_time,omitempty"`
+}
+
+// aliveAt returns true if the process was running at the provided time
+func (r *ProcessRecord) aliveAt(at time.Time) bool {
+	return !r.StartTime.After(at) && (r.ExitTime == nil || !r.ExitTime.Before(at))
+}
+
+// processTree maintains a bounded history of the processes of the host, persisted in the state directory
+type processTree struct {
+	options  *ProcessTreeOptions
+	bootTime time.Time
+	path     string
+	// captureArgs is false in privacy mode, the command lines aren't read from /proc
+	captureArgs bool
+
+	lock    sync.RWMutex
+	records map[uint32][]*ProcessRecord
+	count   int
+	file    *os.File
+	writer  *bufio.Writer
+	written int
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newProcessTree(options *ProcessTreeOptions, stateDirectory string, bootTime time.Time) (*processTree, error) {
+	pt := &processTree{
+		options:     options,
+		bootTime:    bootTime,
+		captureArgs: true,
+		records:     make(map[uint32][]*ProcessRecord),
+		stop:        make(chan struct{}),
+	}
+	if len(stateDirectory) == 0 {
+		// the history is only kept in memory
+		return pt, nil
+	}
+
+	if err := os.MkdirAll(stateDirectory, 0700); err != nil {
+		return nil, fmt.Errorf("couldn't create state directory: %w", err)
+	}
+	pt.path = filepath.Join(stateDirectory, processHistoryFile)
+	if err := pt.load(); err != nil {
+		logrus.Warnf("couldn't load the process history, starting from scratch: %v", err)
+	}
+	pt.prune(time.Now())
+	if err := pt.compact(); err != nil {
+		return nil, err
+	}
+	return pt, nil
+}
+
+// load reads the process history of the previous runs. The history is an append only log: a record is written when a
+// process is discovered, and written again when it exits.
+func (pt *processTree) load() error {
+	f, err := os.Open(pt.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record ProcessRecord
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// ignore a truncated last line
+			continue
+		}
+		if existing := pt.find(record.PID, record.StartTime); existing != nil {
+			existing.ExitTime = record.ExitTime
+		} else {
+			pt.insert(&record)
+		}
+	}
+	return scanner.Err()
+}
+
+// compact rewrites the history file with the records in memory
+func (pt *processTree) compact() error {
+	if len(pt.path) == 0 {
+		return nil
+	}
+	if pt.file != nil {
+		_ = pt.writer.Flush()
+		_ = pt.file.Close()
+	}
+
+	tmp := pt.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("couldn't write the process history: %w", err)
+	}
+	writer := bufio.NewWriter(f)
+	pt.written = 0
+	for _, records := range pt.records {
+		for _, record := range records {
+			if err = writeProcessRecord(writer, record); err != nil {
+				_ = f.Close()
+				return fmt.Errorf("couldn't write the process history: %w", err)
+			}
+			pt.written++
+		}
+	}
+	if err = writer.Flush(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("couldn't write the process history: %w", err)
+	}
+	if err = os.Rename(tmp, pt.path); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("couldn't write the process history: %w", err)
+	}
+	pt.file = f
+	pt.writer = bufio.NewWriter(f)
+	return nil
+}
+
+func writeProcessRecord(writer *bufio.Writer, record *ProcessRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
+
+// persist appends a record to the history file, the caller must hold the lock
+func (pt *processTree) persist(record *ProcessRecord) {
+	if pt.writer == nil {
+		return
+	}
+	if err := writeProcessRecord(pt.writer, record); err != nil {
+		logrus.Debugf("couldn't persist process %d: %v", record.PID, err)
+		return
+	}
+	pt.written++
+}
+
+// find returns the record of the process with the provided PID and start time, the caller must hold the lock
+func (pt *processTree) find(pid uint32, start time.Time) *ProcessRecord {
+	for _, record := range pt.records[pid] {
+		if record.StartTime.Equal(start) {
+			return record
+		}
+	}
+	return nil
+}
+
+// insert adds a record to the history, the caller must hold the lock
+func (pt *processTree) insert(record *ProcessRecord) {
+	records := append(pt.records[record.PID], record)
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].StartTime.Before(records[j].StartTime)
+	})
+	pt.records[record.PID] = records
+	pt.count++
+}
+
+// prune drops the processes which exited before the retention period, then the oldest exited processes until the
+// history fits in MaxEntries. The caller must hold the lock.
+func (pt *processTree) prune(now time.Time) {
+	deadline := now.Add(-time.Duration(pt.options.Retention) * time.Second)
+	var exited []*ProcessRecord
+	for pid, records := range pt.records {
+		kept := records[:0]
+		for _, record := range records {
+			if record.ExitTime != nil && record.ExitTime.Before(deadline) {
+				pt.count--
+				continue
+			}
+			kept = append(kept, record)
+			if record.ExitTime != nil {
+				exited = append(exited, record)
+			}
+		}
+		if len(kept) == 0 {
+			delete(pt.records, pid)
+		} else {
+			pt.records[pid] = kept
+		}
+	}
+
+	if pt.count <= pt.options.MaxEntries {
+		return
+	}
+	sort.Slice(exited, func(i, j int) bool {
+		return exited[i].ExitTime.Before(*exited[j].ExitTime)
+	})
+	for _, record := range exited {
+		if pt.count <= pt.options.MaxEntries {
+			break
+		}
+		records := pt.records[record.PID]
+		for i, r := range records {
+			if r == record {
+				records = append(records[:i], records[i+1:]...)
+				break
+			}
+		}
+		if len(records) == 0 {
+			delete(pt.records, record.PID)
+		} else {
+			pt.records[record.PID] = records
+		}
+		pt.count--
+	}
+}
+
+func (pt *processTree) start() {
+	pt.wg.Add(1)
+	go func() {
+		defer pt.wg.Done()
+		ticker := time.NewTicker(time.Duration(pt.options.ScanInterval) * time.Millisecond)
+		defer ticker.Stop()
+		pt.scan()
+		for {
+			select {
+			case <-pt.stop:
+				return
+			case <-ticker.C:
+				pt.scan()
+			}
+		}
+	}()
+}
+
+func (pt *processTree) close() {
+	close(pt.stop)
+	pt.wg.Wait()
+
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	if pt.file != nil {
+		_ = pt.writer.Flush()
+		_ = pt.file.Close()
+		pt.file = nil
+		pt.writer = nil
+	}
+}
+
+// scan records the processes created and the processes exited since the previous scan
+func (pt *processTree) scan() {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		logrus.Debugf("couldn't scan /proc: %v", err)
+		return
+	}
+	now := time.Now()
+	seen := make(map[*ProcessRecord]bool, len(entries))
+
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+
+	for _, entry := range entries {
+		pid, err := strconv.ParseUint(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		record, err := pt.readProcess(uint32(pid))
+		if err != nil {
+			continue
+		}
+		if existing := pt.find(record.PID, record.StartTime); existing != nil {
+			seen[existing] = true
+			continue
+		}
+		pt.insert(record)
+		pt.persist(record)
+		seen[record] = true
+	}
+
+	for _, records := range pt.records {
+		for _, record := range records {
+			if record.ExitTime == nil && !seen[record] {
+				exit := now
+				record.ExitTime = &exit
+				pt.persist(record)
+			}
+		}
+	}
+
+	pt.prune(now)
+	if pt.written > 2*pt.options.MaxEntries {
+		if err = pt.compact(); err != nil {
+			logrus.Warnf("%v", err)
+		}
+	} else if pt.writer != nil {
+		_ = pt.writer.Flush()
+	}
+}
+
+// readProcess reads a process from /proc
+func (pt *processTree) readProcess(pid uint32) (*ProcessRecord, error) {
+	procDir := fmt.Sprintf("/proc/%d", pid)
+	stat, err := os.ReadFile(filepath.Join(procDir, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	// the comm of the process is between parenthesis, and can contain spaces or parenthesis
+	start := bytes.IndexByte(stat, '(')
+	end := bytes.LastIndexByte(stat, ')')
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("invalid stat file for %d", pid)
+	}
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 20 {
+		return nil, fmt.Errorf("invalid stat file for %d", pid)
+	}
+	ppid, err := strconv.ParseUint(fields[1], 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	ticks, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &ProcessRecord{
+		PID:       pid,
+		PPID:      uint32(ppid),
+		Comm:      string(stat[start+1 : end]),
+		StartTime: pt.bootTime.Add(time.Duration(ticks) * time.Second / clockTicks),
+	}
+	record.Exe, _ = os.Readlink(filepath.Join(procDir, "exe"))
+	if !pt.captureArgs {
+		return record, nil
+	}
+	if cmdline, err := os.ReadFile(filepath.Join(procDir, "cmdline")); err == nil && len(cmdline) > 0 {
+		record.Args = strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+	}
+	return record, nil
+}
+
+// lookup returns the process with the provided PID at the provided time, the caller must hold the lock
+func (pt *processTree) lookup(pid uint32, at time.Time) *ProcessRecord {
+	records := pt.records[pid]
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].aliveAt(at) {
+			return records[i]
+		}
+	}
+	return nil
+}
+
+// ancestry returns the ancestors of a process, starting with its parent. The caller must hold the lock.
+func (pt *processTree) ancestry(record *ProcessRecord, max int) []*ProcessRecord {
+	var ancestors []*ProcessRecord
+	visited := map[uint32]bool{record.PID: true}
+	for len(ancestors) < max && record.PPID != 0 && !visited[record.PPID] {
+		// the parent was running when its child started
+		parent := pt.lookup(record.PPID, record.StartTime)
+		if parent == nil {
+			break
+		}
+		visited[parent.PID] = true
+		ancestors = append(ancestors, parent)
+		record = parent
+	}
+	return ancestors
+}
+
+// enrich adds the executable and the ancestors of the process of an event. The processes which weren't seen by the
+// scans yet are read from /proc, or recorded from the process context of the event if they already exited.
+func (pt *processTree) enrich(pc *events.ProcessContext, at time.Time) {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+
+	record := pt.lookup(pc.PID, at)
+	if record == nil {
+		var err error
+		if record, err = pt.readProcess(pc.PID); err != nil || !record.aliveAt(at) {
+			record = &ProcessRecord{
+				PID:       pc.PID,
+				Comm:      pc.Comm,
+				Args:      append([]string(nil), pc.Args...),
+				StartTime: at,
+			}
+		}
+		pt.insert(record)
+		pt.persist(record)
+	}
+
+	pc.Exe = record.Exe
+	for _, ancestor := range pt.ancestry(record, pt.options.MaxAncestors) {
+		pc.Ancestors = append(pc.Ancestors, events.ProcessAncestor{
+			PID:  ancestor.PID,
+			Comm: ancestor.Comm,
+			Exe:  ancestor.Exe,
+		})
+	}
+}
+
+// LookupProcess returns the process with the provided PID at the provided time (now if the time is zero), along with
+// its ancestors. The process history must be enabled.
+func (e *KRIE) LookupProcess(pid uint32, at time.Time) (*ProcessRecord, []ProcessRecord, error) {
+	if e.processTree == nil {
+		return nil, nil, fmt.Errorf("the process history is disabled")
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	e.processTree.lock.RLock()
+	defer e.processTree.lock.RUnlock()
+
+	record := e.processTree.lookup(pid, at)
+	if record == nil {
+		return nil, nil, nil
+	}
+	out := *record
+	var ancestors []ProcessRecord
+	for _, ancestor := range e.processTree.ancestry(record, e.options.ProcessTree.MaxAncestors) {
+		ancestors = append(ancestors, *ancestor)
+	}
+	return &out, ancestors, nil
+}
@@ -0,0 +1,238 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	// ProfileTriggerRSS is the trigger of the profiles written when the RSS crosses its threshold
+	ProfileTriggerRSS = "rss"
+	// ProfileTriggerGoroutines is the trigger of the profiles written when the goroutine count crosses its threshold
+	ProfileTriggerGoroutines = "goroutines"
+
+	// profilesDirectory is the directory of the profiles, in the state directory
+	profilesDirectory = "profiles"
+)
+
+// ProfilingOptions configures the automatic profiles: when the RSS or the goroutine count of KRIE crosses its
+// threshold, a heap and a goroutine profile are written to the state directory and a "profile_snapshot" event is
+// emitted, so that the leaks of the long-running agents can be debugged post-hoc
+type ProfilingOptions struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is the number of seconds between two checks
+	Interval int64 `yaml:"interval"`
+	// MaxRSS is the RSS threshold, in MiB, 0 disables the threshold
+	MaxRSS uint64 `yaml:"max_rss"`
+	// MaxGoroutines is the goroutine count threshold, 0 disables the threshold
+	MaxGoroutines uint64 `yaml:"max_goroutines"`
+	// Cooldown is the minimum number of seconds between two snapshots
+	Cooldown int64 `yaml:"cooldown"`
+	// MaxSnapshots is the number of snapshots kept in the state directory, the oldest ones are removed
+	MaxSnapshots int `yaml:"max_snapshots"`
+}
+
+// IsValid returns an error if the profiling options are invalid
+func (o *ProfilingOptions) IsValid() error {
+	if !o.Enabled {
+		return nil
+	}
+	if o.Interval <= 0 {
+		return fmt.Errorf("invalid interval: %d", o.Interval)
+	}
+	if o.Cooldown < 0 {
+		return fmt.Errorf("invalid cooldown: %d", o.Cooldown)
+	}
+	if o.MaxSnapshots <= 0 {
+		return fmt.Errorf("invalid max_snapshots: %d", o.MaxSnapshots)
+	}
+	return nil
+}
+
+// NewProfilingOptions returns a default set of profiling options
+func NewProfilingOptions() *ProfilingOptions {
+	return &ProfilingOptions{
+		Interval:      30,
+		MaxRSS:        1024,
+		MaxGoroutines: 10000,
+		Cooldown:      3600,
+		MaxSnapshots:  5,
+	}
+}
+
+// profiler watches the resources of KRIE and writes the profiles when they cross their thresholds
+type profiler struct {
+	options   *ProfilingOptions
+	krie      *KRIE
+	directory string
+	last      time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newProfiler(krie *KRIE, options *ProfilingOptions, stateDirectory string) (*profiler, error) {
+	if len(stateDirectory) == 0 {
+		return nil, fmt.Errorf("the profiles require a state directory")
+	}
+	return &profiler{
+		options:   options,
+		krie:      krie,
+		directory: filepath.Join(stateDirectory, profilesDirectory),
+		stop:      make(chan struct{}),
+	}, nil
+}
+
+func (p *profiler) start() {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(time.Duration(p.options.Interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case now := <-ticker.C:
+				p.check(now)
+			}
+		}
+	}()
+}
+
+func (p *profiler) close() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+// readRSS returns the resident set size of the current process, in bytes
+func readRSS() (uint64, error) {
+	statm, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(statm))
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("invalid statm: %s", statm)
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return pages * uint64(os.Getpagesize()), nil
+}
+
+// check writes the profiles if a resource crossed its threshold, at most once per cooldown
+func (p *profiler) check(now time.Time) {
+	if !p.last.IsZero() && now.Sub(p.last) < time.Duration(p.options.Cooldown)*time.Second {
+		return
+	}
+
+	rss, err := readRSS()
+	if err != nil {
+		logrus.Debugf("couldn't read RSS: %v", err)
+	}
+	snapshot := events.ProfileSnapshotEvent{
+		RSS:        rss,
+		Goroutines: uint64(runtime.NumGoroutine()),
+	}
+	switch {
+	case p.options.MaxRSS > 0 && rss > p.options.MaxRSS<<20:
+		snapshot.Trigger = ProfileTriggerRSS
+		snapshot.Value = rss
+		snapshot.Threshold = p.options.MaxRSS << 20
+	case p.options.MaxGoroutines > 0 && snapshot.Goroutines > p.options.MaxGoroutines:
+		snapshot.Trigger = ProfileTriggerGoroutines
+		snapshot.Value = snapshot.Goroutines
+		snapshot.Threshold = p.options.MaxGoroutines
+	default:
+		return
+	}
+	p.last = now
+
+	snapshot.Profiles, err = p.write(now)
+	if err != nil {
+		snapshot.Error = err.Error()
+		logrus.Errorf("couldn't write profiles: %v", err)
+	}
+	p.prune()
+
+	event := events.NewEvent()
+	event.Kernel.Type = events.ProfileSnapshotEventType
+	event.ProfileSnapshotEvent = snapshot
+	if err = p.krie.emitEvent(event); err != nil {
+		logrus.Errorf("couldn't emit profile snapshot event: %v", err)
+	}
+}
+
+// write writes the heap and the goroutine profiles, in the pprof format
+func (p *profiler) write(now time.Time) ([]string, error) {
+	if err := os.MkdirAll(p.directory, 0700); err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, name := range []string{"heap", "goroutine"} {
+		var buf bytes.Buffer
+		if err := pprof.Lookup(name).WriteTo(&buf, 0); err != nil {
+			return paths, fmt.Errorf("couldn't collect %s profile: %w", name, err)
+		}
+		path := filepath.Join(p.directory, fmt.Sprintf("%s-%s.pprof", now.UTC().Format("20060102T150405Z"), name))
+		if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// prune removes the oldest snapshots, the names of the profiles start with their timestamp
+func (p *profiler) prune() {
+	entries, err := os.ReadDir(p.directory)
+	if err != nil {
+		return
+	}
+	snapshots := make(map[string][]string)
+	for _, entry := range entries {
+		timestamp := strings.SplitN(entry.Name(), "-", 2)[0]
+		snapshots[timestamp] = append(snapshots[timestamp], entry.Name())
+	}
+	timestamps := make([]string, 0, len(snapshots))
+	for timestamp := range snapshots {
+		timestamps = append(timestamps, timestamp)
+	}
+	sort.Strings(timestamps)
+	for i := 0; i < len(timestamps)-p.options.MaxSnapshots; i++ {
+		for _, name := range snapshots[timestamps[i]] {
+			_ = os.Remove(filepath.Join(p.directory, name))
+		}
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// DropPolicy defines what a queue does when it is full
+type DropPolicy string
+
+const (
+	// DropOldest evicts the oldest queued event of the lowest priority
+	DropOldest DropPolicy = "drop_oldest"
+	// DropNewest drops the incoming event, unless it has a higher priority than a queued event
+	DropNewest DropPolicy = "drop_newest"
+	// Block waits until the queue has room for the incoming event
+	Block DropPolicy = "block"
+)
+
+// UnmarshalYAML parses a drop policy
+func (dp *DropPolicy) UnmarshalYAML(value *yaml.Node) error {
+	var policy string
+	if err := value.Decode(&policy); err != nil {
+		return fmt.Errorf("failed to parse drop policy: %w", err)
+	}
+	switch DropPolicy(policy) {
+	case DropOldest, DropNewest, Block:
+		*dp = DropPolicy(policy)
+	case "":
+		*dp = DropOldest
+	default:
+		return fmt.Errorf("unknown drop policy: %s", policy)
+	}
+	return nil
+}
+
+// queuedEvent is a serialized event waiting to be written to a sink
+type queuedEvent struct {
+	seq       uint64
+	id        string
+	eventType events.EventType
+	priority  int
+	data      []byte
+
+	// emitted is the time at which the event was emitted, and queued the time at which it was queued
+	emitted time.Time
+	queued  time.Time
+}
+
+// eventQueue is a bounded FIFO queue which drops events according to a DropPolicy and to the priority of each event
+// type: an event is never dropped in favor of an event with a lower priority.
+type eventQueue struct {
+	sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+
+	size   int
+	policy DropPolicy
+	closed bool
+	seq    uint64
+	count  int
+
+	// levels holds one FIFO per priority, priorities is kept sorted in ascending order
+	levels     map[int][]*queuedEvent
+	priorities []int
+
+	dropped map[events.EventType]uint64
+}
+
+func newEventQueue(size int, policy DropPolicy) *eventQueue {
+	q := &eventQueue{
+		size:    size,
+		policy:  policy,
+		levels:  make(map[int][]*queuedEvent),
+		dropped: make(map[events.EventType]uint64),
+	}
+	q.notEmpty = sync.NewCond(q)
+	q.notFull = sync.NewCond(q)
+	return q
+}
+
+// lowestPriority returns the lowest priority currently queued
+func (q *eventQueue) lowestPriority() int {
+	for _, p := range q.priorities {
+		if len(q.levels[p]) > 0 {
+			return p
+		}
+	}
+	return 0
+}
+
+// push adds an event to the queue, it returns false if the event was dropped
+func (q *eventQueue) push(id string, eventType events.EventType, priority int, data []byte) bool {
+	return q.pushEvent(&queuedEvent{
+		id:        id,
+		eventType: eventType,
+		priority:  priority,
+		data:      data,
+	})
+}
+
+// pushEvent adds an event to the queue, it returns false if the event was dropped
+func (q *eventQueue) pushEvent(evt *queuedEvent) bool {
+	eventType, priority := evt.eventType, evt.priority
+	q.Lock()
+	defer q.Unlock()
+
+	if q.closed {
+		return false
+	}
+
+	for q.count >= q.size {
+		lowest := q.lowestPriority()
+		if priority < lowest {
+			// never drop a queued event in favor of a lower priority one
+			if q.policy == Block {
+				q.notFull.Wait()
+				if q.closed {
+					return false
+				}
+				continue
+			}
+			q.dropped[eventType]++
+			return false
+		}
+
+		switch q.policy {
+		case Block:
+			q.notFull.Wait()
+			if q.closed {
+				return false
+			}
+			continue
+		case DropNewest:
+			if priority == lowest {
+				q.dropped[eventType]++
+				return false
+			}
+			// evict the newest event of the lowest priority
+			level := q.levels[lowest]
+			q.dropped[level[len(level)-1].eventType]++
+			q.levels[lowest] = level[:len(level)-1]
+		default:
+			// evict the oldest event of the lowest priority
+			level := q.levels[lowest]
+			q.dropped[level[0].eventType]++
+			level[0] = nil
+			q.levels[lowest] = level[1:]
+		}
+		q.count--
+	}
+
+	if _, ok := q.levels[priority]; !ok {
+		q.priorities = append(q.priorities, priority)
+		sort.Ints(q.priorities)
+	}
+	q.seq++
+	evt.seq = q.seq
+	evt.queued = time.Now()
+	q.levels[priority] = append(q.levels[priority], evt)
+	q.count++
+	q.notEmpty.Signal()
+	return true
+}
+
+// pop returns the oldest queued event, it blocks until an event is available or the queue is closed and empty
+func (q *eventQueue) pop() (*queuedEvent, bool) {
+	q.Lock()
+	defer q.Unlock()
+
+	for q.count == 0 {
+		if q.closed {
+			return nil, false
+		}
+		q.notEmpty.Wait()
+	}
+
+	var next int
+	var out *queuedEvent
+	for _, p := range q.priorities {
+		level := q.levels[p]
+		if len(level) > 0 && (out == nil || level[0].seq < out.seq) {
+			out = level[0]
+			next = p
+		}
+	}
+	q.levels[next][0] = nil
+	q.levels[next] = q.levels[next][1:]
+	q.count--
+	q.notFull.Signal()
+	return out, true
+}
+
+// close wakes up all the waiting producers and consumers, queued events can still be popped
+func (q *eventQueue) close() {
+	q.Lock()
+	defer q.Unlock()
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+}
+
+// flushDropped returns and resets the drop counters of the queue
+func (q *eventQueue) flushDropped() map[events.EventType]uint64 {
+	q.Lock()
+	defer q.Unlock()
+	out := q.dropped
+	q.dropped = make(map[events.EventType]uint64)
+	return out
+}
@@ -0,0 +1,70 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+func popAll(q *eventQueue) []string {
+	q.close()
+	var out []string
+	for {
+		evt, ok := q.pop()
+		if !ok {
+			return out
+		}
+		out = append(out, string(evt.data))
+	}
+}
+
+func TestEventQueueDropOldest(t *testing.T) {
+	q := newEventQueue(2, DropOldest)
+	assert.True(t, q.push("", events.BPFEventType, 0, []byte("a")))
+	assert.True(t, q.push("", events.BPFEventType, 0, []byte("b")))
+	assert.True(t, q.push("", events.BPFEventType, 0, []byte("c")))
+
+	assert.Equal(t, map[events.EventType]uint64{events.BPFEventType: 1}, q.flushDropped())
+	assert.Equal(t, []string{"b", "c"}, popAll(q))
+}
+
+func TestEventQueueDropNewest(t *testing.T) {
+	q := newEventQueue(2, DropNewest)
+	assert.True(t, q.push("", events.BPFEventType, 0, []byte("a")))
+	assert.True(t, q.push("", events.BPFEventType, 0, []byte("b")))
+	assert.False(t, q.push("", events.BPFEventType, 0, []byte("c")))
+
+	assert.Equal(t, map[events.EventType]uint64{events.BPFEventType: 1}, q.flushDropped())
+	assert.Equal(t, []string{"a", "b"}, popAll(q))
+}
+
+func TestEventQueuePriority(t *testing.T) {
+	q := newEventQueue(2, DropNewest)
+	assert.True(t, q.push("", events.HookedSyscallEventType, 10, []byte("a")))
+	assert.True(t, q.push("", events.BPFEventType, 0, []byte("b")))
+	// a high priority event evicts the low priority one
+	assert.True(t, q.push("", events.HookedSyscallEventType, 10, []byte("c")))
+	// a low priority event never evicts a high priority one
+	assert.False(t, q.push("", events.BPFEventType, 0, []byte("d")))
+
+	assert.Equal(t, map[events.EventType]uint64{events.BPFEventType: 2}, q.flushDropped())
+	assert.Equal(t, []string{"a", "c"}, popAll(q))
+}
@@ -0,0 +1,157 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// RedactionAction defines how a field is redacted
+type RedactionAction string
+
+const (
+	// RedactionDrop removes the field from the event
+	RedactionDrop RedactionAction = "drop"
+	// RedactionHash replaces the field with a salted SHA-256 hash of its value
+	RedactionHash RedactionAction = "hash"
+	// RedactionTruncate truncates the field to RedactionOptions.TruncateLength bytes
+	RedactionTruncate RedactionAction = "truncate"
+)
+
+// UnmarshalYAML parses a redaction action
+func (ra *RedactionAction) UnmarshalYAML(value *yaml.Node) error {
+	var action string
+	if err := value.Decode(&action); err != nil {
+		return fmt.Errorf("failed to parse redaction action: %w", err)
+	}
+	switch RedactionAction(action) {
+	case RedactionDrop, RedactionHash, RedactionTruncate:
+		*ra = RedactionAction(action)
+	default:
+		return fmt.Errorf("unknown redaction action: %s", action)
+	}
+	return nil
+}
+
+// RedactionOptions configures the fields redacted before the events are sent to the sinks
+type RedactionOptions struct {
+	Fields         map[string]RedactionAction `yaml:"fields"`
+	TruncateLength int                        `yaml:"truncate_length"`
+	HashSalt       string                     `yaml:"hash_salt"`
+}
+
+// IsValid returns an error if the redaction options are invalid
+func (o *RedactionOptions) IsValid() error {
+	for field, action := range o.Fields {
+		if _, ok := events.RedactableFields[field]; !ok {
+			return fmt.Errorf("unknown redactable field: %s", field)
+		}
+		if action == RedactionTruncate && o.TruncateLength <= 0 {
+			return fmt.Errorf("invalid truncate_length for %s: %d", field, o.TruncateLength)
+		}
+	}
+	return nil
+}
+
+// NewRedactionOptions returns a default set of redaction options
+func NewRedactionOptions() *RedactionOptions {
+	return &RedactionOptions{
+		TruncateLength: 16,
+	}
+}
+
+type redactionRule struct {
+	name   string
+	field  events.RedactableField
+	action RedactionAction
+}
+
+// redactor applies the redaction rules to an event
+type redactor struct {
+	options *RedactionOptions
+	rules   []redactionRule
+}
+
+func newRedactor(options *RedactionOptions) *redactor {
+	r := &redactor{
+		options: options,
+	}
+	for name, action := range options.Fields {
+		r.rules = append(r.rules, redactionRule{
+			name:   name,
+			field:  events.RedactableFields[name],
+			action: action,
+		})
+	}
+	// keep the list of redacted fields of an event stable
+	sort.Slice(r.rules, func(i, j int) bool {
+		return r.rules[i].name < r.rules[j].name
+	})
+	return r
+}
+
+// redact applies the redaction rules to the provided event. Redacted fields are listed in the event so that an analyst
+// knows that the data was removed, and not absent.
+func (r *redactor) redact(event *events.Event) {
+	for _, rule := range r.rules {
+		if !rule.field.AppliesTo(event.Kernel.Type) {
+			continue
+		}
+		values := rule.field.Get(event)
+		if len(values) == 0 {
+			continue
+		}
+
+		switch rule.action {
+		case RedactionDrop:
+			values = nil
+		case RedactionHash:
+			values = r.mapValues(values, r.hash)
+		case RedactionTruncate:
+			values = r.mapValues(values, r.truncate)
+		}
+		rule.field.Set(event, values)
+		event.Redacted = append(event.Redacted, rule.name)
+	}
+}
+
+func (r *redactor) mapValues(values []string, f func(string) string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		out = append(out, f(value))
+	}
+	return out
+}
+
+func (r *redactor) hash(value string) string {
+	h := sha256.Sum256([]byte(r.options.HashSalt + value))
+	return "sha256:" + hex.EncodeToString(h[:])
+}
+
+func (r *redactor) truncate(value string) string {
+	if len(value) <= r.options.TruncateLength {
+		return value
+	}
+	return value[:r.options.TruncateLength]
+}
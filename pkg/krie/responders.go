@@ -0,0 +1,256 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	// responderQueueSize is the number of pending triggers of a responder
+	responderQueueSize = 64
+
+	ResponseStatusSuccess = "success"
+	ResponseStatusFailure = "failure"
+	ResponseStatusTimeout = "timeout"
+	ResponseStatusError   = "error"
+)
+
+// ResponderOptions configures a responder: a local script or binary executed when one of its rules matches an event.
+// The event is written in JSON on the standard input of the command.
+type ResponderOptions struct {
+	Name      string   `yaml:"name"`
+	Rules     []string `yaml:"rules"`
+	Command   string   `yaml:"command"`
+	Args      []string `yaml:"args"`
+	Timeout   int64    `yaml:"timeout"`
+	RateLimit int      `yaml:"rate_limit"`
+	// MinConfidence is the minimum confidence of the events triggering the responder, empty to ignore the confidence
+	MinConfidence events.Confidence `yaml:"min_confidence"`
+}
+
+// IsValid returns an error if the responder options are invalid
+func (o *ResponderOptions) IsValid() error {
+	if len(o.Name) == 0 {
+		return fmt.Errorf("empty name")
+	}
+	if len(o.Command) == 0 {
+		return fmt.Errorf("empty command")
+	}
+	if len(o.Rules) == 0 {
+		return fmt.Errorf("empty rules")
+	}
+	if o.Timeout <= 0 {
+		return fmt.Errorf("invalid timeout: %d", o.Timeout)
+	}
+	if o.RateLimit < 0 {
+		return fmt.Errorf("invalid rate_limit: %d", o.RateLimit)
+	}
+	if len(o.MinConfidence) > 0 {
+		if err := o.MinConfidence.IsValid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the responder should be triggered by the provided rule ID
+func (o *ResponderOptions) Matches(ruleID string) bool {
+	return matchRules(o.Rules, ruleID)
+}
+
+// matchRules returns true if one of the rules matches the provided rule ID. A rule matches its own ID and the IDs of
+// its sub rules ("sysctl" matches "sysctl/kernel/yama/ptrace_scope").
+func matchRules(rules []string, ruleID string) bool {
+	for _, rule := range rules {
+		if ruleID == rule || strings.HasPrefix(ruleID, rule+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+type responseRequest struct {
+	eventID string
+	ruleID  string
+	data    []byte
+	skipped uint64
+}
+
+// responder runs a command when one of its rules matches an event
+type responder struct {
+	options *ResponderOptions
+	krie    *KRIE
+	queue   chan responseRequest
+
+	lock    sync.Mutex
+	runs    []time.Time
+	skipped uint64
+}
+
+func newResponder(krie *KRIE, options *ResponderOptions) *responder {
+	return &responder{
+		options: options,
+		krie:    krie,
+		queue:   make(chan responseRequest, responderQueueSize),
+	}
+}
+
+// trigger schedules a run of the responder, unless the rate limit was reached
+func (r *responder) trigger(eventID string, ruleID string, data []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	if r.options.RateLimit > 0 {
+		// only keep the runs of the last minute
+		cursor := 0
+		for cursor < len(r.runs) && now.Sub(r.runs[cursor]) > time.Minute {
+			cursor++
+		}
+		r.runs = r.runs[cursor:]
+
+		if len(r.runs) >= r.options.RateLimit {
+			r.skipped++
+			logrus.Debugf("responder %s: rate limit reached, skipping trigger from %s", r.options.Name, eventID)
+			return
+		}
+	}
+
+	select {
+	case r.queue <- responseRequest{eventID: eventID, ruleID: ruleID, data: data, skipped: r.skipped}:
+		r.runs = append(r.runs, now)
+		r.skipped = 0
+	default:
+		r.skipped++
+		logrus.Warnf("responder %s: too many pending runs, skipping trigger from %s", r.options.Name, eventID)
+	}
+}
+
+func (r *responder) run(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for req := range r.queue {
+		r.execute(req)
+	}
+}
+
+func (r *responder) execute(req responseRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.options.Timeout)*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, r.options.Command, r.options.Args...)
+	cmd.Stdin = bytes.NewReader(req.data)
+	cmd.Env = append(os.Environ(), "KRIE_EVENT_ID="+req.eventID, "KRIE_RULE_ID="+req.ruleID)
+
+	start := time.Now()
+	err := cmd.Run()
+
+	event := events.NewEvent()
+	event.Kernel.Type = events.ResponseEventType
+	event.ResponseEvent = events.ResponseEvent{
+		Responder:       r.options.Name,
+		Command:         r.options.Command,
+		TriggerEventID:  req.eventID,
+		TriggerRuleID:   req.ruleID,
+		Status:          ResponseStatusSuccess,
+		Duration:        time.Since(start).Milliseconds(),
+		SkippedTriggers: req.skipped,
+	}
+	if cmd.ProcessState != nil {
+		event.ResponseEvent.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		event.ResponseEvent.Status = ResponseStatusTimeout
+		event.ResponseEvent.Error = err.Error()
+	case errors.As(err, &exitErr):
+		event.ResponseEvent.Status = ResponseStatusFailure
+		event.ResponseEvent.Error = err.Error()
+	default:
+		event.ResponseEvent.Status = ResponseStatusError
+		event.ResponseEvent.Error = err.Error()
+	}
+
+	if err != nil {
+		logrus.Warnf("responder %s triggered by %s: %s: %v", r.options.Name, req.eventID, event.ResponseEvent.Status, err)
+	}
+
+	// record the action
+	if err = r.krie.emitEvent(event); err != nil {
+		logrus.Errorf("couldn't emit response event: %v", err)
+	}
+}
+
+// responders dispatches the events to the responders
+type responders struct {
+	list []*responder
+	wg   sync.WaitGroup
+}
+
+func newResponders(krie *KRIE, options []*ResponderOptions) *responders {
+	rs := &responders{}
+	for _, opts := range options {
+		rs.list = append(rs.list, newResponder(krie, opts))
+	}
+	return rs
+}
+
+// match returns the responders triggered by the provided event
+func (rs *responders) match(event *events.Event) []*responder {
+	// responders can't trigger each other
+	if event.Kernel.Type == events.ResponseEventType {
+		return nil
+	}
+
+	var out []*responder
+	for _, r := range rs.list {
+		if r.options.Matches(event.Policy.RuleID) && event.Policy.Confidence.AtLeast(r.options.MinConfidence) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func (rs *responders) start() {
+	for _, r := range rs.list {
+		rs.wg.Add(1)
+		go r.run(&rs.wg)
+	}
+}
+
+// stop waits for the pending runs to complete
+func (rs *responders) stop() {
+	for _, r := range rs.list {
+		close(r.queue)
+	}
+	rs.wg.Wait()
+}
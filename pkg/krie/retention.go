@@ -0,0 +1,185 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	storageCompactedMetricName = "krie_storage_compacted_events_total"
+	storageCompactedMetricHelp = "Events removed from the local store by the retention policies, by event type"
+)
+
+// RetentionOptions configures how long the local store keeps the events of each event type
+type RetentionOptions struct {
+	// Default is the number of hours the events are kept, 0 keeps them forever
+	Default int64 `yaml:"default"`
+	// EventTypes overrides the retention of some event types, in hours, 0 keeps the events of the type forever
+	EventTypes map[string]int64 `yaml:"event_types"`
+	// CompactionInterval is the number of seconds between two compactions of the store
+	CompactionInterval int64 `yaml:"compaction_interval"`
+}
+
+// IsValid returns an error if the retention options are invalid
+func (o *RetentionOptions) IsValid() error {
+	if o.Default < 0 {
+		return fmt.Errorf("invalid default: %d", o.Default)
+	}
+	for name, hours := range o.EventTypes {
+		if events.ParseEventType(name) == events.UnknownEventType {
+			return fmt.Errorf("unknown event type: %s", name)
+		}
+		if hours < 0 {
+			return fmt.Errorf("invalid retention of %s: %d", name, hours)
+		}
+	}
+	if o.CompactionInterval <= 0 {
+		return fmt.Errorf("invalid compaction_interval: %d", o.CompactionInterval)
+	}
+	return nil
+}
+
+// NewRetentionOptions returns a default set of retention options, the events are kept forever
+func NewRetentionOptions() *RetentionOptions {
+	return &RetentionOptions{
+		CompactionInterval: 3600,
+	}
+}
+
+// policy returns the retention policy of the options
+func (o *RetentionOptions) policy() *RetentionPolicy {
+	policy := &RetentionPolicy{
+		Default:    time.Duration(o.Default) * time.Hour,
+		EventTypes: make(map[events.EventType]time.Duration),
+	}
+	for name, hours := range o.EventTypes {
+		policy.EventTypes[events.ParseEventType(name)] = time.Duration(hours) * time.Hour
+	}
+	return policy
+}
+
+// RetentionPolicy is the maximum age of the events of each event type in a Storage, 0 keeps the events forever
+type RetentionPolicy struct {
+	Default    time.Duration
+	EventTypes map[events.EventType]time.Duration
+}
+
+// MaxAge returns the maximum age of the events of the provided type, 0 if they are kept forever
+func (p *RetentionPolicy) MaxAge(eventType events.EventType) time.Duration {
+	if maxAge, ok := p.EventTypes[eventType]; ok {
+		return maxAge
+	}
+	return p.Default
+}
+
+// IsEmpty returns true if the policy keeps every event forever
+func (p *RetentionPolicy) IsEmpty() bool {
+	if p.Default > 0 {
+		return false
+	}
+	for _, maxAge := range p.EventTypes {
+		if maxAge > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Expired returns true if the provided event is expired at the provided time
+func (p *RetentionPolicy) Expired(event *StoredEvent, now time.Time) bool {
+	maxAge := p.MaxAge(event.Type)
+	return maxAge > 0 && event.Time.Before(now.Add(-maxAge))
+}
+
+// StorageCompactor is implemented by the storages which support the retention policies
+type StorageCompactor interface {
+	// Compact removes the events expired at the provided time, and returns the number of removed events of each event
+	// type. Compact may be called concurrently with Send and Query.
+	Compact(policy *RetentionPolicy, now time.Time) (map[events.EventType]int, error)
+}
+
+// storageCompactor periodically removes the expired events of the local store
+type storageCompactor struct {
+	krie     *KRIE
+	storage  StorageCompactor
+	policy   *RetentionPolicy
+	interval time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newStorageCompactor(krie *KRIE, storage Storage, options *RetentionOptions) (*storageCompactor, error) {
+	compactor, ok := storage.(StorageCompactor)
+	if !ok {
+		return nil, fmt.Errorf("the storage doesn't support retention policies")
+	}
+	return &storageCompactor{
+		krie:     krie,
+		storage:  compactor,
+		policy:   options.policy(),
+		interval: time.Duration(options.CompactionInterval) * time.Second,
+		stop:     make(chan struct{}),
+	}, nil
+}
+
+// start compacts the store right away, then every interval
+func (sc *storageCompactor) start() {
+	sc.wg.Add(1)
+	go func() {
+		defer sc.wg.Done()
+		sc.compact()
+
+		ticker := time.NewTicker(sc.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-sc.stop:
+				return
+			case <-ticker.C:
+				sc.compact()
+			}
+		}
+	}()
+}
+
+func (sc *storageCompactor) close() {
+	close(sc.stop)
+	sc.wg.Wait()
+}
+
+func (sc *storageCompactor) compact() {
+	start := time.Now()
+	removed, err := sc.storage.Compact(sc.policy, start)
+	if err != nil {
+		logrus.Errorf("couldn't compact the local store: %v", err)
+		return
+	}
+	var total int
+	for eventType, count := range removed {
+		sc.krie.metrics.add(storageCompactedMetricName, storageCompactedMetricHelp, uint64(count), "event_type", eventType.String())
+		total += count
+	}
+	logrus.Debugf("local store compacted in %s: %d expired event(s) removed", time.Since(start), total)
+}
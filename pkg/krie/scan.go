@@ -0,0 +1,385 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bufio"
+	"context"
+	"debug/elf"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	// maxSyscallNr is the highest syscall number checked in the syscall tables, it matches MAX_SYSCALL_NR in kernel space
+	maxSyscallNr = 448
+
+	HiddenModulesScannerName = "hidden_modules"
+	SyscallTableScannerName  = "syscall_table"
+	InventoryScannerName     = "inventory_diff"
+)
+
+// Finding is a result of an integrity scan
+type Finding struct {
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// ScanResult is the result of a scanner
+type ScanResult struct {
+	Scanner  string    `json:"scanner"`
+	Start    time.Time `json:"start"`
+	Duration int64     `json:"duration_ms"`
+	Clean    bool      `json:"clean"`
+	Findings []Finding `json:"findings"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Scanner is an integrity scanner, run on demand
+type Scanner interface {
+	// Name returns the unique name of the scanner
+	Name() string
+	// Scan runs the scanner and returns its findings, a clean host has no finding
+	Scan(ctx context.Context) ([]Finding, error)
+}
+
+// scanners holds the scanners of a KRIE instance
+type scanners struct {
+	lock sync.RWMutex
+	list map[string]Scanner
+}
+
+func newScanners() *scanners {
+	return &scanners{
+		list: make(map[string]Scanner),
+	}
+}
+
+// RegisterScanner registers a scanner, it can then be run on demand with Scan
+func (e *KRIE) RegisterScanner(scanner Scanner) error {
+	e.scanners.lock.Lock()
+	defer e.scanners.lock.Unlock()
+	if _, ok := e.scanners.list[scanner.Name()]; ok {
+		return fmt.Errorf("scanner %s is already registered", scanner.Name())
+	}
+	e.scanners.list[scanner.Name()] = scanner
+	return nil
+}
+
+// Scanners returns the sorted names of the registered scanners
+func (e *KRIE) Scanners() []string {
+	e.scanners.lock.RLock()
+	defer e.scanners.lock.RUnlock()
+	return sortedKeys(e.scanners.list)
+}
+
+// Scan runs the provided scanners (or all the registered scanners if none is provided), and returns their results
+func (e *KRIE) Scan(ctx context.Context, names ...string) ([]ScanResult, error) {
+	if len(names) == 0 {
+		names = e.Scanners()
+	}
+
+	e.scanners.lock.RLock()
+	toRun := make([]Scanner, 0, len(names))
+	for _, name := range names {
+		scanner, ok := e.scanners.list[name]
+		if !ok {
+			e.scanners.lock.RUnlock()
+			return nil, fmt.Errorf("unknown scanner: %s", name)
+		}
+		toRun = append(toRun, scanner)
+	}
+	e.scanners.lock.RUnlock()
+
+	results := make([]ScanResult, len(toRun))
+	var wg sync.WaitGroup
+	for i, scanner := range toRun {
+		wg.Add(1)
+		go func(i int, scanner Scanner) {
+			defer wg.Done()
+			result := ScanResult{
+				Scanner: scanner.Name(),
+				Start:   time.Now(),
+			}
+			findings, err := scanner.Scan(ctx)
+			result.Duration = time.Since(result.Start).Milliseconds()
+			result.Findings = findings
+			if result.Findings == nil {
+				result.Findings = []Finding{}
+			}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			result.Clean = err == nil && len(findings) == 0
+			results[i] = result
+		}(i, scanner)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// registerBuiltinScanners registers the scanners shipped with KRIE
+func (e *KRIE) registerBuiltinScanners() {
+	for _, scanner := range []Scanner{
+		&hiddenModulesScanner{},
+		&syscallTableScanner{krie: e},
+		&inventoryScanner{baseline: readInventory()},
+		e.hidden,
+	} {
+		_ = e.RegisterScanner(scanner)
+	}
+}
+
+// hiddenModulesScanner looks for the modules which are loaded but hidden from /proc/modules
+type hiddenModulesScanner struct{}
+
+func (s *hiddenModulesScanner) Name() string {
+	return HiddenModulesScannerName
+}
+
+func (s *hiddenModulesScanner) Scan(_ context.Context) ([]Finding, error) {
+	listed, err := readProcModules()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	// loadable modules have an initstate file in sysfs
+	states, _ := filepath.Glob("/sys/module/*/initstate")
+	for _, state := range states {
+		name := filepath.Base(filepath.Dir(state))
+		if !listed[name] {
+			findings = append(findings, Finding{
+				Message: fmt.Sprintf("module %s is in /sys/module but not in /proc/modules", name),
+				Details: map[string]string{"module": name, "source": "sysfs"},
+			})
+		}
+	}
+
+	// the symbols of the loaded modules are listed in /proc/kallsyms
+	f, err := os.Open("/proc/kallsyms")
+	if err != nil {
+		return findings, err
+	}
+	defer f.Close()
+	reported := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		start := strings.LastIndexByte(line, '[')
+		end := strings.LastIndexByte(line, ']')
+		if start < 0 || end <= start {
+			continue
+		}
+		name := line[start+1 : end]
+		if listed[name] || reported[name] || name == "bpf" || name == "__builtin__ftrace" || strings.HasPrefix(name, "__builtin") {
+			continue
+		}
+		reported[name] = true
+		findings = append(findings, Finding{
+			Message: fmt.Sprintf("module %s has symbols in /proc/kallsyms but isn't in /proc/modules", name),
+			Details: map[string]string{"module": name, "source": "kallsyms"},
+		})
+	}
+	return findings, scanner.Err()
+}
+
+// readProcModules returns the modules listed in /proc/modules
+func readProcModules() (map[string]bool, error) {
+	data, err := os.ReadFile("/proc/modules")
+	if err != nil {
+		return nil, err
+	}
+	modules := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			modules[fields[0]] = true
+		}
+	}
+	return modules, nil
+}
+
+// syscallTableScanner reads the syscall tables from /proc/kcore, and looks for the handlers outside of the kernel text
+type syscallTableScanner struct {
+	krie *KRIE
+}
+
+func (s *syscallTableScanner) Name() string {
+	return SyscallTableScannerName
+}
+
+func (s *syscallTableScanner) Scan(_ context.Context) ([]Finding, error) {
+	s.krie.kernelSymbolsLock.Lock()
+	stext, okStext := s.krie.kernelSymbols["system/_stext"]
+	etext, okEtext := s.krie.kernelSymbols["system/_etext"]
+	tables := make(map[string]uint64)
+	for _, table := range []string{"sys_call_table", "x32_sys_call_table", "ia32_sys_call_table"} {
+		if symbol, ok := s.krie.kernelSymbols["system/"+table]; ok {
+			tables[table] = symbol.Value
+		}
+	}
+	s.krie.kernelSymbolsLock.Unlock()
+	if !okStext || !okEtext || len(tables) == 0 {
+		return nil, fmt.Errorf("kernel symbols not loaded")
+	}
+
+	kcore, err := elf.Open("/proc/kcore")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open /proc/kcore (is the kernel locked down?): %w", err)
+	}
+	defer kcore.Close()
+
+	var findings []Finding
+	for _, table := range sortedKeys(tables) {
+		data := make([]byte, (maxSyscallNr+1)*8)
+		if err = readKernelMemory(kcore, tables[table], data); err != nil {
+			return findings, fmt.Errorf("couldn't read %s: %w", table, err)
+		}
+		for nr := 1; nr <= maxSyscallNr; nr++ {
+			handler := events.ByteOrder.Uint64(data[nr*8 : nr*8+8])
+			if handler == 0 || (handler >= stext.Value && handler < etext.Value) {
+				continue
+			}
+			symbol := events.KernelSymbol{Address: events.MemoryPointer(handler)}
+			_ = s.krie.resolveFuncSymbol(&symbol)
+			findings = append(findings, Finding{
+				Message: fmt.Sprintf("%s entry %d points outside of the kernel text", table, nr),
+				Details: map[string]string{
+					"table":   table,
+					"syscall": fmt.Sprintf("%d", nr),
+					"handler": fmt.Sprintf("0x%x", handler),
+					"symbol":  symbol.Symbol,
+					"module":  symbol.Module,
+				},
+			})
+		}
+	}
+	return findings, nil
+}
+
+// readKernelMemory reads kernel memory from the loadable segments of /proc/kcore
+func readKernelMemory(kcore *elf.File, address uint64, data []byte) error {
+	for _, prog := range kcore.Progs {
+		if prog.Type != elf.PT_LOAD || address < prog.Vaddr || address+uint64(len(data)) > prog.Vaddr+prog.Memsz {
+			continue
+		}
+		_, err := prog.ReadAt(data, int64(address-prog.Vaddr))
+		return err
+	}
+	return fmt.Errorf("address 0x%x isn't mapped in /proc/kcore", address)
+}
+
+// inventory is a snapshot of the security relevant state of the kernel
+type inventory struct {
+	modules         map[string]bool
+	tainted         string
+	lockdown        string
+	modulesDisabled string
+	kprobes         map[string]bool
+}
+
+func readInventory() *inventory {
+	inv := &inventory{
+		kprobes: make(map[string]bool),
+	}
+	inv.modules, _ = readProcModules()
+	inv.tainted = readTrimmedFile("/proc/sys/kernel/tainted")
+	inv.lockdown = readTrimmedFile("/sys/kernel/security/lockdown")
+	inv.modulesDisabled = readTrimmedFile("/proc/sys/kernel/modules_disabled")
+	for _, line := range strings.Split(readTrimmedFile("/sys/kernel/debug/tracing/kprobe_events"), "\n") {
+		if len(line) > 0 {
+			inv.kprobes[line] = true
+		}
+	}
+	return inv
+}
+
+func readTrimmedFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// inventoryScanner reports the changes of the kernel inventory since KRIE started
+type inventoryScanner struct {
+	baseline *inventory
+}
+
+func (s *inventoryScanner) Name() string {
+	return InventoryScannerName
+}
+
+func (s *inventoryScanner) Scan(_ context.Context) ([]Finding, error) {
+	current := readInventory()
+
+	var findings []Finding
+	for _, module := range diffKeys(current.modules, s.baseline.modules) {
+		findings = append(findings, Finding{
+			Message: fmt.Sprintf("module %s was loaded", module),
+			Details: map[string]string{"module": module},
+		})
+	}
+	for _, module := range diffKeys(s.baseline.modules, current.modules) {
+		findings = append(findings, Finding{
+			Message: fmt.Sprintf("module %s was unloaded", module),
+			Details: map[string]string{"module": module},
+		})
+	}
+	for _, kprobe := range diffKeys(current.kprobes, s.baseline.kprobes) {
+		findings = append(findings, Finding{
+			Message: "a kprobe was registered",
+			Details: map[string]string{"kprobe": kprobe},
+		})
+	}
+	for name, values := range map[string][2]string{
+		"tainted":          {s.baseline.tainted, current.tainted},
+		"lockdown":         {s.baseline.lockdown, current.lockdown},
+		"modules_disabled": {s.baseline.modulesDisabled, current.modulesDisabled},
+	} {
+		if values[0] != values[1] {
+			findings = append(findings, Finding{
+				Message: fmt.Sprintf("%s changed", name),
+				Details: map[string]string{"previous": values[0], "current": values[1]},
+			})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Message < findings[j].Message
+	})
+	return findings, nil
+}
+
+// diffKeys returns the sorted keys of a which aren't in b
+func diffKeys(a map[string]bool, b map[string]bool) []string {
+	var out []string
+	for key := range a {
+		if !b[key] {
+			out = append(out, key)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	selfEventsMetricName = "krie_self_generated_events_total"
+	selfEventsMetricHelp = "Events caused by KRIE or by the processes it starts, by event type"
+)
+
+// selfEventsConstant returns the value of the krie_self_events constant of the eBPF programs
+func (o *Options) selfEventsConstant() uint64 {
+	if o.SelfEvents {
+		return 1
+	}
+	return 0
+}
+
+// isSelfGenerated returns true if the event was caused by KRIE or by a process started by KRIE. The kernel drops most
+// of them unless self_events is set, but the events of the scanners and of the exiting processes aren't filtered.
+func (e *KRIE) isSelfGenerated(event *events.Event) bool {
+	if !event.HasProcessContext() {
+		return false
+	}
+	if event.Process.PID == e.pid {
+		return true
+	}
+	return len(event.Process.Ancestors) > 0 && event.Process.Ancestors[0].PID == e.pid
+}
+
+// excludeSelfEvent tags the self-generated events and returns true if the event is excluded from the policies
+func (e *KRIE) excludeSelfEvent(event *events.Event) bool {
+	if event.SelfGenerated = e.isSelfGenerated(event); !event.SelfGenerated {
+		return false
+	}
+	e.metrics.add(selfEventsMetricName, selfEventsMetricHelp, 1, "event_type", event.Kernel.Type.String())
+	return !e.options.SelfEvents
+}
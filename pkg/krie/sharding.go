@@ -0,0 +1,166 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	manager "github.com/DataDog/ebpf-manager"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	// MaxShardCount is the number of perf ring buffers defined in kernel space
+	MaxShardCount = 4
+	// DefaultShard is the shard used by the event types that weren't assigned a shard
+	DefaultShard = 0
+
+	lostEventsMetricName = "krie_perf_lost_events_total"
+	lostEventsMetricHelp = "Events lost because the perf ring buffer of a shard was full"
+)
+
+// ShardingOptions is used to route event types to dedicated perf ring buffers. Each shard is consumed by its own
+// goroutine, so that a slow or chatty event type can't delay the others.
+type ShardingOptions struct {
+	Shards         map[string]uint32 `yaml:"shards"`
+	RingBufferSize map[uint32]int    `yaml:"ring_buffer_size"`
+
+	shards map[events.EventType]uint32
+}
+
+// IsValid returns an error if the sharding options are invalid
+func (o *ShardingOptions) IsValid() error {
+	o.shards = make(map[events.EventType]uint32)
+	for eventType, shard := range o.Shards {
+		evt := events.ParseEventType(eventType)
+		if evt == events.UnknownEventType {
+			return fmt.Errorf("unknown event type in shards: %s", eventType)
+		}
+		if shard >= MaxShardCount {
+			return fmt.Errorf("invalid shard for %s: %d (max %d)", eventType, shard, MaxShardCount-1)
+		}
+		o.shards[evt] = shard
+	}
+	for shard, size := range o.RingBufferSize {
+		if shard >= MaxShardCount {
+			return fmt.Errorf("invalid shard in ring_buffer_size: %d (max %d)", shard, MaxShardCount-1)
+		}
+		if size <= 0 {
+			return fmt.Errorf("invalid ring_buffer_size for shard %d: %d", shard, size)
+		}
+	}
+	return nil
+}
+
+// ActiveShards returns the list of shards in use, the default shard is always in use
+func (o *ShardingOptions) ActiveShards() []uint32 {
+	var active [MaxShardCount]bool
+	active[DefaultShard] = true
+	for _, shard := range o.shards {
+		active[shard] = true
+	}
+
+	var out []uint32
+	for shard, ok := range active {
+		if ok {
+			out = append(out, uint32(shard))
+		}
+	}
+	return out
+}
+
+// NewShardingOptions returns a default set of sharding options
+func NewShardingOptions() *ShardingOptions {
+	return &ShardingOptions{}
+}
+
+// shardMapName returns the name of the perf map of a shard
+func shardMapName(shard uint32) string {
+	if shard == DefaultShard {
+		return "events"
+	}
+	return fmt.Sprintf("events_shard_%d", shard)
+}
+
+// newEventHandler returns the handler of a shard. Shards are consumed concurrently, so the default handler of each
+// shard gets its own event buffer, and its own governor batch.
+func (e *KRIE) newEventHandler() func(data []byte) error {
+	handler := e.options.EventHandler
+	if handler == nil {
+		event := events.NewEvent()
+		handler = func(data []byte) error {
+			return e.defaultEventHandler(event, data)
+		}
+	}
+	if e.governor != nil {
+		handler = e.governor.wrap(handler)
+	}
+	return handler
+}
+
+// shardPerfMaps returns the perf maps of the active shards
+func (e *KRIE) shardPerfMaps() []*manager.PerfMap {
+	var perfMaps []*manager.PerfMap
+	for _, shard := range e.options.Sharding.ActiveShards() {
+		shard := shard
+		pages, ok := e.options.Sharding.RingBufferSize[shard]
+		if !ok {
+			pages = 8192
+		}
+		handler := e.newEventHandler()
+
+		perfMaps = append(perfMaps, &manager.PerfMap{
+			Map: manager.Map{Name: shardMapName(shard)},
+			PerfMapOptions: manager.PerfMapOptions{
+				PerfRingBufferSize: pages * os.Getpagesize(),
+				DataHandler: func(CPU int, data []byte, perfMap *manager.PerfMap, manager *manager.Manager) {
+					if e.chaos.perfDrop() {
+						e.recordLostEvents(shard, CPU, 1)
+						return
+					}
+					if err := handler(data); err != nil {
+						logrus.Errorf("couldn't handle event: %v", err)
+					}
+				},
+				LostHandler: func(CPU int, count uint64, perfMap *manager.PerfMap, manager *manager.Manager) {
+					e.recordLostEvents(shard, CPU, count)
+				},
+			},
+		})
+	}
+	return perfMaps
+}
+
+// recordLostEvents accounts for the events lost because the perf ring buffer of a shard was full
+func (e *KRIE) recordLostEvents(shard uint32, cpu int, count uint64) {
+	e.metrics.add(lostEventsMetricName, lostEventsMetricHelp, count, "shard", strconv.Itoa(int(shard)))
+	logrus.Debugf("lost %d event(s) on shard %d (cpu %d): perf ring buffer full", count, shard, cpu)
+}
+
+// loadEventShards pushes the event types to shard routing table in kernel space
+func (e *KRIE) loadEventShards() error {
+	for eventType, shard := range e.options.Sharding.shards {
+		if err := e.eventShardsMap.Put(uint32(eventType), shard); err != nil {
+			return fmt.Errorf("failed to push shard %d for \"%s\": %w", shard, eventType, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,350 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// SigmaLogSource is the log source of the exported Sigma rules, it should match the log source of the KRIE events in
+// the SIEM
+type SigmaLogSource struct {
+	Product string `yaml:"product"`
+	Service string `yaml:"service"`
+}
+
+// DefaultSigmaLogSource is the default log source of the exported Sigma rules
+var DefaultSigmaLogSource = SigmaLogSource{
+	Product: "linux",
+	Service: "krie",
+}
+
+// sigmaField is a field of a Sigma search, the values are ORed
+type sigmaField struct {
+	Field  string
+	Values []interface{}
+}
+
+// SigmaDetection is the detection section of a Sigma rule: the selection matches the events of the rule, the filters
+// exclude the events of the more specific rules of the same event type
+type SigmaDetection struct {
+	Selection []sigmaField
+	Filters   []sigmaField
+}
+
+// condition returns the condition of the detection
+func (sd SigmaDetection) condition() string {
+	if len(sd.Filters) == 0 {
+		return "selection"
+	}
+	return "selection and not 1 of filter_*"
+}
+
+// MarshalYAML returns the YAML representation of the detection, with the searches before the condition
+func (sd SigmaDetection) MarshalYAML() (interface{}, error) {
+	out := &yaml.Node{Kind: yaml.MappingNode}
+	addSearch := func(name string, fields []sigmaField) error {
+		search := &yaml.Node{Kind: yaml.MappingNode}
+		for _, field := range fields {
+			var value yaml.Node
+			var err error
+			if len(field.Values) == 1 {
+				err = value.Encode(field.Values[0])
+			} else {
+				err = value.Encode(field.Values)
+			}
+			if err != nil {
+				return err
+			}
+			search.Content = append(search.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: field.Field}, &value)
+		}
+		out.Content = append(out.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, search)
+		return nil
+	}
+
+	if err := addSearch("selection", sd.Selection); err != nil {
+		return nil, err
+	}
+	for _, filter := range sd.Filters {
+		if err := addSearch("filter_"+filter.Field[strings.LastIndex(filter.Field, ".")+1:], []sigmaField{filter}); err != nil {
+			return nil, err
+		}
+	}
+	out.Content = append(out.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: "condition"},
+		&yaml.Node{Kind: yaml.ScalarNode, Value: sd.condition()},
+	)
+	return out, nil
+}
+
+// SigmaRule is a Sigma rule matching the JSON representation of the events of a KRIE rule, so that the detections of
+// KRIE can be mirrored in the correlation engine of a SIEM
+type SigmaRule struct {
+	Title       string         `yaml:"title"`
+	ID          string         `yaml:"id"`
+	Status      string         `yaml:"status"`
+	Description string         `yaml:"description"`
+	Author      string         `yaml:"author"`
+	LogSource   SigmaLogSource `yaml:"logsource"`
+	Detection   SigmaDetection `yaml:"detection"`
+	Fields      []string       `yaml:"fields,omitempty"`
+	Level       string         `yaml:"level"`
+
+	// ruleID is the ID of the KRIE rule
+	ruleID string `yaml:"-"`
+}
+
+// RuleID returns the ID of the KRIE rule mirrored by the Sigma rule
+func (sr *SigmaRule) RuleID() string {
+	return sr.ruleID
+}
+
+// sigmaCandidate is a rule of an event type: event is a sample event of the rule, used to resolve its ID and its
+// confidence, discriminator is the field that distinguishes it from the less specific rules of the event type
+type sigmaCandidate struct {
+	event         *events.Event
+	ruleID        string
+	discriminator *sigmaField
+	// extra is an additional field of the selection of the rule
+	extra *sigmaField
+	// exclusive is set when the events of the rule can't match the other rules of the event type
+	exclusive bool
+}
+
+// sigmaCandidates returns the rules of an event type, from the most specific to the least specific, in the order
+// used by Options.RuleID to attribute an event to a rule
+func sigmaCandidates(options *events.Options, eventType events.EventType) []sigmaCandidate {
+	var out []sigmaCandidate
+	add := func(setup func(e *events.Event), field string, value interface{}) *sigmaCandidate {
+		e := events.NewEvent()
+		e.Kernel.Type = eventType
+		if setup != nil {
+			setup(e)
+		}
+		candidate := sigmaCandidate{event: e, ruleID: options.RuleID(e)}
+		if len(field) > 0 {
+			candidate.discriminator = &sigmaField{Field: field, Values: []interface{}{value}}
+		}
+		out = append(out, candidate)
+		return &out[len(out)-1]
+	}
+
+	if events.IsFailureEventType(eventType) {
+		add(func(e *events.Event) {
+			e.Failure = &events.Failure{Repeated: true}
+		}, "failure.repeated", true)
+	}
+
+	switch eventType {
+	case events.InitModuleEventType:
+		if options.ModuleSigners != nil && options.ModuleSigners.Action >= events.LogAction {
+			add(func(e *events.Event) {
+				e.InitModule.SignerPolicy = events.SignerPolicyDenied
+			}, "init_module.signer_policy", events.SignerPolicyDenied)
+		}
+		if options.ContainerImages != nil && options.ContainerImages.Action >= events.LogAction {
+			add(func(e *events.Event) {
+				e.InitModule.ImagePolicy = events.ImagePolicyDenied
+			}, "init_module.image_policy", events.ImagePolicyDenied)
+		}
+		add(func(e *events.Event) {
+			e.InitModule.Fileless = true
+		}, "init_module.fileless", true)
+	case events.BPFEventType:
+		add(func(e *events.Event) {
+			e.BPFEvent.Fileless = true
+		}, "bpf.fileless", true)
+	case events.SysCtlEventType:
+		names := make([]string, 0, len(options.SysCtlEvent.List))
+		for name := range options.SysCtlEvent.List {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			name := name
+			add(func(e *events.Event) {
+				e.SysCtlEvent.Name = name
+			}, "sysctl.name", name).exclusive = true
+		}
+	case events.KernelParameterEventType, events.PeriodicKernelParameterEventType:
+		// the symbols of the parameters are formatted as [module]/[symbol], the module and the symbol are resolved in
+		// separate fields of the events
+		for _, param := range options.KernelParameterEvent.List {
+			module, symbol := "", param.Symbol
+			if i := strings.Index(param.Symbol, "/"); i >= 0 {
+				module, symbol = param.Symbol[:i], param.Symbol[i+1:]
+			}
+			if len(module) == 0 || len(symbol) == 0 {
+				continue
+			}
+			candidate := add(func(e *events.Event) {
+				e.KernelParameterEvent.Parameter.Module = module
+				e.KernelParameterEvent.Parameter.Symbol = symbol
+			}, "kernel_parameter.parameter.module", module)
+			candidate.extra = &sigmaField{Field: "kernel_parameter.parameter.symbol", Values: []interface{}{symbol}}
+			candidate.exclusive = true
+		}
+		if len(out) > 0 {
+			return out
+		}
+	}
+
+	// the catch-all rule of the event type
+	add(nil, "", nil)
+	return out
+}
+
+// SigmaRules returns the Sigma rules of the activated rules of the provided policies: a rule per event type, per sub
+// rule (fileless, unauthorized_signer, unallowed_image, repeated_failure) and per sysctl parameter and kernel parameter of
+// the policies. The level of each rule is the confidence of its events when no other field raises it.
+func SigmaRules(options *events.Options, logSource SigmaLogSource) []*SigmaRule {
+	var eventTypes []events.EventType
+	actions := options.ParseEventsActions()
+	for eventType, action := range actions {
+		if action >= events.LogAction {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	sort.Slice(eventTypes, func(i, j int) bool {
+		return eventTypes[i].String() < eventTypes[j].String()
+	})
+
+	var out []*SigmaRule
+	for _, eventType := range eventTypes {
+		// filters excludes the events of the previous rules, exclusive excludes the events of the exclusive rules from
+		// the catch-all rule
+		var filters, exclusive []sigmaField
+		for _, candidate := range sigmaCandidates(options, eventType) {
+			candidate.event.Policy.RuleID = candidate.ruleID
+
+			rule := &SigmaRule{
+				Title:       fmt.Sprintf("KRIE %s", candidate.ruleID),
+				ID:          uuidV5("sigma/" + candidate.ruleID),
+				Status:      "experimental",
+				Description: sigmaDescription(options, eventType, candidate.ruleID, actions[eventType]),
+				Author:      "KRIE",
+				LogSource:   logSource,
+				Detection: SigmaDetection{
+					Selection: []sigmaField{{Field: "event.type", Values: []interface{}{eventType.String()}}},
+					Filters:   append([]sigmaField{}, filters...),
+				},
+				Fields: []string{"process.comm", "process.pid", "event.action"},
+				Level:  sigmaLevel(options.Confidence(candidate.event)),
+				ruleID: candidate.ruleID,
+			}
+			if candidate.discriminator != nil {
+				rule.Detection.Selection = append(rule.Detection.Selection, *candidate.discriminator)
+				if candidate.extra != nil {
+					rule.Detection.Selection = append(rule.Detection.Selection, *candidate.extra)
+				}
+				// the events of this rule are excluded from the less specific rules
+				if candidate.exclusive {
+					exclusive = mergeSigmaFilter(exclusive, *candidate.discriminator)
+				} else {
+					filters = mergeSigmaFilter(filters, *candidate.discriminator)
+				}
+			} else {
+				for _, filter := range exclusive {
+					rule.Detection.Filters = mergeSigmaFilter(rule.Detection.Filters, filter)
+				}
+			}
+			out = append(out, rule)
+		}
+	}
+	return out
+}
+
+// mergeSigmaFilter adds the values of a field to a list of filters
+func mergeSigmaFilter(filters []sigmaField, field sigmaField) []sigmaField {
+	for i := range filters {
+		if filters[i].Field == field.Field {
+			filters[i].Values = append(append([]interface{}{}, filters[i].Values...), field.Values...)
+			return filters
+		}
+	}
+	return append(filters, field)
+}
+
+// sigmaDescription returns the description of the Sigma rule of a KRIE rule
+func sigmaDescription(options *events.Options, eventType events.EventType, ruleID string, action events.Action) string {
+	description := fmt.Sprintf("Mirrors the KRIE rule %s on the %s events.", ruleID, eventType)
+	switch mode := options.RuleMode(ruleID); mode {
+	case events.EnforceMode:
+		description += fmt.Sprintf(" The rule is enforced by KRIE (action %s), the event.action field reports the outcome.", action)
+	case events.MonitorMode:
+		description += " The rule is in monitor mode, KRIE only logs its events."
+	}
+	return description
+}
+
+// sigmaLevel maps a confidence to a Sigma level
+func sigmaLevel(confidence events.Confidence) string {
+	switch confidence {
+	case events.HighConfidence:
+		return "high"
+	case events.MediumConfidence:
+		return "medium"
+	case events.LowConfidence:
+		return "low"
+	default:
+		return "informational"
+	}
+}
+
+// WriteSigmaRules writes the provided rules as a stream of YAML documents
+func WriteSigmaRules(w io.Writer, rules []*SigmaRule) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(4)
+	for _, rule := range rules {
+		if err := encoder.Encode(rule); err != nil {
+			return fmt.Errorf("couldn't encode Sigma rule %s: %w", rule.ruleID, err)
+		}
+	}
+	return encoder.Close()
+}
+
+// WriteSigmaRuleFiles writes each rule in its own file of the provided directory, the files are named after the KRIE
+// rules
+func WriteSigmaRuleFiles(directory string, rules []*SigmaRule) error {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		name := strings.NewReplacer("/", "_", "*", "any").Replace(rule.ruleID)
+		f, err := os.Create(filepath.Join(directory, fmt.Sprintf("krie_%s.yml", name)))
+		if err != nil {
+			return err
+		}
+		err = WriteSigmaRules(f, []*SigmaRule{rule})
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
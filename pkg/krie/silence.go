@@ -0,0 +1,236 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	SilenceStatusSilent  = "silent"
+	SilenceStatusResumed = "resumed"
+)
+
+// SilenceOptions configures the detection of the windows of silence: an active event type which stops producing
+// events for an abnormal period while its probes are attached is a symptom of kernel side tampering, or of broken tail
+// calls
+type SilenceOptions struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is the number of seconds between two checks
+	Interval int64 `yaml:"interval"`
+	// MinEvents is the number of events of an event type required to learn its baseline rate
+	MinEvents uint64 `yaml:"min_events"`
+	// Threshold is the number of expected intervals without event after which an event type is silent
+	Threshold float64 `yaml:"threshold"`
+	// MinSilence is the minimum number of seconds without event after which an event type is silent
+	MinSilence int64 `yaml:"min_silence"`
+}
+
+// IsValid returns an error if the silence options are invalid
+func (o *SilenceOptions) IsValid() error {
+	if !o.Enabled {
+		return nil
+	}
+	if o.Interval <= 0 {
+		return fmt.Errorf("invalid interval: %d", o.Interval)
+	}
+	if o.MinEvents < 2 {
+		return fmt.Errorf("invalid min_events: %d", o.MinEvents)
+	}
+	if o.Threshold < 1 {
+		return fmt.Errorf("invalid threshold: %f", o.Threshold)
+	}
+	if o.MinSilence <= 0 {
+		return fmt.Errorf("invalid min_silence: %d", o.MinSilence)
+	}
+	return nil
+}
+
+// NewSilenceOptions returns a default set of silence options
+func NewSilenceOptions() *SilenceOptions {
+	return &SilenceOptions{
+		Interval:   10,
+		MinEvents:  20,
+		Threshold:  10,
+		MinSilence: 300,
+	}
+}
+
+// eventTypeActivity is the activity of an event type
+type eventTypeActivity struct {
+	count  uint64
+	first  time.Time
+	last   time.Time
+	silent bool
+}
+
+// expectedInterval returns the mean interval between two events
+func (a *eventTypeActivity) expectedInterval() time.Duration {
+	return a.last.Sub(a.first) / time.Duration(a.count-1)
+}
+
+// silenceDetector tracks the activity of the event types and reports the windows of silence
+type silenceDetector struct {
+	options *SilenceOptions
+	krie    *KRIE
+
+	lock     sync.Mutex
+	activity [events.MaxEventType]*eventTypeActivity
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newSilenceDetector(krie *KRIE, options *SilenceOptions) *silenceDetector {
+	return &silenceDetector{
+		options: options,
+		krie:    krie,
+		stop:    make(chan struct{}),
+	}
+}
+
+// record records an event of the provided event type
+func (sd *silenceDetector) record(eventType events.EventType, at time.Time) {
+	if eventType >= events.MaxEventType {
+		return
+	}
+
+	sd.lock.Lock()
+	activity := sd.activity[eventType]
+	if activity == nil {
+		activity = &eventTypeActivity{first: at}
+		sd.activity[eventType] = activity
+	}
+	var report *events.Event
+	if activity.silent {
+		activity.silent = false
+		report = sd.report(eventType, activity, SilenceStatusResumed, at.Sub(activity.last))
+	}
+	activity.count++
+	activity.last = at
+	sd.lock.Unlock()
+
+	if report != nil {
+		sd.emit(report)
+	}
+}
+
+// report returns the silence event of an event type, the caller must hold the lock
+func (sd *silenceDetector) report(eventType events.EventType, activity *eventTypeActivity, status string, silence time.Duration) *events.Event {
+	event := events.NewEvent()
+	event.Kernel.Type = events.SilenceEventType
+	event.SilenceEvent = events.SilenceEvent{
+		Status:         status,
+		EventType:      eventType.String(),
+		LastEvent:      activity.last,
+		Silence:        int64(silence.Seconds()),
+		BaselineEvents: activity.count,
+	}
+	if activity.count > 1 {
+		event.SilenceEvent.ExpectedInterval = activity.expectedInterval().Milliseconds()
+	}
+	return event
+}
+
+func (sd *silenceDetector) emit(event *events.Event) {
+	if err := sd.krie.emitEvent(event); err != nil {
+		logrus.Errorf("couldn't emit silence event: %v", err)
+	}
+}
+
+func (sd *silenceDetector) start() {
+	sd.wg.Add(1)
+	go func() {
+		defer sd.wg.Done()
+		ticker := time.NewTicker(time.Duration(sd.options.Interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-sd.stop:
+				return
+			case now := <-ticker.C:
+				sd.check(now)
+			}
+		}
+	}()
+}
+
+func (sd *silenceDetector) close() {
+	close(sd.stop)
+	sd.wg.Wait()
+}
+
+// check reports the active event types which are silent while the probes are attached
+func (sd *silenceDetector) check(now time.Time) {
+	running, attached := sd.krie.runningProbes()
+	if !attached {
+		// the probes are known to be detached, this isn't a silent failure
+		return
+	}
+
+	var reports []*events.Event
+	sd.lock.Lock()
+	for _, eventType := range sd.krie.options.Events.ActivatedEventTypes() {
+		if eventType >= events.MaxEventType {
+			continue
+		}
+		activity := sd.activity[eventType]
+		if activity == nil || activity.silent || activity.count < sd.options.MinEvents {
+			continue
+		}
+		expected := time.Duration(sd.options.Threshold * float64(activity.expectedInterval()))
+		if minSilence := time.Duration(sd.options.MinSilence) * time.Second; expected < minSilence {
+			expected = minSilence
+		}
+		if silence := now.Sub(activity.last); silence > expected {
+			activity.silent = true
+			report := sd.report(eventType, activity, SilenceStatusSilent, silence)
+			report.SilenceEvent.RunningProbes = running
+			reports = append(reports, report)
+		}
+	}
+	sd.lock.Unlock()
+
+	for _, report := range reports {
+		logrus.Warnf("no %s event for %ds while the probes are attached", report.SilenceEvent.EventType, report.SilenceEvent.Silence)
+		sd.emit(report)
+	}
+}
+
+// runningProbes returns the number of running probes, and false if one of the initialized probes isn't running
+func (e *KRIE) runningProbes() (int, bool) {
+	if e.manager == nil {
+		return 0, false
+	}
+	var running int
+	for _, probe := range e.manager.Probes {
+		if !probe.IsInitialized() {
+			continue
+		}
+		if !probe.IsRunning() {
+			return running, false
+		}
+		running++
+	}
+	return running, running > 0
+}
@@ -0,0 +1,273 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// SinkEvent is a serialized event sent to a Sink
+type SinkEvent struct {
+	ID   string
+	Type events.EventType
+	// Data is the representation of the event in the format of the sink (JSON by default), terminated by a new line
+	Data []byte
+}
+
+// Sink is a third-party output of KRIE. The events are queued, batched and retried by the pipeline of KRIE before
+// they reach Send. The methods of a Sink are never called concurrently.
+type Sink interface {
+	// Start is called once, before the first call to Send
+	Start() error
+	// Send writes a batch of events, a batch is retried when Send returns an error
+	Send(batch []SinkEvent) error
+	// Flush is called when the pipeline is flushed, before Stop
+	Flush() error
+	// Stop is called once, when KRIE stops
+	Stop() error
+}
+
+// SinkFactory creates a Sink from the "config" section of its configuration. decode unmarshals the "config" section
+// into the provided value, and is a no-op if the section is empty.
+type SinkFactory func(name string, decode func(out interface{}) error) (Sink, error)
+
+var (
+	sinkFactoriesLock sync.RWMutex
+	sinkFactories     = make(map[string]SinkFactory)
+)
+
+// RegisterSink registers a sink type, it is usually called from the init function of the package of the sink. The
+// sinks of this type are then configured in the "sinks" section of the configuration file. RegisterSink panics if the
+// sink type is already registered.
+func RegisterSink(sinkType string, factory SinkFactory) {
+	sinkFactoriesLock.Lock()
+	defer sinkFactoriesLock.Unlock()
+
+	if factory == nil {
+		panic("krie: nil factory for sink type " + sinkType)
+	}
+	if _, ok := sinkFactories[sinkType]; ok {
+		panic("krie: sink type " + sinkType + " registered twice")
+	}
+	sinkFactories[sinkType] = factory
+}
+
+func getSinkFactory(sinkType string) (SinkFactory, bool) {
+	sinkFactoriesLock.RLock()
+	defer sinkFactoriesLock.RUnlock()
+	factory, ok := sinkFactories[sinkType]
+	return factory, ok
+}
+
+// SinkOptions configures a sink of a registered sink type
+type SinkOptions struct {
+	Type          string       `yaml:"type"`
+	BatchSize     int          `yaml:"batch_size"`
+	FlushInterval int64        `yaml:"flush_interval"`
+	MaxRetries    int          `yaml:"max_retries"`
+	Format        OutputFormat `yaml:"format"`
+	Config        yaml.Node    `yaml:"config"`
+}
+
+// IsValid returns an error if the sink options are invalid
+func (o *SinkOptions) IsValid() error {
+	if len(o.Type) == 0 {
+		return fmt.Errorf("empty type")
+	}
+	if _, ok := getSinkFactory(o.Type); !ok {
+		return fmt.Errorf("unknown sink type: %s", o.Type)
+	}
+	if o.BatchSize < 0 {
+		return fmt.Errorf("invalid batch_size: %d", o.BatchSize)
+	}
+	if o.FlushInterval < 0 {
+		return fmt.Errorf("invalid flush_interval: %d", o.FlushInterval)
+	}
+	if o.MaxRetries < 0 {
+		return fmt.Errorf("invalid max_retries: %d", o.MaxRetries)
+	}
+	return nil
+}
+
+// UnmarshalYAML parses the options of a sink, on top of the default options
+func (o *SinkOptions) UnmarshalYAML(value *yaml.Node) error {
+	type rawSinkOptions SinkOptions
+	raw := rawSinkOptions{
+		BatchSize:     100,
+		FlushInterval: 1,
+		MaxRetries:    3,
+		Format:        JSONFormat,
+	}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	*o = SinkOptions(raw)
+	return nil
+}
+
+// decode unmarshals the config section of the sink
+func (o *SinkOptions) decode(out interface{}) error {
+	if o.Config.Kind == 0 {
+		return nil
+	}
+	return o.Config.Decode(out)
+}
+
+// registeredSink adapts a Sink to the pipeline: events are batched, and the batches are retried with an exponential
+// backoff
+type registeredSink struct {
+	name    string
+	options *SinkOptions
+	sink    Sink
+
+	lock  sync.Mutex
+	batch []SinkEvent
+	stop  chan struct{}
+	wg    sync.WaitGroup
+
+	sent    uint64
+	failed  uint64
+	retries uint64
+}
+
+func newRegisteredSink(name string, options *SinkOptions, chaos *chaos) (*registeredSink, error) {
+	factory, ok := getSinkFactory(options.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown sink type: %s", options.Type)
+	}
+	sink, err := factory(name, options.decode)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create sink %s: %w", name, err)
+	}
+	return wrapSink(name, options, sink, chaos), nil
+}
+
+// wrapSink adapts an existing Sink to the pipeline
+func wrapSink(name string, options *SinkOptions, sink Sink, chaos *chaos) *registeredSink {
+	if chaos != nil {
+		sink = &chaosSink{Sink: sink, chaos: chaos}
+	}
+	return &registeredSink{
+		name:    name,
+		options: options,
+		sink:    sink,
+		stop:    make(chan struct{}),
+	}
+}
+
+func (rs *registeredSink) start() error {
+	if err := rs.sink.Start(); err != nil {
+		return fmt.Errorf("couldn't start sink %s: %w", rs.name, err)
+	}
+	if rs.options.FlushInterval > 0 {
+		rs.wg.Add(1)
+		go rs.flushLoop()
+	}
+	return nil
+}
+
+func (rs *registeredSink) flushLoop() {
+	defer rs.wg.Done()
+	ticker := time.NewTicker(time.Duration(rs.options.FlushInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rs.stop:
+			return
+		case <-ticker.C:
+			rs.lock.Lock()
+			if err := rs.sendBatch(); err != nil {
+				logrus.Errorf("couldn't flush sink %s: %v", rs.name, err)
+			}
+			rs.lock.Unlock()
+		}
+	}
+}
+
+func (rs *registeredSink) write(evt *queuedEvent) error {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	rs.batch = append(rs.batch, SinkEvent{
+		ID:   evt.id,
+		Type: evt.eventType,
+		Data: evt.data,
+	})
+	if len(rs.batch) < rs.options.BatchSize {
+		return nil
+	}
+	return rs.sendBatch()
+}
+
+// sendBatch sends the pending batch, the caller must hold the lock
+func (rs *registeredSink) sendBatch() error {
+	if len(rs.batch) == 0 {
+		return nil
+	}
+	batch := rs.batch
+	rs.batch = nil
+
+	var err error
+	backoff := 100 * time.Millisecond
+	for attempt := 0; attempt <= rs.options.MaxRetries; attempt++ {
+		if attempt > 0 {
+			atomic.AddUint64(&rs.retries, 1)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = rs.sink.Send(batch); err == nil {
+			atomic.AddUint64(&rs.sent, uint64(len(batch)))
+			return nil
+		}
+	}
+	atomic.AddUint64(&rs.failed, uint64(len(batch)))
+	return fmt.Errorf("couldn't send %d event(s) after %d attempt(s): %w", len(batch), rs.options.MaxRetries+1, err)
+}
+
+// logStats reports the events sent and lost since the last report
+func (rs *registeredSink) logStats() {
+	sent, failed, retries := atomic.SwapUint64(&rs.sent, 0), atomic.SwapUint64(&rs.failed, 0), atomic.SwapUint64(&rs.retries, 0)
+	if failed > 0 {
+		logrus.Warnf("sink %s: %d event(s) sent, %d event(s) lost, %d retry(ies)", rs.name, sent, failed, retries)
+	} else {
+		logrus.Debugf("sink %s: %d event(s) sent, %d retry(ies)", rs.name, sent, retries)
+	}
+}
+
+func (rs *registeredSink) close() error {
+	close(rs.stop)
+	rs.wg.Wait()
+
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	if err := rs.sendBatch(); err != nil {
+		logrus.Errorf("couldn't flush sink %s: %v", rs.name, err)
+	}
+	if err := rs.sink.Flush(); err != nil {
+		logrus.Errorf("couldn't flush sink %s: %v", rs.name, err)
+	}
+	return rs.sink.Stop()
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+)
+
+const (
+	// WebhookSinkName is the name of the sink posting events to a webhook
+	WebhookSinkName = "webhook"
+)
+
+// StdoutOutput is the output path which writes the events to the standard output
+const StdoutOutput = "-"
+
+// fileSink writes events to a file
+type fileSink struct {
+	file *os.File
+}
+
+func newFileSink(output string) (*fileSink, error) {
+	if output == StdoutOutput {
+		return &fileSink{
+			file: os.Stdout,
+		}, nil
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create output file: %w", err)
+	}
+	_ = os.Chmod(output, 0644)
+
+	return &fileSink{
+		file: f,
+	}, nil
+}
+
+func (fs *fileSink) write(evt *queuedEvent) error {
+	_, err := fs.file.Write(evt.data)
+	return err
+}
+
+func (fs *fileSink) close() error {
+	if fs.file == os.Stdout {
+		return nil
+	}
+	return fs.file.Close()
+}
+
+// TransportOptions configures the connections of a network sink
+type TransportOptions struct {
+	// Proxy is the URL of the proxy (http, https, socks5 or socks5h), the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	// environment variables are used when it is empty
+	Proxy string `yaml:"proxy"`
+	// CAFile is a PEM bundle of certificate authorities, trusted in addition to the certificate authorities of the host
+	CAFile string `yaml:"ca_file"`
+	// IPFamily restricts the connections to ipv4 or ipv6, both families are tried when it is empty (dual stack)
+	IPFamily string `yaml:"ip_family"`
+}
+
+// IsValid returns an error if the transport options are invalid
+func (o *TransportOptions) IsValid() error {
+	if len(o.Proxy) > 0 {
+		proxy, err := url.Parse(o.Proxy)
+		if err != nil {
+			return fmt.Errorf("invalid proxy: %w", err)
+		}
+		switch proxy.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("invalid proxy scheme: %s", proxy.Scheme)
+		}
+	}
+	switch o.IPFamily {
+	case "", "ipv4", "ipv6":
+	default:
+		return fmt.Errorf("invalid ip_family: %s", o.IPFamily)
+	}
+	return nil
+}
+
+// NewTransportOptions returns a default set of transport options
+func NewTransportOptions() *TransportOptions {
+	return &TransportOptions{}
+}
+
+// WebhookOptions configures the webhook sink
+type WebhookOptions struct {
+	URL       string            `yaml:"url"`
+	Timeout   int64             `yaml:"timeout"`
+	Headers   map[string]string `yaml:"headers"`
+	Format    OutputFormat      `yaml:"format"`
+	Spool     *SpoolOptions     `yaml:"spool"`
+	Transport *TransportOptions `yaml:"transport"`
+}
+
+// IsValid returns an error if the webhook options are invalid
+func (o *WebhookOptions) IsValid() error {
+	if len(o.URL) == 0 {
+		return nil
+	}
+	if _, err := url.Parse(o.URL); err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if o.Timeout <= 0 {
+		return fmt.Errorf("invalid timeout: %d", o.Timeout)
+	}
+	if err := o.Spool.IsValid(); err != nil {
+		return fmt.Errorf("invalid spool section: %w", err)
+	}
+	if err := o.Transport.IsValid(); err != nil {
+		return fmt.Errorf("invalid transport section: %w", err)
+	}
+	return nil
+}
+
+// NewWebhookOptions returns a default set of webhook options
+func NewWebhookOptions() *WebhookOptions {
+	return &WebhookOptions{
+		Timeout:   5,
+		Format:    JSONFormat,
+		Spool:     NewSpoolOptions(),
+		Transport: NewTransportOptions(),
+	}
+}
@@ -0,0 +1,402 @@
+//go:build !krie_minimal
+
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// STIXSinkType is the type of the sink exporting the indicators of the findings as STIX 2.1 bundles
+const STIXSinkType = "stix"
+
+func init() {
+	RegisterSink(STIXSinkType, newSTIXSink)
+}
+
+// STIXOptions configures the STIX sink: the module hashes, the bpf program tags and the file paths of the findings are
+// exported as STIX 2.1 indicators. The sink reads the JSON representation of the events.
+type STIXOptions struct {
+	// MinConfidence is the minimum confidence of the exported findings
+	MinConfidence events.Confidence `yaml:"min_confidence"`
+	// Identity is the name of the identity producing the indicators, it defaults to the hostname
+	Identity string `yaml:"identity"`
+	// Directory is the directory of the STIX bundles, one bundle is written per batch with new indicators
+	Directory string `yaml:"directory"`
+	// TAXII is the TAXII collection the indicators are pushed to
+	TAXII *TAXIIOptions `yaml:"taxii"`
+}
+
+// IsValid returns an error if the STIX options are invalid
+func (o *STIXOptions) IsValid() error {
+	if err := o.MinConfidence.IsValid(); err != nil {
+		return fmt.Errorf("invalid min_confidence: %w", err)
+	}
+	if len(o.Directory) == 0 && len(o.TAXII.URL) == 0 {
+		return fmt.Errorf("neither directory nor taxii url configured")
+	}
+	if err := o.TAXII.IsValid(); err != nil {
+		return fmt.Errorf("invalid taxii section: %w", err)
+	}
+	return nil
+}
+
+// TAXIIOptions configures the TAXII 2.1 collection of the STIX sink
+type TAXIIOptions struct {
+	// URL is the URL of the collection, for example https://taxii.example.com/api1/collections/[id]/
+	URL      string            `yaml:"url"`
+	Username string            `yaml:"username"`
+	Password string            `yaml:"password"`
+	Headers  map[string]string `yaml:"headers"`
+	Timeout  int64             `yaml:"timeout"`
+	// Transport configures the proxy, the CA bundle and the IP family of the connections to the collection
+	Transport *TransportOptions `yaml:"transport"`
+}
+
+// IsValid returns an error if the TAXII options are invalid
+func (o *TAXIIOptions) IsValid() error {
+	if len(o.URL) == 0 {
+		return nil
+	}
+	if !strings.HasPrefix(o.URL, "https://") && !strings.HasPrefix(o.URL, "http://") {
+		return fmt.Errorf("invalid url: %s", o.URL)
+	}
+	if o.Timeout <= 0 {
+		return fmt.Errorf("invalid timeout: %d", o.Timeout)
+	}
+	if err := o.Transport.IsValid(); err != nil {
+		return fmt.Errorf("invalid transport section: %w", err)
+	}
+	return nil
+}
+
+// stixFinding is the subset of the JSON representation of an event used to build the indicators
+type stixFinding struct {
+	ID    string `json:"id"`
+	Event struct {
+		Time time.Time `json:"time"`
+		Type string    `json:"type"`
+	} `json:"event"`
+	Policy *struct {
+		RuleID     string            `json:"rule_id"`
+		Confidence events.Confidence `json:"confidence"`
+	} `json:"policy"`
+	InitModule *struct {
+		Name   string `json:"name"`
+		Path   string `json:"path"`
+		SHA256 string `json:"sha256"`
+	} `json:"init_module"`
+	BPF *struct {
+		Program *struct {
+			Name string `json:"name"`
+			Tag  string `json:"tag"`
+		} `json:"program"`
+	} `json:"bpf"`
+	Kexec *struct {
+		KernelPath string `json:"kernel_path"`
+		InitrdPath string `json:"initrd_path"`
+	} `json:"kexec"`
+}
+
+// stixObject is a STIX 2.1 domain object
+type stixObject struct {
+	Type           string   `json:"type"`
+	SpecVersion    string   `json:"spec_version"`
+	ID             string   `json:"id"`
+	Created        string   `json:"created"`
+	Modified       string   `json:"modified"`
+	CreatedByRef   string   `json:"created_by_ref,omitempty"`
+	Name           string   `json:"name"`
+	Description    string   `json:"description,omitempty"`
+	IdentityClass  string   `json:"identity_class,omitempty"`
+	IndicatorTypes []string `json:"indicator_types,omitempty"`
+	Pattern        string   `json:"pattern,omitempty"`
+	PatternType    string   `json:"pattern_type,omitempty"`
+	ValidFrom      string   `json:"valid_from,omitempty"`
+	Confidence     int      `json:"confidence,omitempty"`
+	Labels         []string `json:"labels,omitempty"`
+}
+
+// stixBundle is a STIX 2.1 bundle
+type stixBundle struct {
+	Type    string        `json:"type"`
+	ID      string        `json:"id"`
+	Objects []*stixObject `json:"objects"`
+}
+
+// stixSink converts the findings of KRIE into STIX 2.1 indicators, writes them in bundles and pushes them to a TAXII
+// collection
+type stixSink struct {
+	name     string
+	options  *STIXOptions
+	client   *http.Client
+	identity *stixObject
+	// shared are the identifiers of the indicators already exported
+	shared map[string]bool
+}
+
+func newSTIXSink(name string, decode func(out interface{}) error) (Sink, error) {
+	options := &STIXOptions{
+		MinConfidence: events.HighConfidence,
+		TAXII: &TAXIIOptions{
+			Timeout:   10,
+			Transport: NewTransportOptions(),
+		},
+	}
+	if err := decode(options); err != nil {
+		return nil, err
+	}
+	if len(options.Identity) == 0 {
+		options.Identity = hostPrivacy.hostname()
+	}
+	if err := options.IsValid(); err != nil {
+		return nil, err
+	}
+
+	client, err := newHTTPClient(options.TAXII.Transport, time.Duration(options.TAXII.Timeout)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	now := stixTimestamp(time.Now())
+	return &stixSink{
+		name:    name,
+		options: options,
+		client:  client,
+		identity: &stixObject{
+			Type:          "identity",
+			SpecVersion:   "2.1",
+			ID:            "identity--" + uuidV5("krie/"+options.Identity),
+			Created:       now,
+			Modified:      now,
+			Name:          options.Identity,
+			IdentityClass: "system",
+		},
+		shared: make(map[string]bool),
+	}, nil
+}
+
+// Start creates the directory of the bundles
+func (ss *stixSink) Start() error {
+	if len(ss.options.Directory) == 0 {
+		return nil
+	}
+	return os.MkdirAll(ss.options.Directory, 0700)
+}
+
+// Send exports the new indicators of a batch of events
+func (ss *stixSink) Send(batch []SinkEvent) error {
+	var indicators []*stixObject
+	for _, evt := range batch {
+		var finding stixFinding
+		if err := json.Unmarshal(evt.Data, &finding); err != nil {
+			logrus.Debugf("sink %s: couldn't parse event %s: %v", ss.name, evt.ID, err)
+			continue
+		}
+		for _, indicator := range ss.indicators(&finding) {
+			if !ss.shared[indicator.ID] {
+				indicators = append(indicators, indicator)
+			}
+		}
+	}
+	if len(indicators) == 0 {
+		return nil
+	}
+
+	objects := append([]*stixObject{ss.identity}, indicators...)
+	if len(ss.options.Directory) > 0 {
+		if err := ss.writeBundle(objects); err != nil {
+			return err
+		}
+	}
+	if len(ss.options.TAXII.URL) > 0 {
+		if err := ss.push(objects); err != nil {
+			return err
+		}
+	}
+	for _, indicator := range indicators {
+		ss.shared[indicator.ID] = true
+	}
+	return nil
+}
+
+// Flush is a no-op, the bundles are written by Send
+func (ss *stixSink) Flush() error {
+	return nil
+}
+
+// Stop closes the connections to the TAXII server
+func (ss *stixSink) Stop() error {
+	ss.client.CloseIdleConnections()
+	return nil
+}
+
+// indicators returns the indicators of a finding
+func (ss *stixSink) indicators(finding *stixFinding) []*stixObject {
+	if finding.Policy == nil || !finding.Policy.Confidence.AtLeast(ss.options.MinConfidence) {
+		return nil
+	}
+
+	var out []*stixObject
+	add := func(name string, pattern string) {
+		out = append(out, ss.newIndicator(finding, name, pattern))
+	}
+	if im := finding.InitModule; im != nil {
+		if len(im.SHA256) > 0 {
+			add(fmt.Sprintf("kernel module %s", im.Name), fmt.Sprintf("[file:hashes.'SHA-256' = '%s']", stixEscape(im.SHA256)))
+		}
+		if len(im.Path) > 0 {
+			add(fmt.Sprintf("kernel module %s", im.Name), stixPathPattern(im.Path))
+		}
+	}
+	if bpf := finding.BPF; bpf != nil && bpf.Program != nil && len(bpf.Program.Tag) > 0 {
+		add(fmt.Sprintf("bpf program %s", bpf.Program.Name), fmt.Sprintf("[x-krie-bpf-program:tag = '%s']", stixEscape(bpf.Program.Tag)))
+	}
+	if kexec := finding.Kexec; kexec != nil {
+		if len(kexec.KernelPath) > 0 {
+			add("kexec kernel image", stixPathPattern(kexec.KernelPath))
+		}
+		if len(kexec.InitrdPath) > 0 {
+			add("kexec initrd", stixPathPattern(kexec.InitrdPath))
+		}
+	}
+	return out
+}
+
+// newIndicator returns the indicator of a pattern, its identifier only depends on the pattern
+func (ss *stixSink) newIndicator(finding *stixFinding, name string, pattern string) *stixObject {
+	now := stixTimestamp(time.Now())
+	return &stixObject{
+		Type:           "indicator",
+		SpecVersion:    "2.1",
+		ID:             "indicator--" + uuidV5(pattern),
+		Created:        now,
+		Modified:       now,
+		CreatedByRef:   ss.identity.ID,
+		Name:           name,
+		Description:    fmt.Sprintf("%s event %s reported by KRIE (rule %s)", finding.Event.Type, finding.ID, finding.Policy.RuleID),
+		IndicatorTypes: []string{"malicious-activity"},
+		Pattern:        pattern,
+		PatternType:    "stix",
+		ValidFrom:      stixTimestamp(finding.Event.Time),
+		Confidence:     stixConfidence(finding.Policy.Confidence),
+		Labels:         []string{finding.Policy.RuleID},
+	}
+}
+
+// writeBundle writes a bundle in the directory of the sink
+func (ss *stixSink) writeBundle(objects []*stixObject) error {
+	bundle := stixBundle{
+		Type:    "bundle",
+		ID:      "bundle--" + uuidV4(),
+		Objects: objects,
+	}
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(ss.options.Directory, strings.TrimPrefix(bundle.ID, "bundle--")+".json")
+	if err = os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("couldn't write STIX bundle: %w", err)
+	}
+	return nil
+}
+
+// push adds the objects to the TAXII collection
+func (ss *stixSink) push(objects []*stixObject) error {
+	data, err := json.Marshal(struct {
+		Objects []*stixObject `json:"objects"`
+	}{Objects: objects})
+	if err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(ss.options.TAXII.URL, "/") + "/objects/"
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", taxiiContentType)
+	req.Header.Set("Accept", taxiiContentType)
+	if len(ss.options.TAXII.Username) > 0 {
+		req.SetBasicAuth(ss.options.TAXII.Username, ss.options.TAXII.Password)
+	}
+	for key, value := range ss.options.TAXII.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := ss.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code from %s: %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// stixPathPattern returns the pattern matching a file path
+func stixPathPattern(path string) string {
+	return fmt.Sprintf("[file:name = '%s' AND file:parent_directory_ref.path = '%s']", stixEscape(filepath.Base(path)), stixEscape(filepath.Dir(path)))
+}
+
+// stixEscape escapes a string literal of a STIX pattern
+func stixEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+}
+
+// stixTimestamp returns the STIX representation of a timestamp
+func stixTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// stixConfidence maps a confidence to the STIX confidence scale (0 to 100)
+func stixConfidence(confidence events.Confidence) int {
+	switch confidence {
+	case events.HighConfidence:
+		return 85
+	case events.MediumConfidence:
+		return 50
+	default:
+		return 15
+	}
+}
+
+// uuidV4 returns a random UUID
+func uuidV4() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return formatUUID(b)
+}
@@ -0,0 +1,73 @@
+//go:build !krie_minimal
+
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// webhookSink posts each event to a webhook
+type webhookSink struct {
+	options *WebhookOptions
+	client  *http.Client
+}
+
+func newWebhookSink(options *WebhookOptions) (*webhookSink, error) {
+	client, err := newHTTPClient(options.Transport, time.Duration(options.Timeout)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &webhookSink{
+		options: options,
+		client:  client,
+	}, nil
+}
+
+func (ws *webhookSink) write(evt *queuedEvent) error {
+	req, err := http.NewRequest(http.MethodPost, ws.options.URL, bytes.NewReader(evt.data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", ws.options.Format.contentType())
+	req.Header.Set("X-KRIE-Event-ID", evt.id)
+	for key, value := range ws.options.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code from %s: %d", ws.options.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+func (ws *webhookSink) close() error {
+	ws.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,432 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	spoolSegmentSuffix = ".spool"
+	// deliveredIDsCacheSize is the number of delivered event IDs remembered to deduplicate replayed events
+	deliveredIDsCacheSize = 4096
+)
+
+// SpoolOptions configures the on-disk spool of a network sink
+type SpoolOptions struct {
+	Directory     string `yaml:"directory"`
+	MaxSize       int64  `yaml:"max_size"`
+	SegmentSize   int64  `yaml:"segment_size"`
+	RetryInterval int64  `yaml:"retry_interval"`
+}
+
+// IsValid returns an error if the spool options are invalid
+func (o *SpoolOptions) IsValid() error {
+	if len(o.Directory) == 0 {
+		return nil
+	}
+	if o.SegmentSize <= 0 || o.MaxSize < o.SegmentSize {
+		return fmt.Errorf("invalid segment_size (%d) or max_size (%d)", o.SegmentSize, o.MaxSize)
+	}
+	if o.RetryInterval <= 0 {
+		return fmt.Errorf("invalid retry_interval: %d", o.RetryInterval)
+	}
+	return nil
+}
+
+// NewSpoolOptions returns a default set of spool options
+func NewSpoolOptions() *SpoolOptions {
+	return &SpoolOptions{
+		MaxSize:       100 * 1024 * 1024,
+		SegmentSize:   4 * 1024 * 1024,
+		RetryInterval: 5,
+	}
+}
+
+// spool is a bounded on-disk FIFO of serialized events. Events are appended to segment files, the oldest segment is
+// dropped when the spool is full. Each record is made of the event ID, a tab and the serialized event (which ends with
+// a new line).
+type spool struct {
+	sync.Mutex
+	options    *SpoolOptions
+	checkpoint *checkpoint
+
+	segments    []string
+	current     *os.File
+	currentSize int64
+	headOffset  int64
+	size        int64
+	nextSegment uint64
+	dropped     uint64
+}
+
+func newSpool(options *SpoolOptions, cp *checkpoint) (*spool, error) {
+	if err := os.MkdirAll(options.Directory, 0700); err != nil {
+		return nil, fmt.Errorf("couldn't create spool directory: %w", err)
+	}
+
+	s := &spool{
+		options:    options,
+		checkpoint: cp,
+	}
+
+	// reload the segments left by a previous run
+	entries, err := os.ReadDir(options.Directory)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list spool directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), spoolSegmentSuffix) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(entry.Name(), spoolSegmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		s.segments = append(s.segments, filepath.Join(options.Directory, entry.Name()))
+		s.size += info.Size()
+		if id >= s.nextSegment {
+			s.nextSegment = id + 1
+		}
+	}
+	sort.Strings(s.segments)
+
+	// resume from the last acknowledged event
+	if cp != nil && len(cp.Segment) > 0 {
+		for len(s.segments) > 0 && filepath.Base(s.segments[0]) < cp.Segment {
+			// this segment was delivered, but the agent stopped before removing it
+			if info, err := os.Stat(s.segments[0]); err == nil {
+				s.size -= info.Size()
+			}
+			_ = os.Remove(s.segments[0])
+			s.segments = s.segments[1:]
+		}
+		if len(s.segments) > 0 && filepath.Base(s.segments[0]) == cp.Segment {
+			s.headOffset = cp.Offset
+		}
+		// new segments must sort after the checkpoint
+		if id, err := strconv.ParseUint(strings.TrimSuffix(cp.Segment, spoolSegmentSuffix), 10, 64); err == nil && id >= s.nextSegment {
+			s.nextSegment = id + 1
+		}
+	}
+	return s, nil
+}
+
+func (s *spool) segmentPath(id uint64) string {
+	return filepath.Join(s.options.Directory, fmt.Sprintf("%020d%s", id, spoolSegmentSuffix))
+}
+
+// isEmpty returns true if the spool doesn't contain any event
+func (s *spool) isEmpty() bool {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.segments) == 0
+}
+
+// rotate closes the current segment, a new one will be created on the next append
+func (s *spool) rotate() {
+	if s.current != nil {
+		_ = s.current.Close()
+		s.current = nil
+		s.currentSize = 0
+	}
+}
+
+// dropOldestSegment removes the oldest segment of the spool
+func (s *spool) dropOldestSegment() {
+	if len(s.segments) == 0 {
+		return
+	}
+	if len(s.segments) == 1 {
+		s.rotate()
+	}
+	oldest := s.segments[0]
+	if info, err := os.Stat(oldest); err == nil {
+		s.size -= info.Size()
+	}
+	if data, err := os.ReadFile(oldest); err == nil {
+		s.dropped += uint64(bytes.Count(data[s.headOffset:], []byte{'\n'}))
+	}
+	_ = os.Remove(oldest)
+	s.segments = s.segments[1:]
+	s.headOffset = 0
+}
+
+// append adds an event at the end of the spool
+func (s *spool) append(id string, data []byte) error {
+	s.Lock()
+	defer s.Unlock()
+
+	recordSize := int64(len(id) + 1 + len(data))
+	for s.size+recordSize > s.options.MaxSize && len(s.segments) > 0 {
+		s.dropOldestSegment()
+	}
+
+	if s.current == nil || s.currentSize+recordSize > s.options.SegmentSize {
+		s.rotate()
+		path := s.segmentPath(s.nextSegment)
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("couldn't create spool segment: %w", err)
+		}
+		s.nextSegment++
+		s.current = f
+		s.segments = append(s.segments, path)
+	}
+
+	record := make([]byte, 0, recordSize)
+	record = append(record, id...)
+	record = append(record, '\t')
+	record = append(record, data...)
+	if _, err := s.current.Write(record); err != nil {
+		return fmt.Errorf("couldn't write to spool segment: %w", err)
+	}
+	s.currentSize += recordSize
+	s.size += recordSize
+	return nil
+}
+
+// replay sends the spooled events in order, it stops at the first error
+func (s *spool) replay(send func(id string, data []byte) error) error {
+	for {
+		s.Lock()
+		if len(s.segments) == 0 {
+			s.Unlock()
+			return nil
+		}
+		head := s.segments[0]
+		offset := s.headOffset
+		data, err := os.ReadFile(head)
+		s.Unlock()
+		if err != nil {
+			return fmt.Errorf("couldn't read spool segment: %w", err)
+		}
+		if offset > int64(len(data)) {
+			offset = int64(len(data))
+		}
+
+		// send the complete records of the segment
+		for _, record := range bytes.SplitAfter(data[offset:], []byte{'\n'}) {
+			if len(record) == 0 || record[len(record)-1] != '\n' {
+				break
+			}
+			var id string
+			if tab := bytes.IndexByte(record, '\t'); tab >= 0 {
+				id = string(record[:tab])
+				if err = send(id, record[tab+1:]); err != nil {
+					s.saveCheckpoint()
+					return err
+				}
+			}
+			offset += int64(len(record))
+			s.Lock()
+			if len(s.segments) > 0 && s.segments[0] == head {
+				s.headOffset = offset
+			}
+			s.Unlock()
+			if s.checkpoint != nil {
+				if err = s.checkpoint.acknowledge(filepath.Base(head), offset, id); err != nil {
+					logrus.Warnf("couldn't save spool checkpoint: %v", err)
+				}
+			}
+		}
+
+		s.Lock()
+		if len(s.segments) == 0 || s.segments[0] != head {
+			// the segment was dropped while we were sending it
+			s.Unlock()
+			continue
+		}
+		if len(s.segments) == 1 && s.currentSize > s.headOffset {
+			// new events were appended to the active segment
+			s.Unlock()
+			continue
+		}
+		if len(s.segments) == 1 {
+			s.rotate()
+		}
+		if info, err := os.Stat(head); err == nil {
+			s.size -= info.Size()
+		}
+		_ = os.Remove(head)
+		s.segments = s.segments[1:]
+		s.headOffset = 0
+		s.Unlock()
+		s.saveCheckpoint()
+	}
+}
+
+// saveCheckpoint persists the position of the last acknowledged event
+func (s *spool) saveCheckpoint() {
+	if s.checkpoint == nil || s.checkpoint.pending == 0 {
+		return
+	}
+	if err := s.checkpoint.save(); err != nil {
+		logrus.Warnf("couldn't save spool checkpoint: %v", err)
+	}
+}
+
+// flushDropped returns and resets the number of events dropped because the spool was full
+func (s *spool) flushDropped() uint64 {
+	s.Lock()
+	defer s.Unlock()
+	out := s.dropped
+	s.dropped = 0
+	return out
+}
+
+func (s *spool) close() error {
+	s.saveCheckpoint()
+	s.Lock()
+	defer s.Unlock()
+	s.rotate()
+	return nil
+}
+
+// spooledSink wraps a network sink: events are spooled to disk while the sink is unreachable, and replayed once it is
+// reachable again. Replayed events are deduplicated by event ID, and the delivery position is checkpointed in the state
+// directory so that a restart resumes the replay from the last acknowledged event.
+type spooledSink struct {
+	name  string
+	sink  eventSink
+	spool *spool
+
+	deliveredLock *sync.Mutex
+	delivered     map[string]struct{}
+	deliveredRing []string
+	deliveredNext int
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newSpooledSink(name string, sink eventSink, options *SpoolOptions, stateDirectory string) (*spooledSink, error) {
+	var cp *checkpoint
+	if len(stateDirectory) > 0 {
+		var err error
+		if cp, err = loadCheckpoint(stateDirectory, name); err != nil {
+			return nil, err
+		}
+	}
+
+	s, err := newSpool(options, cp)
+	if err != nil {
+		return nil, err
+	}
+
+	ss := &spooledSink{
+		name:          name,
+		sink:          sink,
+		spool:         s,
+		deliveredLock: &sync.Mutex{},
+		delivered:     make(map[string]struct{}),
+		deliveredRing: make([]string, deliveredIDsCacheSize),
+		stop:          make(chan struct{}),
+	}
+
+	ss.wg.Add(1)
+	go ss.replayLoop(time.Duration(options.RetryInterval) * time.Second)
+	return ss, nil
+}
+
+func (ss *spooledSink) isDelivered(id string) bool {
+	ss.deliveredLock.Lock()
+	defer ss.deliveredLock.Unlock()
+	_, ok := ss.delivered[id]
+	return ok
+}
+
+func (ss *spooledSink) markDelivered(id string) {
+	ss.deliveredLock.Lock()
+	defer ss.deliveredLock.Unlock()
+	if evicted := ss.deliveredRing[ss.deliveredNext]; len(evicted) > 0 {
+		delete(ss.delivered, evicted)
+	}
+	ss.deliveredRing[ss.deliveredNext] = id
+	ss.delivered[id] = struct{}{}
+	ss.deliveredNext = (ss.deliveredNext + 1) % len(ss.deliveredRing)
+}
+
+func (ss *spooledSink) write(evt *queuedEvent) error {
+	// keep the events ordered: once the spool isn't empty, new events are spooled until the spool is replayed
+	if ss.spool.isEmpty() {
+		err := ss.sink.write(evt)
+		if err == nil {
+			ss.markDelivered(evt.id)
+			return nil
+		}
+		logrus.Warnf("sink %s is unreachable, spooling events to %s: %v", ss.name, ss.spool.options.Directory, err)
+	}
+	return ss.spool.append(evt.id, evt.data)
+}
+
+func (ss *spooledSink) replayLoop(interval time.Duration) {
+	defer ss.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ss.stop:
+			return
+		case <-ticker.C:
+			if dropped := ss.spool.flushDropped(); dropped > 0 {
+				logrus.Warnf("sink %s dropped %d spooled event(s): spool full (max_size: %d)", ss.name, dropped, ss.spool.options.MaxSize)
+			}
+			if ss.spool.isEmpty() {
+				continue
+			}
+			if err := ss.spool.replay(ss.replayEvent); err != nil {
+				logrus.Debugf("sink %s is still unreachable: %v", ss.name, err)
+				continue
+			}
+			logrus.Infof("sink %s is reachable again, spooled events were replayed", ss.name)
+		}
+	}
+}
+
+func (ss *spooledSink) replayEvent(id string, data []byte) error {
+	if ss.isDelivered(id) {
+		return nil
+	}
+	if err := ss.sink.write(&queuedEvent{id: id, data: data}); err != nil {
+		return err
+	}
+	ss.markDelivered(id)
+	return nil
+}
+
+func (ss *spooledSink) close() error {
+	close(ss.stop)
+	ss.wg.Wait()
+	_ = ss.spool.close()
+	return ss.sink.close()
+}
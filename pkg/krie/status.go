@@ -0,0 +1,230 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StatusOptions configures the status file: a small JSON document periodically written by KRIE, so that external
+// tooling (health checks, independent integrity monitors) can cross-check its state without talking to the agent
+type StatusOptions struct {
+	// Path is the path of the status file, leave empty to disable it
+	Path string `yaml:"path"`
+	// Interval is the number of seconds between two updates of the status file
+	Interval int64 `yaml:"interval"`
+}
+
+// IsValid returns an error if the status options are invalid
+func (o *StatusOptions) IsValid() error {
+	if len(o.Path) > 0 && o.Interval <= 0 {
+		return fmt.Errorf("invalid interval: %d", o.Interval)
+	}
+	return nil
+}
+
+// NewStatusOptions returns a default set of status options
+func NewStatusOptions() *StatusOptions {
+	return &StatusOptions{
+		Interval: 10,
+	}
+}
+
+// Status is the content of the status file
+type Status struct {
+	PID             int        `json:"pid"`
+	Running         bool       `json:"running"`
+	StartedAt       time.Time  `json:"started_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	LastEvent       *time.Time `json:"last_event,omitempty"`
+	PolicyVersion   string     `json:"policy_version,omitempty"`
+	PolicyHash      string     `json:"policy_hash"`
+	Lockdown        string     `json:"lockdown,omitempty"`
+	BytecodeVariant string     `json:"bytecode_variant,omitempty"`
+	RunningProbes   int        `json:"running_probes"`
+	PendingProbes   int        `json:"pending_probes"`
+	ProbesHealthy   bool       `json:"probes_healthy"`
+}
+
+// statusWriter periodically writes the status file
+type statusWriter struct {
+	krie      *KRIE
+	options   *StatusOptions
+	startedAt time.Time
+	lastEvent int64
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newStatusWriter(krie *KRIE, options *StatusOptions) *statusWriter {
+	return &statusWriter{
+		krie:    krie,
+		options: options,
+		stop:    make(chan struct{}),
+	}
+}
+
+// record records the reception time of a kernel event
+func (sw *statusWriter) record(at time.Time) {
+	atomic.StoreInt64(&sw.lastEvent, at.UnixNano())
+}
+
+func (sw *statusWriter) start() error {
+	sw.startedAt = time.Now()
+	// fail early on a missing or read-only directory
+	if err := sw.write(true); err != nil {
+		return err
+	}
+	sw.wg.Add(1)
+	go func() {
+		defer sw.wg.Done()
+		ticker := time.NewTicker(time.Duration(sw.options.Interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-sw.stop:
+				return
+			case <-ticker.C:
+				if err := sw.write(true); err != nil {
+					logrus.Errorf("%v", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// close stops the periodic updates and marks the agent as stopped in the status file
+func (sw *statusWriter) close() {
+	close(sw.stop)
+	sw.wg.Wait()
+	if err := sw.write(false); err != nil {
+		logrus.Errorf("%v", err)
+	}
+}
+
+// currentLockdownMode returns the active lockdown mode, the one between brackets in /sys/kernel/security/lockdown
+func currentLockdownMode() string {
+	for _, mode := range strings.Fields(readTrimmedFile("/sys/kernel/security/lockdown")) {
+		if strings.HasPrefix(mode, "[") && strings.HasSuffix(mode, "]") {
+			return strings.Trim(mode, "[]")
+		}
+	}
+	return ""
+}
+
+func (sw *statusWriter) status(running bool) Status {
+	_, policyVersion, policyHash := sw.krie.currentPolicies()
+	status := Status{
+		PID:           os.Getpid(),
+		Running:       running,
+		StartedAt:     sw.startedAt,
+		UpdatedAt:     time.Now(),
+		PolicyVersion: policyVersion,
+		PolicyHash:    policyHash,
+		Lockdown:      currentLockdownMode(),
+	}
+	if last := atomic.LoadInt64(&sw.lastEvent); last > 0 {
+		lastEvent := time.Unix(0, last)
+		status.LastEvent = &lastEvent
+	}
+	if variant := sw.krie.BytecodeVariant(); variant != nil {
+		status.BytecodeVariant = variant.Name
+	}
+	if running {
+		status.RunningProbes, status.ProbesHealthy = sw.krie.runningProbes()
+	}
+	if sw.krie.symbols != nil {
+		sw.krie.symbols.lock.Lock()
+		status.PendingProbes = sw.krie.symbols.pendingProbes()
+		sw.krie.symbols.lock.Unlock()
+	}
+	return status
+}
+
+// write atomically replaces the status file
+func (sw *statusWriter) write(running bool) error {
+	data, err := json.Marshal(sw.status(running))
+	if err != nil {
+		return fmt.Errorf("couldn't marshal status: %w", err)
+	}
+	tmp := filepath.Join(filepath.Dir(sw.options.Path), "."+filepath.Base(sw.options.Path)+".tmp")
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("couldn't write status file: %w", err)
+	}
+	if err = os.Rename(tmp, sw.options.Path); err != nil {
+		return fmt.Errorf("couldn't write status file: %w", err)
+	}
+	return nil
+}
+
+const (
+	// ProbeRunning is the state of an attached probe
+	ProbeRunning = "running"
+	// ProbeStopped is the state of an initialized probe which isn't attached, because its event type isn't activated
+	// or because it was detached
+	ProbeStopped = "stopped"
+	// ProbeUnavailable is the state of a probe which couldn't be initialized, usually because its hook point doesn't
+	// exist on the running kernel
+	ProbeUnavailable = "unavailable"
+)
+
+// ProbeStatus is the state of an eBPF program of KRIE
+type ProbeStatus struct {
+	Section string `json:"section"`
+	Func    string `json:"func"`
+	State   string `json:"state"`
+}
+
+// ProbeStatuses returns the state of the eBPF programs of KRIE, sorted by section
+func (e *KRIE) ProbeStatuses() []ProbeStatus {
+	if e.manager == nil {
+		return nil
+	}
+	out := make([]ProbeStatus, 0, len(e.manager.Probes))
+	for _, probe := range e.manager.Probes {
+		status := ProbeStatus{
+			Section: probe.EBPFSection,
+			Func:    probe.EBPFFuncName,
+			State:   ProbeUnavailable,
+		}
+		if probe.IsRunning() {
+			status.State = ProbeRunning
+		} else if probe.IsInitialized() {
+			status.State = ProbeStopped
+		}
+		out = append(out, status)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Section != out[j].Section {
+			return out[i].Section < out[j].Section
+		}
+		return out[i].Func < out[j].Func
+	})
+	return out
+}
@@ -0,0 +1,427 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	// StorageSinkName is the name of the sink writing events to the local store
+	StorageSinkName = "storage"
+
+	// FileStorageType is the type of the built-in storage, a JSON lines file
+	FileStorageType = "file"
+)
+
+// StoredEvent is an event of a Storage, with the fields used to select it
+type StoredEvent struct {
+	ID   string
+	Type events.EventType
+	// Time is the kernel timestamp of the event
+	Time time.Time
+	// RuleID is the rule of the event, the events without a rule are stored under their type
+	RuleID string
+	Comm   string
+	// Data is the JSON representation of the event, terminated by a new line
+	Data []byte
+}
+
+// storedEventHeader is the subset of the JSON representation of an event parsed by ParseStoredEvent
+type storedEventHeader struct {
+	ID    string `json:"id"`
+	Event *struct {
+		Time time.Time `json:"time"`
+		Type string    `json:"type"`
+	} `json:"event"`
+	Process *struct {
+		Comm string `json:"comm"`
+	} `json:"process"`
+	Policy *struct {
+		RuleID string `json:"rule_id"`
+	} `json:"policy"`
+}
+
+// ParseStoredEvent parses the fields used to select the provided JSON event, so that the Storage backends can index
+// the events they receive
+func ParseStoredEvent(data []byte) (*StoredEvent, error) {
+	var header storedEventHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	if header.Event == nil {
+		return nil, fmt.Errorf("missing event section")
+	}
+	event := &StoredEvent{
+		ID:     header.ID,
+		Type:   events.ParseEventType(header.Event.Type),
+		Time:   header.Event.Time,
+		RuleID: header.Event.Type,
+		Data:   data,
+	}
+	if header.Process != nil {
+		event.Comm = header.Process.Comm
+	}
+	if header.Policy != nil && len(header.Policy.RuleID) > 0 {
+		event.RuleID = header.Policy.RuleID
+	}
+	if len(data) == 0 || data[len(data)-1] != '\n' {
+		event.Data = append(append(make([]byte, 0, len(data)+1), data...), '\n')
+	}
+	return event, nil
+}
+
+// StorageQuery selects the events of a Storage, the zero value matches every event
+type StorageQuery struct {
+	// Since and Until bound the kernel timestamps of the events, a zero time is unbounded
+	Since time.Time
+	Until time.Time
+	// Types are the event types to return, empty for all the event types
+	Types []events.EventType
+	// RuleID matches the events of a rule and of its sub rules (for example "sysctl" matches
+	// "sysctl/kernel/yama/ptrace_scope"), the events without a rule are matched by their type
+	RuleID string
+	// Limit is the maximum number of events to return, 0 for no limit
+	Limit int
+}
+
+// Matches returns true if the provided event is selected by the query. Storage backends which can't filter natively
+// call it on each event.
+func (q *StorageQuery) Matches(event *StoredEvent) bool {
+	if !q.Since.IsZero() && event.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && event.Time.After(q.Until) {
+		return false
+	}
+	if len(q.RuleID) > 0 && !matchRules([]string{q.RuleID}, event.RuleID) {
+		return false
+	}
+	if len(q.Types) == 0 {
+		return true
+	}
+	for _, eventType := range q.Types {
+		if event.Type == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Storage is the local store of the events, queried by the query command and by the report generators. A Storage is a
+// Sink: the events are queued, batched and retried by the pipeline before they reach Send, in the JSON format. Query
+// may be called without Start, to read the store of a stopped KRIE, and concurrently with Send.
+type Storage interface {
+	Sink
+	// Query calls fn with the events selected by the query, in the order they were stored, until fn returns false
+	Query(query StorageQuery, fn func(event *StoredEvent) bool) error
+}
+
+// StorageFactory creates a Storage from the "config" section of its configuration. decode unmarshals the "config"
+// section into the provided value, and is a no-op if the section is empty.
+type StorageFactory func(decode func(out interface{}) error) (Storage, error)
+
+var (
+	storageFactoriesLock sync.RWMutex
+	storageFactories     = map[string]StorageFactory{
+		FileStorageType: newFileStorage,
+	}
+)
+
+// RegisterStorage registers a storage type (SQLite, bbolt, a remote database, ...), it is usually called from the init
+// function of the package of the storage. The storage is then selected in the "storage" section of the configuration
+// file. RegisterStorage panics if the storage type is already registered.
+func RegisterStorage(storageType string, factory StorageFactory) {
+	storageFactoriesLock.Lock()
+	defer storageFactoriesLock.Unlock()
+
+	if factory == nil {
+		panic("krie: nil factory for storage type " + storageType)
+	}
+	if _, ok := storageFactories[storageType]; ok {
+		panic("krie: storage type " + storageType + " registered twice")
+	}
+	storageFactories[storageType] = factory
+}
+
+func getStorageFactory(storageType string) (StorageFactory, bool) {
+	storageFactoriesLock.RLock()
+	defer storageFactoriesLock.RUnlock()
+	factory, ok := storageFactories[storageType]
+	return factory, ok
+}
+
+// StorageOptions configures the local store of the events
+type StorageOptions struct {
+	// Type is the storage type, leave empty to query the JSON output file instead of a dedicated store
+	Type          string    `yaml:"type"`
+	BatchSize     int       `yaml:"batch_size"`
+	FlushInterval int64     `yaml:"flush_interval"`
+	MaxRetries    int       `yaml:"max_retries"`
+	Config        yaml.Node `yaml:"config"`
+	// Retention is how long the events of each event type are kept, the storage must implement StorageCompactor
+	Retention *RetentionOptions `yaml:"retention"`
+}
+
+// IsValid returns an error if the storage options are invalid
+func (o *StorageOptions) IsValid() error {
+	if err := o.Retention.IsValid(); err != nil {
+		return fmt.Errorf("invalid retention: %w", err)
+	}
+	if len(o.Type) == 0 {
+		if !o.Retention.policy().IsEmpty() {
+			return fmt.Errorf("retention requires a storage type")
+		}
+		return nil
+	}
+	if _, ok := getStorageFactory(o.Type); !ok {
+		return fmt.Errorf("unknown storage type: %s", o.Type)
+	}
+	if o.BatchSize < 0 {
+		return fmt.Errorf("invalid batch_size: %d", o.BatchSize)
+	}
+	if o.FlushInterval < 0 {
+		return fmt.Errorf("invalid flush_interval: %d", o.FlushInterval)
+	}
+	if o.MaxRetries < 0 {
+		return fmt.Errorf("invalid max_retries: %d", o.MaxRetries)
+	}
+	return nil
+}
+
+// NewStorageOptions returns a default set of storage options
+func NewStorageOptions() *StorageOptions {
+	return &StorageOptions{
+		BatchSize:     100,
+		FlushInterval: 1,
+		MaxRetries:    3,
+		Retention:     NewRetentionOptions(),
+	}
+}
+
+// decode unmarshals the config section of the storage
+func (o *StorageOptions) decode(out interface{}) error {
+	if o.Config.Kind == 0 {
+		return nil
+	}
+	return o.Config.Decode(out)
+}
+
+// sinkOptions returns the options of the sink writing to the storage, the events are always stored in JSON
+func (o *StorageOptions) sinkOptions() *SinkOptions {
+	return &SinkOptions{
+		Type:          o.Type,
+		BatchSize:     o.BatchSize,
+		FlushInterval: o.FlushInterval,
+		MaxRetries:    o.MaxRetries,
+		Format:        JSONFormat,
+	}
+}
+
+// newStorage creates the storage of the provided options
+func newStorage(options *StorageOptions) (Storage, error) {
+	factory, ok := getStorageFactory(options.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown storage type: %s", options.Type)
+	}
+	storage, err := factory(options.decode)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create %s storage: %w", options.Type, err)
+	}
+	return storage, nil
+}
+
+// OpenStorage opens the local store of the provided configuration, to query the events. When no storage type is
+// configured, the JSON output file is queried.
+func OpenStorage(options *Options) (Storage, error) {
+	if options.Storage == nil || len(options.Storage.Type) == 0 {
+		if len(options.Output) == 0 || options.Output == StdoutOutput {
+			return nil, fmt.Errorf("no storage configured and the events aren't written to a file")
+		}
+		return &fileStorage{path: options.Output}, nil
+	}
+	return newStorage(options.Storage)
+}
+
+// OpenFileStorage opens a JSON lines file of events as a Storage
+func OpenFileStorage(path string) Storage {
+	return &fileStorage{path: path}
+}
+
+// fileStorage is the built-in storage: the events are appended to a JSON lines file, and the queries scan the file
+type fileStorage struct {
+	path string
+
+	lock sync.Mutex
+	file *os.File
+}
+
+// fileStorageConfig is the config section of the file storage
+type fileStorageConfig struct {
+	Path string `yaml:"path"`
+}
+
+func newFileStorage(decode func(out interface{}) error) (Storage, error) {
+	var config fileStorageConfig
+	if err := decode(&config); err != nil {
+		return nil, err
+	}
+	if len(config.Path) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	return &fileStorage{path: config.Path}, nil
+}
+
+func (fs *fileStorage) Start() error {
+	f, err := os.OpenFile(fs.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("couldn't open storage file: %w", err)
+	}
+	fs.lock.Lock()
+	fs.file = f
+	fs.lock.Unlock()
+	return nil
+}
+
+func (fs *fileStorage) Send(batch []SinkEvent) error {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+	if fs.file == nil {
+		return fmt.Errorf("storage file %s isn't open", fs.path)
+	}
+	for _, evt := range batch {
+		if _, err := fs.file.Write(evt.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fs *fileStorage) Flush() error {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+	if fs.file == nil {
+		return nil
+	}
+	return fs.file.Sync()
+}
+
+func (fs *fileStorage) Stop() error {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+	if fs.file == nil {
+		return nil
+	}
+	err := fs.file.Close()
+	fs.file = nil
+	return err
+}
+
+// Compact rewrites the file without the expired events, the lines that aren't events are kept
+func (fs *fileStorage) Compact(policy *RetentionPolicy, now time.Time) (map[events.EventType]int, error) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	in, err := os.Open(fs.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer in.Close()
+
+	tmp := filepath.Join(filepath.Dir(fs.path), "."+filepath.Base(fs.path)+".compact")
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create compacted file: %w", err)
+	}
+	defer os.Remove(tmp)
+
+	removed := make(map[events.EventType]int)
+	writer := bufio.NewWriter(out)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if event, err := ParseStoredEvent(scanner.Bytes()); err == nil && policy.Expired(event, now) {
+			removed[event.Type]++
+			continue
+		}
+		_, _ = writer.Write(scanner.Bytes())
+		_ = writer.WriteByte('\n')
+	}
+	if err = scanner.Err(); err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || len(removed) == 0 {
+		return nil, err
+	}
+	if err = os.Rename(tmp, fs.path); err != nil {
+		return nil, fmt.Errorf("couldn't replace storage file: %w", err)
+	}
+
+	// the pending writes go to the compacted file
+	if fs.file != nil {
+		_ = fs.file.Close()
+		if fs.file, err = os.OpenFile(fs.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644); err != nil {
+			return removed, fmt.Errorf("couldn't reopen storage file: %w", err)
+		}
+	}
+	return removed, nil
+}
+
+func (fs *fileStorage) Query(query StorageQuery, fn func(event *StoredEvent) bool) error {
+	f, err := os.Open(fs.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var count int
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		event, err := ParseStoredEvent(append([]byte{}, scanner.Bytes()...))
+		if err != nil {
+			// ignore the lines that aren't events
+			continue
+		}
+		if !query.Matches(event) {
+			continue
+		}
+		if !fn(event) {
+			return nil
+		}
+		if count++; query.Limit > 0 && count >= query.Limit {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,49 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"sync"
+)
+
+// maxSymbolResolutionProcesses is the maximum number of processes tracked by the symbol resolution counter, the counts
+// are reset once the limit is reached
+const maxSymbolResolutionProcesses = 4096
+
+// symbolResolutionCounter counts the symbol resolutions of each process, so that the repeated resolutions stand out
+type symbolResolutionCounter struct {
+	lock   sync.Mutex
+	counts map[uint32]uint64
+}
+
+func newSymbolResolutionCounter() *symbolResolutionCounter {
+	return &symbolResolutionCounter{
+		counts: make(map[uint32]uint64),
+	}
+}
+
+// add records a symbol resolution of a process, and returns the number of resolutions of the process
+func (sc *symbolResolutionCounter) add(pid uint32) uint64 {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	if _, ok := sc.counts[pid]; !ok && len(sc.counts) >= maxSymbolResolutionProcesses {
+		sc.counts = make(map[uint32]uint64)
+	}
+	sc.counts[pid]++
+	return sc.counts[pid]
+}
@@ -0,0 +1,290 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	manager "github.com/DataDog/ebpf-manager"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// SymbolWatcherOptions configures the attachment of the probes whose symbol wasn't available at startup
+type SymbolWatcherOptions struct {
+	// Interval is the number of seconds between two checks of the loaded modules, 0 disables the watcher
+	Interval int64 `yaml:"interval"`
+}
+
+// IsValid returns an error if the symbol watcher options are invalid
+func (o *SymbolWatcherOptions) IsValid() error {
+	if o.Interval < 0 {
+		return fmt.Errorf("invalid interval: %d", o.Interval)
+	}
+	return nil
+}
+
+// NewSymbolWatcherOptions returns a default set of symbol watcher options
+func NewSymbolWatcherOptions() *SymbolWatcherOptions {
+	return &SymbolWatcherOptions{
+		Interval: 10,
+	}
+}
+
+// watchedSymbol is a module symbol of probes attached by the symbol watcher
+type watchedSymbol struct {
+	module string
+	probes []*manager.Probe
+}
+
+// symbolWatcher attaches the kprobes that couldn't be attached at startup once a module provides their symbol, and
+// puts them back in the pending list when the module is unloaded
+type symbolWatcher struct {
+	krie     *KRIE
+	interval int64
+	trigger  chan struct{}
+	stop     chan struct{}
+	wg       sync.WaitGroup
+
+	lock     sync.Mutex
+	modules  string
+	pending  map[string][]*manager.Probe
+	attached map[string]*watchedSymbol
+}
+
+func newSymbolWatcher(krie *KRIE, options *SymbolWatcherOptions) *symbolWatcher {
+	return &symbolWatcher{
+		krie:     krie,
+		interval: options.Interval,
+		trigger:  make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		pending:  make(map[string][]*manager.Probe),
+		attached: make(map[string]*watchedSymbol),
+	}
+}
+
+// probeSymbol returns the kernel symbol of a kprobe, or an empty string for the other program types and the syscall
+// probes (a module can't provide a syscall)
+func probeSymbol(probe *manager.Probe) string {
+	var symbol string
+	switch {
+	case strings.HasPrefix(probe.EBPFSection, "kprobe/"):
+		symbol = strings.TrimPrefix(probe.EBPFSection, "kprobe/")
+	case strings.HasPrefix(probe.EBPFSection, "kretprobe/"):
+		symbol = strings.TrimPrefix(probe.EBPFSection, "kretprobe/")
+	default:
+		return ""
+	}
+	if strings.Contains(symbol, "sys_") && (strings.HasPrefix(symbol, "sys_") || strings.HasPrefix(symbol, "SyS_") || strings.HasPrefix(symbol, "__")) {
+		return ""
+	}
+	return symbol
+}
+
+// start lists the kprobes that aren't running, and watches the loaded modules if there is at least one
+func (sw *symbolWatcher) start(probes []*manager.Probe) bool {
+	for _, probe := range probes {
+		if !probe.IsInitialized() || probe.IsRunning() {
+			continue
+		}
+		if symbol := probeSymbol(probe); len(symbol) > 0 {
+			sw.pending[symbol] = append(sw.pending[symbol], probe)
+		}
+	}
+	if len(sw.pending) == 0 {
+		return false
+	}
+	sw.modules = readLoadedModules()
+	logrus.Infof("%d kprobe(s) pending, waiting for a module to provide their symbol", sw.pendingProbes())
+
+	sw.wg.Add(1)
+	go func() {
+		defer sw.wg.Done()
+		ticker := time.NewTicker(time.Duration(sw.interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-sw.stop:
+				return
+			case <-ticker.C:
+				sw.check()
+			case <-sw.trigger:
+				sw.check()
+			}
+		}
+	}()
+	return true
+}
+
+func (sw *symbolWatcher) close() {
+	close(sw.stop)
+	sw.wg.Wait()
+}
+
+// notify schedules a check after a module was loaded or unloaded
+func (sw *symbolWatcher) notify() {
+	select {
+	case sw.trigger <- struct{}{}:
+	default:
+		// a check is already pending
+	}
+}
+
+// pendingProbes returns the number of probes waiting for their symbol, the lock must be held
+func (sw *symbolWatcher) pendingProbes() int {
+	var count int
+	for _, probes := range sw.pending {
+		count += len(probes)
+	}
+	return count
+}
+
+// check attaches the pending probes whose symbol is now available, and detaches the probes whose module was unloaded
+func (sw *symbolWatcher) check() {
+	modules := readLoadedModules()
+
+	sw.lock.Lock()
+	if modules == sw.modules {
+		sw.lock.Unlock()
+		return
+	}
+	sw.modules = modules
+
+	wanted := make(map[string]bool)
+	for symbol := range sw.pending {
+		wanted[symbol] = true
+	}
+	for symbol := range sw.attached {
+		wanted[symbol] = true
+	}
+	available := readModuleSymbols(wanted)
+
+	var reports []*events.Event
+	for symbol, watched := range sw.attached {
+		if _, ok := available[symbol]; ok {
+			continue
+		}
+		for _, probe := range watched.probes {
+			_ = probe.Detach()
+		}
+		delete(sw.attached, symbol)
+		sw.pending[symbol] = append(sw.pending[symbol], watched.probes...)
+		logrus.Warnf("module %s was unloaded, %d probe(s) on %s are pending again", watched.module, len(watched.probes), symbol)
+		reports = append(reports, newCoverageChangeEvent(symbol, watched.module, events.CoverageDetached, watched.probes, nil))
+	}
+
+	for symbol, probes := range sw.pending {
+		module, ok := available[symbol]
+		if !ok {
+			continue
+		}
+		var attached, failed []*manager.Probe
+		var errs []string
+		for _, probe := range probes {
+			if err := probe.Attach(); err != nil {
+				failed = append(failed, probe)
+				errs = append(errs, fmt.Sprintf("%s: %v", probe.EBPFSection, err))
+				continue
+			}
+			attached = append(attached, probe)
+		}
+		if len(attached) > 0 {
+			sw.attached[symbol] = &watchedSymbol{module: module, probes: attached}
+			logrus.Infof("module %s provides %s, %d pending probe(s) attached", module, symbol, len(attached))
+			reports = append(reports, newCoverageChangeEvent(symbol, module, events.CoverageAttached, attached, nil))
+		}
+		if len(failed) > 0 {
+			sw.pending[symbol] = failed
+			logrus.Warnf("couldn't attach the probes of %s: %s", symbol, strings.Join(errs, ", "))
+			reports = append(reports, newCoverageChangeEvent(symbol, module, events.CoverageError, failed, errs))
+		} else {
+			delete(sw.pending, symbol)
+		}
+	}
+	pending := sw.pendingProbes()
+	sw.lock.Unlock()
+
+	for _, report := range reports {
+		report.CoverageChangeEvent.Pending = pending
+		if err := sw.krie.emitEvent(report); err != nil {
+			logrus.Errorf("couldn't emit coverage_change event: %v", err)
+		}
+	}
+}
+
+// newCoverageChangeEvent returns a coverage_change event for the provided probes
+func newCoverageChangeEvent(symbol string, module string, status string, probes []*manager.Probe, errs []string) *events.Event {
+	event := events.NewEvent()
+	event.Kernel.Type = events.CoverageChangeEventType
+	event.CoverageChangeEvent = events.CoverageChangeEvent{
+		Symbol: symbol,
+		Module: module,
+		Status: status,
+		Error:  strings.Join(errs, ", "),
+	}
+	for _, probe := range probes {
+		event.CoverageChangeEvent.Probes = append(event.CoverageChangeEvent.Probes, probe.EBPFSection)
+	}
+	return event
+}
+
+// readLoadedModules returns the sorted names of the loaded modules, as a single string
+func readLoadedModules() string {
+	f, err := os.Open("/proc/modules")
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var modules []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) > 0 {
+			modules = append(modules, fields[0])
+		}
+	}
+	sort.Strings(modules)
+	return strings.Join(modules, ",")
+}
+
+// readModuleSymbols returns the module of each wanted symbol provided by a loaded module
+func readModuleSymbols(wanted map[string]bool) map[string]string {
+	available := make(map[string]string)
+	f, err := os.Open("/proc/kallsyms")
+	if err != nil {
+		return available
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// the symbols of the modules have a 4th field: "address type name [module]"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !wanted[fields[2]] {
+			continue
+		}
+		available[fields[2]] = strings.Trim(fields[3], "[]")
+	}
+	return available
+}
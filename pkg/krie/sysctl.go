@@ -18,11 +18,79 @@ package krie
 
 import (
 	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
 )
 
+// procSysPath is the root of the sysctl parameters
+const procSysPath = "/proc/sys"
+
+// resolveSysCtlParameters returns the parameters to push to kernel space: the configured list, along with the
+// parameters of the provided /proc/sys directory that match the capture_reads patterns. An error is returned if they
+// don't fit in the sysctl_parameters map.
+func resolveSysCtlParameters(opts *events.SysCtlOptions, root string) (map[string]events.SysCtlParameter, error) {
+	out := make(map[string]events.SysCtlParameter, len(opts.List))
+	for name, param := range opts.List {
+		if opts.CapturesReads(name) {
+			param.CaptureReadAccess = true
+		}
+		out[name] = param
+	}
+	if len(opts.CaptureReads) == 0 {
+		return out, nil
+	}
+
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			// some directories can't be listed, keep walking
+			return nil
+		}
+		name, err := filepath.Rel(root, path)
+		if err != nil || !opts.CapturesReads(name) {
+			return nil
+		}
+		if _, ok := out[name]; ok {
+			return nil
+		}
+		param := opts.Default
+		param.CaptureReadAccess = true
+		out[name] = param
+		return nil
+	})
+	if len(out) > events.MaxSysCtlParameters {
+		return nil, fmt.Errorf("the capture_reads patterns match %d parameters, the maximum is %d", len(out), events.MaxSysCtlParameters)
+	}
+	return out, nil
+}
+
+// removedSysCtlParameters returns the sorted names of the parameters of previous which are no longer in current
+func removedSysCtlParameters(previous *events.SysCtlOptions, current *events.SysCtlOptions, root string) []string {
+	// the previous parameters were loaded, the current ones are validated before they replace them
+	before, _ := resolveSysCtlParameters(previous, root)
+	after, _ := resolveSysCtlParameters(current, root)
+
+	var out []string
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
 func (e *KRIE) loadSysCtlParameters() error {
 	// load parameters
-	for name, param := range e.options.Events.SysCtlEvent.List {
+	policies := e.options.Events
+	params, err := resolveSysCtlParameters(policies.SysCtlEvent, procSysPath)
+	if err != nil {
+		return fmt.Errorf("invalid sysctl section: %w", err)
+	}
+	for name, param := range params {
+		param = policies.MonitoredSysCtlParameter(fmt.Sprintf("%s/%s", events.SysCtlEventType, name), param)
 		b := make([]byte, 256)
 		copy(b, name)
 		if err := e.sysctlParametersMap.Put(b, &param); err != nil {
@@ -32,7 +100,8 @@ func (e *KRIE) loadSysCtlParameters() error {
 
 	// load defaults
 	key := uint32(0)
-	if err := e.sysctlDefaultMap.Put(key, &e.options.Events.SysCtlEvent.Default); err != nil {
+	defaultParam := policies.MonitoredSysCtlParameter(fmt.Sprintf("%s/default", events.SysCtlEventType), policies.SysCtlEvent.Default)
+	if err := e.sysctlDefaultMap.Put(key, &defaultParam); err != nil {
 		return fmt.Errorf("failed to push default SysCtlParameter: %w", err)
 	}
 	return nil
@@ -0,0 +1,149 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// SysCtlRevertResponder is the responder name of the response events generated by the sysctl reverts
+const SysCtlRevertResponder = "sysctl_revert"
+
+// sysctlReverter writes back the pinned value of the sysctl parameters that drifted away from it. It covers the
+// kernels where the cgroup/sysctl programs can't block the writes.
+type sysctlReverter struct {
+	krie    *KRIE
+	trigger chan string
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newSysCtlReverter(krie *KRIE) *sysctlReverter {
+	return &sysctlReverter{
+		krie:    krie,
+		trigger: make(chan string, 1),
+		stop:    make(chan struct{}),
+	}
+}
+
+func (sr *sysctlReverter) start(interval int64) {
+	sr.wg.Add(1)
+	go func() {
+		defer sr.wg.Done()
+		var tick <-chan time.Time
+		if interval > 0 {
+			ticker := time.NewTicker(time.Duration(interval) * time.Second)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		for {
+			select {
+			case <-sr.stop:
+				return
+			case <-tick:
+				sr.check("")
+			case eventID := <-sr.trigger:
+				sr.check(eventID)
+			}
+		}
+	}()
+}
+
+func (sr *sysctlReverter) close() {
+	close(sr.stop)
+	sr.wg.Wait()
+}
+
+// notify schedules a check of the pinned parameters after the provided sysctl write event
+func (sr *sysctlReverter) notify(eventID string) {
+	select {
+	case sr.trigger <- eventID:
+	default:
+		// a check is already pending
+	}
+}
+
+// normalizeSysCtlValue trims the trailing new line and collapses the separators of multi-value parameters
+func normalizeSysCtlValue(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// check compares each pinned parameter with its pinned value, and reverts the parameters that drifted
+func (sr *sysctlReverter) check(triggerEventID string) {
+	policies, _, _ := sr.krie.currentPolicies()
+	for name, pinned := range policies.SysCtlEvent.PinnedParameters() {
+		path := filepath.Join(procSysPath, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logrus.Debugf("couldn't read sysctl parameter %s: %v", name, err)
+			continue
+		}
+		current := normalizeSysCtlValue(string(data))
+		if current == normalizeSysCtlValue(pinned) {
+			continue
+		}
+
+		// record the violation
+		drift := events.NewEvent()
+		drift.Kernel.Type = events.SysCtlDriftEventType
+		drift.SysCtlDriftEvent = events.SysCtlDriftEvent{
+			Name:           name,
+			PinnedValue:    pinned,
+			CurrentValue:   current,
+			TriggerEventID: triggerEventID,
+		}
+		if err = sr.krie.emitEvent(drift); err != nil {
+			logrus.Errorf("couldn't emit sysctl_drift event: %v", err)
+		}
+		if policies.IsMonitored(fmt.Sprintf("%s/%s", events.SysCtlEventType, name)) {
+			// the rule is in monitor mode, report the drift only
+			continue
+		}
+
+		// then the remediation
+		start := time.Now()
+		event := events.NewEvent()
+		event.Kernel.Type = events.ResponseEventType
+		event.ResponseEvent = events.ResponseEvent{
+			Responder:      SysCtlRevertResponder,
+			Command:        "write " + path,
+			TriggerEventID: drift.ID,
+			TriggerRuleID:  drift.Policy.RuleID,
+			Status:         ResponseStatusSuccess,
+		}
+		if err = os.WriteFile(path, []byte(strings.TrimSuffix(pinned, "\n")+"\n"), 0644); err != nil {
+			event.ResponseEvent.Status = ResponseStatusError
+			event.ResponseEvent.Error = err.Error()
+			logrus.Warnf("couldn't revert sysctl parameter %s to %q: %v", name, pinned, err)
+		} else {
+			logrus.Warnf("sysctl parameter %s was changed to %q, reverted to %q", name, current, pinned)
+		}
+		event.ResponseEvent.Duration = time.Since(start).Milliseconds()
+		if err = sr.krie.emitEvent(event); err != nil {
+			logrus.Errorf("couldn't emit response event: %v", err)
+		}
+	}
+}
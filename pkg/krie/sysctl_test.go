@@ -0,0 +1,118 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// newTestProcSys creates a /proc/sys directory with the provided parameters
+func newTestProcSys(t *testing.T, names ...string) string {
+	root := t.TempDir()
+	for _, name := range names {
+		path := filepath.Join(root, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte("0\n"), 0644))
+	}
+	return root
+}
+
+func TestResolveSysCtlParameters(t *testing.T) {
+	root := newTestProcSys(t, "kernel/yama/ptrace_scope", "kernel/modules_disabled", "kernel/kptr_restrict", "net/core/bpf_jit_harden")
+
+	for name, test := range map[string]struct {
+		list         map[string]events.SysCtlParameter
+		captureReads []string
+		expected     map[string]events.SysCtlParameter
+	}{
+		"list only": {
+			list:     map[string]events.SysCtlParameter{"kernel/modules_disabled": {PinnedValue: "1"}},
+			expected: map[string]events.SysCtlParameter{"kernel/modules_disabled": {PinnedValue: "1"}},
+		},
+		"pattern": {
+			captureReads: []string{"kernel/k*", "kernel/yama/*"},
+			expected: map[string]events.SysCtlParameter{
+				"kernel/kptr_restrict":     {BlockWriteAccess: true, CaptureReadAccess: true},
+				"kernel/yama/ptrace_scope": {BlockWriteAccess: true, CaptureReadAccess: true},
+			},
+		},
+		"pattern and list": {
+			list:         map[string]events.SysCtlParameter{"net/core/bpf_jit_harden": {PinnedValue: "2"}, "net/unknown": {}},
+			captureReads: []string{"net/*/*"},
+			expected: map[string]events.SysCtlParameter{
+				"net/core/bpf_jit_harden": {PinnedValue: "2", CaptureReadAccess: true},
+				"net/unknown":             {},
+			},
+		},
+	} {
+		opts := events.NewSysCtlOptions()
+		opts.Default = events.SysCtlParameter{BlockWriteAccess: true}
+		opts.List = test.list
+		opts.CaptureReads = test.captureReads
+
+		params, err := resolveSysCtlParameters(opts, root)
+		assert.NoError(t, err, name)
+		assert.Equal(t, test.expected, params, name)
+	}
+}
+
+func TestResolveSysCtlParametersLimit(t *testing.T) {
+	names := make([]string, 0, events.MaxSysCtlParameters+1)
+	for i := 0; i <= events.MaxSysCtlParameters; i++ {
+		names = append(names, fmt.Sprintf("fs/param_%d", i))
+	}
+	root := newTestProcSys(t, names...)
+
+	// the expansion of the patterns has to fit in the sysctl_parameters map
+	opts := events.NewSysCtlOptions()
+	opts.CaptureReads = []string{"fs/*"}
+	_, err := resolveSysCtlParameters(opts, root)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), fmt.Sprintf("match %d parameters", events.MaxSysCtlParameters+1))
+	}
+
+	opts.CaptureReads = []string{"fs/param_1??"}
+	params, err := resolveSysCtlParameters(opts, root)
+	assert.NoError(t, err)
+	assert.Len(t, params, 100)
+}
+
+func TestRemovedSysCtlParameters(t *testing.T) {
+	root := newTestProcSys(t, "kernel/yama/ptrace_scope", "kernel/kptr_restrict")
+
+	previous := events.NewSysCtlOptions()
+	previous.List["kernel/modules_disabled"] = events.SysCtlParameter{PinnedValue: "1"}
+	previous.List["kernel/kexec_load_disabled"] = events.SysCtlParameter{PinnedValue: "1"}
+	previous.CaptureReads = []string{"kernel/*"}
+
+	// the parameters dropped from the list and no longer matched by a pattern are removed
+	current := events.NewSysCtlOptions()
+	current.List["kernel/modules_disabled"] = events.SysCtlParameter{PinnedValue: "1"}
+	current.CaptureReads = []string{"kernel/yama/*"}
+	assert.Equal(t, []string{"kernel/kexec_load_disabled", "kernel/kptr_restrict"}, removedSysCtlParameters(previous, current, root))
+	assert.Empty(t, removedSysCtlParameters(current, current, root))
+
+	// patterns don't match across directories
+	assert.Equal(t, []string{"kernel/yama/ptrace_scope"}, removedSysCtlParameters(current, previous, root))
+}
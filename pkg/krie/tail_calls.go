@@ -0,0 +1,170 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	manager "github.com/DataDog/ebpf-manager"
+	"github.com/cilium/ebpf"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// TailCallsOptions configures the verification of the program arrays used by the tail call routes
+type TailCallsOptions struct {
+	// Interval is the number of seconds between two verifications, 0 disables the verification
+	Interval int64 `yaml:"interval"`
+	// Repair restores the expected program of the altered entries
+	Repair bool `yaml:"repair"`
+}
+
+// IsValid returns an error if the tail calls options are invalid
+func (o *TailCallsOptions) IsValid() error {
+	if o.Interval < 0 {
+		return fmt.Errorf("invalid interval: %d", o.Interval)
+	}
+	return nil
+}
+
+// NewTailCallsOptions returns a default set of tail calls options
+func NewTailCallsOptions() *TailCallsOptions {
+	return &TailCallsOptions{
+		Interval: 60,
+		Repair:   true,
+	}
+}
+
+// tailCallEntry is an entry of a program array, and the program it should contain
+type tailCallEntry struct {
+	route      manager.TailCallRoute
+	progArray  *ebpf.Map
+	program    *ebpf.Program
+	expectedID ebpf.ProgramID
+}
+
+// tailCallVerifier periodically checks that the program arrays still contain the programs of the tail call routes
+type tailCallVerifier struct {
+	options *TailCallsOptions
+	krie    *KRIE
+	entries []tailCallEntry
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newTailCallVerifier resolves the expected content of the program arrays, the manager must be started
+func newTailCallVerifier(krie *KRIE, options *TailCallsOptions, routes []manager.TailCallRoute) *tailCallVerifier {
+	tcv := &tailCallVerifier{
+		options: options,
+		krie:    krie,
+		stop:    make(chan struct{}),
+	}
+	for _, route := range routes {
+		progArray, found, err := krie.manager.GetMap(route.ProgArrayName)
+		if err != nil || !found {
+			logrus.Debugf("couldn't find program array %s: %v", route.ProgArrayName, err)
+			continue
+		}
+		programs, found, err := krie.manager.GetProgram(route.ProbeIdentificationPair)
+		if err != nil || !found || len(programs) == 0 {
+			logrus.Debugf("couldn't find tail call program %s: %v", route.ProbeIdentificationPair, err)
+			continue
+		}
+		info, err := programs[0].Info()
+		if err != nil {
+			logrus.Debugf("couldn't get the info of %s: %v", route.ProbeIdentificationPair, err)
+			continue
+		}
+		id, ok := info.ID()
+		if !ok {
+			// the kernel doesn't expose the program IDs
+			logrus.Debugf("couldn't get the ID of %s, the tail call routes won't be verified", route.ProbeIdentificationPair)
+			return tcv
+		}
+		tcv.entries = append(tcv.entries, tailCallEntry{
+			route:      route,
+			progArray:  progArray,
+			program:    programs[0],
+			expectedID: id,
+		})
+	}
+	return tcv
+}
+
+func (tcv *tailCallVerifier) start() {
+	tcv.wg.Add(1)
+	go func() {
+		defer tcv.wg.Done()
+		ticker := time.NewTicker(time.Duration(tcv.options.Interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-tcv.stop:
+				return
+			case <-ticker.C:
+				tcv.verify()
+			}
+		}
+	}()
+}
+
+func (tcv *tailCallVerifier) close() {
+	close(tcv.stop)
+	tcv.wg.Wait()
+}
+
+// verify checks each entry of the program arrays, and repairs the altered entries
+func (tcv *tailCallVerifier) verify() {
+	for _, entry := range tcv.entries {
+		// looking up a program array from user space returns the ID of the program
+		var foundID uint32
+		if err := entry.progArray.Lookup(entry.route.Key, &foundID); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			logrus.Debugf("couldn't lookup %s[%d]: %v", entry.route.ProgArrayName, entry.route.Key, err)
+			continue
+		}
+		if ebpf.ProgramID(foundID) == entry.expectedID {
+			continue
+		}
+
+		event := events.NewEvent()
+		event.Kernel.Type = events.TailCallTamperEventType
+		event.TailCallTamperEvent = events.TailCallTamperEvent{
+			ProgArray:         entry.route.ProgArrayName,
+			Key:               entry.route.Key,
+			Program:           entry.route.ProbeIdentificationPair.EBPFFuncName,
+			ExpectedProgramID: uint32(entry.expectedID),
+			FoundProgramID:    foundID,
+		}
+		if tcv.options.Repair {
+			if err := entry.progArray.Put(entry.route.Key, uint32(entry.program.FD())); err != nil {
+				event.TailCallTamperEvent.Error = err.Error()
+			} else {
+				event.TailCallTamperEvent.Repaired = true
+			}
+		}
+
+		logrus.Warnf("tail call route %s[%d] was altered: expected program %d, found %d (repaired: %v)", entry.route.ProgArrayName, entry.route.Key, entry.expectedID, foundID, event.TailCallTamperEvent.Repaired)
+		if err := tcv.krie.emitEvent(event); err != nil {
+			logrus.Errorf("couldn't emit tail_call_tamper event: %v", err)
+		}
+	}
+}
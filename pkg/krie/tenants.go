@@ -0,0 +1,196 @@
+//go:build !krie_minimal
+
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	tenantEventsMetricName = "krie_tenant_events_total"
+	tenantEventsMetricHelp = "Events received by the ingestion endpoint, by tenant and outcome"
+
+	// maxIngestBodySize is the maximum size of an ingestion request
+	maxIngestBodySize = 4 << 20
+)
+
+// tenant is a tenant of the ingestion endpoint
+type tenant struct {
+	name    string
+	key     string
+	options *TenantOptions
+
+	lock        sync.Mutex
+	windowStart time.Time
+	windowCount int64
+}
+
+// reserve consumes count events from the quota of the current window. It returns false, along with the time left in
+// the window, if the quota can't fit the events.
+func (t *tenant) reserve(count int64, now time.Time) (bool, time.Duration) {
+	if t.options.EventsPerMinute <= 0 {
+		return true, 0
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if now.Sub(t.windowStart) >= time.Minute {
+		t.windowStart = now
+		t.windowCount = 0
+	}
+	if t.windowCount+count > t.options.EventsPerMinute {
+		return false, t.windowStart.Add(time.Minute).Sub(now)
+	}
+	t.windowCount += count
+	return true, 0
+}
+
+// newTenants reads the keys of the tenants, sorted by name
+func newTenants(options map[string]*TenantOptions) ([]*tenant, error) {
+	var tenants []*tenant
+	for _, name := range sortedKeys(options) {
+		key, err := readAPIToken(options[name].KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", name, err)
+		}
+		for _, other := range tenants {
+			if other.key == key {
+				return nil, fmt.Errorf("tenants %s and %s share the same key", other.name, name)
+			}
+		}
+		tenants = append(tenants, &tenant{
+			name:    name,
+			key:     key,
+			options: options[name],
+		})
+	}
+	return tenants, nil
+}
+
+// lookupTenant returns the tenant of the bearer token of the request. All the keys are compared so that the time of the
+// lookup doesn't depend on the matching tenant.
+func (as *apiServer) lookupTenant(req *http.Request) *tenant {
+	var match *tenant
+	header := []byte(req.Header.Get("Authorization"))
+	for _, t := range as.tenants {
+		if subtle.ConstantTimeCompare(header, []byte("Bearer "+t.key)) == 1 {
+			match = t
+		}
+	}
+	return match
+}
+
+// ingestedEvent is the part of an ingested event used to route it
+type ingestedEvent struct {
+	ID    string `json:"id"`
+	Event struct {
+		Type string `json:"type"`
+	} `json:"event"`
+}
+
+// parseIngestBody splits an ingestion request in events, one JSON event per line
+func parseIngestBody(body []byte) ([]*queuedEvent, error) {
+	var queued []*queuedEvent
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 64*1024), maxIngestBodySize)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var evt ingestedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, fmt.Errorf("line %d: invalid event: %w", line, err)
+		}
+		eventType := events.ParseEventType(evt.Event.Type)
+		if len(evt.ID) == 0 || eventType == events.UnknownEventType {
+			return nil, fmt.Errorf("line %d: missing event id or type", line)
+		}
+		queued = append(queued, &queuedEvent{
+			id:        evt.ID,
+			eventType: eventType,
+			data:      append([]byte(nil), data...),
+		})
+	}
+	return queued, scanner.Err()
+}
+
+// handleIngest receives the events of the agents of a tenant, and queues them in the sinks of the tenant
+func (as *apiServer) handleIngest(w http.ResponseWriter, req *http.Request) {
+	t := as.lookupTenant(req)
+	if t == nil {
+		writeAPIError(w, http.StatusUnauthorized, fmt.Errorf("invalid tenant key"))
+		return
+	}
+	if req.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxIngestBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeAPIError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request larger than %d bytes", maxIngestBodySize))
+			return
+		}
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("couldn't read request: %w", err))
+		return
+	}
+	queued, err := parseIngestBody(body)
+	if err != nil {
+		as.countTenantEvents(t, "invalid", 1)
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// the quota applies to the whole request, so that the agent retries all of its events
+	if ok, retryAfter := t.reserve(int64(len(queued)), time.Now()); !ok {
+		as.countTenantEvents(t, "over_quota", len(queued))
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		writeAPIError(w, http.StatusTooManyRequests, fmt.Errorf("tenant %s is over its quota of %d events per minute", t.name, t.options.EventsPerMinute))
+		return
+	}
+	for _, evt := range queued {
+		as.krie.pipeline.pushTenant(t.name, evt)
+	}
+	as.countTenantEvents(t, "accepted", len(queued))
+	writeAPIResponse(w, http.StatusOK, map[string]interface{}{
+		"tenant":   t.name,
+		"accepted": len(queued),
+	})
+}
+
+func (as *apiServer) countTenantEvents(t *tenant, outcome string, count int) {
+	if as.krie.metrics == nil || count == 0 {
+		return
+	}
+	as.krie.metrics.add(tenantEventsMetricName, tenantEventsMetricHelp, uint64(count), "tenant", t.name, "outcome", outcome)
+}
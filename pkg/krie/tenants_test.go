@@ -0,0 +1,86 @@
+//go:build !krie_minimal
+
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memorySink struct{}
+
+func (ms *memorySink) write(_ *queuedEvent) error { return nil }
+func (ms *memorySink) close() error               { return nil }
+
+func newTestTenantServer() *apiServer {
+	p := newPipeline(NewPipelineOptions())
+	p.addSink("local", &memorySink{})
+	p.addTenantSink("blue", "blue_sink", &memorySink{}, JSONFormat)
+	p.addTenantSink("red", "red_sink", &memorySink{}, JSONFormat)
+	return &apiServer{
+		krie: &KRIE{pipeline: p},
+		tenants: []*tenant{
+			{name: "blue", key: "blue-key", options: &TenantOptions{EventsPerMinute: 2}},
+			{name: "red", key: "red-key", options: &TenantOptions{}},
+		},
+	}
+}
+
+func ingest(as *apiServer, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/ingest", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+key)
+	w := httptest.NewRecorder()
+	as.handleIngest(w, req)
+	return w
+}
+
+func TestIngestRouting(t *testing.T) {
+	as := newTestTenantServer()
+	w := ingest(as, "red-key", `{"id":"1","event":{"type":"bpf"}}`+"\n"+`{"id":"2","event":{"type":"ptrace"}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	sinks := as.krie.pipeline.sinks
+	assert.Equal(t, []string{`{"id":"1","event":{"type":"bpf"}}`, `{"id":"2","event":{"type":"ptrace"}}`}, popAll(sinks[2].queue))
+	assert.Empty(t, popAll(sinks[1].queue))
+	assert.Empty(t, popAll(sinks[0].queue))
+}
+
+func TestIngestAuthentication(t *testing.T) {
+	as := newTestTenantServer()
+	assert.Equal(t, http.StatusUnauthorized, ingest(as, "green-key", `{"id":"1","event":{"type":"bpf"}}`).Code)
+	assert.Equal(t, http.StatusBadRequest, ingest(as, "red-key", `{"id":"1","event":{"type":"nope"}}`).Code)
+}
+
+func TestIngestQuota(t *testing.T) {
+	as := newTestTenantServer()
+	assert.Equal(t, http.StatusOK, ingest(as, "blue-key", `{"id":"1","event":{"type":"bpf"}}`).Code)
+	w := ingest(as, "blue-key", `{"id":"2","event":{"type":"bpf"}}`+"\n"+`{"id":"3","event":{"type":"bpf"}}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, ingest(as, "blue-key", `{"id":"4","event":{"type":"bpf"}}`).Code)
+
+	blue := as.tenants[0]
+	ok, _ := blue.reserve(1, time.Now().Add(time.Minute))
+	assert.True(t, ok)
+}
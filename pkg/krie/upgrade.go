@@ -0,0 +1,288 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// UpgradeOptions configures the in-place upgrades: a new KRIE instance asks the instance running on the upgrade
+// socket to hand off once its own configuration is validated, the running instance detaches its probes and sends its
+// state, and the new instance then loads and attaches its own probes. The upgrades aren't gapless: the probes aren't
+// handed over (the kprobes are perf events owned by the process that created them, they can't be pinned), so the
+// kernel isn't monitored from the detach of the old probes until the new ones are attached, which includes the load
+// of the kernel BTF and the verification of the programs. The gap is recorded by the "upgrade" event of the new
+// instance.
+type UpgradeOptions struct {
+	// Socket is the unix socket of the handoff, leave empty to disable the upgrades
+	Socket string `yaml:"socket"`
+	// Timeout is the number of seconds the new instance waits for the running instance to detach its probes, the new
+	// instance exits when it expires so that two instances never enforce the policies at the same time
+	Timeout int64 `yaml:"timeout"`
+}
+
+// IsValid returns an error if the upgrade options are invalid
+func (o *UpgradeOptions) IsValid() error {
+	if len(o.Socket) == 0 {
+		return nil
+	}
+	if o.Timeout <= 0 {
+		return fmt.Errorf("invalid timeout: %d", o.Timeout)
+	}
+	return nil
+}
+
+// NewUpgradeOptions returns a default set of upgrade options
+func NewUpgradeOptions() *UpgradeOptions {
+	return &UpgradeOptions{
+		Timeout: 30,
+	}
+}
+
+// upgradeRequest is sent by the new instance to the running instance
+type upgradeRequest struct {
+	Successor events.KRIEInstance `json:"successor"`
+}
+
+// upgradeHandoff is the state sent by the running instance once its probes are detached
+type upgradeHandoff struct {
+	Predecessor events.KRIEInstance `json:"predecessor"`
+	DetachedAt  time.Time           `json:"detached_at"`
+	// Modprobe is the value of kernel.modprobe followed by the usermode helper tracker
+	Modprobe string `json:"modprobe,omitempty"`
+	// LastModule and LastModuleLoad are the last module load followed by the kernel log tracker
+	LastModule     string    `json:"last_module,omitempty"`
+	LastModuleLoad time.Time `json:"last_module_load,omitempty"`
+}
+
+// upgrader hands off the running instance to a new instance, and takes over from the running instance
+type upgrader struct {
+	options *UpgradeOptions
+	krie    *KRIE
+
+	// predecessor is the handoff of the instance this instance took over from
+	predecessor *upgradeHandoff
+
+	listener  net.Listener
+	lock      sync.Mutex
+	successor net.Conn
+	requested chan struct{}
+	wg        sync.WaitGroup
+}
+
+func newUpgrader(krie *KRIE, options *UpgradeOptions) *upgrader {
+	return &upgrader{
+		options:   options,
+		krie:      krie,
+		requested: make(chan struct{}),
+	}
+}
+
+// instance returns the identity of this instance
+func (u *upgrader) instance() events.KRIEInstance {
+	instance := events.KRIEInstance{
+		PID: uint32(Getpid()),
+	}
+	if executable, err := os.Executable(); err == nil {
+		instance.Executable = executable
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				instance.Revision = setting.Value
+			}
+		}
+	}
+	_, _, instance.PolicyHash = u.krie.currentPolicies()
+	return instance
+}
+
+// takeOver asks the running instance to hand off, and waits until its probes are detached. It returns without error
+// when no instance is running.
+func (u *upgrader) takeOver() error {
+	conn, err := net.Dial("unix", u.options.Socket)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ECONNREFUSED) {
+			return nil
+		}
+		return fmt.Errorf("couldn't connect to the running instance: %w", err)
+	}
+	defer conn.Close()
+
+	logrus.Infof("taking over from the instance running on %s", u.options.Socket)
+	_ = conn.SetDeadline(time.Now().Add(time.Duration(u.options.Timeout) * time.Second))
+	if err = json.NewEncoder(conn).Encode(upgradeRequest{Successor: u.instance()}); err != nil {
+		return fmt.Errorf("couldn't request the handoff: %w", err)
+	}
+	var handoff upgradeHandoff
+	if err = json.NewDecoder(conn).Decode(&handoff); err != nil {
+		return fmt.Errorf("the running instance didn't hand off: %w", err)
+	}
+	u.predecessor = &handoff
+
+	// import the state of the running instance
+	if len(handoff.Modprobe) > 0 {
+		u.krie.helpers.lock.Lock()
+		u.krie.helpers.modprobe = handoff.Modprobe
+		u.krie.helpers.lock.Unlock()
+	}
+	if len(handoff.LastModule) > 0 {
+		u.krie.kernelLog.lock.Lock()
+		u.krie.kernelLog.lastModule = handoff.LastModule
+		u.krie.kernelLog.lastLoad = handoff.LastModuleLoad
+		u.krie.kernelLog.lock.Unlock()
+	}
+	logrus.Infof("instance %d detached its probes, taking over", handoff.Predecessor.PID)
+	return nil
+}
+
+// start records the takeover, once the probes are attached, and listens for the next upgrade
+func (u *upgrader) start() error {
+	if u.predecessor != nil {
+		event := events.NewEvent()
+		event.Kernel.Type = events.UpgradeEventType
+		successor := u.instance()
+		event.UpgradeEvent = events.UpgradeEvent{
+			Stage:       events.UpgradeCompleted,
+			Predecessor: &u.predecessor.Predecessor,
+			Successor:   &successor,
+			Gap:         u.krie.startTime.Sub(u.predecessor.DetachedAt).Seconds(),
+		}
+		if err := u.krie.emitEvent(event); err != nil {
+			logrus.Errorf("couldn't emit upgrade event: %v", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(u.options.Socket), 0700); err != nil {
+		return fmt.Errorf("couldn't start the upgrade socket: %w", err)
+	}
+	// remove the socket of a previous run
+	_ = os.Remove(u.options.Socket)
+	listener, err := net.Listen("unix", u.options.Socket)
+	if err != nil {
+		return fmt.Errorf("couldn't start the upgrade socket: %w", err)
+	}
+	if err = os.Chmod(u.options.Socket, 0600); err != nil {
+		_ = listener.Close()
+		return fmt.Errorf("couldn't start the upgrade socket: %w", err)
+	}
+	u.listener = listener
+
+	u.wg.Add(1)
+	go u.accept()
+	return nil
+}
+
+// accept waits for the upgrade request of a new instance
+func (u *upgrader) accept() {
+	defer u.wg.Done()
+	for {
+		conn, err := u.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				logrus.Errorf("upgrade socket stopped: %v", err)
+			}
+			return
+		}
+
+		var request upgradeRequest
+		_ = conn.SetReadDeadline(time.Now().Add(time.Duration(u.options.Timeout) * time.Second))
+		if err = json.NewDecoder(conn).Decode(&request); err != nil {
+			logrus.Warnf("invalid upgrade request: %v", err)
+			_ = conn.Close()
+			continue
+		}
+		_ = conn.SetReadDeadline(time.Time{})
+
+		u.lock.Lock()
+		if u.successor != nil {
+			u.lock.Unlock()
+			logrus.Warnf("upgrade already requested, ignoring the request of instance %d", request.Successor.PID)
+			_ = conn.Close()
+			continue
+		}
+		u.successor = conn
+		u.lock.Unlock()
+
+		logrus.Infof("instance %d requested the handoff, stopping", request.Successor.PID)
+		event := events.NewEvent()
+		event.Kernel.Type = events.UpgradeEventType
+		predecessor := u.instance()
+		event.UpgradeEvent = events.UpgradeEvent{
+			Stage:       events.UpgradeHandoff,
+			Predecessor: &predecessor,
+			Successor:   &request.Successor,
+		}
+		if err = u.krie.emitEvent(event); err != nil {
+			logrus.Errorf("couldn't emit upgrade event: %v", err)
+		}
+		close(u.requested)
+	}
+}
+
+// close stops listening for upgrades, and hands off to the new instance once the probes are detached
+func (u *upgrader) close(detachedAt time.Time) {
+	if u.listener == nil {
+		return
+	}
+	// the new instance listens on the socket once it took over, release it first
+	_ = u.listener.Close()
+	u.wg.Wait()
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if u.successor == nil {
+		return
+	}
+	handoff := upgradeHandoff{
+		Predecessor: u.instance(),
+		DetachedAt:  detachedAt,
+	}
+	u.krie.helpers.lock.Lock()
+	handoff.Modprobe = u.krie.helpers.modprobe
+	u.krie.helpers.lock.Unlock()
+	u.krie.kernelLog.lock.Lock()
+	handoff.LastModule, handoff.LastModuleLoad = u.krie.kernelLog.lastModule, u.krie.kernelLog.lastLoad
+	u.krie.kernelLog.lock.Unlock()
+
+	if err := json.NewEncoder(u.successor).Encode(handoff); err != nil {
+		logrus.Errorf("couldn't hand off: %v", err)
+	}
+	_ = u.successor.Close()
+}
+
+// UpgradeRequested returns a channel closed when a new instance requests the handoff, the instance should then be
+// stopped so that the new instance can take over
+func (e *KRIE) UpgradeRequested() <-chan struct{} {
+	if e.upgrade == nil {
+		return nil
+	}
+	return e.upgrade.requested
+}
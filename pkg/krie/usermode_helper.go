@@ -0,0 +1,66 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// modprobeSysctl exposes modprobe_path
+const modprobeSysctl = "/proc/sys/kernel/modprobe"
+
+// usermodeHelperTracker follows the value of kernel.modprobe through its writes, so that the executions of a modprobe
+// path overwritten in memory stand out
+type usermodeHelperTracker struct {
+	lock     sync.Mutex
+	modprobe string
+}
+
+func newUsermodeHelperTracker() *usermodeHelperTracker {
+	ut := &usermodeHelperTracker{}
+	if data, err := os.ReadFile(modprobeSysctl); err == nil {
+		ut.modprobe = strings.TrimSpace(string(data))
+	}
+	return ut
+}
+
+// check records the writes to kernel.modprobe, and flags the executions of an unexpected modprobe path
+func (ut *usermodeHelperTracker) check(event *events.Event) {
+	helper := &event.UsermodeHelperEvent
+	if helper.Helper != events.ModprobeHelper {
+		return
+	}
+
+	ut.lock.Lock()
+	defer ut.lock.Unlock()
+
+	switch helper.Operation {
+	case events.UsermodeHelperWrite:
+		if event.Kernel.Retval == 0 {
+			ut.modprobe = helper.NewPath
+		}
+	case events.UsermodeHelperExec:
+		if len(ut.modprobe) > 0 && helper.NewPath != ut.modprobe {
+			helper.OldPath = ut.modprobe
+			helper.Hijacked = true
+		}
+	}
+}
@@ -19,21 +19,26 @@ package krie
 import (
 	"archive/tar"
 	"bytes"
+	"crypto/sha1"
 	"fmt"
 	"io"
 	"os"
+	"runtime"
+	"runtime/debug"
 	"strconv"
 	"strings"
 
 	"github.com/DataDog/gopsutil/cpu"
+	"github.com/sirupsen/logrus"
 	"github.com/smira/go-xz"
 )
 
 // NumCPU returns the count of CPUs in the CPU affinity mask of the pid 1 process
 func NumCPU() (int, error) {
 	cpuInfos, err := cpu.Info()
-	if err != nil {
-		return 0, err
+	if err != nil || len(cpuInfos) == 0 {
+		// /proc/cpuinfo isn't available (for example in a scratch container without /proc), fall back to sysfs
+		return numPossibleCPU()
 	}
 	var count int32
 	for _, inf := range cpuInfos {
@@ -42,6 +47,52 @@ func NumCPU() (int, error) {
 	return int(count), nil
 }
 
+// numPossibleCPU parses /sys/devices/system/cpu/possible ("0-3,5"), and falls back to the CPUs usable by KRIE
+func numPossibleCPU() (int, error) {
+	data, err := os.ReadFile("/sys/devices/system/cpu/possible")
+	if err != nil {
+		return runtime.NumCPU(), nil
+	}
+	var count int
+	for _, cpuRange := range strings.Split(strings.TrimSpace(string(data)), ",") {
+		bounds := strings.SplitN(cpuRange, "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return 0, fmt.Errorf("couldn't parse possible CPUs %q: %w", data, err)
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, fmt.Errorf("couldn't parse possible CPUs %q: %w", data, err)
+			}
+		}
+		count += last - first + 1
+	}
+	return count, nil
+}
+
+// cgoEnabled returns false if KRIE was built without cgo
+func cgoEnabled() bool {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return true
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "CGO_ENABLED" {
+			return setting.Value != "0"
+		}
+	}
+	return true
+}
+
+// logStaticBuild reports the features that degrade in a static build
+func logStaticBuild() {
+	if cgoEnabled() {
+		return
+	}
+	logrus.Infof("static build (cgo disabled): host names are resolved with the pure Go DNS resolver, nsswitch.conf is ignored")
+}
+
 func createBTFReaderFromTarball(archivePath string) (io.ReaderAt, error) {
 	archiveFile, err := os.Open(archivePath)
 	if err != nil {
@@ -91,3 +142,21 @@ func Getpid() int32 {
 	}
 	return int32(os.Getpid())
 }
+
+// stixNamespace is the namespace of the deterministic STIX identifiers, an indicator shared twice keeps its identifier
+var stixNamespace = [16]byte{0x00, 0xab, 0xed, 0xb4, 0xaa, 0x42, 0x46, 0x6c, 0x9c, 0x01, 0xfe, 0xd2, 0x33, 0x15, 0xa9, 0xb7}
+
+// uuidV5 returns the name based UUID of a value, in the STIX namespace
+func uuidV5(value string) string {
+	h := sha1.New()
+	h.Write(stixNamespace[:])
+	h.Write([]byte(value))
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return formatUUID(sum[:16])
+}
+
+func formatUUID(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	manager "github.com/DataDog/ebpf-manager"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gui774ume/krie/pkg/assets"
+	"github.com/Gui774ume/krie/pkg/kernel"
+)
+
+const (
+	bytecodeVariantMetricName = "krie_bytecode_variant_info"
+	bytecodeVariantMetricHelp = "Variant of the embedded eBPF programs loaded by KRIE (the value is always 1)."
+)
+
+// legacyVariants are the variants of the assets built without a manifest, their checksum can't be verified
+var legacyVariants = []assets.Variant{
+	{Name: "syscall_wrapper", Asset: "/probe_syscall_wrapper.o", SyscallWrapper: true},
+	{Name: "default", Asset: "/probe.o"},
+}
+
+// selectBytecodeVariant returns the variant built for the provided kernel version and syscall wrapper. When the
+// kernel ranges of several variants match, the variant with the narrowest lower bound wins.
+func selectBytecodeVariant(variants []assets.Variant, version kernel.Version, syscallWrapper bool) (*assets.Variant, error) {
+	var selected *assets.Variant
+	var selectedMin kernel.Version
+	for i := range variants {
+		v := &variants[i]
+		if v.SyscallWrapper != syscallWrapper {
+			continue
+		}
+		var min kernel.Version
+		if len(v.MinKernel) > 0 {
+			if min = kernel.ParseVersion(v.MinKernel); version < min {
+				continue
+			}
+		}
+		if len(v.MaxKernel) > 0 && version >= kernel.ParseVersion(v.MaxKernel) {
+			continue
+		}
+		if selected == nil || min > selectedMin {
+			selected, selectedMin = v, min
+		}
+	}
+	if selected == nil {
+		var names []string
+		for _, v := range variants {
+			names = append(names, fmt.Sprintf("%s [%s, %s)", v.Name, v.MinKernel, v.MaxKernel))
+		}
+		return nil, fmt.Errorf("no eBPF programs variant for kernel %s (syscall wrapper: %v), available variants: %s", version, syscallWrapper, strings.Join(names, ", "))
+	}
+	return selected, nil
+}
+
+// fetchAssets selects the variant of the eBPF programs built for the running kernel, and verifies its checksum
+func (e *KRIE) fetchAssets() (*bytes.Reader, error) {
+	openSyscall, err := manager.GetSyscallFnName("open")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't determine which asset to use: %w", err)
+	}
+	syscallWrapper := !strings.HasPrefix(openSyscall, "SyS_") && !strings.HasPrefix(openSyscall, "sys_")
+
+	version, err := kernel.HostVersion()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't determine which asset to use: %w", err)
+	}
+
+	verified := true
+	variants, err := assets.Variants()
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		// the assets were generated without a manifest (development build)
+		variants = legacyVariants
+		verified = false
+	}
+
+	variant, err := selectBytecodeVariant(variants, version, syscallWrapper)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := assets.Asset(variant.Asset)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find load asset %s: %w", variant.Asset, err)
+	}
+	if verified {
+		if err = variant.Verify(buf); err != nil {
+			return nil, err
+		}
+		logrus.Infof("eBPF programs variant %s selected for kernel %s (sha256 %s)", variant.Name, version, variant.SHA256)
+	} else {
+		logrus.Warnf("eBPF programs variant %s selected for kernel %s, the assets don't have a manifest: the checksum of the variant can't be verified", variant.Name, version)
+	}
+
+	e.variant = variant
+	e.metrics.add(bytecodeVariantMetricName, bytecodeVariantMetricHelp, 1, "variant", variant.Name, "sha256", variant.SHA256, "kernel", version.String())
+	return bytes.NewReader(buf), nil
+}
+
+// BytecodeVariant returns the variant of the embedded eBPF programs loaded by KRIE, nil until KRIE is started
+func (e *KRIE) BytecodeVariant() *assets.Variant {
+	return e.variant
+}
@@ -0,0 +1,49 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Gui774ume/krie/pkg/assets"
+	"github.com/Gui774ume/krie/pkg/kernel"
+)
+
+func TestSelectBytecodeVariant(t *testing.T) {
+	variants := []assets.Variant{
+		{Name: "legacy", MaxKernel: "4.17.0"},
+		{Name: "wrapper", SyscallWrapper: true},
+		{Name: "wrapper_5.8", MinKernel: "5.8.0", SyscallWrapper: true},
+	}
+
+	variant, err := selectBytecodeVariant(variants, kernel.VersionCode(4, 14, 0), false)
+	assert.NoError(t, err)
+	assert.Equal(t, "legacy", variant.Name)
+
+	variant, err = selectBytecodeVariant(variants, kernel.VersionCode(5, 4, 0), true)
+	assert.NoError(t, err)
+	assert.Equal(t, "wrapper", variant.Name)
+
+	variant, err = selectBytecodeVariant(variants, kernel.VersionCode(6, 1, 0), true)
+	assert.NoError(t, err)
+	assert.Equal(t, "wrapper_5.8", variant.Name)
+
+	_, err = selectBytecodeVariant(variants, kernel.VersionCode(5, 4, 0), false)
+	assert.Error(t, err)
+}
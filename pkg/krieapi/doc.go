@@ -0,0 +1,30 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package krieapi is the stable API of KRIE, for the projects that embed KRIE or consume its events.
+//
+// Every type of this package is defined by this package: the internal types of pkg/krie are converted at the boundary
+// and never appear in the exported signatures, so that the internal packages can be refactored without breaking the
+// embedding projects. The types follow semantic versioning (see Version): within a major version, exported identifiers
+// are never removed or renamed, and the JSON fields of the events are never removed, renamed or retyped (new fields
+// and new event types may be added). The options of KRIE are configured through the configuration file, its YAML
+// representation follows the same guarantees.
+//
+// Embedding projects should only import this package: the packages under pkg/krie can change at any time.
+package krieapi
+
+// Version is the semantic version of the API
+const Version = "1.0.0"
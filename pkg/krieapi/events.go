@@ -0,0 +1,170 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krieapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Gui774ume/krie/pkg/krie"
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// EventType is the type of an event, it is the "event.type" field of the JSON events
+type EventType string
+
+// Event types
+const (
+	UnknownEventType                 EventType = "unknown"
+	InitModuleEventType              EventType = "init_module"
+	DeleteModuleEventType            EventType = "delete_module"
+	BPFEventType                     EventType = "bpf"
+	BPFFilterEventType               EventType = "bpf_filter"
+	PTraceEventType                  EventType = "ptrace"
+	KProbeEventType                  EventType = "kprobe"
+	SysCtlEventType                  EventType = "sysctl"
+	HookedSyscallTableEventType      EventType = "hooked_syscall_table"
+	HookedSyscallEventType           EventType = "hooked_syscall"
+	EventCheckEventType              EventType = "event_check"
+	KernelParameterEventType         EventType = "kernel_parameter"
+	PeriodicKernelParameterEventType EventType = "periodic_kernel_parameter"
+	RegisterCheckEventType           EventType = "register_check"
+	ResponseEventType                EventType = "response"
+	PolicyChangeEventType            EventType = "policy_change"
+	RawEventType                     EventType = "raw"
+	ClockSkewEventType               EventType = "clock_skew"
+	SilenceEventType                 EventType = "silence"
+	TailCallTamperEventType          EventType = "tail_call_tamper"
+	IntegritySwitchEventType         EventType = "integrity_switch"
+	SysCtlDriftEventType             EventType = "sysctl_drift"
+	KexecEventType                   EventType = "kexec"
+	CoverageChangeEventType          EventType = "coverage_change"
+	ControlRegisterEventType         EventType = "control_register"
+	ProcessExitEventType             EventType = "process_exit"
+	FTraceHookEventType              EventType = "ftrace_hook"
+	CredChangeEventType              EventType = "cred_change"
+	LSMStateChangeEventType          EventType = "lsm_state_change"
+	EnrichmentEventType              EventType = "enrichment"
+	IOUringEventType                 EventType = "io_uring"
+	BPFProgramUnloadEventType        EventType = "bpf_program_unload"
+	PerfEventOpenEventType           EventType = "perf_event_open"
+	RawMemoryAccessEventType         EventType = "raw_memory_access"
+	ModuleTaintChangeEventType       EventType = "module_taint_change"
+	MSRWriteEventType                EventType = "msr_write"
+	SymbolResolutionEventType        EventType = "symbol_resolution"
+	UsermodeHelperEventType          EventType = "usermode_helper"
+	ProcessVMEventType               EventType = "process_vm"
+	MountEventType                   EventType = "mount"
+	PrctlEventType                   EventType = "prctl"
+	ProfileSnapshotEventType         EventType = "profile_snapshot"
+	CapsetEventType                  EventType = "capset"
+	BPFMapTamperEventType            EventType = "bpf_map_tamper"
+	TracefsProbeEventType            EventType = "tracefs_probe"
+	NetfilterHookEventType           EventType = "netfilter_hook"
+	KernelLogTamperEventType         EventType = "kernel_log_tamper"
+	UpgradeEventType                 EventType = "upgrade"
+	KeyringEventType                 EventType = "keyring"
+	NamespaceEventType               EventType = "namespace"
+	SyscallOrphansEventType          EventType = "syscall_orphans"
+	UserfaultfdEventType             EventType = "userfaultfd"
+	BinfmtMiscEventType              EventType = "binfmt_misc"
+	ModuleSignatureEventType         EventType = "module_signature"
+	RebootEventType                  EventType = "reboot"
+	HiddenProcessEventType           EventType = "hidden_process"
+	AnnotationEventType              EventType = "annotation"
+)
+
+// ParseEventType returns an event type from its string representation, UnknownEventType if the event type doesn't
+// exist
+func ParseEventType(input string) EventType {
+	return newEventType(events.ParseEventType(input))
+}
+
+// newEventType converts an internal event type
+func newEventType(eventType events.EventType) EventType {
+	if eventType == events.UnknownEventType {
+		return UnknownEventType
+	}
+	return EventType(eventType.String())
+}
+
+// internal returns the internal event type of the event type
+func (t EventType) internal() events.EventType {
+	return events.ParseEventType(string(t))
+}
+
+// Event is an event of KRIE. The fields used to select the events are parsed from its JSON representation, Data holds
+// the complete JSON representation of the event.
+type Event struct {
+	ID   string
+	Type EventType
+	// Time is the kernel timestamp of the event
+	Time time.Time
+	// RuleID is the rule of the event, the events without a rule have the rule of their type
+	RuleID string
+	Comm   string
+	// Data is the JSON representation of the event, terminated by a new line
+	Data []byte
+}
+
+// Decode unmarshals the JSON representation of the event into the provided value
+func (e *Event) Decode(out interface{}) error {
+	return json.Unmarshal(e.Data, out)
+}
+
+// UnmarshalEvent parses a JSON event, as written by the sinks of KRIE
+func UnmarshalEvent(data []byte) (*Event, error) {
+	stored, err := krie.ParseStoredEvent(data)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse event: %w", err)
+	}
+	return newStoredEvent(stored), nil
+}
+
+// newEvent converts an internal event to its JSON representation
+func newEvent(event *events.Event) (*Event, error) {
+	data, err := event.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't marshal %s event: %w", event.Kernel.Type, err)
+	}
+	return UnmarshalEvent(data)
+}
+
+// newStoredEvent converts an internal stored event
+func newStoredEvent(stored *krie.StoredEvent) *Event {
+	return &Event{
+		ID:     stored.ID,
+		Type:   newEventType(stored.Type),
+		Time:   stored.Time,
+		RuleID: stored.RuleID,
+		Comm:   stored.Comm,
+		Data:   stored.Data,
+	}
+}
+
+// internal returns the internal stored event of the event
+func (e *Event) internal() *krie.StoredEvent {
+	return &krie.StoredEvent{
+		ID:     e.ID,
+		Type:   e.Type.internal(),
+		Time:   e.Time,
+		RuleID: e.RuleID,
+		Comm:   e.Comm,
+		Data:   e.Data,
+	}
+}
@@ -0,0 +1,397 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krieapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Gui774ume/krie/pkg/krie"
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// Options contains the parameters of KRIE. They are loaded from a configuration file, its YAML representation is part
+// of the API. The handlers and the middlewares of an embedding project are added with the methods of Options.
+type Options struct {
+	options *krie.Options
+}
+
+// NewOptions returns a default set of options
+func NewOptions() *Options {
+	return &Options{options: krie.NewOptions()}
+}
+
+// LoadOptions loads the options of a configuration file, on top of the default set of options
+func LoadOptions(path string) (*Options, error) {
+	options, err := krie.LoadOptions(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Options{options: options}, nil
+}
+
+// IsValid returns an error if the options are invalid
+func (o *Options) IsValid() error {
+	return o.options.IsValid()
+}
+
+// EventHandler is called with the raw kernel payload of each event, from one goroutine per active shard
+type EventHandler func(data []byte) error
+
+// SetEventHandler replaces the processing of the events by the provided handler: the events aren't decoded, and the
+// event type handlers and the middlewares are ignored
+func (o *Options) SetEventHandler(handler EventHandler) {
+	o.options.EventHandler = handler
+}
+
+// EventTypeHandler handles the decoded and enriched events of an event type, from one goroutine per active shard
+type EventTypeHandler func(event *Event) error
+
+// SetEventTypeHandler replaces the default processing of the events of the provided event type: the events are
+// decoded and enriched by KRIE, then passed to the handler instead of the sinks
+func (o *Options) SetEventTypeHandler(eventType EventType, handler EventTypeHandler) error {
+	internal := eventType.internal()
+	if internal == events.UnknownEventType {
+		return fmt.Errorf("unknown event type: %s", eventType)
+	}
+	if o.options.EventTypeHandlers == nil {
+		o.options.EventTypeHandlers = make(map[events.EventType]krie.EventTypeHandler)
+	}
+	o.options.EventTypeHandlers[internal] = func(event *events.Event) error {
+		converted, err := newEvent(event)
+		if err != nil {
+			return err
+		}
+		return handler(converted)
+	}
+	return nil
+}
+
+// EventStage is a stage of the processing chain of the events, see Options.AddMiddleware
+type EventStage string
+
+const (
+	// DecodeStage unmarshalls the kernel payload of the event
+	DecodeStage EventStage = "decode"
+	// EnrichStage resolves the container, the process ancestors and the kernel symbols of the event
+	EnrichStage EventStage = "enrich"
+	// RulesStage adds the rule, the mode and the confidence of the policy that produced the event
+	RulesStage EventStage = "rules"
+	// RedactStage applies the redaction rules
+	RedactStage EventStage = "redact"
+	// SinksStage queues the event for the sinks, triggers the responders and records the event in the burst captures
+	SinksStage EventStage = "sinks"
+)
+
+// EventMiddleware is a step of the processing chain: it is called with the event, as it is after the stage of the
+// middleware, and continues the processing by calling next. It drops the event by returning without calling next.
+// The event passed to the middleware is a copy, its changes aren't applied to the event processed by KRIE.
+type EventMiddleware func(event *Event, next func() error) error
+
+// Middleware is inserted in the processing chain of the events right after a stage
+type Middleware struct {
+	Name    string
+	After   EventStage
+	Handler EventMiddleware
+}
+
+// AddMiddleware inserts a middleware in the processing chain of the events, the middlewares of a stage run in the
+// order they were added
+func (o *Options) AddMiddleware(middleware Middleware) error {
+	internal := krie.Middleware{
+		Name:  middleware.Name,
+		After: krie.EventStage(middleware.After),
+	}
+	if middleware.Handler != nil {
+		internal.Handler = func(event *events.Event, next krie.EventProcessor) error {
+			converted, err := newEvent(event)
+			if err != nil {
+				return err
+			}
+			return middleware.Handler(converted, func() error {
+				return next(event)
+			})
+		}
+	}
+	if err := internal.IsValid(); err != nil {
+		return fmt.Errorf("invalid middleware: %w", err)
+	}
+	o.options.Middlewares = append(o.options.Middlewares, internal)
+	return nil
+}
+
+// KRIE is an instance of KRIE
+type KRIE struct {
+	krie *krie.KRIE
+}
+
+// New creates a new KRIE instance, call Start to load the probes and Stop to unload them
+func New(options *Options) (*KRIE, error) {
+	instance, err := krie.NewKRIE(options.options)
+	if err != nil {
+		return nil, err
+	}
+	return &KRIE{krie: instance}, nil
+}
+
+// Start loads the probes and begins tracing
+func (k *KRIE) Start() error {
+	return k.krie.Start()
+}
+
+// Stop unloads the probes and flushes the sinks
+func (k *KRIE) Stop() error {
+	return k.krie.Stop()
+}
+
+// RuleMode is the enforcement mode of a rule, see KRIE.SetRuleMode
+type RuleMode string
+
+const (
+	// EnforceMode applies the configured action of the rule
+	EnforceMode RuleMode = "enforce"
+	// MonitorMode only logs the events of the rule, whatever its configured action
+	MonitorMode RuleMode = "monitor"
+)
+
+// SetRuleMode switches a rule (and its sub rules) between the monitor and enforce modes, without reloading the
+// configuration. The change goes through the same approval and audit as any other policy change.
+func (k *KRIE) SetRuleMode(ruleID string, mode RuleMode, tokens []string) error {
+	return k.krie.SetRuleMode(ruleID, events.RuleMode(mode), tokens)
+}
+
+// RuleModes returns the mode of each rule configured to block, kill or alter the monitored operations
+func (k *KRIE) RuleModes() map[string]RuleMode {
+	modes := k.krie.RuleModes()
+	out := make(map[string]RuleMode, len(modes))
+	for rule, mode := range modes {
+		out[rule] = RuleMode(mode)
+	}
+	return out
+}
+
+// Finding is a result of an integrity scan
+type Finding struct {
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// ScanResult is the result of a scanner
+type ScanResult struct {
+	Scanner  string    `json:"scanner"`
+	Start    time.Time `json:"start"`
+	Duration int64     `json:"duration_ms"`
+	Clean    bool      `json:"clean"`
+	Findings []Finding `json:"findings"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Scanner is an integrity scanner run on demand, see KRIE.RegisterScanner and KRIE.Scan
+type Scanner interface {
+	// Name returns the unique name of the scanner
+	Name() string
+	// Scan runs the scanner and returns its findings, a clean host has no finding
+	Scan(ctx context.Context) ([]Finding, error)
+}
+
+// scannerAdapter adapts a Scanner to KRIE
+type scannerAdapter struct {
+	scanner Scanner
+}
+
+func (sa *scannerAdapter) Name() string {
+	return sa.scanner.Name()
+}
+
+func (sa *scannerAdapter) Scan(ctx context.Context) ([]krie.Finding, error) {
+	findings, err := sa.scanner.Scan(ctx)
+	out := make([]krie.Finding, 0, len(findings))
+	for _, finding := range findings {
+		out = append(out, krie.Finding{
+			Message: finding.Message,
+			Details: finding.Details,
+		})
+	}
+	return out, err
+}
+
+// RegisterScanner registers a scanner, it can then be run on demand with Scan
+func (k *KRIE) RegisterScanner(scanner Scanner) error {
+	return k.krie.RegisterScanner(&scannerAdapter{scanner: scanner})
+}
+
+// Scanners returns the sorted names of the registered scanners
+func (k *KRIE) Scanners() []string {
+	return k.krie.Scanners()
+}
+
+// Scan runs the provided scanners (or all the registered scanners if none is provided), and returns their results
+func (k *KRIE) Scan(ctx context.Context, names ...string) ([]ScanResult, error) {
+	results, err := k.krie.Scan(ctx, names...)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ScanResult, 0, len(results))
+	for _, result := range results {
+		converted := ScanResult{
+			Scanner:  result.Scanner,
+			Start:    result.Start,
+			Duration: result.Duration,
+			Clean:    result.Clean,
+			Error:    result.Error,
+		}
+		for _, finding := range result.Findings {
+			converted.Findings = append(converted.Findings, Finding{
+				Message: finding.Message,
+				Details: finding.Details,
+			})
+		}
+		out = append(out, converted)
+	}
+	return out, nil
+}
+
+// ProcessRecord is an entry of the process history, see KRIE.LookupProcess
+type ProcessRecord struct {
+	PID       uint32     `json:"pid"`
+	PPID      uint32     `json:"ppid"`
+	Comm      string     `json:"comm"`
+	Exe       string     `json:"exe,omitempty"`
+	Args      []string   `json:"args,omitempty"`
+	StartTime time.Time  `json:"start_time"`
+	ExitTime  *time.Time `json:"exit_time,omitempty"`
+}
+
+// newProcessRecord converts an internal process record
+func newProcessRecord(record *krie.ProcessRecord) ProcessRecord {
+	return ProcessRecord{
+		PID:       record.PID,
+		PPID:      record.PPID,
+		Comm:      record.Comm,
+		Exe:       record.Exe,
+		Args:      record.Args,
+		StartTime: record.StartTime,
+		ExitTime:  record.ExitTime,
+	}
+}
+
+// LookupProcess returns the process with the provided PID at the provided time (now if the time is zero), along with
+// its ancestors. The process history must be enabled.
+func (k *KRIE) LookupProcess(pid uint32, at time.Time) (*ProcessRecord, []ProcessRecord, error) {
+	process, ancestors, err := k.krie.LookupProcess(pid, at)
+	if err != nil || process == nil {
+		return nil, nil, err
+	}
+	record := newProcessRecord(process)
+	out := make([]ProcessRecord, 0, len(ancestors))
+	for i := range ancestors {
+		out = append(out, newProcessRecord(&ancestors[i]))
+	}
+	return &record, out, nil
+}
+
+// CaptureRequest is a time-window capture of a set of event types, see Capture and KRIE.StartCapture
+type CaptureRequest struct {
+	// Duration is the duration of the capture
+	Duration time.Duration
+	// EventTypes are the captured event types
+	EventTypes []EventType
+	// PID restricts the capture to the events of a process (thread group), 0 captures every process
+	PID uint32
+	// Output is the file of the captured events, one JSON event per line
+	Output string
+}
+
+// internal returns the internal capture request of the request
+func (r *CaptureRequest) internal() (*krie.CaptureRequest, error) {
+	out := &krie.CaptureRequest{
+		Duration: r.Duration,
+		PID:      r.PID,
+		Output:   r.Output,
+	}
+	for _, eventType := range r.EventTypes {
+		internal := eventType.internal()
+		if internal == events.UnknownEventType {
+			return nil, fmt.Errorf("unknown event type: %s", eventType)
+		}
+		out.EventTypes = append(out.EventTypes, internal)
+	}
+	return out, nil
+}
+
+// CaptureResult is the summary of a capture
+type CaptureResult struct {
+	Output     string      `json:"output"`
+	EventTypes []EventType `json:"event_types"`
+	PID        uint32      `json:"pid,omitempty"`
+	Start      time.Time   `json:"start"`
+	End        time.Time   `json:"end"`
+	Events     uint64      `json:"events"`
+}
+
+// newCaptureResult converts an internal capture result
+func newCaptureResult(result *krie.CaptureResult) *CaptureResult {
+	out := &CaptureResult{
+		Output: result.Output,
+		PID:    result.PID,
+		Start:  result.Start,
+		End:    result.End,
+		Events: result.Events,
+	}
+	for _, eventType := range result.EventTypes {
+		out.EventTypes = append(out.EventTypes, EventType(eventType))
+	}
+	return out
+}
+
+// Capture runs a one-shot capture: only the probes of the requested event types are attached for the duration of the
+// capture, and their events are written to the output of the request
+func Capture(ctx context.Context, options *Options, request *CaptureRequest) (*CaptureResult, error) {
+	internal, err := request.internal()
+	if err != nil {
+		return nil, err
+	}
+	result, err := krie.Capture(ctx, options.options, internal)
+	if err != nil {
+		return nil, err
+	}
+	return newCaptureResult(result), nil
+}
+
+// StartCapture starts a time-window capture on the running instance
+func (k *KRIE) StartCapture(request *CaptureRequest) (*CaptureResult, error) {
+	internal, err := request.internal()
+	if err != nil {
+		return nil, err
+	}
+	result, err := k.krie.StartCapture(internal)
+	if err != nil {
+		return nil, err
+	}
+	return newCaptureResult(result), nil
+}
+
+// Captures returns the summary of the active captures
+func (k *KRIE) Captures() []*CaptureResult {
+	captures := k.krie.Captures()
+	out := make([]*CaptureResult, 0, len(captures))
+	for _, capture := range captures {
+		out = append(out, newCaptureResult(capture))
+	}
+	return out
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krieapi
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// internalPackages are the import names of the internal packages, which must not appear in the exported API
+var internalPackages = map[string]bool{
+	"krie":   true,
+	"events": true,
+}
+
+// internalReferences returns the references to the internal packages of a node
+func internalReferences(node ast.Node) []string {
+	var refs []string
+	ast.Inspect(node, func(n ast.Node) bool {
+		if selector, ok := n.(*ast.SelectorExpr); ok {
+			if pkg, ok := selector.X.(*ast.Ident); ok && internalPackages[pkg.Name] {
+				refs = append(refs, pkg.Name+"."+selector.Sel.Name)
+			}
+		}
+		return true
+	})
+	return refs
+}
+
+func TestNoInternalTypesInExportedAPI(t *testing.T) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", nil, 0)
+	assert.NoError(t, err)
+
+	for _, file := range pkgs["krieapi"].Files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				// the exported functions and the exported methods of the exported types
+				if !decl.Name.IsExported() {
+					continue
+				}
+				if decl.Recv != nil {
+					if receiver, ok := decl.Recv.List[0].Type.(*ast.StarExpr); ok && !receiver.X.(*ast.Ident).IsExported() {
+						continue
+					}
+					if receiver, ok := decl.Recv.List[0].Type.(*ast.Ident); ok && !receiver.IsExported() {
+						continue
+					}
+				}
+				assert.Empty(t, internalReferences(decl.Type), "%s leaks internal types", decl.Name.Name)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						if !spec.Name.IsExported() {
+							continue
+						}
+						assert.False(t, spec.Assign.IsValid(), "%s is an alias", spec.Name.Name)
+						if structType, ok := spec.Type.(*ast.StructType); ok {
+							// the unexported fields hold the internal values
+							for _, field := range structType.Fields.List {
+								if len(field.Names) > 0 && !field.Names[0].IsExported() {
+									continue
+								}
+								assert.Empty(t, internalReferences(field.Type), "%s leaks internal types", spec.Name.Name)
+							}
+							continue
+						}
+						assert.Empty(t, internalReferences(spec.Type), "%s leaks internal types", spec.Name.Name)
+					case *ast.ValueSpec:
+						if spec.Names[0].IsExported() {
+							assert.Empty(t, internalReferences(spec), "%s leaks internal values", spec.Names[0].Name)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestEventTypes(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "events.go", nil, 0)
+	assert.NoError(t, err)
+	constants := make(map[string]bool)
+	for _, decl := range file.Decls {
+		if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.CONST {
+			for _, spec := range decl.Specs {
+				if value, ok := spec.(*ast.ValueSpec).Values[0].(*ast.BasicLit); ok {
+					constants[strings.Trim(value.Value, `"`)] = true
+				}
+			}
+		}
+	}
+
+	// every internal event type has a constant of the API
+	for eventType := events.EventType(1); eventType < events.MaxEventType; eventType++ {
+		converted := newEventType(eventType)
+		assert.True(t, constants[string(converted)], "missing constant for %s", eventType)
+		assert.Equal(t, eventType, converted.internal())
+		assert.Equal(t, converted, ParseEventType(eventType.String()))
+	}
+	assert.Equal(t, UnknownEventType, ParseEventType("not_an_event_type"))
+}
+
+func TestUnmarshalEvent(t *testing.T) {
+	event, err := UnmarshalEvent([]byte(`{"id":"42","event":{"type":"init_module","time":"2022-06-01T14:02:00Z"},"process":{"comm":"insmod"},"policy":{"rule_id":"init_module/fileless"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "42", event.ID)
+	assert.Equal(t, InitModuleEventType, event.Type)
+	assert.Equal(t, "insmod", event.Comm)
+	assert.Equal(t, "init_module/fileless", event.RuleID)
+
+	var decoded struct {
+		Process struct {
+			Comm string `json:"comm"`
+		} `json:"process"`
+	}
+	assert.NoError(t, event.Decode(&decoded))
+	assert.Equal(t, "insmod", decoded.Process.Comm)
+}
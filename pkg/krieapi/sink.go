@@ -0,0 +1,323 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package krieapi
+
+import (
+	"time"
+
+	"github.com/Gui774ume/krie/pkg/krie"
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+// SinkEvent is a serialized event sent to a Sink
+type SinkEvent struct {
+	ID   string
+	Type EventType
+	// Data is the representation of the event in the format of the sink (JSON by default), terminated by a new line
+	Data []byte
+}
+
+// Sink is a third-party output of KRIE. The events are queued, batched and retried by the pipeline of KRIE before
+// they reach Send. The methods of a Sink are never called concurrently.
+type Sink interface {
+	// Start is called once, before the first call to Send
+	Start() error
+	// Send writes a batch of events, a batch is retried when Send returns an error
+	Send(batch []SinkEvent) error
+	// Flush is called when the pipeline is flushed, before Stop
+	Flush() error
+	// Stop is called once, when KRIE stops
+	Stop() error
+}
+
+// SinkFactory creates a Sink from the "config" section of its configuration. decode unmarshals the "config" section
+// into the provided value, and is a no-op if the section is empty.
+type SinkFactory func(name string, decode func(out interface{}) error) (Sink, error)
+
+// RegisterSink registers a sink type, the sinks of this type are configured in the "sinks" section of the
+// configuration file. RegisterSink panics if the sink type is already registered.
+func RegisterSink(sinkType string, factory SinkFactory) {
+	if factory == nil {
+		krie.RegisterSink(sinkType, nil)
+		return
+	}
+	krie.RegisterSink(sinkType, func(name string, decode func(out interface{}) error) (krie.Sink, error) {
+		sink, err := factory(name, decode)
+		if err != nil {
+			return nil, err
+		}
+		return &sinkAdapter{sink: sink}, nil
+	})
+}
+
+// sinkAdapter adapts a Sink to the pipeline of KRIE
+type sinkAdapter struct {
+	sink Sink
+}
+
+func (sa *sinkAdapter) Start() error {
+	return sa.sink.Start()
+}
+
+func (sa *sinkAdapter) Send(batch []krie.SinkEvent) error {
+	converted := make([]SinkEvent, 0, len(batch))
+	for _, event := range batch {
+		converted = append(converted, SinkEvent{
+			ID:   event.ID,
+			Type: newEventType(event.Type),
+			Data: event.Data,
+		})
+	}
+	return sa.sink.Send(converted)
+}
+
+func (sa *sinkAdapter) Flush() error {
+	return sa.sink.Flush()
+}
+
+func (sa *sinkAdapter) Stop() error {
+	return sa.sink.Stop()
+}
+
+// StorageQuery selects the events of a Storage, the zero value matches every event
+type StorageQuery struct {
+	// Since and Until bound the kernel timestamps of the events, a zero time is unbounded
+	Since time.Time
+	Until time.Time
+	// Types are the event types to return, empty for all the event types
+	Types []EventType
+	// RuleID matches the events of a rule and of its sub rules (for example "sysctl" matches
+	// "sysctl/kernel/yama/ptrace_scope"), the events without a rule are matched by their type
+	RuleID string
+	// Limit is the maximum number of events to return, 0 for no limit
+	Limit int
+}
+
+// Matches returns true if the provided event is selected by the query. Storage backends which can't filter natively
+// call it on each event.
+func (q *StorageQuery) Matches(event *Event) bool {
+	query := q.internal()
+	return query.Matches(event.internal())
+}
+
+// newStorageQuery converts an internal storage query
+func newStorageQuery(query krie.StorageQuery) StorageQuery {
+	out := StorageQuery{
+		Since:  query.Since,
+		Until:  query.Until,
+		RuleID: query.RuleID,
+		Limit:  query.Limit,
+	}
+	for _, eventType := range query.Types {
+		out.Types = append(out.Types, newEventType(eventType))
+	}
+	return out
+}
+
+// internal returns the internal storage query of the query
+func (q *StorageQuery) internal() krie.StorageQuery {
+	out := krie.StorageQuery{
+		Since:  q.Since,
+		Until:  q.Until,
+		RuleID: q.RuleID,
+		Limit:  q.Limit,
+	}
+	for _, eventType := range q.Types {
+		out.Types = append(out.Types, eventType.internal())
+	}
+	return out
+}
+
+// Storage is the local store of the events, queried by the query command and by the report generators. A Storage is a
+// Sink: the events are queued, batched and retried by the pipeline before they reach Send, in the JSON format. Query
+// may be called without Start, to read the store of a stopped KRIE, and concurrently with Send. UnmarshalEvent parses
+// the fields used to select the events, for the backends which index them.
+type Storage interface {
+	Sink
+	// Query calls fn with the events selected by the query, in the order they were stored, until fn returns false
+	Query(query StorageQuery, fn func(event *Event) bool) error
+}
+
+// StorageFactory creates a Storage from the "config" section of its configuration. decode unmarshals the "config"
+// section into the provided value, and is a no-op if the section is empty.
+type StorageFactory func(decode func(out interface{}) error) (Storage, error)
+
+// RegisterStorage registers a storage type, selected in the "storage" section of the configuration file.
+// RegisterStorage panics if the storage type is already registered.
+func RegisterStorage(storageType string, factory StorageFactory) {
+	if factory == nil {
+		krie.RegisterStorage(storageType, nil)
+		return
+	}
+	krie.RegisterStorage(storageType, func(decode func(out interface{}) error) (krie.Storage, error) {
+		storage, err := factory(decode)
+		if err != nil {
+			return nil, err
+		}
+		adapter := &storageAdapter{sinkAdapter: sinkAdapter{sink: storage}, storage: storage}
+		if compactor, ok := storage.(StorageCompactor); ok {
+			return &compactingStorageAdapter{storageAdapter: adapter, compactor: compactor}, nil
+		}
+		return adapter, nil
+	})
+}
+
+// storageAdapter adapts a Storage to KRIE
+type storageAdapter struct {
+	sinkAdapter
+	storage Storage
+}
+
+func (sa *storageAdapter) Query(query krie.StorageQuery, fn func(event *krie.StoredEvent) bool) error {
+	return sa.storage.Query(newStorageQuery(query), func(event *Event) bool {
+		return fn(event.internal())
+	})
+}
+
+// compactingStorageAdapter adapts a Storage which supports the retention policies to KRIE
+type compactingStorageAdapter struct {
+	*storageAdapter
+	compactor StorageCompactor
+}
+
+func (ca *compactingStorageAdapter) Compact(policy *krie.RetentionPolicy, now time.Time) (map[events.EventType]int, error) {
+	removed, err := ca.compactor.Compact(newRetentionPolicy(policy), now)
+	out := make(map[events.EventType]int, len(removed))
+	for eventType, count := range removed {
+		out[eventType.internal()] += count
+	}
+	return out, err
+}
+
+// OpenStorage opens the local store of the provided configuration, to query the events
+func OpenStorage(options *Options) (Storage, error) {
+	storage, err := krie.OpenStorage(options.options)
+	if err != nil {
+		return nil, err
+	}
+	opened := &openedStorage{storage: storage}
+	if compactor, ok := storage.(krie.StorageCompactor); ok {
+		return &openedCompactingStorage{openedStorage: opened, compactor: compactor}, nil
+	}
+	return opened, nil
+}
+
+// openedStorage exposes a storage of KRIE
+type openedStorage struct {
+	storage krie.Storage
+}
+
+func (s *openedStorage) Start() error {
+	return s.storage.Start()
+}
+
+func (s *openedStorage) Send(batch []SinkEvent) error {
+	converted := make([]krie.SinkEvent, 0, len(batch))
+	for _, event := range batch {
+		converted = append(converted, krie.SinkEvent{
+			ID:   event.ID,
+			Type: event.Type.internal(),
+			Data: event.Data,
+		})
+	}
+	return s.storage.Send(converted)
+}
+
+func (s *openedStorage) Flush() error {
+	return s.storage.Flush()
+}
+
+func (s *openedStorage) Stop() error {
+	return s.storage.Stop()
+}
+
+func (s *openedStorage) Query(query StorageQuery, fn func(event *Event) bool) error {
+	return s.storage.Query(query.internal(), func(event *krie.StoredEvent) bool {
+		return fn(newStoredEvent(event))
+	})
+}
+
+// openedCompactingStorage exposes a storage of KRIE which supports the retention policies
+type openedCompactingStorage struct {
+	*openedStorage
+	compactor krie.StorageCompactor
+}
+
+func (oc *openedCompactingStorage) Compact(policy *RetentionPolicy, now time.Time) (map[EventType]int, error) {
+	removed, err := oc.compactor.Compact(policy.internal(), now)
+	out := make(map[EventType]int, len(removed))
+	for eventType, count := range removed {
+		out[newEventType(eventType)] += count
+	}
+	return out, err
+}
+
+// RetentionPolicy is the maximum age of the events of each event type in a Storage, 0 keeps the events forever
+type RetentionPolicy struct {
+	Default    time.Duration
+	EventTypes map[EventType]time.Duration
+}
+
+// MaxAge returns the maximum age of the events of the provided type, 0 if they are kept forever
+func (p *RetentionPolicy) MaxAge(eventType EventType) time.Duration {
+	if maxAge, ok := p.EventTypes[eventType]; ok {
+		return maxAge
+	}
+	return p.Default
+}
+
+// IsEmpty returns true if the policy keeps every event forever
+func (p *RetentionPolicy) IsEmpty() bool {
+	return p.internal().IsEmpty()
+}
+
+// Expired returns true if the provided event is expired at the provided time
+func (p *RetentionPolicy) Expired(event *Event, now time.Time) bool {
+	maxAge := p.MaxAge(event.Type)
+	return maxAge > 0 && event.Time.Before(now.Add(-maxAge))
+}
+
+// newRetentionPolicy converts an internal retention policy
+func newRetentionPolicy(policy *krie.RetentionPolicy) *RetentionPolicy {
+	out := &RetentionPolicy{
+		Default:    policy.Default,
+		EventTypes: make(map[EventType]time.Duration, len(policy.EventTypes)),
+	}
+	for eventType, maxAge := range policy.EventTypes {
+		out.EventTypes[newEventType(eventType)] = maxAge
+	}
+	return out
+}
+
+// internal returns the internal retention policy of the policy
+func (p *RetentionPolicy) internal() *krie.RetentionPolicy {
+	out := &krie.RetentionPolicy{
+		Default:    p.Default,
+		EventTypes: make(map[events.EventType]time.Duration, len(p.EventTypes)),
+	}
+	for eventType, maxAge := range p.EventTypes {
+		out.EventTypes[eventType.internal()] = maxAge
+	}
+	return out
+}
+
+// StorageCompactor is implemented by the storages which support the retention policies of the "storage" section
+type StorageCompactor interface {
+	// Compact removes the events expired at the provided time, and returns the number of removed events of each event
+	// type. Compact may be called concurrently with Send and Query.
+	Compact(policy *RetentionPolicy, now time.Time) (map[EventType]int, error)
+}
@@ -0,0 +1,252 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Gui774ume/krie/pkg/krie"
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// HarnessOptions configures the test harness
+type HarnessOptions struct {
+	// ModulePath is the path to the compiled test module (see pkg/testing/module), the module events are skipped if
+	// it is empty
+	ModulePath string
+	// Timeout is the maximum time to wait for the event of a trigger
+	Timeout time.Duration
+}
+
+// Result is the result of a trigger
+type Result struct {
+	Trigger  string        `json:"trigger"`
+	Status   string        `json:"status"`
+	EventID  string        `json:"event_id,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// harnessEvent contains the fields of an event used to match a trigger
+type harnessEvent struct {
+	ID    string `json:"id"`
+	Event struct {
+		Type string `json:"type"`
+	} `json:"event"`
+	Process struct {
+		PID uint32 `json:"pid"`
+	} `json:"process"`
+	// Raw is set when the event couldn't be decoded
+	Raw *struct {
+		Type  string `json:"type"`
+		Error string `json:"error"`
+	} `json:"raw"`
+}
+
+// Harness runs KRIE against the running kernel, triggers its event types and checks the produced events
+type Harness struct {
+	options HarnessOptions
+	krie    *krie.KRIE
+	dir     string
+
+	output  *os.File
+	reader  *bufio.Reader
+	partial string
+}
+
+// NewHarness creates a test harness from a KRIE configuration. The sinks, responders, bursts, approvals and
+// redactions of the configuration are disabled, and the triggered event types are logged. The events are decoded in
+// lenient mode, so that a decoding failure is reported by the trigger of the event instead of a timeout.
+func NewHarness(config *krie.Options, options HarnessOptions) (*Harness, error) {
+	dir, err := os.MkdirTemp("", "krie-harness-")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create harness directory: %w", err)
+	}
+
+	h := &Harness{
+		options: options,
+		dir:     dir,
+	}
+
+	opts := *config
+	opts.Output = filepath.Join(dir, "events.json")
+	opts.StateDirectory = filepath.Join(dir, "state")
+	webhook := *config.Webhook
+	webhook.URL = ""
+	opts.Webhook = &webhook
+	opts.Responders = nil
+	opts.Burst = krie.NewBurstOptions()
+	opts.Approval = krie.NewApprovalOptions()
+	opts.Redaction = krie.NewRedactionOptions()
+	opts.Events = harnessPolicies()
+	opts.Decoding = krie.LenientDecoding
+	opts.Storage = krie.NewStorageOptions()
+
+	if h.krie, err = krie.NewKRIE(&opts); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	return h, nil
+}
+
+// harnessPolicies returns policies which log every triggered event type
+func harnessPolicies() *events.Options {
+	policies := events.NewEventsOptions()
+	policies.InitModuleEvent = events.LogAction
+	policies.DeleteModuleEvent = events.LogAction
+	policies.BPFEvent = events.LogAction
+	policies.BPFFilterEvent = events.LogAction
+	policies.PTraceEvent = events.LogAction
+	policies.KexecEvent = events.LogAction
+	policies.KProbeEvent = events.LogAction
+	policies.SysCtlEvent.Action = events.LogAction
+	return policies
+}
+
+// Start starts KRIE
+func (h *Harness) Start() error {
+	if err := h.krie.Start(); err != nil {
+		return err
+	}
+	var err error
+	if h.output, err = os.Open(filepath.Join(h.dir, "events.json")); err != nil {
+		return fmt.Errorf("couldn't open harness output: %w", err)
+	}
+	h.reader = bufio.NewReader(h.output)
+	return nil
+}
+
+// Stop stops KRIE and removes the harness directory
+func (h *Harness) Stop() error {
+	err := h.krie.Stop()
+	if h.output != nil {
+		_ = h.output.Close()
+	}
+	_ = os.RemoveAll(h.dir)
+	return err
+}
+
+// ProbeStatuses returns the state of the eBPF programs of KRIE
+func (h *Harness) ProbeStatuses() []krie.ProbeStatus {
+	return h.krie.ProbeStatuses()
+}
+
+// BytecodeVariant returns the name of the eBPF bytecode variant loaded by KRIE, if any
+func (h *Harness) BytecodeVariant() string {
+	if variant := h.krie.BytecodeVariant(); variant != nil {
+		return variant.Name
+	}
+	return ""
+}
+
+// RunAll runs the provided triggers in order
+func (h *Harness) RunAll(triggers []*Trigger) []Result {
+	results := make([]Result, 0, len(triggers))
+	for _, t := range triggers {
+		results = append(results, h.Run(t))
+	}
+	return results
+}
+
+// Run executes a trigger in a child process and waits for the matching event
+func (h *Harness) Run(t *Trigger) Result {
+	start := time.Now()
+	result := Result{
+		Trigger: t.Name,
+		Status:  StatusFail,
+	}
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
+
+	if t.EventType == events.InitModuleEventType || t.EventType == events.DeleteModuleEventType {
+		if len(h.options.ModulePath) == 0 {
+			result.Status = StatusSkip
+			result.Error = "no test module provided"
+			return result
+		}
+	}
+
+	cmd := t.command(h.options.ModulePath)
+	if err := cmd.Start(); err != nil {
+		result.Error = fmt.Sprintf("couldn't start trigger: %v", err)
+		return result
+	}
+	pid := uint32(cmd.Process.Pid)
+	if err := cmd.Wait(); err != nil {
+		result.Error = fmt.Sprintf("trigger failed: %v", err)
+		return result
+	}
+
+	deadline := time.Now().Add(h.options.Timeout)
+	for time.Now().Before(deadline) {
+		evt, err := h.next()
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		if evt == nil {
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		if evt.Raw != nil && evt.Raw.Type == t.EventType.String() {
+			result.EventID = evt.ID
+			result.Error = fmt.Sprintf("couldn't decode %s event: %s", t.EventType, evt.Raw.Error)
+			return result
+		}
+		if evt.Event.Type == t.EventType.String() && evt.Process.PID == pid {
+			result.Status = StatusPass
+			result.EventID = evt.ID
+			return result
+		}
+	}
+	result.Error = fmt.Sprintf("no %s event from pid %d after %s", t.EventType, pid, h.options.Timeout)
+	return result
+}
+
+// next returns the next event of the output file, or nil if no new event is available
+func (h *Harness) next() (*harnessEvent, error) {
+	line, err := h.reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("couldn't read harness output: %w", err)
+	}
+	h.partial += line
+	if !strings.HasSuffix(h.partial, "\n") {
+		return nil, nil
+	}
+
+	var evt harnessEvent
+	data := h.partial
+	h.partial = ""
+	if err = json.Unmarshal([]byte(data), &evt); err != nil {
+		return nil, fmt.Errorf("couldn't parse harness output: %w", err)
+	}
+	return &evt, nil
+}
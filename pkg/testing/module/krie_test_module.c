@@ -0,0 +1,18 @@
+#include <linux/init.h>
+#include <linux/module.h>
+#include <linux/kernel.h>
+
+MODULE_LICENSE("GPL");
+MODULE_AUTHOR("Guillaume Fournier");
+MODULE_DESCRIPTION("KRIE test module: loaded and unloaded by the test harness to trigger init_module and delete_module events");
+MODULE_VERSION("0.01");
+
+static int __init krie_test_module_init(void) {
+    return 0;
+}
+
+static void __exit krie_test_module_exit(void) {
+}
+
+module_init(krie_test_module_init);
+module_exit(krie_test_module_exit);
@@ -0,0 +1,215 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/cilium/ebpf"
+	"golang.org/x/sys/unix"
+
+	"github.com/Gui774ume/krie/pkg/krie/events"
+)
+
+const (
+	// triggerEnv is set by the harness to select the trigger executed by a child process
+	triggerEnv = "KRIE_TEST_TRIGGER"
+	// triggerArgEnv is the argument of the trigger
+	triggerArgEnv = "KRIE_TEST_TRIGGER_ARG"
+
+	testKProbeGroup = "krie_test"
+	testKProbeName  = "krie_harness"
+)
+
+// Trigger deterministically generates an event of a given type. Since KRIE ignores its own events, triggers are
+// executed in a child process.
+type Trigger struct {
+	Name      string
+	EventType events.EventType
+
+	// run is executed in the child process
+	run func(arg string) error
+}
+
+// Triggers is the list of the event types that the harness is able to trigger
+var Triggers = []*Trigger{
+	{Name: "init_module", EventType: events.InitModuleEventType, run: loadTestModule},
+	{Name: "delete_module", EventType: events.DeleteModuleEventType, run: unloadTestModule},
+	{Name: "bpf", EventType: events.BPFEventType, run: createBPFMap},
+	{Name: "bpf_filter", EventType: events.BPFFilterEventType, run: attachSocketFilter},
+	{Name: "ptrace", EventType: events.PTraceEventType, run: ptraceTraceMe},
+	{Name: "kexec", EventType: events.KexecEventType, run: kexecInvalidFlags},
+	{Name: "kprobe", EventType: events.KProbeEventType, run: registerKProbe},
+	{Name: "sysctl", EventType: events.SysCtlEventType, run: writeSysCtl},
+}
+
+// SelectTriggers returns the triggers of the provided names, or all the triggers if no name is provided
+func SelectTriggers(names []string) ([]*Trigger, error) {
+	if len(names) == 0 {
+		return Triggers, nil
+	}
+	var out []*Trigger
+	for _, name := range names {
+		var found bool
+		for _, t := range Triggers {
+			if t.Name == name {
+				out = append(out, t)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown trigger: %s", name)
+		}
+	}
+	return out, nil
+}
+
+// command returns the command used to run the trigger in a child process
+func (t *Trigger) command(arg string) *exec.Cmd {
+	cmd := exec.Command("/proc/self/exe")
+	cmd.Env = append(os.Environ(), triggerEnv+"="+t.Name, triggerArgEnv+"="+arg)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// HandleTrigger runs the requested trigger and exits when the current process was started by the harness. It must be
+// called at the beginning of the main function of the binaries using the harness.
+func HandleTrigger() {
+	name := os.Getenv(triggerEnv)
+	if len(name) == 0 {
+		return
+	}
+	for _, t := range Triggers {
+		if t.Name != name {
+			continue
+		}
+		if err := t.run(os.Getenv(triggerArgEnv)); err != nil {
+			fmt.Fprintf(os.Stderr, "trigger %s failed: %v\n", name, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	fmt.Fprintf(os.Stderr, "unknown trigger: %s\n", name)
+	os.Exit(1)
+}
+
+// testModuleName returns the name of a kernel module from its path
+func testModuleName(path string) string {
+	return strings.ReplaceAll(strings.TrimSuffix(filepath.Base(path), ".ko"), "-", "_")
+}
+
+func loadTestModule(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("couldn't open test module: %w", err)
+	}
+	defer f.Close()
+	return unix.FinitModule(int(f.Fd()), "", 0)
+}
+
+func unloadTestModule(path string) error {
+	return unix.DeleteModule(testModuleName(path), 0)
+}
+
+func createBPFMap(_ string) error {
+	m, err := ebpf.NewMap(&ebpf.MapSpec{
+		Name:       "krie_test",
+		Type:       ebpf.Array,
+		KeySize:    4,
+		ValueSize:  4,
+		MaxEntries: 1,
+	})
+	if err != nil {
+		return err
+	}
+	return m.Close()
+}
+
+func attachSocketFilter(_ string) error {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM, 0)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	// accept all
+	filter := []unix.SockFilter{{Code: unix.BPF_RET | unix.BPF_K, K: 0xffffffff}}
+	return unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER, &unix.SockFprog{
+		Len:    uint16(len(filter)),
+		Filter: &filter[0],
+	})
+}
+
+func ptraceTraceMe(_ string) error {
+	if _, _, errno := unix.Syscall(unix.SYS_PTRACE, unix.PTRACE_TRACEME, 0, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// kexecInvalidFlags calls kexec_file_load with unknown flags, the kernel rejects the call before loading anything
+func kexecInvalidFlags(_ string) error {
+	if _, _, errno := unix.Syscall6(unix.SYS_KEXEC_FILE_LOAD, ^uintptr(0), ^uintptr(0), 0, 0, 1<<31, 0); errno != unix.EINVAL {
+		return fmt.Errorf("kexec_file_load returned %v, expected EINVAL", errno)
+	}
+	return nil
+}
+
+func tracefsPath() string {
+	for _, path := range []string{"/sys/kernel/tracing", "/sys/kernel/debug/tracing"} {
+		if _, err := os.Stat(filepath.Join(path, "kprobe_events")); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+func registerKProbe(_ string) error {
+	tracefs := tracefsPath()
+	if len(tracefs) == 0 {
+		return fmt.Errorf("couldn't find tracefs")
+	}
+	writeKProbeEvent := func(definition string) error {
+		f, err := os.OpenFile(filepath.Join(tracefs, "kprobe_events"), os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = f.WriteString(definition + "\n")
+		return err
+	}
+
+	if err := writeKProbeEvent(fmt.Sprintf("p:%s/%s vfs_read", testKProbeGroup, testKProbeName)); err != nil {
+		return fmt.Errorf("couldn't register kprobe: %w", err)
+	}
+	return writeKProbeEvent(fmt.Sprintf("-:%s/%s", testKProbeGroup, testKProbeName))
+}
+
+func writeSysCtl(_ string) error {
+	// write back the current value
+	path := "/proc/sys/kernel/printk_ratelimit"
+	value, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, value, 0644)
+}
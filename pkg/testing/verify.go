@@ -0,0 +1,122 @@
+/*
+Copyright © 2022 GUILLAUME FOURNIER
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/Gui774ume/krie/pkg/kernel"
+	"github.com/Gui774ume/krie/pkg/krie"
+)
+
+// KernelInfo identifies the kernel of a compatibility report
+type KernelInfo struct {
+	Release      string `json:"release"`
+	Version      string `json:"version"`
+	Distribution string `json:"distribution,omitempty"`
+	DistVersion  string `json:"distribution_version,omitempty"`
+	Arch         string `json:"arch"`
+}
+
+// KernelReport is the compatibility report of KRIE on the running kernel, meant to be archived by the image pipelines
+// of the distributions and appliances
+type KernelReport struct {
+	GeneratedAt time.Time  `json:"generated_at"`
+	Kernel      KernelInfo `json:"kernel"`
+	// Compatible is set when KRIE started and no trigger failed, the skipped triggers don't affect it
+	Compatible      bool   `json:"compatible"`
+	Error           string `json:"error,omitempty"`
+	BytecodeVariant string `json:"bytecode_variant,omitempty"`
+
+	// Probes is the state of each eBPF program after startup
+	Probes            []krie.ProbeStatus `json:"probes,omitempty"`
+	RunningProbes     int                `json:"running_probes"`
+	UnavailableProbes int                `json:"unavailable_probes"`
+
+	// Triggers are the results of the generated syscalls, each one checks the attachment of the probes of an event
+	// type and the decoding of its events
+	Triggers []Result `json:"triggers,omitempty"`
+	Passed   int      `json:"passed"`
+	Failed   int      `json:"failed"`
+	Skipped  int      `json:"skipped"`
+}
+
+// currentKernel returns the kernel of the host
+func currentKernel() KernelInfo {
+	info := KernelInfo{
+		Arch: runtime.GOARCH,
+	}
+	host, err := kernel.NewHost()
+	if err != nil {
+		return info
+	}
+	info.Release = host.UnameRelease
+	info.Version = host.Code.String()
+	info.Distribution = host.OsRelease["ID"]
+	info.DistVersion = host.OsRelease["VERSION_ID"]
+	return info
+}
+
+// VerifyKernel loads KRIE on the running kernel, records the state of its probes, then runs the provided triggers and
+// checks that their events are captured and decoded. Like the harness, it requires HandleTrigger to be called at the
+// beginning of the main function of the binary. The returned report is never nil: a failure to load KRIE is reported
+// in its Error field.
+func VerifyKernel(config *krie.Options, options HarnessOptions, triggers []*Trigger) *KernelReport {
+	report := &KernelReport{
+		GeneratedAt: time.Now().UTC(),
+		Kernel:      currentKernel(),
+	}
+
+	harness, err := NewHarness(config, options)
+	if err != nil {
+		report.Error = "couldn't create test harness: " + err.Error()
+		return report
+	}
+	if err = harness.Start(); err != nil {
+		_ = harness.Stop()
+		report.Error = "couldn't start KRIE: " + err.Error()
+		return report
+	}
+	report.BytecodeVariant = harness.BytecodeVariant()
+	report.Probes = harness.ProbeStatuses()
+	for _, probe := range report.Probes {
+		switch probe.State {
+		case krie.ProbeRunning:
+			report.RunningProbes++
+		case krie.ProbeUnavailable:
+			report.UnavailableProbes++
+		}
+	}
+
+	report.Triggers = harness.RunAll(triggers)
+	if err = harness.Stop(); err != nil {
+		report.Error = "couldn't stop KRIE: " + err.Error()
+	}
+	for _, result := range report.Triggers {
+		switch result.Status {
+		case StatusPass:
+			report.Passed++
+		case StatusFail:
+			report.Failed++
+		case StatusSkip:
+			report.Skipped++
+		}
+	}
+	report.Compatible = len(report.Error) == 0 && report.Failed == 0
+	return report
+}
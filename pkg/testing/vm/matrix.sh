@@ -0,0 +1,51 @@
+#!/bin/bash
+# Runs "krie verify-kernel" on a list of kernels, each one booted in a VM with virtme-ng
+# (https://github.com/arighi/virtme-ng), and writes a compatibility report per kernel.
+#
+# usage: matrix.sh <report directory> <kernel image or kernel release>... [-- krie verify-kernel flags...]
+#
+# The reports are written to <report directory>/<kernel>.json. The script exits with an error if one of the kernels
+# isn't compatible.
+
+set -uo pipefail
+
+if [ $# -lt 2 ]; then
+    echo "usage: $0 <report directory> <kernel image or kernel release>... [-- krie verify-kernel flags...]" >&2
+    exit 1
+fi
+
+REPORTS=$(realpath -m "$1")
+shift
+
+KERNELS=()
+while [ $# -gt 0 ] && [ "$1" != "--" ]; do
+    KERNELS+=("$1")
+    shift
+done
+[ $# -gt 0 ] && shift
+
+ROOT=$(cd "$(dirname "$0")/../../.." && pwd)
+
+if ! command -v vng > /dev/null; then
+    echo "virtme-ng (vng) is required" >&2
+    exit 1
+fi
+
+mkdir -p "$REPORTS"
+cd "$ROOT"
+
+FAILED=0
+for KERNEL in "${KERNELS[@]}"; do
+    REPORT="$REPORTS/$(basename "$KERNEL").json"
+    if vng --run "$KERNEL" --user root --rwdir "$ROOT" --rwdir "$REPORTS" --exec "./bin/krie verify-kernel --output $REPORT $*"; then
+        echo "PASS $KERNEL"
+    else
+        echo "FAIL $KERNEL ($REPORT)"
+        FAILED=$((FAILED + 1))
+    fi
+done
+
+if [ $FAILED -gt 0 ]; then
+    echo "$FAILED/${#KERNELS[@]} kernel(s) aren't compatible" >&2
+    exit 1
+fi
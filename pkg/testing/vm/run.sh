@@ -0,0 +1,26 @@
+#!/bin/bash
+# Boots a kernel in a VM with virtme-ng (https://github.com/arighi/virtme-ng) and runs "krie selftest" inside it.
+#
+# usage: run.sh <kernel image or kernel release> [krie selftest flags...]
+#
+# The test module must be compiled against the tested kernel, and passed with --module.
+
+set -euo pipefail
+
+if [ $# -lt 1 ]; then
+    echo "usage: $0 <kernel image or kernel release> [krie selftest flags...]" >&2
+    exit 1
+fi
+
+KERNEL=$1
+shift
+
+ROOT=$(cd "$(dirname "$0")/../../.." && pwd)
+
+if ! command -v vng > /dev/null; then
+    echo "virtme-ng (vng) is required" >&2
+    exit 1
+fi
+
+cd "$ROOT"
+exec vng --run "$KERNEL" --user root --rwdir "$ROOT" --exec "./bin/krie selftest $*"